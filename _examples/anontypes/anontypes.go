@@ -0,0 +1,85 @@
+// Copyright 2024 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// package anontypes tests anonymous struct types, unexported helper types
+// and types of internal packages in the exported API.
+package anontypes
+
+import "github.com/go-python/gopy/_examples/anontypes/internal/impl"
+
+// Config has a field of an anonymous struct type
+type Config struct {
+	Name string
+
+	// Limits is an anonymous struct
+	Limits struct {
+		Max int
+		Min int
+	}
+
+	// Tags has anonymous struct values
+	Tags []struct{ Key, Value string }
+
+	engine *impl.Engine
+}
+
+// NewConfig returns a new config
+func NewConfig(name string) *Config {
+	c := &Config{Name: name, engine: &impl.Engine{Speed: 1}}
+	c.Limits.Max = 10
+	return c
+}
+
+// Max returns the max limit
+func (c *Config) Max() int {
+	return c.Limits.Max
+}
+
+// Point returns an anonymous struct
+func Point() struct{ X, Y int } {
+	return struct{ X, Y int }{1, 2}
+}
+
+// SetPoint takes an anonymous struct
+func SetPoint(p struct{ X, Y int }) int {
+	return p.X + p.Y
+}
+
+// helper is an unexported helper type
+type helper struct {
+	N int
+}
+
+// Helper returns an unexported type
+func Helper() *helper {
+	return &helper{N: 1}
+}
+
+// Engine returns an internal type
+func (c *Config) Engine() *impl.Engine {
+	return c.engine
+}
+
+// NewEngine returns an internal type
+func NewEngine() impl.Engine {
+	return impl.Engine{Speed: 2}
+}
+
+// Speed takes an internal type
+func Speed(e *impl.Engine) int {
+	return e.Speed
+}
+
+// Local uses a locally defined type, that is not part of its signature
+func Local() int {
+	type pair struct{ a, b int }
+	p := pair{1, 2}
+	return p.a + p.b
+}
+
+// Holder holds an internal engine, as an exported field
+type Holder struct {
+	Engine impl.Engine
+	Count  int
+}
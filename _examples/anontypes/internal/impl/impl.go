@@ -0,0 +1,11 @@
+// Copyright 2024 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// package impl has the implementation of anontypes, which is internal
+package impl
+
+// Engine is an internal type
+type Engine struct {
+	Speed int
+}
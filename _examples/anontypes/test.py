@@ -0,0 +1,25 @@
+# Copyright 2024 The go-python Authors.  All rights reserved.
+# Use of this source code is governed by a BSD-style
+# license that can be found in the LICENSE file.
+
+from __future__ import print_function
+
+import anontypes
+
+c = anontypes.NewConfig("cfg")
+print("c.Name:", c.Name)
+print("c.Max():", c.Max())
+print("Local():", anontypes.Local())
+
+h = anontypes.Holder(Count=3)
+print("h.Count:", h.Count)
+
+# symbols using anonymous, unexported or internal types are skipped
+for nm in ["Point", "SetPoint", "Helper", "NewEngine", "Speed"]:
+    print("has %s:" % nm, hasattr(anontypes, nm))
+print("has Config.Engine:", hasattr(anontypes.Config, "Engine"))
+print("has Config.Limits:", hasattr(anontypes.Config, "Limits"))
+print("has Config.Tags:", hasattr(anontypes.Config, "Tags"))
+print("has Holder.Engine:", hasattr(anontypes.Holder, "Engine"))
+
+print("OK")
@@ -0,0 +1,88 @@
+// Copyright 2024 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// package argchecks tests the checks of the args in python, recognized in
+// the Go code or set by //gopy:check directives.
+package argchecks
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+)
+
+// MaxName is the maximum length of a name.
+const MaxName = 8
+
+// ErrNegative is returned for negative numbers.
+var ErrNegative = errors.New("negative number")
+
+// Calls counts the calls of the Go functions, past their checks.
+var Calls int
+
+// Sqrt returns the square root of x.
+func Sqrt(x float64) (float64, error) {
+	if x < 0 {
+		return 0, ErrNegative
+	}
+	Calls++
+	return math.Sqrt(x), nil
+}
+
+// Greet returns a greeting of the name.
+func Greet(name string) (string, error) {
+	if name == "" {
+		return "", errors.New("empty name")
+	}
+	if len(name) > MaxName {
+		return "", fmt.Errorf("%w: name too long", ErrInvalid)
+	}
+	Calls++
+	return "hello " + name, nil
+}
+
+// ErrInvalid is returned for invalid args.
+var ErrInvalid = errors.New("invalid argument")
+
+// Clamp returns x clamped to [lo, hi].
+func Clamp(x, lo, hi int) (int, error) {
+	if lo > hi || (hi-lo)*2 > 100 {
+		return 0, fmt.Errorf("bad range %d-%d", lo, hi)
+	}
+	if strings.HasPrefix("", "") {
+		// not a check of the args: the checks stop here
+		Calls++
+	}
+	if x < lo {
+		return lo, nil
+	}
+	return x, nil
+}
+
+// Scale scales the values by p, a probability.
+//
+//gopy:check 0 <= p && p <= 1
+//gopy:check len(vals) > 0
+func Scale(vals []float64, p float64) {
+	Calls++
+	for i := range vals {
+		vals[i] *= p
+	}
+}
+
+// Counter counts up to its limit.
+type Counter struct {
+	N int
+}
+
+// Add adds d to the counter, less than 10 at a time.
+func (c *Counter) Add(d int) error {
+	if d < 0 || d >= 10 {
+		return ErrInvalid
+	}
+	Calls++
+	c.N += d
+	return nil
+}
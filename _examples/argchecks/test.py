@@ -0,0 +1,54 @@
+# Copyright 2024 The go-python Authors.  All rights reserved.
+# Use of this source code is governed by a BSD-style
+# license that can be found in the LICENSE file.
+
+from __future__ import print_function
+
+import argchecks, go
+
+print("Sqrt(4):", argchecks.Sqrt(4))
+try:
+    argchecks.Sqrt(-1)
+except argchecks.ErrNegativeException as e:
+    print("caught ErrNegativeException:", e)
+
+print("Greet(go):", argchecks.Greet("go"))
+try:
+    argchecks.Greet("")
+except go.ArgumentError as e:
+    print("caught ArgumentError:", e)
+try:
+    argchecks.Greet("héhéhé")
+except argchecks.ErrInvalidException as e:
+    print("caught ErrInvalidException:", e)
+
+try:
+    argchecks.Clamp(1, 5, 2)
+except ValueError as e:
+    print("caught ValueError:", e)
+print("Clamp(1, 2, 5):", argchecks.Clamp(1, 2, 5))
+
+vals = go.Slice_float64([1, 2])
+argchecks.Scale(vals, 0.5)
+print("Scale:", vals[0], vals[1])
+for p in (2, -0.5):
+    try:
+        argchecks.Scale(vals, p)
+    except go.ArgumentError as e:
+        print("caught ArgumentError:", e)
+try:
+    argchecks.Scale(go.Slice_float64(), 0.5)
+except go.ArgumentError as e:
+    print("caught ArgumentError:", e)
+
+c = argchecks.Counter()
+c.Add(3)
+try:
+    c.Add(10)
+except argchecks.ErrInvalidException as e:
+    print("caught ErrInvalidException:", e)
+print("N:", c.N)
+
+print("calls:", argchecks.Calls())
+
+print("OK")
@@ -15,3 +15,33 @@ func IntSum(a [4]int) int {
 func CreateArray() [4]int {
 	return [4]int{1, 2, 3, 4}
 }
+
+// Vec is a named array type
+type Vec [3]float64
+
+// Norm1 returns the sum of the absolute values of the vector
+func Norm1(v Vec) float64 {
+	s := 0.0
+	for _, x := range v {
+		if x < 0 {
+			x = -x
+		}
+		s += x
+	}
+	return s
+}
+
+// Block has fields of array types
+type Block struct {
+	Pos Vec
+	Sum [16]byte
+}
+
+// Checksum returns the sum of the bytes of the Sum of the block
+func (b *Block) Checksum() int {
+	s := 0
+	for _, x := range b.Sum {
+		s += int(x)
+	}
+	return s
+}
@@ -9,8 +9,28 @@ a = [1,2,3,4]
 b = arrays.CreateArray()
 print ("Python list:", a)
 print ("Go array: ", b)
-# note: only support slices as converted from python -- arrays don't make sense
-#print ("arrays.IntSum from Python list:", arrays.IntSum(a))
+print ("arrays.IntSum from Python list:", arrays.IntSum(a))
 print ("arrays.IntSum from Go array:", arrays.IntSum(b))
+print ("arrays.IntSum from Python tuple:", arrays.IntSum((1, 1, 1, 1)))
+
+try:
+    arrays.IntSum([1, 2])
+except ValueError as e:
+    print("arrays.IntSum from short list:", e)
+
+b[0] = 10
+print ("Go array after set:", b.to_list())
+
+v = arrays.Vec([1, -2, 3])
+print ("arrays.Norm1(Vec):", arrays.Norm1(v))
+print ("arrays.Norm1(list):", arrays.Norm1([0.5, 0.5, -1]))
+
+blk = arrays.Block()
+blk.Pos = (1, 2, 3)
+blk.Pos[2] = 4
+blk.Sum = range(16)
+blk.Sum[0] = 100
+print ("blk.Pos:", blk.Pos.to_list())
+print ("blk.Checksum():", blk.Checksum())
 
 print("OK")
@@ -0,0 +1,76 @@
+// Copyright 2024 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// package asconv tests asserting and converting the wrapped Go values to
+// other bound types with as_.
+package asconv
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Shape is a shape with an area.
+type Shape interface {
+	Area() float64
+}
+
+// Named is a value with a name.
+type Named interface {
+	Name() string
+}
+
+// Circle is a circle.
+type Circle struct {
+	Radius float64
+}
+
+// Area returns the area of the circle.
+func (c *Circle) Area() float64 {
+	return 3 * c.Radius * c.Radius
+}
+
+// Name returns the name of the circle.
+func (c *Circle) Name() string {
+	return fmt.Sprintf("circle of radius %g", c.Radius)
+}
+
+// Square is a square, held by value by the shapes.
+type Square struct {
+	Side float64
+}
+
+// Area returns the area of the square.
+func (s Square) Area() float64 {
+	return s.Side * s.Side
+}
+
+// Disc is a circle under another name.
+type Disc Circle
+
+// Names is a list of names.
+type Names []string
+
+// Tags is a list of tags.
+type Tags []string
+
+// Join returns the tags joined with commas.
+func (t Tags) Join() string {
+	return strings.Join(t, ",")
+}
+
+// NewCircle returns a circle of the radius as a shape.
+func NewCircle(r float64) Shape {
+	return &Circle{Radius: r}
+}
+
+// NewSquare returns a square of the side as a shape.
+func NewSquare(side float64) Shape {
+	return Square{Side: side}
+}
+
+// NewNames returns the names.
+func NewNames(names ...string) Names {
+	return Names(names)
+}
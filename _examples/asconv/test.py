@@ -0,0 +1,33 @@
+# Copyright 2024 The go-python Authors.  All rights reserved.
+# Use of this source code is governed by a BSD-style
+# license that can be found in the LICENSE file.
+
+from __future__ import print_function
+
+import asconv
+
+s = asconv.NewCircle(2)
+c = asconv.as_(asconv.Circle, s)
+print("as_(Circle, shape).Radius:", c.Radius)
+c.Radius = 3
+print("shape.Area() after setting the circle radius:", s.Area())
+
+n = asconv.as_(asconv.Named, s)
+print("as_(Named, shape).Name():", n.Name())
+
+sq = asconv.as_(asconv.Square, asconv.NewSquare(4))
+print("as_(Square, square shape).Side:", sq.Side)
+
+d = asconv.as_(asconv.Disc, c)
+print("as_(Disc, circle).Radius:", d.Radius)
+
+t = asconv.as_(asconv.Tags, asconv.NewNames("a", "b"))
+print("as_(Tags, names).Join():", t.Join())
+
+for target, obj in [(asconv.Circle, asconv.NewSquare(1)), (asconv.Named, asconv.NewSquare(1)), (asconv.Circle, 1), (int, c)]:
+    try:
+        asconv.as_(target, obj)
+    except TypeError as e:
+        print("TypeError:", e)
+
+print("OK")
@@ -0,0 +1,51 @@
+// Copyright 2024 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// package asyncapi tests the async twins of the functions and methods,
+// generated along the sync ones with -async.
+package asyncapi
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+)
+
+// Slow returns n*2, after sleeping for ms milliseconds.
+func Slow(n, ms int) int {
+	time.Sleep(time.Duration(ms) * time.Millisecond)
+	return n * 2
+}
+
+// Join joins the parts with the separator.
+func Join(sep string, parts ...string) string {
+	return strings.Join(parts, sep)
+}
+
+// Wait waits for ms milliseconds, or the end of the context.
+func Wait(ctx context.Context, ms int) error {
+	select {
+	case <-time.After(time.Duration(ms) * time.Millisecond):
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Fail returns an error.
+func Fail() error {
+	return errors.New("failed")
+}
+
+// Counter counts.
+type Counter struct {
+	N int
+}
+
+// Add adds d to the counter, and returns its count.
+func (c *Counter) Add(d int) int {
+	c.N += d
+	return c.N
+}
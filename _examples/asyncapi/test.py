@@ -0,0 +1,34 @@
+# Copyright 2024 The go-python Authors.  All rights reserved.
+# Use of this source code is governed by a BSD-style
+# license that can be found in the LICENSE file.
+
+from __future__ import print_function
+
+import asyncio, time
+import asyncapi, go
+
+print("Slow(1, 0):", asyncapi.Slow(1, 0))
+print("Join(-, a, b):", asyncapi.Join("-", "a", "b"))
+
+async def main():
+    start = time.time()
+    res = await asyncio.gather(*[asyncapi.Slow_async(i, 100) for i in range(5)])
+    print("Slow_async:", res)
+    print("Slow_async calls ran concurrently:", time.time() - start < 0.4)
+
+    print("Join_async(-, a, b):", await asyncapi.Join_async("-", "a", "b"))
+    print("Wait_async(10):", repr(await asyncapi.Wait_async(10)))
+
+    try:
+        await asyncapi.Fail_async()
+    except go.GoError as err:
+        print("Fail_async caught go.GoError:", err)
+
+    c = asyncapi.Counter()
+    c.Add(1)
+    print("c.Add_async(2):", await c.Add_async(2))
+    print("N:", c.N)
+
+asyncio.run(main())
+
+print("OK")
@@ -0,0 +1,23 @@
+# Copyright 2024 The go-python Authors.  All rights reserved.
+# Use of this source code is governed by a BSD-style
+# license that can be found in the LICENSE file.
+
+# prints the targets of the generated BUILD.bazel
+
+from __future__ import print_function
+
+import os
+
+import bazelbuild
+
+out = os.path.dirname(os.path.abspath(__file__))
+print("Makefile:", os.path.exists(os.path.join(out, "Makefile")))
+
+with open(os.path.join(out, "BUILD.bazel")) as f:
+    for line in f:
+        line = line.strip()
+        if line.startswith(("load(", "name = ", '"@', "linkmode = ")):
+            print(line)
+
+print("bazelbuild.Add(1, 2):", bazelbuild.Add(1, 2))
+print("OK")
@@ -0,0 +1,50 @@
+// Copyright 2024 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// package borrow tests the string params borrowed from the python str and
+// buffers, without copy, by //gopy:borrow directives.
+package borrow
+
+import (
+	"hash/fnv"
+	"strings"
+)
+
+// Hash returns the FNV-1a hash of the data.
+//
+//gopy:borrow
+func Hash(data string) uint64 {
+	h := fnv.New64a()
+	h.Write([]byte(data))
+	return h.Sum64()
+}
+
+// Count returns the number of occurrences of sub in s, with only s borrowed.
+//
+//gopy:borrow s
+func Count(s, sub string) int {
+	return strings.Count(s, sub)
+}
+
+// Scan calls f with the index of each byte of the data, e.g., changing it,
+// which the -borrow-checks option reports.
+//
+//gopy:borrow data
+func Scan(data string, f func(i int)) {
+	for i := range []byte(data) {
+		f(i)
+	}
+}
+
+// Parser counts the lines of the text fed to it.
+type Parser struct {
+	Lines int
+}
+
+// Feed counts the lines of the text.
+//
+//gopy:borrow
+func (p *Parser) Feed(text string) {
+	p.Lines += strings.Count(text, "\n")
+}
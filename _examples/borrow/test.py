@@ -0,0 +1,34 @@
+# Copyright 2024 The go-python Authors.  All rights reserved.
+# Use of this source code is governed by a BSD-style
+# license that can be found in the LICENSE file.
+
+from __future__ import print_function
+
+import borrow
+
+data = b"hello world\n" * 1000
+print("hash of bytes == hash of str:", borrow.Hash(data) == borrow.Hash(data.decode()))
+print("hash of memoryview == hash of str:", borrow.Hash(memoryview(data)[:11]) == borrow.Hash("hello world"))
+print("hash of bytearray == hash of str:", borrow.Hash(bytearray(b"abc")) == borrow.Hash("abc"))
+print("count:", borrow.Count("héllo héllo", "é"))
+
+p = borrow.Parser()
+p.Feed(data)
+p.Feed("a\nb\n")
+print("lines:", p.Lines)
+
+try:
+    borrow.Hash(42)
+except TypeError as e:
+    print("caught TypeError:", e)
+
+buf = bytearray(b"abc")
+def mutate(i):
+    buf[i] = ord("x")
+try:
+    borrow.Scan(buf, mutate)
+except RuntimeError as e:
+    print("caught RuntimeError:", e)
+print("buf:", buf.decode())
+
+print("OK")
@@ -0,0 +1,49 @@
+// Copyright 2024 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// package bulkfields tests setting and getting the fields of structs at
+// once, by update and fields.
+package bulkfields
+
+import "fmt"
+
+// Level is a log level.
+type Level int
+
+// Limits are the limits of a server.
+type Limits struct {
+	Conns   int
+	Timeout float64
+}
+
+// Config is the configuration of a server.
+type Config struct {
+	Name    string
+	Port    uint16
+	Debug   bool
+	Level   Level
+	Ratio   float32
+	Initial rune
+	Limits  *Limits
+}
+
+// String returns the configuration as a string.
+func (c *Config) String() string {
+	lim := "no limits"
+	if c.Limits != nil {
+		lim = fmt.Sprintf("%d conns", c.Limits.Conns)
+	}
+	return fmt.Sprintf("%s:%d debug=%v level=%d ratio=%g initial=%q %s", c.Name, c.Port, c.Debug, c.Level, c.Ratio, c.Initial, lim)
+}
+
+// Entry is a key and its value.
+type Entry struct {
+	Key   string
+	Value int
+}
+
+// Update sets the value of the entry.
+func (e *Entry) Update(v int) {
+	e.Value = v
+}
@@ -0,0 +1,34 @@
+# Copyright 2024 The go-python Authors.  All rights reserved.
+# Use of this source code is governed by a BSD-style
+# license that can be found in the LICENSE file.
+
+from __future__ import print_function
+
+import bulkfields
+
+c = bulkfields.Config()
+c.update(Name="srv", Port=8080, Debug=True, Level=2, Ratio=0.5, Initial="x", Limits=bulkfields.Limits(Conns=10))
+print("after update:", c.String())
+
+f = c.fields()
+print("fields:", sorted(f))
+print("Name, Port, Debug, Level, Ratio, Initial:", f["Name"], f["Port"], f["Debug"], f["Level"], f["Ratio"], f["Initial"])
+print("Limits.Conns:", f["Limits"].Conns)
+
+for kw in [dict(Port=70000), dict(Name="other", Port="80")]:
+    try:
+        c.update(**kw)
+    except (OverflowError, TypeError) as e:
+        print("update(%s): %s" % (", ".join(sorted(kw)), type(e).__name__))
+print("after failed updates:", c.String())
+
+try:
+    c.update(Nme="x")
+except TypeError as e:
+    print("TypeError:", e)
+
+e = bulkfields.Entry(Key="k")
+e.Update(3)
+print("Entry fields:", e.fields())
+
+print("OK")
@@ -0,0 +1,11 @@
+// Copyright 2024 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// package cmakebuild tests the generated CMakeLists.txt (-build=cmake).
+package cmakebuild
+
+// Add returns the sum of a and b.
+func Add(a, b int) int {
+	return a + b
+}
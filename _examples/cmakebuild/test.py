@@ -0,0 +1,22 @@
+# Copyright 2024 The go-python Authors.  All rights reserved.
+# Use of this source code is governed by a BSD-style
+# license that can be found in the LICENSE file.
+
+# prints the targets of the generated CMakeLists.txt
+
+from __future__ import print_function
+
+import os
+
+import cmakebuild
+
+out = os.path.dirname(os.path.abspath(__file__))
+print("Makefile:", os.path.exists(os.path.join(out, "Makefile")))
+
+with open(os.path.join(out, "CMakeLists.txt")) as f:
+    for line in f:
+        if line.startswith(("project(", "add_custom_target(", "add_library(", "add_dependencies(")):
+            print(line.rstrip())
+
+print("cmakebuild.Add(1, 2):", cmakebuild.Add(1, 2))
+print("OK")
@@ -0,0 +1,66 @@
+// Copyright 2024 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// package columns tests the functions returning parallel slices, bound by
+// //gopy:columns directives to return numpy structured arrays, or dicts of
+// numpy arrays.
+package columns
+
+import "errors"
+
+// Sample returns n samples of the times and values of a ramp, with their
+// validity.
+//
+//gopy:columns
+func Sample(n int, slope float64) (t []float64, v []float32, ok []bool, err error) {
+	if n < 0 {
+		return nil, nil, nil, errors.New("columns: negative number of samples")
+	}
+	for i := 0; i < n; i++ {
+		t = append(t, float64(i)/10)
+		v = append(v, float32(slope*float64(i)))
+		ok = append(ok, i%2 == 0)
+	}
+	return t, v, ok, nil
+}
+
+// Histogram returns the bins of the values, of width w, and their counts.
+//
+//gopy:columns dict
+func Histogram(w int) (bins []int64, counts []uint32) {
+	n := map[int64]uint32{}
+	for _, x := range []int64{1, 2, 3, 5, 8, 13, 21} {
+		n[x/int64(w)]++
+	}
+	for b := int64(0); b <= 21/int64(w); b++ {
+		bins = append(bins, b*int64(w))
+		counts = append(counts, n[b])
+	}
+	return bins, counts
+}
+
+// Pairs returns the unnamed columns of the pairs of i and i*i.
+//
+//gopy:columns
+func Pairs(n int) ([]int, []int) {
+	a, b := make([]int, n), make([]int, n)
+	for i := range a {
+		a[i], b[i] = i, i*i
+	}
+	return a, b
+}
+
+// Ragged returns columns of different lengths, raising ValueError.
+//
+//gopy:columns dict
+func Ragged() (a []int, b []int) {
+	return []int{1, 2}, []int{3}
+}
+
+// Names returns strings, not numbers, which cannot be columns.
+//
+//gopy:columns
+func Names() (a []string, b []string) {
+	return nil, nil
+}
@@ -0,0 +1,37 @@
+# Copyright 2024 The go-python Authors.  All rights reserved.
+# Use of this source code is governed by a BSD-style
+# license that can be found in the LICENSE file.
+
+from __future__ import print_function
+
+import columns
+
+s = columns.Sample(4, 0.5)
+print("Sample dtype names:", s.dtype.names)
+print("Sample t:", s['t'].tolist())
+print("Sample v:", s['v'].tolist())
+print("Sample ok:", s['ok'].tolist())
+print("Sample[1]:", s[1]['t'], s[1]['v'], s[1]['ok'])
+print("Sample(0) len:", len(columns.Sample(0, 1)))
+try:
+    columns.Sample(-1, 1)
+except Exception as e:
+    print("Sample(-1) raised:", e)
+
+h = columns.Histogram(5)
+print("Histogram keys:", sorted(h.keys()))
+print("Histogram bins:", h['bins'].tolist())
+print("Histogram counts:", h['counts'].tolist())
+
+p = columns.Pairs(3)
+print("Pairs dtype names:", p.dtype.names)
+print("Pairs f1:", p['f1'].tolist())
+
+try:
+    columns.Ragged()
+except ValueError as e:
+    print("Ragged raised ValueError:", e)
+
+print("Names bound:", hasattr(columns, "Names"))
+
+print("OK")
@@ -0,0 +1,69 @@
+// Copyright 2024 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// package commaok tests the functions returning a value and a bool, in the
+// comma-ok idiom, returning None or raising KeyError when the bool is false.
+package commaok
+
+// Colors are the codes of the color names.
+var Colors = map[string]int{"red": 1, "green": 2}
+
+// Color returns the code of the color name, if any.
+func Color(name string) (int, bool) {
+	c, ok := Colors[name]
+	return c, ok
+}
+
+// MustColor returns the code of the color name, raising KeyError if there
+// is none.
+//
+//gopy:commaok raise
+func MustColor(name string) (int, bool) {
+	c, ok := Colors[name]
+	return c, ok
+}
+
+// Entry is an entry of a cache.
+type Entry struct {
+	Key   string
+	Value string
+}
+
+// Cache is a cache of entries.
+type Cache struct {
+	entries map[string]*Entry
+}
+
+// NewCache returns a new empty cache.
+func NewCache() *Cache {
+	return &Cache{entries: make(map[string]*Entry)}
+}
+
+// Put adds the value of the key to the cache.
+func (c *Cache) Put(key, value string) {
+	c.entries[key] = &Entry{Key: key, Value: value}
+}
+
+// Get returns the entry of the key, if any.
+func (c *Cache) Get(key string) (*Entry, bool) {
+	e, ok := c.entries[key]
+	return e, ok
+}
+
+// Lookup returns the value of the key, raising KeyError if there is none.
+//
+//gopy:commaok raise
+func (c *Cache) Lookup(key string) (string, bool) {
+	e, ok := c.entries[key]
+	if !ok {
+		return "", false
+	}
+	return e.Value, true
+}
+
+// AsString returns the value as a string, if it is one.
+func AsString(v interface{}) (string, bool) {
+	s, ok := v.(string)
+	return s, ok
+}
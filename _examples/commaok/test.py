@@ -0,0 +1,29 @@
+# Copyright 2024 The go-python Authors.  All rights reserved.
+# Use of this source code is governed by a BSD-style
+# license that can be found in the LICENSE file.
+
+from __future__ import print_function
+
+import commaok
+
+print("Color('red'):", commaok.Color('red'))
+print("Color('blue'):", commaok.Color('blue'))
+print("MustColor('green'):", commaok.MustColor('green'))
+try:
+    commaok.MustColor('blue')
+except KeyError as e:
+    print("MustColor('blue'): KeyError", e)
+
+c = commaok.NewCache()
+c.Put('a', 'alpha')
+print("c.Get('a').Value:", c.Get('a').Value)
+print("c.Get('b'):", c.Get('b'))
+print("c.Lookup('a'):", c.Lookup('a'))
+try:
+    c.Lookup('b')
+except KeyError as e:
+    print("c.Lookup('b'): KeyError", e)
+
+print("AsString('x'):", commaok.AsString('x'))
+
+print("OK")
@@ -0,0 +1,72 @@
+// Copyright 2024 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// package complexnum tests complex64 and complex128 values, passed as
+// python complex numbers.
+package complexnum
+
+import (
+	"math"
+	"math/cmplx"
+)
+
+// I is the imaginary unit.
+var I = 1i
+
+// Signal is a sampled signal.
+type Signal struct {
+	Name    string
+	Gain    complex64
+	Samples []complex128
+}
+
+// Level is the complex level of a signal.
+type Level complex128
+
+// NewSignal returns a new signal of the samples.
+func NewSignal(name string, samples ...complex128) *Signal {
+	return &Signal{Name: name, Gain: 1, Samples: samples}
+}
+
+// Sum returns the sum of the samples, scaled by the gain.
+func (s *Signal) Sum() complex128 {
+	var sum complex128
+	for _, z := range s.Samples {
+		sum += z
+	}
+	return sum * complex128(s.Gain)
+}
+
+// Add returns a+b.
+func Add(a, b complex64) complex64 {
+	return a + b
+}
+
+// Roots returns the n-th roots of unity.
+func Roots(n int) []complex128 {
+	roots := make([]complex128, n)
+	for k := range roots {
+		roots[k] = cmplx.Rect(1, 2*math.Pi*float64(k)/float64(n))
+	}
+	return roots
+}
+
+// Abs returns the absolute values of the values.
+func Abs(zs []complex64) []float64 {
+	abs := make([]float64, len(zs))
+	for i, z := range zs {
+		abs[i] = cmplx.Abs(complex128(z))
+	}
+	return abs
+}
+
+// Half returns half the level.
+func Half(l Level) Level {
+	return l / 2
+}
+
+// Apply returns f applied to z.
+func Apply(f func(z complex128) complex128, z complex128) complex128 {
+	return f(z)
+}
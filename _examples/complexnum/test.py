@@ -0,0 +1,36 @@
+# Copyright 2024 The go-python Authors.  All rights reserved.
+# Use of this source code is governed by a BSD-style
+# license that can be found in the LICENSE file.
+
+from __future__ import print_function
+
+import complexnum, go
+
+def r(z):
+    return complex(round(z.real, 6) + 0.0, round(z.imag, 6) + 0.0)
+
+print("I:", complexnum.I())
+print("Add(1+2j, 3-1j):", complexnum.Add(1+2j, 3-1j))
+print("Add(1, 0.5):", complexnum.Add(1, 0.5))
+print("Roots(4):", [r(z) for z in complexnum.Roots(4)])
+print("Abs:", list(complexnum.Abs([3+4j, -5j])))
+print("Half:", complexnum.Half(2+4j))
+
+s = complexnum.NewSignal("s", 1+1j, 2-1j)
+print("s.Samples:", list(s.Samples))
+print("s.Gain:", s.Gain)
+s.Gain = 2j
+print("s.Sum():", s.Sum())
+s.Samples = go.Slice_complex128([1j, 1j, 1j])
+print("s.Sum() after:", s.Sum())
+s.Samples[0] = 5
+print("s.Samples:", s.Samples.to_list())
+
+print("Apply:", complexnum.Apply(lambda z: z * z, 1+1j))
+
+try:
+    complexnum.Add("x", 1)
+except TypeError:
+    print("TypeError for str")
+
+print("OK")
@@ -0,0 +1,80 @@
+// Copyright 2024 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// package compound tests deeply composed container types and channels.
+package compound
+
+// T is a struct stored in the containers and sent on the channels
+type T struct {
+	Name string
+}
+
+// Groups returns a map of slices of pointers to Ts, with a nil one
+func Groups() map[string][]*T {
+	return map[string][]*T{"a": {{"a1"}, nil}, "b": {{"b1"}}}
+}
+
+// Count returns the number of Ts of the groups, including the nil ones
+func Count(m map[string][]*T) int {
+	n := 0
+	for _, v := range m {
+		n += len(v)
+	}
+	return n
+}
+
+// Records returns a slice of maps of Ts
+func Records() []map[string]T {
+	return []map[string]T{{"x": {"x"}}, {"y": {"y"}, "z": {"z"}}}
+}
+
+// Size returns the number of Ts of the records
+func Size(recs []map[string]T) int {
+	n := 0
+	for _, r := range recs {
+		n += len(r)
+	}
+	return n
+}
+
+// Deep returns a map of maps of slices of ints
+func Deep() map[string]map[string][]int {
+	return map[string]map[string][]int{"o": {"i": {1, 2}}}
+}
+
+// Cube returns a slice of slices of slices of floats
+func Cube() [][][]float64 {
+	return [][][]float64{{{1, 2}, {3}}, {{4}}}
+}
+
+// Produce returns a closed channel of the Ts of the names
+func Produce(names ...string) <-chan T {
+	ch := make(chan T, len(names))
+	for _, nm := range names {
+		ch <- T{nm}
+	}
+	close(ch)
+	return ch
+}
+
+// Consume receives the Ts from the channel until it is closed,
+// returning the number of non-nil ones
+func Consume(ch chan *T) int {
+	n := 0
+	for t := range ch {
+		if t != nil {
+			n++
+		}
+	}
+	return n
+}
+
+// Double sends the doubles of the values received from src to dst,
+// until src is closed, then closes dst
+func Double(src <-chan int, dst chan<- int) {
+	for v := range src {
+		dst <- 2 * v
+	}
+	close(dst)
+}
@@ -0,0 +1,44 @@
+# Copyright 2024 The go-python Authors.  All rights reserved.
+# Use of this source code is governed by a BSD-style
+# license that can be found in the LICENSE file.
+
+from __future__ import print_function
+
+import compound
+
+g = compound.Groups()
+print("g['a'][0].Name:", g["a"][0].Name)
+print("g['a'][1]:", g["a"][1])
+print("Count(g):", compound.Count(g))
+print("Count(dict):", compound.Count({"x": [compound.T(Name="x1"), None], "y": []}))
+
+r = compound.Records()
+print("r[1]['z'].Name:", r[1]["z"].Name)
+print("Size(r):", compound.Size(r))
+print("Size(list):", compound.Size([{"a": compound.T(Name="a")}, {}]))
+
+d = compound.Deep()
+print("d['o']['i'].to_list():", d["o"]["i"].to_list())
+print("d.to_dict():", d.to_dict())
+print("Cube().to_list():", compound.Cube().to_list())
+
+p = compound.Produce("a", "b")
+print("Produce names:", [t.Name for t in p])
+print("recv ok after close:", p.recv()[1])
+
+ch = compound.Chan_Ptr_compound_T(3)
+ch.send(compound.T(Name="x"))
+ch.send(None)
+print("len(ch), ch.cap():", len(ch), ch.cap())
+ch.close()
+print("Consume(ch):", compound.Consume(ch))
+
+src = compound.Chan_int(3)
+for i in range(1, 4):
+    src.send(i)
+src.close()
+dst = compound.Chan_int(3)
+compound.Double(src, dst)
+print("Double:", list(dst))
+
+print("OK")
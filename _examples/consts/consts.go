@@ -17,6 +17,19 @@ const (
 	C7 float64 = 666.666
 )
 
+const (
+	// Big exceeds the range of int64.
+	Big   = 1 << 100
+	Pi    = 3.14159265358979323846264338327950288419716939937510582097494459
+	Third = 1.0 / 3
+	Esc   = "tab\tquote\"nl\n\u00e9\x00\U0001F600"
+	Long  = "0123456789 0123456789 0123456789 0123456789 0123456789 0123456789 0123456789 0123456789"
+
+	F8  float64 = 8
+	F32 float32 = 0.1
+	Neg int64   = -1 << 63
+)
+
 type Kind int
 
 const (
@@ -30,3 +43,24 @@ const (
 //  Kind3 kind = 3
 //  Kind4      = 4
 // )
+
+// Perm is a set of permissions, bound as an enum.IntFlag.
+type Perm uint8
+
+const (
+	Read Perm = 1 << iota
+	Write
+	Exec
+
+	ReadWrite = Read | Write
+)
+
+// HasPerm returns whether the permissions p include those of q.
+func HasPerm(p, q Perm) bool {
+	return p&q == q
+}
+
+// AllPerms returns all the permissions.
+func AllPerms() Perm {
+	return Read | Write | Exec
+}
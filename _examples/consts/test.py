@@ -15,8 +15,31 @@ print("c5 = %s" % consts.C5)
 print("c6 = %s" % consts.C6)
 print("c7 = %s" % consts.C7)
 
+print("big = %r" % (consts.Big,))
+print("big exact:", consts.Big == 1 << 100)
+print("pi = %r" % (consts.Pi,))
+print("third = %r" % (consts.Third,))
+print("esc = %r" % (consts.Esc,))
+print("long = %d %s" % (len(consts.Long), consts.Long[-10:]))
+print("f8 = %r" % (consts.F8,))
+print("f32 = %r" % (consts.F32,))
+print("neg = %r" % (consts.Neg,))
+
 print("k1 = %s" % consts.Kind1)
 print("k2 = %s" % consts.Kind2)
+
+rw = consts.Read | consts.Write
+print("rw = %d %s" % (rw, isinstance(rw, consts.Perm)))
+print("rw == ReadWrite:", rw == consts.ReadWrite)
+print("Write in rw:", consts.Write in rw, "Exec in rw:", consts.Exec in rw)
+print("HasPerm(rw, Read):", consts.HasPerm(rw, consts.Read))
+print("HasPerm(rw, Exec):", consts.HasPerm(rw, consts.Perm.Exec))
+print("HasPerm(3, 1):", consts.HasPerm(3, 1))
+all = consts.AllPerms()
+print("AllPerms = %d %s" % (all, isinstance(all, consts.Perm)))
+print("Exec in AllPerms:", consts.Exec in all)
+print("Kind is Enum:", not issubclass(consts.Kind, int))
+
 ## FIXME: unexported types not supported yet (issue #44)
 #print("k3 = %s" % consts.Kind3)
 #print("k4 = %s" % consts.Kind4)
@@ -0,0 +1,48 @@
+// Copyright 2024 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// package copymax tests the slices and maps returned as python lists and
+// dicts when small, and as proxies of the Go values when large, with the
+// -copy-max option.
+package copymax
+
+// Ints is a named slice, always proxied.
+type Ints []int
+
+// Sum returns the sum of the ints.
+func (is Ints) Sum() int {
+	s := 0
+	for _, i := range is {
+		s += i
+	}
+	return s
+}
+
+// Range returns the ints from 0 to n-1.
+func Range(n int) []int {
+	r := make([]int, n)
+	for i := range r {
+		r[i] = i
+	}
+	return r
+}
+
+// Counts returns the counts of the letters of s.
+func Counts(s string) map[string]int {
+	m := make(map[string]int)
+	for _, r := range s {
+		m[string(r)]++
+	}
+	return m
+}
+
+// Bytes returns the bytes of s, always proxied.
+func Bytes(s string) []byte {
+	return []byte(s)
+}
+
+// NamedRange returns the ints from 0 to n-1 as Ints.
+func NamedRange(n int) Ints {
+	return Ints(Range(n))
+}
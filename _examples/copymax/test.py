@@ -0,0 +1,26 @@
+# Copyright 2024 The go-python Authors.  All rights reserved.
+# Use of this source code is governed by a BSD-style
+# license that can be found in the LICENSE file.
+
+from __future__ import print_function
+
+import copymax, go
+
+small = copymax.Range(3)
+print("small:", type(small).__name__, small)
+large = copymax.Range(100)
+print("large:", type(large).__name__, len(large), large[99])
+
+counts = copymax.Counts("abca")
+print("counts:", type(counts).__name__, sorted(counts.items()))
+
+print("bytes:", type(copymax.Bytes("hi")).__name__)
+print("named:", type(copymax.NamedRange(3)).__name__, copymax.NamedRange(3).Sum())
+
+go.copy_max = 0
+print("small, copy_max 0:", type(copymax.Range(3)).__name__)
+go.copy_max = 1000
+print("large, copy_max 1000:", type(copymax.Range(100)).__name__)
+
+print("doc:", "go.copy_max (10)" in copymax.Range.__doc__)
+print("OK")
@@ -0,0 +1,57 @@
+// Copyright 2024 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package ctxmgr tests using types with a Close method as python context
+// managers.
+package ctxmgr
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Open is the number of open resources.
+var Open int
+
+// Resource is a resource that must be closed.
+type Resource struct {
+	Name   string
+	closed bool
+}
+
+// NewResource opens a new resource.
+func NewResource(name string) *Resource {
+	Open++
+	return &Resource{Name: name}
+}
+
+// Use uses the resource.
+func (r *Resource) Use() string {
+	return fmt.Sprintf("using %s", r.Name)
+}
+
+// Close closes the resource, failing if it is already closed.
+func (r *Resource) Close() error {
+	if r.closed {
+		return errors.New("ctxmgr: already closed")
+	}
+	r.closed = true
+	Open--
+	return nil
+}
+
+// Server is a service that must be stopped.
+type Server struct {
+	Running bool
+}
+
+// NewServer starts a new server.
+func NewServer() *Server {
+	return &Server{Running: true}
+}
+
+// Stop stops the server.
+func (s *Server) Stop() {
+	s.Running = false
+}
@@ -0,0 +1,31 @@
+# Copyright 2024 The go-python Authors.  All rights reserved.
+# Use of this source code is governed by a BSD-style
+# license that can be found in the LICENSE file.
+
+from __future__ import print_function
+
+import go, ctxmgr
+
+with ctxmgr.NewResource("db") as r:
+    print(r.Use(), "open:", ctxmgr.Open())
+print("closed, open:", ctxmgr.Open())
+
+try:
+    with ctxmgr.NewResource("file") as r:
+        raise ValueError("oops")
+except ValueError as err:
+    print("caught ValueError:", err, "open:", ctxmgr.Open())
+
+r = ctxmgr.NewResource("twice")
+r.Close()
+try:
+    with r:
+        pass
+except go.GoError as err:
+    print("caught go.GoError:", err)
+
+with ctxmgr.NewServer() as s:
+    print("running:", s.Running)
+print("stopped:", not s.Running)
+
+print("OK")
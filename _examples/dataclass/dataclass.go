@@ -0,0 +1,84 @@
+// Copyright 2024 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// package dataclass tests the binding of the plain value structs as python
+// dataclasses, with the -dataclass option.
+package dataclass
+
+import (
+	"fmt"
+)
+
+// Point is a point of the plane.
+type Point struct {
+	X, Y float64
+}
+
+// Color is a named int.
+type Color int
+
+// Pixel is a colored point.
+type Pixel struct {
+	At    Point
+	Color Color
+	Alpha uint8
+	Label string
+	Mark  rune
+	Next  *Point
+}
+
+// Path has a method, so it is not a dataclass.
+type Path struct {
+	Points []Point
+}
+
+// Len returns the number of points of the path.
+func (p *Path) Len() int {
+	return len(p.Points)
+}
+
+// Origin is the origin of the plane.
+var Origin = Point{}
+
+// NewPixel returns a pixel at x, y.
+func NewPixel(x, y float64, label string) Pixel {
+	return Pixel{At: Point{x, y}, Color: 1, Alpha: 255, Label: label, Mark: '*'}
+}
+
+// Add returns the sum of the points.
+func Add(a, b Point) Point {
+	return Point{a.X + b.X, a.Y + b.Y}
+}
+
+// Describe returns a description of the pixel.
+func Describe(p Pixel) string {
+	next := "nil"
+	if p.Next != nil {
+		next = fmt.Sprintf("%v", *p.Next)
+	}
+	return fmt.Sprintf("%s at %v color %d alpha %d mark %c next %s", p.Label, p.At, p.Color, p.Alpha, p.Mark, next)
+}
+
+// Farthest returns the farthest point from the origin, or nil if there is none.
+func Farthest(pts []Point) *Point {
+	var far *Point
+	for i := range pts {
+		p := &pts[i]
+		if far == nil || p.X*p.X+p.Y*p.Y > far.X*far.X+far.Y*far.Y {
+			far = p
+		}
+	}
+	return far
+}
+
+// Shift moves the point in place, which python does not see, as it is a copy.
+func Shift(p *Point, d float64) {
+	p.X += d
+	p.Y += d
+}
+
+// NewPath returns a path of the points.
+func NewPath(pts ...Point) *Path {
+	return &Path{Points: pts}
+}
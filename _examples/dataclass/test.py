@@ -0,0 +1,47 @@
+# Copyright 2024 The go-python Authors.  All rights reserved.
+# Use of this source code is governed by a BSD-style
+# license that can be found in the LICENSE file.
+
+from __future__ import print_function
+
+import dataclasses
+import dataclass, go
+
+print("is_dataclass(Point):", dataclasses.is_dataclass(dataclass.Point))
+print("Point():", dataclass.Point())
+print("Add:", dataclass.Add(dataclass.Point(1, 2), dataclass.Point(3, 4.5)))
+
+px = dataclass.NewPixel(1, 2, "dot")
+print("NewPixel:", px)
+px.Next = dataclass.Point(5, 6)
+px.Mark = 'é'
+print("Describe:", dataclass.Describe(px))
+print("Pixel() == Pixel():", dataclass.Pixel() == dataclass.Pixel())
+
+print("Origin:", dataclass.Origin())
+dataclass.Set_Origin(dataclass.Point(7, 8))
+print("Origin after:", dataclass.Origin())
+
+pts = [dataclass.Point(1, 1), dataclass.Point(3, 4), dataclass.Point(-2, 0)]
+print("Farthest:", dataclass.Farthest(pts))
+print("Farthest([]):", dataclass.Farthest([]))
+
+pt = dataclass.Point(1, 1)
+dataclass.Shift(pt, 1)
+print("Shift copy:", pt)
+
+path = dataclass.NewPath(dataclass.Point(0, 1), dataclass.Point(2, 3))
+print("path.Len():", path.Len())
+print("path.Points[1]:", path.Points[1])
+path.Points[1] = dataclass.Point(9, 9)
+print("path.Points:", list(path.Points))
+
+px.Alpha = 300
+try:
+    dataclass.Describe(px)
+except OverflowError as e:
+    print("OverflowError:", e)
+
+print("registry:", dataclass.__go_types__["dataclass.Point"].kind, dataclass.__go_types__["dataclass.Path"].kind)
+
+print("OK")
@@ -0,0 +1,42 @@
+// Copyright 2024 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// package durations tests time.Duration bound as go.Duration, with the
+// -no-timedelta option.
+package durations
+
+import "time"
+
+// Timeout is the default timeout.
+var Timeout = 90 * time.Second
+
+// Task is a task taking some time.
+type Task struct {
+	Name string
+	Len  time.Duration
+}
+
+// Total returns the total length of the tasks.
+func Total(tasks ...*Task) time.Duration {
+	var d time.Duration
+	for _, t := range tasks {
+		d += t.Len
+	}
+	return d
+}
+
+// Scale returns d scaled by n, in Go.
+func Scale(d time.Duration, n int64) time.Duration {
+	return d * time.Duration(n)
+}
+
+// Div returns d divided by n, in Go.
+func Div(d time.Duration, n int64) time.Duration {
+	return d / time.Duration(n)
+}
+
+// Nanos returns d in nanoseconds.
+func Nanos(d time.Duration) int64 {
+	return int64(d)
+}
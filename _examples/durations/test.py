@@ -0,0 +1,42 @@
+# Copyright 2024 The go-python Authors.  All rights reserved.
+# Use of this source code is governed by a BSD-style
+# license that can be found in the LICENSE file.
+
+from __future__ import print_function
+
+import datetime
+import durations, go
+
+t = durations.Timeout()
+print("Timeout:", t, repr(t))
+print("isinstance int:", isinstance(t, int))
+
+a = durations.Task("a", 90 * go.Minute)
+b = durations.Task("b", go.Duration.parse("1.5s"))
+print("a.Len:", a.Len)
+print("Total:", durations.Total(a, b))
+print("Nanos(1ms):", durations.Nanos(go.Millisecond))
+
+d = go.Duration.parse("-7ns")
+print("-7ns / 2:", d / 2, "Div:", durations.Div(d, 2))
+print("-7ns % 2:", d % 2)
+print("1h / 7m:", go.Hour / (7 * go.Minute))
+print("Hour * 3:", go.Hour * 3, "Scale:", durations.Scale(go.Hour, 3))
+big = go.Duration((1 << 63) - 1)
+print("max + 1:", big + 1)
+print("max * 2 == Scale:", big * 2 == durations.Scale(big, 2))
+print("compare:", go.Second < go.Minute, go.Minute == 60 * go.Second, sorted([go.Hour, go.Second])[0])
+print("hours:", (90 * go.Minute).hours())
+print("round:", go.Duration.parse("1h15m30.918273645s").round(go.Millisecond))
+print("truncate:", go.Duration.parse("1h15m30.918273645s").truncate(go.Second))
+print("to_timedelta:", repr(go.Duration.parse("1m0.0000015s").to_timedelta()))
+print("from_timedelta:", go.Duration.from_timedelta(datetime.timedelta(hours=1, microseconds=5)))
+try:
+    go.Duration.parse("1 hour")
+except ValueError as err:
+    print("ValueError:", err)
+
+a.Len = 2 * go.Hour
+print("a.Len:", a.Len)
+
+print("OK")
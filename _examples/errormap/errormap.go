@@ -0,0 +1,64 @@
+// Copyright 2024 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// package errormap tests raising Go errors as python builtin exceptions,
+// with the //gopy:raises directive and the -errors option.
+package errormap
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+)
+
+// ErrNoKey is returned for missing keys.
+//
+//gopy:raises KeyError
+var ErrNoKey = errors.New("no such key")
+
+// ErrClosed is returned for closed stores.
+var ErrClosed = errors.New("store closed")
+
+// Get returns the value of the key.
+func Get(key string) (string, error) {
+	if key == "gopher" {
+		return "go", nil
+	}
+	return "", fmt.Errorf("get %q: %w", key, ErrNoKey)
+}
+
+// Close returns ErrClosed.
+func Close() error {
+	return ErrClosed
+}
+
+// Read returns the content of the file.
+func Read(path string) (string, error) {
+	b, err := os.ReadFile(path)
+	return string(b), err
+}
+
+// Atoi returns the int of s.
+func Atoi(s string) (int, error) {
+	return strconv.Atoi(s)
+}
+
+// Wait waits for the context for at most d.
+func Wait(d time.Duration) error {
+	ctx, cancel := context.WithTimeout(context.Background(), d)
+	defer cancel()
+	<-ctx.Done()
+	return fmt.Errorf("wait: %w", ctx.Err())
+}
+
+// Check returns an invalid argument error if n is negative.
+func Check(n int) error {
+	if n < 0 {
+		return fmt.Errorf("invalid argument %d", n)
+	}
+	return nil
+}
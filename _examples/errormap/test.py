@@ -0,0 +1,28 @@
+# Copyright 2024 The go-python Authors.  All rights reserved.
+# Use of this source code is governed by a BSD-style
+# license that can be found in the LICENSE file.
+
+from __future__ import print_function
+
+import datetime
+import errormap, go
+
+for exc, fn, args in [
+    (KeyError, errormap.Get, ("python",)),
+    (FileNotFoundError, errormap.Read, ("/no/such/file",)),
+    (ValueError, errormap.Atoi, ("x",)),
+    (TimeoutError, errormap.Wait, (datetime.timedelta(microseconds=1),)),
+    (ValueError, errormap.Check, (-1,)),
+    (go.GoError, errormap.Close, ()),
+]:
+    try:
+        fn(*args)
+        print("no error")
+    except exc as err:
+        print("%s is %s: %s (GoError: %s)" % (type(err).__name__, exc.__name__, err, isinstance(err, go.GoError)))
+
+print("Get(gopher):", errormap.Get("gopher"))
+errormap.Check(1)
+print("Check(1): no error")
+
+print("OK")
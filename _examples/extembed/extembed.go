@@ -0,0 +1,47 @@
+// Copyright 2024 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// package extembed tests structs embedding types of packages that are not
+// bound.
+package extembed
+
+import (
+	"strings"
+	"sync"
+)
+
+// Doc embeds a strings.Builder, whose methods it exposes
+type Doc struct {
+	strings.Builder
+	Title string
+}
+
+// NewDoc returns a new doc
+func NewDoc(title string) *Doc {
+	return &Doc{Title: title}
+}
+
+// Heading returns the heading of the doc
+func (d *Doc) Heading() string {
+	return "# " + d.Title
+}
+
+// Counter embeds a mutex, by pointer
+type Counter struct {
+	*sync.Mutex
+	N int
+}
+
+// NewCounter returns a new counter
+func NewCounter() *Counter {
+	return &Counter{Mutex: &sync.Mutex{}}
+}
+
+// Incr increments the counter
+func (c *Counter) Incr() int {
+	c.Lock()
+	defer c.Unlock()
+	c.N++
+	return c.N
+}
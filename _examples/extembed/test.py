@@ -0,0 +1,26 @@
+# Copyright 2024 The go-python Authors.  All rights reserved.
+# Use of this source code is governed by a BSD-style
+# license that can be found in the LICENSE file.
+
+from __future__ import print_function
+
+import extembed
+
+d = extembed.NewDoc("notes")
+print("d.Heading():", d.Heading())
+d.WriteString("hello ")
+d.WriteString("world")
+print("d.Len():", d.Len())
+print("d.String():", d.String())
+print("str(d):", str(d))
+d.Reset()
+print("d.Len() after Reset:", d.Len())
+
+c = extembed.NewCounter()
+print("c.Incr():", c.Incr())
+print("c.TryLock():", c.TryLock())
+c.Unlock()
+print("c.Incr():", c.Incr())
+print("c.N:", c.N)
+
+print("OK")
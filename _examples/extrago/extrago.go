@@ -0,0 +1,20 @@
+// Copyright 2024 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// package extrago tests binding functions the generator cannot handle
+// with hand-written shims, from the Go files of the -extra-go directory.
+package extrago
+
+import "fmt"
+
+// DivMod returns the quotient and remainder of a / b: its two results
+// are not supported by the generator, see shims/shims.go.
+func DivMod(a, b int) (int, int) {
+	return a / b, a % b
+}
+
+// Greet returns a greeting.
+func Greet(name string) string {
+	return fmt.Sprintf("hello, %s", name)
+}
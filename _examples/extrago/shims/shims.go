@@ -0,0 +1,56 @@
+// Copyright 2024 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// The shims of the extrago bindings, copied into the generated main
+// package with gopy gen -extra-go=shims.
+
+package main
+
+/*
+#include <Python.h>
+#include <stdlib.h>
+*/
+import "C"
+
+import (
+	"strings"
+	"unsafe"
+
+	"github.com/go-python/gopy/_examples/extrago"
+	"github.com/go-python/gopy/gopyh"
+)
+
+// DivMod returns the (quotient, remainder) tuple of a / b.
+//
+//export DivMod
+func DivMod(a, b C.longlong) *C.PyObject {
+	if b == 0 {
+		msg := C.CString("division by zero")
+		C.PyErr_SetString(C.PyExc_ZeroDivisionError, msg)
+		C.free(unsafe.Pointer(msg))
+		return nil
+	}
+	q, r := extrago.DivMod(int(a), int(b))
+	tup := C.PyTuple_New(2)
+	C.PyTuple_SetItem(tup, 0, C.PyLong_FromLongLong(C.longlong(q)))
+	C.PyTuple_SetItem(tup, 1, C.PyLong_FromLongLong(C.longlong(r)))
+	return tup
+}
+
+// Shout returns the greeting of extrago.Greet in upper case.
+//
+//export Shout
+func Shout(name *C.char) *C.char {
+	return C.CString(strings.ToUpper(extrago.Greet(C.GoString(name))))
+}
+
+// NumBound returns the number of handles of bound Go values.
+//
+//export NumBound
+func NumBound() C.int {
+	return C.int(gopyh.NumHandles())
+}
+
+// unbound is not exported, so not bound
+func unbound() {}
@@ -0,0 +1,23 @@
+# Copyright 2024 The go-python Authors.  All rights reserved.
+# Use of this source code is governed by a BSD-style
+# license that can be found in the LICENSE file.
+
+from __future__ import print_function
+
+import go, extrago
+
+print("extrago.Greet:", extrago.Greet("gopher"))
+print("extrago.DivMod:", extrago.DivMod(17, 5))
+print("go.DivMod:", go.DivMod(-7, 2))
+print("extrago.Shout:", extrago.Shout("gopher"))
+print("extrago.NumBound:", extrago.NumBound() >= 0)
+print("doc:", extrago.DivMod.__doc__)
+
+try:
+    extrago.DivMod(1, 0)
+except ZeroDivisionError as err:
+    print("caught ZeroDivisionError:", err)
+
+print("has unbound:", hasattr(go, "unbound"))
+
+print("OK")
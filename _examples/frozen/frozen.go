@@ -0,0 +1,37 @@
+// Copyright 2024 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// package frozen tests the frozen structs, immutable after construction,
+// shared by python threads without locks.
+package frozen
+
+import "fmt"
+
+// Point is an immutable point.
+//
+//gopy:frozen
+type Point struct {
+	X, Y int
+}
+
+// String returns the point as (x, y).
+func (p *Point) String() string {
+	return fmt.Sprintf("(%d, %d)", p.X, p.Y)
+}
+
+// Moved returns a new point, moved by dx and dy.
+func (p *Point) Moved(dx, dy int) *Point {
+	return &Point{X: p.X + dx, Y: p.Y + dy}
+}
+
+// Settings are set once, frozen by the -frozen option of the test.
+type Settings struct {
+	Name  string
+	Limit int
+}
+
+// Cursor is a mutable position.
+type Cursor struct {
+	Pos int
+}
@@ -0,0 +1,59 @@
+# Copyright 2024 The go-python Authors.  All rights reserved.
+# Use of this source code is governed by a BSD-style
+# license that can be found in the LICENSE file.
+
+from __future__ import print_function
+
+import threading
+
+import frozen, go
+
+p = frozen.Point(1, Y=2)
+print("Point:", p, p.X, p.Y)
+try:
+    p.X = 5
+except AttributeError:
+    print("AttributeError on Point.X")
+print("hasattr update:", hasattr(p, "update"))
+print("fields:", p.fields())
+print("Moved:", p.Moved(1, 1), p)
+
+s = frozen.Settings(Name="svc", Limit=3)
+try:
+    s.Limit = 4
+except AttributeError:
+    print("AttributeError on Settings.Limit")
+print("Settings:", s.Name, s.Limit)
+
+# frozen values are not thread checked, and shared without locks
+seen = []
+
+
+def read():
+    seen.append((p.X, p.Y, s.Limit))
+
+
+threads = [threading.Thread(target=read) for _ in range(4)]
+for t in threads:
+    t.start()
+for t in threads:
+    t.join()
+print("seen:", seen)
+
+c = frozen.Cursor(Pos=1)
+c.Pos = 2
+
+
+def move():
+    try:
+        c.Pos = 3
+    except go.ThreadError:
+        print("ThreadError on Cursor.Pos")
+
+
+t = threading.Thread(target=move)
+t.start()
+t.join()
+print("Cursor:", c.Pos)
+
+print("OK")
@@ -0,0 +1,69 @@
+// Copyright 2024 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// package genapp tests the generated sample python application (-gen-app),
+// as by gopy quickstart.
+package genapp
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Version is a string constant.
+const Version = "1.0"
+
+// Level is an enum.
+type Level int
+
+const (
+	Low Level = iota
+	High
+)
+
+// Hits is an int variable.
+var Hits = 7
+
+// Shape is implemented by Rect.
+type Shape interface {
+	Area() int
+}
+
+// Rect is a struct.
+type Rect struct {
+	W, H int
+}
+
+// Area returns the area of the rectangle.
+func (r *Rect) Area() int {
+	return r.W * r.H
+}
+
+// Counter is a struct not safe for concurrent use.
+//
+//gopy:guarded
+type Counter struct {
+	N int
+}
+
+// Task has a field not marshaled by encoding/json, so it is not picklable.
+type Task struct {
+	Name string
+	Run  func()
+}
+
+// Unit returns the unit rectangle.
+func Unit() Rect {
+	return Rect{W: 1, H: 1}
+}
+
+// Describe describes the unit rectangle.
+func Describe() string {
+	return fmt.Sprintf("unit rect of area %d", Hits/Hits)
+}
+
+// Broken always returns an error.
+func Broken() error {
+	return errors.New("broken")
+}
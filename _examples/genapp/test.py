@@ -0,0 +1,24 @@
+# Copyright 2024 The go-python Authors.  All rights reserved.
+# Use of this source code is governed by a BSD-style
+# license that can be found in the LICENSE file.
+
+# runs the generated sample application, app.py, checking that each demo
+# runs without raising
+
+from __future__ import print_function
+
+import contextlib, io
+
+import app
+
+out = io.StringIO()
+with contextlib.redirect_stdout(out), contextlib.redirect_stderr(out):
+    app.main()
+
+for line in out.getvalue().splitlines():
+    if line.startswith("== ") or line.startswith("not picklable") or "raised" in line:
+        print(line)
+    if line.startswith("Traceback"):
+        print("unexpected:", line)
+
+print("OK")
@@ -0,0 +1,63 @@
+// Copyright 2024 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// package gendocs tests the generated API reference docs (-doc).
+package gendocs
+
+import "errors"
+
+// Answer is the answer to everything.
+const Answer = 42
+
+// Verbose enables verbose output.
+var Verbose = false
+
+// ErrEmpty is returned for empty names.
+var ErrEmpty = errors.New("empty name")
+
+// Shape has an area.
+type Shape interface {
+	// Area returns the area of the shape.
+	Area() float64
+}
+
+// Rect is a rectangle.
+type Rect struct {
+	// W is the width.
+	W float64
+	H float64 // H is the height.
+}
+
+// NewRect returns a new rectangle of the given width and height.
+func NewRect(w, h float64) *Rect {
+	return &Rect{W: w, H: h}
+}
+
+// Area returns the area of the rectangle.
+func (r *Rect) Area() float64 {
+	return r.W * r.H
+}
+
+// Scale scales the rectangle by f.
+func (r *Rect) Scale(f float64) {
+	r.W *= f
+	r.H *= f
+}
+
+// Greet returns a greeting for name.
+func Greet(name string) (string, error) {
+	if name == "" {
+		return "", ErrEmpty
+	}
+	return "hello " + name, nil
+}
+
+// Sum returns the sum of the values.
+func Sum(vals ...int) int {
+	s := 0
+	for _, v := range vals {
+		s += v
+	}
+	return s
+}
@@ -0,0 +1,19 @@
+# Copyright 2024 The go-python Authors.  All rights reserved.
+# Use of this source code is governed by a BSD-style
+# license that can be found in the LICENSE file.
+
+# prints the generated API reference docs in docs/
+
+from __future__ import print_function
+
+import os
+
+import gendocs
+
+docs = os.path.join(os.path.dirname(os.path.abspath(__file__)), "docs")
+print("docs:", sorted(os.listdir(docs)))
+
+with open(os.path.join(docs, "gendocs.rst")) as f:
+    print(f.read())
+
+print("OK")
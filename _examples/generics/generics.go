@@ -0,0 +1,110 @@
+// Copyright 2024 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// package generics tests the binding of the instantiations of generic
+// types and functions.
+package generics
+
+import "fmt"
+
+// Stack is a generic stack
+//
+//gopy:instantiate Stack[string]
+type Stack[T any] struct {
+	Items []T
+	Name  string
+}
+
+// NewStack returns a new named stack
+//
+//gopy:instantiate NewStack[float64]
+func NewStack[T any](name string) *Stack[T] {
+	return &Stack[T]{Name: name}
+}
+
+// Push pushes a value on the stack
+func (s *Stack[T]) Push(v T) {
+	s.Items = append(s.Items, v)
+}
+
+// Len returns the number of values on the stack
+func (s *Stack[T]) Len() int {
+	return len(s.Items)
+}
+
+// Pop pops the last value pushed on the stack
+func (s *Stack[T]) Pop() (T, error) {
+	var v T
+	if len(s.Items) == 0 {
+		return v, fmt.Errorf("generics: empty stack %s", s.Name)
+	}
+	v = s.Items[len(s.Items)-1]
+	s.Items = s.Items[:len(s.Items)-1]
+	return v, nil
+}
+
+// NewIntStack returns a new int stack, binding Stack[int]
+func NewIntStack() *Stack[int] {
+	return &Stack[int]{Name: "ints"}
+}
+
+// Pair is a key and a value
+type Pair[K comparable, V any] struct {
+	Key K
+	Val V
+}
+
+// String returns the pair as a string
+func (p Pair[K, V]) String() string {
+	return fmt.Sprintf("%v=%v", p.Key, p.Val)
+}
+
+// MakePair returns a pair of a string and an int, binding Pair[string, int]
+func MakePair(k string, v int) Pair[string, int] {
+	return Pair[string, int]{Key: k, Val: v}
+}
+
+// Point is a point
+type Point struct {
+	X, Y int
+}
+
+// Points returns a stack of points, binding Stack[Point]
+func Points() *Stack[Point] {
+	return &Stack[Point]{Name: "points", Items: []Point{{1, 2}, {3, 4}}}
+}
+
+// Number is the constraint of the numbers of Sum
+type Number interface {
+	~int | ~int64 | ~float64
+}
+
+// Sum returns the sum of the values
+//
+//gopy:instantiate Sum[int]
+//gopy:instantiate Sum[float64]
+func Sum[T Number](vs []T) T {
+	var s T
+	for _, v := range vs {
+		s += v
+	}
+	return s
+}
+
+// Swap returns the pair with the key and value swapped
+//
+//gopy:instantiate Swap[string, int]
+func Swap[K, V comparable](p Pair[K, V]) Pair[V, K] {
+	return Pair[V, K]{Key: p.Val, Val: p.Key}
+}
+
+// Map maps the values with the function, and is not bound, as it is
+// not instantiated
+func Map[T, U any](vs []T, f func(T) U) []U {
+	us := make([]U, len(vs))
+	for i, v := range vs {
+		us[i] = f(v)
+	}
+	return us
+}
@@ -0,0 +1,48 @@
+# Copyright 2024 The go-python Authors.  All rights reserved.
+# Use of this source code is governed by a BSD-style
+# license that can be found in the LICENSE file.
+
+from __future__ import print_function
+
+import generics, go
+
+# Stack[int] is bound as it is returned by NewIntStack
+s = generics.NewIntStack()
+s.Push(1)
+s.Push(2)
+s.Push(3)
+print("s.Name:", s.Name)
+print("s.Len():", s.Len())
+print("s.Pop():", s.Pop())
+print("s.Items:", list(s.Items))
+
+# Stack[string] is bound by an instantiate directive
+ss = generics.Stack_string(Name="strs")
+ss.Push("a")
+print("ss.Pop():", ss.Pop())
+try:
+    ss.Pop()
+except Exception as e:
+    print("ss.Pop() raised:", e)
+
+fs = generics.NewStack_float64("floats")
+fs.Push(1.5)
+print("fs:", fs.Name, fs.Len(), type(fs).__name__)
+
+ps = generics.Points()
+print("ps.Pop().X:", ps.Pop().X)
+
+p = generics.MakePair("a", 1)
+print("p:", p.String(), type(p).__name__)
+q = generics.Swap_string_int(p)
+print("q:", q.Key, q.Val, type(q).__name__)
+
+print("Sum_int:", generics.Sum_int(go.Slice_int([1, 2, 3])))
+print("Sum_float64:", generics.Sum_float64(go.Slice_float64([0.5, 0.25])))
+
+print("has Stack:", hasattr(generics, "Stack"))
+print("has Sum:", hasattr(generics, "Sum"))
+print("has Map:", hasattr(generics, "Map"))
+print("has Number:", hasattr(generics, "Number"))
+
+print("OK")
@@ -0,0 +1,69 @@
+// Copyright 2024 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// package genhelpers tests the automatic instantiations of generic helper
+// functions constrained to basic types, like min, max and clamp.
+package genhelpers
+
+import "cmp"
+
+// Max returns the largest of the values
+func Max[T cmp.Ordered](a, b T) T {
+	if a < b {
+		return b
+	}
+	return a
+}
+
+// Min returns the smallest of the values
+func Min[T cmp.Ordered](a, b T) T {
+	if b < a {
+		return b
+	}
+	return a
+}
+
+// Clamp returns the value limited to the range [lo, hi]
+func Clamp[T cmp.Ordered](v, lo, hi T) T {
+	return Min(Max(v, lo), hi)
+}
+
+// Integer is the constraint of integers
+type Integer interface {
+	~int | ~int8 | ~int16 | ~int32 | ~int64
+}
+
+// Abs returns the absolute value, and is only instantiated with int
+func Abs[T Integer](v T) T {
+	if v < 0 {
+		return -v
+	}
+	return v
+}
+
+// Scale scales the value by the factor, instantiated with all the
+// combinations of int and float64
+func Scale[V, F int | float64](v V, f F) float64 {
+	return float64(v) * float64(f)
+}
+
+// Equal returns whether the values are equal, and is not instantiated, as
+// comparable does not restrict the type args to basic types
+func Equal[T comparable](a, b T) bool {
+	return a == b
+}
+
+// Larger returns the largest of the values, only instantiated with int
+//
+//gopy:instantiate Larger[int]
+func Larger[T cmp.Ordered](a, b T) T {
+	return Max(a, b)
+}
+
+// Smaller is skipped, as it is not wanted
+//
+//gopy:skip
+func Smaller[T cmp.Ordered](a, b T) T {
+	return Min(a, b)
+}
@@ -0,0 +1,23 @@
+# Copyright 2024 The go-python Authors.  All rights reserved.
+# Use of this source code is governed by a BSD-style
+# license that can be found in the LICENSE file.
+
+from __future__ import print_function
+
+import genhelpers
+
+print("Max_int:", genhelpers.Max_int(1, 2))
+print("Max_float64:", genhelpers.Max_float64(1.5, 0.5))
+print("Max_string:", genhelpers.Max_string("abc", "abd"))
+print("Min_int:", genhelpers.Min_int(1, 2))
+print("Clamp_int:", genhelpers.Clamp_int(15, 0, 10))
+print("Clamp_float64:", genhelpers.Clamp_float64(-1.5, 0.5, 2.0))
+print("Clamp_string:", genhelpers.Clamp_string("m", "a", "k"))
+print("Abs_int:", genhelpers.Abs_int(-3))
+print("Scale_int_float64:", genhelpers.Scale_int_float64(3, 0.5))
+print("Larger_int:", genhelpers.Larger_int(3, 4))
+
+for nm in ["Abs_float64", "Abs_string", "Equal_int", "Larger_float64", "Smaller_int", "Max"]:
+    print("has %s:" % nm, hasattr(genhelpers, nm))
+
+print("OK")
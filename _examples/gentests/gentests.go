@@ -0,0 +1,62 @@
+// Copyright 2024 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// package gentests tests the generated pytest smoke tests (-gen-tests).
+package gentests
+
+import "errors"
+
+// Answer is the answer.
+const Answer = 42
+
+// Name is a string constant.
+const Name = "gentests"
+
+// Enabled is a bool constant.
+const Enabled = true
+
+// Color is an enum.
+type Color int
+
+const (
+	Red Color = iota
+	Green
+	Blue
+)
+
+var (
+	// Count is an int variable.
+	Count = 3
+
+	// Label is a string variable.
+	Label = "label"
+
+	// Default is a struct variable.
+	Default = Point{X: 1, Y: 2}
+)
+
+// Point is a struct.
+type Point struct {
+	X, Y int
+}
+
+// Origin returns the origin.
+func Origin() Point {
+	return Point{}
+}
+
+// Reset resets Count.
+func Reset() {
+	Count = 0
+}
+
+// Fail always returns an error.
+func Fail() error {
+	return errors.New("failed")
+}
+
+// Scale takes an arg, so it is not called by the smoke tests.
+func Scale(p Point, f int) Point {
+	panic("not called")
+}
@@ -0,0 +1,21 @@
+# Copyright 2024 The go-python Authors.  All rights reserved.
+# Use of this source code is governed by a BSD-style
+# license that can be found in the LICENSE file.
+
+# runs the generated smoke tests in tests/ without requiring pytest
+
+from __future__ import print_function
+
+import os, sys
+
+sys.path.insert(0, os.path.join(os.path.dirname(os.path.abspath(__file__)), "tests"))
+
+import conftest
+import test_gentests
+
+for name in sorted(dir(test_gentests)):
+    if name.startswith("test_"):
+        getattr(test_gentests, name)()
+        print(name, "passed")
+
+print("OK")
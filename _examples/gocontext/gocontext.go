@@ -0,0 +1,46 @@
+// Copyright 2024 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// package gocontext tests passing a context.Context from python,
+// with python-side cancellation and timeouts.
+package gocontext
+
+import (
+	"context"
+	"time"
+)
+
+// Sleep sleeps for the given number of milliseconds,
+// or until ctx is done, in which case it returns the context error.
+func Sleep(ctx context.Context, ms int) error {
+	select {
+	case <-time.After(time.Duration(ms) * time.Millisecond):
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Done returns true if ctx is done.
+func Done(ctx context.Context) bool {
+	return ctx.Err() != nil
+}
+
+// Counter counts steps.
+type Counter struct {
+	Steps int
+}
+
+// Run runs n steps of 1 millisecond each, stopping early when ctx is done,
+// and returns the total number of steps.
+func (c *Counter) Run(ctx context.Context, n int) int {
+	for i := 0; i < n; i++ {
+		if ctx.Err() != nil {
+			break
+		}
+		time.Sleep(time.Millisecond)
+		c.Steps++
+	}
+	return c.Steps
+}
@@ -0,0 +1,50 @@
+# Copyright 2024 The go-python Authors.  All rights reserved.
+# Use of this source code is governed by a BSD-style
+# license that can be found in the LICENSE file.
+
+from __future__ import print_function
+
+import concurrent.futures
+
+import go, gocontext
+
+print("gocontext.Done():", gocontext.Done())
+print("gocontext.Done(background):", gocontext.Done(go.Context.background()))
+
+try:
+    gocontext.Sleep(10)
+    print("gocontext.Sleep(10): ok")
+except Exception as err:
+    print("unexpected error:", err)
+
+ctx = go.Context.with_cancel()
+print("ctx.err() before cancel:", repr(ctx.err()))
+ctx.cancel()
+print("ctx.err() after cancel:", ctx.err())
+print("gocontext.Done(ctx):", gocontext.Done(ctx=ctx))
+
+ctx = go.Context.with_timeout(0.05)
+try:
+    gocontext.Sleep(10000, ctx)
+    print("no timeout")
+except Exception as err:
+    print("caught:", err)
+    print("timeout error:", isinstance(err, TimeoutError), isinstance(err, go.GoError))
+
+ctx = go.Context.with_cancel()
+ctx.cancel()
+try:
+    gocontext.Sleep(10000, ctx)
+    print("not cancelled")
+except concurrent.futures.CancelledError as err:
+    print("cancelled:", type(err).__name__, err)
+
+with go.Context.with_cancel() as ctx:
+    child = go.Context.with_timeout(60, parent=ctx)
+    c = gocontext.Counter()
+    print("c.Run(3, ctx=child):", c.Run(3, ctx=child))
+print("child.err() after with:", child.err())
+print("c.Run(3, ctx=child) after with:", c.Run(3, ctx=child))
+print("c.Run(2):", c.Run(2))
+
+print("OK")
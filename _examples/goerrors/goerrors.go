@@ -0,0 +1,79 @@
+// Copyright 2024 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// package goerrors tests the mapping of Go sentinel errors and
+// error types to python exception classes.
+package goerrors
+
+import (
+	"errors"
+	"fmt"
+)
+
+var (
+	// ErrNotFound is returned when a name is not found.
+	ErrNotFound = errors.New("not found")
+
+	// ErrPermission is returned when access is denied.
+	ErrPermission = errors.New("permission denied")
+)
+
+// PathError records an error and the operation and path that caused it.
+type PathError struct {
+	Op   string
+	Path string
+	Err  error
+}
+
+func (e *PathError) Error() string {
+	return e.Op + " " + e.Path + ": " + e.Err.Error()
+}
+
+func (e *PathError) Unwrap() error {
+	return e.Err
+}
+
+// Code is an error code.
+type Code int
+
+func (c Code) Error() string {
+	return fmt.Sprintf("error code %d", int(c))
+}
+
+// Find returns the index of name, wrapping ErrNotFound if it is unknown.
+func Find(name string) (int, error) {
+	if name == "gopher" {
+		return 42, nil
+	}
+	return 0, fmt.Errorf("find %q: %w", name, ErrNotFound)
+}
+
+// Open returns a *PathError wrapping ErrPermission.
+func Open(path string) error {
+	return &PathError{Op: "open", Path: path, Err: ErrPermission}
+}
+
+// Load returns an error wrapping a *PathError wrapping ErrPermission,
+// raised with the chain of their exceptions.
+func Load(path string) error {
+	return fmt.Errorf("load config: %w", Open(path))
+}
+
+// Check returns ErrPermission if ok is false.
+func Check(ok bool) error {
+	if !ok {
+		return ErrPermission
+	}
+	return nil
+}
+
+// Fail returns the given error code.
+func Fail(code int) error {
+	return Code(code)
+}
+
+// Other returns an error that does not match any of the package errors.
+func Other() error {
+	return errors.New("other error")
+}
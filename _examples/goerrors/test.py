@@ -0,0 +1,55 @@
+# Copyright 2024 The go-python Authors.  All rights reserved.
+# Use of this source code is governed by a BSD-style
+# license that can be found in the LICENSE file.
+
+from __future__ import print_function
+
+import go, goerrors
+
+print("goerrors.Find('gopher'):", goerrors.Find("gopher"))
+
+try:
+    goerrors.Find("nobody")
+except goerrors.ErrNotFoundException as err:
+    print("caught ErrNotFoundException:", err)
+
+try:
+    goerrors.Open("/etc/shadow")
+except goerrors.PathErrorException as err:
+    print("caught PathErrorException:", err)
+
+try:
+    goerrors.Check(False)
+except goerrors.ErrPermissionException as err:
+    print("caught ErrPermissionException:", err)
+
+try:
+    goerrors.Fail(7)
+except goerrors.CodeException as err:
+    print("caught CodeException:", err)
+
+try:
+    goerrors.Other()
+except goerrors.ErrNotFoundException as err:
+    print("unexpected ErrNotFoundException:", err)
+except go.GoError as err:
+    print("caught go.GoError:", err, type(err).__name__)
+
+try:
+    goerrors.Load("/etc/shadow")
+except go.GoError as err:
+    print("caught", type(err).__name__ + ":", err, err.go_type)
+    cause = err.__cause__
+    while cause is not None:
+        print("  caused by", type(cause).__name__ + ":", cause, cause.go_type)
+        cause = cause.__cause__
+
+print("ErrNotFoundException is a go.GoError:", issubclass(goerrors.ErrNotFoundException, go.GoError))
+print("go.GoError is a RuntimeError:", issubclass(go.GoError, RuntimeError))
+
+try:
+    goerrors.Find("nobody")
+except RuntimeError as err:
+    print("caught RuntimeError:", type(err).__name__)
+
+print("OK")
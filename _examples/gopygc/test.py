@@ -5,6 +5,7 @@
 # py2/py3 compat
 from __future__ import print_function
 
+import go
 import gopygc
 import _gopygc
 
@@ -58,5 +59,29 @@ print(_gopygc.NumHandles())  # 1
 del f
 print(_gopygc.NumHandles())  # 0
 
+# test the handle limit and the handle pressure helper
+print("pressure:", go.handle_pressure(gopygc.StructA, 100))
+calls = []
+go.set_handle_limit(2, calls.append)
+a = [gopygc.StructA() for i in range(4)]
+print("limit calls:", calls)
+del a
+b = [gopygc.StructA() for i in range(3)]
+print("limit calls:", calls)
+go.set_handle_limit(0)
+del b
+
+# test the handle reports
+a = [gopygc.StructA() for i in range(2)]
+m = gopygc.MapValue()
+m2 = type(m)(handle=m.handle)
+print("num_handles:", go.num_handles())
+print("handle_stats:", list(go.handle_stats().items()))
+import io
+buf = io.StringIO()
+go.dump_handles(buf)
+print("dump_handles:", [line.split("\t")[1:3] for line in buf.getvalue().splitlines()])
+del a, m, m2
+print("num_handles:", go.num_handles())
 
 print("OK")
@@ -0,0 +1,59 @@
+// Copyright 2024 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// package gotime tests the conversion of time.Time and time.Duration
+// to and from python datetime.datetime and datetime.timedelta.
+package gotime
+
+import "time"
+
+// Epoch is the unix epoch.
+var Epoch = time.Unix(0, 0).UTC()
+
+// Event has a time and a duration.
+type Event struct {
+	Name  string
+	Start time.Time
+	Len   time.Duration
+}
+
+// End returns the end time of the event.
+func (e *Event) End() time.Time {
+	return e.Start.Add(e.Len)
+}
+
+// NewEvent returns a new event.
+func NewEvent(name string, start time.Time, dur time.Duration) *Event {
+	return &Event{Name: name, Start: start, Len: dur}
+}
+
+// Date returns the given date at midnight UTC.
+func Date(year, month, day int) time.Time {
+	return time.Date(year, time.Month(month), day, 0, 0, 0, 0, time.UTC)
+}
+
+// Unix returns the unix time of t in seconds.
+func Unix(t time.Time) int64 {
+	return t.Unix()
+}
+
+// Add returns t+d.
+func Add(t time.Time, d time.Duration) time.Time {
+	return t.Add(d)
+}
+
+// Sub returns t-u.
+func Sub(t, u time.Time) time.Duration {
+	return t.Sub(u)
+}
+
+// Minutes returns a duration of n minutes.
+func Minutes(n int) time.Duration {
+	return time.Duration(n) * time.Minute
+}
+
+// String returns the Go string representation of d.
+func String(d time.Duration) string {
+	return d.String()
+}
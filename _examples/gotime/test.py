@@ -0,0 +1,45 @@
+# Copyright 2024 The go-python Authors.  All rights reserved.
+# Use of this source code is governed by a BSD-style
+# license that can be found in the LICENSE file.
+
+from __future__ import print_function
+
+import datetime
+import gotime
+
+utc = datetime.timezone.utc
+
+d = gotime.Date(2024, 2, 29)
+print("gotime.Date(2024, 2, 29):", d.isoformat())
+print("isinstance datetime:", isinstance(d, datetime.datetime))
+
+print("gotime.Unix(aware):", gotime.Unix(datetime.datetime(2000, 1, 1, tzinfo=utc)))
+print("gotime.Unix(naive):", gotime.Unix(datetime.datetime(2000, 1, 1)))
+est = datetime.timezone(datetime.timedelta(hours=-5))
+print("gotime.Unix(-05:00):", gotime.Unix(datetime.datetime(2000, 1, 1, tzinfo=est)))
+
+m = gotime.Minutes(90)
+print("gotime.Minutes(90):", repr(m))
+print("gotime.String(timedelta(days=1, microseconds=5)):", gotime.String(datetime.timedelta(days=1, microseconds=5)))
+print("gotime.String(-timedelta(seconds=1.5)):", gotime.String(-datetime.timedelta(seconds=1.5)))
+
+t = gotime.Add(d, datetime.timedelta(hours=1, microseconds=250))
+print("gotime.Add:", t.isoformat())
+print("gotime.Sub:", repr(gotime.Sub(t, d)))
+
+print("gotime.Epoch():", gotime.Epoch().isoformat())
+
+e = gotime.NewEvent("meeting", datetime.datetime(2024, 1, 2, 9, 30, tzinfo=utc), datetime.timedelta(minutes=45))
+print("e.Start:", e.Start.isoformat())
+print("e.Len:", repr(e.Len))
+print("e.End():", e.End().isoformat())
+e.Len = datetime.timedelta(hours=2)
+print("e.End() after e.Len = 2h:", e.End().isoformat())
+
+try:
+    gotime.Unix(42)
+    print("no error for int")
+except TypeError as err:
+    print("caught:", err)
+
+print("OK")
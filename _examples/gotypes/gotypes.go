@@ -0,0 +1,48 @@
+// Copyright 2024 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// package gotypes tests the __go_types__ registry of the wrapper
+// classes, with the metadata of their fields and methods.
+package gotypes
+
+// Genre is the genre of a book.
+type Genre int
+
+const (
+	Fiction Genre = iota
+	Poetry
+)
+
+// Author is the author of books.
+type Author struct {
+	// Name is the full name of the author.
+	Name string
+}
+
+// Book is a book, by an author.
+type Book struct {
+	Title  string
+	Pages  int
+	Genre  Genre
+	Author Author
+}
+
+// Describe returns a description of the book, in the given language.
+func (b *Book) Describe(lang string) string {
+	return b.Title + " by " + b.Author.Name + " (" + lang + ")"
+}
+
+// Titled is anything with a title.
+type Titled interface {
+	// GetTitle returns the title.
+	GetTitle() string
+}
+
+// Shelf is a named list of titles.
+type Shelf []string
+
+// NewBook returns a new book.
+func NewBook(title, author string, pages int) *Book {
+	return &Book{Title: title, Pages: pages, Genre: Poetry, Author: Author{Name: author}}
+}
@@ -0,0 +1,33 @@
+# Copyright 2024 The go-python Authors.  All rights reserved.
+# Use of this source code is governed by a BSD-style
+# license that can be found in the LICENSE file.
+
+from __future__ import print_function
+
+import go, gotypes
+
+types = gotypes.__go_types__
+for name in sorted(types):
+    t = types[name]
+    print("%s: %s %s" % (name, t.kind, t.cls.__name__))
+
+book = types["gotypes.Book"]
+print("Book fields:", [(f.name, f.gotype, f.pytype) for f in book.fields])
+print("Book methods:", [(m.name, m.args, m.returns) for m in book.methods])
+print("Book doc:", book.doc.strip())
+print("Author.Name doc:", types["gotypes.Author"].fields[0].doc)
+print("Titled methods:", [(m.name, m.doc) for m in types["gotypes.Titled"].methods])
+
+# a generic serializer, working on any gopy bindings
+classes = dict((t.cls, t) for t in types.values())
+
+def to_dict(obj):
+    t = classes.get(type(obj))
+    if t is None or t.kind != "struct":
+        return obj
+    return dict((f.name, to_dict(getattr(obj, f.name))) for f in t.fields)
+
+b = gotypes.NewBook("Odes", "Keats", 42)
+print("to_dict(book):", sorted(to_dict(b).items()))
+
+print("OK")
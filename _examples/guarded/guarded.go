@@ -0,0 +1,43 @@
+// Copyright 2024 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// package guarded tests the locked method of the structs requiring
+// external locking, shared by python threads.
+package guarded
+
+// Account is a bank account not safe for concurrent use: its users must
+// hold its lock.
+//
+//gopy:guarded
+type Account struct {
+	Balance int
+	Ops     int
+}
+
+// Deposit adds the amount to the balance.
+func (a *Account) Deposit(amount int) {
+	a.Balance += amount
+	a.Ops++
+}
+
+// Withdraw removes the amount from the balance, returning false if it is
+// not enough.
+func (a *Account) Withdraw(amount int) bool {
+	if a.Balance < amount {
+		return false
+	}
+	a.Balance -= amount
+	a.Ops++
+	return true
+}
+
+// Accounts holds an account, returning new wrappers of it.
+type Accounts struct {
+	Main *Account
+}
+
+// NewAccounts returns accounts with a main account.
+func NewAccounts() *Accounts {
+	return &Accounts{Main: &Account{}}
+}
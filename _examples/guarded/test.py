@@ -0,0 +1,42 @@
+# Copyright 2024 The go-python Authors.  All rights reserved.
+# Use of this source code is governed by a BSD-style
+# license that can be found in the LICENSE file.
+
+from __future__ import print_function
+
+import threading
+
+import guarded, go
+
+accts = guarded.NewAccounts()
+
+
+def work(a):
+    for _ in range(200):
+        with a.locked() as acct:
+            bal = acct.Balance
+            acct.Deposit(2)
+            acct.Withdraw(1)
+            assert acct.Balance == bal + 1
+
+
+# each thread has its own wrapper of the same Go account
+threads = [threading.Thread(target=work, args=(accts.Main,)) for _ in range(4)]
+for t in threads:
+    t.start()
+for t in threads:
+    t.join()
+
+a = accts.Main
+print("Balance:", a.Balance, "Ops:", a.Ops)
+
+lk = a.locked()
+with lk:
+    try:
+        with lk:
+            pass
+    except RuntimeError as e:
+        print("RuntimeError:", e)
+
+print("hasattr locked:", hasattr(accts, "locked"))
+print("OK")
@@ -0,0 +1,54 @@
+// Copyright 2024 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// package hooks tests the fragments of Go and python code injected into
+// the generated functions and methods by the -hooks option, of hooks.txtar.
+package hooks
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"sync"
+)
+
+var (
+	mu    sync.Mutex
+	calls = map[string]int{}
+)
+
+// Record records a call of the named function, by the Go hooks.
+func Record(name string) {
+	mu.Lock()
+	defer mu.Unlock()
+	calls[name]++
+}
+
+// Calls returns the numbers of the calls recorded, as name=n strings.
+func Calls() string {
+	mu.Lock()
+	defer mu.Unlock()
+	var cs []string
+	for name, n := range calls {
+		cs = append(cs, fmt.Sprintf("%s=%d", name, n))
+	}
+	sort.Strings(cs)
+	return strings.Join(cs, ",")
+}
+
+// Sqrt returns the square root of x, validated by a python hook.
+func Sqrt(x float64) float64 {
+	return math.Sqrt(x)
+}
+
+// Counter is a counter.
+type Counter struct {
+	N int
+}
+
+// Add adds n to the counter.
+func (c *Counter) Add(n int) {
+	c.N += n
+}
@@ -0,0 +1,21 @@
+# Copyright 2024 The go-python Authors.  All rights reserved.
+# Use of this source code is governed by a BSD-style
+# license that can be found in the LICENSE file.
+
+from __future__ import print_function
+
+import hooks
+
+print("sqrt:", hooks.Sqrt(4))
+try:
+    hooks.Sqrt(-1)
+except ValueError as e:
+    print("caught ValueError:", e)
+
+c = hooks.Counter()
+c.Add(2)
+c.Add(3)
+print("n:", c.N)
+print("calls:", hooks.Calls())
+
+print("OK")
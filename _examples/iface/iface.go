@@ -24,6 +24,21 @@ func (t *T) F() {
 	cpkg.Printf("t.F [CALLED]\n")
 }
 
+// impl is an unexported implementation of Iface
+type impl struct {
+	n int
+}
+
+func (i *impl) F() {
+	cpkg.Printf("impl.F [CALLED] %d\n", i.n)
+}
+
+// NewIface returns an Iface with an unexported concrete type,
+// whose methods can only be called through the interface.
+func NewIface(n int) Iface {
+	return &impl{n: n}
+}
+
 // CallIface calls F() on v
 func CallIface(v Iface) {
 	cpkg.Printf("iface.CallIface...\n")
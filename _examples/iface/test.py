@@ -18,6 +18,14 @@ t.F()
 print("iface.CallIface(t)")
 iface.CallIface(t)
 
+print("v = iface.NewIface(42)")
+v = iface.NewIface(42)
+print("isinstance(v, iface.Iface):", isinstance(v, iface.Iface))
+print("v.F()")
+v.F()
+print("iface.CallIface(v)")
+iface.CallIface(v)
+
 print('iface.IfaceString("test string"')
 iface.IfaceString("test string")
 
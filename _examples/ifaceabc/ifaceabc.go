@@ -0,0 +1,40 @@
+// Copyright 2024 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// package ifaceabc tests the abstract base classes of the interfaces.
+package ifaceabc
+
+// Shape is a shape.
+type Shape interface {
+	// Area returns the area of the shape.
+	Area() float64
+	// Scale scales the shape by the factor.
+	Scale(f float64)
+}
+
+// Rect is a rectangle.
+type Rect struct {
+	W, H float64
+}
+
+// Area returns the area of the rectangle.
+func (r *Rect) Area() float64 {
+	return r.W * r.H
+}
+
+// Scale scales the rectangle by the factor.
+func (r *Rect) Scale(f float64) {
+	r.W *= f
+	r.H *= f
+}
+
+// Point is a point, with no area.
+type Point struct {
+	X, Y float64
+}
+
+// NewShape returns a rectangle of the width and height as a shape.
+func NewShape(w, h float64) Shape {
+	return &Rect{W: w, H: h}
+}
@@ -0,0 +1,55 @@
+# Copyright 2024 The go-python Authors.  All rights reserved.
+# Use of this source code is governed by a BSD-style
+# license that can be found in the LICENSE file.
+
+from __future__ import print_function
+
+import ifaceabc
+
+
+class Square(ifaceabc.AbstractShape):
+    def __init__(self, side):
+        self.side = side
+
+    def Area(self):
+        return self.side * self.side
+
+    def Scale(self, f):
+        self.side *= f
+
+
+class Incomplete(ifaceabc.AbstractShape):
+    def Area(self):
+        return 0
+
+
+class Circle(object):
+    def __init__(self, r):
+        self.r = r
+
+    def Area(self):
+        return 3 * self.r * self.r
+
+    def Scale(self, f):
+        self.r *= f
+
+
+sq = Square(2)
+sq.Scale(2)
+print("Square area:", sq.Area())
+
+try:
+    Incomplete()
+except TypeError as e:
+    print("Incomplete: TypeError naming Scale:", "Scale" in str(e))
+
+s = ifaceabc.NewShape(2, 3)
+print("Go shape area:", s.Area())
+print("isinstance(Go shape, AbstractShape):", isinstance(s, ifaceabc.AbstractShape))
+print("isinstance(Rect(), AbstractShape):", isinstance(ifaceabc.Rect(W=1, H=1), ifaceabc.AbstractShape))
+print("isinstance(Point(), AbstractShape):", isinstance(ifaceabc.Point(), ifaceabc.AbstractShape))
+print("isinstance(Square, AbstractShape):", isinstance(sq, ifaceabc.AbstractShape))
+print("isinstance(Circle, AbstractShape):", isinstance(Circle(1), ifaceabc.AbstractShape))
+print("isinstance(1, AbstractShape):", isinstance(1, ifaceabc.AbstractShape))
+
+print("OK")
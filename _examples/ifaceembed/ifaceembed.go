@@ -0,0 +1,83 @@
+// Copyright 2024 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// package ifaceembed tests interfaces composed of embedded interfaces.
+package ifaceembed
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Reader reads strings.
+type Reader interface {
+	Read() string
+}
+
+// Writer writes strings.
+type Writer interface {
+	Write(s string)
+}
+
+// Closer can be closed.
+type Closer interface {
+	Close()
+}
+
+// ReadWriter groups the Read and Write methods.
+type ReadWriter interface {
+	Reader
+	Writer
+}
+
+// ReadWriteCloser groups the Read, Write and Close methods,
+// and can be printed.
+type ReadWriteCloser interface {
+	ReadWriter
+	Reader
+	Closer
+	fmt.Stringer
+}
+
+type buffer struct {
+	lines  []string
+	closed bool
+}
+
+func (b *buffer) Read() string {
+	if len(b.lines) == 0 {
+		return ""
+	}
+	s := b.lines[0]
+	b.lines = b.lines[1:]
+	return s
+}
+
+func (b *buffer) Write(s string) {
+	if !b.closed {
+		b.lines = append(b.lines, s)
+	}
+}
+
+func (b *buffer) Close() {
+	b.closed = true
+}
+
+func (b *buffer) String() string {
+	return fmt.Sprintf("buffer(%s, closed=%v)", strings.Join(b.lines, ","), b.closed)
+}
+
+// NewReadWriteCloser returns a new ReadWriteCloser buffer.
+func NewReadWriteCloser() ReadWriteCloser {
+	return &buffer{}
+}
+
+// ReadAll reads all strings from r.
+func ReadAll(r Reader) []string {
+	var all []string
+	for s := r.Read(); s != ""; s = r.Read() {
+		all = append(all, s)
+	}
+	return all
+}
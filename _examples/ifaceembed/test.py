@@ -0,0 +1,27 @@
+# Copyright 2024 The go-python Authors.  All rights reserved.
+# Use of this source code is governed by a BSD-style
+# license that can be found in the LICENSE file.
+
+from __future__ import print_function
+
+import ifaceembed
+
+print("ReadWriter bases:", [c.__name__ for c in ifaceembed.ReadWriter.__bases__])
+print("ReadWriteCloser bases:", [c.__name__ for c in ifaceembed.ReadWriteCloser.__bases__])
+
+rwc = ifaceembed.NewReadWriteCloser()
+print("isinstance(rwc, ReadWriter):", isinstance(rwc, ifaceembed.ReadWriter))
+print("isinstance(rwc, Reader):", isinstance(rwc, ifaceembed.Reader))
+print("isinstance(rwc, Closer):", isinstance(rwc, ifaceembed.Closer))
+
+rwc.Write("a")
+rwc.Write("b")
+print("rwc.Read():", rwc.Read())
+rwc.Write("c")
+print("rwc:", rwc)
+rwc.Close()
+rwc.Write("d")
+print("rwc after Close:", rwc)
+print("ifaceembed.ReadAll(rwc):", list(ifaceembed.ReadAll(rwc)))
+
+print("OK")
@@ -0,0 +1,66 @@
+// Copyright 2024 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package iorw tests passing python file-like objects as io.Reader and
+// io.Writer args, and using Go readers and writers as python file-like
+// objects.
+package iorw
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Greet writes a greeting to w.
+func Greet(w io.Writer, name string) error {
+	_, err := fmt.Fprintf(w, "hello, %s\n", name)
+	return err
+}
+
+// Upper copies r to w in upper case, returning the number of bytes copied.
+func Upper(w io.Writer, r io.Reader) (int, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return 0, err
+	}
+	return w.Write(bytes.ToUpper(data))
+}
+
+// Count returns the number of bytes read from r until EOF.
+func Count(r io.Reader) (int, error) {
+	n, err := io.Copy(io.Discard, r)
+	return int(n), err
+}
+
+// Lines returns a reader of the lines.
+func Lines(lines []string) io.Reader {
+	return strings.NewReader(strings.Join(lines, "\n"))
+}
+
+// Buffer is a Go buffer, an io.Reader and an io.Writer.
+type Buffer struct {
+	buf bytes.Buffer
+}
+
+// NewBuffer returns a new empty buffer.
+func NewBuffer() *Buffer {
+	return &Buffer{}
+}
+
+// Read reads from the buffer.
+func (b *Buffer) Read(p []byte) (int, error) {
+	return b.buf.Read(p)
+}
+
+// Write writes to the buffer.
+func (b *Buffer) Write(p []byte) (int, error) {
+	return b.buf.Write(p)
+}
+
+// Len returns the number of unread bytes of the buffer.
+func (b *Buffer) Len() int {
+	return b.buf.Len()
+}
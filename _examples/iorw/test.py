@@ -0,0 +1,39 @@
+# Copyright 2024 The go-python Authors.  All rights reserved.
+# Use of this source code is governed by a BSD-style
+# license that can be found in the LICENSE file.
+
+from __future__ import print_function
+
+import io
+
+import go, iorw
+
+out = io.StringIO()
+iorw.Greet(out, "you")
+print("Greet to StringIO:", repr(out.getvalue()))
+
+bout = io.BytesIO()
+n = iorw.Upper(bout, io.BytesIO(b"hello, world"))
+print("Upper:", n, bout.getvalue())
+
+print("Count:", iorw.Count(io.StringIO(u"héllo")))
+
+b = iorw.NewBuffer()
+print("write:", b.write("abc"), b.write(b"def"), b.Len())
+print("read:", b.read(2), b.read(), b.read())
+iorw.Greet(b, "buffer")
+print("Greet to Buffer:", b.read())
+
+r = iorw.Lines(["a", "b"])
+print("Lines:", r.read())
+
+class Failing(object):
+    def write(self, data):
+        raise ValueError("disk full")
+
+try:
+    iorw.Greet(Failing(), "nobody")
+except go.GoError as err:
+    print("caught go.GoError:", err)
+
+print("OK")
@@ -0,0 +1,126 @@
+// Copyright 2024 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// package iterseq tests functions and methods returning iter.Seq and
+// iter.Seq2 iterators, bound as python iterators.
+package iterseq
+
+import (
+	"errors"
+	"iter"
+)
+
+// Count returns an iterator over the ints from 0 to n-1
+func Count(n int) iter.Seq[int] {
+	return func(yield func(int) bool) {
+		for i := 0; i < n; i++ {
+			if !yield(i) {
+				return
+			}
+		}
+	}
+}
+
+// Stopped is the number of iterators of Naturals stopped early
+var Stopped int
+
+// Naturals returns an endless iterator over the natural numbers
+func Naturals() iter.Seq[int] {
+	return func(yield func(int) bool) {
+		for i := 0; ; i++ {
+			if !yield(i) {
+				Stopped++
+				return
+			}
+		}
+	}
+}
+
+// Enumerate returns an iterator over the index and value of the words
+func Enumerate(words []string) iter.Seq2[int, string] {
+	return func(yield func(int, string) bool) {
+		for i, w := range words {
+			if !yield(i, w) {
+				return
+			}
+		}
+	}
+}
+
+// Nil returns a nil iterator
+func Nil() iter.Seq[string] {
+	return nil
+}
+
+// Item is an item of a Bag
+type Item struct {
+	Name  string
+	Count int
+}
+
+// Bag has items
+type Bag struct {
+	items []Item
+}
+
+// NewBag returns a bag with the items of the given names
+func NewBag(names ...string) *Bag {
+	b := &Bag{}
+	for i, n := range names {
+		b.items = append(b.items, Item{Name: n, Count: i + 1})
+	}
+	return b
+}
+
+// Items returns an iterator over copies of the items
+func (b *Bag) Items() iter.Seq[Item] {
+	return func(yield func(Item) bool) {
+		for _, it := range b.items {
+			if !yield(it) {
+				return
+			}
+		}
+	}
+}
+
+// Ptrs returns an iterator over pointers to the items
+func (b *Bag) Ptrs() iter.Seq[*Item] {
+	return func(yield func(*Item) bool) {
+		for i := range b.items {
+			if !yield(&b.items[i]) {
+				return
+			}
+		}
+	}
+}
+
+// Counts returns an iterator over the names and counts of the items
+func (b *Bag) Counts() iter.Seq2[string, int] {
+	return func(yield func(string, int) bool) {
+		for _, it := range b.items {
+			if !yield(it.Name, it.Count) {
+				return
+			}
+		}
+	}
+}
+
+// Checked returns an iterator over the items, or an error for an empty bag
+func (b *Bag) Checked() (iter.Seq[Item], error) {
+	if len(b.items) == 0 {
+		return nil, errors.New("iterseq: empty bag")
+	}
+	return b.Items(), nil
+}
+
+// Sum returns the sum of the values of the iterator, which is not bound,
+// as iterators are not supported as args
+func Sum(seq iter.Seq[int]) int {
+	s := 0
+	seq(func(v int) bool {
+		s += v
+		return true
+	})
+	return s
+}
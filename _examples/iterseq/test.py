@@ -0,0 +1,37 @@
+# Copyright 2024 The go-python Authors.  All rights reserved.
+# Use of this source code is governed by a BSD-style
+# license that can be found in the LICENSE file.
+
+from __future__ import print_function
+
+import iterseq, go
+
+print("list(Count(4)):", list(iterseq.Count(4)))
+print("sum(Count(10)):", sum(iterseq.Count(10)))
+
+for n in iterseq.Naturals():
+    if n == 3:
+        break
+it = iterseq.Naturals()
+print("next(Naturals()):", next(it), next(it))
+it.close()
+print("Stopped:", iterseq.Stopped())
+
+words = go.Slice_string(["a", "b", "c"])
+print("dict(Enumerate):", dict(iterseq.Enumerate(words)))
+print("list(Nil()):", list(iterseq.Nil()))
+
+bag = iterseq.NewBag("x", "y", "z")
+print("Items:", [(it.Name, it.Count) for it in bag.Items()])
+for p in bag.Ptrs():
+    p.Count *= 10
+print("Ptrs:", [it.Count for it in bag.Ptrs()])
+print("Counts:", list(bag.Counts()))
+print("Checked:", [it.Name for it in bag.Checked()])
+try:
+    iterseq.NewBag().Checked()
+except Exception as e:
+    print("empty Checked raised:", e)
+print("has Sum:", hasattr(iterseq, "Sum"))
+
+print("OK")
@@ -0,0 +1,38 @@
+// Copyright 2024 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// package lossy tests the checks of the ranges of the python ints converted
+// to Go, and of lossy conversions of python floats, with the -lossy-checks
+// option.
+package lossy
+
+// Level is a small unsigned level.
+type Level uint8
+
+// Sample is a sample.
+type Sample struct {
+	Count uint32
+	Ratio float32
+
+	// Done cannot be bound, so it is dropped, with a warning.
+	Done chan bool
+}
+
+// Max is a maximum.
+var Max uint64
+
+// Scale returns f scaled by n.
+func Scale(f float32, n uint16) float64 {
+	return float64(f) * float64(n)
+}
+
+// Next returns the next level.
+func Next(l Level) Level {
+	return l + 1
+}
+
+// Offset returns base moved by d.
+func Offset(base uint64, d int8) uint64 {
+	return base + uint64(d)
+}
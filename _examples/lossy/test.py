@@ -0,0 +1,35 @@
+# Copyright 2024 The go-python Authors.  All rights reserved.
+# Use of this source code is governed by a BSD-style
+# license that can be found in the LICENSE file.
+
+from __future__ import print_function
+
+import warnings
+
+import go, lossy
+
+def overflow(fn, *args):
+    try:
+        fn(*args)
+    except OverflowError as e:
+        print("OverflowError:", e)
+
+with warnings.catch_warnings(record=True) as ws:
+    warnings.simplefilter("always")
+    print("Scale(0.5, 3):", lossy.Scale(0.5, 3))
+    print("Next(1):", lossy.Next(1))
+    print("Offset(1<<64 - 2, -1):", lossy.Offset((1 << 64) - 2, -1))
+    lossy.Scale(0.1, 3)
+    for w in ws:
+        print("%s: %s" % (w.category.__name__, w.message))
+
+overflow(lossy.Scale, 0.5, 70000)
+overflow(lossy.Next, 256)
+overflow(lossy.Offset, 1 << 64, 0)
+overflow(lossy.Offset, 0, -129)
+s = lossy.Sample()
+overflow(setattr, s, "Count", 1 << 33)
+overflow(lossy.Set_Max, -1)
+print("Count:", s.Count)
+
+print("OK")
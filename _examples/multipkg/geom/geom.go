@@ -0,0 +1,21 @@
+// Copyright 2024 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// package geom is a dependency of package multipkg, bound along with it.
+package geom
+
+// Rect is a rectangle.
+type Rect struct {
+	W, H float64
+}
+
+// NewRect returns a new rectangle of the given width and height.
+func NewRect(w, h float64) *Rect {
+	return &Rect{W: w, H: h}
+}
+
+// Area returns the area of the rectangle.
+func (r *Rect) Area() float64 {
+	return r.W * r.H
+}
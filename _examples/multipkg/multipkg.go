@@ -0,0 +1,21 @@
+// Copyright 2024 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// package multipkg tests the binding of a package along with its
+// dependencies within the same module, with the -recursive option.
+package multipkg
+
+import (
+	"github.com/go-python/gopy/_examples/multipkg/geom"
+)
+
+// Unit returns the unit square.
+func Unit() *geom.Rect {
+	return geom.NewRect(1, 1)
+}
+
+// Scale returns the rectangle scaled by f.
+func Scale(r *geom.Rect, f float64) *geom.Rect {
+	return geom.NewRect(r.W*f, r.H*f)
+}
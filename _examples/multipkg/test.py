@@ -0,0 +1,15 @@
+# Copyright 2024 The go-python Authors.  All rights reserved.
+# Use of this source code is governed by a BSD-style
+# license that can be found in the LICENSE file.
+
+from __future__ import print_function
+
+import multipkg, geom
+
+u = multipkg.Unit()
+print("unit:", type(u).__module__, type(u).__name__, u.Area())
+
+r = multipkg.Scale(geom.NewRect(2, 3), 2)
+print("scaled:", r.W, r.H, r.Area())
+
+print("OK")
@@ -0,0 +1,111 @@
+// Copyright 2024 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// package namedret tests functions and methods with named results,
+// naked returns and multiple returns.
+package namedret
+
+import "errors"
+
+// ErrNegative is returned for negative values
+var ErrNegative = errors.New("namedret: negative value")
+
+// Naked returns its named result with a naked return
+func Naked() (n int) {
+	n = 42
+	return
+}
+
+// NakedErr returns twice its arg, with a naked return
+func NakedErr(x int) (n int, err error) {
+	if x < 0 {
+		err = ErrNegative
+		return
+	}
+	n = 2 * x
+	return
+}
+
+// Named returns its named results explicitly
+func Named(s string) (out string, err error) {
+	return s + s, nil
+}
+
+// BlankResult has a blank named result
+func BlankResult(x int) (_ int, err error) {
+	if x < 0 {
+		return 0, ErrNegative
+	}
+	return x + 1, nil
+}
+
+// OnlyErr has a named error result
+func OnlyErr(fail bool) (err error) {
+	if fail {
+		err = ErrNegative
+	}
+	return
+}
+
+// Grouped has grouped named results, that are not bound
+func Grouped() (a, b int) {
+	return 1, 2
+}
+
+// BlankArgs has blank args
+func BlankArgs(_ int, x int, _ string) (n int) {
+	n = x
+	return
+}
+
+// Shadow has args named like the generated code locals and the package
+func Shadow(cret, vifc, estr int, namedret string) (ret int) {
+	ret = cret + vifc + estr + len(namedret)
+	return
+}
+
+// Apply calls f with x, f having named results
+func Apply(f func(x int) (y int), x int) (r int) {
+	r = f(x)
+	return
+}
+
+// Counter counts
+type Counter struct {
+	N int
+}
+
+// Incr increments the counter, returning its new value
+func (c *Counter) Incr() (n int) {
+	c.N++
+	n = c.N
+	return
+}
+
+// Decr decrements the counter, returning ErrNegative below zero
+func (c *Counter) Decr() (n int, err error) {
+	if c.N == 0 {
+		err = ErrNegative
+		return
+	}
+	c.N--
+	n = c.N
+	return
+}
+
+// Value returns a copy of the counter
+func (c *Counter) Value() (v Counter) {
+	v = *c
+	return
+}
+
+// ShadowErr has an arg named like the generated code locals, with an error
+func ShadowErr(cret int) (n int, err error) {
+	if cret < 0 {
+		err = ErrNegative
+		return
+	}
+	n = cret
+	return
+}
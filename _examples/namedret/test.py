@@ -0,0 +1,39 @@
+# Copyright 2024 The go-python Authors.  All rights reserved.
+# Use of this source code is governed by a BSD-style
+# license that can be found in the LICENSE file.
+
+from __future__ import print_function
+
+import namedret
+
+print("Naked():", namedret.Naked())
+print("NakedErr(21):", namedret.NakedErr(21))
+try:
+    namedret.NakedErr(-1)
+except Exception as e:
+    print("NakedErr(-1) raised:", e)
+print("Named('ab'):", namedret.Named("ab"))
+print("BlankResult(1):", namedret.BlankResult(1))
+namedret.OnlyErr(False)
+try:
+    namedret.OnlyErr(True)
+except Exception as e:
+    print("OnlyErr(True) raised:", e)
+print("has Grouped:", hasattr(namedret, "Grouped"))
+print("BlankArgs(1, 2, 'x'):", namedret.BlankArgs(1, 2, "x"))
+print("Shadow(1, 2, 3, 'abcd'):", namedret.Shadow(1, 2, 3, "abcd"))
+print("ShadowErr(cret=5):", namedret.ShadowErr(cret=5))
+print("Apply(lambda x: x*3, 5):", namedret.Apply(lambda x: x * 3, 5))
+
+c = namedret.Counter()
+print("c.Incr():", c.Incr())
+print("c.Incr():", c.Incr())
+print("c.Decr():", c.Decr())
+print("c.Value().N:", c.Value().N)
+c.Decr()
+try:
+    c.Decr()
+except Exception as e:
+    print("c.Decr() raised:", e)
+
+print("OK")
@@ -0,0 +1,80 @@
+// Copyright 2024 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// package nested tests maps with struct values and nested container types.
+package nested
+
+// Foo is a struct stored in maps
+type Foo struct {
+	Name string
+	N    int
+}
+
+// Series returns a map of slices of floats
+func Series() map[string][]float64 {
+	return map[string][]float64{"a": {1, 2}, "b": {3}}
+}
+
+// SumSeries returns the sum of all the floats of the series
+func SumSeries(m map[string][]float64) float64 {
+	s := 0.0
+	for _, v := range m {
+		for _, x := range v {
+			s += x
+		}
+	}
+	return s
+}
+
+// Foos returns a map of pointers to Foos, with a nil one
+func Foos() map[string]*Foo {
+	return map[string]*Foo{"x": {"x", 1}, "none": nil}
+}
+
+// CountFoos returns the sum of the N of the non-nil Foos
+func CountFoos(m map[string]*Foo) int {
+	n := 0
+	for _, f := range m {
+		if f != nil {
+			n += f.N
+		}
+	}
+	return n
+}
+
+// FooVals returns a map of Foo values
+func FooVals() map[string]Foo {
+	return map[string]Foo{"y": {"y", 2}}
+}
+
+// CountFooVals returns the sum of the N of the Foos
+func CountFooVals(m map[string]Foo) int {
+	n := 0
+	for _, f := range m {
+		n += f.N
+	}
+	return n
+}
+
+// Grid returns a slice of slices of ints
+func Grid() [][]int {
+	return [][]int{{1, 2}, {3}}
+}
+
+// GridSum returns the sum of the ints of the grid
+func GridSum(g [][]int) int {
+	s := 0
+	for _, r := range g {
+		for _, x := range r {
+			s += x
+		}
+	}
+	return s
+}
+
+// Table has nested container fields
+type Table struct {
+	Cols map[string][]float64
+	Rows [][]int
+}
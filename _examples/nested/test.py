@@ -0,0 +1,76 @@
+# Copyright 2024 The go-python Authors.  All rights reserved.
+# Use of this source code is governed by a BSD-style
+# license that can be found in the LICENSE file.
+
+from __future__ import print_function
+
+import copy
+import pickle
+
+import nested
+
+s = nested.Series()
+print("s['a']:", list(s["a"]))
+print("s.to_dict():", sorted(s.to_dict().items()))
+print("SumSeries(s):", nested.SumSeries(s))
+print("SumSeries(dict):", nested.SumSeries({"x": [0.5, 1.5], "y": [2.0]}))
+s["c"] = [4.0, 5.0]
+print("s['c'] set from list:", s["c"].to_list())
+
+f = nested.Foos()
+print("f['x'].Name:", f["x"].Name)
+print("f['none']:", f["none"])
+f["z"] = nested.Foo(Name="z", N=10)
+f["none2"] = None
+print("CountFoos(f):", nested.CountFoos(f))
+print("CountFoos(dict):", nested.CountFoos({"a": nested.Foo(N=3), "b": None}))
+
+v = nested.FooVals()
+print("v['y'].N:", v["y"].N)
+print("CountFooVals(dict):", nested.CountFooVals({"a": nested.Foo(N=4), "b": nested.Foo(N=5)}))
+
+g = nested.Grid()
+print("g.to_list():", g.to_list())
+print("GridSum(list):", nested.GridSum([[1, 2, 3], [4]]))
+g.append([5, 6])
+g[0] = [7]
+print("g.to_list() after append and set:", g.to_list())
+
+t = nested.Table()
+t.Cols = {"u": [1.0]}
+t.Rows = [[1], [2, 3]]
+print("t.Cols.to_dict():", t.Cols.to_dict())
+print("t.Rows.to_list():", t.Rows.to_list())
+
+c = nested.Map_string_Slice_float64.from_dict(s)
+c["a"][0] = 9.0
+print("from_dict copy, original:", c["a"].to_list(), s["a"].to_list())
+r = nested.Slice_Slice_int.from_list(((1,), [2, 3]))
+print("from_list:", r.to_list(), nested.GridSum(r))
+
+print("zero(Foo):", nested.zero(nested.Foo).Name == "", nested.Foo.zero().N)
+print("zero(Map_string_Slice_float64):", nested.zero(nested.Map_string_Slice_float64).to_dict())
+print("zero(int), zero(str):", nested.zero(int), repr(nested.zero(str)))
+
+foo = pickle.loads(pickle.dumps(nested.Foo(Name="p", N=7)))
+print("unpickled Foo:", foo.Name, foo.N)
+t2 = pickle.loads(pickle.dumps(t))
+t2.Rows[0] = [0]
+print("unpickled Table:", t2.Cols.to_dict(), t2.Rows.to_list(), t.Rows.to_list())
+
+f1 = nested.Foo(Name="c", N=1)
+f2 = copy.copy(f1)
+f2.N = 2
+print("copied Foo:", f1.N, f2.N)
+t3 = copy.copy(t)
+t3.Rows[0] = [8]
+t4 = copy.deepcopy(t)
+t4.Rows[1] = [9]
+t4.Cols["v"] = [2.0]
+print("copied Table:", t.Rows.to_list(), t4.Rows.to_list(), sorted(t.Cols.to_dict()), sorted(t4.Cols.to_dict()))
+
+a, b = nested.Foo(Name="e", N=1), nested.Foo(Name="e", N=1)
+print("Foo ==:", a == b, a != nested.Foo(Name="e", N=2), len({a, b}), {a: 1}[b])
+print("Table ==:", t == copy.copy(t), t == t)
+
+print("OK")
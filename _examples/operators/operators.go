@@ -0,0 +1,75 @@
+// Copyright 2024 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package operators tests binding Go methods to python operators.
+package operators
+
+import "errors"
+
+// Vec is a 2D vector, with the conventional methods of operators.
+type Vec struct {
+	X, Y float64
+}
+
+// NewVec returns a new vector.
+func NewVec(x, y float64) Vec {
+	return Vec{X: x, Y: y}
+}
+
+// Add returns v + o.
+func (v Vec) Add(o Vec) Vec {
+	return Vec{v.X + o.X, v.Y + o.Y}
+}
+
+// Sub returns v - o.
+func (v Vec) Sub(o Vec) Vec {
+	return Vec{v.X - o.X, v.Y - o.Y}
+}
+
+// Mul returns v scaled by f.
+func (v Vec) Mul(f float64) Vec {
+	return Vec{v.X * f, v.Y * f}
+}
+
+// Neg returns -v.
+func (v Vec) Neg() Vec {
+	return Vec{-v.X, -v.Y}
+}
+
+// Less returns whether v is shorter than o.
+func (v Vec) Less(o Vec) bool {
+	return v.X*v.X+v.Y*v.Y < o.X*o.X+o.Y*o.Y
+}
+
+// Money is an amount of cents, with operators set by directives.
+type Money struct {
+	Cents int
+}
+
+// Plus returns the sum of the amounts.
+//
+//gopy:operator +
+func (m Money) Plus(o Money) Money {
+	return Money{m.Cents + o.Cents}
+}
+
+// Div splits the amount in n parts, failing if it cannot.
+func (m Money) Div(n int) (Money, error) {
+	if n <= 0 || m.Cents%n != 0 {
+		return Money{}, errors.New("operators: cannot split the amount")
+	}
+	return Money{m.Cents / n}, nil
+}
+
+// Equal returns whether the amounts are the same.
+func (m Money) Equal(o Money) bool {
+	return m.Cents == o.Cents
+}
+
+// Sub is not an operator of Money.
+//
+//gopy:operator none
+func (m Money) Sub(o Money) Money {
+	return Money{m.Cents - o.Cents}
+}
@@ -0,0 +1,43 @@
+# Copyright 2024 The go-python Authors.  All rights reserved.
+# Use of this source code is governed by a BSD-style
+# license that can be found in the LICENSE file.
+
+from __future__ import print_function
+
+import go, operators
+
+a = operators.NewVec(1, 2)
+b = operators.NewVec(3, 4)
+c = a + b
+print("a + b:", c.X, c.Y)
+c = b - a
+print("b - a:", c.X, c.Y)
+c = a * 3
+print("a * 3:", c.X, c.Y)
+c = -a
+print("-a:", c.X, c.Y)
+print("a < b:", a < b, b < a)
+try:
+    a + 1
+except TypeError:
+    print("a + 1: TypeError")
+
+m = operators.Money(Cents=150)
+n = operators.Money(Cents=50)
+print("m + n:", (m + n).Cents)
+print("m / 3:", (m / 3).Cents)
+try:
+    m / 4
+except go.GoError as err:
+    print("m / 4:", err)
+print("m == n:", m == n, m == operators.Money(Cents=150), m != n)
+try:
+    hash(m)
+except TypeError:
+    print("hash(m): TypeError")
+try:
+    m - n
+except TypeError:
+    print("m - n: TypeError")
+
+print("OK")
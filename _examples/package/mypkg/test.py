@@ -9,4 +9,6 @@ import mypkg.mypkg
 
 print("mypkg.mypkg.SayHello()...")
 print(mypkg.mypkg.SayHello())
+print("submodules:", mypkg.__all__)
+print("lazy go:", mypkg.go.__name__, hasattr(mypkg.go, "GoClass"))
 print("OK")
@@ -0,0 +1,65 @@
+// Copyright 2024 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// package parallel tests calling bound functions from goroutines
+// with parallel_map and go.run, and awaiting them with asyncio.
+package parallel
+
+import (
+	"fmt"
+	"sync/atomic"
+	"time"
+)
+
+var running, maxRunning int64
+
+// Square returns n*n, after sleeping for ms milliseconds.
+func Square(n, ms int) int {
+	r := atomic.AddInt64(&running, 1)
+	for {
+		m := atomic.LoadInt64(&maxRunning)
+		if r <= m || atomic.CompareAndSwapInt64(&maxRunning, m, r) {
+			break
+		}
+	}
+	time.Sleep(time.Duration(ms) * time.Millisecond)
+	atomic.AddInt64(&running, -1)
+	return n * n
+}
+
+// MaxRunning returns the max number of concurrent calls of Square,
+// and resets it.
+func MaxRunning() int {
+	return int(atomic.SwapInt64(&maxRunning, 0))
+}
+
+// Fib returns the n-th Fibonacci number.
+func Fib(n int) int {
+	if n < 2 {
+		return n
+	}
+	return Fib(n-1) + Fib(n-2)
+}
+
+// Check returns an error for negative n.
+func Check(n int) (int, error) {
+	if n < 0 {
+		return 0, fmt.Errorf("negative: %d", n)
+	}
+	return n, nil
+}
+
+// Generate returns a channel of the squares of 0 to n-1, sent every ms
+// milliseconds, and closed after them.
+func Generate(n, ms int) <-chan int {
+	ch := make(chan int)
+	go func() {
+		for i := 0; i < n; i++ {
+			time.Sleep(time.Duration(ms) * time.Millisecond)
+			ch <- i * i
+		}
+		close(ch)
+	}()
+	return ch
+}
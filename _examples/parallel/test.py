@@ -0,0 +1,89 @@
+# Copyright 2024 The go-python Authors.  All rights reserved.
+# Use of this source code is governed by a BSD-style
+# license that can be found in the LICENSE file.
+
+from __future__ import print_function
+
+import asyncio
+import threading
+
+import go, parallel
+
+print("parallel_map(Fib):", parallel.parallel_map(parallel.Fib, range(10), workers=4))
+print("go.parallel_map(lambda):", go.parallel_map(lambda x: x + 1, [1, 2, 3]))
+print("parallel_map(empty):", parallel.parallel_map(parallel.Fib, []))
+
+sq = parallel.parallel_map(lambda n: parallel.Square(n, 100), range(8), workers=8)
+print("parallel_map(Square):", sq)
+print("Square calls ran concurrently:", parallel.MaxRunning() > 1)
+
+sq = parallel.parallel_map(lambda n: parallel.Square(n, 10), range(8), workers=1)
+print("Square calls with one worker ran concurrently:", parallel.MaxRunning() > 1)
+
+try:
+    parallel.parallel_map(parallel.Check, [1, -2, 3])
+except go.GoError as err:
+    print("caught go.GoError:", err)
+
+def fail(x):
+    raise ValueError("python error %d" % x)
+
+try:
+    parallel.parallel_map(fail, [7], workers=2)
+except ValueError as err:
+    print("caught ValueError:", err)
+
+fut = parallel.run(parallel.Square, 3, 10)
+print("run(Square):", fut.result())
+
+futs = [go.run(parallel.Square, n, 100) for n in range(4)]
+print("run(Square) results:", [f.result() for f in futs])
+print("run calls ran concurrently:", parallel.MaxRunning() > 1)
+
+called = []
+ev = threading.Event()
+def on_done(f):
+    called.append(f.result())
+    ev.set()
+go.run(parallel.Fib, 10).add_done_callback(on_done)
+ev.wait(10)
+print("run done callback:", called)
+
+try:
+    go.run(parallel.Check, -1).result()
+except go.GoError as err:
+    print("run caught go.GoError:", err)
+
+try:
+    go.run(fail, 3).result()
+except ValueError as err:
+    print("run caught ValueError:", err)
+
+async def ticker(ticks):
+    while True:
+        await asyncio.sleep(0.01)
+        ticks.append(1)
+
+async def main():
+    ticks = []
+    t = asyncio.ensure_future(ticker(ticks))
+    v = await parallel.run_async(parallel.Square, 4, 200)
+    t.cancel()
+    print("run_async(Square):", v)
+    print("event loop ran meanwhile:", len(ticks) > 5)
+
+    ch = parallel.Generate(2, 10)
+    print("recv_async:", await ch.recv_async())
+    print("recv_async:", await ch.recv_async())
+    print("recv_async closed:", await ch.recv_async())
+
+    print("async for:", [v async for v in parallel.Generate(5, 1)])
+
+    try:
+        await go.run_async(parallel.Check, -5)
+    except go.GoError as err:
+        print("run_async caught go.GoError:", err)
+
+asyncio.run(main())
+
+print("OK")
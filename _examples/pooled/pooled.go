@@ -0,0 +1,32 @@
+// Copyright 2024 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// package pooled tests the structs constructed from a pool of their values,
+// released by python.
+package pooled
+
+import "math"
+
+// Vec is a 2D vector, created by the million in python loops.
+//
+//gopy:pooled
+type Vec struct {
+	X, Y float64
+}
+
+// Norm returns the length of the vector.
+func (v *Vec) Norm() float64 {
+	return math.Hypot(v.X, v.Y)
+}
+
+// Scale multiplies the vector by f, in place.
+func (v *Vec) Scale(f float64) {
+	v.X *= f
+	v.Y *= f
+}
+
+// Segment is a segment between two vectors, not pooled.
+type Segment struct {
+	A, B Vec
+}
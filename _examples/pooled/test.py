@@ -0,0 +1,32 @@
+# Copyright 2024 The go-python Authors.  All rights reserved.
+# Use of this source code is governed by a BSD-style
+# license that can be found in the LICENSE file.
+
+from __future__ import print_function
+
+import pooled, _pooled
+
+n = _pooled.NumHandles()
+
+total = 0.0
+for i in range(1000):
+    v = pooled.Vec(X=3, Y=4)
+    v.Scale(i % 3)
+    total += v.Norm()
+    v.release()
+print("total:", total)
+print("handles released:", _pooled.NumHandles() == n)
+
+# released values are reset for reuse
+v = pooled.Vec()
+print("new Vec:", v.X, v.Y)
+
+# a value whose handle is used by another object is not pooled
+a = pooled.Vec(X=1, Y=2)
+b = pooled.Vec(a)
+a.release()
+print("shared Vec:", b.X, b.Y)
+a.release()
+
+print("hasattr release:", hasattr(pooled.Segment(), "release"))
+print("OK")
@@ -0,0 +1,32 @@
+// Copyright 2024 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// package pretty tests the pretty() and to_yaml() methods (-pretty).
+package pretty
+
+// Point is a 2D point.
+type Point struct {
+	X, Y int
+}
+
+// Shape is a named polygon, with tags.
+type Shape struct {
+	Name   string `yaml:"name"`
+	Points []Point
+	Tags   map[string]string
+	Parent *Shape
+	area   float64
+}
+
+// NewShape returns a new triangle shape, within a parent shape.
+func NewShape() *Shape {
+	parent := &Shape{Name: "root"}
+	return &Shape{
+		Name:   "triangle",
+		Points: []Point{{0, 0}, {4, 0}, {0, 3}},
+		Tags:   map[string]string{"color": "red", "kind": "polygon"},
+		Parent: parent,
+		area:   6,
+	}
+}
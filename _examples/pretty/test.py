@@ -0,0 +1,21 @@
+# Copyright 2024 The go-python Authors.  All rights reserved.
+# Use of this source code is governed by a BSD-style
+# license that can be found in the LICENSE file.
+
+from __future__ import print_function
+
+import go, pretty
+
+s = pretty.NewShape()
+print("--- s.pretty():")
+print(s.pretty())
+print("--- s.to_yaml():")
+print(s.to_yaml())
+
+p = pretty.Point(X=1, Y=2)
+print("--- p.pretty():")
+print(p.pretty())
+print("--- p.to_yaml():")
+print(p.to_yaml())
+
+print("OK")
@@ -0,0 +1,23 @@
+# Copyright 2024 The go-python Authors.  All rights reserved.
+# Use of this source code is governed by a BSD-style
+# license that can be found in the LICENSE file.
+
+# mixins of the classes of the pymixin package, copied into the output
+# directory before generating the bindings
+
+class PersonMixin(object):
+	def is_adult(self):
+		return self.Age >= 18
+
+	def __format__(self, spec):
+		return format(self.Name, spec)
+
+class NamesMixin(object):
+	def __str__(self):
+		return "generated methods take precedence"
+
+	def upper(self):
+		return [s.upper() for s in self]
+
+class UnknownMixin(object):
+	pass
@@ -0,0 +1,33 @@
+// Copyright 2024 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// package pymixin tests adding python methods to the generated classes
+// with the mixins of _mixins.py.
+package pymixin
+
+import "strings"
+
+// Person is a person.
+type Person struct {
+	Name string
+	Age  int
+}
+
+// Greet returns a greeting of the person.
+func (p *Person) Greet() string {
+	return "hello, " + p.Name
+}
+
+// Names is a list of names.
+type Names []string
+
+// Join returns the names joined with the separator.
+func (n Names) Join(sep string) string {
+	return strings.Join(n, sep)
+}
+
+// NewNames returns the names.
+func NewNames(names ...string) Names {
+	return Names(names)
+}
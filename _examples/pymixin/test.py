@@ -0,0 +1,20 @@
+# Copyright 2024 The go-python Authors.  All rights reserved.
+# Use of this source code is governed by a BSD-style
+# license that can be found in the LICENSE file.
+
+from __future__ import print_function
+
+import pymixin
+
+p = pymixin.Person(Name="Ada", Age=36)
+print("Greet:", p.Greet())
+print("is_adult:", p.is_adult())
+print("format: [%s]" % format(p, ">5"))
+print("isinstance PersonMixin:", isinstance(p, pymixin._mixins.PersonMixin))
+
+n = pymixin.NewNames("a", "b", "c")
+print("upper:", n.upper())
+print("generated __str__ kept:", str(n) != "generated methods take precedence")
+print("len(Names):", len(n))
+
+print("OK")
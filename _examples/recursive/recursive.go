@@ -0,0 +1,103 @@
+// Copyright 2024 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// package recursive tests self-referential and mutually recursive types.
+package recursive
+
+// Node is a node of a linked list and of a tree
+type Node struct {
+	Val      int
+	Next     *Node
+	Children []*Node
+	Named    map[string]*Node
+	Tree     *Tree
+}
+
+// NewNode returns a new node with the value
+func NewNode(val int) *Node {
+	return &Node{Val: val, Named: make(map[string]*Node)}
+}
+
+// Push returns a new node with the value, linked to the node
+func (n *Node) Push(val int) *Node {
+	nn := NewNode(val)
+	nn.Next = n
+	return nn
+}
+
+// Add adds a child node with the value and returns it
+func (n *Node) Add(val int) *Node {
+	c := NewNode(val)
+	c.Tree = n.Tree
+	n.Children = append(n.Children, c)
+	return c
+}
+
+// Len returns the length of the linked list starting at the node
+func (n *Node) Len() int {
+	l := 0
+	for ; n != nil; n = n.Next {
+		l++
+	}
+	return l
+}
+
+// Sum returns the sum of the values of the tree rooted at the node
+func (n *Node) Sum() int {
+	if n == nil {
+		return 0
+	}
+	s := n.Val
+	for _, c := range n.Children {
+		s += c.Sum()
+	}
+	return s
+}
+
+// Tree is a tree of nodes, pointing back to its tree
+type Tree struct {
+	Root *Node
+	Subs []*Tree
+}
+
+// NewTree returns a new tree with a root node of the value
+func NewTree(val int) *Tree {
+	t := &Tree{}
+	t.Root = NewNode(val)
+	t.Root.Tree = t
+	return t
+}
+
+// List is a list of lists
+type List []List
+
+// Nest returns a list nested to the depth
+func Nest(depth int) List {
+	if depth == 0 {
+		return List{}
+	}
+	return List{Nest(depth - 1)}
+}
+
+// Depth returns the depth of the nested list
+func Depth(l List) int {
+	d := 0
+	for len(l) > 0 {
+		l = l[0]
+		d++
+	}
+	return d
+}
+
+// Dict is a map of dicts
+type Dict map[string]Dict
+
+// Path returns a dict with the keys nested in order
+func Path(keys ...string) Dict {
+	d := Dict{}
+	for i := len(keys) - 1; i >= 0; i-- {
+		d = Dict{keys[i]: d}
+	}
+	return d
+}
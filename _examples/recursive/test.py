@@ -0,0 +1,40 @@
+# Copyright 2024 The go-python Authors.  All rights reserved.
+# Use of this source code is governed by a BSD-style
+# license that can be found in the LICENSE file.
+
+from __future__ import print_function
+
+import recursive
+
+t = recursive.NewTree(1)
+root = t.Root
+c = root.Add(2)
+root.Add(3)
+c.Add(4)
+print("root.Sum():", root.Sum())
+print("len(root.Children):", len(root.Children))
+print("root.Children[0].Children[0].Val:", root.Children[0].Children[0].Val)
+print("c.Tree.Root.Val:", c.Tree.Root.Val)
+print("c.Next:", c.Next)
+
+n = root.Push(5).Push(6)
+vals = []
+while n is not None:
+    vals.append(n.Val)
+    n = n.Next
+print("list:", vals)
+
+root.Next = root
+print("root.Next.Next.Val:", root.Next.Next.Val)
+print("cycle in str(root):", "Next=..." in str(root))
+print("cycle in str(t):", "Tree=..." in str(t))
+root.Next = None
+print("root.Next:", root.Next)
+
+print("Depth(Nest(3)):", recursive.Depth(recursive.Nest(3)))
+print("Depth(Nest(3)[0]):", recursive.Depth(recursive.Nest(3)[0]))
+d = recursive.Path("a", "b", "c")
+print("d keys:", list(d.keys()), list(d["a"].keys()), list(d["a"]["b"].keys()))
+print("len(d['a']['b']['c']):", len(d["a"]["b"]["c"]))
+
+print("OK")
@@ -0,0 +1,12 @@
+// Copyright 2024 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// package extra is a second package of the reexport test, whose python
+// module is imported lazily.
+package extra
+
+// Twice returns twice n
+func Twice(n int) int {
+	return 2 * n
+}
@@ -0,0 +1,26 @@
+// Copyright 2024 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// package reexport tests the re-exporting of the symbols of the package
+// at the top level of the output package, with the -reexport option, and
+// the lazy importing of the modules of the other packages.
+package reexport
+
+// Answer is a constant
+const Answer = 42
+
+// Point is a struct
+type Point struct {
+	X, Y int
+}
+
+// Sum returns the sum of the coordinates of the point
+func (p Point) Sum() int {
+	return p.X + p.Y
+}
+
+// Hello returns a greeting for the name
+func Hello(name string) string {
+	return "hello, " + name
+}
@@ -0,0 +1,21 @@
+# Copyright 2024 The go-python Authors.  All rights reserved.
+# Use of this source code is governed by a BSD-style
+# license that can be found in the LICENSE file.
+
+from __future__ import print_function
+
+import sys
+
+import outname
+
+print("extra imported:", "outname.extra" in sys.modules, "extra" in dir(outname))
+
+print(outname.Hello("you"))
+p = outname.Point(X=1, Y=2)
+print("sum:", p.Sum(), "answer:", outname.Answer)
+print("module:", type(p).__module__)
+print("same:", outname.Point is outname.reexport.Point)
+print("all:", "reexport" in outname.__all__, "Hello" in outname.__all__)
+print("extra.Twice:", outname.extra.Twice(2), "extra imported:", "outname.extra" in sys.modules)
+
+print("OK")
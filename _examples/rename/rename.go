@@ -5,6 +5,8 @@
 // package rename tests changing the names of methods and functions
 package rename
 
+//go:generate gopy gen -rename -no-warn=false .
+
 // gopy:name say_hi_fn
 // Comment follows the tag and function should be renamed
 // to say_hi_fn
@@ -18,7 +20,14 @@ func AutoRenamedFunc() {
 
 }
 
-// MyStruct has two fields
+// I should be renamed to directive_fn by the directive
+//
+//gopy:name directive_fn
+func DirectiveFunc() string {
+	return "directive fn"
+}
+
+// MyStruct has three fields
 type MyStruct struct {
 	// I should be renamed to auto_renamed_property
 	// when generated with -rename flag
@@ -26,6 +35,10 @@ type MyStruct struct {
 
 	// I should be renamed to custom_name with the custom option
 	AutoRenamedProperty2 string `gopy:"custom_name"`
+
+	// I should be renamed to directive_property by the directive
+	//gopy:name directive_property
+	DirectiveProperty string
 }
 
 // A method that says something
@@ -34,6 +47,13 @@ func (s *MyStruct) SaySomethingFunc() (something string) {
 	return "something"
 }
 
+// I should be renamed to directive_meth by the directive
+//
+//gopy:name directive_meth
+func (s *MyStruct) DirectiveMeth() string {
+	return "directive meth"
+}
+
 // I should be renamed to auto_renamed_meth, when generated
 // with -rename flag
 func (s *MyStruct) AutoRenamedMeth() {
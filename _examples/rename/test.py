@@ -9,6 +9,8 @@ import rename
 
 print("say_hi_fn():", rename.say_hi_fn())
 print("MyStruct().say_something():", rename.MyStruct().say_something())
+print("directive_fn():", rename.directive_fn())
+print("MyStruct().directive_meth():", rename.MyStruct().directive_meth())
 
 # Just make sure the symbols exist
 rename.auto_renamed_func()
@@ -18,8 +20,11 @@ _ = struct.auto_renamed_property
 struct.auto_renamed_property = "foo"
 _ = struct.custom_name
 struct.custom_name = "foo"
+struct.directive_property = "bar"
+print("MyStruct(directive_property=...).directive_property:", rename.MyStruct(directive_property="baz").directive_property)
 
 print("MyStruct.auto_renamed_property.__doc__:", rename.MyStruct.auto_renamed_property.__doc__.strip())
 print("MyStruct.custom_name.__doc__:", rename.MyStruct.custom_name.__doc__.strip())
+print("MyStruct.directive_property.__doc__:", rename.MyStruct.directive_property.__doc__.strip())
 
 print("OK")
@@ -0,0 +1,86 @@
+// Copyright 2024 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// package runes tests rune and []rune values, passed as single character
+// python strs and strs.
+package runes
+
+import (
+	"unicode"
+)
+
+// Sep is the separator of the words.
+var Sep = '·'
+
+// Glyph is a named rune.
+type Glyph rune
+
+// Word is a word of runes.
+type Word struct {
+	Text    []rune
+	Initial rune
+	Mark    Glyph
+}
+
+// NewWord returns the word of the text.
+func NewWord(text string) *Word {
+	r := []rune(text)
+	w := &Word{Text: r, Mark: '*'}
+	if len(r) > 0 {
+		w.Initial = r[0]
+	}
+	return w
+}
+
+// Title returns the word with its initial in upper case.
+func (w *Word) Title() []rune {
+	t := append([]rune{}, w.Text...)
+	if len(t) > 0 {
+		t[0] = unicode.ToUpper(t[0])
+	}
+	return t
+}
+
+// Upper returns the upper case of the rune.
+func Upper(r rune) rune {
+	return unicode.ToUpper(r)
+}
+
+// CodePoint returns the code point of the rune.
+func CodePoint(r rune) int {
+	return int(r)
+}
+
+// Invalid returns an invalid rune.
+func Invalid() rune {
+	return 0xD800
+}
+
+// Reverse returns the runes in reverse order.
+func Reverse(r []rune) []rune {
+	rev := make([]rune, len(r))
+	for i, c := range r {
+		rev[len(r)-1-i] = c
+	}
+	return rev
+}
+
+// Count returns the number of the runes.
+func Count(rs ...rune) int {
+	return len(rs)
+}
+
+// Boxed returns the glyph as a Glyph.
+func Boxed(g Glyph) Glyph {
+	return g + 1
+}
+
+// Map returns the runes of the string mapped by f.
+func Map(f func(r rune) rune, s string) string {
+	rs := []rune(s)
+	for i, r := range rs {
+		rs[i] = f(r)
+	}
+	return string(rs)
+}
@@ -0,0 +1,44 @@
+# Copyright 2024 The go-python Authors.  All rights reserved.
+# Use of this source code is governed by a BSD-style
+# license that can be found in the LICENSE file.
+
+from __future__ import print_function
+
+import runes
+
+print("Sep:", runes.Sep())
+runes.Set_Sep('-')
+print("Sep after:", runes.Sep())
+print("Upper('é'):", runes.Upper('é'))
+print("Upper(97):", runes.Upper(97))
+print("CodePoint('😀'):", runes.CodePoint('😀'))
+print("Invalid:", hex(ord(runes.Invalid())))
+print("Reverse:", runes.Reverse('héllo'))
+print("Count:", runes.Count('a', 'b', 'c'))
+print("Boxed:", runes.Boxed('a'))
+print("Map:", runes.Map(lambda c: c.upper(), 'naïve'))
+
+w = runes.NewWord('élan')
+print("w.Text:", w.Text)
+print("w.Initial:", w.Initial)
+print("w.Mark:", w.Mark)
+print("w.Title():", w.Title())
+w.Text = 'zèbre'
+w.Initial = 'z'
+print("w.Title() after:", w.Title())
+
+for bad in ['ab', '', 0xD800, -1]:
+    try:
+        runes.Upper(bad)
+    except ValueError as e:
+        print("ValueError:", e)
+try:
+    w.Initial = 1.5
+except TypeError as e:
+    print("TypeError:", e)
+try:
+    w.Text = ['a']
+except TypeError as e:
+    print("TypeError:", e)
+
+print("OK")
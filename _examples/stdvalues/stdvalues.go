@@ -0,0 +1,72 @@
+// Copyright 2024 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// package stdvalues tests the conversion of the common value types of the
+// standard library, net.IP, url.URL and *time.Location, to and from python
+// ipaddress addresses, strs and zoneinfo.ZoneInfo.
+package stdvalues
+
+import (
+	"net"
+	"net/url"
+	"time"
+)
+
+// Loopback returns the IPv4 loopback address, of 16 bytes in Go.
+func Loopback() net.IP {
+	return net.IPv4(127, 0, 0, 1)
+}
+
+// IsPrivate returns whether ip is a private address.
+func IsPrivate(ip net.IP) bool {
+	return ip.IsPrivate()
+}
+
+// Mask returns ip masked by the prefix of n bits.
+func Mask(ip net.IP, n int) net.IP {
+	bits := 8 * len(ip)
+	if ip4 := ip.To4(); ip4 != nil {
+		ip, bits = ip4, 32
+	}
+	return ip.Mask(net.CIDRMask(n, bits))
+}
+
+// Parse parses the URL.
+func Parse(raw string) (*url.URL, error) {
+	return url.Parse(raw)
+}
+
+// Host returns the host of the URL.
+func Host(u *url.URL) string {
+	return u.Host
+}
+
+// WithPath returns u with the path.
+func WithPath(u url.URL, path string) url.URL {
+	u.Path = path
+	return u
+}
+
+// Zone returns the location of the time zone name.
+func Zone(name string) (*time.Location, error) {
+	return time.LoadLocation(name)
+}
+
+// ZoneName returns the name of the location.
+func ZoneName(loc *time.Location) string {
+	return loc.String()
+}
+
+// Offset returns the UTC offset of the location at the unix time sec, in seconds.
+func Offset(loc *time.Location, sec int64) int {
+	_, off := time.Unix(sec, 0).In(loc).Zone()
+	return off
+}
+
+// Server is the endpoint of a server.
+type Server struct {
+	Addr net.IP
+	URL  *url.URL
+	TZ   *time.Location
+}
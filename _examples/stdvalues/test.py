@@ -0,0 +1,48 @@
+# Copyright 2024 The go-python Authors.  All rights reserved.
+# Use of this source code is governed by a BSD-style
+# license that can be found in the LICENSE file.
+
+from __future__ import print_function
+
+import datetime
+import ipaddress
+import zoneinfo
+
+import stdvalues
+
+lo = stdvalues.Loopback()
+print("Loopback():", repr(lo))
+print("IsPrivate(10.1.2.3):", stdvalues.IsPrivate(ipaddress.ip_address("10.1.2.3")))
+print("IsPrivate('8.8.8.8'):", stdvalues.IsPrivate("8.8.8.8"))
+print("Mask(2001:db8::1, 32):", stdvalues.Mask(ipaddress.ip_address("2001:db8::1"), 32))
+try:
+    stdvalues.IsPrivate("not an ip")
+except ValueError as e:
+    print("caught ValueError:", e)
+
+u = stdvalues.Parse("https://example.com:8080/a?q=1")
+print("Parse():", repr(u))
+print("Host():", stdvalues.Host(u))
+print("WithPath():", stdvalues.WithPath("https://example.com/a", "/b"))
+try:
+    stdvalues.Host("http://[::1")
+except ValueError as e:
+    print("caught ValueError:", e)
+
+z = stdvalues.Zone("Europe/Paris")
+print("Zone():", repr(z))
+print("ZoneName(ZoneInfo):", stdvalues.ZoneName(zoneinfo.ZoneInfo("Asia/Tokyo")))
+print("ZoneName(None):", stdvalues.ZoneName(None))
+print("Offset(Asia/Tokyo):", stdvalues.Offset("Asia/Tokyo", 0))
+print("Offset(timezone(+2h)):", stdvalues.Offset(datetime.timezone(datetime.timedelta(hours=2)), 0))
+print("Zone(Local):", stdvalues.Zone("Local"))
+
+s = stdvalues.Server()
+s.Addr = "192.168.1.1"
+s.URL = "http://192.168.1.1/status"
+s.TZ = zoneinfo.ZoneInfo("UTC")
+print("s.Addr:", repr(s.Addr))
+print("s.URL:", s.URL)
+print("s.TZ:", s.TZ)
+
+print("OK")
@@ -0,0 +1,44 @@
+// Copyright 2024 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// package strhandles tests the string handles of the -handle=string option.
+package strhandles
+
+import "context"
+
+// Person is a person.
+type Person struct {
+	Name    string
+	Friends []*Person
+}
+
+// NewPerson returns a new person.
+func NewPerson(name string) *Person {
+	return &Person{Name: name}
+}
+
+// Befriend adds the friend to the friends of the person.
+func (p *Person) Befriend(f *Person) {
+	p.Friends = append(p.Friends, f)
+}
+
+// Find returns the friend of the person of the name, or nil.
+func (p *Person) Find(name string) *Person {
+	for _, f := range p.Friends {
+		if f.Name == name {
+			return f
+		}
+	}
+	return nil
+}
+
+// Ages returns the ages of the names.
+func Ages() map[string]int {
+	return map[string]int{"ann": 30, "bob": 40}
+}
+
+// Done returns whether the context is done.
+func Done(ctx context.Context) bool {
+	return ctx.Err() != nil
+}
@@ -0,0 +1,32 @@
+# Copyright 2024 The go-python Authors.  All rights reserved.
+# Use of this source code is governed by a BSD-style
+# license that can be found in the LICENSE file.
+
+from __future__ import print_function
+
+import strhandles, go
+
+ann = strhandles.NewPerson("ann")
+bob = strhandles.NewPerson("bob")
+ann.Befriend(bob)
+
+print("handle type:", type(ann.handle).__name__)
+print("handle:", ann.handle.split("#")[0])
+print("Find bob:", ann.Find("bob").Name)
+print("Find eve handle:", repr(ann.Find("eve").handle))
+print("Friends:", [f.Name for f in ann.Friends])
+print("Friends handle:", ann.Friends.handle.split("#")[0])
+friends = ann.Friends
+friends.append(None)
+print("Friends with None:", len(friends))
+
+print("Done(None):", strhandles.Done())
+ctx = go.Context.with_cancel()
+ctx.cancel()
+print("Done(cancelled):", strhandles.Done(ctx))
+
+ages = strhandles.Ages()
+print("Ages handle:", ages.handle.split("#")[0])
+print("Ages:", sorted(ages.items()))
+
+print("OK")
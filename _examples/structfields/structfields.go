@@ -0,0 +1,74 @@
+// Copyright 2024 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// package structfields tests the struct fields of slice, map, array,
+// struct, pointer and interface types, aliasing the fields of the Go
+// struct.
+package structfields
+
+import "strings"
+
+// Point is a point.
+type Point struct {
+	X, Y int
+}
+
+// Named has a name.
+type Named interface {
+	Name() string
+}
+
+// Label is a Named label.
+type Label string
+
+// Name returns the label.
+func (l Label) Name() string {
+	return string(l)
+}
+
+// NewLabel returns a new label, as a Named.
+func NewLabel(s string) Named {
+	return Label(s)
+}
+
+// Shape is a shape with composite fields.
+type Shape struct {
+	Tags   []string
+	Attrs  map[string]int
+	Origin Point
+	Anchor *Point
+	Path   []Point
+	Box    [2]Point
+	Label  Named
+}
+
+// NewShape returns a new shape.
+func NewShape() *Shape {
+	return &Shape{
+		Tags:  []string{"a"},
+		Attrs: map[string]int{"w": 1},
+		Path:  []Point{{1, 2}},
+	}
+}
+
+// Describe describes the shape, as seen from Go.
+func (s *Shape) Describe() string {
+	var sb strings.Builder
+	sb.WriteString(strings.Join(s.Tags, ","))
+	for _, k := range []string{"w", "h"} {
+		if v, ok := s.Attrs[k]; ok {
+			sb.WriteString(" " + k + "=" + string(rune('0'+v)))
+		}
+	}
+	sb.WriteString(" origin=" + string(rune('0'+s.Origin.X)) + string(rune('0'+s.Origin.Y)))
+	if s.Anchor != nil {
+		sb.WriteString(" anchor")
+	}
+	sb.WriteString(" path=" + string(rune('0'+len(s.Path))))
+	sb.WriteString(" box=" + string(rune('0'+s.Box[1].X)))
+	if s.Label != nil {
+		sb.WriteString(" label=" + s.Label.Name())
+	}
+	return sb.String()
+}
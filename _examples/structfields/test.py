@@ -0,0 +1,48 @@
+# Copyright 2024 The go-python Authors.  All rights reserved.
+# Use of this source code is governed by a BSD-style
+# license that can be found in the LICENSE file.
+
+from __future__ import print_function
+
+import structfields
+
+s = structfields.NewShape()
+print("initial:", s.Describe())
+
+# the fields alias those of the Go struct
+s.Tags.append("b")
+s.Attrs["h"] = 2
+s.Origin.X = 3
+s.Path[0].Y = 5
+s.Box[1].X = 7
+print("aliased:", s.Describe())
+print("Path[0]:", s.Path[0].X, s.Path[0].Y)
+
+# nil pointers and interfaces are None, and can be set to None
+print("Anchor:", s.Anchor, "Label:", s.Label)
+s.Anchor = structfields.Point(X=1, Y=1)
+s.Label = structfields.NewLabel("L")
+print("set:", s.Describe())
+print("Label.Name:", s.Label.Name())
+s.Anchor = None
+s.Label = None
+print("unset:", s.Describe(), s.Anchor, s.Label)
+
+# struct values are copied, from a struct or a dict
+p = structfields.Point(X=4, Y=6)
+s.Origin = p
+p.X = 9
+print("Origin:", s.Origin.X, s.Origin.Y)
+s.Origin = {"X": 1, "Y": 2}
+print("Origin from dict:", s.Origin.X, s.Origin.Y)
+try:
+    s.Origin = 42
+except TypeError as e:
+    print("TypeError:", "dict" in str(e))
+
+# slices and maps are copied from python lists and dicts
+s.Tags = ["x", "y"]
+s.Attrs = {"w": 4}
+print("copied:", s.Describe())
+
+print("OK")
@@ -0,0 +1,58 @@
+// Copyright 2024 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// package structseq tests slices of structs and of pointers to structs.
+package structseq
+
+// Item is an item
+type Item struct {
+	Name  string
+	Count int
+}
+
+// Label returns the label of the item
+func (it *Item) Label() string {
+	return it.Name
+}
+
+// Items returns a slice of items
+func Items() []Item {
+	return []Item{{"a", 1}, {"b", 2}}
+}
+
+// ItemPtrs returns a slice of pointers to items
+func ItemPtrs() []*Item {
+	return []*Item{{"x", 10}, {"y", 20}, nil}
+}
+
+// Total returns the total count of the items
+func Total(items []Item) int {
+	n := 0
+	for _, it := range items {
+		n += it.Count
+	}
+	return n
+}
+
+// TotalPtrs returns the total count of the items
+func TotalPtrs(items []*Item) int {
+	n := 0
+	for _, it := range items {
+		if it != nil {
+			n += it.Count
+		}
+	}
+	return n
+}
+
+// Bag holds items
+type Bag struct {
+	Items []Item
+	Ptrs  []*Item
+}
+
+// NewBag returns a new bag
+func NewBag() *Bag {
+	return &Bag{Items: Items(), Ptrs: ItemPtrs()}
+}
@@ -0,0 +1,37 @@
+# Copyright 2024 The go-python Authors.  All rights reserved.
+# Use of this source code is governed by a BSD-style
+# license that can be found in the LICENSE file.
+
+from __future__ import print_function
+
+import structseq
+
+items = structseq.Items()
+print("len(items):", len(items))
+print("items[0]:", items[0].Name, items[0].Count, items[0].Label())
+print("names:", [it.Name for it in items])
+
+# elements share the element of the slice
+items[1].Count = 40
+print("Total(items):", structseq.Total(items))
+
+# python lists of structs convert to slices
+print("Total(list):", structseq.Total([structseq.Item(Name="c", Count=3), structseq.Item(Name="d", Count=4)]))
+
+ptrs = structseq.ItemPtrs()
+print("ptrs:", [it.Name if it is not None else None for it in ptrs])
+print("ptrs[-1]:", ptrs[-1])
+ptrs[0].Count = 11
+ptrs[1] = None
+ptrs.append(structseq.Item(Name="z", Count=5))
+print("TotalPtrs(ptrs):", structseq.TotalPtrs(ptrs))
+print("TotalPtrs(list):", structseq.TotalPtrs([structseq.Item(Count=1), None, structseq.Item(Count=2)]))
+
+b = structseq.NewBag()
+print("b.Items:", [it.Name for it in b.Items])
+b.Items = [structseq.Item(Name="n", Count=9)]
+print("b.Items:", [(it.Name, it.Count) for it in b.Items])
+b.Ptrs = [structseq.Item(Name="p")]
+print("b.Ptrs:", [it.Name for it in b.Ptrs])
+
+print("OK")
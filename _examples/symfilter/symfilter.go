@@ -0,0 +1,67 @@
+// Copyright 2024 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// package symfilter tests the skipping of symbols with the -exclude
+// option and the //gopy:skip directive, and the hiding of struct fields
+// with json:"-" tags and the //gopy:hide directive.
+package symfilter
+
+import "sync"
+
+// Config is a configuration.
+type Config struct {
+	// Name is the name of the configuration.
+	Name string
+
+	// Secret is not bound.
+	//gopy:skip
+	Secret string
+
+	// Cache uses a skipped type, so it is not bound either.
+	Cache *Cache
+
+	// Mu is hidden by its json tag.
+	Mu *sync.Mutex `json:"-"`
+
+	// Token is hidden by the hide directive.
+	//gopy:hide
+	Token string
+
+	// Raw has a json tag not hiding it.
+	Raw string `json:"raw,omitempty"`
+}
+
+// Describe describes the configuration.
+func (c *Config) Describe() string {
+	return "config " + c.Name
+}
+
+// Reset is not bound.
+//
+//gopy:skip
+func (c *Config) Reset() {
+	c.Name = ""
+}
+
+// Cache is not bound.
+//
+//gopy:skip
+type Cache struct {
+	Size int
+}
+
+// NewCache uses a skipped type, so it is not bound.
+func NewCache() *Cache {
+	return &Cache{}
+}
+
+// Version returns the version.
+func Version() string {
+	return "1.0"
+}
+
+// DebugDump is excluded by the -exclude option of the test.
+func DebugDump() string {
+	return "dump"
+}
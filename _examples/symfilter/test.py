@@ -0,0 +1,18 @@
+# Copyright 2024 The go-python Authors.  All rights reserved.
+# Use of this source code is governed by a BSD-style
+# license that can be found in the LICENSE file.
+
+from __future__ import print_function
+
+import symfilter
+
+print("Version:", symfilter.Version())
+for name in ["Config", "Cache", "NewCache", "DebugDump"]:
+    print("has %s: %s" % (name, hasattr(symfilter, name)))
+
+c = symfilter.Config(Name="prod")
+print(c.Describe())
+for name in ["Name", "Secret", "Cache", "Mu", "Token", "Raw", "Describe", "Reset"]:
+    print("Config has %s: %s" % (name, hasattr(c, name)))
+
+print("OK")
@@ -0,0 +1,49 @@
+# Copyright 2024 The go-python Authors.  All rights reserved.
+# Use of this source code is governed by a BSD-style
+# license that can be found in the LICENSE file.
+
+from __future__ import print_function
+
+import threading
+
+import threadchecks, go
+
+
+def in_thread(fn):
+    t = threading.Thread(target=fn, name="worker")
+    t.start()
+    t.join()
+
+
+c = threadchecks.Counter()
+c.Inc()
+print("N:", c.N)
+
+
+def use():
+    try:
+        c.Inc()
+    except go.ThreadError as e:
+        print("ThreadError:", e)
+    try:
+        c.N = 5
+    except go.ThreadError:
+        print("ThreadError on field")
+
+
+in_thread(use)
+print("N after:", c.N)
+
+# handing the counter over to the worker
+go.release_thread(c)
+in_thread(lambda: c.Inc())
+# the worker has exited: the main thread takes the counter back
+c.Inc()
+print("N handed over:", c.N)
+
+s = threadchecks.SafeCounter()
+s.Inc()
+in_thread(lambda: s.Inc())
+print("SafeCounter:", s.Value())
+
+print("OK")
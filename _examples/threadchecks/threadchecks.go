@@ -0,0 +1,42 @@
+// Copyright 2024 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// package threadchecks tests the checks of the -thread-checks option,
+// raising go.ThreadError when the structs not safe for concurrent use are
+// used from several python threads.
+package threadchecks
+
+import "sync"
+
+// Counter is a counter not safe for concurrent use.
+type Counter struct {
+	N int
+}
+
+// Inc increments the counter.
+func (c *Counter) Inc() {
+	c.N++
+}
+
+// SafeCounter is a counter safe for concurrent use.
+//
+//gopy:threadsafe
+type SafeCounter struct {
+	mu sync.Mutex
+	n  int
+}
+
+// Inc increments the counter.
+func (c *SafeCounter) Inc() {
+	c.mu.Lock()
+	c.n++
+	c.mu.Unlock()
+}
+
+// Value returns the value of the counter.
+func (c *SafeCounter) Value() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.n
+}
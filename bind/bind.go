@@ -10,14 +10,22 @@ import (
 	"fmt"
 	"io"
 	"os"
+	"sync"
 )
 
 // BindCfg is a configuration used during binding generation
 type BindCfg struct {
 	// output directory for bindings
 	OutputDir string
-	// name of output package (otherwise name of first package is used)
+	// name of output package (otherwise name of first package is used),
+	// the last component of a dotted name set with SetName
 	Name string
+	// dotted python package containing the output package, if any,
+	// from a dotted name set with SetName, or SetEmbedded
+	Parent string
+	// the output package is the _gopy subpackage of the existing python
+	// package Parent, see SetEmbedded
+	Embedded bool
 	// code string to run in the go main() function in the cgo library
 	Main string
 	// the full command args as a string, without path to exe
@@ -28,16 +36,94 @@ type BindCfg struct {
 	PkgPrefix string
 	// rename Go exported symbols to python PEP snake_case
 	RenameCase bool
+	// generate an __init__.py re-exporting the symbols of the primary
+	// package at the top level
+	InitExports bool
+	// name of the primary Go package, the first one given,
+	// otherwise the first one bound is used
+	Primary string
+	// generate Go benchmarks of the conversions, with perf Makefile targets
+	GenPerf bool
+	// generate a compile_commands.json and VS Code settings for IDEs
+	GenIDE bool
+	// type of the handles of the Go values passed to python: int64, the
+	// default if empty, or string, see SetHandleType
+	Handle string
+	// number of shards of the handle registry, to reduce the contention
+	// for its lock: 0 or 1 for one, -1 for one per CPU, see gopyh.SetShards
+	HandleShards int
+	// generate checks raising go.ThreadError when the structs not safe for
+	// concurrent use are used from several python threads, see gen_threads.go
+	ThreadChecks bool
+	// check that the python buffers of the borrowed string params do not
+	// change during the calls, raising a RuntimeError, see gen_borrow.go
+	BorrowChecks bool
+	// do not check the args of the functions in python by the checks
+	// recognized in their Go code, only by their check directives, see
+	// gen_checks.go
+	NoArgChecks bool
+	// generate an async twin of each function and method, Func_async,
+	// returning an asyncio future of its result, see gen_async.go
+	AsyncAPI bool
+	// directory of Go files copied into the generated main package,
+	// with their exported functions bound, see gen_extra.go
+	ExtraGo string
+	// generate pytest smoke tests in a tests/ subdirectory
+	GenTests bool
+	// generate a sample python application, app.py, at the root of the
+	// python package, see gen_app.go
+	GenApp bool
+	// format of the API reference docs generated in a docs/ subdirectory:
+	// rst (Sphinx reStructuredText), md (Markdown), or none if empty
+	DocFormat string
+	// generate a Sphinx conf.py along with the API reference docs
+	DocConf bool
+	// add pretty() and to_yaml() methods to the python classes,
+	// rendering the Go values for debugging
+	Pretty bool
+	// check the python floats losing precision as Go float32,
+	// warning with go.LossyConversionWarning
+	LossyChecks bool
+	// comma-separated mappings of go errors to the python builtin exceptions
+	// they are raised as, e.g., io/fs.ErrNotExist=FileNotFoundError,
+	// see gen_errors.go
+	ErrorMap string
+	// txtar file of the fragments of Go and python code injected into the
+	// generated functions and methods, see gen_hooks.go
+	Hooks string
+	// directory of the templates of the preambles of the generated files
+	// and of the Makefile overriding the builtin ones, see gen_templates.go
+	Templates string
+	// raise go.LossyConversionError for lossy conversions instead of warning,
+	// and fail on exported struct fields of unsupported types
+	Strict bool
+	// target GOOS and GOARCH of cross builds, the host ones if empty,
+	// see cross.go
+	GOOS   string
+	GOARCH string
+	// JSON file of the sysconfig config vars of the target python, used
+	// instead of those of VM, e.g., for cross builds
+	PyConfig string
+	// build system of the build file generated along with the bindings:
+	// make (Makefile), the default if empty, cmake (CMakeLists.txt), see
+	// gen_cmake.go, or bazel (BUILD.bazel), see gen_bazel.go
+	BuildSystem string
 }
 
 // ErrorList is a list of errors
 type ErrorList []error
 
+// errorListMu guards the additions to the error lists, of the packages
+// generated in parallel
+var errorListMu sync.Mutex
+
 func (list *ErrorList) Add(err error) {
 	if err == nil {
 		return
 	}
+	errorListMu.Lock()
 	*list = append(*list, err)
+	errorListMu.Unlock()
 }
 
 func (list *ErrorList) Error() error {
@@ -0,0 +1,623 @@
+// Copyright 2024 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package bind
+
+import (
+	"go/types"
+)
+
+// typeConverter maps a Go type onto a native python type, which is
+// passed by value in both directions instead of as an opaque handle.
+type typeConverter struct {
+	goname string // fully-qualified go type name, e.g., time.Time
+	pysig  string // type string for doc-signatures
+	go2py  string // name of go->py converter function
+	py2go  string // name of py->go converter function
+	zval   string // zero value representation
+	cpre   string // C code for the cgo preamble
+	gopre  string // Go code for the converter functions
+}
+
+// typeConverters is the table of builtin type converters.
+// The converter code is only emitted when the type is used, once for the
+// converters sharing it, as those of the uuid packages.
+var typeConverters = []*typeConverter{
+	{
+		goname: "time.Time",
+		pysig:  "datetime",
+		go2py:  "timeGoToPy",
+		py2go:  "timePyToGo",
+		zval:   "time.Time{}",
+		cpre: `
+static inline void gopy_datetime_fields(PyObject* o, int* f) {
+	f[0] = PyDateTime_GET_YEAR(o);
+	f[1] = PyDateTime_GET_MONTH(o);
+	f[2] = PyDateTime_GET_DAY(o);
+	f[3] = PyDateTime_DATE_GET_HOUR(o);
+	f[4] = PyDateTime_DATE_GET_MINUTE(o);
+	f[5] = PyDateTime_DATE_GET_SECOND(o);
+	f[6] = PyDateTime_DATE_GET_MICROSECOND(o);
+}
+static inline PyObject* gopy_datetime_utc(int* f) {
+	if (!gopy_datetime_import()) {
+		return NULL;
+	}
+	return PyDateTimeAPI->DateTime_FromDateAndTime(f[0], f[1], f[2], f[3], f[4], f[5], f[6], PyDateTime_TimeZone_UTC, PyDateTimeAPI->DateTimeType);
+}
+static inline int gopy_datetime_check(PyObject* o) {
+	return gopy_datetime_import() && PyDateTime_Check(o);
+}
+// gopy_datetime_as_utc returns a new reference to o in UTC -- naive datetimes are taken to be UTC
+static inline PyObject* gopy_datetime_as_utc(PyObject* o) {
+	PyObject* tz = PyObject_GetAttrString(o, "tzinfo");
+	if (tz == NULL) {
+		return NULL;
+	}
+	int naive = (tz == Py_None);
+	Py_DECREF(tz);
+	if (naive) {
+		Py_INCREF(o);
+		return o;
+	}
+	return PyObject_CallMethod(o, "astimezone", "O", PyDateTime_TimeZone_UTC);
+}
+`,
+		gopre: `
+// timeGoToPy converts a Go time.Time to a UTC python datetime.datetime
+func timeGoToPy(t time.Time) *C.PyObject {
+	_gstate := C.PyGILState_Ensure()
+	defer C.PyGILState_Release(_gstate)
+	t = t.UTC()
+	f := [7]C.int{C.int(t.Year()), C.int(t.Month()), C.int(t.Day()), C.int(t.Hour()), C.int(t.Minute()), C.int(t.Second()), C.int(t.Nanosecond() / 1000)}
+	return C.gopy_datetime_utc(&f[0])
+}
+
+// timePyToGo converts a python datetime.datetime to a Go time.Time in UTC.
+// A naive datetime (without tzinfo) is taken to be in UTC.
+func timePyToGo(o *C.PyObject) time.Time {
+	_gstate := C.PyGILState_Ensure()
+	defer C.PyGILState_Release(_gstate)
+	if C.gopy_datetime_check(o) == 0 {
+		gopyTypeError("expected a datetime.datetime")
+		return time.Time{}
+	}
+	u := C.gopy_datetime_as_utc(o)
+	if u == nil {
+		return time.Time{}
+	}
+	defer C.gopy_decref(u)
+	var f [7]C.int
+	C.gopy_datetime_fields(u, &f[0])
+	return time.Date(int(f[0]), time.Month(f[1]), int(f[2]), int(f[3]), int(f[4]), int(f[5]), int(f[6])*1000, time.UTC)
+}
+`,
+	},
+	{
+		goname: "time.Duration",
+		pysig:  "timedelta",
+		go2py:  "durationGoToPy",
+		py2go:  "durationPyToGo",
+		zval:   "0",
+		cpre: `
+static inline PyObject* gopy_timedelta(int d, int s, int us) {
+	if (!gopy_datetime_import()) {
+		return NULL;
+	}
+	return PyDelta_FromDSU(d, s, us);
+}
+static inline int gopy_timedelta_check(PyObject* o) {
+	return gopy_datetime_import() && PyDelta_Check(o);
+}
+static inline void gopy_timedelta_fields(PyObject* o, int* f) {
+	f[0] = PyDateTime_DELTA_GET_DAYS(o);
+	f[1] = PyDateTime_DELTA_GET_SECONDS(o);
+	f[2] = PyDateTime_DELTA_GET_MICROSECONDS(o);
+}
+`,
+		gopre: `
+// durationGoToPy converts a Go time.Duration to a python datetime.timedelta
+func durationGoToPy(d time.Duration) *C.PyObject {
+	_gstate := C.PyGILState_Ensure()
+	defer C.PyGILState_Release(_gstate)
+	const usPerDay = int64(24 * time.Hour / time.Microsecond)
+	us := int64(d / time.Microsecond)
+	days := us / usPerDay
+	us -= days * usPerDay
+	return C.gopy_timedelta(C.int(days), C.int(us/1000000), C.int(us%1000000))
+}
+
+// durationPyToGo converts a python datetime.timedelta to a Go time.Duration
+func durationPyToGo(o *C.PyObject) time.Duration {
+	_gstate := C.PyGILState_Ensure()
+	defer C.PyGILState_Release(_gstate)
+	if C.gopy_timedelta_check(o) == 0 {
+		gopyTypeError("expected a datetime.timedelta")
+		return 0
+	}
+	var f [3]C.int
+	C.gopy_timedelta_fields(o, &f[0])
+	return time.Duration(f[0])*24*time.Hour + time.Duration(f[1])*time.Second + time.Duration(f[2])*time.Microsecond
+}
+`,
+	},
+	{
+		goname: "[]rune",
+		pysig:  "str",
+		go2py:  "runesGoToPy",
+		py2go:  "runesPyToGo",
+		zval:   "nil",
+		cpre: `
+static inline int gopy_unicode_check(PyObject* o) { // macro
+	return PyUnicode_Check(o);
+}
+`,
+		gopre: `
+// runesGoToPy converts a Go []rune to a python str, the invalid code points
+// becoming U+FFFD, as for string(r)
+func runesGoToPy(r []rune) *C.PyObject {
+	_gstate := C.PyGILState_Ensure()
+	defer C.PyGILState_Release(_gstate)
+	s := string(r)
+	cs := C.CString(s)
+	defer C.free(unsafe.Pointer(cs))
+	return C.PyUnicode_FromStringAndSize(cs, C.Py_ssize_t(len(s)))
+}
+
+// runesPyToGo converts a python str to a Go []rune
+func runesPyToGo(o *C.PyObject) []rune {
+	_gstate := C.PyGILState_Ensure()
+	defer C.PyGILState_Release(_gstate)
+	if o == nil { // e.g., the result of a failed callback
+		return nil
+	}
+	if C.gopy_unicode_check(o) == 0 {
+		gopyTypeError("expected a str for Go []rune")
+		return nil
+	}
+	var n C.Py_ssize_t
+	cs := C.PyUnicode_AsUTF8AndSize(o, &n)
+	if cs == nil {
+		return nil
+	}
+	return []rune(C.GoStringN(cs, C.int(n)))
+}
+`,
+	},
+	{
+		goname: "github.com/google/uuid.UUID",
+		pysig:  "uuid.UUID",
+		go2py:  "uuidGoToPy",
+		py2go:  "uuidPyToGo",
+		zval:   "[16]byte{}",
+		gopre:  uuidConvGo,
+	},
+	{
+		goname: "github.com/gofrs/uuid.UUID",
+		pysig:  "uuid.UUID",
+		go2py:  "uuidGoToPy",
+		py2go:  "uuidPyToGo",
+		zval:   "[16]byte{}",
+		gopre:  uuidConvGo,
+	},
+	{
+		goname: "net.IP",
+		pysig:  "ipaddress",
+		go2py:  "ipGoToPy",
+		py2go:  "ipPyToGo",
+		zval:   "nil",
+		gopre: `
+// ipGoToPy converts a Go net.IP to a python ipaddress.IPv4Address, for the
+// IPv4 addresses, even of 16 bytes, or IPv6Address, or None if it is empty
+func ipGoToPy(ip []byte) *C.PyObject {
+	_gstate := C.PyGILState_Ensure()
+	defer C.PyGILState_Release(_gstate)
+	if len(ip) == 0 {
+		return C.gopy_conv_none()
+	}
+	if len(ip) == 16 && string(ip[:12]) == "\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\xff\xff" {
+		ip = ip[12:]
+	}
+	return gopyConvCall("ipaddress", "ip_address", C.PyBytes_FromStringAndSize((*C.char)(unsafe.Pointer(&ip[0])), C.Py_ssize_t(len(ip))))
+}
+
+// ipPyToGo converts a python ipaddress.IPv4Address or IPv6Address, or a
+// str of one, to a Go net.IP, None being nil
+func ipPyToGo(o *C.PyObject) []byte {
+	_gstate := C.PyGILState_Ensure()
+	defer C.PyGILState_Release(_gstate)
+	if o == nil || C.gopy_conv_is_none(o) != 0 {
+		return nil
+	}
+	if C.gopy_str_check(o) != 0 {
+		C.gopy_incref(o)
+		if o = gopyConvCall("ipaddress", "ip_address", o); o == nil {
+			return nil
+		}
+		defer C.gopy_decref(o)
+	}
+	var ip [16]byte
+	n := gopyConvBytes(o, "packed", ip[:])
+	if n < 0 {
+		gopyTypeError("expected an ipaddress.IPv4Address, IPv6Address or str for Go net.IP")
+		return nil
+	}
+	return append([]byte(nil), ip[:n]...)
+}
+`,
+	},
+	{
+		goname: "net/url.URL",
+		pysig:  "str",
+		go2py:  "urlGoToPy",
+		py2go:  "urlPyToGo",
+		zval:   "url.URL{}",
+		gopre: `
+// urlGoToPy converts a Go url.URL to a python str
+func urlGoToPy(u url.URL) *C.PyObject {
+	return urlPtrGoToPy(&u)
+}
+
+// urlPyToGo converts a python str to a Go url.URL, raising ValueError if
+// it cannot be parsed
+func urlPyToGo(o *C.PyObject) url.URL {
+	if u := urlPtrPyToGo(o); u != nil {
+		return *u
+	}
+	return url.URL{}
+}
+`,
+	},
+	{
+		goname: "*net/url.URL",
+		pysig:  "str",
+		go2py:  "urlPtrGoToPy",
+		py2go:  "urlPtrPyToGo",
+		zval:   "nil",
+		gopre: `
+// urlPtrGoToPy converts a Go *url.URL to a python str, or None if it is nil
+func urlPtrGoToPy(u *url.URL) *C.PyObject {
+	_gstate := C.PyGILState_Ensure()
+	defer C.PyGILState_Release(_gstate)
+	if u == nil {
+		return C.gopy_conv_none()
+	}
+	return gopyConvUnicode(u.String())
+}
+
+// urlPtrPyToGo converts a python str to a Go *url.URL, raising ValueError
+// if it cannot be parsed, None being nil
+func urlPtrPyToGo(o *C.PyObject) *url.URL {
+	_gstate := C.PyGILState_Ensure()
+	defer C.PyGILState_Release(_gstate)
+	if o == nil || C.gopy_conv_is_none(o) != 0 {
+		return nil
+	}
+	s, ok := gopyConvStr(o)
+	if !ok {
+		gopyTypeError("expected a str for Go url.URL")
+		return nil
+	}
+	u, err := url.Parse(s)
+	if err != nil {
+		gopyConvValueError(err.Error())
+		return nil
+	}
+	return u
+}
+`,
+	},
+	{
+		goname: "*time.Location",
+		pysig:  "zoneinfo.ZoneInfo",
+		go2py:  "locationGoToPy",
+		py2go:  "locationPyToGo",
+		zval:   "nil",
+		cpre: `
+// gopy_tz_offset sets the UTC offset of the python tzinfo in seconds,
+// returning -1 with a python exception set if it has none
+static inline int gopy_tz_offset(PyObject* o, long* secs) {
+	PyObject* d = PyObject_CallMethod(o, "utcoffset", "O", Py_None);
+	if (d == NULL) {
+		return -1;
+	}
+	if (!PyDelta_Check(d)) {
+		Py_DECREF(d);
+		PyErr_SetString(PyExc_TypeError, "expected a zoneinfo.ZoneInfo, str, tzinfo of a fixed offset or None for Go *time.Location");
+		return -1;
+	}
+	*secs = PyDateTime_DELTA_GET_DAYS(d) * 86400L + PyDateTime_DELTA_GET_SECONDS(d);
+	Py_DECREF(d);
+	return 0;
+}
+`,
+		gopre: `
+// locationGoToPy converts a Go *time.Location to a python
+// zoneinfo.ZoneInfo, of its name, or None for time.Local, as the naive
+// python datetimes are in local time, nil being UTC as in Go
+func locationGoToPy(loc *time.Location) *C.PyObject {
+	_gstate := C.PyGILState_Ensure()
+	defer C.PyGILState_Release(_gstate)
+	if loc == time.Local {
+		return C.gopy_conv_none()
+	}
+	return gopyConvCall("zoneinfo", "ZoneInfo", gopyConvUnicode(loc.String()))
+}
+
+// locationPyToGo converts a python zoneinfo.ZoneInfo, or str of the name
+// of a time zone, to a Go *time.Location loaded by time.LoadLocation, None
+// to time.Local, and other python tzinfos, e.g., datetime.timezone, to a
+// time.FixedZone of their UTC offset
+func locationPyToGo(o *C.PyObject) *time.Location {
+	_gstate := C.PyGILState_Ensure()
+	defer C.PyGILState_Release(_gstate)
+	if o == nil || C.gopy_conv_is_none(o) != 0 {
+		return time.Local
+	}
+	name, ok := gopyConvStr(o)
+	if !ok {
+		key := gopyConvAttr(o, "key")
+		if key == nil {
+			if C.gopy_datetime_import() == 0 {
+				return nil
+			}
+			var secs C.long
+			if C.gopy_tz_offset(o, &secs) < 0 {
+				return nil
+			}
+			s := C.PyObject_Str(o)
+			if s == nil {
+				return nil
+			}
+			defer C.gopy_decref(s)
+			name, _ = gopyConvStr(s)
+			return time.FixedZone(name, int(secs))
+		}
+		defer C.gopy_decref(key)
+		if name, ok = gopyConvStr(key); !ok {
+			gopyTypeError("expected a str key of zoneinfo.ZoneInfo for Go *time.Location")
+			return nil
+		}
+	}
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		gopyConvValueError(err.Error())
+		return nil
+	}
+	return loc
+}
+`,
+	},
+}
+
+const (
+	// common C code for the datetime converters
+	convDateTimePreC = `
+#include <datetime.h>
+static inline int gopy_datetime_import() {
+	if (PyDateTimeAPI == NULL) {
+		PyDateTime_IMPORT;
+	}
+	return PyDateTimeAPI != NULL;
+}
+`
+
+	// common C code for the converters calling python modules
+	convModulePreC = `
+static inline PyObject* gopy_conv_none() { // macro
+	Py_INCREF(Py_None);
+	return Py_None;
+}
+static inline int gopy_conv_is_none(PyObject* o) { // macro
+	return o == Py_None;
+}
+static inline int gopy_str_check(PyObject* o) { // macro
+	return PyUnicode_Check(o);
+}
+// gopy_call_module returns mod.fn(arg), or NULL with a python exception
+// set, stealing the reference to arg
+static inline PyObject* gopy_call_module(const char* mod, const char* fn, PyObject* arg) {
+	if (arg == NULL) {
+		return NULL;
+	}
+	PyObject* m = PyImport_ImportModule(mod);
+	PyObject* f = m == NULL ? NULL : PyObject_GetAttrString(m, fn);
+	Py_XDECREF(m);
+	PyObject* r = f == NULL ? NULL : PyObject_CallFunctionObjArgs(f, arg, NULL);
+	Py_XDECREF(f);
+	Py_DECREF(arg);
+	return r;
+}
+`
+
+	// go code of the uuid converters, of the uuid.UUID types of
+	// github.com/google/uuid and github.com/gofrs/uuid, arrays of 16 bytes
+	uuidConvGo = `
+// uuidGoToPy converts a Go uuid.UUID to a python uuid.UUID
+func uuidGoToPy(u [16]byte) *C.PyObject {
+	_gstate := C.PyGILState_Ensure()
+	defer C.PyGILState_Release(_gstate)
+	return gopyConvCall("uuid", "UUID", gopyConvUnicode(fmt.Sprintf("%x", u[:])))
+}
+
+// uuidPyToGo converts a python uuid.UUID, or str of one, to a Go uuid.UUID
+func uuidPyToGo(o *C.PyObject) (u [16]byte) {
+	_gstate := C.PyGILState_Ensure()
+	defer C.PyGILState_Release(_gstate)
+	if o == nil { // e.g., the result of a failed callback
+		return u
+	}
+	if C.gopy_str_check(o) != 0 {
+		C.gopy_incref(o)
+		if o = gopyConvCall("uuid", "UUID", o); o == nil {
+			return u
+		}
+		defer C.gopy_decref(o)
+	}
+	if gopyConvBytes(o, "bytes", u[:]) != 16 {
+		gopyTypeError("expected a uuid.UUID or str for Go uuid.UUID")
+	}
+	return u
+}
+`
+
+	// common Go code for the converters
+	convPreGo = `
+// gopyTypeError sets a python TypeError with the given message
+func gopyTypeError(msg string) {
+	estr := C.CString(msg)
+	C.PyErr_SetString(C.PyExc_TypeError, estr)
+	C.free(unsafe.Pointer(estr))
+}
+
+// gopyConvValueError sets a python ValueError with the given message
+func gopyConvValueError(msg string) {
+	estr := C.CString(msg)
+	C.PyErr_SetString(C.PyExc_ValueError, estr)
+	C.free(unsafe.Pointer(estr))
+}
+
+// gopyConvCall returns the result of the call of the function fn of the
+// python module mod with the arg, stealing the reference to the arg, or
+// nil with a python exception set
+func gopyConvCall(mod, fn string, arg *C.PyObject) *C.PyObject {
+	cm := C.CString(mod)
+	defer C.free(unsafe.Pointer(cm))
+	cf := C.CString(fn)
+	defer C.free(unsafe.Pointer(cf))
+	return C.gopy_call_module(cm, cf, arg)
+}
+
+// gopyConvUnicode returns a new python str of the Go string
+func gopyConvUnicode(s string) *C.PyObject {
+	cs := C.CString(s)
+	defer C.free(unsafe.Pointer(cs))
+	return C.PyUnicode_FromStringAndSize(cs, C.Py_ssize_t(len(s)))
+}
+
+// gopyConvStr returns the python str as a Go string, and false if it is
+// not a str
+func gopyConvStr(o *C.PyObject) (string, bool) {
+	if C.gopy_str_check(o) == 0 {
+		return "", false
+	}
+	var n C.Py_ssize_t
+	cs := C.PyUnicode_AsUTF8AndSize(o, &n)
+	if cs == nil {
+		C.PyErr_Clear()
+		return "", false
+	}
+	return C.GoStringN(cs, C.int(n)), true
+}
+
+// gopyConvAttr returns a new reference to the attribute of the python
+// object, or nil, clearing the python exception, if it has none
+func gopyConvAttr(o *C.PyObject, name string) *C.PyObject {
+	cs := C.CString(name)
+	defer C.free(unsafe.Pointer(cs))
+	a := C.PyObject_GetAttrString(o, cs)
+	if a == nil {
+		C.PyErr_Clear()
+	}
+	return a
+}
+
+// gopyConvBytes copies the python bytes attribute of the name of the
+// object to buf, returning their number, or -1, clearing the python
+// exception, if it has none, or it is longer than buf
+func gopyConvBytes(o *C.PyObject, name string, buf []byte) int {
+	b := gopyConvAttr(o, name)
+	if b == nil {
+		return -1
+	}
+	defer C.gopy_decref(b)
+	var p *C.char
+	var n C.Py_ssize_t
+	if C.PyBytes_AsStringAndSize(b, &p, &n) < 0 || int(n) > len(buf) {
+		C.PyErr_Clear()
+		return -1
+	}
+	return copy(buf, unsafe.Slice((*byte)(unsafe.Pointer(p)), int(n)))
+}
+`
+)
+
+// findTypeConverter returns the builtin converter for the given
+// fully-qualified go type name, or nil if there is none, or it is
+// turned off, as for time.Duration with NoTimedelta.
+func findTypeConverter(goname string) *typeConverter {
+	if NoTimedelta && goname == "time.Duration" {
+		return nil
+	}
+	for _, tc := range typeConverters {
+		if tc.goname == goname {
+			return tc
+		}
+	}
+	return nil
+}
+
+// addConvertedType adds a symbol for a type having a builtin converter.
+// These are treated as basic types that are passed by value as PyObject*.
+func (sym *symtab) addConvertedType(pkg *types.Package, obj types.Object, t types.Type, kind symkind, id, n string, tc *typeConverter) error {
+	fn := sym.fullTypeString(t)
+	sym.syms[fn] = &symbol{
+		gopkg:   pkg,
+		goobj:   obj,
+		gotyp:   t,
+		kind:    kind | skBasic,
+		id:      id,
+		goname:  n,
+		cgoname: "*C.PyObject",
+		cpyname: "PyObject*",
+		pysig:   tc.pysig,
+		go2py:   tc.go2py,
+		py2go:   tc.py2go,
+		zval:    tc.zval,
+		pyfmt:   "O&",
+	}
+	return nil
+}
+
+// isConverted returns true if the symbol is for a type with a builtin converter,
+// or for a struct bound as a python dataclass
+func (s *symbol) isConverted() bool {
+	if isDataclass(s) {
+		return true
+	}
+	return s.isType() && s.gotyp != nil && findTypeConverter(types.TypeString(s.gotyp, nil)) != nil
+}
+
+// usedTypeConverters returns the type converters for types that
+// appear in the current symbol table, in table order.
+func usedTypeConverters() []*typeConverter {
+	var used []*typeConverter
+	for _, tc := range typeConverters {
+		if s := current.sym(tc.goname); s != nil && s.isConverted() {
+			used = append(used, tc)
+		}
+	}
+	return used
+}
+
+// genConvPreamble returns the C and Go preamble code for the
+// type converters used in the current symbol table.
+func genConvPreamble() (cpre, gopre string) {
+	used := usedTypeConverters()
+	if len(used) == 0 {
+		return "", ""
+	}
+	cpre = convDateTimePreC + convModulePreC
+	gopre = convPreGo
+	shared := make(map[string]bool)
+	for _, tc := range used {
+		if shared[tc.gopre] {
+			continue
+		}
+		shared[tc.gopre] = true
+		cpre += tc.cpre
+		gopre += tc.gopre
+	}
+	return cpre, gopre
+}
@@ -0,0 +1,165 @@
+// Copyright 2024 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package bind
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"runtime"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// The bindings can be cross-built for another GOOS and GOARCH than those of
+// the host, e.g., for linux/arm64 or windows/amd64 wheels, with the C cross
+// compiler of the target, from the CC env var, or the usual one of the
+// target, and the python configuration of the target, from the JSON of its
+// sysconfig config vars, dumped on the target by:
+//
+//	python3 -c "import json, sysconfig; print(json.dumps(sysconfig.get_config_vars()))" > target.json
+//
+// The Makefile sets the GOOS, GOARCH and CGO_ENABLED env vars of the go
+// commands and uses the cross compiler, and the cgo preamble has the flags
+// of the target python.
+
+// crossCCs are the usual C cross compilers of the targets, by GOOS/GOARCH
+var crossCCs = map[string]string{
+	"linux/386":     "i686-linux-gnu-gcc",
+	"linux/amd64":   "x86_64-linux-gnu-gcc",
+	"linux/arm":     "arm-linux-gnueabihf-gcc",
+	"linux/arm64":   "aarch64-linux-gnu-gcc",
+	"linux/ppc64le": "powerpc64le-linux-gnu-gcc",
+	"linux/riscv64": "riscv64-linux-gnu-gcc",
+	"linux/s390x":   "s390x-linux-gnu-gcc",
+	"windows/386":   "i686-w64-mingw32-gcc",
+	"windows/amd64": "x86_64-w64-mingw32-gcc",
+	"windows/arm64": "aarch64-w64-mingw32-clang",
+}
+
+// goRuntimeLibs are the system libraries of the go runtime, linked with the
+// c-archive of the cgo wrappers to go functions, by GOOS, -lpthread for
+// the others
+var goRuntimeLibs = map[string][]string{
+	"darwin":  {"-framework CoreFoundation", "-framework Security"},
+	"windows": {"-lwinmm", "-lntdll", "-lws2_32"},
+}
+
+// archiveLibs returns the flags linking the system libraries of the go
+// runtime of the target GOOS, as required by the c-archive of the cgo
+// wrappers to go functions
+func (cfg *BindCfg) archiveLibs() []string {
+	if libs, ok := goRuntimeLibs[cfg.TargetOS()]; ok {
+		return libs
+	}
+	return []string{"-lpthread"}
+}
+
+// TargetOS returns the GOOS of the bindings: GOOS, or that of the host
+func (cfg *BindCfg) TargetOS() string {
+	if cfg.GOOS != "" {
+		return cfg.GOOS
+	}
+	return runtime.GOOS
+}
+
+// TargetArch returns the GOARCH of the bindings: GOARCH, or that of the host
+func (cfg *BindCfg) TargetArch() string {
+	if cfg.GOARCH != "" {
+		return cfg.GOARCH
+	}
+	return runtime.GOARCH
+}
+
+// CrossBuild returns whether the bindings are cross-built, for another
+// GOOS or GOARCH than those of the host
+func (cfg *BindCfg) CrossBuild() bool {
+	return cfg.TargetOS() != runtime.GOOS || cfg.TargetArch() != runtime.GOARCH
+}
+
+// CrossCC returns the usual C cross compiler of the target GOOS and GOARCH,
+// or "" if there is none
+func CrossCC(goos, goarch string) string {
+	return crossCCs[goos+"/"+goarch]
+}
+
+// PythonConfig returns the python configuration of the bindings: that of
+// the PyConfig file, if any, and otherwise that of VM
+func (cfg *BindCfg) PythonConfig() (PyConfig, error) {
+	if cfg.PyConfig != "" {
+		return PythonConfigFile(cfg.PyConfig)
+	}
+	return GetPythonConfig(cfg.VM)
+}
+
+// PythonConfigFile returns the python configuration of the JSON file of
+// the sysconfig config vars of a python, with the include and lib dirs and
+// python lib overridden by the GOPY_INCLUDE, GOPY_LIBDIR and GOPY_PYLIB env
+// vars, as by GetPythonConfig
+func PythonConfigFile(fname string) (PyConfig, error) {
+	b, err := os.ReadFile(fname)
+	if err != nil {
+		return PyConfig{}, errors.Wrap(err, "could not read python config file")
+	}
+	var vars map[string]any
+	if err := json.Unmarshal(b, &vars); err != nil {
+		return PyConfig{}, errors.Wrapf(err, "could not decode python config file %s", fname)
+	}
+	str := func(name string) string {
+		if v, ok := vars[name].(string); ok {
+			return v
+		}
+		return ""
+	}
+	var raw pyConfigRaw
+	major, minor, ok := strings.Cut(str("py_version_short"), ".")
+	if !ok {
+		return PyConfig{}, fmt.Errorf("gopy: no python version in python config file %s", fname)
+	}
+	raw.Version, _ = strconv.Atoi(major)
+	raw.Minor, _ = strconv.Atoi(minor)
+	raw.IncDir = str("INCLUDEPY")
+	raw.LibDir = str("LIBDIR")
+	raw.LibPy = str("LIBRARY")
+	raw.ShLibs = str("SHLIBS")
+	raw.SysLibs = str("SYSLIBS")
+	raw.ShFlags = clearLdFlags(str("LDSHARED"))
+	raw.ExtSuffix = str("EXT_SUFFIX")
+	inc, iok := os.LookupEnv("GOPY_INCLUDE")
+	lib, lok := os.LookupEnv("GOPY_LIBDIR")
+	pylib, pok := os.LookupEnv("GOPY_PYLIB")
+	if iok && lok && pok {
+		raw.IncDir, raw.LibDir, raw.LibPy = inc, lib, pylib
+	}
+	return raw.config(), nil
+}
+
+// clearLdFlags returns the flags of the LDSHARED command linking the python
+// extensions, without the compiler and -bundle, as cgo passes -dynamiclib
+func clearLdFlags(s string) string {
+	_, flags, _ := strings.Cut(s, " ")
+	return strings.ReplaceAll(flags, "-bundle", "")
+}
+
+// makefileCross returns the Makefile settings of the cross build, if any:
+// the env vars of the go commands and the C cross compiler
+func (g *pyGen) makefileCross() string {
+	if !g.cfg.CrossBuild() {
+		return ""
+	}
+	goos, goarch := g.cfg.TargetOS(), g.cfg.TargetArch()
+	var b strings.Builder
+	fmt.Fprintf(&b, "\n# cross-build for %s/%s, with the C cross compiler CC\n", goos, goarch)
+	fmt.Fprintf(&b, "export GOOS=%s\nexport GOARCH=%s\nexport CGO_ENABLED=1\n", goos, goarch)
+	if cc := CrossCC(goos, goarch); cc != "" {
+		fmt.Fprintf(&b, "ifeq ($(origin CC),default)\nCC = %s\nendif\n", cc)
+	} else {
+		fmt.Fprintf(&b, "# CC must be set to the C cross compiler of %s/%s\n", goos, goarch)
+	}
+	b.WriteString("export CC\nGCC = $(CC)\n")
+	return b.String()
+}
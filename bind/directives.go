@@ -0,0 +1,158 @@
+// Copyright 2024 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package bind
+
+import (
+	"fmt"
+	"go/ast"
+	"go/doc"
+	"strings"
+)
+
+// Directives are //gopy: lines in the doc comment of a symbol, setting how
+// it is bound, without changing the Go side:
+//
+//	//gopy:skip                   leaves the symbol out of the bindings
+//	//gopy:hide                   leaves the struct field out of the bindings, see filter.go
+//	//gopy:name pyname            binds the symbol under the python name pyname
+//	//gopy:instantiate Name[T]    binds an instantiation of a generic, see generics.go
+//	//gopy:operator op            binds a method to a python operator, see gen_operators.go
+//	//gopy:threadsafe             marks a struct safe for concurrent use, see gen_threads.go
+//	//gopy:frozen                 marks a struct immutable after construction, see gen_frozen.go
+//	//gopy:guarded                marks a struct requiring external locking, see gen_locks.go
+//	//gopy:pooled                 constructs a struct from a pool of its values, see gen_pool.go
+//	//gopy:raises Exception       raises an error as a python builtin exception too, see gen_errors.go
+//	//gopy:commaok none|raise     sets the policy of a func returning a value and a bool, see gen_commaok.go
+//	//gopy:columns struct|dict    returns the parallel slices of a func as numpy arrays, see gen_columns.go
+//	//gopy:borrow [param ...]     passes the string params of a func as views of the python data, see gen_borrow.go
+//	//gopy:check cond             checks the args of a func in python, before calling it, see gen_checks.go
+//
+// Like other Go directives, there is no space after the //, and they are
+// not part of the doc text.
+
+// directivePrefix starts the doc comment lines of gopy directives
+const directivePrefix = "//gopy:"
+
+// directives returns the gopy directives of the symbols of the package,
+// mapping their names, with the names of methods and fields qualified by
+// their type, to the args of each of their directives
+func (p *Package) directives() map[string]map[string]string {
+	if p.dirs != nil {
+		return p.dirs
+	}
+	p.dirs = make(map[string]map[string]string)
+	if p.doc == nil {
+		return p.dirs
+	}
+	add := func(name string, cgs ...*ast.CommentGroup) {
+		for _, cg := range cgs {
+			if cg == nil {
+				continue
+			}
+			for _, c := range cg.List {
+				txt := strings.TrimSpace(c.Text)
+				if !strings.HasPrefix(txt, directivePrefix) {
+					continue
+				}
+				dir, arg, _ := strings.Cut(txt[len(directivePrefix):], " ")
+				arg = strings.TrimSpace(arg)
+				if p.dirs[name] == nil {
+					p.dirs[name] = make(map[string]string)
+				}
+				if prev, ok := p.dirs[name][dir]; ok {
+					arg = prev + "\n" + arg
+				}
+				p.dirs[name][dir] = arg
+			}
+		}
+	}
+	values := func(vals []*doc.Value) {
+		for _, v := range vals {
+			for _, spec := range v.Decl.Specs {
+				vs := spec.(*ast.ValueSpec)
+				for _, n := range vs.Names {
+					add(n.Name, v.Decl.Doc, vs.Doc)
+				}
+			}
+		}
+	}
+	funcs := func(prefix string, fns []*doc.Func) {
+		for _, f := range fns {
+			add(prefix+f.Name, f.Decl.Doc)
+		}
+	}
+
+	values(p.doc.Consts)
+	values(p.doc.Vars)
+	funcs("", p.doc.Funcs)
+	for _, t := range p.doc.Types {
+		values(t.Consts)
+		values(t.Vars)
+		funcs("", t.Funcs)
+		funcs(t.Name+".", t.Methods)
+		for _, spec := range t.Decl.Specs {
+			ts, ok := spec.(*ast.TypeSpec)
+			if !ok || ts.Name.Name != t.Name {
+				continue
+			}
+			add(t.Name, t.Decl.Doc, ts.Doc)
+			var fields *ast.FieldList
+			switch typ := ts.Type.(type) {
+			case *ast.StructType:
+				fields = typ.Fields
+			case *ast.InterfaceType:
+				fields = typ.Methods
+			}
+			if fields == nil {
+				continue
+			}
+			for _, f := range fields.List {
+				for _, n := range f.Names {
+					add(t.Name+"."+n.Name, f.Doc, f.Comment)
+				}
+			}
+		}
+	}
+	return p.dirs
+}
+
+// directive returns the arg of the directive dir of the named symbol,
+// one line per repetition of the directive, and whether the symbol has it.
+// The members of instantiations have the directives of the generic type.
+func (p *Package) directive(name, dir string) (string, bool) {
+	if tn, mn, ok := strings.Cut(name, "."); ok {
+		name = p.originName(tn) + "." + mn
+	}
+	arg, ok := p.directives()[name][dir]
+	return arg, ok
+}
+
+// SymbolNames are the python names of the symbols, by qualified name, e.g.,
+// hi.Person.Greet, as set by the names table of the config file, for the
+// symbols without a name directive -- this must be a global as it is
+// relevant during initial package parsing, like NoWarn.
+var SymbolNames map[string]string
+
+// pyNameDirective returns the python name of the named symbol set by its
+// name directive, or by SymbolNames, or "" if none, warning about invalid
+// python names
+func (p *Package) pyNameDirective(name string) string {
+	nm, ok := p.directive(name, "name")
+	what := directivePrefix + "name directive"
+	if !ok {
+		nm, ok = SymbolNames[p.Name()+"."+name]
+		what = "config name"
+	}
+	if !ok {
+		return ""
+	}
+	if !isValidPythonName(nm) {
+		if !NoWarn {
+			fmt.Printf("gopy: warning: ignoring %s of %s.%s: invalid python name %q\n", what, p.Name(), name, nm)
+		}
+		return ""
+	}
+	return nm
+}
@@ -0,0 +1,77 @@
+// Copyright 2024 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package bind
+
+import (
+	"fmt"
+	"go/types"
+)
+
+// The python class of a struct embedding a struct of the bound packages
+// inherits from the class of the embedded struct, but the types of other
+// packages have no classes to inherit from: the exported methods promoted
+// from these embedded types are bound as methods of the struct itself,
+// calling them through the struct on the Go side.
+
+// isBoundPkg returns true if the package is bound, as the package being
+// processed or an already processed one
+func (p *Package) isBoundPkg(pkg *types.Package) bool {
+	if pkg == nil || pkg == p.pkg {
+		return true
+	}
+	for _, op := range Packages {
+		if op.pkg.Path() == pkg.Path() {
+			return true
+		}
+	}
+	return false
+}
+
+// extEmbedMethods returns the methods of the struct type named sname
+// promoted from its embedded fields of types of packages that are not bound
+func (p *Package) extEmbedMethods(sname string, styp types.Type) []*Func {
+	st, ok := styp.Underlying().(*types.Struct)
+	if !ok {
+		return nil
+	}
+	var meths []*Func
+	mset := types.NewMethodSet(types.NewPointer(styp))
+	for i := 0; i < mset.Len(); i++ {
+		sel := mset.At(i)
+		m := sel.Obj()
+		if len(sel.Index()) < 2 || !m.Exported() || isSkipped(m) {
+			continue
+		}
+		ftyp := st.Field(sel.Index()[0]).Type()
+		if ptr, isPtr := ftyp.(*types.Pointer); isPtr {
+			ftyp = ptr.Elem()
+		}
+		nt, ok := ftyp.(*types.Named)
+		if !ok || p.isBoundPkg(nt.Obj().Pkg()) {
+			continue
+		}
+		qn := p.pkg.Name() + "." + sname + "." + m.Name()
+		if ExcludeSyms != nil && ExcludeSyms.MatchString(qn) {
+			continue
+		}
+		msig := m.Type().(*types.Signature)
+		if _, _, _, err := isPyCompatFunc(msig); err != nil {
+			if !NoWarn {
+				fmt.Printf("ignoring python incompatible promoted method: %s: %v\n", qn, err)
+			}
+			continue
+		}
+		p.syms.processTuple(msig.Results())
+		p.syms.processTuple(msig.Params())
+		// no receiver: the method is called through the struct
+		sig := types.NewSignatureType(nil, nil, nil, msig.Params(), msig.Results(), msig.Variadic())
+		fn, err := newFuncFrom(p, sname, m, sig)
+		if err != nil {
+			continue
+		}
+		meths = append(meths, fn)
+	}
+	return meths
+}
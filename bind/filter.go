@@ -0,0 +1,149 @@
+// Copyright 2024 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package bind
+
+import (
+	"fmt"
+	"go/types"
+	"reflect"
+	"regexp"
+)
+
+// Symbols are left out of the bindings by the IncludeSyms and ExcludeSyms
+// regexps, matched against their qualified names, e.g., "hi.Person" for
+// package-level symbols and "hi.Person.Greet" for methods and fields, and
+// by a //gopy:skip line in their doc comment.  Functions, methods, fields
+// and variables using a skipped type are skipped as well.  The struct
+// fields with a json:"-" tag, or a //gopy:hide line, are hidden: skipped,
+// along with the types they use, for the internal fields of structs, e.g.,
+// mutexes, which python should not see.
+
+var (
+	// IncludeSyms, if set, only binds the package-level symbols matching it,
+	// along with their methods and fields -- this must be a global as it is
+	// relevant during initial package parsing, like NoWarn.
+	IncludeSyms *regexp.Regexp
+
+	// ExcludeSyms, if set, skips the symbols, methods and fields matching it.
+	ExcludeSyms *regexp.Regexp
+
+	// skippedObjs are the objects skipped by the filters or directives
+	skippedObjs = map[types.Object]bool{}
+)
+
+// SetSymbolFilter sets IncludeSyms and ExcludeSyms from the include and
+// exclude regexps, with empty ones not filtering anything.
+func SetSymbolFilter(include, exclude string) error {
+	IncludeSyms, ExcludeSyms = nil, nil
+	var err error
+	if include != "" {
+		IncludeSyms, err = regexp.Compile(include)
+		if err != nil {
+			return fmt.Errorf("gopy: invalid include regexp: %v", err)
+		}
+	}
+	if exclude != "" {
+		ExcludeSyms, err = regexp.Compile(exclude)
+		if err != nil {
+			return fmt.Errorf("gopy: invalid exclude regexp: %v", err)
+		}
+	}
+	return nil
+}
+
+// isSkipped returns whether the object is skipped, or the method or field
+// of a generic type it is instantiated from
+func isSkipped(obj types.Object) bool {
+	switch o := obj.(type) {
+	case *types.Func:
+		obj = o.Origin()
+	case *types.Var:
+		obj = o.Origin()
+	}
+	return skippedObjs[obj]
+}
+
+// skippedType returns the name of the skipped named type used by the type,
+// if any, looking through pointers and containers
+func skippedType(typ types.Type) string {
+	switch t := typ.(type) {
+	case *types.Named:
+		if isSkipped(t.Obj()) {
+			return t.Obj().Name()
+		}
+	case *types.Pointer:
+		return skippedType(t.Elem())
+	case *types.Slice:
+		return skippedType(t.Elem())
+	case *types.Array:
+		return skippedType(t.Elem())
+	case *types.Map:
+		if nm := skippedType(t.Key()); nm != "" {
+			return nm
+		}
+		return skippedType(t.Elem())
+	}
+	return ""
+}
+
+// markSkipped marks the objects of the package skipped by the filters
+// or by the skip directive
+func (p *Package) markSkipped() {
+	pn := p.pkg.Name()
+	skip := func(obj types.Object, name string, member bool) {
+		qn := pn + "." + name
+		_, skipDir := p.directive(name, "skip")
+		switch {
+		case skipDir:
+		case ExcludeSyms != nil && ExcludeSyms.MatchString(qn):
+		case !member && IncludeSyms != nil && !IncludeSyms.MatchString(qn):
+		default:
+			return
+		}
+		skippedObjs[obj] = true
+	}
+
+	scope := p.pkg.Scope()
+	for _, name := range scope.Names() {
+		obj := scope.Lookup(name)
+		if !obj.Exported() {
+			continue
+		}
+		skip(obj, name, false)
+		named, ok := obj.Type().(*types.Named)
+		if _, isType := obj.(*types.TypeName); !isType || !ok {
+			continue
+		}
+		for i := 0; i < named.NumMethods(); i++ {
+			m := named.Method(i)
+			skip(m, name+"."+m.Name(), true)
+		}
+		switch typ := named.Underlying().(type) {
+		case *types.Struct:
+			for i := 0; i < typ.NumFields(); i++ {
+				f := typ.Field(i)
+				if p.isHiddenField(name, f.Name(), typ.Tag(i)) {
+					skippedObjs[f] = true
+					continue
+				}
+				skip(f, name+"."+f.Name(), true)
+			}
+		case *types.Interface:
+			for i := 0; i < typ.NumExplicitMethods(); i++ {
+				m := typ.ExplicitMethod(i)
+				skip(m, name+"."+m.Name(), true)
+			}
+		}
+	}
+}
+
+// isHiddenField returns whether the field of the struct type is hidden, by a
+// json:"-" tag, as encoding/json hides it, or by the hide directive
+func (p *Package) isHiddenField(typName, field, tag string) bool {
+	if _, ok := p.directive(typName+"."+field, "hide"); ok {
+		return true
+	}
+	return reflect.StructTag(tag).Get("json") == "-"
+}
@@ -7,10 +7,15 @@ package bind
 import (
 	"bytes"
 	"fmt"
+	"go/format"
+	"go/types"
 	"io"
 	"os"
 	"path/filepath"
+	"runtime"
+	"sort"
 	"strings"
+	"sync"
 )
 
 // this version uses pybindgen and a generated .go file to do the binding
@@ -18,6 +23,10 @@ import (
 const (
 	// GoHandle is the type to use for the Handle map key, go-side
 	GoHandle = "int64"
+)
+
+// the types of the handles, set by SetHandleType, see handletype.go
+var (
 	// CGoHandle is Handle for cgo files
 	CGoHandle = "C.longlong"
 	// PyHandle is within python
@@ -38,7 +47,8 @@ var WindowsOS = false
 
 // for all preambles: 1 = name of package (outname), 2 = cmdstr
 
-// 3 = libcfg, 4 = GoHandle, 5 = CGoHandle, 6 = all imports, 7 = mainstr, 8 = exe pre C, 9 = exe pre go
+// 3 = libcfg, 4 = GoHandle, 5 = CGoHandle, 6 = all imports, 7 = mainstr, 8 = converters + exe pre C, 9 = converters + exe pre go,
+// 10 = handle conversions
 const (
 	goPreamble = `/*
 cgo stubs for package %[1]s.
@@ -80,6 +90,34 @@ static inline void gopy_incref(PyObject* obj) { // macro
 static inline int gopy_method_check(PyObject* obj) { // macro
 	return PyMethod_Check(obj);
 }
+// gopy_rune_from_py returns the code point of the single character str,
+// or int, o, or -1 with a python exception set if it is not a valid Go rune
+static inline long gopy_rune_from_py(PyObject* o) {
+	long r;
+	if (o == NULL) { // e.g., the result of a failed callback
+		return 0;
+	}
+	if (PyUnicode_Check(o)) {
+		if (PyUnicode_GetLength(o) != 1) {
+			PyErr_Format(PyExc_ValueError, "expected a single character str for Go rune, got %%R", o);
+			return -1;
+		}
+		r = (long)PyUnicode_ReadChar(o, 0);
+	} else if (PyLong_Check(o)) {
+		r = PyLong_AsLong(o);
+		if (r == -1 && PyErr_Occurred() != NULL) {
+			return -1;
+		}
+	} else {
+		PyErr_Format(PyExc_TypeError, "expected a single character str for Go rune, got %%.200s", Py_TYPE(o)->tp_name);
+		return -1;
+	}
+	if (r < 0 || r > 0x10FFFF || (r >= 0xD800 && r < 0xE000)) {
+		PyErr_Format(PyExc_ValueError, "invalid code point %%ld for Go rune", r);
+		return -1;
+	}
+	return r;
+}
 static inline void gopy_err_handle() {
 	if(PyErr_Occurred() != NULL) {
 		PyErr_Print();
@@ -107,21 +145,23 @@ func GoPyInit() {
 	%[7]s
 }
 
-// type for the handle -- int64 for speed (can switch to string)
+// type for the handle -- int64 for speed, or string, with the Handle option
 type GoHandle %[4]s
 type CGoHandle %[5]s
-
+%[10]s
 // DecRef decrements the reference count for the specified handle
 // and deletes it it goes to zero.
 //export DecRef
 func DecRef(handle CGoHandle) {
-	gopyh.DecRef(gopyh.CGoHandle(handle))
+	h := handleGo(handle)
+	gopyh.DecRef(h)
+	handleFree(h)
 }
 
 // IncRef increments the reference count for the specified handle.
 //export IncRef
 func IncRef(handle CGoHandle) {
-	gopyh.IncRef(gopyh.CGoHandle(handle))
+	gopyh.IncRef(handleGo(handle))
 }
 
 // NumHandles returns the number of handles currently in use.
@@ -130,6 +170,13 @@ func NumHandles() int {
 	return gopyh.NumHandles()
 }
 
+// GoPyAddr returns the address of the variable of the handle, identifying
+// the same variable through different handles, or 0 if it has none.
+//export GoPyAddr
+func GoPyAddr(handle CGoHandle) C.longlong {
+	return C.longlong(gopyh.Addr(handleGo(handle)))
+}
+
 // boolGoToPy converts a Go bool to python-compatible C.char
 func boolGoToPy(b bool) C.char {
 	if b {
@@ -146,24 +193,51 @@ func boolPyToGo(b C.char) bool {
 	return false
 }
 
+// complex64GoToPy converts a Go complex64 to a python complex
 func complex64GoToPy(c complex64) *C.PyObject {
-	return C.PyComplex_FromDoubles(C.double(real(c)), C.double(imag(c)))
+	return complex128GoToPy(complex128(c))
 }
 
+// complex64PyToGo converts a python complex, or number, to a Go complex64
 func complex64PyToGo(o *C.PyObject) complex64 {
-	v := C.PyComplex_AsCComplex(o)
-	return complex(float32(v.real), float32(v.imag))
+	return complex64(complex128PyToGo(o))
 }
 
+// complex128GoToPy converts a Go complex128 to a python complex
 func complex128GoToPy(c complex128) *C.PyObject {
+	_gstate := C.PyGILState_Ensure()
+	defer C.PyGILState_Release(_gstate)
 	return C.PyComplex_FromDoubles(C.double(real(c)), C.double(imag(c)))
 }
 
+// complex128PyToGo converts a python complex, or number, to a Go complex128,
+// leaving the TypeError of other values set
 func complex128PyToGo(o *C.PyObject) complex128 {
+	_gstate := C.PyGILState_Ensure()
+	defer C.PyGILState_Release(_gstate)
 	v := C.PyComplex_AsCComplex(o)
 	return complex(float64(v.real), float64(v.imag))
 }
 
+// runeGoToPy converts a Go rune to a single character python str, which is
+// U+FFFD for invalid code points, as for string(r)
+func runeGoToPy(r rune) *C.PyObject {
+	_gstate := C.PyGILState_Ensure()
+	defer C.PyGILState_Release(_gstate)
+	if !utf8.ValidRune(r) {
+		r = utf8.RuneError
+	}
+	return C.PyUnicode_FromOrdinal(C.int(r))
+}
+
+// runePyToGo converts a single character python str, or an int code point,
+// to a Go rune, leaving the TypeError or ValueError of other values set
+func runePyToGo(o *C.PyObject) rune {
+	_gstate := C.PyGILState_Ensure()
+	defer C.PyGILState_Release(_gstate)
+	return rune(C.gopy_rune_from_py(o))
+}
+
 // errorGoToPy converts a Go error to python-compatible C.CString
 func errorGoToPy(e error) *C.char {
 	if e != nil {
@@ -172,6 +246,137 @@ func errorGoToPy(e error) *C.char {
 	return C.CString("")
 }
 
+// gopyError is a go error of a bound package, raised as the python
+// exception class exc for all errors matching is
+type gopyError struct {
+	name string
+	is   func(err error) bool
+	exc  *C.PyObject
+}
+
+// gopyErrors are the go errors with their own python exception classes,
+// matched in order, and gopyErrorBase is the go.GoError base class
+var (
+	gopyErrors    []*gopyError
+	gopyErrorBase *C.PyObject
+)
+
+// gopyAddError adds a go error, whose python exception class is
+// registered by name from python
+func gopyAddError(name string, is func(err error) bool) {
+	gopyErrors = append(gopyErrors, &gopyError{name: name, is: is})
+}
+
+// GoPyRegisterError registers the python exception class for the named
+// go error, or the go.GoError base class for the name "error".
+//export GoPyRegisterError
+func GoPyRegisterError(name *C.char, exc *C.PyObject) {
+	nm := C.GoString(name)
+	C.gopy_incref(exc)
+	if nm == "error" {
+		C.gopy_decref(gopyErrorBase)
+		gopyErrorBase = exc
+		return
+	}
+	for _, e := range gopyErrors {
+		if e.name == nm {
+			C.gopy_decref(e.exc)
+			e.exc = exc
+			return
+		}
+	}
+	C.gopy_decref(exc)
+}
+
+// gopyErrorClass returns the python exception class to raise for err
+func gopyErrorClass(err error) *C.PyObject {
+	for _, e := range gopyErrors {
+		if e.exc != nil && e.is(err) {
+			return e.exc
+		}
+	}
+	if gopyErrorBase != nil {
+		return gopyErrorBase
+	}
+	return C.PyExc_RuntimeError
+}
+
+// gopyMaxCauses bounds the chain of causes of the exceptions of Go errors
+const gopyMaxCauses = 100
+
+// gopyRaise raises err as the python exception of its class, with the
+// message msg, and, when err wraps other errors, with the chain of their
+// exceptions as its __cause__, one per level of unwrapping, as by %%+v in
+// Go.  The exceptions have the Go type of their error in their go_type
+// attribute, and the causes in their message too.
+func gopyRaise(err error, msg *C.char) {
+	cls := gopyErrorClass(err)
+	if gopyUnwrap(err) == nil {
+		C.PyErr_SetString(cls, msg)
+		return
+	}
+	exc := gopyErrorValue(cls, C.GoString(msg), err)
+	if exc == nil {
+		C.PyErr_SetString(cls, msg)
+		return
+	}
+	last := exc
+	e := gopyUnwrap(err)
+	for i := 0; e != nil && i < gopyMaxCauses; i++ {
+		cause := gopyErrorValue(gopyErrorClass(e), fmt.Sprintf("%%T: %%s", e, e.Error()), e)
+		if cause == nil {
+			break
+		}
+		C.PyException_SetCause(last, cause) // steals cause
+		last = cause
+		e = gopyUnwrap(e)
+	}
+	C.PyErr_SetObject(cls, exc)
+	C.gopy_decref(exc)
+}
+
+// gopyUnwrap returns the error wrapped by err, or the first one of those
+// of errors.Join and multiple %%w, or nil if none
+func gopyUnwrap(err error) error {
+	if e := errors.Unwrap(err); e != nil {
+		return e
+	}
+	if j, ok := err.(interface{ Unwrap() []error }); ok {
+		for _, e := range j.Unwrap() {
+			if e != nil {
+				return e
+			}
+		}
+	}
+	return nil
+}
+
+// gopyErrorValue returns a new exception of the class cls with the message
+// msg, and the Go type of err as its go_type attribute, or nil if it cannot
+// be created
+func gopyErrorValue(cls *C.PyObject, msg string, err error) *C.PyObject {
+	cmsg := C.CString(msg)
+	args := C.PyTuple_New(1)
+	C.PyTuple_SetItem(args, 0, C.PyUnicode_FromString(cmsg)) // steals the str
+	C.free(unsafe.Pointer(cmsg))
+	exc := C.PyObject_Call(cls, args, nil)
+	C.gopy_decref(args)
+	if exc == nil {
+		C.PyErr_Clear()
+		return nil
+	}
+	ctyp := C.CString(fmt.Sprintf("%%T", err))
+	typ := C.PyUnicode_FromString(ctyp)
+	C.free(unsafe.Pointer(ctyp))
+	cattr := C.CString("go_type")
+	if C.PyObject_SetAttrString(exc, cattr, typ) < 0 {
+		C.PyErr_Clear() // builtin exceptions have no attributes
+	}
+	C.free(unsafe.Pointer(cattr))
+	C.gopy_decref(typ)
+	return exc
+}
+
 %[9]s
 `
 
@@ -190,15 +395,40 @@ static inline void gopy_load_mod() {
 
 `
 
+	// 1 = name of package (outname)
 	goExePreambleGo = `
 // wchar version of startup args
 var wargs []*C.wchar_t
 
+// gopyBundleArgs returns the startup args of the interpreter, making the
+// python wrappers next to the executable importable as package %[1]s,
+// and running its __main__.py, if any, when no args are given.
+func gopyBundleArgs() []string {
+	args := os.Args
+	exe, err := os.Executable()
+	if err != nil {
+		return args
+	}
+	if ex, err := filepath.EvalSymlinks(exe); err == nil {
+		exe = ex
+	}
+	dir := filepath.Dir(exe)
+	path := filepath.Dir(dir)
+	if pp := os.Getenv("PYTHONPATH"); pp != "" {
+		path += string(os.PathListSeparator) + pp
+	}
+	os.Setenv("PYTHONPATH", path)
+	if _, err := os.Stat(filepath.Join(dir, "__main__.py")); err == nil && len(args) == 1 {
+		args = append(args, "-m", "%[1]s")
+	}
+	return args
+}
+
 //export GoPyMainRun
 func GoPyMainRun() {
 	// need to encode char* into wchar_t*
-	for i := range os.Args {
-		cstr := C.CString(os.Args[i])
+	for _, arg := range gopyBundleArgs() {
+		cstr := C.CString(arg)
 		wargs = append(wargs, C.Py_DecodeLocale(cstr, nil))
 		C.free(unsafe.Pointer(cstr))
 	}
@@ -254,9 +484,11 @@ def add_checked_string_function(mod, name, retval, params, failure_expression=''
 mod = Module('_%[1]s')
 mod.add_include('"%[1]s_go.h"')
 mod.add_function('GoPyInit', None, [])
-mod.add_function('DecRef', None, [param('int64_t', 'handle')])
-mod.add_function('IncRef', None, [param('int64_t', 'handle')])
+mod.add_function('DecRef', None, [param('%[3]s', 'handle')])
+mod.add_function('IncRef', None, [param('%[3]s', 'handle')])
 mod.add_function('NumHandles', retval('int'), [])
+mod.add_function('GoPyAddr', retval('int64_t'), [param('%[3]s', 'handle')])
+mod.add_function('GoPyRegisterError', None, [param('char*', 'name'), param('PyObject*', 'exc', transfer_ownership=False)])
 `
 
 	// appended to imports in py wrap preamble as key for adding at end
@@ -269,18 +501,12 @@ mod.add_function('NumHandles', retval('int'), [])
 # File is generated by gopy. Do not edit.
 # %[2]s
 
-# the following is required to enable dlopen to open the _go.so file
-import os,sys,inspect,collections
+import os,sys,collections
 try:
 	import collections.abc as _collections_abc
 except ImportError:
 	_collections_abc = collections
-
-cwd = os.getcwd()
-currentdir = os.path.dirname(os.path.abspath(inspect.getfile(inspect.currentframe())))
-os.chdir(currentdir)
 %[6]s
-os.chdir(cwd)
 
 # to use this code in your end-user python file, import it as follows:
 # from %[1]s import %[3]s
@@ -289,6 +515,24 @@ os.chdir(cwd)
 
 %[7]s
 
+`
+
+	// importer of the modules of the bindings next to the wrapper, not in a
+	// python package, by explicit path, without changing the working dir
+	pyImportLocal = `import importlib.machinery, importlib.util
+
+def _gopy_import(name):
+	"""imports the module name of the bindings from the dir of this file, whatever the sys.path and working dir"""
+	if name in sys.modules:
+		return sys.modules[name]
+	spec = importlib.machinery.PathFinder.find_spec(name, [os.path.dirname(os.path.abspath(__file__))])
+	if spec is None:
+		return importlib.import_module(name)
+	mod = importlib.util.module_from_spec(spec)
+	sys.modules[name] = mod
+	spec.loader.exec_module(mod)
+	return mod
+
 `
 
 	// exe version of preamble -- doesn't need complex code to load _ module
@@ -315,8 +559,10 @@ except ImportError:
 
 `
 
+	// 1 = name of package (outname), 2 = extra GoClass methods, 3 = no handle
 	GoPkgDefs = `
 import collections
+import threading
 try:
 	import collections.abc as _collections_abc
 except ImportError:
@@ -325,7 +571,58 @@ except ImportError:
 class GoClass(object):
 	"""GoClass is the base class for all GoPy wrapper classes"""
 	def __init__(self):
-		self.handle = 0
+		self.handle = %[3]s
+	@classmethod
+	def zero(cls):
+		"""zero returns the Go zero value of the type, e.g., a struct with zero fields, or an empty slice or map"""
+		return cls()
+%[2]s
+_cycles = threading.local()
+
+def cycle_guard(fn):
+	"""cycle_guard guards the rendering of Go values by fn against the cycles of self-referential values, rendering the values already being rendered as ..."""
+	def guard(self):
+		addr = _%[1]s.GoPyAddr(self.handle)
+		if addr == 0:
+			return fn(self)
+		seen = getattr(_cycles, 'seen', None)
+		if seen is None:
+			seen = _cycles.seen = set()
+		key = (type(self), addr)
+		if key in seen:
+			return '...'
+		seen.add(key)
+		try:
+			return fn(self)
+		finally:
+			seen.discard(key)
+	return guard
+
+def to_py(v):
+	"""to_py returns the value with the Go slices and maps converted to python lists and dicts, recursively"""
+	if hasattr(v, 'to_dict'):
+		return v.to_dict()
+	if hasattr(v, 'to_list'):
+		return v.to_list()
+	return v
+
+def zero(cls):
+	"""zero returns the Go zero value of the bound type cls, as cls.zero() for the wrapper classes,
+	or of an enum, or of the python type of a basic Go type, e.g., zero(int) is 0"""
+	if hasattr(cls, 'zero'):
+		return cls.zero()
+	if hasattr(cls, '__members__'):
+		try:
+			return cls(0)
+		except ValueError:
+			return 0
+	return cls()
+
+class GoError(RuntimeError):
+	"""GoError is the base class of the exceptions raised for Go errors"""
+	pass
+
+_%[1]s.GoPyRegisterError("error", GoError)
 
 # use go.nil for nil pointers 
 nil = GoClass()
@@ -344,7 +641,8 @@ def Init():
 	`
 
 	// 3 = gencmd, 4 = vm, 5 = libext 6 = extraGccArgs, 7 = CFLAGS, 8 = LDLFAGS,
-	// 9 = windows special declspec hack
+	// 9 = windows special declspec hack, 10 = extra Go files, 11 = cross build,
+	// 12 = go runtime libs
 	MakefileTemplate = `# Makefile for python interface for package %[1]s.
 # File is generated by gopy. Do not edit.
 # %[2]s
@@ -359,7 +657,9 @@ LIBEXT=%[5]s
 GCC = $(shell $(GOCMD) env CC)
 CFLAGS = %[7]s
 LDFLAGS = %[8]s
-
+# the system libraries of the go runtime, linked with the go archive
+GOLIBS = %[12]s
+%[11]s
 all: gen build
 
 gen:
@@ -371,19 +671,22 @@ build:
 	- rm %[1]s.c
 	# goimports is needed to ensure that the imports list is valid
 	$(GOIMPORTS) -w %[1]s.go
-	# generate %[1]s_go$(LIBEXT) from %[1]s.go -- the cgo wrappers to go functions
-	$(GOBUILD) -buildmode=c-shared -o %[1]s_go$(LIBEXT) %[1]s.go
+	# generate the %[1]s_go.a archive and %[1]s_go.h from %[1]s.go -- the cgo wrappers to go functions
+	$(GOBUILD) -buildmode=c-archive -o %[1]s_go.a %[1]s.go%[10]s
 	# use pybindgen to build the %[1]s.c file which are the CPython wrappers to cgo wrappers..
 	# note: pip install pybindgen to get pybindgen if this fails
 	$(PYTHON) build.py
-	# build the _%[1]s$(LIBEXT) library that contains the cgo and CPython wrappers
+	# build the _%[1]s$(LIBEXT) library that contains the cgo and CPython wrappers,
+	# statically linking the go archive, so that it is the only library to load
 	# generated %[1]s.py python wrapper imports this c-code package
 	%[9]s
-	$(GCC) %[1]s.c %[6]s %[1]s_go$(LIBEXT) -o _%[1]s$(LIBEXT) $(CFLAGS) $(LDFLAGS) -fPIC --shared -w
+	$(GCC) %[1]s.c %[6]s %[1]s_go.a -o _%[1]s$(LIBEXT) $(CFLAGS) $(LDFLAGS) $(GOLIBS) -fPIC --shared -w
+	- rm %[1]s_go.a
 	
 `
 
-	// exe version of template: 3 = gencmd, 4 = vm, 5 = libext
+	// exe version of template: 3 = gencmd, 4 = vm, 5 = libext, 6 = CFLAGS,
+	// 7 = LDFLAGS, 8 = cross build
 	MakefileExeTemplate = `# Makefile for python interface for standalone executable package %[1]s.
 # File is generated by gopy. Do not edit.
 # %[2]s
@@ -398,7 +701,7 @@ LDFLAGS = %[7]s
 
 # get the flags used to build python:
 GCC = $(shell $(GOCMD) env CC)
-
+%[8]s
 all: gen build
 
 gen:
@@ -445,12 +748,12 @@ var NoMake = false
 func GenPyBind(mode BuildMode, libext, extragccargs string, lang int, dynamicLink bool, cfg *BindCfg) error {
 	gen := &pyGen{
 		mode:         mode,
-		pypkgname:    cfg.Name,
 		cfg:          cfg,
 		libext:       libext,
 		extraGccArgs: extragccargs,
 		lang:         lang,
 		dynamicLink:  dynamicLink,
+		exports:      make(map[*Package][]string),
 	}
 	gen.genPackageMap()
 	thePyGen = gen
@@ -468,13 +771,30 @@ type pyGen struct {
 	pybuild  *printer
 	pywrap   *printer
 	makefile *printer
+	gowrap   *printer // the go module, written once the build id is set
 
 	pkg    *Package // current package (only set when doing package-specific processing)
 	err    ErrorList
 	pkgmap map[string]struct{} // map of package paths
+	// python names of the symbols of the packages, re-exported by __init__.py
+	exports map[*Package][]string
+	// ids of the structs and slices of numbers benchmarked by genPerf
+	perfStructs []string
+	perfSlices  []string
+	// bound functions of the extra Go files
+	extraFuncs []extraFunc
+	// python names of the classes with mixins, and whether they were generated
+	mixins map[string]bool
+	// mappings of go errors to python builtin exceptions of the ErrorMap option
+	errMap []*errorMapping
+	// fragments of code injected into the functions of the Hooks option
+	hooks []*hook
+	// templates of the Templates option overriding the builtin ones, by file
+	templates map[string]string
+	// python params of the function of the last genFuncSig, see genAsyncTwin
+	pyParams []string
 
 	mode         BuildMode // mode: gen, build, pkg, exe
-	pypkgname    string
 	cfg          *BindCfg
 	libext       string
 	extraGccArgs string
@@ -488,13 +808,65 @@ func (g *pyGen) gen() error {
 	if err != nil {
 		return fmt.Errorf("gopy: could not create output directory: %v", err)
 	}
+	g.mixins, err = readMixins(g.cfg.OutputDir)
+	if err != nil {
+		return fmt.Errorf("gopy: could not read the python mixins: %v", err)
+	}
+	if g.mixins != nil && g.cfg.ExtName() == "_mixins" {
+		return fmt.Errorf("gopy: the python mixins of %s conflict with the _mixins extension module of package mixins", mixinsFile)
+	}
+	g.errMap, err = parseErrorMap(g.cfg.ErrorMap)
+	if err != nil {
+		return err
+	}
+	g.errMap = append(g.errMap, contextErrorMaps(g.errMap)...)
+	importErrorMap(g.errMap)
+	var hookImps []*types.Package
+	g.hooks, hookImps, err = readHooks(g.cfg.Hooks)
+	if err != nil {
+		return err
+	}
+	importHooks(hookImps)
+	g.templates, err = readTemplates(g.cfg.Templates)
+	if err != nil {
+		return err
+	}
 
 	g.genPre()
 	g.genExtTypesGo()
-	for _, p := range Packages {
-		g.genPkg(p)
-	}
+	g.genContextGo()
+	g.genDurationGo()
+	g.genDataclassGo()
+	g.genBulkGo()
+	g.genIOGo()
+	g.genBorrowGo()
+	g.genParallelGo()
+	g.genRunGo()
+	g.genHandlesGo()
+	g.genAsGo()
+	g.genLocksGo()
+	g.genColumnsGo()
+	g.genPrettyGo()
+	g.genExtraGo()
+	g.genBuildIDGo()
+	g.genPkgs()
+	g.genErrorMapGo()
+	g.warnMixins()
 	g.genOut()
+	g.genInit()
+	g.genPerf()
+	if g.cfg.GenTests && g.mode != ModeExe {
+		g.genTests()
+	}
+	if g.cfg.GenApp && g.mode != ModeExe {
+		g.genApp()
+	}
+	if g.cfg.DocFormat != "" {
+		g.genDocs()
+	}
+	if g.cfg.GenIDE {
+		g.genIDE()
+	}
 	if len(g.err) == 0 {
 		return nil
 	}
@@ -520,14 +892,27 @@ func (g *pyGen) genPre() {
 	if !NoMake {
 		g.genMakefile()
 	}
-	oinit, err := os.Create(filepath.Join(g.cfg.OutputDir, "__init__.py"))
-	g.err.Add(err)
-	err = oinit.Close()
-	g.err.Add(err)
 }
 
+// genPrintOut writes the output file of the printer, gofmt-ing the go files,
+// unless it has the same contents, keeping its modification time for the
+// build tools when regenerating the bindings
 func (g *pyGen) genPrintOut(outfn string, pr *printer) {
-	of, err := os.Create(filepath.Join(g.cfg.OutputDir, outfn))
+	if strings.HasSuffix(outfn, ".go") {
+		src, err := format.Source(pr.buf.Bytes())
+		if err != nil {
+			g.err.Add(fmt.Errorf("gopy: could not gofmt the generated %s: %v", outfn, err))
+		} else {
+			pr.buf.Reset()
+			pr.buf.Write(src)
+		}
+	}
+	fn := filepath.Join(g.cfg.OutputDir, outfn)
+	StatOutput(filepath.ToSlash(outfn), int64(pr.buf.Len()))
+	if old, err := os.ReadFile(fn); err == nil && bytes.Equal(old, pr.buf.Bytes()) {
+		return
+	}
+	of, err := os.Create(fn)
 	g.err.Add(err)
 	_, err = io.Copy(of, pr)
 	g.err.Add(err)
@@ -538,11 +923,13 @@ func (g *pyGen) genPrintOut(outfn string, pr *printer) {
 func (g *pyGen) genOut() {
 	g.pybuild.Printf("\nmod.generate(open('%v.c', 'w'))\n\n", g.cfg.Name)
 	g.gofile.Printf("\n\n")
+	setBuildID(g.buildID(), g.gofile, g.gowrap)
+	g.genPrintOut("go.py", g.gowrap)
 	g.genPrintOut(g.cfg.Name+".go", g.gofile)
 	g.genPrintOut("build.py", g.pybuild)
 	if !NoMake {
 		g.makefile.Printf("\n\n")
-		g.genPrintOut("Makefile", g.makefile)
+		g.genPrintOut(g.buildFile(), g.makefile)
 	}
 }
 
@@ -550,30 +937,114 @@ func (g *pyGen) genPkgWrapOut() {
 	g.pywrap.Printf("\n\n")
 	// note: must generate import string at end as imports can be added during processing
 	impstr := ""
+	ims := make([]string, 0, len(g.pkg.pyimports))
 	for _, im := range g.pkg.pyimports {
+		ims = append(ims, im)
+	}
+	sort.Strings(ims)
+	for _, im := range ims {
 		if g.mode == ModeGen || g.mode == ModeBuild || g.mode == ModePkg {
 			if g.cfg.PkgPrefix != "" {
 				impstr += fmt.Sprintf("from %s import %s\n", g.cfg.PkgPrefix, im)
 			} else {
-				impstr += fmt.Sprintf("import %s\n", im)
+				impstr += fmt.Sprintf("%[1]s = _gopy_import(%[1]q)\n", im)
 			}
 		} else {
-			impstr += fmt.Sprintf("from %s import %s\n", g.cfg.Name, im)
+			impstr += fmt.Sprintf("from %s import %s\n", g.cfg.FullName(), im)
 		}
 	}
 	b := g.pywrap.buf.Bytes()
 	nb := bytes.Replace(b, []byte(importHereKeyString), []byte(impstr), 1)
 	g.pywrap.buf = bytes.NewBuffer(nb)
+	if g.pkg == goPackage {
+		g.gowrap = g.pywrap // written by genOut, once the build id is set
+		return
+	}
 	g.genPrintOut(g.pkg.pkg.Name()+".py", g.pywrap)
 }
 
+// genPkgs generates the code of the packages in parallel, each by a copy of
+// the generator with its own printers, appending their Go code, pybindgen
+// stubs and errors in the order of the packages, so that the outputs do not
+// depend on the scheduling of the goroutines
+func (g *pyGen) genPkgs() {
+	pgs := make([]*pyGen, len(Packages))
+	sem := make(chan struct{}, runtime.GOMAXPROCS(0))
+	var wg sync.WaitGroup
+	for i, p := range Packages {
+		pg := *g
+		pg.gofile = &printer{buf: new(bytes.Buffer), indentEach: []byte("\t")}
+		pg.pybuild = &printer{buf: new(bytes.Buffer), indentEach: []byte("\t")}
+		pg.err = nil
+		pg.exports = make(map[*Package][]string)
+		pg.perfStructs, pg.perfSlices = nil, nil
+		if g.mixins != nil {
+			pg.mixins = make(map[string]bool, len(g.mixins))
+			for nm := range g.mixins {
+				pg.mixins[nm] = false
+			}
+		}
+		pgs[i] = &pg
+		wg.Add(1)
+		go func(pg *pyGen, p *Package) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+			pg.genPkg(p)
+		}(&pg, p)
+	}
+	wg.Wait()
+	for _, pg := range pgs {
+		g.gofile.buf.Write(pg.gofile.buf.Bytes())
+		g.pybuild.buf.Write(pg.pybuild.buf.Bytes())
+		for _, err := range pg.err {
+			g.err.Add(err)
+		}
+		for p, nms := range pg.exports {
+			g.exports[p] = nms
+		}
+		g.perfStructs = append(g.perfStructs, pg.perfStructs...)
+		g.perfSlices = append(g.perfSlices, pg.perfSlices...)
+		for nm, done := range pg.mixins {
+			if done {
+				g.mixins[nm] = true
+			}
+		}
+		if pg.gowrap != nil {
+			g.gowrap = pg.gowrap
+		}
+	}
+}
+
 func (g *pyGen) genPkg(p *Package) {
 	g.pkg = p
 	g.pywrap = &printer{buf: new(bytes.Buffer), indentEach: []byte("\t")}
 	g.genPyWrapPreamble()
 	if p == goPackage {
+		g.genBuildIDPyWrap()
 		g.genGoPkg()
 		g.genExtTypesPyWrap()
+		g.genContextPyWrap()
+		g.genDurationPyWrap()
+		g.genDataclassPyWrap()
+		g.genIOPyWrap()
+		g.genIterPyWrap()
+		g.genParallelPyWrap()
+		g.genRunPyWrap()
+		g.genAsyncPyWrap()
+		g.genExtraPyWrap()
+		g.genHandlesPyWrap()
+		g.genAsPyWrap()
+		g.genAbcPyWrap()
+		g.genThreadsPyWrap()
+		g.genLocksPyWrap()
+		g.genColumnsPyWrap()
+		g.genRegistryPyWrap()
+		g.genLossyPyWrap()
+		g.genAdaptPyWrap()
+		g.genRunesPyWrap()
+		g.genErrorMapPyWrap()
+		g.genArgChecksPyWrap()
 		g.genPkgWrapOut()
 	} else {
 		g.genAll()
@@ -584,7 +1055,8 @@ func (g *pyGen) genPkg(p *Package) {
 
 func (g *pyGen) genGoPreamble() {
 	pkgimport := ""
-	for pp, pnm := range current.imports {
+	for _, pp := range sortedNames(current.imports) {
+		pnm := current.imports[pp]
 		_, psfx := filepath.Split(pp)
 		if psfx != pnm {
 			pkgimport += fmt.Sprintf("\n\t%s %q", pnm, pp)
@@ -593,7 +1065,7 @@ func (g *pyGen) genGoPreamble() {
 		}
 	}
 	libcfg := func() string {
-		pycfg, err := GetPythonConfig(g.cfg.VM)
+		pycfg, err := g.cfg.PythonConfig()
 		if err != nil {
 			panic(err)
 		}
@@ -616,19 +1088,24 @@ func (g *pyGen) genGoPreamble() {
 	if g.mode == ModeExe && g.cfg.Main == "" {
 		g.cfg.Main = "GoPyMainRun()" // default is just to run main
 	}
-	exeprec := ""
-	exeprego := ""
+	exeprec, exeprego := genConvPreamble()
+	if usesDataclass() {
+		exeprec += dataclassPreC
+	}
+	if usesBorrow() {
+		exeprec += borrowPreC
+	}
 	if g.mode == ModeExe {
-		exeprec = fmt.Sprintf(goExePreambleC, g.cfg.Name)
-		exeprego = goExePreambleGo
+		exeprec += fmt.Sprintf(goExePreambleC, g.cfg.Name)
+		exeprego += fmt.Sprintf(goExePreambleGo, g.cfg.Name)
 	}
-	g.gofile.Printf(goPreamble, g.cfg.Name, g.cfg.Cmd, libcfg, GoHandle, CGoHandle,
-		pkgimport, g.cfg.Main, exeprec, exeprego)
+	g.printTemplate(g.gofile, "go.tmpl", goPreamble, g.cfg.Name, g.cfg.Cmd, libcfg, GoHandle, CGoHandle,
+		pkgimport, g.cfg.Main, exeprec, exeprego, curHandle.goConv)
 	g.gofile.Printf("\n// --- generated code for package: %[1]s below: ---\n\n", g.cfg.Name)
 }
 
 func (g *pyGen) genPyBuildPreamble() {
-	g.pybuild.Printf(PyBuildPreamble, g.cfg.Name, g.cfg.Cmd)
+	g.printTemplate(g.pybuild, "build.py.tmpl", PyBuildPreamble, g.cfg.Name, g.cfg.Cmd, PyHandle)
 }
 
 func (g *pyGen) genPyWrapPreamble() {
@@ -644,31 +1121,39 @@ func (g *pyGen) genPyWrapPreamble() {
 
 	// import other packages for other types that we might use
 	var impstr, impgenstr string
-	impgenNames := []string{"_" + g.cfg.Name, "go"}
+	impgenNames := []string{g.cfg.ExtName(), "go"}
+	if len(g.mixins) > 0 {
+		impgenNames = append(impgenNames, "_mixins")
+	}
 	switch {
 	case g.pkg.Name() == "go":
 		if g.cfg.PkgPrefix != "" {
-			impgenstr += fmt.Sprintf("from %s import %s\n", g.cfg.PkgPrefix, "_"+g.cfg.Name)
+			impgenstr += fmt.Sprintf("from %s import %s\n", g.cfg.PkgPrefix, g.cfg.ExtName())
 		} else {
-			impgenstr += fmt.Sprintf("import %s\n", "_"+g.cfg.Name)
+			impgenstr += pyImportLocal
+			impgenstr += fmt.Sprintf("%[1]s = _gopy_import(%[1]q)\n", g.cfg.ExtName())
 		}
-		impstr += fmt.Sprintf(GoPkgDefs, g.cfg.Name)
+		impstr += fmt.Sprintf(GoPkgDefs, g.cfg.Name, g.prettyPyMethods(), curHandle.pyZero)
 	case g.mode == ModeGen || g.mode == ModeBuild || g.mode == ModePkg:
 		if g.cfg.PkgPrefix != "" {
 			for _, name := range impgenNames {
 				impgenstr += fmt.Sprintf("from %s import %s\n", g.cfg.PkgPrefix, name)
 			}
 		} else {
+			impgenstr += pyImportLocal
 			for _, name := range impgenNames {
-				impgenstr += fmt.Sprintf("import %s\n", name)
+				impgenstr += fmt.Sprintf("%[1]s = _gopy_import(%[1]q)\n", name)
 			}
 		}
 	case g.mode == ModeExe:
 		// exe mode ignores PkgPrefix, because it is always built in to exe
-		impgenstr += fmt.Sprintf("import _%s\n", g.cfg.Name)
-		impgenstr += fmt.Sprintf("from %s import go\n", g.cfg.Name)
+		impgenstr += fmt.Sprintf("import %s\n", g.cfg.ExtName())
+		impgenstr += fmt.Sprintf("from %s import go\n", g.cfg.FullName())
+		if len(g.mixins) > 0 {
+			impgenstr += fmt.Sprintf("from %s import _mixins\n", g.cfg.FullName())
+		}
 	default:
-		pkg := g.cfg.Name
+		pkg := g.cfg.FullName()
 		if g.cfg.PkgPrefix != "" {
 			pkg = g.cfg.PkgPrefix + "." + pkg
 		}
@@ -686,9 +1171,9 @@ func (g *pyGen) genPyWrapPreamble() {
 	impstr += importHereKeyString
 
 	if g.mode == ModeExe {
-		g.pywrap.Printf(PyWrapExePreamble, g.cfg.Name, g.cfg.Cmd, n, pkgimport, pkgDoc, impgenstr, impstr)
+		g.printTemplate(g.pywrap, "py_exe.tmpl", PyWrapExePreamble, g.cfg.FullName(), g.cfg.Cmd, n, pkgimport, pkgDoc, impgenstr, impstr)
 	} else {
-		g.pywrap.Printf(PyWrapPreamble, g.cfg.Name, g.cfg.Cmd, n, pkgimport, pkgDoc, impgenstr, impstr)
+		g.printTemplate(g.pywrap, "py.tmpl", PyWrapPreamble, g.cfg.FullName(), g.cfg.Cmd, n, pkgimport, pkgDoc, impgenstr, impstr)
 	}
 }
 
@@ -712,20 +1197,39 @@ func (g *pyGen) genMakefile() {
 	gencmd := strings.Replace(g.cfg.Cmd, "gopy build", "gopy gen", 1)
 	gencmd = CmdStrToMakefile(gencmd)
 
-	pycfg, err := GetPythonConfig(g.cfg.VM)
+	pycfg, err := g.cfg.PythonConfig()
 	if err != nil {
 		panic(err)
 	}
 
+	g.checkBuildSystem()
+	if g.cmake() {
+		g.genCMakeLists(gencmd, pycfg)
+		return
+	}
+	if g.bazel() {
+		g.genBazelBuild(gencmd, pycfg)
+		return
+	}
 	if g.mode == ModeExe {
-		g.makefile.Printf(MakefileExeTemplate, g.cfg.Name, g.cfg.Cmd, gencmd, g.cfg.VM, g.libext, pycfg.CFlags, pycfg.LdFlags)
+		g.printTemplate(g.makefile, "Makefile_exe.tmpl", MakefileExeTemplate, g.cfg.Name, g.cfg.Cmd, gencmd, g.cfg.VM, g.libext, pycfg.CFlags, pycfg.LdFlags, g.makefileCross())
 	} else {
 		winhack := ""
 		if WindowsOS {
 			winhack = fmt.Sprintf(`# windows-only sed hack here to fix pybindgen declaration of PyInit
   sed -i "s/ PyInit_/ __declspec(dllexport) PyInit_/g" %s.c`, g.cfg.Name)
 		}
-		g.makefile.Printf(MakefileTemplate, g.cfg.Name, g.cfg.Cmd, gencmd, g.cfg.VM, g.libext, g.extraGccArgs, pycfg.CFlags, pycfg.LdFlags, winhack)
+		extra := ""
+		if g.cfg.ExtraGo != "" {
+			files, _ := extraGoFiles(g.cfg.ExtraGo) // errors reported by genExtraGo
+			for _, fn := range files {
+				extra += " " + filepath.Base(fn)
+			}
+		}
+		g.printTemplate(g.makefile, "Makefile.tmpl", MakefileTemplate, g.cfg.Name, g.cfg.Cmd, gencmd, g.cfg.VM, g.libext, g.extraGccArgs, pycfg.CFlags, pycfg.LdFlags, winhack, extra, g.makefileCross(), strings.Join(g.cfg.archiveLibs(), " "))
+		if g.cfg.GenPerf {
+			g.makefile.Printf(perfMakefile, g.cfg.Name)
+		}
 	}
 }
 
@@ -768,6 +1272,11 @@ func (g *pyGen) genAll() {
 
 	g.gofile.Printf("\n// ---- Types ---\n")
 	g.pywrap.Printf("\n# ---- Types ---\n")
+	g.pywrap.Printf("\n# zero returns the Go zero value of any bound type, e.g., zero(T)\n")
+	g.pywrap.Printf("zero = go.zero\n")
+	g.pywrap.Printf("\n# as_ returns a Go value as a bound type, asserted or converted, e.g., as_(T, obj)\n")
+	g.pywrap.Printf("as_ = go.as_\n")
+	start := g.pywrap.buf.Len()
 	names := current.names()
 	for _, n := range names {
 		sym := current.sym(n)
@@ -783,7 +1292,14 @@ func (g *pyGen) genAll() {
 	g.pywrap.Printf("\n\n#---- Enums from Go (collections of consts with same type) ---\n")
 	// conditionally add Enum support because it is an external dependency in py2
 	if len(g.pkg.enums) > 0 {
-		g.pywrap.Printf("from enum import Enum\n\n")
+		g.pywrap.Printf("from enum import Enum\n")
+		for _, e := range g.pkg.enums {
+			if e.IsFlags() {
+				g.pywrap.Printf("from enum import IntFlag\n")
+				break
+			}
+		}
+		g.pywrap.Printf("\n")
 	}
 	for _, e := range g.pkg.enums {
 		g.genEnum(e)
@@ -800,8 +1316,13 @@ func (g *pyGen) genAll() {
 		g.genVar(v)
 	}
 
+	g.gofile.Printf("\n\n// ---- Errors ---\n")
+	g.pywrap.Printf("\n\n# ---- Errors: python exceptions for Go sentinel errors and error types ---\n")
+	g.genErrors()
+
 	g.gofile.Printf("\n\n// ---- Interfaces ---\n")
 	g.pywrap.Printf("\n\n# ---- Interfaces ---\n")
+	g.pkg.sortIfaceEmbeds()
 	for _, ifc := range g.pkg.ifaces {
 		g.genInterface(ifc)
 	}
@@ -825,8 +1346,8 @@ func (g *pyGen) genAll() {
 		g.genMap(m.sym, false, false, m)
 	}
 
-	// note: these are extracted from reg functions that return full
-	// type (not pointer -- should do pointer but didn't work yet)
+	// note: these are extracted from reg functions that return the
+	// struct type or a pointer to it (optionally with an error)
 	g.gofile.Printf("\n\n// ---- Constructors ---\n")
 	g.pywrap.Printf("\n\n# ---- Constructors ---\n")
 	for _, s := range g.pkg.structs {
@@ -840,6 +1361,18 @@ func (g *pyGen) genAll() {
 	for _, f := range g.pkg.funcs {
 		g.genFunc(f)
 	}
+	for _, c := range g.pkg.columns {
+		g.genColumnsFunc(c)
+	}
+	g.genParallelAlias()
+	g.genRunAlias()
+	g.genExtraAlias()
+	g.exports[g.pkg] = pyTopLevelNames(g.pywrap.buf.Bytes()[start:])
+
+	g.genAs()
+
+	g.pywrap.Printf("\n\n# ---- Go type registry ---\n")
+	g.genRegistry()
 }
 
 func (g *pyGen) genGoPkg() {
@@ -864,6 +1397,16 @@ func (g *pyGen) genGoPkg() {
 	}
 }
 
+// genCycleGuard generates the decorator of the str and repr methods of the
+// classes of structs and containers, whose values can be self-referential
+func (g *pyGen) genCycleGuard() {
+	gocl := "go."
+	if g.pkg == goPackage {
+		gocl = ""
+	}
+	g.pywrap.Printf("@%scycle_guard\n", gocl)
+}
+
 // genStringerCall generates a call to either self.String() or self.string()
 // depending on RenameCase option
 func (g *pyGen) genStringerCall() {
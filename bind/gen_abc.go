@@ -0,0 +1,105 @@
+// Copyright 2024 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package bind
+
+import (
+	"go/types"
+	"strings"
+)
+
+// Each bound Go interface Iface also has a python abstract base class,
+// AbstractIface, with an abstract method for each of its bound methods, so
+// that the python classes implementing it by deriving from AbstractIface
+// fail to instantiate, naming the missing methods.  As Go interfaces are
+// implemented implicitly, isinstance(v, AbstractIface) is true for any
+// value having all the methods, whether it is of a python class or of the
+// wrapper class of a Go type implementing the interface, and the wrapper
+// class of the interface itself is registered as a subclass.
+
+const (
+	// python support of the interface abstract base classes, in go.py
+	abcPyWrap = `
+# ---- abstract base classes of the Go interfaces ---
+import abc
+
+def implements(C, names):
+	"""implements returns True if the class C has the methods of the names, as needed to implement a Go interface,
+	and NotImplemented otherwise, for the __subclasshook__ of the interface abstract base classes"""
+	mro = C.__mro__
+	for name in names:
+		for B in mro:
+			if name in B.__dict__:
+				if B.__dict__[name] is None:
+					return NotImplemented
+				break
+		else:
+			return NotImplemented
+	return True
+
+`
+)
+
+// genAbcPyWrap generates the python support of the interface abstract
+// base classes in go.py
+func (g *pyGen) genAbcPyWrap() {
+	g.pywrap.Printf("%s", abcPyWrap)
+}
+
+// abcName returns the name of the abstract base class of the interface
+func abcName(ifc *Interface) string {
+	return "Abstract" + ifc.obj.Name()
+}
+
+// genIfaceAbc generates the abstract base class of the interface, with
+// abstract stubs of its bound methods meths
+func (g *pyGen) genIfaceAbc(ifc *Interface, meths []*Func) {
+	abnm := abcName(ifc)
+	var names []string
+	g.pywrap.Printf(`
+# Python abstract base class for interface %[2]s
+class %[1]s(go.abc.ABC):
+	""%[3]q""
+`,
+		abnm,
+		ifc.GoName(),
+		abnm+" is the abstract base class of the python implementations of the Go interface "+ifc.GoName()+".\n"+ifc.Doc(),
+	)
+	g.pywrap.Indent()
+	for _, m := range meths {
+		fn, ok := g.pyFuncName(m)
+		if !ok {
+			continue
+		}
+		names = append(names, fn)
+		args, _, _ := m.pkg.docItems(m.obj.Type().(*types.Signature), m.commaOk)
+		pyArgs := []string{"self"}
+		for _, it := range args {
+			if it.name == "goRun" {
+				continue // only for calling Go
+			}
+			pyArgs = append(pyArgs, it.pyArg())
+		}
+		g.pywrap.Printf("@go.abc.abstractmethod\n")
+		g.pywrap.Printf("def %s(%s):\n", fn, strings.Join(pyArgs, ", "))
+		g.pywrap.Indent()
+		g.pywrap.Printf("%q\n", funcDocBody(m))
+		g.pywrap.Printf("raise NotImplementedError\n")
+		g.pywrap.Outdent()
+	}
+	for i, nm := range names {
+		names[i] = "'" + nm + "'"
+	}
+	g.pywrap.Printf("@classmethod\n")
+	g.pywrap.Printf("def __subclasshook__(cls, C):\n")
+	g.pywrap.Indent()
+	g.pywrap.Printf("if cls is %s:\n", abnm)
+	g.pywrap.Indent()
+	g.pywrap.Printf("return go.implements(C, %s)\n", pyTuple(names))
+	g.pywrap.Outdent()
+	g.pywrap.Printf("return NotImplemented\n")
+	g.pywrap.Outdent()
+	g.pywrap.Outdent()
+	g.pywrap.Printf("\n%s.register(%s)\n", abnm, ifc.obj.Name())
+}
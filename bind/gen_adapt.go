@@ -0,0 +1,77 @@
+// Copyright 2024 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package bind
+
+import (
+	"fmt"
+	"go/types"
+)
+
+// The slices, arrays and maps returned by the functions and methods are
+// python proxies of the Go values, by handle, e.g., Slice_int, reading and
+// writing them in place, without copying them.  With the CopyMax option,
+// those of unnamed types with at most CopyMax elements are returned as
+// python lists and dicts instead, copied, which are more convenient, and
+// faster to use when small, the larger ones still being proxied.  The
+// threshold is go.copy_max, which can be changed at run time, e.g., to 0
+// to proxy them all, and the docstrings of the functions record the policy.
+// The byte slices, and the named containers, which can have methods, are
+// always proxied.
+
+// CopyMax is the maximum number of elements of the containers returned as
+// python lists and dicts, 0 proxying them all -- this must be a global as
+// it is relevant during initial package parsing, for the docstrings
+var CopyMax = 0
+
+// adaptPyDefs is the python adaptive conversion of the Go containers
+// returned, in go.py
+// 1 = CopyMax
+const adaptPyDefs = `
+# ---- Adaptive conversion of the Go slices, arrays and maps returned ---
+copy_max = %[1]d
+
+def adapt(v):
+	"""adapt returns the proxy v of a Go slice, array or map as a python list or dict, copied,
+	if it has at most copy_max elements, otherwise v itself"""
+	if len(v) > copy_max:
+		return v
+	if hasattr(v, 'items'):
+		return dict(v.items())
+	return list(v)
+
+`
+
+// genAdaptPyWrap generates the go.adapt conversion of the containers
+// returned, with the CopyMax option
+func (g *pyGen) genAdaptPyWrap() {
+	if CopyMax <= 0 {
+		return
+	}
+	g.pywrap.Printf(adaptPyDefs, CopyMax)
+}
+
+// isAdapted returns whether the container of the symbol returned by the
+// functions is converted by go.adapt, with the CopyMax option
+func isAdapted(sym *symbol) bool {
+	if CopyMax <= 0 || sym.isNamed() || !(sym.isSlice() || sym.isArray() || sym.isMap()) {
+		return false
+	}
+	if slc, ok := sym.gotyp.Underlying().(*types.Slice); ok {
+		if b, ok := slc.Elem().Underlying().(*types.Basic); ok && b.Kind() == types.Uint8 {
+			return false
+		}
+	}
+	return true
+}
+
+// adaptCost returns how the adapted container of the symbol is returned,
+// along with its rough cost, for the docstrings of functions
+func adaptCost(sym *symbol) string {
+	kind := "list"
+	if sym.isMap() {
+		kind = "dict"
+	}
+	return fmt.Sprintf("copied as %s, O(len), if at most go.copy_max (%d) elements, else %s", kind, CopyMax, sym.convCost())
+}
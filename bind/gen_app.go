@@ -0,0 +1,263 @@
+// Copyright 2024 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package bind
+
+import (
+	"bytes"
+	"go/types"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// With GenApp, as by gopy quickstart, a sample python application, app.py,
+// is generated at the root of the python package of the bindings, using
+// their major features on the bound API of each package: the constants and
+// variables, the structs, with their fields, copies, pickling, equality,
+// as_ conversions and locks, the interfaces they implement, the functions
+// without args, also called in goroutines by go.run and go.parallel_map,
+// the Go slices and the handles in use.  Each demo is run apart, printing
+// the exceptions it raises, so that the app runs to the end on any API.
+
+const (
+	// 1 = names of the packages, 2 = cmd, 3 = import statement
+	pyAppPreamble = `# sample python application of the gopy bindings of %[1]s, using their
+# major features on the bound API.
+# File is generated by gopy (will not be overwritten though), as a starting point to edit.
+# %[2]s
+
+from __future__ import print_function
+
+import copy, pickle, traceback
+
+%[3]s
+
+def demo(fn):
+	"""demo runs the demo function fn, printing its title and the exceptions it raises"""
+	print()
+	print("== %%s ==" %% fn.__doc__)
+	try:
+		fn()
+	except Exception:
+		traceback.print_exc()
+
+`
+
+	// the demos of the go package
+	pyAppGo = `def demo_go_slices():
+	"""Go slices, proxied by handles"""
+	s = go.Slice_int([1, 2, 3])
+	s.append(4)
+	print("go.Slice_int:", s, "len:", len(s), "sum:", sum(s))
+	print("to_list:", s.to_list())
+
+def demo_go_handles():
+	"""handles of the Go values held by python"""
+	print("handles in use:", go.num_handles())
+	for typ, (n, refs) in go.handle_stats().items():
+		print("  %%s: %%d handles, %%d refs" %% (typ, n, refs))
+
+`
+)
+
+// genApp generates app.py, the sample python application of the bindings,
+// at the root of their python package, unless it exists, as it is edited
+func (g *pyGen) genApp() {
+	// as genTests, the bindings are imported from the root of their python
+	// package, or as top-level modules
+	root, from := ".", ""
+	switch g.cfg.PkgPrefix {
+	case ".":
+		root, from = "..", filepath.Base(g.cfg.OutputDir)
+		if g.cfg.Parent != "" {
+			from = g.cfg.FullName()
+			for range strings.Split(g.cfg.Parent, ".") {
+				root = filepath.Join(root, "..")
+			}
+		}
+	case "":
+	default:
+		from = g.cfg.PkgPrefix
+	}
+
+	if _, err := os.Stat(filepath.Join(g.cfg.OutputDir, root, "app.py")); err == nil {
+		return
+	}
+
+	var names []string
+	for _, p := range Packages {
+		if p != goPackage {
+			names = append(names, p.Name())
+		}
+	}
+	imp := "import " + strings.Join(append([]string{"go"}, names...), ", ")
+	if from != "" {
+		imp = "from " + from + " " + imp
+	}
+
+	pr := &printer{buf: new(bytes.Buffer), indentEach: []byte("\t")}
+	pr.Printf(pyAppPreamble, strings.Join(names, ", "), g.cfg.Cmd, imp)
+	var demos []string
+	for _, p := range Packages {
+		if p != goPackage {
+			demos = append(demos, g.genPkgApp(pr, p)...)
+		}
+	}
+	pr.Printf(pyAppGo)
+	demos = append(demos, "demo_go_slices", "demo_go_handles")
+
+	pr.Printf("def main():\n")
+	pr.Indent()
+	for _, d := range demos {
+		pr.Printf("demo(%s)\n", d)
+	}
+	pr.Outdent()
+	pr.Printf("\nif __name__ == \"__main__\":\n")
+	pr.Indent()
+	pr.Printf("main()\n")
+	pr.Outdent()
+	g.genPrintOut(filepath.Join(root, "app.py"), pr)
+}
+
+// genPkgApp generates the demo functions of the package, returning their
+// names
+func (g *pyGen) genPkgApp(pr *printer, p *Package) []string {
+	pn := p.Name()
+	var demos []string
+	begin := func(name, title string) {
+		fn := "demo_" + pn + "_" + name
+		demos = append(demos, fn)
+		pr.Printf("def %s():\n", fn)
+		pr.Indent()
+		pr.Printf("%q\n", pn+" "+title)
+	}
+	end := func() {
+		pr.Outdent()
+		pr.Printf("\n")
+	}
+
+	var consts []*Const
+	for _, c := range p.consts {
+		if isPyCompatVar(c.sym) == nil && !c.sym.isSignature() {
+			consts = append(consts, c)
+		}
+	}
+	for _, e := range p.enums {
+		consts = append(consts, e.items...)
+	}
+	var vars []*Var
+	for _, v := range p.vars {
+		if isPyCompatVar(v.sym) == nil && !v.sym.isSignature() {
+			vars = append(vars, v)
+		}
+	}
+	if len(consts) > 0 || len(vars) > 0 {
+		begin("values", "constants and variables")
+		for _, c := range consts {
+			pr.Printf("print(%q, repr(%s.%s))\n", pn+"."+c.GoName()+" =", pn, c.GoName())
+		}
+		for _, v := range vars {
+			get := v.Name()
+			if g.cfg.RenameCase {
+				get = toSnakeCase(get)
+			}
+			pr.Printf("print(%q, repr(%s.%s()))\n", pn+"."+get+"() =", pn, get)
+		}
+		end()
+	}
+
+	for _, s := range p.structs {
+		nm := s.obj.Name()
+		begin("struct_"+nm, "struct "+nm)
+		pr.Printf("v = %s.%s()\n", pn, nm)
+		pr.Printf("print(%q, repr(v))\n", pn+"."+nm+"():")
+		if isDataclass(s.sym) {
+			pr.Printf("print(\"asdict:\", go.dataclasses.asdict(v))\n")
+			end()
+			continue
+		}
+		if g.hasBoundFields(s) && !g.hasStructMember(s, "fields") {
+			pr.Printf("print(\"fields:\", v.fields())\n")
+		}
+		pr.Printf("c = copy.copy(v)\n")
+		if types.Comparable(s.GoType()) {
+			pr.Printf("print(\"copy == v:\", c == v, \"hash:\", hash(c) == hash(v))\n")
+		}
+		pr.Printf("try:\n")
+		pr.Indent()
+		pr.Printf("print(\"pickled:\", repr(pickle.loads(pickle.dumps(v))))\n")
+		pr.Outdent()
+		pr.Printf("except go.GoError as e: # fields not marshaled by encoding/json\n")
+		pr.Indent()
+		pr.Printf("print(\"not picklable:\", e)\n")
+		pr.Outdent()
+		pr.Printf("print(\"as_:\", repr(go.as_(%s.%s, v)))\n", pn, nm)
+		if p.isGuarded(nm) && !g.hasStructMember(s, "locked") {
+			pr.Printf("with v.locked():\n")
+			pr.Indent()
+			pr.Printf("print(\"locked:\", repr(v))\n")
+			pr.Outdent()
+		}
+		for _, ifc := range p.ifaces {
+			if types.Implements(types.NewPointer(s.GoType()), ifc.Interface()) {
+				pr.Printf("print(%q, isinstance(v, %s.%s))\n", "implements "+ifc.GoName()+":", pn, abcName(ifc))
+			}
+		}
+		end()
+	}
+
+	var funcs []*Func
+	for _, s := range p.structs {
+		funcs = append(funcs, s.ctors...)
+	}
+	funcs = append(funcs, p.funcs...)
+	var (
+		calls []string // python names of the functions without args
+		safe  string   // first of them not raising
+	)
+	for _, f := range funcs {
+		if fn, ok := g.pyFuncName(f); ok && len(f.sig.Params()) == 0 {
+			calls = append(calls, fn)
+			if safe == "" && !f.err && f.commaOk != commaOkRaise {
+				safe = fn
+			}
+		}
+	}
+	if len(calls) > 0 {
+		begin("funcs", "functions")
+		for _, fn := range calls {
+			pr.Printf("try:\n")
+			pr.Indent()
+			pr.Printf("print(%q, repr(%s.%s()))\n", pn+"."+fn+"():", pn, fn)
+			pr.Outdent()
+			pr.Printf("except (go.GoError, KeyError) as e:\n")
+			pr.Indent()
+			pr.Printf("print(%q, repr(e))\n", pn+"."+fn+"() raised:")
+			pr.Outdent()
+		}
+		end()
+	}
+	if safe != "" {
+		begin("goroutines", "functions called in goroutines")
+		pr.Printf("fut = go.run(%s.%s)\n", pn, safe)
+		pr.Printf("print(\"go.run:\", repr(fut.result()))\n")
+		pr.Printf("print(\"go.parallel_map:\", go.parallel_map(lambda _: %s.%s(), range(4)))\n", pn, safe)
+		end()
+	}
+	return demos
+}
+
+// hasBoundFields returns whether the struct has bound fields, returned by
+// its fields method
+func (g *pyGen) hasBoundFields(s *Struct) bool {
+	typ := s.Struct()
+	for i := 0; i < typ.NumFields(); i++ {
+		f := typ.Field(i)
+		if _, err := isPyCompatField(f); err == nil && current.symtype(f.Type()) != nil {
+			return true
+		}
+	}
+	return false
+}
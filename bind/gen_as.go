@@ -0,0 +1,149 @@
+// Copyright 2024 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package bind
+
+import (
+	"fmt"
+)
+
+// pkg.as_(T, obj) returns the Go value of the wrapper obj as the bound type
+// of the class T, for navigating the APIs returning interfaces: the Go value
+// is asserted to implement T, if it is an interface, or to be of type T, or
+// converted to T, as Go converts between the named types of the same
+// underlying type, e.g., a Shape holding a *Circle as Circle, or a Names
+// slice as Tags.  It raises TypeError if the Go value is none of these.
+// The result is a new wrapper, of a new handle of the same Go value, except
+// for the values held by interfaces, which are copied, as by a type
+// assertion.  T is any struct, interface, slice or map class of the
+// bound packages.
+
+const (
+	// go code of the assertions and conversions of the wrapped values
+	asGo = `
+// ---- as_ support for asserting and converting the wrapped values ---
+
+// gopyAs returns a new handle of the Go value of the handle h asserted to
+// implement the interface type t, or asserted or converted to the type t,
+// setting a python TypeError if it cannot be.  The wrappers of the other
+// types than interfaces hold pointers to their values.
+func gopyAs(h CGoHandle, t reflect.Type, typnm string) CGoHandle {
+	v, err := gopyh.VarFromHandleTry(handleGo(h), typnm)
+	if err != nil || gopyh.IfaceIsNil(v) {
+		gopyAsError(fmt.Sprintf("as_: nil Go value is not %s", typnm))
+		return handleC(-1)
+	}
+	rv := reflect.ValueOf(v)
+	switch {
+	case t.Kind() == reflect.Interface:
+		if rv.Type().Implements(t) {
+			return handleC(gopyh.Register(typnm, v))
+		}
+	case rv.Type().ConvertibleTo(t):
+		return handleC(gopyh.Register(typnm, rv.Convert(t).Interface()))
+	case t.Kind() == reflect.Ptr && rv.Type().ConvertibleTo(t.Elem()):
+		// the values held by interfaces are copied, as by a type assertion
+		p := reflect.New(t.Elem())
+		p.Elem().Set(rv.Convert(t.Elem()))
+		return handleC(gopyh.Register(typnm, p.Interface()))
+	}
+	gopyAsError(fmt.Sprintf("as_: Go %s is not %s", rv.Type(), typnm))
+	return handleC(-1)
+}
+
+// gopyAsError sets the python TypeError of a failed assertion or conversion
+func gopyAsError(msg string) {
+	estr := C.CString(msg)
+	C.PyErr_SetString(C.PyExc_TypeError, estr)
+	C.free(unsafe.Pointer(estr))
+}
+`
+
+	// python as_ function, in go
+	asPyWrap = `
+# ---- as_: Go type assertions and conversions of the wrapped values ---
+_as_funcs = {}
+
+def register_as(cls, fn):
+	"""register_as registers fn, returning the handle of the Go value of a handle asserted or converted to the
+	bound type of the class cls, for as_"""
+	_as_funcs[cls] = fn
+
+def as_(cls, obj):
+	"""as_ returns the Go value of the wrapper obj as the bound type of the class cls, e.g., as_(Circle, shape):
+	the Go value is asserted to implement cls, if it is an interface, or to be of type cls, or converted
+	to cls, as Go converts between the named types of the same underlying type.
+	It raises TypeError if the Go value is none of these"""
+	for c in getattr(cls, '__mro__', ()):
+		fn = _as_funcs.get(c)
+		if fn is not None:
+			break
+	else:
+		raise TypeError("as_: %%r is not the class of a bound Go type" %% (cls,))
+	if not isinstance(obj, GoClass):
+		raise TypeError("as_: expected a Go value, got %%s" %% type(obj).__name__)
+	return cls(handle=fn(obj.handle))
+
+`
+)
+
+// genAsGo generates the go code of as_
+func (g *pyGen) genAsGo() {
+	g.gofile.Printf("%s", asGo)
+}
+
+// genAsPyWrap generates the go.as_ python function
+func (g *pyGen) genAsPyWrap() {
+	g.pywrap.Printf(asPyWrap)
+}
+
+// genAs generates the go functions asserting or converting the wrapped
+// values to the struct, interface, slice and map types of the package,
+// registering them for as_
+func (g *pyGen) genAs() {
+	var (
+		syms []*symbol
+		clss []string // names of the python classes
+	)
+	add := func(sym *symbol, cls string) {
+		syms = append(syms, sym)
+		clss = append(clss, cls)
+	}
+	for _, s := range g.pkg.structs {
+		if !isDataclass(s.sym) {
+			add(s.sym, s.obj.Name())
+		}
+	}
+	for _, ifc := range g.pkg.ifaces {
+		add(ifc.sym, ifc.obj.Name())
+	}
+	for _, s := range g.pkg.slices {
+		add(s.sym, s.obj.Name())
+	}
+	for _, m := range g.pkg.maps {
+		add(m.sym, m.obj.Name())
+	}
+	if len(syms) == 0 {
+		return
+	}
+
+	g.gofile.Printf("\n\n// ---- as_ assertions and conversions ---\n")
+	g.pywrap.Printf("\n\n# ---- as_ assertions and conversions ---\n")
+	for i, sym := range syms {
+		fn := sym.id + "_GoPyAs"
+		typ := fmt.Sprintf("reflect.TypeOf((*%s)(nil))", sym.goname)
+		if sym.isInterface() {
+			typ += ".Elem()"
+		}
+		g.gofile.Printf("\n//export %s\n", fn)
+		g.gofile.Printf("func %s(h CGoHandle) CGoHandle {\n", fn)
+		g.gofile.Indent()
+		g.gofile.Printf("return gopyAs(h, %s, %q)\n", typ, sym.goname)
+		g.gofile.Outdent()
+		g.gofile.Printf("}\n")
+
+		g.pybuild.Printf("add_checked_function(mod, '%s', retval('%s'), [param('%s', 'h')])\n", fn, PyHandle, PyHandle)
+		g.pywrap.Printf("go.register_as(%s, _%s.%s)\n", clss[i], g.cfg.Name, fn)
+	}
+}
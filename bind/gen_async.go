@@ -0,0 +1,180 @@
+// Copyright 2024 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package bind
+
+import (
+	"fmt"
+	"strings"
+)
+
+// The asyncio layer runs the blocking Go calls in goroutines, with go.run,
+// and exposes their results as asyncio futures, so that async python code
+// can await Go calls and channels without blocking the event loop:
+//
+//	v = await go.run_async(pkg.Compute, 42)
+//	v, ok = await ch.recv_async()
+//	async for v in ch:
+//		...
+//
+// The goroutines hand their results over to a dispatcher thread, which
+// completes the asyncio futures in the threads of their event loops, with
+// call_soon_threadsafe, so that the goroutines only hold the GIL briefly.
+// Cancelling an asyncio future does not stop its Go call: the result of a
+// cancelled recv_async, in particular, is dropped.
+//
+// With the AsyncAPI option, each bound function and method, e.g., Compute,
+// also has an async twin, Compute_async, of the same params, returning the
+// asyncio future of go.run_async of it, so that the applications can
+// migrate to asyncio incrementally, with the same bindings:
+//
+//	v = pkg.Compute(42)
+//	v = await pkg.Compute_async(42)
+
+const (
+	// python asyncio functions, after runPyWrap
+	asyncPyWrap = `
+# ---- asyncio support for awaiting Go calls and channels ---
+import threading as _threading
+try:
+	import queue as _queue
+except ImportError:
+	import Queue as _queue
+
+_async_queue = _queue.Queue()
+_async_lock = _threading.Lock()
+_async_thread = None
+
+def _async_settle(afut, fut):
+	if afut.done():
+		return
+	exc = fut.exception()
+	if exc is not None:
+		afut.set_exception(exc)
+	else:
+		afut.set_result(fut.result())
+
+def _async_dispatch():
+	while True:
+		loop, afut, fut = _async_queue.get()
+		try:
+			loop.call_soon_threadsafe(_async_settle, afut, fut)
+		except RuntimeError:
+			pass # the event loop is closed
+
+def _async_start():
+	global _async_thread
+	with _async_lock:
+		if _async_thread is None:
+			_async_thread = _threading.Thread(target=_async_dispatch, name="gopy-asyncio-dispatcher")
+			_async_thread.daemon = True
+			_async_thread.start()
+
+def run_async(fn, *args, **kwargs):
+	"""run_async calls fn(*args, **kwargs) in a new goroutine, as go.run, returning an asyncio future
+	of its result, or of the exception it raised, in the running event loop.  Cancelling the future
+	does not stop the call."""
+	import asyncio
+	try:
+		loop = asyncio.get_running_loop()
+	except AttributeError: # python < 3.7
+		loop = asyncio.get_event_loop()
+	afut = loop.create_future()
+	_async_start()
+	run(fn, *args, **kwargs).add_done_callback(lambda fut: _async_queue.put((loop, afut, fut)))
+	return afut
+
+def _async_anext(ch):
+	"""_async_anext returns an asyncio future of the next value received from the channel,
+	or of StopAsyncIteration if it is closed"""
+	def recv_next():
+		v, ok = ch.recv()
+		if not ok:
+			raise StopAsyncIteration
+		return v
+	return run_async(recv_next)
+
+`
+)
+
+// genAsyncPyWrap generates the asyncio functions of go
+func (g *pyGen) genAsyncPyWrap() {
+	g.pywrap.Printf("%s", asyncPyWrap)
+}
+
+// genChanAsync generates the asyncio methods of a channel class, calling
+// its send and recv methods, which must have been generated, in goroutines
+func (g *pyGen) genChanAsync(canSend, canRecv bool) {
+	gocl := "go."
+	if g.pkg == goPackage {
+		gocl = ""
+	}
+	if canSend {
+		g.pywrap.Printf("def send_async(self, value):\n")
+		g.pywrap.Indent()
+		g.pywrap.Printf(`"""send_async returns an asyncio future of sending the value on the channel, as send"""
+`)
+		g.pywrap.Printf("return %srun_async(self.send, value)\n", gocl)
+		g.pywrap.Outdent()
+	}
+	if canRecv {
+		g.pywrap.Printf("def recv_async(self):\n")
+		g.pywrap.Indent()
+		g.pywrap.Printf(`"""recv_async returns an asyncio future of the (value, ok) tuple received from the channel, as recv"""
+`)
+		g.pywrap.Printf("return %srun_async(self.recv)\n", gocl)
+		g.pywrap.Outdent()
+
+		g.pywrap.Printf("def __aiter__(self):\n")
+		g.pywrap.Indent()
+		g.pywrap.Println("return self")
+		g.pywrap.Outdent()
+
+		g.pywrap.Printf("def __anext__(self):\n")
+		g.pywrap.Indent()
+		g.pywrap.Printf("return %s_async_anext(self)\n", gocl)
+		g.pywrap.Outdent()
+	}
+}
+
+// genAsyncTwin generates the async twin of the function or method, just
+// generated by genFuncSig, with the AsyncAPI option
+func (g *pyGen) genAsyncTwin(sym *symbol, fsym *Func) {
+	if !g.cfg.AsyncAPI {
+		return
+	}
+	name, ok := g.pyFuncName(fsym)
+	if !ok {
+		return
+	}
+	twin := name + "_async"
+	if sym == nil {
+		for _, f := range g.pkg.funcs {
+			if fn, ok := g.pyFuncName(f); ok && fn == twin {
+				if !NoWarn {
+					fmt.Printf("gopy: warning: no async twin of %s: %s is bound\n", qualName(fsym.obj), twin)
+				}
+				return
+			}
+		}
+	}
+	fn := name
+	var args, kwargs []string
+	for _, p := range g.pyParams {
+		nm, _, isKw := strings.Cut(p, "=")
+		switch {
+		case p == "self":
+			fn = "self." + name
+		case isKw:
+			kwargs = append(kwargs, nm+"="+nm)
+		default:
+			args = append(args, p)
+		}
+	}
+	g.pywrap.Printf("def %s(%s):\n", twin, strings.Join(g.pyParams, ", "))
+	g.pywrap.Indent()
+	g.pywrap.Printf(`"""%s returns an asyncio future of the result of %s, called in a goroutine by go.run_async"""`+"\n", twin, name)
+	g.pywrap.Printf("return go.run_async(%s)\n", strings.Join(append(append([]string{fn}, args...), kwargs...), ", "))
+	g.pywrap.Outdent()
+}
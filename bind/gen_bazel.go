@@ -0,0 +1,185 @@
+// Copyright 2024 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package bind
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// With the bazel BuildSystem, a BUILD.bazel is generated instead of the
+// Makefile, for consuming the bindings in Bazel workspaces with rules_go,
+// rules_cc and rules_python, by the same steps as the Makefile: a c-archive
+// go_binary of the cgo wrappers to the go functions, after goimports of
+// golang.org/x/tools, a genrule running pybindgen to generate the CPython
+// wrappers to them, a cc_binary linking both into the python extension
+// module, and a py_library of the python wrappers.  The deps of the
+// go_binary are labeled by the gazelle naming of go_repository, assuming
+// the modules are at the roots of their repositories, which gazelle
+// resolves in the workspace when they are not.  The executables of exe and
+// the perf targets are only built by the Makefile, and the platform of
+// cross builds is selected by the --platforms of bazel.
+
+const (
+	// 1 = name of package, 2 = cmdstr, 3 = gencmd, 4 = vm, 5 = libext,
+	// 6 = python CFLAGS, 7 = python LDFLAGS, 8 = go srcs, 9 = go deps,
+	// 10 = windows special declspec hack
+	bazelTemplate = `# BUILD.bazel for python interface for package %[1]s.
+# File is generated by gopy. Do not edit.
+# %[2]s
+#
+# regenerate with:
+#   %[3]s
+# and build the python package with, e.g.:
+#   bazel build //path/to/this/dir:%[1]s
+
+load("@io_bazel_rules_go//go:def.bzl", "go_binary")
+load("@rules_cc//cc:defs.bzl", "cc_binary")
+load("@rules_python//python:defs.bzl", "py_library")
+
+# the flags used to build python:
+PY_CFLAGS = %[6]s
+
+PY_LDFLAGS = %[7]s
+
+# goimports is needed to ensure that the imports list is valid
+genrule(
+    name = "%[1]s_goimports",
+    srcs = ["%[1]s.go"],
+    outs = ["%[1]s_goimports.go"],
+    cmd = "$(location @org_golang_x_tools//cmd/goimports) $(location %[1]s.go) > $@",
+    tools = ["@org_golang_x_tools//cmd/goimports"],
+)
+
+# the %[1]s_go archive and %[1]s_go.h from %[1]s.go -- the cgo wrappers to go functions
+go_binary(
+    name = "%[1]s_go",
+    srcs = %[8]s,
+    cgo = True,
+    linkmode = "c-archive",
+    deps = %[9]s,
+)
+
+# %[1]s.c from build.py -- the CPython wrappers to cgo wrappers, by pybindgen
+# note: pip install pybindgen to get pybindgen if this fails
+genrule(
+    name = "%[1]s_c",
+    srcs = ["build.py"],
+    outs = ["%[1]s.c"],
+    cmd = "%[4]s $(location build.py) && mv %[1]s.c $@"%[10]s,
+)
+
+# _%[1]s%[5]s -- the library that contains the cgo and CPython wrappers,
+# statically linking the go archive, imported by the generated %[1]s.py
+# python wrapper
+cc_binary(
+    name = "_%[1]s%[5]s",
+    srcs = [":%[1]s_c"],
+    copts = PY_CFLAGS + ["-w"],
+    linkopts = PY_LDFLAGS,
+    linkshared = True,
+    deps = [":%[1]s_go"],
+)
+
+py_library(
+    name = "%[1]s",
+    srcs = glob(
+        ["*.py"],
+        exclude = ["build.py"],
+    ),
+    data = [":_%[1]s%[5]s"],
+    imports = ["."],
+    visibility = ["//visibility:public"],
+)
+`
+)
+
+// bazel returns whether a BUILD.bazel is generated instead of the Makefile
+func (g *pyGen) bazel() bool {
+	return g.cfg.BuildSystem == "bazel" && g.mode != ModeExe
+}
+
+// bazelList returns the Starlark list of the strings, one per line, of the
+// indentation of the line it starts
+func bazelList(items []string, indent string) string {
+	if len(items) == 0 {
+		return "[]"
+	}
+	var b strings.Builder
+	b.WriteString("[\n")
+	for _, it := range items {
+		fmt.Fprintf(&b, "%s    %q,\n", indent, it)
+	}
+	b.WriteString(indent + "]")
+	return b.String()
+}
+
+// bazelFlags returns the Starlark list of the flags, unquoted from the
+// #cgo lines
+func bazelFlags(flags string) string {
+	var items []string
+	for _, f := range strings.Fields(flags) {
+		items = append(items, strings.Trim(f, `"`))
+	}
+	return bazelList(items, "")
+}
+
+// bazelGoLabel returns the label of the go package of the import path, by
+// the gazelle naming of go_repository, e.g., @com_github_go_python_gopy//gopyh,
+// taking the first 3 elements of the path as the module of the repository
+func bazelGoLabel(path string) string {
+	elems := strings.Split(path, "/")
+	n := len(elems)
+	if n > 3 {
+		n = 3
+	}
+	host := strings.Split(elems[0], ".")
+	for i, j := 0, len(host)-1; i < j; i, j = i+1, j-1 {
+		host[i], host[j] = host[j], host[i]
+	}
+	repo := strings.Join(append(host, elems[1:n]...), "_")
+	repo = strings.NewReplacer(".", "_", "-", "_").Replace(repo)
+	if n == len(elems) {
+		return "@" + repo + "//:" + elems[n-1]
+	}
+	return "@" + repo + "//" + strings.Join(elems[n:], "/")
+}
+
+// bazelGoDeps returns the labels of the go packages imported by the cgo
+// wrappers, other than those of the standard library
+func bazelGoDeps() []string {
+	paths := []string{"github.com/go-python/gopy/gopyh"}
+	for p := range current.imports {
+		if strings.Contains(strings.Split(p, "/")[0], ".") {
+			paths = append(paths, p)
+		}
+	}
+	sort.Strings(paths[1:])
+	var deps []string
+	for _, p := range paths {
+		deps = append(deps, bazelGoLabel(p))
+	}
+	return deps
+}
+
+// genBazelBuild generates the BUILD.bazel of the bindings, of the gen
+// command gencmd and the python configuration pycfg
+func (g *pyGen) genBazelBuild(gencmd string, pycfg PyConfig) {
+	winhack := ""
+	if WindowsOS {
+		// windows-only hack here to fix pybindgen declaration of PyInit
+		winhack = ` + " && sed -i 's/ PyInit_/ __declspec(dllexport) PyInit_/g' $@"`
+	}
+	srcs := []string{":" + g.cfg.Name + "_goimports"}
+	if g.cfg.ExtraGo != "" {
+		files, _ := extraGoFiles(g.cfg.ExtraGo) // errors reported by genExtraGo
+		for _, fn := range files {
+			srcs = append(srcs, filepath.Base(fn))
+		}
+	}
+	g.makefile.Printf(bazelTemplate, g.cfg.Name, g.cfg.Cmd, gencmd, g.cfg.VM, g.libext, bazelFlags(pycfg.CFlags), bazelFlags(pycfg.LdFlags), bazelList(srcs, "    "), bazelList(bazelGoDeps(), "    "), winhack)
+}
@@ -0,0 +1,198 @@
+// Copyright 2024 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package bind
+
+import (
+	"fmt"
+	"go/types"
+	"strings"
+)
+
+// The string params of a func or method whose doc comment has a
+// //gopy:borrow line, listing their names, or all of them if none, are
+// passed as views of the data of the python args, without copying it, e.g.,
+// for the parsers and hashes of large payloads: the args are python str,
+// viewed in UTF-8 (without any copy for ASCII strings), or objects of the
+// buffer protocol, e.g., bytes, bytearray, memoryview or mmap, held until
+// the call returns.  The Go strings are valid only during the call: the
+// Go code must not retain them, or parts of them, e.g., in a struct field
+// or a map, and a goroutine of goRun gets a copy.  The python code must
+// not change a mutable buffer during the call either, from another thread,
+// which the BorrowChecks option, for debugging, checks, raising a
+// RuntimeError when the data has changed at the end of the call.
+
+const (
+	// C code of the borrowed string params
+	borrowPreC = `
+static inline const char* gopy_borrow(PyObject* o, Py_ssize_t* n, Py_buffer* view) { // macro
+	view->obj = NULL;
+	if (PyUnicode_Check(o)) {
+		return PyUnicode_AsUTF8AndSize(o, n);
+	}
+	if (PyObject_GetBuffer(o, view, PyBUF_SIMPLE) < 0) {
+		return NULL;
+	}
+	*n = view->len;
+	return (const char*)view->buf;
+}
+`
+
+	// go code of the borrowed string params, of the BorrowChecks option
+	borrowGo = `
+// ---- borrowed string params, see //gopy:borrow ---
+
+// gopyBorrowChecks checks that the borrowed buffers do not change during the calls
+const gopyBorrowChecks = %[1]v
+
+// gopyBorrowed is a Go string viewing the data of a python str or buffer,
+// valid only during the call it is passed to
+type gopyBorrowed struct {
+	s    string
+	view *C.Py_buffer // of the buffers, released at the end of the call
+	orig string       // copy of the data of the buffers, with gopyBorrowChecks
+}
+
+// gopyBorrow returns the string viewing the data of the python str, in
+// UTF-8, or object of the buffer protocol, or nil with a python exception
+// set if it is neither, with the GIL held
+func gopyBorrow(o *C.PyObject) *gopyBorrowed {
+	b := &gopyBorrowed{view: (*C.Py_buffer)(C.calloc(1, C.sizeof_Py_buffer))}
+	var n C.Py_ssize_t
+	p := C.gopy_borrow(o, &n, b.view)
+	if p == nil {
+		C.free(unsafe.Pointer(b.view))
+		return nil
+	}
+	if n > 0 {
+		bs := unsafe.Slice((*byte)(unsafe.Pointer(p)), int(n))
+		b.s = *(*string)(unsafe.Pointer(&bs))
+		if gopyBorrowChecks && b.view.obj != nil {
+			b.orig = string(bs)
+		}
+	}
+	return b
+}
+
+// keep replaces the string with a copy of it, e.g., for a goroutine outliving the call
+func (b *gopyBorrowed) keep() {
+	b.s = string([]byte(b.s))
+}
+
+// release releases the python buffer, at the end of the call of the
+// param of the name, raising a RuntimeError, with gopyBorrowChecks, if
+// its data has changed during the call
+func (b *gopyBorrowed) release(name string) {
+	_gstate := C.PyGILState_Ensure()
+	defer C.PyGILState_Release(_gstate)
+	if b.view.obj != nil {
+		if gopyBorrowChecks && b.s != b.orig && C.PyErr_Occurred() == nil {
+			estr := C.CString("gopy: " + name + ": borrowed buffer changed during the call")
+			C.PyErr_SetString(C.PyExc_RuntimeError, estr)
+			C.free(unsafe.Pointer(estr))
+		}
+		C.PyBuffer_Release(b.view)
+	}
+	C.free(unsafe.Pointer(b.view))
+}
+`
+
+	// borrowCost is the conversion cost of the borrowed string params, in
+	// the docstrings of their functions
+	borrowCost = "borrowed from str in UTF-8 or bytes-like, without copy, O(1), valid only during the call"
+)
+
+// borrowedArgs returns the indexes of the string params of the function
+// of the name, Func or Type.Method, borrowed by a //gopy:borrow directive,
+// if any
+func (p *Package) borrowedArgs(name string, sig *types.Signature) map[int]bool {
+	if borrow, ok := p.borrows[sig]; ok {
+		return borrow
+	}
+	arg, ok := p.directive(name, "borrow")
+	if !ok {
+		return nil
+	}
+	names := make(map[string]bool)
+	for _, nm := range strings.Fields(arg) {
+		names[nm] = true
+	}
+	all := len(names) == 0
+	borrow := make(map[int]bool)
+	params := sig.Params()
+	for i := 0; i < params.Len(); i++ {
+		v := params.At(i)
+		if !all && !names[v.Name()] {
+			continue
+		}
+		delete(names, v.Name())
+		if !types.Identical(v.Type(), types.Typ[types.String]) || (sig.Variadic() && i == params.Len()-1) {
+			if !all && !NoWarn {
+				fmt.Printf("gopy: warning: ignoring %sborrow directive of param %s of %s.%s: not a string\n", directivePrefix, v.Name(), p.Name(), name)
+			}
+			continue
+		}
+		borrow[i] = true
+	}
+	for nm := range names {
+		if !NoWarn {
+			fmt.Printf("gopy: warning: ignoring %sborrow directive of %s.%s: no param %s\n", directivePrefix, p.Name(), name, nm)
+		}
+	}
+	if p.borrows == nil {
+		p.borrows = make(map[*types.Signature]map[int]bool)
+	}
+	p.borrows[sig] = borrow
+	return borrow
+}
+
+// usesBorrow returns whether any function has borrowed string params
+func usesBorrow() bool {
+	for _, p := range Packages {
+		for _, borrow := range p.borrows {
+			if len(borrow) > 0 {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// genBorrowGo generates the go code of the borrowed string params
+func (g *pyGen) genBorrowGo() {
+	if !usesBorrow() {
+		return
+	}
+	g.gofile.Printf(borrowGo, g.cfg.BorrowChecks)
+}
+
+// genBorrowArgs generates the views of the borrowed string params of the
+// function, returning the zero value if an arg is neither a str nor a
+// buffer, and releasing them at the end of the call, with the GIL held
+func (g *pyGen) genBorrowArgs(fsym *Func) {
+	args := fsym.sig.Params()
+	for i, arg := range args {
+		if !fsym.borrow[i] {
+			continue
+		}
+		gnm := goSafeArg(arg.Name(), i)
+		g.gofile.Printf("_borrow_%s := gopyBorrow(%s)\n", gnm, gnm)
+		g.gofile.Printf("if _borrow_%s == nil {\n", gnm)
+		g.gofile.Indent()
+		g.genZeroReturn(fsym.sig.Results())
+		g.gofile.Outdent()
+		g.gofile.Printf("}\n")
+		g.gofile.Printf("defer _borrow_%s.release(%q)\n", gnm, qualName(fsym.obj)+" arg "+pySafeArg(arg.Name(), i))
+	}
+}
+
+// genBorrowKeep generates the copies of the borrowed string params of the
+// function, before its call in a goroutine
+func (g *pyGen) genBorrowKeep(fsym *Func) {
+	for i, arg := range fsym.sig.Params() {
+		if fsym.borrow[i] {
+			g.gofile.Printf("_borrow_%s.keep()\n", goSafeArg(arg.Name(), i))
+		}
+	}
+}
@@ -0,0 +1,86 @@
+// Copyright 2024 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package bind
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+)
+
+// The generated go module checks, when imported, that the _<name> extension
+// module was built from the Go code and pybindgen stubs generated along with
+// the python wrappers, by comparing the build id compiled into the extension
+// with the one of the wrappers, raising an ImportError asking to rebuild the
+// bindings when they differ, e.g., after regenerating the bindings without
+// rebuilding the extension, or copying a stale extension over, instead of the
+// undefined behavior of calling the functions of another generation.  The Go
+// code of the bindings is linked into the extension, so the build id of the
+// extension is that of its Go code.  The build id is the content hash of the
+// Go code and pybindgen stubs generated, so that the output stays identical
+// when nothing changed.
+
+const (
+	// buildIDKey is replaced by the build id in the generated files, once
+	// the Go code and pybindgen stubs are complete
+	buildIDKey = "@GOPY_BUILD_ID@"
+
+	// go code returning the build id of the extension
+	buildIDGo = `
+// ---- build id of the bindings, checked by the go module when imported ---
+
+//export GoPyBuildID
+func GoPyBuildID() *C.char {
+	return C.CString("` + buildIDKey + `")
+}
+`
+
+	// pybindgen stub for buildIDGo
+	buildIDPyBuild = `add_checked_string_function(mod, 'GoPyBuildID', retval('char*'), [])
+`
+
+	// python check of the build id of the extension
+	// 1 = package name
+	buildIDPyWrap = `
+# ---- build id of the bindings, checked against the one of the extension ---
+_gopy_build_id = "` + buildIDKey + `"
+_gopy_ext_build_id = _%[1]s.GoPyBuildID() if hasattr(_%[1]s, 'GoPyBuildID') else 'none'
+if _gopy_ext_build_id != _gopy_build_id:
+	raise ImportError("the _%[1]s extension module at %%s was built from another generation of the bindings "
+		"than the python wrappers of %%s (build id %%s, not %%s): rebuild the bindings, e.g., with make build" %%
+		(getattr(_%[1]s, '__file__', '_%[1]s'), __name__, _gopy_ext_build_id, _gopy_build_id))
+
+`
+)
+
+// genBuildIDGo generates the go code and pybindgen stub returning the build
+// id of the extension
+func (g *pyGen) genBuildIDGo() {
+	g.gofile.Printf("%s", buildIDGo)
+	g.pybuild.Printf("%s", buildIDPyBuild)
+}
+
+// genBuildIDPyWrap generates the python check of the build id of the
+// extension, when importing the go module
+func (g *pyGen) genBuildIDPyWrap() {
+	g.pywrap.Printf(buildIDPyWrap, g.cfg.Name)
+}
+
+// buildID returns the build id of the bindings, the content hash of the Go
+// code and pybindgen stubs generated
+func (g *pyGen) buildID() string {
+	h := sha256.New()
+	h.Write(g.gofile.buf.Bytes())
+	h.Write([]byte{0})
+	h.Write(g.pybuild.buf.Bytes())
+	return hex.EncodeToString(h.Sum(nil))[:16]
+}
+
+// setBuildID replaces the build id key by the build id in the printers
+func setBuildID(id string, prs ...*printer) {
+	for _, pr := range prs {
+		pr.buf = bytes.NewBuffer(bytes.Replace(pr.buf.Bytes(), []byte(buildIDKey), []byte(id), -1))
+	}
+}
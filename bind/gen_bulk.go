@@ -0,0 +1,282 @@
+// Copyright 2024 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package bind
+
+import (
+	"fmt"
+	"go/types"
+	"strings"
+)
+
+// The struct wrappers have obj.update(**fields), setting the fields of the
+// keyword args, and obj.fields(), returning a dict of the values of the
+// fields, by name, as for populating config structs from python.  The fields
+// of the basic types, bools, ints, floats and strings, are set and got all at
+// once, by a single call to Go, passing them as a flat tuple of (index, value)
+// pairs, and returning them as a tuple, with the others set and got one by
+// one, by their properties.  update sets none of the basic fields if any of
+// their values is invalid, raising the exception of the first one, and raises
+// TypeError for the names of no field.  The methods are not generated if the
+// struct has members of the same names.
+
+const (
+	// go code of the conversions of the python values of the basic fields
+	pyConvGo = `
+// ---- conversions of the python values of the basic fields ---
+
+// gopyStrGoToPy converts a Go string to a python str
+func gopyStrGoToPy(s string) *C.PyObject {
+	cs := C.CString(s)
+	defer C.free(unsafe.Pointer(cs))
+	return C.PyUnicode_FromStringAndSize(cs, C.Py_ssize_t(len(s)))
+}
+
+// gopyStrPyToGo converts a python str to a Go string, leaving the TypeError
+// of other values set
+func gopyStrPyToGo(o *C.PyObject) string {
+	var n C.Py_ssize_t
+	cs := C.PyUnicode_AsUTF8AndSize(o, &n)
+	if cs == nil {
+		return ""
+	}
+	return C.GoStringN(cs, C.int(n))
+}
+
+// gopyIntPyToGo converts a python int to a Go int of the bits, setting an
+// OverflowError naming the field if it does not fit, and leaving the
+// TypeError of other values set
+func gopyIntPyToGo(o *C.PyObject, bits uint, gotype, name string) int64 {
+	v := int64(C.PyLong_AsLongLong(o))
+	if C.PyErr_Occurred() != nil {
+		return 0
+	}
+	if bits < 64 && (v < -1<<(bits-1) || v >= 1<<(bits-1)) {
+		estr := C.CString(fmt.Sprintf("%s: %d overflows Go %s", name, v, gotype))
+		C.PyErr_SetString(C.PyExc_OverflowError, estr)
+		C.free(unsafe.Pointer(estr))
+		return 0
+	}
+	return v
+}
+
+// gopyUintPyToGo converts a python int to a Go unsigned int of the bits,
+// setting an OverflowError naming the field if it does not fit, and leaving
+// the TypeError of other values set
+func gopyUintPyToGo(o *C.PyObject, bits uint, gotype, name string) uint64 {
+	v := uint64(C.PyLong_AsUnsignedLongLong(o))
+	if C.PyErr_Occurred() != nil {
+		return 0
+	}
+	if bits < 64 && v >= 1<<bits {
+		estr := C.CString(fmt.Sprintf("%s: %d overflows Go %s", name, v, gotype))
+		C.PyErr_SetString(C.PyExc_OverflowError, estr)
+		C.free(unsafe.Pointer(estr))
+		return 0
+	}
+	return v
+}
+`
+)
+
+// genBulkGo generates the go conversions of the python values of the basic
+// fields, of the bulk field access and of the dataclasses
+func (g *pyGen) genBulkGo() {
+	g.gofile.Printf("%s", pyConvGo)
+}
+
+// isBulkField returns whether the field symbol is set and got by the single
+// calls to Go of update and fields: a basic type other than runes and
+// durations, and float32 with lossy checks, which are checked by python
+func (g *pyGen) isBulkField(fsym *symbol) bool {
+	if fsym == nil || !fsym.isBasic() || isRune(fsym) || isDuration(fsym) {
+		return false
+	}
+	switch k := basicKind(fsym); {
+	case k == types.Bool || k == types.String || k == types.Float64:
+		return true
+	case k == types.Float32:
+		return !g.lossyChecks()
+	case types.Int <= k && k <= types.Uintptr:
+		return true
+	}
+	return false
+}
+
+// hasStructMember returns whether the struct has a bound field or method of
+// the python name
+func (g *pyGen) hasStructMember(s *Struct, name string) bool {
+	typ := s.Struct()
+	for i := 0; i < typ.NumFields(); i++ {
+		if _, err := isPyCompatField(typ.Field(i)); err == nil && g.pyFieldName(s, i) == name {
+			return true
+		}
+	}
+	for _, m := range s.meths {
+		if fn, ok := g.pyFuncName(m); ok && fn == name {
+			return true
+		}
+	}
+	return false
+}
+
+// genStructBulk generates the update and fields methods of the struct, and
+// the go functions setting and getting its basic fields at once
+func (g *pyGen) genStructBulk(s *Struct) {
+	var (
+		names []string       // python names of the bound fields
+		bulk  []*types.Var   // basic fields
+		bidx  map[string]int // indexes in bulk, by python name
+	)
+	bidx = make(map[string]int)
+	typ := s.Struct()
+	for i := 0; i < typ.NumFields(); i++ {
+		f := typ.Field(i)
+		if _, err := isPyCompatField(f); err != nil || current.symtype(f.Type()) == nil {
+			continue
+		}
+		nm := g.pyFieldName(s, i)
+		names = append(names, nm)
+		if g.isBulkField(current.symtype(f.Type())) {
+			bidx[nm] = len(bulk)
+			bulk = append(bulk, f)
+		}
+	}
+	if len(names) == 0 {
+		return
+	}
+	if !g.hasStructMember(s, "update") && !s.pkg.isFrozen(s.obj.Name()) {
+		g.genStructUpdate(s, names, bulk, bidx)
+	}
+	if !g.hasStructMember(s, "fields") {
+		g.genStructFields(s, names, bulk, bidx)
+	}
+}
+
+// genStructUpdate generates the update method of the struct, of the python
+// names of its fields, and the go function setting its bulk fields
+func (g *pyGen) genStructUpdate(s *Struct, names []string, bulk []*types.Var, bidx map[string]int) {
+	cgoFn := s.ID() + "_GoPyUpdate"
+	var (
+		idxs   []string
+		others []string
+	)
+	for _, nm := range names {
+		if i, ok := bidx[nm]; ok {
+			idxs = append(idxs, fmt.Sprintf("%q: %d", nm, i))
+		} else {
+			others = append(others, fmt.Sprintf("%q", nm))
+		}
+	}
+	g.genThreadGuard(s.sym, "update")
+	g.pywrap.Printf("def update(self, **fields):\n")
+	g.pywrap.Indent()
+	g.pywrap.Printf(`"""update sets the fields of the keyword args, with those of basic types set at once by a single call to Go.
+It raises TypeError for the names of no field"""
+`)
+	g.pywrap.Printf("bulk = {%s}\n", strings.Join(idxs, ", "))
+	g.pywrap.Printf("packed = []\n")
+	g.pywrap.Printf("for name, value in fields.items():\n")
+	g.pywrap.Indent()
+	g.pywrap.Printf("i = bulk.get(name)\n")
+	g.pywrap.Printf("if i is not None:\n")
+	g.pywrap.Indent()
+	g.pywrap.Printf("packed += (i, value)\n")
+	g.pywrap.Outdent()
+	if len(others) > 0 {
+		g.pywrap.Printf("elif name in %s:\n", pyTuple(others))
+		g.pywrap.Indent()
+		g.pywrap.Printf("setattr(self, name, value)\n")
+		g.pywrap.Outdent()
+	}
+	g.pywrap.Printf("else:\n")
+	g.pywrap.Indent()
+	g.pywrap.Printf("raise TypeError(\"update: %s has no field %%r\" %% (name,))\n", s.GoName())
+	g.pywrap.Outdent()
+	g.pywrap.Outdent()
+	if len(bulk) > 0 {
+		g.pywrap.Printf("if packed:\n")
+		g.pywrap.Indent()
+		g.pywrap.Printf("_%s.%s(self.handle, tuple(packed))\n", g.cfg.Name, cgoFn)
+		g.pywrap.Outdent()
+	}
+	g.pywrap.Outdent()
+
+	if len(bulk) == 0 {
+		return
+	}
+	g.gofile.Printf("\n//export %s\n", cgoFn)
+	g.gofile.Printf("func %s(handle CGoHandle, packed *C.PyObject) {\n", cgoFn)
+	g.gofile.Indent()
+	g.gofile.Printf("op := ptrFromHandle_%s(handle)\n", s.ID())
+	g.gofile.Printf("var _set [%d]bool\n", len(bulk))
+	for i, f := range bulk {
+		g.gofile.Printf("var _v%d %s\n", i, current.typeGoName(f.Type()))
+	}
+	g.gofile.Printf("n := int(C.PyTuple_Size(packed))\n")
+	g.gofile.Printf("for i := 0; i+1 < n; i += 2 {\n")
+	g.gofile.Indent()
+	g.gofile.Printf("_o := C.PyTuple_GetItem(packed, C.Py_ssize_t(i+1))\n")
+	g.gofile.Printf("switch C.PyLong_AsLong(C.PyTuple_GetItem(packed, C.Py_ssize_t(i))) {\n")
+	for i, f := range bulk {
+		ft := f.Type()
+		g.gofile.Printf("case %d:\n", i)
+		g.gofile.Indent()
+		g.gofile.Printf("_v%d = %s\n", i, dataclassFieldPyToGo(current.symtype(ft), current.typeGoName(ft), "_o", s.Obj().Name()+"."+f.Name()))
+		g.gofile.Printf("_set[%d] = true\n", i)
+		g.gofile.Outdent()
+	}
+	g.gofile.Printf("}\n")
+	g.gofile.Printf("if C.PyErr_Occurred() != nil {\n\treturn\n}\n")
+	g.gofile.Outdent()
+	g.gofile.Printf("}\n")
+	for i, f := range bulk {
+		g.gofile.Printf("if _set[%d] {\n\top.%s = _v%d\n}\n", i, f.Name(), i)
+	}
+	g.gofile.Outdent()
+	g.gofile.Printf("}\n")
+
+	g.pybuild.Printf("add_checked_function(mod, '%s', None, [param('%s', 'handle'), param('PyObject*', 'packed', transfer_ownership=False)])\n", cgoFn, PyHandle)
+}
+
+// genStructFields generates the fields method of the struct, of the python
+// names of its fields, and the go function getting its bulk fields
+func (g *pyGen) genStructFields(s *Struct, names []string, bulk []*types.Var, bidx map[string]int) {
+	cgoFn := s.ID() + "_GoPyFields"
+	items := make([]string, len(names))
+	for j, nm := range names {
+		if i, ok := bidx[nm]; ok {
+			items[j] = fmt.Sprintf("%q: v[%d]", nm, i)
+		} else {
+			items[j] = fmt.Sprintf("%q: self.%s", nm, nm)
+		}
+	}
+	g.genThreadGuard(s.sym, "fields")
+	g.pywrap.Printf("def fields(self):\n")
+	g.pywrap.Indent()
+	g.pywrap.Printf(`"""fields returns a dict of the values of the fields, by name, with those of basic types got at once by a single call to Go"""
+`)
+	if len(bulk) > 0 {
+		g.pywrap.Printf("v = _%s.%s(self.handle)\n", g.cfg.Name, cgoFn)
+	}
+	g.pywrap.Printf("return {%s}\n", strings.Join(items, ", "))
+	g.pywrap.Outdent()
+
+	if len(bulk) == 0 {
+		return
+	}
+	g.gofile.Printf("\n//export %s\n", cgoFn)
+	g.gofile.Printf("func %s(handle CGoHandle) *C.PyObject {\n", cgoFn)
+	g.gofile.Indent()
+	g.gofile.Printf("op := ptrFromHandle_%s(handle)\n", s.ID())
+	g.gofile.Printf("_t := C.PyTuple_New(%d)\n", len(bulk))
+	for i, f := range bulk {
+		g.gofile.Printf("C.PyTuple_SetItem(_t, %d, %s)\n", i, dataclassFieldGoToPy(current.symtype(f.Type()), "op."+f.Name()))
+	}
+	g.gofile.Printf("return _t\n")
+	g.gofile.Outdent()
+	g.gofile.Printf("}\n")
+
+	g.pybuild.Printf("add_checked_function(mod, '%s', retval('PyObject*', caller_owns_return=True), [param('%s', 'handle')])\n", cgoFn, PyHandle)
+}
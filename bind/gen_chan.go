@@ -0,0 +1,352 @@
+// Copyright 2024 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package bind
+
+import (
+	"fmt"
+	"go/types"
+	"strings"
+)
+
+// Channel types, e.g., chan T, <-chan T or chan<- T, are bound as python
+// classes sharing the Go channel by handle, with send, recv and close
+// methods as allowed by the direction of the channel, and iterating over
+// the received values until the channel is closed, like a Go range loop.
+// Calling the class with an optional buffer capacity makes a new channel.
+// Sending and receiving release the GIL while blocked, and the send_async
+// and recv_async methods and async iteration await them in asyncio, see
+// gen_async.go.
+
+// addChanType adds a symbol for a channel type, passed by handle like the
+// other container types
+func (sym *symtab) addChanType(pkg *types.Package, obj types.Object, t types.Type, kind symkind, id, n string) error {
+	fn := sym.fullTypeString(t)
+	typ := t.Underlying().(*types.Chan)
+	kind |= skChan
+	// add our type first before adding the elements -- prevents loops!
+	s := &symbol{
+		gopkg:   pkg,
+		goobj:   obj,
+		gotyp:   t,
+		kind:    kind,
+		id:      id,
+		goname:  n,
+		cgoname: "CGoHandle",
+		cpyname: PyHandle,
+		pysig:   "object",
+		go2py:   "handleFromPtr_" + id,
+		py2go:   "deptrFromHandle_" + id,
+		zval:    "nil",
+	}
+	sym.syms[fn] = s
+	elt := typ.Elem()
+	elsym, err := sym.addTypeIfNew(elt)
+	if err == nil && (elsym.isSignature() || elsym.isChan() || elsym.isIter() || elsym.goname == "interface{}") {
+		err = fmt.Errorf("gopy: channel value type not supported: %q", elsym.goname)
+	}
+	if err == nil && typ.Dir() != types.SendRecv {
+		// add the bidirectional type, to make the channels passed as
+		// directional ones from python
+		_, err = sym.addTypeIfNew(types.NewChan(types.SendRecv, elt))
+	}
+	if err != nil {
+		delete(sym.syms, fn)
+		return err
+	}
+	return nil
+}
+
+// genChan generates the python class of the channel type and the go
+// functions it calls
+func (g *pyGen) genChan(sym *symbol, extTypes, pyWrapOnly bool) {
+	typ := sym.GoType().Underlying().(*types.Chan)
+	esym := current.symtype(typ.Elem())
+	canSend := typ.Dir() != types.RecvOnly
+	canRecv := typ.Dir() != types.SendOnly
+
+	pkgname := sym.gopkg.Name()
+	chNm := sym.id
+	qNm := g.cfg.Name + "." + chNm // this is only for referring to the _ .go package!
+	pysnm := chNm
+	if sym.isNamed() {
+		pysnm = strings.TrimPrefix(pysnm, pkgname+"_")
+	}
+
+	gocl := "go."
+	if g.pkg == goPackage {
+		gocl = ""
+	}
+
+	if !extTypes || pyWrapOnly {
+		g.pywrap.Printf(`
+# Python type for channel %[3]s
+class %[1]s(%[4]s):
+	""%[2]q""
+`,
+			pysnm,
+			sym.doc,
+			sym.goname,
+			g.mixinBases(pysnm, gocl+"GoClass"),
+		)
+		g.pywrap.Indent()
+
+		g.pywrap.Printf("def __init__(self, *args, **kwargs):\n")
+		g.pywrap.Indent()
+		g.pywrap.Printf(`"""
+handle=A Go-side object is always initialized with an explicit handle=arg
+otherwise the optional parameter is the buffer capacity of a new channel
+"""
+`)
+		g.pywrap.Printf("if len(kwargs) == 1 and 'handle' in kwargs:\n")
+		g.pywrap.Indent()
+		g.pywrap.Printf("self.handle = kwargs['handle']\n")
+		g.pywrap.Printf("_%s.IncRef(self.handle)\n", g.cfg.Name)
+		g.pywrap.Outdent()
+		g.pywrap.Printf("elif len(args) == 1 and isinstance(args[0], %sGoClass):\n", gocl)
+		g.pywrap.Indent()
+		g.pywrap.Printf("self.handle = args[0].handle\n")
+		g.pywrap.Printf("_%s.IncRef(self.handle)\n", g.cfg.Name)
+		g.pywrap.Outdent()
+		g.pywrap.Printf("else:\n")
+		g.pywrap.Indent()
+		g.pywrap.Printf("self.handle = _%s_CTor(args[0] if len(args) > 0 else 0)\n", qNm)
+		g.pywrap.Printf("_%s.IncRef(self.handle)\n", g.cfg.Name)
+		g.pywrap.Outdent()
+		g.pywrap.Outdent()
+
+		g.pywrap.Printf("def __del__(self):\n")
+		g.pywrap.Indent()
+		g.pywrap.Printf("_%s.DecRef(self.handle)\n", g.cfg.Name)
+		g.pywrap.Outdent()
+
+		g.pywrap.Printf("@classmethod\n")
+		g.pywrap.Printf("def zero(cls):\n")
+		g.pywrap.Indent()
+		g.pywrap.Printf(`"""zero returns the Go zero value of the channel, nil being None"""
+`)
+		g.pywrap.Printf("return None\n")
+		g.pywrap.Outdent()
+
+		g.pywrap.Printf("def __str__(self):\n")
+		g.pywrap.Indent()
+		g.pywrap.Printf("return '%s.%s len: ' + str(len(self)) + ' cap: ' + str(self.cap()) + ' handle: ' + str(self.handle)\n", pkgname, pysnm)
+		g.pywrap.Outdent()
+
+		g.pywrap.Printf("def __repr__(self):\n")
+		g.pywrap.Indent()
+		g.pywrap.Printf("return '%s.%s(cap=' + str(self.cap()) + ')'\n", pkgname, pysnm)
+		g.pywrap.Outdent()
+
+		g.pywrap.Printf("def __len__(self):\n")
+		g.pywrap.Indent()
+		g.pywrap.Printf(`"""number of values buffered in the channel"""
+`)
+		g.pywrap.Printf("return _%s_len(self.handle)\n", qNm)
+		g.pywrap.Outdent()
+
+		g.pywrap.Printf("def cap(self):\n")
+		g.pywrap.Indent()
+		g.pywrap.Printf(`"""buffer capacity of the channel"""
+`)
+		g.pywrap.Printf("return _%s_cap(self.handle)\n", qNm)
+		g.pywrap.Outdent()
+
+		if canSend {
+			g.pywrap.Printf("def send(self, value):\n")
+			g.pywrap.Indent()
+			g.pywrap.Printf(`"""send sends the value on the channel, blocking until it is received or buffered"""
+`)
+			g.genContainerConv("value", esym, sym.gopkg)
+			g.pywrap.Printf("_%s_send(self.handle, %s)\n", qNm, pyElemArg("value", esym))
+			g.pywrap.Outdent()
+
+			g.pywrap.Printf("def close(self):\n")
+			g.pywrap.Indent()
+			g.pywrap.Printf(`"""close closes the channel, ending the iterations over its values"""
+`)
+			g.pywrap.Printf("_%s_close(self.handle)\n", qNm)
+			g.pywrap.Outdent()
+		}
+
+		if canRecv {
+			g.pywrap.Printf("def recv(self):\n")
+			g.pywrap.Indent()
+			g.pywrap.Printf(`"""recv returns a (value, ok) tuple of the value received from the channel, blocking until there is one,
+ok being False, with a zero value, if the channel is closed"""
+`)
+			g.pywrap.Printf("r = _%s_recv(self.handle)\n", qNm)
+			g.pywrap.Printf("_%s.IncRef(r)\n", g.cfg.Name)
+			g.pywrap.Printf("try:\n")
+			g.pywrap.Indent()
+			switch {
+			case esym.isPointer():
+				// nil pointers are None
+				g.pywrap.Printf("h = _%s_recv_value(r)\n", qNm)
+				g.pywrap.Printf("return (None if %s else %s(handle=h), _%s_recv_ok(r))\n", pyNilHandle("h"), esym.pyPkgId(sym.gopkg, g.pkg), qNm)
+			case esym.hasHandle():
+				g.pywrap.Printf("return (%s(handle=_%s_recv_value(r)), _%s_recv_ok(r))\n", esym.pyPkgId(sym.gopkg, g.pkg), qNm, qNm)
+			default:
+				g.pywrap.Printf("return (_%s_recv_value(r), _%s_recv_ok(r))\n", qNm, qNm)
+			}
+			g.pywrap.Outdent()
+			g.pywrap.Printf("finally:\n")
+			g.pywrap.Indent()
+			g.pywrap.Printf("_%s.DecRef(r)\n", g.cfg.Name)
+			g.pywrap.Outdent()
+			g.pywrap.Outdent()
+
+			g.pywrap.Printf("def __iter__(self):\n")
+			g.pywrap.Indent()
+			g.pywrap.Println("return self")
+			g.pywrap.Outdent()
+
+			if g.lang == 2 {
+				g.pywrap.Printf("def next(self):\n")
+			} else {
+				g.pywrap.Printf("def __next__(self):\n")
+			}
+			g.pywrap.Indent()
+			g.pywrap.Println("v, ok = self.recv()")
+			g.pywrap.Println("if not ok:")
+			g.pywrap.Indent()
+			g.pywrap.Println("raise StopIteration")
+			g.pywrap.Outdent()
+			g.pywrap.Println("return v")
+			g.pywrap.Outdent()
+		}
+		g.genChanAsync(canSend, canRecv)
+		g.pywrap.Outdent()
+	}
+
+	if extTypes && pyWrapOnly {
+		return
+	}
+
+	// go ctor
+	ctNm := chNm + "_CTor"
+	g.gofile.Printf("\n// --- wrapping channel: %v ---\n", sym.goname)
+	g.gofile.Printf("//export %s\n", ctNm)
+	g.gofile.Printf("func %s(_cap int) CGoHandle {\n", ctNm)
+	g.gofile.Indent()
+	g.gofile.Printf("ch := make(%s, _cap)\n", sym.goname)
+	g.gofile.Printf("return CGoHandle(handleFromPtr_%s(&ch))\n", chNm)
+	g.gofile.Outdent()
+	g.gofile.Printf("}\n\n")
+
+	g.pybuild.Printf("mod.add_function('%s', retval('%s'), [param('int', 'cap')])\n", ctNm, PyHandle)
+
+	g.gofile.Printf("//export %s_len\n", chNm)
+	g.gofile.Printf("func %s_len(handle CGoHandle) int {\n", chNm)
+	g.gofile.Indent()
+	g.gofile.Printf("return len(deptrFromHandle_%s(handle))\n", chNm)
+	g.gofile.Outdent()
+	g.gofile.Printf("}\n\n")
+
+	g.pybuild.Printf("mod.add_function('%s_len', retval('int'), [param('%s', 'handle')])\n", chNm, PyHandle)
+
+	g.gofile.Printf("//export %s_cap\n", chNm)
+	g.gofile.Printf("func %s_cap(handle CGoHandle) int {\n", chNm)
+	g.gofile.Indent()
+	g.gofile.Printf("return cap(deptrFromHandle_%s(handle))\n", chNm)
+	g.gofile.Outdent()
+	g.gofile.Printf("}\n\n")
+
+	g.pybuild.Printf("mod.add_function('%s_cap', retval('int'), [param('%s', 'handle')])\n", chNm, PyHandle)
+
+	if canSend {
+		g.gofile.Printf("//export %s_send\n", chNm)
+		g.gofile.Printf("func %s_send(handle CGoHandle, _vl %s) {\n", chNm, esym.cgoname)
+		g.gofile.Indent()
+		g.gofile.Printf("ch := deptrFromHandle_%s(handle)\n", chNm)
+		if esym.py2go != "" {
+			g.gofile.Printf("vl := %s(_vl)%s\n", esym.py2go, esym.py2goParenEx)
+		} else {
+			g.gofile.Printf("vl := _vl\n")
+		}
+		g.gofile.Printf("_saved_thread := C.PyEval_SaveThread()\n")
+		g.gofile.Printf("defer C.PyEval_RestoreThread(_saved_thread)\n")
+		g.gofile.Printf("ch <- vl\n")
+		g.gofile.Outdent()
+		g.gofile.Printf("}\n\n")
+
+		g.pybuild.Printf("mod.add_function('%s_send', None, [param('%s', 'handle'), param('%s', 'value'%s)])\n", chNm, PyHandle, esym.cpyname, esym.pyParamOwn())
+
+		g.gofile.Printf("//export %s_close\n", chNm)
+		g.gofile.Printf("func %s_close(handle CGoHandle) {\n", chNm)
+		g.gofile.Indent()
+		g.gofile.Printf("close(deptrFromHandle_%s(handle))\n", chNm)
+		g.gofile.Outdent()
+		g.gofile.Printf("}\n\n")
+
+		g.pybuild.Printf("mod.add_function('%s_close', None, [param('%s', 'handle')])\n", chNm, PyHandle)
+	}
+
+	if canRecv {
+		rcvNm := "gopyRecv_" + chNm
+		g.gofile.Printf("// %s is a value received from %s, with its ok flag\n", rcvNm, sym.goname)
+		g.gofile.Printf("type %s struct {\n", rcvNm)
+		g.gofile.Indent()
+		g.gofile.Printf("v  %s\n", esym.goname)
+		g.gofile.Printf("ok bool\n")
+		g.gofile.Outdent()
+		g.gofile.Printf("}\n\n")
+
+		g.gofile.Printf("//export %s_recv\n", chNm)
+		g.gofile.Printf("func %s_recv(handle CGoHandle) CGoHandle {\n", chNm)
+		g.gofile.Indent()
+		g.gofile.Printf("ch := deptrFromHandle_%s(handle)\n", chNm)
+		g.gofile.Printf("r := &%s{}\n", rcvNm)
+		g.gofile.Printf("_saved_thread := C.PyEval_SaveThread()\n")
+		g.gofile.Printf("r.v, r.ok = <-ch\n")
+		g.gofile.Printf("C.PyEval_RestoreThread(_saved_thread)\n")
+		g.gofile.Printf("return handleC(gopyh.Register(%q, r))\n", rcvNm)
+		g.gofile.Outdent()
+		g.gofile.Printf("}\n\n")
+
+		g.gofile.Printf("//export %s_recv_value\n", chNm)
+		g.gofile.Printf("func %s_recv_value(h CGoHandle) %s {\n", chNm, esym.cgoname)
+		g.gofile.Indent()
+		g.gofile.Printf("r := gopyh.VarFromHandle(handleGo(h), %q).(*%s)\n", rcvNm, rcvNm)
+		switch {
+		case esym.go2py == "":
+			g.gofile.Printf("return r.v\n")
+		case esym.hasHandle() && !esym.isPtrOrIface():
+			g.gofile.Printf("return %s(&r.v)%s\n", esym.go2py, esym.go2pyParenEx)
+		default:
+			g.gofile.Printf("return %s(r.v)%s\n", esym.go2py, esym.go2pyParenEx)
+		}
+		g.gofile.Outdent()
+		g.gofile.Printf("}\n\n")
+
+		g.gofile.Printf("//export %s_recv_ok\n", chNm)
+		g.gofile.Printf("func %s_recv_ok(h CGoHandle) C.char {\n", chNm)
+		g.gofile.Indent()
+		g.gofile.Printf("r := gopyh.VarFromHandle(handleGo(h), %q).(*%s)\n", rcvNm, rcvNm)
+		g.gofile.Printf("return boolGoToPy(r.ok)\n")
+		g.gofile.Outdent()
+		g.gofile.Printf("}\n\n")
+
+		addFuncName := "mod.add_function("
+		if esym.cpyname == "char*" && !esym.hasHandle() {
+			addFuncName = "add_checked_string_function(mod, "
+		}
+		g.pybuild.Printf("mod.add_function('%s_recv', retval('%s'), [param('%s', 'handle')])\n", chNm, PyHandle, PyHandle)
+		g.pybuild.Printf("%s'%s_recv_value', retval('%s'%s), [param('%s', 'h')])\n", addFuncName, chNm, esym.cpyname, esym.pyRetOwn(), PyHandle)
+		g.pybuild.Printf("mod.add_function('%s_recv_ok', retval('bool'), [param('%s', 'h')])\n", chNm, PyHandle)
+	}
+}
+
+// bidirChanName returns the go name of the bidirectional channel type of
+// the directional channel type sym, and "" if it is not one
+func bidirChanName(sym *symbol) string {
+	if !sym.isChan() {
+		return ""
+	}
+	typ := sym.GoType().Underlying().(*types.Chan)
+	if typ.Dir() == types.SendRecv {
+		return ""
+	}
+	return current.typeGoName(types.NewChan(types.SendRecv, typ.Elem()))
+}
@@ -0,0 +1,583 @@
+// Copyright 2024 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package bind
+
+import (
+	"fmt"
+	"go/ast"
+	"go/constant"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// The args of the functions and methods are checked in python, before
+// crossing into Go, so that obviously bad args fail fast, with a message
+// naming the check: the leading checks of the Go code of a function
+// returning an error, e.g.,
+//
+//	func Sqrt(x float64) (float64, error) {
+//		if x < 0 {
+//			return 0, ErrNegative
+//		}
+//		...
+//
+// are recognized, as the if statements without init or else, up to the
+// first other statement, whose conditions only use the params of basic
+// types, literals and constants, len and the comparison, logical, +, - and
+// * operators, the latter only of int, int64 and float params, as python
+// ints do not wrap around as the unsigned and sized Go ints, and that
+// return a sentinel error of the package, or an errors.New or fmt.Errorf of
+// a string literal, whose verbs are %w of sentinel errors and %d, %s and %v
+// of params of basic types.  They raise the same exceptions as the Go
+// errors, with the same messages: that of the sentinel error returned, or
+// wrapped, if any, and otherwise that of the ~message entries of the
+// ErrorMap option matching the message, or go.ArgumentError, a go.GoError
+// and a ValueError.  The NoArgChecks option turns them off, e.g., for the
+// Go code depending on the order of its checks.  A //gopy:check line in the
+// doc comment of a function sets a check of its args, in any function, as
+// the Go condition the valid args satisfy, e.g.,
+//
+//	//gopy:check 0 <= p && p <= 1
+//
+// raising go.ArgumentError when it does not hold.
+
+const (
+	// python arg checks, in go
+	argChecksPyWrap = `
+# ---- checks of the args of the Go functions, in python ---
+class ArgumentError(GoError, ValueError):
+	"""ArgumentError is raised when the args of a Go function fail its checks, of its Go code or its //gopy:check directives, before calling it"""
+	pass
+
+def arg_len(x):
+	"""arg_len returns the Go len of the python arg: the number of UTF-8 bytes of a str, 0 for None, and its len otherwise"""
+	if x is None:
+		return 0
+	if isinstance(x, str):
+		return len(x.encode('utf-8'))
+	return len(x)
+
+`
+)
+
+// argCheck is a check of the args of a function, in python
+type argCheck struct {
+	fail     string // python condition of the invalid args
+	msg      string // python expression of the message of the exception
+	sentinel string // name of the sentinel error of the package returned by the Go check, if any
+	idiom    bool   // recognized in the Go code, rather than set by a check directive
+}
+
+// argChecks returns the checks of the args of the function of the name,
+// Func or Type.Method, of its check directives, then recognized in its Go
+// code
+func (p *Package) argChecks(name string, sig *types.Signature) []argCheck {
+	if checks, ok := p.checks[sig]; ok {
+		return checks
+	}
+	var checks []argCheck
+	if arg, ok := p.directive(name, "check"); ok {
+		for _, cond := range strings.Split(arg, "\n") {
+			x, err := parser.ParseExpr(cond)
+			py, ok := "", err == nil
+			if ok {
+				py, ok = p.pyCheckExpr(name, sig, x)
+			}
+			if !ok {
+				if !NoWarn {
+					fmt.Printf("gopy: warning: ignoring %scheck directive %q of %s.%s: must be a Go condition of the params of basic types, "+
+						"literals and constants, len and the comparison, logical, +, - and * operators\n", directivePrefix, cond, p.Name(), name)
+				}
+				continue
+			}
+			checks = append(checks, argCheck{
+				fail: "not (" + py + ")",
+				msg:  pyStringLiteral(p.Name() + "." + name + ": invalid args, want " + types.ExprString(x)),
+			})
+		}
+	}
+	checks = append(checks, p.argCheckIdioms(name, sig)...)
+	if p.checks == nil {
+		p.checks = make(map[*types.Signature][]argCheck)
+	}
+	p.checks[sig] = checks
+	return checks
+}
+
+// argCheckIdioms returns the checks of the args recognized in the leading
+// if statements of the Go code of the function, returning an error
+func (p *Package) argCheckIdioms(name string, sig *types.Signature) []argCheck {
+	res := sig.Results()
+	if res.Len() == 0 || !isErrorType(res.At(res.Len()-1).Type()) {
+		return nil
+	}
+	decl := p.funcDecl(name)
+	if decl == nil || decl.Body == nil {
+		return nil
+	}
+	var checks []argCheck
+	for _, st := range decl.Body.List {
+		ifs, ok := st.(*ast.IfStmt)
+		if !ok || ifs.Init != nil || ifs.Else != nil || len(ifs.Body.List) != 1 {
+			break
+		}
+		ret, ok := ifs.Body.List[0].(*ast.ReturnStmt)
+		if !ok || len(ret.Results) != res.Len() {
+			break
+		}
+		py, ok := p.pyCheckExpr(name, sig, ifs.Cond)
+		if !ok {
+			break
+		}
+		msg, sentinel, ok := p.checkError(sig, ret.Results[len(ret.Results)-1])
+		if !ok {
+			break
+		}
+		if msg == "" {
+			msg = pyStringLiteral(p.Name() + "." + name + ": invalid args: " + types.ExprString(ifs.Cond))
+		}
+		checks = append(checks, argCheck{fail: py, msg: msg, sentinel: sentinel, idiom: true})
+	}
+	return checks
+}
+
+// checkError returns the python expression of the message of the error
+// returned by a check, or "" if unknown, and the name of the sentinel error
+// of the package it is, or wraps, if any, and whether the error can be
+// raised in python: a sentinel error of the package, or an errors.New or
+// fmt.Errorf of a string literal, whose verbs are %w of sentinel errors
+// and %d, %s and %v of params of basic types
+func (p *Package) checkError(sig *types.Signature, x ast.Expr) (msg, sentinel string, ok bool) {
+	if id, ok := x.(*ast.Ident); ok && p.isSentinelError(id.Name) {
+		if s, ok := p.sentinelMessage(id.Name); ok {
+			return pyStringLiteral(s), id.Name, true
+		}
+		return "", id.Name, true
+	}
+	fn, s, args, ok := errorCall(x)
+	switch {
+	case !ok:
+		return "", "", false
+	case fn == "errors.New":
+		return pyStringLiteral(s), "", true
+	}
+
+	params := checkParams(sig)
+	var (
+		lit, pyfmt strings.Builder // message without args, and python format of it
+		pyArgs     []string
+	)
+	for i := 0; i < len(s); i++ {
+		if s[i] != '%' {
+			lit.WriteByte(s[i])
+			pyfmt.WriteByte(s[i])
+			continue
+		}
+		i++
+		if i == len(s) {
+			return "", "", false
+		}
+		verb := s[i]
+		if verb == '%' {
+			lit.WriteByte('%')
+			pyfmt.WriteString("%%")
+			continue
+		}
+		if len(args) == 0 {
+			return "", "", false
+		}
+		id, ok := args[0].(*ast.Ident)
+		args = args[1:]
+		if !ok {
+			return "", "", false
+		}
+		if verb == 'w' {
+			wmsg, ok := p.sentinelMessage(id.Name)
+			if !ok || !p.isSentinelError(id.Name) {
+				return "", "", false
+			}
+			if sentinel == "" {
+				sentinel = id.Name
+			}
+			lit.WriteString(wmsg)
+			pyfmt.WriteString(strings.ReplaceAll(wmsg, "%", "%%"))
+			continue
+		}
+		pi, ok := params[id.Name]
+		if !ok {
+			return "", "", false
+		}
+		b, ok := sig.Params().At(pi).Type().(*types.Basic)
+		switch {
+		case !ok || b.Name() == "rune":
+			return "", "", false
+		case b.Info()&types.IsInteger != 0 && (verb == 'd' || verb == 'v'):
+			pyfmt.WriteString("%d")
+		case b.Info()&types.IsString != 0 && (verb == 's' || verb == 'v'):
+			pyfmt.WriteString("%s")
+		default:
+			return "", "", false
+		}
+		pyArgs = append(pyArgs, pySafeArg(id.Name, pi))
+	}
+	if len(args) != 0 {
+		return "", "", false
+	}
+	if len(pyArgs) == 0 {
+		return pyStringLiteral(lit.String()), sentinel, true
+	}
+	return pyStringLiteral(pyfmt.String()) + " % (" + strings.Join(pyArgs, ", ") + ",)", sentinel, true
+}
+
+// errorCall returns the function, errors.New or fmt.Errorf, the string
+// literal and the other args of the call making an error, if it is one
+func errorCall(x ast.Expr) (fn, s string, args []ast.Expr, ok bool) {
+	call, ok := x.(*ast.CallExpr)
+	if !ok || len(call.Args) == 0 {
+		return "", "", nil, false
+	}
+	sel, ok := call.Fun.(*ast.SelectorExpr)
+	if !ok {
+		return "", "", nil, false
+	}
+	pkg, ok := sel.X.(*ast.Ident)
+	if !ok {
+		return "", "", nil, false
+	}
+	fn = pkg.Name + "." + sel.Sel.Name
+	if (fn != "errors.New" || len(call.Args) != 1) && fn != "fmt.Errorf" {
+		return "", "", nil, false
+	}
+	lit, ok := call.Args[0].(*ast.BasicLit)
+	if !ok || lit.Kind != token.STRING {
+		return "", "", nil, false
+	}
+	s, err := strconv.Unquote(lit.Value)
+	if err != nil {
+		return "", "", nil, false
+	}
+	return fn, s, call.Args[1:], true
+}
+
+// isSentinelError returns whether the name is that of a sentinel error var
+// of the package
+func (p *Package) isSentinelError(name string) bool {
+	v, ok := p.pkg.Scope().Lookup(name).(*types.Var)
+	return ok && isErrorType(v.Type())
+}
+
+// sentinelMessage returns the message of the sentinel error of the
+// package of the name, if it is an errors.New of a string literal
+func (p *Package) sentinelMessage(name string) (string, bool) {
+	if p.doc == nil {
+		return "", false
+	}
+	for _, v := range p.doc.Vars {
+		for _, spec := range v.Decl.Specs {
+			vs := spec.(*ast.ValueSpec)
+			for i, n := range vs.Names {
+				if n.Name != name || i >= len(vs.Values) {
+					continue
+				}
+				if fn, s, _, ok := errorCall(vs.Values[i]); ok && fn == "errors.New" {
+					return s, true
+				}
+			}
+		}
+	}
+	return "", false
+}
+
+// funcDecl returns the declaration of the function of the name, Func or
+// Type.Method, if any
+func (p *Package) funcDecl(name string) *ast.FuncDecl {
+	if p.decls == nil {
+		p.decls = make(map[string]*ast.FuncDecl)
+		if p.doc != nil {
+			for _, f := range p.doc.Funcs {
+				p.decls[f.Name] = f.Decl
+			}
+			for _, t := range p.doc.Types {
+				for _, f := range t.Funcs {
+					p.decls[f.Name] = f.Decl
+				}
+				for _, f := range t.Methods {
+					p.decls[t.Name+"."+f.Name] = f.Decl
+				}
+			}
+		}
+	}
+	if tn, mn, ok := strings.Cut(name, "."); ok {
+		name = p.originName(tn) + "." + mn
+	}
+	return p.decls[name]
+}
+
+// pyCheckExpr returns the python expression of the Go condition of a check
+// of the args of the function, and whether it can be checked in python
+func (p *Package) pyCheckExpr(name string, sig *types.Signature, x ast.Expr) (string, bool) {
+	borrow := p.borrowedArgs(name, sig)
+	params := checkParams(sig)
+	ops := map[token.Token]string{
+		token.EQL: "==", token.NEQ: "!=", token.LSS: "<", token.LEQ: "<=", token.GTR: ">", token.GEQ: ">=",
+		token.LAND: "and", token.LOR: "or", token.ADD: "+", token.SUB: "-", token.MUL: "*",
+	}
+
+	// arith returns whether the arithmetic on the operand is that of python:
+	// that of the params of types whose arithmetic does not wrap around in
+	// practice, literals, constants and len
+	var arith func(x ast.Expr) bool
+	arith = func(x ast.Expr) bool {
+		switch x := x.(type) {
+		case *ast.ParenExpr:
+			return arith(x.X)
+		case *ast.Ident:
+			if i, ok := params[x.Name]; ok {
+				return isArithType(sig.Params().At(i).Type())
+			}
+			return true
+		case *ast.BasicLit, *ast.CallExpr:
+			return true
+		case *ast.UnaryExpr:
+			return arith(x.X)
+		case *ast.BinaryExpr:
+			return !isArithOp(x.Op) || (arith(x.X) && arith(x.Y))
+		}
+		return false
+	}
+
+	var expr, operand func(x ast.Expr) (string, bool)
+	expr = func(x ast.Expr) (string, bool) {
+		switch x := x.(type) {
+		case *ast.ParenExpr:
+			return expr(x.X)
+		case *ast.Ident:
+			if i, ok := params[x.Name]; ok {
+				return pySafeArg(x.Name, i), isCheckableType(sig.Params().At(i).Type())
+			}
+			switch x.Name {
+			case "true":
+				return "True", true
+			case "false":
+				return "False", true
+			}
+			if c, ok := p.pkg.Scope().Lookup(x.Name).(*types.Const); ok {
+				return pyCheckConst(c.Val())
+			}
+		case *ast.BasicLit:
+			return pyCheckConst(constant.MakeFromLiteral(x.Value, x.Kind, 0))
+		case *ast.UnaryExpr:
+			py, ok := operand(x.X)
+			switch x.Op {
+			case token.NOT:
+				return "not " + py, ok
+			case token.SUB:
+				return "-" + py, ok && arith(x.X)
+			}
+		case *ast.BinaryExpr:
+			op, ok := ops[x.Op]
+			if !ok {
+				return "", false
+			}
+			if isArithOp(x.Op) && !(arith(x.X) && arith(x.Y)) {
+				return "", false
+			}
+			l, lok := operand(x.X)
+			r, rok := operand(x.Y)
+			return l + " " + op + " " + r, lok && rok
+		case *ast.CallExpr:
+			fn, ok := x.Fun.(*ast.Ident)
+			if !ok || fn.Name != "len" || len(x.Args) != 1 {
+				return "", false
+			}
+			arg, ok := x.Args[0].(*ast.Ident)
+			if !ok {
+				return "", false
+			}
+			i, ok := params[arg.Name]
+			if !ok || borrow[i] || !hasCheckableLen(sig.Params().At(i).Type()) {
+				return "", false
+			}
+			return "go.arg_len(" + pySafeArg(arg.Name, i) + ")", true
+		}
+		return "", false
+	}
+	operand = func(x ast.Expr) (string, bool) {
+		for {
+			paren, ok := x.(*ast.ParenExpr)
+			if !ok {
+				break
+			}
+			x = paren.X
+		}
+		py, ok := expr(x)
+		switch x := x.(type) {
+		case *ast.UnaryExpr:
+			if x.Op == token.NOT {
+				py = "(" + py + ")"
+			}
+		case *ast.BinaryExpr:
+			py = "(" + py + ")"
+		}
+		return py, ok
+	}
+	return expr(x)
+}
+
+// checkParams returns the indexes of the params of the signature that the
+// checks can use, by name: all but the blank and variadic ones
+func checkParams(sig *types.Signature) map[string]int {
+	params := make(map[string]int)
+	for i := 0; i < sig.Params().Len(); i++ {
+		if sig.Variadic() && i == sig.Params().Len()-1 {
+			continue
+		}
+		if nm := sig.Params().At(i).Name(); nm != "" && nm != "_" {
+			params[nm] = i
+		}
+	}
+	return params
+}
+
+// isCheckableType returns whether the params of the type can be used in
+// the checks of the args in python, as they are python bool, int, float
+// or str
+func isCheckableType(typ types.Type) bool {
+	if findTypeConverter(types.TypeString(typ, nil)) != nil {
+		return false
+	}
+	b, ok := typ.Underlying().(*types.Basic)
+	return ok && b.Name() != "rune" && b.Info()&(types.IsBoolean|types.IsInteger|types.IsFloat|types.IsString) != 0
+}
+
+// isArithOp returns whether the operator of the checks is an arithmetic one
+func isArithOp(op token.Token) bool {
+	return op == token.ADD || op == token.SUB || op == token.MUL
+}
+
+// isArithType returns whether the arithmetic of the params of the type can
+// be done in python: that of int, int64 and float, but not of the unsigned
+// and sized ints, which wrap around
+func isArithType(typ types.Type) bool {
+	b, ok := typ.Underlying().(*types.Basic)
+	if !ok {
+		return false
+	}
+	switch b.Kind() {
+	case types.Int, types.Int64, types.Float32, types.Float64:
+		return true
+	}
+	return false
+}
+
+// hasCheckableLen returns whether the len of the params of the type can
+// be checked in python
+func hasCheckableLen(typ types.Type) bool {
+	if tc := findTypeConverter(types.TypeString(typ, nil)); tc != nil {
+		return tc.goname == "[]rune"
+	}
+	switch t := typ.Underlying().(type) {
+	case *types.Basic:
+		return t.Info()&types.IsString != 0
+	case *types.Slice, *types.Map, *types.Array:
+		return true
+	}
+	return false
+}
+
+// pyCheckConst returns the python literal of a constant of a check
+func pyCheckConst(v constant.Value) (string, bool) {
+	switch v.Kind() {
+	case constant.Bool:
+		if constant.BoolVal(v) {
+			return "True", true
+		}
+		return "False", true
+	case constant.String:
+		return pyStringLiteral(constant.StringVal(v)), true
+	case constant.Int:
+		return v.ExactString(), true
+	case constant.Float:
+		f, _ := constant.Float64Val(v)
+		if math.IsInf(f, 0) {
+			return "", false
+		}
+		s := strconv.FormatFloat(f, 'g', -1, 64)
+		if !strings.ContainsAny(s, ".e") {
+			s += ".0"
+		}
+		return s, true
+	}
+	return "", false
+}
+
+// usesArgChecks returns whether any function has checks of its args, in
+// python, but the recognized ones with the NoArgChecks option
+func usesArgChecks(noIdioms bool) bool {
+	for _, p := range Packages {
+		for _, checks := range p.checks {
+			for _, c := range checks {
+				if !c.idiom || !noIdioms {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
+
+// genArgChecksPyWrap generates the ArgumentError and the arg_error of go,
+// if used, raising the errors of the recognized checks as the exceptions
+// of the ~message entries of the ErrorMap option matching them, as in Go
+func (g *pyGen) genArgChecksPyWrap() {
+	if !usesArgChecks(g.cfg.NoArgChecks) {
+		return
+	}
+	g.pywrap.Printf("%s", argChecksPyWrap)
+	g.pywrap.Printf("def arg_error(msg):\n")
+	g.pywrap.Indent()
+	g.pywrap.Printf("\"\"\"arg_error returns the exception of a failed check of the Go code of a function, returning an error of the message\"\"\"\n")
+	for _, m := range g.errMap {
+		if m.msg == "" {
+			continue
+		}
+		g.pywrap.Printf("if %s in msg:\n", pyStringLiteral(m.msg))
+		g.pywrap.Indent()
+		g.pywrap.Printf("return Go%s(msg)\n", m.exc)
+		g.pywrap.Outdent()
+	}
+	g.pywrap.Printf("return ArgumentError(msg)\n")
+	g.pywrap.Outdent()
+	g.pywrap.Printf("\n")
+}
+
+// genArgChecks generates the checks of the args of the function, in
+// python, raising the exception of the sentinel error of the check, if
+// bound, or that of go.arg_error for the other recognized checks, or
+// go.ArgumentError
+func (g *pyGen) genArgChecks(fsym *Func) {
+	for _, c := range fsym.checks {
+		if c.idiom && g.cfg.NoArgChecks {
+			continue
+		}
+		exc := "go.ArgumentError"
+		if c.idiom {
+			exc = "go.arg_error"
+		}
+		for _, e := range fsym.pkg.errs {
+			if c.sentinel != "" && e.isSentinel() && e.Name() == c.sentinel {
+				exc = e.PyName()
+			}
+		}
+		g.pywrap.Printf("if %s:\n", c.fail)
+		g.pywrap.Indent()
+		g.pywrap.Printf("raise %s(%s)\n", exc, c.msg)
+		g.pywrap.Outdent()
+	}
+}
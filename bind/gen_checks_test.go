@@ -0,0 +1,133 @@
+// Copyright 2024 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package bind
+
+import (
+	"go/ast"
+	"go/doc"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"reflect"
+	"testing"
+)
+
+func TestArgChecks(t *testing.T) {
+	const src = `package hi
+
+import (
+	"errors"
+	"fmt"
+)
+
+const Max = 10
+
+var ErrBad = errors.New("bad")
+
+func Add(a, b int) (int, error) {
+	if a < 0 || !(b >= -1) {
+		return 0, ErrBad
+	}
+	if a+b > Max*2 {
+		return 0, errors.New("too big")
+	}
+	if a == 2*b {
+		return 0, fmt.Errorf("%w: %d is 2*%v, 100%%", ErrBad, a, b)
+	}
+	if a == b {
+		return a, nil
+	}
+	if a > 5 {
+		return 0, ErrBad
+	}
+	return a + b, nil
+}
+
+func Sub(u uint, n int32, x float64) error {
+	if x*2 > 1 {
+		return ErrBad
+	}
+	if u-10 < 5 {
+		return ErrBad
+	}
+	return nil
+}
+
+func Neg(n int32) error {
+	if -n > 0 || n*2 > 10 {
+		return ErrBad
+	}
+	return nil
+}
+
+// Name sets the name.
+//
+//gopy:check len(s) <= Max && f != 0.5
+//gopy:check s[0] == 'a'
+func Name(s string, f float64, r rune, xs ...int) error {
+	if r > 'a' {
+		return ErrBad
+	}
+	return nil
+}
+`
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, "hi.go", src, parser.ParseComments)
+	if err != nil {
+		t.Fatal(err)
+	}
+	conf := types.Config{Importer: importerFunc(func(path string) (*types.Package, error) {
+		pkg := types.NewPackage(path, path)
+		params := []*types.Var{types.NewVar(token.NoPos, pkg, "format", types.Typ[types.String])}
+		if path == "fmt" {
+			params = append(params, types.NewVar(token.NoPos, pkg, "a", types.NewSlice(types.NewInterfaceType(nil, nil))))
+		}
+		sig := types.NewSignatureType(nil, nil, nil, types.NewTuple(params...),
+			types.NewTuple(types.NewVar(token.NoPos, pkg, "", types.Universe.Lookup("error").Type())), path == "fmt")
+		pkg.Scope().Insert(types.NewFunc(token.NoPos, pkg, map[string]string{"errors": "New", "fmt": "Errorf"}[path], sig))
+		pkg.MarkComplete()
+		return pkg, nil
+	})}
+	tpkg, err := conf.Check("hi", fset, []*ast.File{f}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	dpkg, err := doc.NewFromFiles(fset, []*ast.File{f}, "hi", doc.PreserveAST)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p := &Package{pkg: tpkg, doc: dpkg}
+	sig := func(name string) *types.Signature {
+		return tpkg.Scope().Lookup(name).Type().(*types.Signature)
+	}
+
+	NoWarn = true
+	defer func() { NoWarn = false }()
+	for _, tc := range []struct {
+		name string
+		want []argCheck
+	}{
+		{"Add", []argCheck{
+			{fail: "(a < 0) or (not (b >= -1))", msg: `"bad"`, sentinel: "ErrBad", idiom: true},
+			{fail: "(a + b) > (10 * 2)", msg: `"too big"`, idiom: true},
+			{fail: "a == (2 * b)", msg: `"bad: %d is 2*%d, 100%%" % (a, b,)`, sentinel: "ErrBad", idiom: true},
+		}},
+		{"Sub", []argCheck{
+			{fail: "(x * 2) > 1", msg: `"bad"`, sentinel: "ErrBad", idiom: true},
+		}},
+		{"Neg", nil},
+		{"Name", []argCheck{
+			{fail: "not ((go.arg_len(s) <= 10) and (f != 0.5))", msg: `"hi.Name: invalid args, want len(s) <= Max && f != 0.5"`},
+		}},
+	} {
+		if got := p.argChecks(tc.name, sig(tc.name)); !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("checks of %s:\ngot  %+v\nwant %+v", tc.name, got, tc.want)
+		}
+	}
+}
+
+type importerFunc func(path string) (*types.Package, error)
+
+func (f importerFunc) Import(path string) (*types.Package, error) { return f(path) }
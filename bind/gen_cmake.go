@@ -0,0 +1,172 @@
+// Copyright 2024 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package bind
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+)
+
+// With the cmake BuildSystem, a CMakeLists.txt is generated instead of the
+// Makefile, for integrating the bindings into larger CMake builds, building
+// the same outputs by the same steps, as targets depending on their inputs:
+// the cgo wrappers to the go functions, as a c-archive and its header, the
+// CPython wrappers to them, by pybindgen, and the python extension module
+// statically linking both.  The go commands use the C compiler of
+// cmake.  The executables of exe and the perf targets are only built by
+// the Makefile.
+
+const (
+	// 1 = name of package, 2 = cmdstr, 3 = gencmd, 4 = vm, 5 = libext,
+	// 6 = python CFLAGS, 7 = python LDFLAGS, 8 = extra Go files,
+	// 9 = extra Go file dependencies, 10 = windows special declspec hack,
+	// 11 = default cross compiler, 12 = cross build go env, 13 = go runtime libs
+	cmakeTemplate = `# CMakeLists.txt for python interface for package %[1]s.
+# File is generated by gopy. Do not edit.
+# %[2]s
+#
+# the outputs are built in this directory, as by the Makefile, e.g., by:
+#   cmake -S . -B build && cmake --build build
+
+cmake_minimum_required(VERSION 3.16)
+%[11]s
+project(gopy_%[1]s C)
+
+set(GO go CACHE STRING "go command")
+set(GOIMPORTS goimports CACHE STRING "goimports command")
+set(PYTHON %[4]s CACHE FILEPATH "python interpreter")
+set(LIBEXT %[5]s)
+set(SRC ${CMAKE_CURRENT_SOURCE_DIR})
+
+# the flags used to build python:
+set(PY_CFLAGS %[6]s)
+set(PY_LDFLAGS %[7]s)
+
+# the go commands use the C compiler of cmake
+set(GOENV ${CMAKE_COMMAND} -E env CC=${CMAKE_C_COMPILER}%[12]s)
+
+# gen regenerates the bindings
+add_custom_target(gen
+	COMMAND %[3]s
+	WORKING_DIRECTORY ${SRC}
+)
+
+# the %[1]s_go.a archive and %[1]s_go.h from %[1]s.go -- the cgo wrappers to go functions
+# goimports is needed to ensure that the imports list is valid
+add_custom_command(
+	OUTPUT ${SRC}/%[1]s_go.a ${SRC}/%[1]s_go.h
+	COMMAND ${GOIMPORTS} -w %[1]s.go
+	COMMAND ${GOENV} ${GO} build -mod=mod -buildmode=c-archive -o %[1]s_go.a %[1]s.go%[8]s
+	DEPENDS ${SRC}/%[1]s.go%[9]s
+	WORKING_DIRECTORY ${SRC}
+	VERBATIM
+)
+add_custom_target(%[1]s_go DEPENDS ${SRC}/%[1]s_go.a ${SRC}/%[1]s_go.h)
+
+# %[1]s.c from build.py -- the CPython wrappers to cgo wrappers, by pybindgen
+# note: pip install pybindgen to get pybindgen if this fails
+add_custom_command(
+	OUTPUT ${SRC}/%[1]s.c
+	COMMAND ${PYTHON} build.py%[10]s
+	DEPENDS ${SRC}/build.py ${SRC}/%[1]s_go.h
+	WORKING_DIRECTORY ${SRC}
+	VERBATIM
+)
+
+# _%[1]s${LIBEXT} -- the library that contains the cgo and CPython wrappers,
+# statically linking the go archive, imported by the generated %[1]s.py
+# python wrapper
+add_library(_%[1]s SHARED ${SRC}/%[1]s.c)
+add_dependencies(_%[1]s %[1]s_go)
+target_compile_options(_%[1]s PRIVATE ${PY_CFLAGS} -w)
+target_link_libraries(_%[1]s PRIVATE ${SRC}/%[1]s_go.a ${PY_LDFLAGS} %[13]s)
+set_target_properties(_%[1]s PROPERTIES
+	PREFIX ""
+	SUFFIX ${LIBEXT}
+	LIBRARY_OUTPUT_DIRECTORY ${SRC}
+	RUNTIME_OUTPUT_DIRECTORY ${SRC}
+)
+`
+)
+
+// buildFile returns the name of the build file generated along with the
+// bindings, for the BuildSystem
+func (g *pyGen) buildFile() string {
+	switch {
+	case g.cmake():
+		return "CMakeLists.txt"
+	case g.bazel():
+		return "BUILD.bazel"
+	}
+	return "Makefile"
+}
+
+// cmake returns whether a CMakeLists.txt is generated instead of the Makefile
+func (g *pyGen) cmake() bool {
+	return g.cfg.BuildSystem == "cmake" && g.mode != ModeExe
+}
+
+// checkBuildSystem checks the BuildSystem, warning that exe has no cmake
+// or bazel build
+func (g *pyGen) checkBuildSystem() {
+	switch g.cfg.BuildSystem {
+	case "", "make":
+	case "cmake", "bazel":
+		if g.mode == ModeExe && !NoWarn {
+			fmt.Printf("gopy: warning: generating a Makefile, as exe has no %s build\n", g.cfg.BuildSystem)
+		}
+	default:
+		g.err.Add(fmt.Errorf("gopy: invalid build system %q: must be make, cmake or bazel", g.cfg.BuildSystem))
+	}
+}
+
+// cmakeList returns the CMake list of the flags, quoted for the #cgo lines
+func cmakeList(flags string) string {
+	var items []string
+	for _, f := range strings.Fields(flags) {
+		items = append(items, strings.Trim(f, `"`))
+	}
+	return cmakeItems(items)
+}
+
+// cmakeItems returns the CMake list of the items, quoted, keeping together
+// the items of several words, e.g., -framework Security
+func cmakeItems(items []string) string {
+	quoted := make([]string, len(items))
+	for i, it := range items {
+		quoted[i] = fmt.Sprintf("%q", it)
+	}
+	return strings.Join(quoted, " ")
+}
+
+// genCMakeLists generates the CMakeLists.txt of the bindings, of the gen
+// command gencmd and the python configuration pycfg
+func (g *pyGen) genCMakeLists(gencmd string, pycfg PyConfig) {
+	winhack := ""
+	if WindowsOS {
+		winhack = fmt.Sprintf(`
+	# windows-only hack here to fix pybindgen declaration of PyInit
+	COMMAND ${PYTHON} -c "f = '%[1]s.c'; s = open(f).read().replace(' PyInit_', ' __declspec(dllexport) PyInit_'); open(f, 'w').write(s)"`, g.cfg.Name)
+	}
+	extra, extraDeps := "", ""
+	if g.cfg.ExtraGo != "" {
+		files, _ := extraGoFiles(g.cfg.ExtraGo) // errors reported by genExtraGo
+		for _, fn := range files {
+			extra += " " + filepath.Base(fn)
+			extraDeps += " ${SRC}/" + filepath.Base(fn)
+		}
+	}
+	cc, goenv := "", ""
+	if g.cfg.CrossBuild() {
+		goos, goarch := g.cfg.TargetOS(), g.cfg.TargetArch()
+		goenv = fmt.Sprintf(" GOOS=%s GOARCH=%s CGO_ENABLED=1", goos, goarch)
+		cc = fmt.Sprintf("\n# cross-build for %s/%s, with the C cross compiler CC\n", goos, goarch)
+		if xcc := CrossCC(goos, goarch); xcc != "" {
+			cc += fmt.Sprintf("if(NOT CMAKE_C_COMPILER AND NOT DEFINED ENV{CC})\n\tset(CMAKE_C_COMPILER %s)\nendif()\n", xcc)
+		}
+	}
+	g.makefile.Printf(cmakeTemplate, g.cfg.Name, g.cfg.Cmd, gencmd, g.cfg.VM, g.libext, cmakeList(pycfg.CFlags), cmakeList(pycfg.LdFlags), extra, extraDeps, winhack, cc, goenv, cmakeItems(g.cfg.archiveLibs()))
+}
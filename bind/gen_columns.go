@@ -0,0 +1,332 @@
+// Copyright 2024 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package bind
+
+import (
+	"fmt"
+	"go/types"
+	"strings"
+)
+
+// The functions returning parallel slices of numbers or bools, the columns
+// of a table, optionally with an error, e.g., func Sample(n int) (t, v
+// []float64, err error), common in scientific Go APIs, are not bound by
+// default, returning more than one value, and are bound by a //gopy:columns
+// directive, e.g.,
+//
+//	// Sample returns n samples of the times and values of the signal.
+//	//gopy:columns
+//	func Sample(n int) (t, v []float64, err error)
+//
+// returning to python a numpy structured array with a field per column,
+// named by the named results, or f0, f1..., or with //gopy:columns dict, a
+// dict of numpy arrays, by name.  The columns are copied from Go in a single
+// call, as bytearrays, viewed by numpy.  Columns of different lengths raise
+// ValueError.  The functions only take args of basic types, and numpy is
+// only imported by their first call.
+
+const (
+	columnsStruct = "struct" // a numpy structured array
+	columnsDict   = "dict"   // a dict of numpy arrays
+
+	// go code of the conversion of the columns
+	columnsGo = `
+// ---- columns: parallel slices returned as numpy arrays ---
+
+// gopyColumnPy returns the bytes of the n values of size sz at p, the data
+// of a slice, as a python bytearray
+func gopyColumnPy(p unsafe.Pointer, n, sz int) *C.PyObject {
+	if n == 0 {
+		return C.PyByteArray_FromStringAndSize(nil, 0)
+	}
+	return C.PyByteArray_FromStringAndSize((*C.char)(p), C.Py_ssize_t(n*sz))
+}
+
+// gopyColumnsLen checks that the columns of the function fn have the same
+// length, setting a ValueError if not
+func gopyColumnsLen(fn string, names []string, lens ...int) bool {
+	for i, n := range lens {
+		if n != lens[0] {
+			estr := C.CString(fmt.Sprintf("%s: columns of different lengths: %s has %d values, %s has %d", fn, names[0], lens[0], names[i], n))
+			C.PyErr_SetString(C.PyExc_ValueError, estr)
+			C.free(unsafe.Pointer(estr))
+			return false
+		}
+	}
+	return true
+}
+`
+
+	// python code of the conversion of the columns, in go
+	columnsPyWrap = `
+# ---- columns: parallel slices returned as numpy arrays ---
+def columns(names, dtypes, cols, shape):
+	"""columns returns the columns of bytearrays of the Go slices returned by a function of a //gopy:columns directive as a numpy structured array, or a dict of numpy arrays, by the shape"""
+	import numpy
+	arrs = [numpy.frombuffer(c, dtype=d) for c, d in zip(cols, dtypes)]
+	if shape == "dict":
+		return dict(zip(names, arrs))
+	a = numpy.empty(len(arrs[0]), dtype=list(zip(names, dtypes)))
+	for n, c in zip(names, arrs):
+		a[n] = c
+	return a
+
+`
+)
+
+// columnsFunc is a function bound by a //gopy:columns directive
+type columnsFunc struct {
+	pkg   *Package
+	obj   *types.Func
+	sig   *types.Signature
+	doc   string
+	shape string   // columnsStruct or columnsDict
+	names []string // of the columns
+	elems []types.Type
+	err   bool // whether the last result is an error
+}
+
+// genColumnsGo generates the go conversion of the columns
+func (g *pyGen) genColumnsGo() {
+	g.gofile.Printf("%s", columnsGo)
+}
+
+// genColumnsPyWrap generates the go.columns python conversion of the columns
+func (g *pyGen) genColumnsPyWrap() {
+	g.pywrap.Printf("%s", columnsPyWrap)
+}
+
+// newColumnsFunc returns the columns function of the function of a
+// //gopy:columns directive, or an error if it is not one
+func newColumnsFunc(p *Package, obj *types.Func, shape string) (*columnsFunc, error) {
+	switch shape {
+	case "":
+		shape = columnsStruct
+	case columnsStruct, columnsDict:
+	default:
+		return nil, fmt.Errorf("must be struct or dict, not %q", shape)
+	}
+	sig := obj.Type().(*types.Signature)
+	c := &columnsFunc{pkg: p, obj: obj, sig: sig, shape: shape}
+	res := sig.Results()
+	n := res.Len()
+	if n > 0 && isErrorType(res.At(n-1).Type()) {
+		c.err = true
+		n--
+	}
+	if n == 0 {
+		return nil, fmt.Errorf("no columns returned")
+	}
+	for i := 0; i < n; i++ {
+		r := res.At(i)
+		sl, ok := r.Type().Underlying().(*types.Slice)
+		if !ok || columnDtype(p, sl.Elem()) == "" {
+			return nil, fmt.Errorf("result %d is not a slice of numbers or bools: %s", i, r.Type())
+		}
+		nm := r.Name()
+		if nm == "" || nm == "_" {
+			nm = fmt.Sprintf("f%d", i)
+		}
+		c.names = append(c.names, nm)
+		c.elems = append(c.elems, sl.Elem())
+	}
+	args := sig.Params()
+	for i := 0; i < args.Len(); i++ {
+		sym := current.symtype(args.At(i).Type())
+		if sym == nil || !sym.isBasic() || isDuration(sym) || sig.Variadic() {
+			return nil, fmt.Errorf("arg %s is not of a basic type", args.At(i).Name())
+		}
+	}
+	for _, f := range p.doc.Funcs {
+		if f.Name == obj.Name() {
+			c.doc = f.Doc
+		}
+	}
+	return c, nil
+}
+
+// addColumnsFunc adds the function of a //gopy:columns directive to the
+// package, warning if it is not one
+func (p *Package) addColumnsFunc(obj *types.Func, shape string) {
+	c, err := newColumnsFunc(p, obj, shape)
+	if err != nil {
+		statDiag(skipIncompatFunc, p.Name()+"."+obj.Name(), obj, errDetail(err))
+		if !NoWarn {
+			fmt.Printf("gopy: warning: ignoring %scolumns directive of %s.%s: %v\n", directivePrefix, p.Name(), obj.Name(), err)
+		}
+		return
+	}
+	p.columns = append(p.columns, c)
+}
+
+// isColumnsFunc returns whether the object is a function of a
+// //gopy:columns directive
+func (p *Package) isColumnsFunc(obj types.Object) bool {
+	if _, ok := obj.(*types.Func); !ok {
+		return false
+	}
+	_, ok := p.directive(obj.Name(), "columns")
+	return ok
+}
+
+// columnDtype returns the numpy dtype of the elements of a column, in the
+// native byte order, or "" if not a number or bool
+func columnDtype(p *Package, elem types.Type) string {
+	b, ok := elem.Underlying().(*types.Basic)
+	if !ok {
+		return ""
+	}
+	sz := p.sz.Sizeof(b)
+	info := b.Info()
+	switch {
+	case info&types.IsBoolean != 0:
+		return "?"
+	case info&types.IsFloat != 0:
+		return fmt.Sprintf("=f%d", sz)
+	case info&types.IsUnsigned != 0:
+		return fmt.Sprintf("=u%d", sz)
+	case info&types.IsInteger != 0:
+		return fmt.Sprintf("=i%d", sz)
+	}
+	return ""
+}
+
+// docString returns the docstring of the python function, in the Google
+// style of the docstrings of the other functions, see docSections
+func (c *columnsFunc) docString(pyname string) string {
+	args, _, raises := c.pkg.docItems(c.sig, "")
+	pyArgs := make([]string, len(args))
+	for i, it := range args {
+		pyArgs[i] = it.typ + " " + it.name
+	}
+	ret := docItem{typ: "numpy.ndarray", desc: "the columns " + strings.Join(c.names, ", ") + " of the Go slices, as the fields of a structured array (copied, O(n))"}
+	if c.shape == columnsDict {
+		ret = docItem{typ: "dict", desc: "the columns " + strings.Join(c.names, ", ") + " of the Go slices, as numpy arrays by name (copied, O(n))"}
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s(%s) %s\n\n", pyname, strings.Join(pyArgs, ", "), ret.typ)
+	if doc := strings.TrimSpace(c.doc); doc != "" {
+		b.WriteString(doc + "\n\n")
+	}
+	if len(args) > 0 {
+		b.WriteString("Args:\n")
+		for _, it := range args {
+			b.WriteString("    " + it.google() + "\n")
+		}
+		b.WriteString("\n")
+	}
+	b.WriteString("Returns:\n    " + ret.google() + "\n")
+	var errs []string
+	if raises {
+		errs = append(errs, docRaises)
+	}
+	if len(c.names) > 1 {
+		errs = append(errs, "ValueError: if the columns have different lengths")
+	}
+	if len(errs) > 0 {
+		b.WriteString("\nRaises:\n")
+		for _, e := range errs {
+			b.WriteString("    " + e + "\n")
+		}
+	}
+	return b.String()
+}
+
+// genColumnsFunc generates the python function of the columns function, and
+// the go function returning its columns as a tuple of bytearrays
+func (g *pyGen) genColumnsFunc(c *columnsFunc) {
+	name := c.obj.Name()
+	pyname := name
+	if g.cfg.RenameCase {
+		pyname = toSnakeCase(pyname)
+	}
+	if nm := c.pkg.pyNameDirective(name); nm != "" {
+		pyname = nm
+	}
+	cgoFn := c.pkg.Name() + "_" + name + "_GoPyColumns"
+
+	var (
+		goArgs   []string // of the go function
+		pyArgs   []string // of pybindgen
+		wpArgs   []string // of the python function
+		callArgs []string // of the Go call
+	)
+	args := c.sig.Params()
+	for i := 0; i < args.Len(); i++ {
+		sym := current.symtype(args.At(i).Type())
+		anm := pySafeArg(args.At(i).Name(), i)
+		gnm := goSafeArg(args.At(i).Name(), i)
+		goArgs = append(goArgs, fmt.Sprintf("%s %s", gnm, sym.cgoname))
+		pyArgs = append(pyArgs, fmt.Sprintf("param('%s', '%s')", sym.cpyname, anm))
+		wpArgs = append(wpArgs, anm)
+		if sym.py2go != "" {
+			callArgs = append(callArgs, fmt.Sprintf("%s(%s)%s", sym.py2go, gnm, sym.py2goParenEx))
+		} else {
+			callArgs = append(callArgs, gnm)
+		}
+	}
+
+	var dtypes, names, cols []string
+	for i, nm := range c.names {
+		dtypes = append(dtypes, fmt.Sprintf("%q", columnDtype(c.pkg, c.elems[i])))
+		names = append(names, fmt.Sprintf("%q", nm))
+		cols = append(cols, fmt.Sprintf("_c%d", i))
+	}
+
+	g.pywrap.Printf("def %s(%s):\n", pyname, strings.Join(wpArgs, ", "))
+	g.pywrap.Indent()
+	g.pywrap.Printf(`"""%s"""`, c.docString(pyname))
+	g.pywrap.Printf("\n")
+	for i := 0; i < args.Len(); i++ {
+		g.genLossyCheck(wpArgs[i], current.symtype(args.At(i).Type()), name+" arg "+wpArgs[i])
+	}
+	g.pywrap.Printf("return go.columns(%s, %s, _%s.%s(%s), %q)\n", pyTuple(names), pyTuple(dtypes), g.cfg.Name, cgoFn, strings.Join(wpArgs, ", "), c.shape)
+	g.pywrap.Outdent()
+	g.pywrap.Printf("\n")
+
+	g.gofile.Printf("\n//export %s\n", cgoFn)
+	g.gofile.Printf("func %s(%s) *C.PyObject {\n", cgoFn, strings.Join(goArgs, ", "))
+	g.gofile.Indent()
+	g.gofile.Printf("_saved_thread := C.PyEval_SaveThread()\n")
+	rets := append([]string{}, cols...)
+	if c.err {
+		rets = append(rets, "__err")
+	}
+	g.gofile.Printf("%s := %s.%s(%s)\n", strings.Join(rets, ", "), c.pkg.Name(), name, strings.Join(callArgs, ", "))
+	g.gofile.Printf("C.PyEval_RestoreThread(_saved_thread)\n")
+	if c.err {
+		g.gofile.Printf("if __err != nil {\n")
+		g.gofile.Indent()
+		g.gofile.Printf("estr := C.CString(__err.Error())\n")
+		g.gofile.Printf("gopyRaise(__err, estr)\n")
+		g.gofile.Printf("C.free(unsafe.Pointer(estr))\n")
+		g.gofile.Printf("return nil\n")
+		g.gofile.Outdent()
+		g.gofile.Printf("}\n")
+	}
+	if len(cols) > 1 {
+		var lens []string
+		for _, cn := range cols {
+			lens = append(lens, "len("+cn+")")
+		}
+		g.gofile.Printf("if !gopyColumnsLen(%q, []string{%s}, %s) {\n", c.pkg.Name()+"."+name, strings.Join(names, ", "), strings.Join(lens, ", "))
+		g.gofile.Printf("\treturn nil\n")
+		g.gofile.Printf("}\n")
+	}
+	g.gofile.Printf("_t := C.PyTuple_New(%d)\n", len(cols))
+	for i, cn := range cols {
+		g.gofile.Printf("var _p%d unsafe.Pointer\n", i)
+		g.gofile.Printf("if len(%s) > 0 {\n", cn)
+		g.gofile.Printf("\t_p%d = unsafe.Pointer(&%s[0])\n", i, cn)
+		g.gofile.Printf("}\n")
+		g.gofile.Printf("C.PyTuple_SetItem(_t, %d, gopyColumnPy(_p%[1]d, len(%s), %d))\n", i, cn, c.pkg.sz.Sizeof(c.elems[i]))
+	}
+	g.gofile.Printf("return _t\n")
+	g.gofile.Outdent()
+	g.gofile.Printf("}\n")
+
+	g.pybuild.Printf("add_checked_function(mod, '%s', retval('PyObject*', caller_owns_return=True), [%s])\n", cgoFn, strings.Join(pyArgs, ", "))
+}
@@ -0,0 +1,106 @@
+// Copyright 2024 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package bind
+
+import (
+	"fmt"
+	"go/types"
+)
+
+// The functions and methods returning a value and a bool, in the comma-ok
+// idiom of map lookups, type assertions and caches, return the value to
+// python, and, when the bool is false, either return None or raise
+// KeyError, by the CommaOk policy, "none" by default, which can be set per
+// function or method by a //gopy:commaok directive, e.g.,
+//
+//	// Lookup returns the value of the key, if any.
+//	//gopy:commaok raise
+//	func (c *Cache) Lookup(key string) (int, bool)
+//
+// The KeyError of the raise policy has the first arg, e.g., the key, as in
+// python mappings.
+
+const (
+	commaOkNone  = "none"  // return None when the bool is false
+	commaOkRaise = "raise" // raise KeyError when the bool is false
+)
+
+// CommaOk is the policy of the functions returning a value and a bool, when
+// the bool is false: none or raise
+// -- this must be a global as it is relevant during initial package parsing
+var CommaOk = commaOkNone
+
+// SetCommaOk sets the CommaOk policy, none if empty, returning an error if
+// it is invalid
+func SetCommaOk(policy string) error {
+	if policy == "" {
+		policy = commaOkNone
+	}
+	if policy != commaOkNone && policy != commaOkRaise {
+		return fmt.Errorf("gopy: invalid comma-ok policy %q, must be none or raise", policy)
+	}
+	CommaOk = policy
+	return nil
+}
+
+// isCommaOk returns whether the function returns a value and a bool
+func isCommaOk(sig *types.Signature) bool {
+	res := sig.Results()
+	return res.Len() == 2 && isBoolType(res.At(1).Type())
+}
+
+// commaOkPolicy returns the policy of the named function or method, with
+// the name of methods qualified by their type, if it returns a value and a
+// bool, and "" otherwise
+func (p *Package) commaOkPolicy(name string, sig *types.Signature) string {
+	if !isCommaOk(sig) {
+		return ""
+	}
+	policy, ok := p.directive(name, "commaok")
+	if !ok {
+		return CommaOk
+	}
+	if policy != commaOkNone && policy != commaOkRaise {
+		if !NoWarn {
+			fmt.Printf("gopy: warning: ignoring %scommaok directive of %s.%s: must be none or raise, not %q\n", directivePrefix, p.Name(), name, policy)
+		}
+		return CommaOk
+	}
+	return policy
+}
+
+// commaOkDesc returns the description of the value returned by a function
+// of the comma-ok policy, following that of its Go type
+func commaOkDesc(policy string) string {
+	if policy == commaOkRaise {
+		return ", raising KeyError if the Go ok result is false"
+	}
+	return ", or None if the Go ok result is false"
+}
+
+// genCommaOkGo generates the check of the ok result of the Go call of f,
+// setting a KeyError when it is false, before the return of the zero value
+func (g *pyGen) genCommaOkGo(f *Func) {
+	g.gofile.Printf("if !__ok {\n")
+	g.gofile.Indent()
+	g.gofile.Printf("estr := C.CString(%q)\n", f.pkg.Name()+"."+f.GoName()+": not found")
+	g.gofile.Printf("C.PyErr_SetString(C.PyExc_KeyError, estr)\n")
+}
+
+// genCommaOkExcept generates the except clause of the python call of f,
+// returning None or raising KeyError of its first arg, pyArg, if any
+func (g *pyGen) genCommaOkExcept(f *Func, pyArg string) {
+	g.pywrap.Printf("except KeyError:\n")
+	g.pywrap.Indent()
+	switch {
+	case f.commaOk == commaOkNone:
+		g.pywrap.Printf("return None\n")
+	case pyArg != "":
+		g.pywrap.Printf("raise KeyError(%s) from None\n", pyArg)
+	default:
+		g.pywrap.Printf("raise\n")
+	}
+	g.pywrap.Outdent()
+}
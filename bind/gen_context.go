@@ -0,0 +1,129 @@
+// Copyright 2024 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package bind
+
+import (
+	"go/types"
+)
+
+// context.Context args are passed as handles to a go.Context python object,
+// which supports python-side cancellation and timeouts.  The python wrapper
+// makes these args optional (ctx=None), defaulting to context.Background().
+
+const (
+	// go code for creating and cancelling contexts from python
+	contextGo = `
+// ---- context.Context support for python-side cancellation and timeouts ---
+
+// gopyContext is a context.Context with its cancel function,
+// so that it can be cancelled from python
+type gopyContext struct {
+	context.Context
+	cancel context.CancelFunc
+}
+
+//export Context_Background
+func Context_Background() CGoHandle {
+	return handleFromPtr_context_Context(context.Background())
+}
+
+//export Context_WithCancel
+func Context_WithCancel(parent CGoHandle) CGoHandle {
+	ctx, cancel := context.WithCancel(ptrFromHandle_context_Context(parent))
+	return handleFromPtr_context_Context(&gopyContext{ctx, cancel})
+}
+
+//export Context_WithTimeout
+func Context_WithTimeout(parent CGoHandle, seconds C.double) CGoHandle {
+	ctx, cancel := context.WithTimeout(ptrFromHandle_context_Context(parent), time.Duration(float64(seconds)*float64(time.Second)))
+	return handleFromPtr_context_Context(&gopyContext{ctx, cancel})
+}
+
+//export Context_Cancel
+func Context_Cancel(h CGoHandle) {
+	if ctx, ok := gopyh.VarFromHandle(handleGo(h), "context.Context").(*gopyContext); ok {
+		ctx.cancel()
+	}
+}
+
+//export Context_Err
+func Context_Err(h CGoHandle) *C.char {
+	if err := ptrFromHandle_context_Context(h).Err(); err != nil {
+		return C.CString(err.Error())
+	}
+	return C.CString("")
+}
+`
+
+	// pybindgen stubs for contextGo
+	contextPyBuild = `mod.add_function('Context_Background', retval('%[1]s'), [])
+mod.add_function('Context_WithCancel', retval('%[1]s'), [param('%[1]s', 'parent')])
+mod.add_function('Context_WithTimeout', retval('%[1]s'), [param('%[1]s', 'parent'), param('double', 'seconds')])
+mod.add_function('Context_Cancel', None, [param('%[1]s', 'h')])
+add_checked_string_function(mod, 'Context_Err', retval('char*'), [param('%[1]s', 'h')])
+`
+
+	// python go.Context class, extending the context_Context ext class
+	// 1 = package name, 2 = python value of no handle
+	contextPyWrap = `
+# ---- context.Context support for python-side cancellation and timeouts ---
+class Context(context_Context):
+	"""Context is a Go context.Context, for cancellation and timeouts of Go calls.
+	Pass it as the ctx arg of functions taking a context.Context,
+	which otherwise use context.Background().
+	Used in a with statement, it is cancelled on exit of the block."""
+	@staticmethod
+	def background():
+		"""background returns the Go background context, which is never cancelled"""
+		return Context(handle=_%[1]s.Context_Background())
+	@staticmethod
+	def with_cancel(parent=None):
+		"""with_cancel returns a new context that is done when cancel is called"""
+		return Context(handle=_%[1]s.Context_WithCancel(parent.handle if parent is not None else %[2]s))
+	@staticmethod
+	def with_timeout(seconds, parent=None):
+		"""with_timeout returns a new context that is done after the given number of seconds, or when cancel is called"""
+		return Context(handle=_%[1]s.Context_WithTimeout(parent.handle if parent is not None else %[2]s, seconds))
+	def cancel(self):
+		"""cancel cancels the context, and thus all Go calls using it"""
+		_%[1]s.Context_Cancel(self.handle)
+	def err(self):
+		"""err returns why the context is done, e.g., 'context canceled', or '' if it is not done"""
+		return _%[1]s.Context_Err(self.handle)
+	def __enter__(self):
+		return self
+	def __exit__(self, *exc):
+		self.cancel()
+
+`
+)
+
+// isContextType returns true if the type is context.Context
+func isContextType(typ types.Type) bool {
+	return types.TypeString(typ, nil) == "context.Context"
+}
+
+// usesContext returns true if context.Context is used by the bound packages
+func usesContext() bool {
+	s := current.sym("context.Context")
+	return s != nil && s.isInterface()
+}
+
+// genContextGo generates the go code and pybindgen stubs for go.Context
+func (g *pyGen) genContextGo() {
+	if !usesContext() {
+		return
+	}
+	g.gofile.Printf("%s", contextGo)
+	g.pybuild.Printf(contextPyBuild, PyHandle)
+}
+
+// genContextPyWrap generates the go.Context python class
+func (g *pyGen) genContextPyWrap() {
+	if !usesContext() {
+		return
+	}
+	g.pywrap.Printf(contextPyWrap, g.cfg.Name, curHandle.pyZero)
+}
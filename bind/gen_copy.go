@@ -0,0 +1,52 @@
+// Copyright 2024 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package bind
+
+// copy.copy of a struct copies its Go value, sharing the values of its
+// pointer, slice and map fields, as assigning it in Go does, and
+// copy.deepcopy copies the values of its exported fields recursively,
+// using gopyh.DeepCopy, instead of both duplicating the handle, which
+// would alias the same Go value.
+
+// genStructCopy generates the __copy__ and __deepcopy__ methods of the
+// python class of the struct, and the go functions they call
+func (g *pyGen) genStructCopy(s *Struct) {
+	pkgname := g.cfg.Name
+	cpNm := s.ID() + "_GoPyCopy"
+	dcpNm := s.ID() + "_GoPyDeepCopy"
+
+	g.pywrap.Printf("def __copy__(self):\n")
+	g.pywrap.Indent()
+	g.pywrap.Printf(`"""__copy__ returns a copy of the Go struct, sharing the values of its pointer, slice and map fields"""
+`)
+	g.pywrap.Printf("return self.__class__(handle=_%s.%s(self.handle))\n", pkgname, cpNm)
+	g.pywrap.Outdent()
+
+	g.pywrap.Printf("def __deepcopy__(self, memo):\n")
+	g.pywrap.Indent()
+	g.pywrap.Printf(`"""__deepcopy__ returns a copy of the Go struct, with copies of the values of its exported fields"""
+`)
+	g.pywrap.Printf("return self.__class__(handle=_%s.%s(self.handle))\n", pkgname, dcpNm)
+	g.pywrap.Outdent()
+
+	g.gofile.Printf("//export %s\n", cpNm)
+	g.gofile.Printf("func %s(handle CGoHandle) CGoHandle {\n", cpNm)
+	g.gofile.Indent()
+	g.gofile.Printf("v := *ptrFromHandle_%s(handle)\n", s.ID())
+	g.gofile.Printf("return CGoHandle(handleFromPtr_%s(&v))\n", s.ID())
+	g.gofile.Outdent()
+	g.gofile.Printf("}\n\n")
+
+	g.gofile.Printf("//export %s\n", dcpNm)
+	g.gofile.Printf("func %s(handle CGoHandle) CGoHandle {\n", dcpNm)
+	g.gofile.Indent()
+	g.gofile.Printf("v := gopyh.DeepCopy(*ptrFromHandle_%s(handle)).(%s)\n", s.ID(), s.GoName())
+	g.gofile.Printf("return CGoHandle(handleFromPtr_%s(&v))\n", s.ID())
+	g.gofile.Outdent()
+	g.gofile.Printf("}\n\n")
+
+	g.pybuild.Printf("mod.add_function('%s', retval('%s'), [param('%s', 'handle')])\n", cpNm, PyHandle, PyHandle)
+	g.pybuild.Printf("mod.add_function('%s', retval('%s'), [param('%s', 'handle')])\n", dcpNm, PyHandle, PyHandle)
+}
@@ -0,0 +1,372 @@
+// Copyright 2024 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package bind
+
+import (
+	"fmt"
+	"go/types"
+)
+
+// With the Dataclass option, the plain value structs of the bound packages,
+// having only exported fields of basic types, []rune and other such structs,
+// or pointers to them, and no methods, are bound as python dataclasses
+// instead of classes proxying the Go values by handle: they are copied to
+// and from Go, field by field, at each call, field access and slice or map
+// element access, with no handle to register and release.  The pointers to
+// them are copied too, nil being None, so that Go does not see the changes
+// made by python to the copies, and the other way around.  The Go converters
+// build the dataclasses of the Go values by calling their python classes,
+// registered by the package modules, and read the fields of any python object
+// having them, by name.
+
+// Dataclass turns on the binding of the plain value structs as python
+// dataclasses -- this must be a global as it is relevant during initial
+// package parsing
+var Dataclass = false
+
+const (
+	// C code of the dataclass converters
+	dataclassPreC = `
+static inline PyObject* gopy_none() { // macro
+	Py_INCREF(Py_None);
+	return Py_None;
+}
+static inline int gopy_is_none(PyObject* o) { // macro
+	return o == NULL || o == Py_None;
+}
+`
+
+	// go code of the dataclass converters
+	dataclassGo = `
+// ---- python dataclasses of the Go value structs, with -dataclass ---
+
+// gopyDataclasses are the python dataclasses of the Go value structs, by Go
+// type name, registered by the package modules
+var gopyDataclasses = make(map[string]*C.PyObject)
+
+//export GoPyRegisterDataclass
+func GoPyRegisterDataclass(name *C.char, cls *C.PyObject) {
+	C.gopy_incref(cls)
+	gopyDataclasses[C.GoString(name)] = cls
+}
+
+// gopyDataclassNew returns a new python dataclass of the Go type name, of
+// the field values of the args tuple, which it steals, or nil with a
+// python exception set
+func gopyDataclassNew(name string, args *C.PyObject) *C.PyObject {
+	defer C.gopy_decref(args)
+	cls, ok := gopyDataclasses[name]
+	if !ok {
+		estr := C.CString("gopy: no python dataclass registered for " + name)
+		C.PyErr_SetString(C.PyExc_RuntimeError, estr)
+		C.free(unsafe.Pointer(estr))
+		return nil
+	}
+	return C.PyObject_CallObject(cls, args)
+}
+
+// gopyGetAttr returns a new reference to the attribute of the python object,
+// or nil with an AttributeError set
+func gopyGetAttr(o *C.PyObject, name string) *C.PyObject {
+	cs := C.CString(name)
+	defer C.free(unsafe.Pointer(cs))
+	return C.PyObject_GetAttrString(o, cs)
+}
+`
+
+	// pybindgen stubs for dataclassGo
+	dataclassPyBuild = `mod.add_function('GoPyRegisterDataclass', None, [param('char*', 'name'), param('PyObject*', 'cls', transfer_ownership=False)])
+`
+
+	// python dataclasses support, in go.py
+	dataclassPyWrap = `
+# ---- python dataclasses of the Go value structs, with -dataclass ---
+import dataclasses
+
+`
+)
+
+// dataclassStruct returns whether the named type, of the package being
+// parsed, is a plain value struct bound as a python dataclass: with only
+// exported fields of basic types, []rune, and such structs or pointers to
+// them, and no methods.  The types being checked are seen, so that the
+// recursive types are not dataclasses.
+func (sym *symtab) dataclassStruct(t types.Type, seen map[*types.Named]bool) bool {
+	named, ok := t.(*types.Named)
+	if !ok || !Dataclass || seen[named] || named.Obj().Pkg() != sym.pkg || named.TypeArgs().Len() > 0 {
+		return false
+	}
+	st, ok := named.Underlying().(*types.Struct)
+	if !ok || types.NewMethodSet(types.NewPointer(named)).Len() > 0 {
+		return false
+	}
+	seen[named] = true
+	defer delete(seen, named)
+	for i := 0; i < st.NumFields(); i++ {
+		f := st.Field(i)
+		if !f.Exported() || f.Embedded() || isSkipped(f) {
+			return false
+		}
+		ft := f.Type()
+		if p, ok := ft.(*types.Pointer); ok {
+			ft = p.Elem()
+		} else if b, ok := ft.Underlying().(*types.Basic); ok {
+			if b.Kind() == types.UnsafePointer || b.Info()&types.IsUntyped != 0 {
+				return false
+			}
+			continue
+		} else if types.TypeString(ft, nil) == "[]rune" {
+			continue
+		}
+		if !sym.dataclassStruct(ft, seen) {
+			return false
+		}
+	}
+	return true
+}
+
+// addDataclassType adds the symbol of a struct bound as a python dataclass,
+// passed by value as PyObject*
+func (sym *symtab) addDataclassType(pkg *types.Package, obj types.Object, t types.Type, kind symkind, id, n string) {
+	sym.syms[sym.fullTypeString(t)] = &symbol{
+		gopkg:   pkg,
+		goobj:   obj,
+		gotyp:   t,
+		kind:    kind | skStruct | skBasic,
+		id:      id,
+		goname:  n,
+		cgoname: "*C.PyObject",
+		cpyname: "PyObject*",
+		pysig:   t.(*types.Named).Obj().Name(),
+		go2py:   "dataclassGoToPy_" + id,
+		py2go:   "dataclassPyToGo_" + id,
+		zval:    n + "{}",
+		pyfmt:   "O&",
+	}
+}
+
+// addDataclassPtrType adds the symbol of a pointer to a struct bound as a
+// python dataclass, copied as the dataclass, or None
+func (sym *symtab) addDataclassPtrType(pkg *types.Package, obj types.Object, t types.Type, kind symkind, id, n string, esym *symbol) {
+	sym.syms[sym.fullTypeString(t)] = &symbol{
+		gopkg:   pkg,
+		goobj:   obj,
+		gotyp:   t,
+		kind:    kind | skPointer | skStruct | skBasic,
+		id:      id,
+		goname:  n,
+		cgoname: "*C.PyObject",
+		cpyname: "PyObject*",
+		pysig:   esym.pysig,
+		go2py:   "dataclassGoToPy_" + id,
+		py2go:   "dataclassPyToGo_" + id,
+		zval:    "nil",
+		pyfmt:   "O&",
+	}
+}
+
+// isDataclass returns whether the symbol is that of a struct bound as a
+// python dataclass, or of a pointer to it
+func isDataclass(sym *symbol) bool {
+	return sym != nil && sym.isStruct() && sym.cpyname == "PyObject*"
+}
+
+// usesDataclass returns whether any struct is bound as a python dataclass
+func usesDataclass() bool {
+	if !Dataclass {
+		return false
+	}
+	for _, s := range current.syms {
+		if isDataclass(s) {
+			return true
+		}
+	}
+	return false
+}
+
+// genDataclassGo generates the go code and pybindgen stubs of the dataclass
+// converters
+func (g *pyGen) genDataclassGo() {
+	if !usesDataclass() {
+		return
+	}
+	g.gofile.Printf("%s", dataclassGo)
+	g.pybuild.Printf("%s", dataclassPyBuild)
+}
+
+// genDataclassPyWrap generates the dataclasses support of go.py
+func (g *pyGen) genDataclassPyWrap() {
+	if !usesDataclass() {
+		return
+	}
+	g.pywrap.Printf("%s", dataclassPyWrap)
+}
+
+// genDataclass generates the python dataclass of the struct, and the go
+// converters of the struct and of the pointers to it
+func (g *pyGen) genDataclass(s *Struct) {
+	strNm := s.obj.Name()
+	typ := s.Struct()
+	frozen := ""
+	if s.pkg.isFrozen(strNm) {
+		frozen = "(frozen=True)"
+	}
+
+	g.pywrap.Printf(`
+# Python dataclass for struct %[3]s, copied to and from Go
+@go.dataclasses.dataclass%[5]s
+class %[1]s(%[4]s):
+	""%[2]q""
+`,
+		strNm,
+		s.Doc(),
+		s.GoName(),
+		g.mixinBases(strNm, "object"),
+		frozen,
+	)
+	g.pywrap.Indent()
+	for i := 0; i < typ.NumFields(); i++ {
+		f := typ.Field(i)
+		fsym := current.symtype(f.Type())
+		g.pywrap.Printf("%s: %q = %s\n", g.pyFieldName(s, i), g.dataclassPyType(fsym), g.dataclassPyZero(fsym))
+	}
+	g.pywrap.Outdent()
+	g.pywrap.Printf("\n_%s.GoPyRegisterDataclass(%q, %s)\n\n", g.cfg.Name, s.GoName(), strNm)
+
+	sym := s.sym
+	g.gofile.Printf("\n// %s converts a Go %s to its python dataclass\n", sym.go2py, sym.goname)
+	g.gofile.Printf("func %s(v %s) *C.PyObject {\n", sym.go2py, sym.goname)
+	g.gofile.Indent()
+	g.gofile.Printf("_gstate := C.PyGILState_Ensure()\n")
+	g.gofile.Printf("defer C.PyGILState_Release(_gstate)\n")
+	g.gofile.Printf("_args := C.PyTuple_New(%d)\n", typ.NumFields())
+	for i := 0; i < typ.NumFields(); i++ {
+		f := typ.Field(i)
+		g.gofile.Printf("C.PyTuple_SetItem(_args, %d, %s)\n", i, dataclassFieldGoToPy(current.symtype(f.Type()), "v."+f.Name()))
+	}
+	g.gofile.Printf("return gopyDataclassNew(%q, _args)\n", s.GoName())
+	g.gofile.Outdent()
+	g.gofile.Printf("}\n")
+
+	g.gofile.Printf("\n// %s converts a python %s dataclass, or any object with its fields,\n", sym.py2go, s.GoName())
+	g.gofile.Printf("// to a Go %s, leaving the python exception of invalid values set\n", sym.goname)
+	g.gofile.Printf("func %s(o *C.PyObject) %s {\n", sym.py2go, sym.goname)
+	g.gofile.Indent()
+	g.gofile.Printf("_gstate := C.PyGILState_Ensure()\n")
+	g.gofile.Printf("defer C.PyGILState_Release(_gstate)\n")
+	g.gofile.Printf("var v %s\n", sym.goname)
+	g.gofile.Printf("if o == nil {\n\treturn v\n}\n")
+	for i := 0; i < typ.NumFields(); i++ {
+		f := typ.Field(i)
+		ft := f.Type()
+		g.gofile.Printf("if _f := gopyGetAttr(o, %q); _f != nil {\n", g.pyFieldName(s, i))
+		g.gofile.Indent()
+		g.gofile.Printf("v.%s = %s\n", f.Name(), dataclassFieldPyToGo(current.symtype(ft), current.typeGoName(ft), "_f", strNm+"."+g.pyFieldName(s, i)))
+		g.gofile.Printf("C.gopy_decref(_f)\n")
+		g.gofile.Outdent()
+		g.gofile.Printf("}\n")
+	}
+	g.gofile.Printf("return v\n")
+	g.gofile.Outdent()
+	g.gofile.Printf("}\n")
+
+	g.gofile.Printf("\n// dataclassGoToPy_Ptr_%s converts a Go *%s to a copy of it as python dataclass, or None\n", sym.id, sym.goname)
+	g.gofile.Printf("func dataclassGoToPy_Ptr_%s(p *%s) *C.PyObject {\n", sym.id, sym.goname)
+	g.gofile.Printf("\tif p == nil {\n")
+	g.gofile.Printf("\t\t_gstate := C.PyGILState_Ensure()\n\t\tdefer C.PyGILState_Release(_gstate)\n")
+	g.gofile.Printf("\t\treturn C.gopy_none()\n\t}\n")
+	g.gofile.Printf("\treturn %s(*p)\n}\n", sym.go2py)
+	g.gofile.Printf("\n// dataclassPyToGo_Ptr_%s converts a python %s dataclass, or None, to a new Go *%s\n", sym.id, s.GoName(), sym.goname)
+	g.gofile.Printf("func dataclassPyToGo_Ptr_%s(o *C.PyObject) *%s {\n", sym.id, sym.goname)
+	g.gofile.Printf("\tif C.gopy_is_none(o) != 0 {\n\t\treturn nil\n\t}\n")
+	g.gofile.Printf("\tv := %s(o)\n\treturn &v\n}\n", sym.py2go)
+}
+
+// dataclassPyType returns the python type annotation of a dataclass field
+func (g *pyGen) dataclassPyType(fsym *symbol) string {
+	if isDataclass(fsym) && fsym.isPointer() {
+		return fsym.pysig + " | None"
+	}
+	return fsym.pysig
+}
+
+// dataclassPyZero returns the python default of a dataclass field, its Go
+// zero value
+func (g *pyGen) dataclassPyZero(fsym *symbol) string {
+	switch {
+	case isDataclass(fsym) && fsym.isPointer():
+		return "None"
+	case isDataclass(fsym):
+		return fmt.Sprintf("go.dataclasses.field(default_factory=lambda: %s())", fsym.pyPkgId(g.pkg.pkg, g.pkg))
+	case isRune(fsym):
+		return `'\x00'`
+	case isRunes(fsym):
+		return "''"
+	}
+	switch k := basicKind(fsym); {
+	case k == types.Bool:
+		return "False"
+	case k == types.String:
+		return "''"
+	case k == types.Float32 || k == types.Float64:
+		return "0.0"
+	case k == types.Complex64 || k == types.Complex128:
+		return "0j"
+	}
+	return "0"
+}
+
+// dataclassFieldGoToPy returns the go expression of the new python object
+// of the go expression x of the field symbol
+func dataclassFieldGoToPy(fsym *symbol, x string) string {
+	if fsym.cpyname == "PyObject*" {
+		return fmt.Sprintf("%s(%s)%s", fsym.go2py, x, fsym.go2pyParenEx)
+	}
+	switch k := basicKind(fsym); {
+	case k == types.Bool:
+		return fmt.Sprintf("C.PyBool_FromLong(C.long(boolGoToPy(bool(%s))))", x)
+	case types.Int <= k && k <= types.Int64:
+		return fmt.Sprintf("C.PyLong_FromLongLong(C.longlong(%s))", x)
+	case types.Uint <= k && k <= types.Uintptr:
+		return fmt.Sprintf("C.PyLong_FromUnsignedLongLong(C.ulonglong(%s))", x)
+	case k == types.Float32 || k == types.Float64:
+		return fmt.Sprintf("C.PyFloat_FromDouble(C.double(%s))", x)
+	}
+	return fmt.Sprintf("gopyStrGoToPy(string(%s))", x)
+}
+
+// dataclassFieldPyToGo returns the go expression of the value of the go
+// type gonm, of the field symbol, of the python object o, described by
+// name in the errors
+func dataclassFieldPyToGo(fsym *symbol, gonm, o, name string) string {
+	if fsym.cpyname == "PyObject*" {
+		return fmt.Sprintf("%s(%s)%s", fsym.py2go, o, fsym.py2goParenEx)
+	}
+	switch k := basicKind(fsym); {
+	case k == types.Bool:
+		return fmt.Sprintf("%s(C.PyObject_IsTrue(%s) != 0)", gonm, o)
+	case types.Int <= k && k <= types.Int64:
+		return fmt.Sprintf("%s(gopyIntPyToGo(%s, %d, %q, %q))", gonm, o, basicBits(k), types.Typ[k].Name(), name)
+	case types.Uint <= k && k <= types.Uintptr:
+		return fmt.Sprintf("%s(gopyUintPyToGo(%s, %d, %q, %q))", gonm, o, basicBits(k), types.Typ[k].Name(), name)
+	case k == types.Float32 || k == types.Float64:
+		return fmt.Sprintf("%s(C.PyFloat_AsDouble(%s))", gonm, o)
+	}
+	return fmt.Sprintf("%s(gopyStrPyToGo(%s))", gonm, o)
+}
+
+// basicBits returns the number of bits of the Go int kind, taking int and
+// uint to be 64 bits
+func basicBits(k types.BasicKind) int {
+	switch k {
+	case types.Int8, types.Uint8:
+		return 8
+	case types.Int16, types.Uint16:
+		return 16
+	case types.Int32, types.Uint32:
+		return 32
+	}
+	return 64
+}
@@ -0,0 +1,393 @@
+// Copyright 2024 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package bind
+
+import (
+	"bytes"
+	"fmt"
+	"go/types"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+const (
+	// 1 = name of output package, 2 = extensions
+	docSphinxConf = `# Sphinx configuration for the API reference of %[1]s.
+# File is generated by gopy, and can be edited.
+
+project = %[1]q
+extensions = [%[2]s]
+html_theme = "alabaster"
+exclude_patterns = ["_build"]
+`
+)
+
+// docPage prints an API reference page in reStructuredText (rst),
+// using the Sphinx python domain directives, or in Markdown
+type docPage struct {
+	pr  *printer
+	rst bool
+}
+
+func newDocPage(rst bool) *docPage {
+	return &docPage{pr: &printer{buf: new(bytes.Buffer), indentEach: []byte("   ")}, rst: rst}
+}
+
+// heading prints a section title at the given level, starting at 1
+func (d *docPage) heading(lvl int, title string) {
+	if !d.rst {
+		d.pr.Printf("%s %s\n\n", strings.Repeat("#", lvl), title)
+		return
+	}
+	ul := strings.Repeat(string("=-~^"[lvl-1]), len(title))
+	if lvl == 1 {
+		d.pr.Printf("%s\n", ul)
+	}
+	d.pr.Printf("%s\n%s\n\n", title, ul)
+}
+
+// text prints a paragraph of doc text, if any
+func (d *docPage) text(doc string) {
+	doc = strings.TrimSpace(doc)
+	if doc == "" {
+		return
+	}
+	d.pr.Printf("%s\n\n", doc)
+}
+
+// begin starts the entry of a symbol of the given kind, a Sphinx python
+// domain directive (function, class, method, property, data, attribute or
+// exception), at the given nesting level within a class (0 or 1).
+// Its doc and fields are printed until the matching end.
+func (d *docPage) begin(lvl int, kind, sig string, opts ...string) {
+	if d.rst {
+		d.pr.Printf(".. py:%s:: %s\n", kind, sig)
+		d.pr.Indent()
+		for _, o := range opts {
+			d.pr.Printf("%s\n", o)
+		}
+		d.pr.Printf("\n")
+		return
+	}
+	d.pr.Printf("%s %s `%s`\n\n", strings.Repeat("#", lvl+3), kind, sig)
+	for _, o := range opts {
+		d.pr.Printf("- %s\n", strings.TrimPrefix(o, ":"))
+	}
+	if len(opts) > 0 {
+		d.pr.Printf("\n")
+	}
+}
+
+// trim removes the indentation printed on blank lines
+func (d *docPage) trim() *printer {
+	lns := bytes.Split(d.pr.buf.Bytes(), []byte("\n"))
+	for i, ln := range lns {
+		lns[i] = bytes.TrimRight(ln, " ")
+	}
+	d.pr.buf = bytes.NewBuffer(bytes.Join(lns, []byte("\n")))
+	return d.pr
+}
+
+func (d *docPage) end() {
+	if d.rst {
+		d.pr.Outdent()
+	}
+}
+
+// fields prints the args, return values and raised errors of a function,
+// as Sphinx info fields or Markdown lists
+func (d *docPage) fields(args, rets []docItem, raises bool) {
+	if d.rst {
+		for _, it := range args {
+			d.pr.Printf(":param %s: %s\n", strings.Replace(it.name, "*", `\*`, -1), it.desc)
+			if it.typ != "" {
+				typ := it.typ
+				if it.def != "" {
+					typ += ", optional"
+				}
+				d.pr.Printf(":type %s: %s\n", strings.Replace(it.name, "*", `\*`, -1), typ)
+			}
+		}
+		for _, it := range rets {
+			d.pr.Printf(":returns: %s\n", it.desc)
+			d.pr.Printf(":rtype: %s\n", it.typ)
+		}
+		if raises {
+			d.pr.Printf(":raises %s\n", docRaises)
+		}
+		if len(args) > 0 || len(rets) > 0 || raises {
+			d.pr.Printf("\n")
+		}
+		return
+	}
+	list := func(name string, items []string) {
+		if len(items) == 0 {
+			return
+		}
+		d.pr.Printf("%s:\n\n", name)
+		for _, it := range items {
+			d.pr.Printf("- %s\n", it)
+		}
+		d.pr.Printf("\n")
+	}
+	md := func(items []docItem) []string {
+		strs := make([]string, len(items))
+		for i, it := range items {
+			if it.name != "" {
+				it.name = "`" + it.name + "`"
+			}
+			strs[i] = it.google()
+		}
+		return strs
+	}
+	list("Args", md(args))
+	list("Returns", md(rets))
+	if raises {
+		list("Raises", []string{docRaises})
+	}
+}
+
+// genDocs generates the API reference of the bound packages into the docs/
+// subdirectory of the output, as an index page and a page per package,
+// in reStructuredText for Sphinx (DocFormat rst) or Markdown (md).
+// With DocConf, a Sphinx conf.py is also generated.
+func (g *pyGen) genDocs() {
+	ext := g.cfg.DocFormat
+	if ext != "rst" && ext != "md" {
+		g.err.Add(fmt.Errorf("gopy: invalid doc format %q: must be rst or md", ext))
+		return
+	}
+	rst := ext == "rst"
+
+	dir := filepath.Join(g.cfg.OutputDir, "docs")
+	err := os.MkdirAll(dir, 0755)
+	if err != nil {
+		g.err.Add(err)
+		return
+	}
+
+	var pkgs []*Package
+	for _, p := range Packages {
+		if p != goPackage {
+			pkgs = append(pkgs, p)
+		}
+	}
+
+	idx := newDocPage(rst)
+	idx.heading(1, g.cfg.FullName()+" API reference")
+	idx.text(fmt.Sprintf("Python bindings for Go, generated by gopy:\n%s", g.cfg.Cmd))
+	if rst {
+		idx.pr.Printf(".. toctree::\n")
+		idx.pr.Indent()
+		idx.pr.Printf(":maxdepth: 2\n\n")
+		for _, p := range pkgs {
+			idx.pr.Printf("%s\n", p.Name())
+		}
+		idx.pr.Outdent()
+	} else {
+		for _, p := range pkgs {
+			idx.pr.Printf("- [%[1]s](%[1]s.md)\n", p.Name())
+		}
+	}
+	g.genPrintOut(filepath.Join("docs", "index."+ext), idx.trim())
+
+	for _, p := range pkgs {
+		d := newDocPage(rst)
+		g.genPkgDocs(d, p)
+		g.genPrintOut(filepath.Join("docs", p.Name()+"."+ext), d.trim())
+	}
+
+	if g.cfg.DocConf {
+		exts := `"sphinx.ext.napoleon"`
+		if !rst {
+			exts += `, "myst_parser"`
+		}
+		pr := &printer{buf: new(bytes.Buffer), indentEach: []byte("\t")}
+		pr.Printf(docSphinxConf, g.cfg.Name, exts)
+		g.genPrintOut(filepath.Join("docs", "conf.py"), pr)
+	}
+}
+
+// genPkgDocs generates the API reference page of package p
+func (g *pyGen) genPkgDocs(d *docPage, p *Package) {
+	pn := p.Name()
+	d.heading(1, "Package "+pn)
+	if d.rst {
+		d.pr.Printf(".. py:module:: %s\n\n", pn)
+	}
+	if p.doc != nil {
+		d.text(p.doc.Doc)
+	}
+
+	var consts []*Const
+	for _, c := range p.consts {
+		if isPyCompatVar(c.sym) == nil && !c.sym.isSignature() {
+			consts = append(consts, c)
+		}
+	}
+	if len(consts) > 0 {
+		d.heading(2, "Constants")
+		for _, c := range consts {
+			d.begin(0, "data", c.GoName(), ":value: "+pyConstValue(c))
+			d.text(c.doc)
+			d.end()
+		}
+	}
+
+	var vars []*Var
+	for _, v := range p.vars {
+		if isPyCompatVar(v.sym) == nil && !v.sym.isSignature() {
+			vars = append(vars, v)
+		}
+	}
+	if len(vars) > 0 {
+		d.heading(2, "Variables")
+		d.text("Go package variables are accessed by getter and setter functions.")
+		for _, v := range vars {
+			get, set := v.Name(), "Set_"+v.Name()
+			if g.cfg.RenameCase {
+				get, set = toSnakeCase(get), toSnakeCase(set)
+			}
+			d.begin(0, "function", get+"()")
+			d.text(fmt.Sprintf("%s gets Go variable %s.%s\n\n%s", get, pn, v.Name(), v.doc))
+			d.fields(nil, []docItem{{typ: v.sym.pysig, desc: "Go " + p.goTypeString(v.sym.gotyp)}}, false)
+			d.end()
+			if v.sym.isArray() {
+				continue
+			}
+			d.begin(0, "function", set+"(value)")
+			d.text(fmt.Sprintf("%s sets Go variable %s.%s", set, pn, v.Name()))
+			d.fields([]docItem{{name: "value", typ: v.sym.pysig, desc: "Go " + p.goTypeString(v.sym.gotyp)}}, nil, false)
+			d.end()
+		}
+	}
+
+	if len(p.enums) > 0 {
+		d.heading(2, "Enums")
+		for _, e := range p.enums {
+			base := "(Enum)"
+			if e.IsFlags() {
+				base = "(IntFlag)"
+			}
+			d.begin(0, "class", e.typ.Obj().Name()+base)
+			d.text(e.Doc())
+			e.SortConsts()
+			for _, c := range e.items {
+				d.begin(1, "attribute", c.GoName(), ":value: "+pyConstValue(c))
+				d.text(c.doc)
+				d.end()
+			}
+			d.end()
+		}
+	}
+
+	if len(p.errs) > 0 {
+		d.heading(2, "Exceptions")
+		for _, e := range p.errs {
+			d.begin(0, "exception", e.PyName()+"("+e.pyBases()+")")
+			d.text(fmt.Sprintf("%s is raised for Go errors matching %s.%s\n\n%s", e.PyName(), pn, e.Name(), e.doc))
+			d.end()
+		}
+	}
+
+	if len(p.ifaces) > 0 {
+		d.heading(2, "Interfaces")
+		for _, ifc := range p.ifaces {
+			d.begin(0, "class", ifc.obj.Name())
+			d.text(ifc.Doc())
+			for _, m := range sortedFuncs(ifc.meths) {
+				g.genFuncDocs(d, 1, m)
+			}
+			d.end()
+		}
+	}
+
+	if len(p.structs) > 0 {
+		d.heading(2, "Classes")
+		for _, s := range p.structs {
+			d.begin(0, "class", s.obj.Name()+"(*args, **kwargs)")
+			d.text(s.Doc())
+			if isDataclass(s.sym) {
+				d.text("A python dataclass of the field values, copied to and from Go.")
+			} else {
+				d.text("Args are the field values, in order or by name, or handle=, the handle of an existing Go object.")
+			}
+			typ := s.Struct()
+			for i := 0; i < typ.NumFields(); i++ {
+				f := typ.Field(i)
+				ftyp, err := isPyCompatField(f)
+				if err != nil {
+					continue
+				}
+				gname := g.pyFieldName(s, i)
+				d.begin(1, "property", gname, ":type: "+ftyp.pysig)
+				d.text(p.getDoc(s.obj.Name(), f))
+				d.end()
+			}
+			for _, m := range sortedFuncs(s.meths) {
+				g.genFuncDocs(d, 1, m)
+			}
+			d.end()
+		}
+	}
+
+	var funcs []*Func
+	for _, s := range p.structs {
+		funcs = append(funcs, s.ctors...)
+	}
+	funcs = append(funcs, p.funcs...)
+	if len(funcs) > 0 {
+		d.heading(2, "Functions")
+		for _, f := range sortedFuncs(funcs) {
+			g.genFuncDocs(d, 0, f)
+		}
+	}
+}
+
+// sortedFuncs returns the functions sorted by name, for a stable order
+func sortedFuncs(fs []*Func) []*Func {
+	sfs := append([]*Func(nil), fs...)
+	sort.Slice(sfs, func(i, j int) bool { return sfs[i].GoName() < sfs[j].GoName() })
+	return sfs
+}
+
+// genFuncDocs generates the reference entry of function f, or of method f
+// at nesting level 1
+func (g *pyGen) genFuncDocs(d *docPage, lvl int, f *Func) {
+	fn, ok := g.pyFuncName(f)
+	if !ok {
+		return
+	}
+	sig := f.obj.Type().(*types.Signature)
+	args, rets, raises := f.pkg.docItems(sig, f.commaOk)
+	pyArgs := make([]string, len(args))
+	for i, it := range args {
+		pyArgs[i] = it.pyArg()
+	}
+
+	kind := "function"
+	if lvl > 0 {
+		kind = "method"
+	}
+	d.begin(lvl, kind, fn+"("+strings.Join(pyArgs, ", ")+")")
+	d.text(funcDocBody(f))
+	d.fields(args, rets, raises)
+	d.end()
+}
+
+// funcDocBody returns the Go doc of f, without the python signature and
+// the sections documenting it, that make up the rest of its docstring
+func funcDocBody(f *Func) string {
+	_, doc, _ := extractPythonName(f.GoName(), f.Doc())
+	_, doc = isIfaceHandle(doc)
+	i := strings.Index(doc, "\n")
+	if i < 0 {
+		return ""
+	}
+	sig := f.obj.Type().(*types.Signature)
+	return strings.TrimSpace(strings.TrimSuffix(doc[i+1:], f.pkg.docSections(sig, f.commaOk)))
+}
@@ -0,0 +1,192 @@
+// Copyright 2024 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package bind
+
+import (
+	"go/types"
+)
+
+// With NoTimedelta, time.Duration values are passed as int64 nanoseconds
+// instead of being converted to datetime.timedelta, which only has
+// microseconds, and are returned to python as go.Duration, an int subclass
+// whose arithmetic follows Go: sums, differences and products wrap around
+// as int64, and quotients are truncated toward zero.  Its str is that of
+// Go, e.g., 1h30m0s, and its round and truncate methods call Go.
+
+// NoTimedelta turns off the conversion of time.Duration to datetime.timedelta
+// -- this must be a global as it is relevant during initial package parsing
+var NoTimedelta = false
+
+const (
+	// go code of the go.Duration methods implemented in Go
+	durationGo = `
+// ---- time.Duration support, with -no-timedelta ---
+
+//export Duration_String
+func Duration_String(d C.longlong) *C.char {
+	return C.CString(time.Duration(d).String())
+}
+
+//export Duration_Parse
+func Duration_Parse(s *C.char) C.longlong {
+	d, err := time.ParseDuration(C.GoString(s))
+	if err != nil {
+		estr := C.CString(err.Error())
+		C.PyErr_SetString(C.PyExc_ValueError, estr)
+		C.free(unsafe.Pointer(estr))
+		return 0
+	}
+	return C.longlong(d)
+}
+
+//export Duration_Round
+func Duration_Round(d, m C.longlong) C.longlong {
+	return C.longlong(time.Duration(d).Round(time.Duration(m)))
+}
+
+//export Duration_Truncate
+func Duration_Truncate(d, m C.longlong) C.longlong {
+	return C.longlong(time.Duration(d).Truncate(time.Duration(m)))
+}
+`
+
+	// pybindgen stubs for durationGo
+	durationPyBuild = `add_checked_string_function(mod, 'Duration_String', retval('char*'), [param('int64_t', 'd')])
+add_checked_function(mod, 'Duration_Parse', retval('int64_t'), [param('char*', 's')])
+mod.add_function('Duration_Round', retval('int64_t'), [param('int64_t', 'd'), param('int64_t', 'm')])
+mod.add_function('Duration_Truncate', retval('int64_t'), [param('int64_t', 'd'), param('int64_t', 'm')])
+`
+
+	// python go.Duration class
+	// 1 = package name
+	durationPyWrap = `
+# ---- time.Duration support, with -no-timedelta ---
+def _int64(v):
+	return ((v + (1 << 63)) %% (1 << 64)) - (1 << 63)
+
+def _div64(a, b):
+	q = abs(a) // abs(b)
+	return _int64(q if (a < 0) == (b < 0) else -q)
+
+class Duration(int):
+	"""Duration is a Go time.Duration, an int of nanoseconds, passed to and from Go as is.
+	Its arithmetic follows Go: sums, differences and products with ints are Durations
+	wrapping around as int64, the quotient of two Durations is an int, and the quotients
+	are truncated toward zero, as are the remainders.  str returns its Go string, e.g., 1h30m0s."""
+	def __new__(cls, ns=0):
+		return int.__new__(cls, _int64(int(ns)))
+	@classmethod
+	def parse(cls, s):
+		"""parse returns the Duration of the Go duration string, e.g., '1h30m', as time.ParseDuration,
+		raising ValueError if it is invalid"""
+		return cls(_%[1]s.Duration_Parse(s))
+	@classmethod
+	def from_timedelta(cls, td):
+		"""from_timedelta returns the Duration of the datetime.timedelta"""
+		return cls(((td.days * 86400 + td.seconds) * 1000000 + td.microseconds) * 1000)
+	def to_timedelta(self):
+		"""to_timedelta returns the datetime.timedelta of the Duration, truncated to microseconds"""
+		import datetime
+		return datetime.timedelta(microseconds=_div64(int(self), 1000))
+	def __str__(self):
+		return _%[1]s.Duration_String(self)
+	def __repr__(self):
+		return "go.Duration.parse(%%r)" %% str(self)
+	def __add__(self, other):
+		if not isinstance(other, int):
+			return NotImplemented
+		return Duration(int(self) + other)
+	__radd__ = __add__
+	def __sub__(self, other):
+		if not isinstance(other, int):
+			return NotImplemented
+		return Duration(int(self) - other)
+	def __rsub__(self, other):
+		if not isinstance(other, int):
+			return NotImplemented
+		return Duration(other - int(self))
+	def __mul__(self, other):
+		if not isinstance(other, int):
+			return NotImplemented
+		return Duration(int(self) * other)
+	__rmul__ = __mul__
+	def __truediv__(self, other):
+		if isinstance(other, Duration):
+			return _div64(int(self), int(other))
+		if not isinstance(other, int):
+			return NotImplemented
+		return Duration(_div64(int(self), other))
+	__floordiv__ = __truediv__
+	def __mod__(self, other):
+		if not isinstance(other, int):
+			return NotImplemented
+		return Duration(int(self) - _div64(int(self), int(other)) * int(other))
+	def __neg__(self):
+		return Duration(-int(self))
+	def __pos__(self):
+		return self
+	def __abs__(self):
+		"""__abs__ returns the absolute value of the Duration, as Duration.Abs, the most negative one becoming the largest"""
+		return Duration(min(abs(int(self)), (1 << 63) - 1))
+	def hours(self):
+		"""hours returns the Duration as a float number of hours"""
+		return int(self) / 3600e9
+	def minutes(self):
+		"""minutes returns the Duration as a float number of minutes"""
+		return int(self) / 60e9
+	def seconds(self):
+		"""seconds returns the Duration as a float number of seconds"""
+		return int(self) / 1e9
+	def milliseconds(self):
+		"""milliseconds returns the Duration as an int number of milliseconds, truncated toward zero"""
+		return _div64(int(self), 1000000)
+	def microseconds(self):
+		"""microseconds returns the Duration as an int number of microseconds, truncated toward zero"""
+		return _div64(int(self), 1000)
+	def round(self, m):
+		"""round returns the Duration rounded to the nearest multiple of m, as Duration.Round"""
+		return Duration(_%[1]s.Duration_Round(self, m))
+	def truncate(self, m):
+		"""truncate returns the Duration truncated toward zero to a multiple of m, as Duration.Truncate"""
+		return Duration(_%[1]s.Duration_Truncate(self, m))
+
+Nanosecond = Duration(1)
+Microsecond = Duration(1000)
+Millisecond = Duration(1000000)
+Second = Duration(1000000000)
+Minute = Duration(60000000000)
+Hour = Duration(3600000000000)
+
+`
+)
+
+// usesDuration returns whether time.Duration is bound as go.Duration
+func usesDuration() bool {
+	s := current.sym("time.Duration")
+	return NoTimedelta && s != nil && !s.isConverted()
+}
+
+// isDuration returns whether the symbol is that of time.Duration bound as
+// go.Duration
+func isDuration(sym *symbol) bool {
+	return NoTimedelta && sym != nil && sym.gotyp != nil && types.TypeString(sym.gotyp, nil) == "time.Duration"
+}
+
+// genDurationGo generates the go code and pybindgen stubs for go.Duration
+func (g *pyGen) genDurationGo() {
+	if !usesDuration() {
+		return
+	}
+	g.gofile.Printf("%s", durationGo)
+	g.pybuild.Printf("%s", durationPyBuild)
+}
+
+// genDurationPyWrap generates the go.Duration python class
+func (g *pyGen) genDurationPyWrap() {
+	if !usesDuration() {
+		return
+	}
+	g.pywrap.Printf(durationPyWrap, g.cfg.Name)
+}
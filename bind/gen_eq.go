@@ -0,0 +1,76 @@
+// Copyright 2024 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package bind
+
+import (
+	"go/types"
+)
+
+// Comparable structs have value semantics in python: == compares their Go
+// values with ==, and they hash with gopyh.Hash, consistently, so that they
+// can be used in sets and as dict keys.  As in Go, pointer fields compare
+// the addresses, not the values pointed to.  The other structs keep the
+// identity semantics of python objects.  Note that the hash of a struct
+// changes when its fields are set, as it is computed from its Go value.
+
+// genStructEq generates the __eq__ and __hash__ methods of the python class
+// of a comparable struct, and the go functions they call
+func (g *pyGen) genStructEq(s *Struct) {
+	if !types.Comparable(s.GoType()) {
+		if s.FirstEmbed() != nil {
+			// do not inherit the value semantics of the embedded struct
+			g.pywrap.Printf("__eq__ = go.GoClass.__eq__\n")
+			g.pywrap.Printf("__hash__ = go.GoClass.__hash__\n")
+		}
+		return
+	}
+	pkgname := g.cfg.Name
+	eqNm := s.ID() + "_GoPyEqual"
+	hashNm := s.ID() + "_GoPyHash"
+
+	g.pywrap.Printf("def __eq__(self, other):\n")
+	g.pywrap.Indent()
+	g.pywrap.Printf(`"""__eq__ compares the Go values of the structs with =="""
+`)
+	g.pywrap.Printf("if type(other) is not type(self):\n")
+	g.pywrap.Indent()
+	g.pywrap.Printf("return NotImplemented\n")
+	g.pywrap.Outdent()
+	g.pywrap.Printf("return _%s.%s(self.handle, other.handle)\n", pkgname, eqNm)
+	g.pywrap.Outdent()
+
+	g.pywrap.Printf("def __hash__(self):\n")
+	g.pywrap.Indent()
+	g.pywrap.Printf(`"""__hash__ returns the hash of the Go value of the struct, which changes with its fields"""
+`)
+	g.pywrap.Printf("return _%s.%s(self.handle)\n", pkgname, hashNm)
+	g.pywrap.Outdent()
+
+	g.gofile.Printf("//export %s\n", eqNm)
+	g.gofile.Printf("func %s(a, b CGoHandle) (eq C.char) {\n", eqNm)
+	g.gofile.Indent()
+	g.gofile.Printf("defer func() {\n")
+	g.gofile.Indent()
+	g.gofile.Printf("if recover() != nil { // interface fields with uncomparable values\n")
+	g.gofile.Indent()
+	g.gofile.Printf("eq = boolGoToPy(false)\n")
+	g.gofile.Outdent()
+	g.gofile.Printf("}\n")
+	g.gofile.Outdent()
+	g.gofile.Printf("}()\n")
+	g.gofile.Printf("return boolGoToPy(*ptrFromHandle_%[1]s(a) == *ptrFromHandle_%[1]s(b))\n", s.ID())
+	g.gofile.Outdent()
+	g.gofile.Printf("}\n\n")
+
+	g.gofile.Printf("//export %s\n", hashNm)
+	g.gofile.Printf("func %s(handle CGoHandle) C.longlong {\n", hashNm)
+	g.gofile.Indent()
+	g.gofile.Printf("return C.longlong(gopyh.Hash(*ptrFromHandle_%s(handle)))\n", s.ID())
+	g.gofile.Outdent()
+	g.gofile.Printf("}\n\n")
+
+	g.pybuild.Printf("mod.add_function('%s', retval('bool'), [param('%s', 'a'), param('%s', 'b')])\n", eqNm, PyHandle, PyHandle)
+	g.pybuild.Printf("mod.add_function('%s', retval('int64_t'), [param('%s', 'handle')])\n", hashNm, PyHandle)
+}
@@ -0,0 +1,268 @@
+// Copyright 2024 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package bind
+
+import (
+	"fmt"
+	"go/token"
+	"go/types"
+	"path"
+	"sort"
+	"strings"
+)
+
+// The Go errors can also be raised as the python builtin exceptions fitting
+// them, so that the bindings handle errors natively: the exception class of
+// an error of the package with a //gopy:raises directive, e.g.,
+//
+//	// ErrNotFound is returned for missing keys.
+//	//gopy:raises KeyError
+//	var ErrNotFound = errors.New("not found")
+//
+// also derives from the builtin exception, and the ErrorMap option maps
+// other Go errors, e.g., of the standard library, to the go.GoError
+// subclasses of builtin exceptions named with a Go prefix, e.g.,
+// go.GoFileNotFoundError:
+//
+//	-errors='io/fs.ErrNotExist=FileNotFoundError,*strconv.NumError=ValueError,~timeout=TimeoutError'
+//
+// Each entry maps a sentinel error, by package path and name, matched with
+// errors.Is, or a pointer error type, with a *, matched with errors.As, or
+// a substring of the error messages, with a ~.  The errors of the packages
+// with their own classes are matched first, then the entries, in order,
+// then the errors of contexts, unless mapped by the entries: the
+// context.DeadlineExceeded errors are raised as go.GoTimeoutError, a
+// TimeoutError, and the context.Canceled ones as go.GoCancelledError, a
+// concurrent.futures.CancelledError, also raised by the cancelled futures
+// of go.run.
+
+// pyBuiltinExceptions are the python builtin exceptions that Go errors can
+// be raised as
+var pyBuiltinExceptions = map[string]bool{
+	"ArithmeticError": true, "AttributeError": true, "BrokenPipeError": true,
+	"BufferError": true, "ConnectionAbortedError": true, "ConnectionError": true,
+	"ConnectionRefusedError": true, "ConnectionResetError": true, "EOFError": true,
+	"FileExistsError": true, "FileNotFoundError": true, "IndexError": true,
+	"InterruptedError": true, "IsADirectoryError": true, "KeyError": true,
+	"LookupError": true, "MemoryError": true, "NotADirectoryError": true,
+	"NotImplementedError": true, "OSError": true, "OverflowError": true,
+	"PermissionError": true, "TimeoutError": true, "TypeError": true,
+	"ValueError": true, "ZeroDivisionError": true,
+}
+
+// checkPyBuiltinException returns an error if exc is not one of the python
+// builtin exceptions that Go errors can be raised as
+func checkPyBuiltinException(exc string) error {
+	if pyBuiltinExceptions[exc] {
+		return nil
+	}
+	var names []string
+	for nm := range pyBuiltinExceptions {
+		names = append(names, nm)
+	}
+	sort.Strings(names)
+	return fmt.Errorf("invalid python exception %q, must be one of %s", exc, strings.Join(names, ", "))
+}
+
+// errorMapping maps the Go errors matching an entry of the ErrorMap option
+// to a python builtin exception
+type errorMapping struct {
+	key  string // entry, the name of the python class registered in go
+	path string // package path of the sentinel error or error type
+	name string // name of the sentinel error or error type
+	ptr  bool   // pointer error type, matched with errors.As
+	msg  string // substring of the error messages, if not path and name
+	exc  string // python builtin exception
+	base string // python base class, if not exc
+	// name of the package of the error in the generated go code
+	pkgName string
+}
+
+// contextErrorMaps returns the mappings of the errors of contexts not
+// mapped by those of the ErrorMap option, matched after them
+func contextErrorMaps(maps []*errorMapping) []*errorMapping {
+	ctxMaps := []*errorMapping{
+		{key: "context.DeadlineExceeded", path: "context", name: "DeadlineExceeded", exc: "TimeoutError"},
+		{key: "context.Canceled", path: "context", name: "Canceled", exc: "CancelledError", base: "_futures.CancelledError"},
+	}
+	var add []*errorMapping
+	for _, cm := range ctxMaps {
+		mapped := false
+		for _, m := range maps {
+			mapped = mapped || m.key == cm.key
+		}
+		if !mapped {
+			add = append(add, cm)
+		}
+	}
+	return add
+}
+
+// parseErrorMap returns the mappings of the ErrorMap option
+func parseErrorMap(emap string) ([]*errorMapping, error) {
+	var maps []*errorMapping
+	for _, ent := range strings.Split(emap, ",") {
+		ent = strings.TrimSpace(ent)
+		if ent == "" {
+			continue
+		}
+		goerr, exc, ok := strings.Cut(ent, "=")
+		if !ok {
+			return nil, fmt.Errorf("gopy: invalid error map entry %q, must be goerror=PythonException", ent)
+		}
+		goerr, exc = strings.TrimSpace(goerr), strings.TrimSpace(exc)
+		if err := checkPyBuiltinException(exc); err != nil {
+			return nil, fmt.Errorf("gopy: error map entry %q: %v", ent, err)
+		}
+		m := &errorMapping{key: goerr, exc: exc}
+		switch {
+		case strings.HasPrefix(goerr, "~"):
+			m.msg = goerr[1:]
+		default:
+			m.ptr = strings.HasPrefix(goerr, "*")
+			i := strings.LastIndexByte(goerr, '.')
+			if i < 0 || !token.IsExported(goerr[i+1:]) {
+				return nil, fmt.Errorf("gopy: invalid error map entry %q, must map a pkg/path.Err sentinel error, "+
+					"a *pkg/path.Error type, or a ~message substring", ent)
+			}
+			m.path, m.name = strings.TrimPrefix(goerr[:i], "*"), goerr[i+1:]
+		}
+		maps = append(maps, m)
+	}
+	return maps, nil
+}
+
+// importErrorMap adds the imports of the packages of the errors of the
+// mappings to the generated go code, which must be done before its preamble
+func importErrorMap(maps []*errorMapping) {
+	for _, m := range maps {
+		if m.path == "" {
+			continue
+		}
+		nm := path.Base(m.path)
+		if i := strings.IndexAny(nm, ".-"); i > 0 {
+			nm = nm[:i]
+		}
+		m.pkgName = current.addImport(types.NewPackage(m.path, nm))
+	}
+}
+
+// genErrorMapGo generates the go code matching the errors of the ErrorMap
+// option, and of the errors of contexts, after those of the packages
+func (g *pyGen) genErrorMapGo() {
+	if len(g.errMap) == 0 {
+		return
+	}
+	g.gofile.Printf("\nfunc init() {\n")
+	g.gofile.Indent()
+	for _, m := range g.errMap {
+		switch {
+		case m.msg != "":
+			g.gofile.Printf("gopyAddError(%q, func(err error) bool { return strings.Contains(err.Error(), %q) })\n", m.key, m.msg)
+		case m.ptr:
+			g.gofile.Printf("gopyAddError(%q, func(err error) bool { var e *%s.%s; return errors.As(err, &e) })\n", m.key, m.pkgName, m.name)
+		default:
+			g.gofile.Printf("gopyAddError(%q, func(err error) bool { return errors.Is(err, %s.%s) })\n", m.key, m.pkgName, m.name)
+		}
+	}
+	g.gofile.Outdent()
+	g.gofile.Printf("}\n")
+}
+
+// genErrorMapPyWrap generates the go.GoError subclasses of the builtin
+// exceptions of the ErrorMap option, and of the errors of contexts,
+// registering them for their errors
+func (g *pyGen) genErrorMapPyWrap() {
+	if len(g.errMap) == 0 {
+		return
+	}
+	g.pywrap.Printf("\n# ---- Go errors raised as builtin exceptions ---\n")
+	done := make(map[string]bool)
+	for _, m := range g.errMap {
+		if done[m.exc] {
+			continue
+		}
+		done[m.exc] = true
+		base := m.exc
+		if m.base != "" {
+			base = m.base
+		}
+		g.pywrap.Printf("class Go%s(GoError, %s):\n", m.exc, base)
+		g.pywrap.Indent()
+		g.pywrap.Printf("\"\"\"Go%s is raised for the Go errors mapped to %s\"\"\"\n", m.exc, m.exc)
+		g.pywrap.Printf("pass\n")
+		g.pywrap.Outdent()
+	}
+	for _, m := range g.errMap {
+		g.pywrap.Printf("_%s.GoPyRegisterError(%q, Go%s)\n", g.cfg.Name, m.key, m.exc)
+	}
+	g.pywrap.Printf("\n")
+}
+
+// pyBases returns the python base classes of the exception class of the
+// error, with the builtin exception of its raises directive, if any
+func (e *Error) pyBases() string {
+	exc, ok := e.pkg.directive(e.Name(), "raises")
+	if !ok {
+		return "go.GoError"
+	}
+	if err := checkPyBuiltinException(exc); err != nil {
+		if !NoWarn {
+			fmt.Printf("gopy: warning: ignoring %sraises directive of %s.%s: %v\n", directivePrefix, e.pkg.Name(), e.Name(), err)
+		}
+		return "go.GoError"
+	}
+	return "go.GoError, " + exc
+}
+
+// genErrors generates the python exception classes for the sentinel errors
+// and error types of the package, all subclasses of go.GoError, and the go
+// code to match returned errors against them using errors.Is / errors.As.
+// The python classes are registered with the go side on import.
+// Error types are matched before sentinel errors, as they typically
+// wrap the latter with more specific information.
+func (g *pyGen) genErrors() {
+	if len(g.pkg.errs) == 0 {
+		return
+	}
+	gopkg := g.pkg.Name()
+
+	errs := make([]*Error, 0, len(g.pkg.errs))
+	for _, e := range g.pkg.errs {
+		if !e.isSentinel() {
+			errs = append(errs, e)
+		}
+	}
+	for _, e := range g.pkg.errs {
+		if e.isSentinel() {
+			errs = append(errs, e)
+		}
+	}
+
+	g.gofile.Printf("func init() {\n")
+	g.gofile.Indent()
+	for _, e := range errs {
+		qn := gopkg + "." + e.Name()
+		switch {
+		case e.isSentinel():
+			g.gofile.Printf("gopyAddError(%q, func(err error) bool { return errors.Is(err, %s) })\n", e.ID(), qn)
+		case e.ptr:
+			g.gofile.Printf("gopyAddError(%q, func(err error) bool { var e *%s; return errors.As(err, &e) })\n", e.ID(), qn)
+		default:
+			g.gofile.Printf("gopyAddError(%q, func(err error) bool { var e %s; return errors.As(err, &e) })\n", e.ID(), qn)
+		}
+	}
+	g.gofile.Outdent()
+	g.gofile.Printf("}\n")
+
+	for _, e := range g.pkg.errs {
+		g.pywrap.Printf("class %s(%s):\n", e.PyName(), e.pyBases())
+		g.pywrap.Indent()
+		g.pywrap.Printf("%s\n%s is raised for Go errors matching %s.%s\n%s\n%s\n", `"""`, e.PyName(), gopkg, e.Name(), e.doc, `"""`)
+		g.pywrap.Printf("pass\n")
+		g.pywrap.Outdent()
+		g.pywrap.Printf("_%s.GoPyRegisterError(%q, %s)\n\n", g.cfg.Name, e.ID(), e.PyName())
+	}
+}
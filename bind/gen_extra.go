@@ -0,0 +1,264 @@
+// Copyright 2024 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package bind
+
+import (
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// With the ExtraGo option, the .go files of a directory are copied into the
+// generated main package, so that hand-written shims can bind the few APIs
+// the generator cannot handle, without editing the generated files.  They
+// must be in package main, and can use the generated code, e.g., the
+// handles of the bound types.  Their functions exported to C with an
+// //export line, whose params and results are of the C types of the table
+// below, are bound too, in go and in the bound packages, e.g.,
+//
+//	//export DivMod
+//	func DivMod(a, b C.longlong) *C.PyObject
+//
+// returning a python tuple built with the C API of python.  Files using it
+// include Python.h in their cgo preamble.  An exported function can raise a
+// python exception by setting it and returning, as for the functions of the
+// generated code.
+
+// extraCTypes are the pybindgen types of the C types of the bound functions
+// of the extra Go files
+var extraCTypes = map[string]string{
+	"C.char":      "bool",
+	"C.int":       "int",
+	"C.long":      "long",
+	"C.longlong":  "int64_t",
+	"C.ulonglong": "uint64_t",
+	"C.float":     "float",
+	"C.double":    "double",
+	"*C.char":     "char*",
+	"*C.PyObject": "PyObject*",
+}
+
+// extraCType returns the pybindgen type of the C type of a param or result
+// of a bound function of the extra Go files, including the CGoHandle of the
+// handles, which depends on the Handle option
+func extraCType(expr ast.Expr) (string, bool) {
+	ctyp := types.ExprString(expr)
+	if ctyp == "CGoHandle" {
+		return PyHandle, true
+	}
+	typ, ok := extraCTypes[ctyp]
+	return typ, ok
+}
+
+// extraFunc is a bound function of the extra Go files
+type extraFunc struct {
+	name      string
+	doc       string
+	params    []extraParam
+	ret       string // pybindgen type of the result, if any
+	retHandle bool   // whether the result is a CGoHandle, not to be freed
+}
+
+// extraParam is a param of a bound function of the extra Go files
+type extraParam struct {
+	name string
+	typ  string // pybindgen type
+}
+
+// extraGoFiles returns the paths of the non-test .go files of the directory
+func extraGoFiles(dir string) ([]string, error) {
+	ents, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("gopy: could not read the extra Go files: %v", err)
+	}
+	var files []string
+	for _, e := range ents {
+		nm := e.Name()
+		if e.IsDir() || !strings.HasSuffix(nm, ".go") || strings.HasSuffix(nm, "_test.go") {
+			continue
+		}
+		files = append(files, filepath.Join(dir, nm))
+	}
+	return files, nil
+}
+
+// extraExport returns the name of the //export line of the function, if any
+func extraExport(fd *ast.FuncDecl) string {
+	if fd.Doc == nil || fd.Recv != nil {
+		return ""
+	}
+	for _, c := range fd.Doc.List {
+		if strings.HasPrefix(c.Text, "//export ") {
+			return strings.TrimSpace(strings.TrimPrefix(c.Text, "//export "))
+		}
+	}
+	return ""
+}
+
+// extraFuncOf returns the bound function of the exported function, or why
+// it cannot be bound
+func extraFuncOf(fd *ast.FuncDecl, name string) (extraFunc, error) {
+	ef := extraFunc{name: name}
+	if fd.Doc != nil {
+		var lines []string
+		for _, ln := range strings.Split(fd.Doc.Text(), "\n") {
+			if !strings.HasPrefix(ln, "export ") {
+				lines = append(lines, ln)
+			}
+		}
+		ef.doc = strings.TrimSpace(strings.Join(lines, "\n"))
+	}
+	for _, fld := range fd.Type.Params.List {
+		typ, ok := extraCType(fld.Type)
+		if !ok {
+			return ef, fmt.Errorf("unsupported param type %s", types.ExprString(fld.Type))
+		}
+		if len(fld.Names) == 0 {
+			ef.params = append(ef.params, extraParam{fmt.Sprintf("arg_%d", len(ef.params)), typ})
+		}
+		for _, nm := range fld.Names {
+			ef.params = append(ef.params, extraParam{nm.Name, typ})
+		}
+	}
+	if res := fd.Type.Results; res != nil && res.NumFields() > 0 {
+		if res.NumFields() > 1 {
+			return ef, fmt.Errorf("more than one result")
+		}
+		typ, ok := extraCType(res.List[0].Type)
+		if !ok {
+			return ef, fmt.Errorf("unsupported result type %s", types.ExprString(res.List[0].Type))
+		}
+		ef.ret = typ
+		ef.retHandle = types.ExprString(res.List[0].Type) == "CGoHandle"
+	}
+	return ef, nil
+}
+
+// genExtraGo copies the extra Go files into the output directory and
+// generates the pybindgen stubs of their bound functions
+func (g *pyGen) genExtraGo() {
+	if g.cfg.ExtraGo == "" {
+		return
+	}
+	files, err := extraGoFiles(g.cfg.ExtraGo)
+	if err != nil {
+		g.err.Add(err)
+		return
+	}
+	fset := token.NewFileSet()
+	for _, fn := range files {
+		base := filepath.Base(fn)
+		if base == g.cfg.Name+".go" {
+			g.err.Add(fmt.Errorf("gopy: extra Go file %s has the name of the generated file", fn))
+			continue
+		}
+		src, err := os.ReadFile(fn)
+		if err != nil {
+			g.err.Add(err)
+			continue
+		}
+		f, err := parser.ParseFile(fset, fn, src, parser.ParseComments)
+		if err != nil {
+			g.err.Add(fmt.Errorf("gopy: invalid extra Go file: %v", err))
+			continue
+		}
+		if f.Name.Name != "main" {
+			g.err.Add(fmt.Errorf("gopy: extra Go file %s is in package %s, not main", fn, f.Name.Name))
+			continue
+		}
+		g.err.Add(os.WriteFile(filepath.Join(g.cfg.OutputDir, base), src, 0644))
+
+		for _, decl := range f.Decls {
+			fd, ok := decl.(*ast.FuncDecl)
+			if !ok {
+				continue
+			}
+			name := extraExport(fd)
+			if name == "" {
+				continue
+			}
+			ef, err := extraFuncOf(fd, name)
+			if err != nil {
+				if !NoWarn {
+					fmt.Printf("gopy: warning: not binding %s of extra Go file %s: %v\n", name, base, err)
+				}
+				continue
+			}
+			g.extraFuncs = append(g.extraFuncs, ef)
+			g.genExtraPyBuild(ef)
+		}
+	}
+}
+
+// genExtraPyBuild generates the pybindgen stub of the extra function
+func (g *pyGen) genExtraPyBuild(ef extraFunc) {
+	addFuncName := "add_checked_function"
+	ret := "None"
+	switch ef.ret {
+	case "":
+	case "char*":
+		if !ef.retHandle { // the C strings of the handles are kept by go
+			addFuncName = "add_checked_string_function"
+		}
+		ret = "retval('char*')"
+	case "PyObject*":
+		ret = "retval('PyObject*', caller_owns_return=True)"
+	default:
+		ret = fmt.Sprintf("retval('%s')", ef.ret)
+	}
+	var params []string
+	for _, p := range ef.params {
+		if p.typ == "PyObject*" {
+			params = append(params, fmt.Sprintf("param('%s', '%s', transfer_ownership=False)", p.typ, p.name))
+		} else {
+			params = append(params, fmt.Sprintf("param('%s', '%s')", p.typ, p.name))
+		}
+	}
+	g.pybuild.Printf("%s(mod, '%s', %s, [%s])\n", addFuncName, ef.name, ret, strings.Join(params, ", "))
+}
+
+// genExtraPyWrap generates the python functions of the extra functions in go
+func (g *pyGen) genExtraPyWrap() {
+	if len(g.extraFuncs) == 0 {
+		return
+	}
+	g.pywrap.Printf("\n# ---- Functions of the extra Go files ---\n")
+	for _, ef := range g.extraFuncs {
+		var params []string
+		for _, p := range ef.params {
+			params = append(params, p.name)
+		}
+		args := strings.Join(params, ", ")
+		g.pywrap.Printf("def %s(%s):\n", ef.name, args)
+		g.pywrap.Indent()
+		if ef.doc != "" {
+			g.pywrap.Printf("\"\"\"%s\"\"\"\n", strings.ReplaceAll(ef.doc, `"""`, `\"\"\"`))
+		}
+		g.pywrap.Printf("return _%s.%s(%s)\n", g.cfg.Name, ef.name, args)
+		g.pywrap.Outdent()
+		g.pywrap.Printf("\n")
+	}
+}
+
+// genExtraAlias makes the extra functions of go available in the package,
+// except those with the name of a function of the package
+func (g *pyGen) genExtraAlias() {
+	names := make(map[string]bool)
+	for _, f := range g.pkg.funcs {
+		if fn, ok := g.pyFuncName(f); ok {
+			names[fn] = true
+		}
+	}
+	for _, ef := range g.extraFuncs {
+		if !names[ef.name] {
+			g.pywrap.Printf("%[1]s = go.%[1]s\n", ef.name)
+		}
+	}
+}
@@ -0,0 +1,52 @@
+// Copyright 2024 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package bind
+
+import (
+	"fmt"
+	"regexp"
+)
+
+// The frozen structs, whose doc comment has a //gopy:frozen line, or whose
+// qualified name, e.g., "hi.Point", matches the FrozenTypes regexp, are
+// immutable after construction: their python classes set the fields only
+// in __init__, have no property setters, raising AttributeError on
+// assignment, and no update method, and their dataclasses are frozen.  As
+// with //gopy:threadsafe, their fields and methods are not thread checked,
+// so that python threads can share them without locks -- the methods of
+// frozen structs are expected not to modify them.
+
+// FrozenTypes, if set, makes the structs of the qualified names matching it
+// frozen, like IncludeSyms and ExcludeSyms.
+var FrozenTypes *regexp.Regexp
+
+// SetFrozen sets FrozenTypes from the regexp, with an empty one not
+// matching any struct.
+func SetFrozen(re string) error {
+	FrozenTypes = nil
+	if re == "" {
+		return nil
+	}
+	var err error
+	FrozenTypes, err = regexp.Compile(re)
+	if err != nil {
+		return fmt.Errorf("gopy: invalid frozen regexp: %v", err)
+	}
+	return nil
+}
+
+// isFrozen returns whether the named struct of the package is frozen
+func (p *Package) isFrozen(name string) bool {
+	if _, ok := p.directive(name, "frozen"); ok {
+		return true
+	}
+	return FrozenTypes != nil && FrozenTypes.MatchString(p.Name()+"."+name)
+}
+
+// frozenSetter returns the name of the method setting the field of the
+// python name in __init__ of a frozen struct
+func frozenSetter(gname string) string {
+	return "_set_" + gname
+}
@@ -51,6 +51,9 @@ func (g *pyGen) genFuncSig(sym *symbol, fsym *Func) bool {
 	if err != nil {
 		return false
 	}
+	if fsym.pyname != "" {
+		gname = fsym.pyname
+	}
 	ifchandle, gdoc := isIfaceHandle(gdoc)
 
 	sig := fsym.sig
@@ -63,14 +66,15 @@ func (g *pyGen) genFuncSig(sym *symbol, fsym *Func) bool {
 	if nres > 2 {
 		return false
 	}
-	if nres == 2 && !fsym.err {
+	if nres == 2 && !fsym.err && fsym.commaOk == "" {
 		return false
 	}
 
 	var (
-		goArgs []string
-		pyArgs []string
-		wpArgs []string
+		goArgs  []string
+		pyArgs  []string
+		wpArgs  []string
+		ctxArgs []string // context.Context args are optional, so go last
 	)
 
 	if isMethod {
@@ -87,12 +91,17 @@ func (g *pyGen) genFuncSig(sym *symbol, fsym *Func) bool {
 			return false
 		}
 		anm := pySafeArg(arg.Name(), i)
+		gnm := goSafeArg(arg.Name(), i)
 
-		if ifchandle && arg.sym.goname == "interface{}" {
-			goArgs = append(goArgs, fmt.Sprintf("%s %s", anm, CGoHandle))
+		switch {
+		case fsym.borrow[i]:
+			goArgs = append(goArgs, fmt.Sprintf("%s *C.PyObject", gnm))
+			pyArgs = append(pyArgs, fmt.Sprintf("param('PyObject*', '%s', transfer_ownership=False)", anm))
+		case ifchandle && arg.sym.goname == "interface{}":
+			goArgs = append(goArgs, gnm+" CGoHandle")
 			pyArgs = append(pyArgs, fmt.Sprintf("param('%s', '%s')", PyHandle, anm))
-		} else {
-			goArgs = append(goArgs, fmt.Sprintf("%s %s", anm, sarg.cgoname))
+		default:
+			goArgs = append(goArgs, fmt.Sprintf("%s %s", gnm, sarg.cgoname))
 			if sarg.cpyname == "PyObject*" {
 				pyArgs = append(pyArgs, fmt.Sprintf("param('%s', '%s', transfer_ownership=False)", sarg.cpyname, anm))
 			} else {
@@ -100,10 +109,14 @@ func (g *pyGen) genFuncSig(sym *symbol, fsym *Func) bool {
 			}
 		}
 
-		if i != nargs-1 || !fsym.isVariadic {
+		switch {
+		case isContextType(arg.GoType()):
+			ctxArgs = append(ctxArgs, anm+"=None")
+		case i != nargs-1 || !fsym.isVariadic:
 			wpArgs = append(wpArgs, anm)
 		}
 	}
+	wpArgs = append(wpArgs, ctxArgs...)
 
 	// support for optional arg to run in a separate go routine -- only if no return val
 	if nres == 0 {
@@ -116,6 +129,7 @@ func (g *pyGen) genFuncSig(sym *symbol, fsym *Func) bool {
 	if fsym.isVariadic {
 		wpArgs = append(wpArgs, "*args")
 	}
+	g.pyParams = wpArgs
 
 	// When building the pybindgen builder code, we start with
 	// a function that adds function calls with exception checking.
@@ -143,6 +157,7 @@ func (g *pyGen) genFuncSig(sym *symbol, fsym *Func) bool {
 
 		g.pybuild.Printf("%s(mod, '%s', ", addFuncName, mnm)
 
+		g.genThreadGuard(sym, fsym.GoName())
 		g.pywrap.Printf("def %s(", gname)
 	default:
 		g.gofile.Printf("\n//export %s\n", fsym.ID())
@@ -198,13 +213,42 @@ func (g *pyGen) genFuncSig(sym *symbol, fsym *Func) bool {
 func (g *pyGen) genFunc(o *Func) {
 	if g.genFuncSig(nil, o) {
 		g.genFuncBody(nil, o)
+		g.genAsyncTwin(nil, o)
+	}
+}
+
+// pyFuncName returns the python name of the wrapped function f,
+// and false if f cannot be wrapped
+func (g *pyGen) pyFuncName(f *Func) (string, bool) {
+	if f.sig == nil {
+		return "", false
 	}
+	nres := len(f.sig.Results())
+	if nres > 2 || (nres == 2 && !f.err && f.commaOk == "") {
+		return "", false
+	}
+	fn := f.GoName()
+	if g.cfg.RenameCase {
+		fn = toSnakeCase(fn)
+	}
+	fn, _, err := extractPythonName(fn, f.Doc())
+	if err != nil {
+		return "", false
+	}
+	if f.pyname != "" {
+		fn = f.pyname
+	}
+	return fn, true
 }
 
-func (g *pyGen) genMethod(s *symbol, o *Func) {
-	if g.genFuncSig(s, o) {
-		g.genFuncBody(s, o)
+// genMethod generates the method, returning whether it could be generated
+func (g *pyGen) genMethod(s *symbol, o *Func) bool {
+	if !g.genFuncSig(s, o) {
+		return false
 	}
+	g.genFuncBody(s, o)
+	g.genAsyncTwin(s, o)
+	return true
 }
 
 func isIfaceHandle(gdoc string) (bool, string) {
@@ -216,6 +260,54 @@ func isIfaceHandle(gdoc string) (bool, string) {
 	return false, gdoc
 }
 
+// genZeroReturn generates the return of the zero value of the results of
+// a function, e.g., with a python exception set
+func (g *pyGen) genZeroReturn(res []*Var) {
+	if len(res) == 0 {
+		g.gofile.Printf("return\n")
+		return
+	}
+	ret := res[0]
+	if ret.sym.zval == "" {
+		fmt.Printf("gopy: programmer error: empty zval zero value in symbol: %v\n", ret.sym)
+	}
+	if ret.sym.go2py != "" {
+		g.gofile.Printf("return %s(%s)%s\n", ret.sym.go2py, ret.sym.zval, ret.sym.go2pyParenEx)
+	} else {
+		g.gofile.Printf("return %s\n", ret.sym.zval)
+	}
+}
+
+// isConvertedArg returns whether the args of the symbol are converted from
+// python values by a converter that can fail, raising a python exception
+func isConvertedArg(sym *symbol) bool {
+	return sym.isConverted() && !sym.isSignature() && sym.py2go != ""
+}
+
+// genConvertedArgs generates the conversions of the converted args of the
+// function, returning the zero value without calling it if one fails, with
+// the GIL held
+func (g *pyGen) genConvertedArgs(fsym *Func) {
+	args := fsym.sig.Params()
+	n := 0
+	for i, arg := range args {
+		if fsym.borrow[i] || !isConvertedArg(arg.sym) {
+			continue
+		}
+		gnm := goSafeArg(arg.Name(), i)
+		g.gofile.Printf("_cvt_%s := %s(%s)%s\n", gnm, arg.sym.py2go, gnm, arg.sym.py2goParenEx)
+		n++
+	}
+	if n == 0 {
+		return
+	}
+	g.gofile.Printf("if C.PyErr_Occurred() != nil {\n")
+	g.gofile.Indent()
+	g.genZeroReturn(fsym.sig.Results())
+	g.gofile.Outdent()
+	g.gofile.Printf("}\n")
+}
+
 func (g *pyGen) genFuncBody(sym *symbol, fsym *Func) {
 	isMethod := (sym != nil)
 	isIface := false
@@ -246,21 +338,40 @@ func (g *pyGen) genFuncBody(sym *symbol, fsym *Func) {
 		}
 	}
 
+	// constructors returning a pointer register the handle under the
+	// struct type, the same as handles made by the python class ctor
+	retGo2py := ""
+	if nres > 0 {
+		ret := res[0]
+		retGo2py = ret.sym.go2py
+		if ptr, ok := ret.GoType().(*types.Pointer); ok && fsym.ctor {
+			if esym := current.symtype(ptr.Elem()); esym != nil && esym.isStruct() && !isDataclass(esym) {
+				retGo2py = esym.go2py
+			}
+		}
+	}
+
+	rvIsAdapted := nres > 0 && !rvIsErr && res[0].sym.hasHandle() && isAdapted(res[0].sym)
+
 	g.pywrap.Printf(":\n")
 	g.pywrap.Indent()
 	g.pywrap.Printf(`"""%s"""`, gdoc)
 	g.pywrap.Printf("\n")
+	g.genArgChecks(fsym)
 
 	g.gofile.Printf(" {\n")
 	g.gofile.Indent()
 	if fsym.hasfun {
 		for i, arg := range args {
 			if arg.sym.isSignature() {
-				g.gofile.Printf("_fun_arg := %s\n", pySafeArg(arg.Name(), i))
+				g.gofile.Printf("_fun_arg := %s\n", goSafeArg(arg.Name(), i))
 			}
 		}
 	}
 
+	g.genBorrowArgs(fsym)
+	g.genConvertedArgs(fsym)
+
 	// release GIL
 	g.gofile.Printf("_saved_thread := C.PyEval_SaveThread()\n")
 	if !rvIsErr && nres != 2 {
@@ -270,28 +381,28 @@ func (g *pyGen) genFuncBody(sym *symbol, fsym *Func) {
 
 	if isMethod {
 		g.gofile.Printf(
-			`vifc, __err := gopyh.VarFromHandleTry((gopyh.CGoHandle)(_handle), "%s")
+			`vifc, __err := gopyh.VarFromHandleTry(handleGo(_handle), "%s")
 if __err != nil {
 `, symNm)
 		g.gofile.Indent()
-		if nres > 0 {
-			ret := res[0]
-			if ret.sym.zval == "" {
-				fmt.Printf("gopy: programmer error: empty zval zero value in symbol: %v\n", ret.sym)
-			}
-			if ret.sym.go2py != "" {
-				g.gofile.Printf("return %s(%s)%s\n", ret.sym.go2py, ret.sym.zval, ret.sym.go2pyParenEx)
-			} else {
-				g.gofile.Printf("return %s\n", ret.sym.zval)
-			}
-		} else {
-			g.gofile.Printf("return\n")
-		}
+		g.genZeroReturn(res)
 		g.gofile.Outdent()
 		g.gofile.Printf("}\n")
 	} else if rvIsErr {
 		g.gofile.Printf("var __err error\n")
 	}
+	var goHookArgs []string
+	for i, arg := range args {
+		goHookArgs = append(goHookArgs, goSafeArg(arg.Name(), i))
+	}
+	g.genHooks(g.gofile, "go", "before", fsym, goHookArgs)
+	if g.hasHooks("go", "after", fsym) {
+		g.gofile.Printf("defer func() {\n")
+		g.gofile.Indent()
+		g.genHooks(g.gofile, "go", "after", fsym, goHookArgs)
+		g.gofile.Outdent()
+		g.gofile.Printf("}()\n")
+	}
 
 	callArgs := []string{}
 	wrapArgs := []string{}
@@ -301,15 +412,20 @@ if __err != nil {
 	for i, arg := range args {
 		na := ""
 		anm := pySafeArg(arg.Name(), i)
+		gnm := goSafeArg(arg.Name(), i)
 		switch {
+		case fsym.borrow[i]:
+			na = "_borrow_" + gnm + ".s"
 		case ifchandle && arg.sym.goname == "interface{}":
-			na = fmt.Sprintf(`gopyh.VarFromHandle((gopyh.CGoHandle)(%s), "interface{}")`, anm)
+			na = fmt.Sprintf(`gopyh.VarFromHandle(handleGo(%s), "interface{}")`, gnm)
 		case arg.sym.isSignature():
 			na = fmt.Sprintf("%s", arg.sym.py2go)
+		case isConvertedArg(arg.sym):
+			na = "_cvt_" + gnm
 		case arg.sym.py2go != "":
-			na = fmt.Sprintf("%s(%s)%s", arg.sym.py2go, anm, arg.sym.py2goParenEx)
+			na = fmt.Sprintf("%s(%s)%s", arg.sym.py2go, gnm, arg.sym.py2goParenEx)
 		default:
-			na = anm
+			na = gnm
 		}
 		if i == len(args)-1 && fsym.isVariadic {
 			na = na + "..."
@@ -322,6 +438,8 @@ if __err != nil {
 			} else {
 				wrapArgs = append(wrapArgs, anm)
 			}
+		case isContextType(arg.GoType()):
+			wrapArgs = append(wrapArgs, fmt.Sprintf("%[1]s.handle if %[1]s is not None else %[2]s", anm, curHandle.pyZero))
 		case arg.sym.hasHandle():
 			wrapArgs = append(wrapArgs, fmt.Sprintf("%s.handle", anm))
 		default:
@@ -329,27 +447,61 @@ if __err != nil {
 		}
 
 		// To support variadic args, we add *args at the end.
-		if fsym.isVariadic && i == len(args)-1 {
+		if fsym.isVariadic && i == len(args)-1 && isRunes(arg.sym) {
+			g.pywrap.Printf("%s = ''.join(args)\n", anm)
+		} else if fsym.isVariadic && i == len(args)-1 {
 			packagePrefix := ""
 			if arg.sym.gopkg.Name() != fsym.pkg.Name() {
 				packagePrefix = arg.sym.gopkg.Name() + "."
 			}
 			g.pywrap.Printf("%s = %s%s(args)\n", anm, packagePrefix, arg.sym.id)
+		} else {
+			g.genLossyCheck(anm, arg.sym, fsym.GoName()+" arg "+anm)
+			g.genContainerConv(anm, arg.sym, g.pkg.pkg)
+			g.genIOConv(anm, arg.sym)
 		}
 	}
 
+	var pyHookArgs []string
+	for i := range args {
+		pyHookArgs = append(pyHookArgs, pySafeArg(args[i].Name(), i))
+	}
+	g.genHooks(g.pywrap, "py", "before", fsym, pyHookArgs)
+	pyAfter := g.hasHooks("py", "after", fsym)
+	if pyAfter {
+		g.pywrap.Printf("try:\n")
+		g.pywrap.Indent()
+	}
+
 	// pywrap output
 	mnm := fsym.ID()
 	if isMethod {
 		mnm = sym.id + "_" + fsym.GoName()
 	}
-	rvHasHandle := false
+	rvIsWrapped := false // whether the result is wrapped in a python class
+	iterArgs := ""
+	if fsym.commaOk != "" {
+		g.pywrap.Printf("try:\n")
+		g.pywrap.Indent()
+	}
 	if nres > 0 {
 		ret := res[0]
-		if !rvIsErr && ret.sym.hasHandle() {
-			rvHasHandle = true
-			cvnm := ret.sym.pyPkgId(g.pkg.pkg)
+		if ret.sym.isIter() {
+			iterArgs = g.iterPyArgs(ret.sym)
+			g.pywrap.Printf("return go.GoIter(_%s.%s(", pkgname, mnm)
+		} else if !rvIsErr && ret.sym.hasHandle() {
+			rvIsWrapped = true
+			cvnm := ret.sym.pyPkgId(g.pkg.pkg, g.pkg)
+			if rvIsAdapted {
+				cvnm = "go.adapt(" + cvnm
+			}
 			g.pywrap.Printf("return %s(handle=_%s.%s(", cvnm, pkgname, mnm)
+		} else if e := g.flagsEnum(ret.sym); !rvIsErr && e != nil {
+			rvIsWrapped = true
+			g.pywrap.Printf("return %s(_%s.%s(", e.typ.Obj().Name(), pkgname, mnm)
+		} else if !rvIsErr && isDuration(ret.sym) {
+			rvIsWrapped = true
+			g.pywrap.Printf("return go.Duration(_%s.%s(", pkgname, mnm)
 		} else {
 			g.pywrap.Printf("return _%s.%s(", pkgname, mnm)
 		}
@@ -364,6 +516,8 @@ if __err != nil {
 		switch {
 		case rvIsErr:
 			g.gofile.Printf("__err = ")
+		case nres == 2 && fsym.commaOk != "":
+			g.gofile.Printf("cret, __ok := ")
 		case nres == 2:
 			g.gofile.Printf("cret, __err := ")
 		case ret.sym.hasHandle() && !ret.sym.isPtrOrIface():
@@ -371,7 +525,7 @@ if __err != nil {
 			g.gofile.Printf("cret := ")
 		case ret.sym.go2py != "":
 			hasRetCvt = true
-			g.gofile.Printf("return %s(", ret.sym.go2py)
+			g.gofile.Printf("return %s(", retGo2py)
 		default:
 			g.gofile.Printf("return ")
 		}
@@ -380,9 +534,15 @@ if __err != nil {
 		wrapArgs = append(wrapArgs, "goRun")
 	}
 	g.pywrap.Printf("%s)", strings.Join(wrapArgs, ", "))
-	if rvHasHandle {
+	if rvIsWrapped {
 		g.pywrap.Printf(")")
 	}
+	if rvIsAdapted {
+		g.pywrap.Printf(")")
+	}
+	if iterArgs != "" {
+		g.pywrap.Printf(", %s)", iterArgs)
+	}
 
 	funCall := ""
 	if isMethod {
@@ -402,6 +562,7 @@ if __err != nil {
 	if nres == 0 {
 		g.gofile.Printf("if boolPyToGo(goRun) {\n")
 		g.gofile.Indent()
+		g.genBorrowKeep(fsym)
 		g.gofile.Printf("go %s\n", funCall)
 		g.gofile.Outdent()
 		g.gofile.Printf("} else {\n")
@@ -418,10 +579,14 @@ if __err != nil {
 		// reacquire GIL
 		g.gofile.Printf("C.PyEval_RestoreThread(_saved_thread)\n")
 
-		g.gofile.Printf("if __err != nil {\n")
-		g.gofile.Indent()
-		g.gofile.Printf("estr := C.CString(__err.Error())\n")
-		g.gofile.Printf("C.PyErr_SetString(C.PyExc_RuntimeError, estr)\n")
+		if fsym.commaOk != "" {
+			g.genCommaOkGo(fsym)
+		} else {
+			g.gofile.Printf("if __err != nil {\n")
+			g.gofile.Indent()
+			g.gofile.Printf("estr := C.CString(__err.Error())\n")
+			g.gofile.Printf("gopyRaise(__err, estr)\n")
+		}
 		if rvIsErr {
 			g.gofile.Printf("return estr\n") // NOTE: leaked string
 		} else {
@@ -431,7 +596,7 @@ if __err != nil {
 				fmt.Printf("gopy: programmer error: empty zval zero value in symbol: %v\n", ret.sym)
 			}
 			if ret.sym.go2py != "" {
-				g.gofile.Printf("return %s(%s)%s\n", ret.sym.go2py, ret.sym.zval, ret.sym.go2pyParenEx)
+				g.gofile.Printf("return %s(%s)%s\n", retGo2py, ret.sym.zval, ret.sym.go2pyParenEx)
 			} else {
 				g.gofile.Printf("return %s\n", ret.sym.zval)
 			}
@@ -446,7 +611,7 @@ if __err != nil {
 				if ret.sym.hasHandle() && !ret.sym.isPtrOrIface() {
 					g.gofile.Printf("return %s(&cret)%s", ret.sym.go2py, ret.sym.go2pyParenEx)
 				} else {
-					g.gofile.Printf("return %s(cret)%s", ret.sym.go2py, ret.sym.go2pyParenEx)
+					g.gofile.Printf("return %s(cret)%s", retGo2py, ret.sym.go2pyParenEx)
 				}
 			} else {
 				g.gofile.Printf("return cret")
@@ -461,5 +626,20 @@ if __err != nil {
 	g.gofile.Printf("}\n")
 
 	g.pywrap.Printf("\n")
+	if fsym.commaOk != "" {
+		g.pywrap.Outdent()
+		pyArg := ""
+		if len(args) > 0 && !(fsym.isVariadic && len(args) == 1) && !isContextType(args[0].GoType()) {
+			pyArg = pySafeArg(args[0].Name(), 0)
+		}
+		g.genCommaOkExcept(fsym, pyArg)
+	}
+	if pyAfter {
+		g.pywrap.Outdent()
+		g.pywrap.Printf("finally:\n")
+		g.pywrap.Indent()
+		g.genHooks(g.pywrap, "py", "after", fsym, pyHookArgs)
+		g.pywrap.Outdent()
+	}
 	g.pywrap.Outdent()
 }
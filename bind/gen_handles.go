@@ -0,0 +1,150 @@
+// Copyright 2024 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package bind
+
+// go.set_handle_limit calls a python callable when the number of Go handles
+// in use exceeds a limit, warning with go.HandleLimitWarning by default, so
+// that services embedding the bindings can alert on leaking handles before
+// running out of memory, and go.handle_pressure calls a function many times
+// to test whether it leaks handles.  The GOPY_HANDLE_LIMIT environment
+// variable sets an initial limit, warning on stderr, see gopyh.SetHandleLimit.
+// go.num_handles, go.handle_stats and go.dump_handles report the handles in
+// use, by Go type or one by one, to find the leaking ones.  With the
+// HandleShards option, the handle registry is sharded, so that the python
+// threads calling into Go simultaneously do not contend for a single lock.
+
+const (
+	// go code for calling a python callable above the handle limit
+	handlesGo = `
+// ---- set_handle_limit support for alerting on leaking handles ---
+
+// gopyHandleLimitFn is the python callable of the handle limit
+var gopyHandleLimitFn *C.PyObject
+
+//export GoPySetHandleLimit
+func GoPySetHandleLimit(limit C.longlong, fn *C.PyObject) {
+	C.gopy_incref(fn)
+	prev := gopyHandleLimitFn
+	gopyHandleLimitFn = fn
+	gopyh.SetHandleLimit(int(limit), func(n int) {
+		_gstate := C.PyGILState_Ensure()
+		args := C.PyTuple_New(1)
+		C.PyTuple_SetItem(args, 0, C.PyLong_FromLongLong(C.longlong(n))) // steals the new reference
+		C.gopy_decref(C.PyObject_CallObject(fn, args))
+		C.gopy_decref(args)
+		C.gopy_err_handle()
+		C.PyGILState_Release(_gstate)
+	})
+	C.gopy_decref(prev)
+}
+
+//export GoPyHandleStats
+func GoPyHandleStats() *C.char {
+	var sb strings.Builder
+	for _, st := range gopyh.HandleStats() {
+		fmt.Fprintf(&sb, "%s\t%d\t%d\n", st.Type, st.Count, st.Refs)
+	}
+	return C.CString(sb.String())
+}
+
+//export GoPyDumpHandles
+func GoPyDumpHandles() *C.char {
+	var sb strings.Builder
+	gopyh.DumpHandles(&sb)
+	return C.CString(sb.String())
+}
+`
+
+	// pybindgen stub for handlesGo
+	handlesPyBuild = `mod.add_function('GoPySetHandleLimit', None, [param('int64_t', 'limit'), param('PyObject*', 'fn', transfer_ownership=False)])
+add_checked_string_function(mod, 'GoPyHandleStats', retval('char*'), [])
+add_checked_string_function(mod, 'GoPyDumpHandles', retval('char*'), [])
+`
+
+	// python go.set_handle_limit, go.handle_pressure and handle report functions
+	// 1 = package name
+	handlesPyWrap = `
+# ---- set_handle_limit support for alerting on leaking handles ---
+import gc as _gc
+
+class HandleLimitWarning(RuntimeWarning):
+	"""HandleLimitWarning is warned when the number of Go handles in use exceeds the limit of set_handle_limit"""
+	pass
+
+def _warn_handle_limit(n):
+	import warnings
+	warnings.warn("%%d Go handles in use, above the limit: are handles leaking?" %% n, HandleLimitWarning)
+
+def set_handle_limit(limit, callback=None):
+	"""set_handle_limit calls callback(n) when the number n of Go handles in use
+	exceeds limit, once each time it does, e.g., to alert on leaking handles
+	before running out of memory.  The default callback warns with
+	HandleLimitWarning.  A limit of 0 turns it off."""
+	if callback is None:
+		callback = _warn_handle_limit
+	_%[1]s.GoPySetHandleLimit(limit, callback)
+
+def handle_pressure(fn, n=1000):
+	"""handle_pressure calls fn() n times, collecting the garbage before and after,
+	and returns the number of Go handles in use after the calls that were not
+	before, e.g., to test in a unit test that fn does not leak handles, if 0."""
+	_gc.collect()
+	start = _%[1]s.NumHandles()
+	for i in range(n):
+		fn()
+	_gc.collect()
+	return _%[1]s.NumHandles() - start
+
+def num_handles():
+	"""num_handles returns the number of Go handles in use"""
+	return _%[1]s.NumHandles()
+
+def handle_stats():
+	"""handle_stats returns a dict of the Go types of the handles in use to (handles, refs) tuples of
+	the number of handles and the sum of their reference counts, by decreasing number of handles,
+	e.g., to find the type of leaking handles"""
+	stats = collections.OrderedDict()
+	for line in _%[1]s.GoPyHandleStats().splitlines():
+		typ, n, refs = line.rsplit("\t", 2)
+		stats[typ] = (int(n), int(refs))
+	return stats
+
+def dump_handles(file=None):
+	"""dump_handles writes the Go handles in use to file, a file object or path, sys.stderr by default,
+	one per line, in increasing order, with their reference count, Go type and value"""
+	dump = _%[1]s.GoPyDumpHandles()
+	if file is None:
+		file = sys.stderr
+	if isinstance(file, str):
+		with open(file, "w") as f:
+			f.write(dump)
+	else:
+		file.write(dump)
+
+`
+)
+
+// genHandlesGo generates the go code and pybindgen stub for go.set_handle_limit,
+// and the sharding of the handle registry
+func (g *pyGen) genHandlesGo() {
+	g.gofile.Printf("%s", handlesGo)
+	if n := g.cfg.HandleShards; n != 0 && n != 1 {
+		if n < 0 {
+			n = 0 // one per CPU
+		}
+		g.gofile.Printf("\nfunc init() {\n")
+		g.gofile.Indent()
+		g.gofile.Printf("gopyh.SetShards(%d)\n", n)
+		g.gofile.Outdent()
+		g.gofile.Printf("}\n")
+	}
+	g.pybuild.Printf("%s", handlesPyBuild)
+}
+
+// genHandlesPyWrap generates the go.set_handle_limit, go.handle_pressure
+// and handle report python functions
+func (g *pyGen) genHandlesPyWrap() {
+	g.pywrap.Printf(handlesPyWrap, g.cfg.Name)
+}
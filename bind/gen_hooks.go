@@ -0,0 +1,152 @@
+// Copyright 2024 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package bind
+
+import (
+	"bytes"
+	"fmt"
+	"go/types"
+	"os"
+	"path"
+	"regexp"
+	"strings"
+	"text/template"
+
+	"golang.org/x/tools/txtar"
+)
+
+// With the Hooks option, fragments of Go or python code are injected into
+// the generated functions and methods whose qualified names, e.g.,
+// hi.Person.Greet, match their patterns, e.g., for argument validation or
+// metrics, without editing the generated files.  The hooks file is a txtar
+// archive of the fragments, each named by its language, go or py, when it
+// runs, before or after the call, and its pattern, e.g.,
+//
+//	-- go imports --
+//	time
+//	-- go before hi\..* --
+//	_hookStart := time.Now()
+//	-- go after hi\..* --
+//	println("{{.Name}}", time.Since(_hookStart).String())
+//	-- py before hi\.Add --
+//	if {{index .Args 0}} < 0:
+//		raise ValueError("{{.PyName}}: negative {{index .Args 0}}")
+//
+// The patterns are regexps matching the whole qualified names, so that a
+// plain name matches that symbol only.  The fragments are text/template
+// templates of a hookData, and are injected in the order of the file.  The
+// Go ones run with the GIL released, the before ones once the args are
+// converted, and the after ones deferred, and the python ones after the
+// conversions of the args, and after the call, even if it raises, in a
+// finally clause.  The go imports are the import paths, possibly preceded
+// by a name, of the packages used by the Go fragments.
+
+// hook is a fragment of code of the Hooks option
+type hook struct {
+	lang string         // go or py
+	when string         // before or after
+	re   *regexp.Regexp // of the qualified names of the functions
+	tmpl *template.Template
+}
+
+// hookData is the data of the templates of the hooks
+type hookData struct {
+	Name   string   // qualified Go name, e.g., hi.Person.Greet
+	GoName string   // Go name, e.g., Greet
+	PyName string   // python name, e.g., greet with the RenameCase option
+	Args   []string // names of the params, of the cgo wrapper in go, without the receiver
+}
+
+// readHooks returns the hooks of the hooks file, if any, and the imports
+// of their Go fragments
+func readHooks(fn string) ([]*hook, []*types.Package, error) {
+	if fn == "" {
+		return nil, nil, nil
+	}
+	data, err := os.ReadFile(fn)
+	if err != nil {
+		return nil, nil, fmt.Errorf("gopy: could not read the hooks: %v", err)
+	}
+	var (
+		hooks []*hook
+		imps  []*types.Package
+	)
+	for _, f := range txtar.Parse(data).Files {
+		fields := strings.Fields(f.Name)
+		if len(fields) == 2 && fields[0] == "go" && fields[1] == "imports" {
+			for _, line := range strings.Split(string(f.Data), "\n") {
+				imp := strings.Fields(line)
+				switch len(imp) {
+				case 0:
+				case 1:
+					imps = append(imps, types.NewPackage(imp[0], path.Base(imp[0])))
+				case 2:
+					imps = append(imps, types.NewPackage(imp[1], imp[0]))
+				default:
+					return nil, nil, fmt.Errorf("gopy: %s: invalid go import %q", fn, line)
+				}
+			}
+			continue
+		}
+		if len(fields) != 3 || (fields[0] != "go" && fields[0] != "py") || (fields[1] != "before" && fields[1] != "after") {
+			return nil, nil, fmt.Errorf("gopy: %s: invalid hook %q, must be go|py before|after pattern, or go imports", fn, f.Name)
+		}
+		re, err := regexp.Compile("^(?:" + fields[2] + ")$")
+		if err != nil {
+			return nil, nil, fmt.Errorf("gopy: %s: invalid pattern of hook %q: %v", fn, f.Name, err)
+		}
+		tmpl, err := template.New(f.Name).Parse(strings.Trim(string(f.Data), "\n"))
+		if err != nil {
+			return nil, nil, fmt.Errorf("gopy: %s: invalid template of hook %q: %v", fn, f.Name, err)
+		}
+		hooks = append(hooks, &hook{lang: fields[0], when: fields[1], re: re, tmpl: tmpl})
+	}
+	return hooks, imps, nil
+}
+
+// importHooks adds the imports of the Go fragments of the hooks to the
+// generated go file
+func importHooks(imps []*types.Package) {
+	for _, imp := range imps {
+		if nm := current.addImport(imp); nm != imp.Name() && !NoWarn {
+			fmt.Printf("gopy: warning: hooks import %s imported as %s\n", imp.Path(), nm)
+		}
+	}
+}
+
+// hasHooks returns whether the function has hooks of the language and time
+func (g *pyGen) hasHooks(lang, when string, fsym *Func) bool {
+	if len(g.hooks) == 0 {
+		return false
+	}
+	name := qualName(fsym.obj)
+	for _, h := range g.hooks {
+		if h.lang == lang && h.when == when && h.re.MatchString(name) {
+			return true
+		}
+	}
+	return false
+}
+
+// genHooks generates the fragments of the hooks of the language and time
+// of the function, with the names of its params, to the printer
+func (g *pyGen) genHooks(pr *printer, lang, when string, fsym *Func, args []string) {
+	if !g.hasHooks(lang, when, fsym) {
+		return
+	}
+	pyname, _ := g.pyFuncName(fsym)
+	data := &hookData{Name: qualName(fsym.obj), GoName: fsym.GoName(), PyName: pyname, Args: args}
+	for _, h := range g.hooks {
+		if h.lang != lang || h.when != when || !h.re.MatchString(data.Name) {
+			continue
+		}
+		var buf bytes.Buffer
+		if err := h.tmpl.Execute(&buf, data); err != nil {
+			g.err.Add(fmt.Errorf("gopy: could not apply hook %q to %s: %v", h.tmpl.Name(), data.Name, err))
+			continue
+		}
+		pr.Printf("%s\n", strings.TrimRight(buf.String(), "\n"))
+	}
+}
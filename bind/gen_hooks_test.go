@@ -0,0 +1,66 @@
+// Copyright 2024 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package bind
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestReadHooks(t *testing.T) {
+	fn := filepath.Join(t.TempDir(), "hooks.txtar")
+	err := os.WriteFile(fn, []byte(`comment
+-- go imports --
+time
+m example.com/metrics
+-- go before hi\..* --
+_start := time.Now()
+-- py after hi\.Add --
+print("{{.PyName}}", {{index .Args 0}})
+`), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	hooks, imps, err := readHooks(fn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(imps) != 2 || imps[0].Path() != "time" || imps[0].Name() != "time" || imps[1].Path() != "example.com/metrics" || imps[1].Name() != "m" {
+		t.Errorf("unexpected imports: %v", imps)
+	}
+	if len(hooks) != 2 {
+		t.Fatalf("got %d hooks, want 2", len(hooks))
+	}
+	if h := hooks[0]; h.lang != "go" || h.when != "before" || !h.re.MatchString("hi.Person.Greet") {
+		t.Errorf("unexpected go hook: %+v", h)
+	}
+	h := hooks[1]
+	if h.lang != "py" || h.when != "after" || !h.re.MatchString("hi.Add") || h.re.MatchString("hi.AddAll") {
+		t.Errorf("unexpected py hook: %+v", h)
+	}
+	var buf bytes.Buffer
+	if err := h.tmpl.Execute(&buf, &hookData{Name: "hi.Add", GoName: "Add", PyName: "add", Args: []string{"a", "b"}}); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := buf.String(), `print("add", a)`; got != want {
+		t.Errorf("hook: got %q, want %q", got, want)
+	}
+
+	for _, bad := range []string{
+		"-- go around hi.Add --\n",
+		"-- py before hi.( --\n",
+		"-- py before hi.Add --\n{{.Nope\n",
+		"-- go imports --\na b c\n",
+	} {
+		if err := os.WriteFile(fn, []byte(bad), 0644); err != nil {
+			t.Fatal(err)
+		}
+		if _, _, err := readHooks(fn); err == nil {
+			t.Errorf("no error for the hooks %q", bad)
+		}
+	}
+}
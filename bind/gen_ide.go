@@ -0,0 +1,130 @@
+// Copyright 2024 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package bind
+
+import (
+	"encoding/json"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// With the GenIDE option, tooling metadata is generated along with the
+// bindings, so that the generated code can be opened and debugged in IDEs
+// without configuring them: a compile_commands.json compilation database
+// of the C extension module built by the Makefile, for clangd and the
+// VS Code C/C++ extension, and the VS Code .vscode/settings.json, with the
+// python interpreter and import path, and .vscode/launch.json, debugging
+// the current python file with the python debugger, or with gdb for the
+// C and Go code.
+
+// ideCompileCommand is an entry of compile_commands.json
+type ideCompileCommand struct {
+	Directory string   `json:"directory"`
+	File      string   `json:"file"`
+	Arguments []string `json:"arguments"`
+	Output    string   `json:"output"`
+}
+
+// ideCC returns the C compiler used by go, as in the Makefile
+func ideCC() string {
+	out, err := exec.Command("go", "env", "CC").Output()
+	if cc := strings.TrimSpace(string(out)); err == nil && cc != "" {
+		return cc
+	}
+	return "cc"
+}
+
+// ideExecutable returns the path of the executable of the python
+// interpreter, not of a wrapper script of it, for debuggers
+func ideExecutable(vm string) string {
+	out, err := exec.Command(vm, "-c", "import sys; print(sys.executable)").Output()
+	if exe := strings.TrimSpace(string(out)); err == nil && exe != "" {
+		return exe
+	}
+	return vm
+}
+
+// genIDE generates the tooling metadata of the output directory
+func (g *pyGen) genIDE() {
+	dir, err := filepath.Abs(g.cfg.OutputDir)
+	if err != nil {
+		g.err.Add(err)
+		return
+	}
+	pycfg, err := g.cfg.PythonConfig()
+	if err != nil {
+		g.err.Add(err)
+		return
+	}
+	// the modules are imported from the output directory, or as a package
+	paths := []string{dir, filepath.Dir(dir)}
+	pypath := strings.Join(paths, string(os.PathListSeparator))
+	vm := ideExecutable(g.cfg.VM)
+
+	src := g.cfg.Name + ".c"
+	obj := "_" + g.cfg.Name + ".o"
+	args := []string{ideCC(), "-c", src}
+	for _, f := range strings.Fields(pycfg.CFlags) {
+		args = append(args, strings.Trim(f, `"`)) // quoted for the #cgo lines
+	}
+	args = append(args, "-fPIC", "-w", "-o", obj)
+	g.genJSON(filepath.Join(dir, "compile_commands.json"), []ideCompileCommand{
+		{Directory: dir, File: src, Arguments: args, Output: obj},
+	})
+
+	vscode := filepath.Join(dir, ".vscode")
+	if err := os.MkdirAll(vscode, 0755); err != nil {
+		g.err.Add(err)
+		return
+	}
+	g.genJSON(filepath.Join(vscode, "settings.json"), map[string]interface{}{
+		"C_Cpp.default.compileCommands": "${workspaceFolder}/compile_commands.json",
+		"python.defaultInterpreterPath": vm,
+		"python.analysis.extraPaths":    paths,
+		"files.readonlyInclude": map[string]bool{
+			g.cfg.Name + ".go": true,
+			g.cfg.Name + ".py": true,
+			"go.py":            true,
+			"build.py":         true,
+		},
+	})
+	g.genJSON(filepath.Join(vscode, "launch.json"), map[string]interface{}{
+		"version": "0.2.0",
+		"configurations": []map[string]interface{}{
+			{
+				"name":    "Python: current file",
+				"type":    "debugpy",
+				"request": "launch",
+				"program": "${file}",
+				"cwd":     "${fileDirname}",
+				"env":     map[string]string{"PYTHONPATH": pypath},
+			},
+			{
+				"name":    "C/Go: python current file under gdb",
+				"type":    "cppdbg",
+				"request": "launch",
+				"program": vm,
+				"args":    []string{"${file}"},
+				"cwd":     "${fileDirname}",
+				"environment": []map[string]string{
+					{"name": "PYTHONPATH", "value": pypath},
+				},
+				"MIMode": "gdb",
+			},
+		},
+	})
+}
+
+// genJSON writes the value as an indented JSON file
+func (g *pyGen) genJSON(fname string, v interface{}) {
+	data, err := json.MarshalIndent(v, "", "\t")
+	if err != nil {
+		g.err.Add(err)
+		return
+	}
+	g.err.Add(os.WriteFile(fname, append(data, '\n'), 0644))
+}
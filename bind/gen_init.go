@@ -0,0 +1,120 @@
+// Copyright 2024 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package bind
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+const (
+	// 1 = full output name, 2 = command, 3 = pyInitReexport if any
+	pyInitPreamble = `# python package %[1]s, importing the modules of its packages when first
+# accessed, e.g., %[1]s.go%[3]s
+# File is generated by gopy. Do not edit.
+# %[2]s
+
+import importlib
+
+`
+
+	// 1 = full output name, 2 = primary package name
+	pyInitReexport = `, and re-exporting the symbols of the %[2]s
+# package at the top level, e.g., import %[1]s; %[1]s.Func()`
+
+	// 1 = quoted module names
+	pyInitLazy = `_modules = {%[1]s}
+
+def __getattr__(name):
+	"""__getattr__ imports the module of a package when first accessed (PEP 562)"""
+	if name in _modules:
+		return importlib.import_module("." + name, __name__)
+	raise AttributeError("module %%r has no attribute %%r" %% (__name__, name))
+
+def __dir__():
+	return sorted(set(globals()) | _modules)
+`
+)
+
+// rxPyTopLevelName matches the python names defined at the top level of a
+// generated wrapper module: classes, functions and constants
+var rxPyTopLevelName = regexp.MustCompile(`(?m)^(?:(?:class|def) ([A-Za-z]\w*)|([A-Za-z]\w*)\s*=[^=])`)
+
+// pyTopLevelNames returns the public python names defined at the top level
+// of the generated python code, in order
+func pyTopLevelNames(code []byte) []string {
+	var names []string
+	has := make(map[string]bool)
+	for _, m := range rxPyTopLevelName.FindAllSubmatch(code, -1) {
+		nm := string(m[1]) + string(m[2])
+		if has[nm] {
+			continue
+		}
+		has[nm] = true
+		names = append(names, nm)
+	}
+	return names
+}
+
+// genInit generates the __init__.py of the output package, making it a real
+// python package of a submodule per Go package, and of go: it imports the
+// submodules lazily, when first accessed as, e.g., outname.pkg, so that
+// importing the output package does not load all of them, and re-exports
+// the symbols of the primary package, if InitExports, so that they are
+// available as, e.g., outname.Func
+func (g *pyGen) genInit() {
+	var mods []string
+	var primary *Package
+	for _, p := range Packages {
+		if p == goPackage {
+			continue
+		}
+		if primary == nil || (p.Name() == g.cfg.Primary && primary.Name() != g.cfg.Primary) {
+			primary = p
+		}
+		mods = append(mods, p.Name())
+	}
+	if primary == nil {
+		g.genPrintOut("__init__.py", &printer{buf: new(bytes.Buffer)})
+		return
+	}
+
+	pr := &printer{buf: new(bytes.Buffer), indentEach: []byte("\t")}
+	reexp := ""
+	if g.cfg.InitExports {
+		reexp = fmt.Sprintf(pyInitReexport, g.cfg.FullName(), primary.Name())
+	}
+	pr.Printf(pyInitPreamble, g.cfg.FullName(), g.cfg.Cmd, reexp)
+
+	all := append([]string{"go"}, mods...)
+	ismod := make(map[string]bool)
+	for _, m := range all {
+		ismod[m] = true
+	}
+	if g.cfg.InitExports {
+		var syms []string
+		for _, nm := range g.exports[primary] {
+			if !ismod[nm] { // the modules take precedence over colliding symbols
+				syms = append(syms, nm)
+			}
+		}
+		if len(syms) > 0 {
+			pr.Printf("from .%s import (\n", primary.Name())
+			pr.Indent()
+			for _, nm := range syms {
+				pr.Printf("%s,\n", nm)
+			}
+			pr.Outdent()
+			pr.Printf(")\n\n")
+			all = append(all, syms...)
+		}
+	}
+	mstr := `"` + strings.Join(all[:len(mods)+1], `", "`) + `"`
+	pr.Printf(pyInitLazy, mstr)
+	pr.Printf("\n__all__ = [%s]\n", `"`+strings.Join(all, `", "`)+`"`)
+	g.genPrintOut("__init__.py", pr)
+}
@@ -0,0 +1,413 @@
+// Copyright 2024 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package bind
+
+import (
+	"go/token"
+	"go/types"
+)
+
+// io.Reader and io.Writer args accept any python file-like object with a
+// read or write method, e.g., files opened in binary or text mode (UTF-8
+// encoded) or io.BytesIO, wrapped in a go.Reader or go.Writer, Go proxies
+// calling back into python.  Conversely, the python classes of the Go types
+// implementing io.Reader or io.Writer have read and write methods, so that
+// they can be used as python file-like objects.
+
+const (
+	// go code of the go.Reader and go.Writer proxies, and of the read and
+	// write methods of Go readers and writers
+	ioGo = `
+// ---- io.Reader and io.Writer support for python file-like objects ---
+
+// gopyPyErr returns the current python exception as a go error, clearing it
+func gopyPyErr() error {
+	var t, v, tb *C.PyObject
+	C.PyErr_Fetch(&t, &v, &tb)
+	defer C.gopy_decref(t)
+	defer C.gopy_decref(v)
+	defer C.gopy_decref(tb)
+	msg := "python exception"
+	if t != nil {
+		msg = C.GoString((*C.PyTypeObject)(unsafe.Pointer(t)).tp_name)
+	}
+	if v != nil {
+		if s := C.PyObject_Str(v); s != nil {
+			msg += ": " + C.GoString(C.PyUnicode_AsUTF8(s))
+			C.gopy_decref(s)
+		}
+	}
+	C.PyErr_Clear()
+	return errors.New(msg)
+}
+
+// gopyIOCall calls the python callable fn with the arg, stealing the
+// reference to the arg, with the GIL held
+func gopyIOCall(fn, arg *C.PyObject) *C.PyObject {
+	args := C.PyTuple_New(1)
+	C.PyTuple_SetItem(args, 0, arg) // steals the reference
+	res := C.PyObject_CallObject(fn, args)
+	C.gopy_decref(args)
+	return res
+}
+
+// gopyIOBytes returns a new python bytes object of the data
+func gopyIOBytes(data []byte) *C.PyObject {
+	if len(data) == 0 {
+		return C.PyBytes_FromStringAndSize(nil, 0)
+	}
+	return C.PyBytes_FromStringAndSize((*C.char)(unsafe.Pointer(&data[0])), C.Py_ssize_t(len(data)))
+}
+
+// gopyIORelease releases the python callable of a proxy, when collected
+func gopyIORelease(fn *C.PyObject) {
+	if C.Py_IsInitialized() == 0 {
+		return
+	}
+	_gstate := C.PyGILState_Ensure()
+	C.gopy_decref(fn)
+	C.PyGILState_Release(_gstate)
+}
+
+// gopyReader is an io.Reader calling a python callable read(n), returning
+// up to n bytes, or no bytes at EOF
+type gopyReader struct {
+	read *C.PyObject
+}
+
+func (r *gopyReader) Read(p []byte) (int, error) {
+	if len(p) == 0 {
+		return 0, nil
+	}
+	_gstate := C.PyGILState_Ensure()
+	defer C.PyGILState_Release(_gstate)
+	res := gopyIOCall(r.read, C.PyLong_FromLongLong(C.longlong(len(p))))
+	if res == nil {
+		return 0, gopyPyErr()
+	}
+	defer C.gopy_decref(res)
+	var buf *C.char
+	var n C.Py_ssize_t
+	if C.PyBytes_AsStringAndSize(res, &buf, &n) < 0 {
+		return 0, gopyPyErr()
+	}
+	if n == 0 {
+		return 0, io.EOF
+	}
+	return copy(p, unsafe.Slice((*byte)(unsafe.Pointer(buf)), int(n))), nil
+}
+
+// gopyWriter is an io.Writer calling a python callable write(data),
+// writing all the bytes
+type gopyWriter struct {
+	write *C.PyObject
+}
+
+func (w *gopyWriter) Write(p []byte) (int, error) {
+	_gstate := C.PyGILState_Ensure()
+	defer C.PyGILState_Release(_gstate)
+	res := gopyIOCall(w.write, gopyIOBytes(p))
+	if res == nil {
+		return 0, gopyPyErr()
+	}
+	C.gopy_decref(res)
+	return len(p), nil
+}
+
+// GoPyIO_Read reads up to n bytes from the io.Reader of the handle, or all
+// of them until EOF if n < 0, returning no bytes at EOF
+//export GoPyIO_Read
+func GoPyIO_Read(h CGoHandle, n C.longlong) *C.PyObject {
+	r, ok := gopyh.VarFromHandle(handleGo(h), "io.Reader").(io.Reader)
+	if !ok {
+		estr := C.CString("gopy: not a Go io.Reader")
+		C.PyErr_SetString(C.PyExc_TypeError, estr)
+		C.free(unsafe.Pointer(estr))
+		return nil
+	}
+	var data []byte
+	var err error
+	_saved_thread := C.PyEval_SaveThread()
+	switch {
+	case n < 0:
+		data, err = io.ReadAll(r)
+	case n > 0:
+		var m int
+		data = make([]byte, n)
+		m, err = io.ReadAtLeast(r, data, 1)
+		data = data[:m]
+		if err == io.EOF {
+			err = nil
+		}
+	}
+	C.PyEval_RestoreThread(_saved_thread)
+	if err != nil {
+		estr := C.CString(err.Error())
+		gopyRaise(err, estr)
+		C.free(unsafe.Pointer(estr))
+		return nil
+	}
+	return gopyIOBytes(data)
+}
+
+// GoPyIO_Write writes the bytes of data to the io.Writer of the handle,
+// returning the number of bytes written
+//export GoPyIO_Write
+func GoPyIO_Write(h CGoHandle, data *C.PyObject) C.longlong {
+	w, ok := gopyh.VarFromHandle(handleGo(h), "io.Writer").(io.Writer)
+	if !ok {
+		estr := C.CString("gopy: not a Go io.Writer")
+		C.PyErr_SetString(C.PyExc_TypeError, estr)
+		C.free(unsafe.Pointer(estr))
+		return -1
+	}
+	var buf *C.char
+	var n C.Py_ssize_t
+	if C.PyBytes_AsStringAndSize(data, &buf, &n) < 0 {
+		return -1
+	}
+	p := C.GoBytes(unsafe.Pointer(buf), C.int(n)) // copied, as the GIL is released
+	_saved_thread := C.PyEval_SaveThread()
+	m, err := w.Write(p)
+	C.PyEval_RestoreThread(_saved_thread)
+	if err != nil {
+		estr := C.CString(err.Error())
+		gopyRaise(err, estr)
+		C.free(unsafe.Pointer(estr))
+		return -1
+	}
+	return C.longlong(m)
+}
+`
+
+	// go code of the constructor of go.Reader
+	ioReaderGo = `
+//export GoPyIO_NewReader
+func GoPyIO_NewReader(read *C.PyObject) CGoHandle {
+	C.gopy_incref(read)
+	r := &gopyReader{read: read}
+	runtime.SetFinalizer(r, func(r *gopyReader) { gopyIORelease(r.read) })
+	return handleFromPtr_io_Reader(r)
+}
+`
+
+	// go code of the constructor of go.Writer
+	ioWriterGo = `
+//export GoPyIO_NewWriter
+func GoPyIO_NewWriter(write *C.PyObject) CGoHandle {
+	C.gopy_incref(write)
+	w := &gopyWriter{write: write}
+	runtime.SetFinalizer(w, func(w *gopyWriter) { gopyIORelease(w.write) })
+	return handleFromPtr_io_Writer(w)
+}
+`
+
+	// pybindgen stubs for ioGo
+	ioPyBuild = `add_checked_function(mod, 'GoPyIO_Read', retval('PyObject*', caller_owns_return=True), [param('%[1]s', 'h'), param('int64_t', 'n')])
+add_checked_function(mod, 'GoPyIO_Write', retval('int64_t'), [param('%[1]s', 'h'), param('PyObject*', 'data', transfer_ownership=False)])
+`
+
+	// pybindgen stubs for ioReaderGo and ioWriterGo
+	ioReaderPyBuild = `mod.add_function('GoPyIO_NewReader', retval('%[1]s'), [param('PyObject*', 'read', transfer_ownership=False)])
+`
+	ioWriterPyBuild = `mod.add_function('GoPyIO_NewWriter', retval('%[1]s'), [param('PyObject*', 'write', transfer_ownership=False)])
+`
+
+	// python adapters of file-like objects, in go.py
+	ioPyWrap = `
+# ---- io.Reader and io.Writer support for python file-like objects ---
+import codecs as _codecs
+import io as _io
+
+class _PyReader(object):
+	"""_PyReader reads up to n bytes from a python file-like object, encoding str in UTF-8, for go.Reader"""
+	def __init__(self, file):
+		self.file = file
+		self.buf = b''
+	def __call__(self, n):
+		if not self.buf:
+			data = self.file.read(n)
+			if data is None:
+				data = b''
+			if isinstance(data, str):
+				data = data.encode('utf-8')
+			self.buf = bytes(data)
+		data, self.buf = self.buf[:n], self.buf[n:]
+		return data
+
+class _PyWriter(object):
+	"""_PyWriter writes bytes to a python file-like object, decoding them from UTF-8 for text files, for go.Writer"""
+	def __init__(self, file):
+		self.file = file
+		self.text = isinstance(file, _io.TextIOBase)
+		self.decoder = _codecs.getincrementaldecoder('utf-8')('replace')
+	def __call__(self, data):
+		if self.text:
+			self.file.write(self.decoder.decode(data))
+			return
+		view = memoryview(data)
+		while view:
+			n = self.file.write(view)
+			if n is None:
+				return
+			view = view[n:]
+
+`
+
+	// python go.Reader class, extending the io_Reader ext class
+	// 1 = package name
+	ioReaderPyWrap = `class Reader(io_Reader):
+	"""Reader is a Go io.Reader reading from a python file-like object with a read method,
+	e.g., a file opened in binary or text mode (read as UTF-8), or io.BytesIO.
+	The python objects passed as io.Reader args are wrapped in a Reader."""
+	def __init__(self, file):
+		self.file = file
+		io_Reader.__init__(self, handle=_%[1]s.GoPyIO_NewReader(_PyReader(file)))
+
+`
+
+	// python go.Writer class, extending the io_Writer ext class
+	// 1 = package name
+	ioWriterPyWrap = `class Writer(io_Writer):
+	"""Writer is a Go io.Writer writing to a python file-like object with a write method,
+	e.g., a file opened in binary or text mode (written as UTF-8), or io.BytesIO.
+	The python objects passed as io.Writer args are wrapped in a Writer."""
+	def __init__(self, file):
+		self.file = file
+		io_Writer.__init__(self, handle=_%[1]s.GoPyIO_NewWriter(_PyWriter(file)))
+
+`
+)
+
+// ioMethod returns the interface of the io method name, Read or Write,
+// with the signature func([]byte) (int, error)
+func ioMethod(name string) *types.Interface {
+	params := types.NewTuple(types.NewVar(token.NoPos, nil, "p", types.NewSlice(types.Typ[types.Byte])))
+	results := types.NewTuple(
+		types.NewVar(token.NoPos, nil, "n", types.Typ[types.Int]),
+		types.NewVar(token.NoPos, nil, "err", types.Universe.Lookup("error").Type()),
+	)
+	sig := types.NewSignatureType(nil, nil, nil, params, results, false)
+	return types.NewInterfaceType([]*types.Func{types.NewFunc(token.NoPos, nil, name, sig)}, nil).Complete()
+}
+
+var (
+	ioReader = ioMethod("Read")
+	ioWriter = ioMethod("Write")
+)
+
+// implementsIO returns whether the values of the handles of the type,
+// pointers for the non-interface types, implement the io interface
+func implementsIO(typ types.Type, iface *types.Interface) bool {
+	if types.Implements(typ, iface) {
+		return true
+	}
+	if types.IsInterface(typ) {
+		return false
+	}
+	if _, isPtr := typ.(*types.Pointer); isPtr {
+		return false
+	}
+	return types.Implements(types.NewPointer(typ), iface)
+}
+
+// isIOType returns whether the type is io.Reader or io.Writer, whose args
+// accept python file-like objects
+func isIOType(typ types.Type) bool {
+	switch types.TypeString(typ, nil) {
+	case "io.Reader", "io.Writer":
+		return true
+	}
+	return false
+}
+
+// usesIOType returns whether the io type, io.Reader or io.Writer, is used
+// by the bound packages
+func usesIOType(name string) bool {
+	s := current.sym(name)
+	return s != nil && s.isInterface()
+}
+
+// genIOGo generates the go code and pybindgen stubs of go.Reader and
+// go.Writer, and of the read and write methods of Go readers and writers
+func (g *pyGen) genIOGo() {
+	g.gofile.Printf("%s", ioGo)
+	g.pybuild.Printf(ioPyBuild, PyHandle)
+	if usesIOType("io.Reader") {
+		g.gofile.Printf("%s", ioReaderGo)
+		g.pybuild.Printf(ioReaderPyBuild, PyHandle)
+	}
+	if usesIOType("io.Writer") {
+		g.gofile.Printf("%s", ioWriterGo)
+		g.pybuild.Printf(ioWriterPyBuild, PyHandle)
+	}
+}
+
+// genIOPyWrap generates the go.Reader and go.Writer python classes
+func (g *pyGen) genIOPyWrap() {
+	reader, writer := usesIOType("io.Reader"), usesIOType("io.Writer")
+	if !reader && !writer {
+		return
+	}
+	g.pywrap.Printf("%s", ioPyWrap)
+	if reader {
+		g.pywrap.Printf(ioReaderPyWrap, g.cfg.Name)
+	}
+	if writer {
+		g.pywrap.Printf(ioWriterPyWrap, g.cfg.Name)
+	}
+}
+
+// genIOConv wraps the python file-like object passed as an io.Reader or
+// io.Writer arg in a go.Reader or go.Writer
+func (g *pyGen) genIOConv(vnm string, sym *symbol) {
+	if !isIOType(sym.gotyp) {
+		return
+	}
+	gocl := "go."
+	if g.pkg == goPackage {
+		gocl = ""
+	}
+	cls := "Reader"
+	if sym.goname == "io.Writer" {
+		cls = "Writer"
+	}
+	g.pywrap.Printf("if not isinstance(%s, %sGoClass):\n", vnm, gocl)
+	g.pywrap.Indent()
+	g.pywrap.Printf("%s = %s%s(%s)\n", vnm, gocl, cls, vnm)
+	g.pywrap.Outdent()
+}
+
+// genIOMethods generates the read and write methods of the python class of
+// the type if it implements io.Reader or io.Writer, unless the class has
+// methods of these names, among its generated methods
+func (g *pyGen) genIOMethods(sym *symbol, meths []*Func) {
+	has := make(map[string]bool)
+	for _, m := range meths {
+		if fn, ok := g.pyFuncName(m); ok {
+			has[fn] = true
+		}
+	}
+	if !has["read"] && implementsIO(sym.gotyp, ioReader) {
+		g.pywrap.Printf("def read(self, n=-1):\n")
+		g.pywrap.Indent()
+		g.pywrap.Printf(`"""read reads up to n bytes from the Go io.Reader, or all of them until EOF if n < 0, returning b'' at EOF"""
+`)
+		g.pywrap.Printf("return _%s.GoPyIO_Read(self.handle, n)\n", g.cfg.Name)
+		g.pywrap.Outdent()
+	}
+	if !has["write"] && implementsIO(sym.gotyp, ioWriter) {
+		g.pywrap.Printf("def write(self, data):\n")
+		g.pywrap.Indent()
+		g.pywrap.Printf(`"""write writes the bytes, or the str encoded in UTF-8, to the Go io.Writer, returning the number of bytes written"""
+`)
+		g.pywrap.Printf("if isinstance(data, str):\n")
+		g.pywrap.Indent()
+		g.pywrap.Printf("data = data.encode('utf-8')\n")
+		g.pywrap.Outdent()
+		g.pywrap.Printf("return _%s.GoPyIO_Write(self.handle, bytes(data))\n", g.cfg.Name)
+		g.pywrap.Outdent()
+	}
+}
@@ -0,0 +1,284 @@
+// Copyright 2024 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package bind
+
+import (
+	"fmt"
+	"go/types"
+	"strings"
+)
+
+// Functions and methods returning an iter.Seq[V] or iter.Seq2[K, V] return
+// a go.GoIter python iterator, which pulls the values from the Go iterator
+// as they are needed, with iter.Pull, yielding (key, value) tuples for
+// iter.Seq2.  The Go iterator is stopped when the python iterator is
+// exhausted, closed, or deleted, e.g., after breaking out of a for loop.
+// Iterators are not supported as args, fields or variables.
+
+const (
+	// python iterator class over Go iterators, in go.py
+	// 1 = package name
+	iterPyWrap = `
+# ---- Go iterators: iter.Seq and iter.Seq2 ---
+class GoIter:
+	"""GoIter is a python iterator over a Go iter.Seq, or an iter.Seq2 yielding (key, value) tuples,
+	pulling the values from the Go iterator as they are needed.
+	The Go iterator is stopped when the GoIter is exhausted, closed or deleted."""
+	def __init__(self, handle, pull, nxt, stop, value, key=None):
+		self.handle = handle
+		_%[1]s.IncRef(self.handle)
+		self._it = None
+		self._done = False
+		self._pull, self._next, self._stop, self._value, self._key = pull, nxt, stop, value, key
+	def __iter__(self):
+		return self
+	def __next__(self):
+		if self._done:
+			raise StopIteration
+		if self._it is None:
+			self._it = self._pull(self.handle)
+			_%[1]s.IncRef(self._it)
+		if not self._next(self._it):
+			self.close()
+			raise StopIteration
+		if self._key is None:
+			return self._value(self._it)
+		return (self._key(self._it), self._value(self._it))
+	def close(self):
+		"""close stops the Go iterator, without pulling any more values"""
+		if self._done:
+			return
+		self._done = True
+		if self._it is not None:
+			self._stop(self._it)
+			_%[1]s.DecRef(self._it)
+			self._it = None
+	def __del__(self):
+		self.close()
+		_%[1]s.DecRef(self.handle)
+
+`
+)
+
+// isIterSeq returns true if the type is an instance of iter.Seq or iter.Seq2
+func isIterSeq(typ types.Type) bool {
+	nt, ok := typ.(*types.Named)
+	if !ok || nt.Obj().Pkg() == nil || nt.Obj().Pkg().Path() != "iter" {
+		return false
+	}
+	nm := nt.Obj().Name()
+	return (nm == "Seq" || nm == "Seq2") && nt.TypeArgs().Len() > 0
+}
+
+// iterElems returns the types of the values yielded by the iter.Seq or
+// iter.Seq2 type: the value, or the key and value
+func iterElems(typ types.Type) []types.Type {
+	targs := typ.(*types.Named).TypeArgs()
+	elts := make([]types.Type, targs.Len())
+	for i := range elts {
+		elts[i] = targs.At(i)
+	}
+	return elts
+}
+
+// addIterType adds a symbol for an iter.Seq or iter.Seq2 type, returned by
+// handle to a go.GoIter python iterator
+func (sym *symtab) addIterType(pkg *types.Package, obj types.Object, t types.Type, kind symkind, id, n string) error {
+	fn := sym.fullTypeString(t)
+	elts := iterElems(t)
+	pysigs := make([]string, len(elts))
+	for i, elt := range elts {
+		esym, err := sym.addTypeIfNew(elt)
+		if err != nil {
+			return err
+		}
+		if esym.isSignature() || esym.goname == "interface{}" || isPyCompatVar(esym) != nil {
+			return fmt.Errorf("gopy: iterator value type not supported: %q", esym.goname)
+		}
+		pysigs[i] = esym.pysig
+	}
+	pysig := "Iterator[" + pysigs[0] + "]"
+	if len(pysigs) == 2 {
+		pysig = "Iterator[Tuple[" + pysigs[0] + ", " + pysigs[1] + "]]"
+	}
+	sym.syms[fn] = &symbol{
+		gopkg:   pkg,
+		goobj:   obj,
+		gotyp:   t,
+		kind:    kind | skNamed | skIter,
+		id:      id,
+		goname:  n,
+		cgoname: "CGoHandle",
+		cpyname: PyHandle,
+		pysig:   pysig,
+		go2py:   "handleFromPtr_" + id,
+		zval:    "nil",
+	}
+	return nil
+}
+
+// usesIter returns true if iterators are returned by the bound packages
+func usesIter() bool {
+	for _, n := range current.names() {
+		if current.sym(n).isIter() {
+			return true
+		}
+	}
+	return false
+}
+
+// genIterPyWrap generates the go.GoIter python class
+func (g *pyGen) genIterPyWrap() {
+	if !usesIter() {
+		return
+	}
+	g.pywrap.Printf(iterPyWrap, g.cfg.Name)
+}
+
+// genIterGo generates the go code and pybindgen stubs for pulling the
+// values of the iterator type from python
+func (g *pyGen) genIterGo(sym *symbol) {
+	elts := iterElems(sym.gotyp)
+	esyms := make([]*symbol, len(elts))
+	for i, elt := range elts {
+		esyms[i] = current.symtype(elt)
+	}
+	itnm := "gopyIter_" + sym.id
+	nextTyp := esyms[0].goname
+	pull := "iter.Pull"
+	fields := []string{"v"}
+	if len(esyms) == 2 {
+		nextTyp += ", " + esyms[1].goname
+		pull = "iter.Pull2"
+		fields = []string{"k", "v"}
+	}
+
+	g.gofile.Printf("\n// ---- iterator %s ---\n\n", sym.goname)
+	g.gofile.Printf("// %s is an iteration of %s, pulled from python\n", itnm, sym.goname)
+	g.gofile.Printf("type %s struct {\n", itnm)
+	g.gofile.Indent()
+	g.gofile.Printf("next func() (%s, bool)\n", nextTyp)
+	g.gofile.Printf("stop func()\n")
+	for i, f := range fields {
+		g.gofile.Printf("%s %s\n", f, esyms[i].goname)
+	}
+	g.gofile.Outdent()
+	g.gofile.Printf("}\n\n")
+
+	g.gofile.Printf("func %s(p interface{}) CGoHandle {\n", sym.go2py)
+	g.gofile.Indent()
+	g.gofile.Printf("return handleC(gopyh.Register(%q, p))\n", sym.goname)
+	g.gofile.Outdent()
+	g.gofile.Printf("}\n\n")
+
+	g.gofile.Printf("//export %s_Pull\n", sym.id)
+	g.gofile.Printf("func %s_Pull(h CGoHandle) CGoHandle {\n", sym.id)
+	g.gofile.Indent()
+	g.gofile.Printf("var seq %s\n", sym.goname)
+	g.gofile.Printf("if p, ok := gopyh.VarFromHandle(handleGo(h), %q).(*%s); ok && *p != nil {\n", sym.goname, sym.goname)
+	g.gofile.Indent()
+	g.gofile.Printf("seq = *p\n")
+	g.gofile.Outdent()
+	g.gofile.Printf("} else {\n")
+	g.gofile.Indent()
+	g.gofile.Printf("seq = func(func(%s) bool) {}\n", nextTyp)
+	g.gofile.Outdent()
+	g.gofile.Printf("}\n")
+	g.gofile.Printf("it := &%s{}\n", itnm)
+	g.gofile.Printf("it.next, it.stop = %s(seq)\n", pull)
+	g.gofile.Printf("return handleC(gopyh.Register(%q, it))\n", itnm)
+	g.gofile.Outdent()
+	g.gofile.Printf("}\n\n")
+
+	g.gofile.Printf("//export %s_Next\n", sym.id)
+	g.gofile.Printf("func %s_Next(h CGoHandle) C.char {\n", sym.id)
+	g.gofile.Indent()
+	g.gofile.Printf("it, ok := gopyh.VarFromHandle(handleGo(h), %q).(*%s)\n", itnm, itnm)
+	g.gofile.Printf("if !ok {\n")
+	g.gofile.Indent()
+	g.gofile.Printf("return boolGoToPy(false)\n")
+	g.gofile.Outdent()
+	g.gofile.Printf("}\n")
+	g.gofile.Printf("_saved_thread := C.PyEval_SaveThread()\n")
+	g.gofile.Printf("defer C.PyEval_RestoreThread(_saved_thread)\n")
+	g.gofile.Printf("it.%s, ok = it.next()\n", strings.Join(fields, ", it."))
+	g.gofile.Printf("return boolGoToPy(ok)\n")
+	g.gofile.Outdent()
+	g.gofile.Printf("}\n\n")
+
+	g.gofile.Printf("//export %s_Stop\n", sym.id)
+	g.gofile.Printf("func %s_Stop(h CGoHandle) {\n", sym.id)
+	g.gofile.Indent()
+	g.gofile.Printf("if it, ok := gopyh.VarFromHandle(handleGo(h), %q).(*%s); ok {\n", itnm, itnm)
+	g.gofile.Indent()
+	g.gofile.Printf("it.stop()\n")
+	g.gofile.Outdent()
+	g.gofile.Printf("}\n")
+	g.gofile.Outdent()
+	g.gofile.Printf("}\n\n")
+
+	g.pybuild.Printf("mod.add_function('%s_Pull', retval('%s'), [param('%s', 'h')])\n", sym.id, PyHandle, PyHandle)
+	g.pybuild.Printf("mod.add_function('%s_Next', retval('bool'), [param('%s', 'h')])\n", sym.id, PyHandle)
+	g.pybuild.Printf("mod.add_function('%s_Stop', None, [param('%s', 'h')])\n", sym.id, PyHandle)
+
+	for i, f := range fields {
+		esym := esyms[i]
+		fnm := fmt.Sprintf("%s_%s", sym.id, iterFieldFunc(f))
+		g.gofile.Printf("//export %s\n", fnm)
+		g.gofile.Printf("func %s(h CGoHandle) %s {\n", fnm, esym.cgoname)
+		g.gofile.Indent()
+		g.gofile.Printf("it := gopyh.VarFromHandle(handleGo(h), %q).(*%s)\n", itnm, itnm)
+		switch {
+		case esym.go2py == "":
+			g.gofile.Printf("return it.%s\n", f)
+		case esym.hasHandle() && !esym.isPtrOrIface():
+			// copy, as the value of the iteration changes with each next
+			g.gofile.Printf("cval := it.%s\n", f)
+			g.gofile.Printf("return %s(&cval)%s\n", esym.go2py, esym.go2pyParenEx)
+		default:
+			g.gofile.Printf("return %s(it.%s)%s\n", esym.go2py, f, esym.go2pyParenEx)
+		}
+		g.gofile.Outdent()
+		g.gofile.Printf("}\n\n")
+		addFuncName := "mod.add_function("
+		if esym.cpyname == "char*" && !esym.hasHandle() {
+			addFuncName = "add_checked_string_function(mod, "
+		}
+		g.pybuild.Printf("%s'%s', retval('%s'%s), [param('%s', 'h')])\n", addFuncName, fnm, esym.cpyname, esym.pyRetOwn(), PyHandle)
+	}
+}
+
+// iterFieldFunc returns the name of the function getting the field of
+// the iteration, k or v
+func iterFieldFunc(f string) string {
+	if f == "k" {
+		return "Key"
+	}
+	return "Value"
+}
+
+// iterPyArgs returns the args of go.GoIter after the handle, for the
+// python iterator over the values of the iterator type sym
+func (g *pyGen) iterPyArgs(sym *symbol) string {
+	pkgname := g.cfg.Name
+	elts := iterElems(sym.gotyp)
+	get := func(elt types.Type, fn string) string {
+		esym := current.symtype(elt)
+		if esym.hasHandle() {
+			return fmt.Sprintf("lambda it: %s(handle=_%s.%s_%s(it))", esym.pyPkgId(g.pkg.pkg, g.pkg), pkgname, sym.id, fn)
+		}
+		return fmt.Sprintf("_%s.%s_%s", pkgname, sym.id, fn)
+	}
+	args := []string{
+		fmt.Sprintf("_%s.%s_Pull", pkgname, sym.id),
+		fmt.Sprintf("_%s.%s_Next", pkgname, sym.id),
+		fmt.Sprintf("_%s.%s_Stop", pkgname, sym.id),
+		get(elts[len(elts)-1], "Value"),
+	}
+	if len(elts) == 2 {
+		args = append(args, "key="+get(elts[0], "Key"))
+	}
+	return strings.Join(args, ", ")
+}
@@ -0,0 +1,145 @@
+// Copyright 2024 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package bind
+
+// The structs whose doc comment has a //gopy:guarded line, documented as
+// requiring external locking, have a locked method returning a context
+// manager holding a Go mutex of their value, e.g.,
+//
+//	with acct.locked():
+//		acct.Deposit(10)
+//		acct.Withdraw(5)
+//
+// so that python threads sharing them serialize their uses.  The mutex is
+// that of the Go value, shared by its handles, e.g., the wrappers returned
+// by different calls, and is not reentrant, like threading.Lock.  The GIL
+// is released while waiting for it.  The mutex only serializes the python
+// code using locked, not the Go code using the value.  The guarded structs
+// are not thread checked, see gen_threads.go.
+
+const (
+	// go code of the mutexes of the values of the guarded structs
+	locksGo = `
+// ---- mutexes of the values of the guarded structs ---
+
+// gopyLock is the mutex of a Go value, with the number of its holders and
+// waiters, deleting it when none
+type gopyLock struct {
+	mu sync.Mutex
+	n  int
+}
+
+var (
+	// gopyLocks are the mutexes of the Go values, by their pointers
+	gopyLocks   = make(map[interface{}]*gopyLock)
+	gopyLocksMu sync.Mutex
+)
+
+// gopyLockValue locks the mutex of the Go value of the pointer p, releasing
+// the GIL while waiting for it
+func gopyLockValue(p interface{}) {
+	gopyLocksMu.Lock()
+	l := gopyLocks[p]
+	if l == nil {
+		l = &gopyLock{}
+		gopyLocks[p] = l
+	}
+	l.n++
+	gopyLocksMu.Unlock()
+	if l.mu.TryLock() {
+		return
+	}
+	ts := C.PyEval_SaveThread()
+	l.mu.Lock()
+	C.PyEval_RestoreThread(ts)
+}
+
+// gopyUnlockValue unlocks the mutex of the Go value of the pointer p
+func gopyUnlockValue(p interface{}) {
+	gopyLocksMu.Lock()
+	defer gopyLocksMu.Unlock()
+	l := gopyLocks[p]
+	if l == nil {
+		return
+	}
+	l.n--
+	if l.n == 0 {
+		delete(gopyLocks, p)
+	}
+	l.mu.Unlock()
+}
+`
+
+	// python context manager of the locked methods, in go
+	locksPyWrap = `
+# ---- locked: mutexes of the values of the guarded Go structs ---
+class Locked(object):
+	"""Locked is the context manager returned by the locked method of the guarded Go structs, holding the Go mutex of their value"""
+	def __init__(self, obj, lock, unlock):
+		self._obj = obj
+		self._lock = lock
+		self._unlock = unlock
+		self._held = False
+	def __enter__(self):
+		if self._held:
+			raise RuntimeError("locked: the Go mutex of the %s is already held by this context manager" % type(self._obj).__name__)
+		self._lock(self._obj.handle)
+		self._held = True
+		return self._obj
+	def __exit__(self, *exc):
+		self._held = False
+		self._unlock(self._obj.handle)
+		return False
+
+`
+)
+
+// genLocksGo generates the go code of the mutexes of the guarded structs
+func (g *pyGen) genLocksGo() {
+	g.gofile.Printf("%s", locksGo)
+}
+
+// genLocksPyWrap generates the go.Locked python context manager
+func (g *pyGen) genLocksPyWrap() {
+	g.pywrap.Printf("%s", locksPyWrap)
+}
+
+// isGuarded returns whether the named struct of the package requires
+// external locking, by a //gopy:guarded directive
+func (p *Package) isGuarded(name string) bool {
+	_, ok := p.directive(name, "guarded")
+	return ok
+}
+
+// genStructLocked generates the locked method of the struct, if guarded,
+// and the go functions locking and unlocking the mutex of its value
+func (g *pyGen) genStructLocked(s *Struct) {
+	if !s.pkg.isGuarded(s.obj.Name()) || g.hasStructMember(s, "locked") {
+		return
+	}
+	lockFn := s.ID() + "_GoPyLock"
+	unlockFn := s.ID() + "_GoPyUnlock"
+	g.pywrap.Printf("def locked(self):\n")
+	g.pywrap.Indent()
+	g.pywrap.Printf(`"""locked returns a context manager holding the Go mutex of the %s value, shared by all its wrappers, e.g.,
+with obj.locked(): ... -- it is not reentrant"""
+`, s.GoName())
+	g.pywrap.Printf("return go.Locked(self, _%[1]s.%[2]s, _%[1]s.%[3]s)\n", g.cfg.Name, lockFn, unlockFn)
+	g.pywrap.Outdent()
+
+	for _, fn := range []string{lockFn, unlockFn} {
+		op := "gopyLockValue"
+		if fn == unlockFn {
+			op = "gopyUnlockValue"
+		}
+		g.gofile.Printf("\n//export %s\n", fn)
+		g.gofile.Printf("func %s(handle CGoHandle) {\n", fn)
+		g.gofile.Indent()
+		g.gofile.Printf("%s(ptrFromHandle_%s(handle))\n", op, s.ID())
+		g.gofile.Outdent()
+		g.gofile.Printf("}\n")
+		g.pybuild.Printf("mod.add_function('%s', None, [param('%s', 'handle')])\n", fn, PyHandle)
+	}
+}
@@ -0,0 +1,149 @@
+// Copyright 2024 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package bind
+
+import (
+	"fmt"
+	"go/types"
+)
+
+// Some conversions of python values to Go would silently change them: python
+// ints out of the range of the sized and unsigned Go ints would wrap around,
+// and python floats lose precision as float32.  The python wrappers always
+// check the ranges of the python ints passed as args of these int types, and
+// set to fields and variables, raising OverflowError, as python does for
+// its own C ints.  With the LossyChecks option, they also check the floats
+// passed as float32, warning about changed values with
+// go.LossyConversionWarning.  With the Strict option, they raise
+// go.LossyConversionError instead, and dropping exported struct fields of
+// unsupported types is a generation error instead of a warning.
+
+const (
+	// python checks of the ranges of the Go ints, in go.py
+	intRangesPyDefs = `
+# ---- Checks of the ranges of the python ints converted to Go ---
+_int_ranges = {
+	'int8': (-1 << 7, 1 << 7), 'int16': (-1 << 15, 1 << 15), 'int32': (-1 << 31, 1 << 31),
+	'uint8': (0, 1 << 8), 'uint16': (0, 1 << 16), 'uint32': (0, 1 << 32),
+	'uint': (0, 1 << 64), 'uint64': (0, 1 << 64), 'uintptr': (0, 1 << 64),
+}
+
+def check_int(value, gotype, name):
+	"""check_int raises OverflowError if the python int value of name does not fit in the Go int type gotype"""
+	if isinstance(value, int):
+		lo, hi = _int_ranges[gotype]
+		if not lo <= value < hi:
+			raise OverflowError("%s: %d overflows Go %s" % (name, value, gotype))
+
+`
+
+	// python checks of lossy conversions, in go.py
+	// 1 = python bool of Strict
+	lossyPyDefs = `
+# ---- Checks of lossy conversions of python values to Go ---
+import struct as _struct
+import warnings as _warnings
+
+class LossyConversionWarning(UserWarning):
+	"""LossyConversionWarning is warned when a python value is changed by its conversion to Go"""
+	pass
+
+class LossyConversionError(ValueError):
+	"""LossyConversionError is raised when a python value would be changed by its conversion to Go,
+	with the -strict option of gopy"""
+	pass
+
+_lossy_strict = %[1]s
+
+def check_lossy(value, gotype, name):
+	"""check_lossy checks that the value of name converts to the Go float type gotype
+	without changing, warning with LossyConversionWarning or raising LossyConversionError otherwise"""
+	if not isinstance(value, (int, float)) or value != value:
+		return
+	try:
+		exact = _struct.unpack('f', _struct.pack('f', value))[0] == value
+	except OverflowError:
+		exact = False
+	if exact:
+		return
+	msg = "%%s: %%r loses precision as Go %%s" %% (name, value, gotype)
+	if _lossy_strict:
+		raise LossyConversionError(msg)
+	_warnings.warn(msg, LossyConversionWarning, stacklevel=3)
+
+`
+)
+
+// lossyChecks returns whether the python wrappers check lossy conversions
+func (g *pyGen) lossyChecks() bool {
+	return g.cfg.LossyChecks || g.cfg.Strict
+}
+
+// genLossyPyWrap generates the int range and lossy conversion checks of go.py
+func (g *pyGen) genLossyPyWrap() {
+	g.pywrap.Printf("%s", intRangesPyDefs)
+	if !g.lossyChecks() {
+		return
+	}
+	strict := "False"
+	if g.cfg.Strict {
+		strict = "True"
+	}
+	g.pywrap.Printf(lossyPyDefs, strict)
+}
+
+// basicKind returns the kind of the Go basic type of the symbol, or
+// types.Invalid if it is not basic
+func basicKind(sym *symbol) types.BasicKind {
+	if sym == nil || !sym.isBasic() {
+		return types.Invalid
+	}
+	b, ok := sym.gotyp.Underlying().(*types.Basic)
+	if !ok {
+		return types.Invalid
+	}
+	return b.Kind()
+}
+
+// genLossyCheck generates the check of the conversion to Go of the python
+// value of the variable, described by name in the messages: the check of
+// its range for the sized and unsigned ints, and of its precision for
+// float32, with the LossyChecks option.  The ints and int64s are checked
+// by pybindgen, and the runes by genRuneCheck.
+func (g *pyGen) genLossyCheck(vnm string, sym *symbol, name string) {
+	if g.genRuneCheck(vnm, sym, name) {
+		return
+	}
+	switch k := basicKind(sym); k {
+	case types.Int8, types.Int16, types.Int32, types.Uint, types.Uint8, types.Uint16, types.Uint32, types.Uint64, types.Uintptr:
+		g.pywrap.Printf("go.check_int(%s, %q, %q)\n", vnm, types.Typ[k].Name(), name)
+	case types.Float32:
+		if g.lossyChecks() {
+			g.pywrap.Printf("go.check_lossy(%s, %q, %q)\n", vnm, types.Typ[k].Name(), name)
+		}
+	}
+}
+
+// lossyField reports the exported struct field dropped from the bindings
+// for its unsupported type, as a warning, or as an error with the Strict option
+func (g *pyGen) lossyField(s *Struct, f *types.Var) {
+	if !f.Exported() || f.Embedded() || isSkipped(f) || skippedType(f.Type()) != "" {
+		return
+	}
+	why := "unsupported type " + g.pkg.goTypeString(f.Type())
+	msg := fmt.Sprintf("dropping field %s.%s of %s", s.Obj().Name(), f.Name(), why)
+	if unb := unboundType(f.Type()); unb != "" {
+		why = unb
+		msg = fmt.Sprintf("dropping field %s.%s: %s", s.Obj().Name(), f.Name(), why)
+	}
+	statDiag(skipField, g.pkg.Name()+"."+s.Obj().Name()+"."+f.Name(), f, why)
+	if g.cfg.Strict {
+		g.err.Add(fmt.Errorf("gopy: %s", msg))
+		return
+	}
+	if !NoWarn {
+		fmt.Printf("gopy: warning: %s\n", msg)
+	}
+}
@@ -39,14 +39,14 @@ func (g *pyGen) genMap(slc *symbol, extTypes, pyWrapOnly bool, mpob *Map) {
 		// TODO: inherit from collections.Iterable too?
 		g.pywrap.Printf(`
 # Python type for map %[4]s
-class %[2]s(%[5]sGoClass):
+class %[2]s(%[5]s):
 	""%[3]q""
 `,
 			pkgname,
 			pysnm,
 			slc.doc,
 			slc.goname,
-			gocl,
+			g.mixinBases(pysnm, gocl+"GoClass"),
 		)
 		g.pywrap.Indent()
 	}
@@ -79,7 +79,7 @@ func (g *pyGen) genMapInit(slc *symbol, extTypes, pyWrapOnly bool, mpob *Map) {
 	if g.pkg == nil {
 		keyslnm = keyslsym.id
 	} else {
-		keyslnm = keyslsym.pyPkgId(g.pkg.pkg)
+		keyslnm = keyslsym.pyPkgId(g.pkg.pkg, g.pkg)
 	}
 
 	gocl := "go."
@@ -99,17 +99,17 @@ otherwise parameter is a python list that we copy from
 		g.pywrap.Printf("if len(kwargs) == 1 and 'handle' in kwargs:\n")
 		g.pywrap.Indent()
 		g.pywrap.Printf("self.handle = kwargs['handle']\n")
-		g.pywrap.Printf("_%s.IncRef(self.handle)\n", g.pypkgname)
+		g.pywrap.Printf("_%s.IncRef(self.handle)\n", g.cfg.Name)
 		g.pywrap.Outdent()
 		g.pywrap.Printf("elif len(args) == 1 and isinstance(args[0], %sGoClass):\n", gocl)
 		g.pywrap.Indent()
 		g.pywrap.Printf("self.handle = args[0].handle\n")
-		g.pywrap.Printf("_%s.IncRef(self.handle)\n", g.pypkgname)
+		g.pywrap.Printf("_%s.IncRef(self.handle)\n", g.cfg.Name)
 		g.pywrap.Outdent()
 		g.pywrap.Printf("else:\n")
 		g.pywrap.Indent()
 		g.pywrap.Printf("self.handle = _%s_CTor()\n", qNm)
-		g.pywrap.Printf("_%s.IncRef(self.handle)\n", g.pypkgname)
+		g.pywrap.Printf("_%s.IncRef(self.handle)\n", g.cfg.Name)
 		g.pywrap.Printf("if len(args) > 0:\n")
 		g.pywrap.Indent()
 		g.pywrap.Printf("if not isinstance(args[0], _collections_abc.Mapping):\n")
@@ -118,7 +118,7 @@ otherwise parameter is a python list that we copy from
 		g.pywrap.Outdent()
 		g.pywrap.Printf("for k, v in args[0].items():\n")
 		g.pywrap.Indent()
-		g.pywrap.Printf("_%s_set(self.handle, k, v)\n", qNm)
+		g.pywrap.Printf("self[k] = v\n")
 		g.pywrap.Outdent()
 		g.pywrap.Outdent()
 		g.pywrap.Outdent()
@@ -126,7 +126,7 @@ otherwise parameter is a python list that we copy from
 
 		g.pywrap.Printf("def __del__(self):\n")
 		g.pywrap.Indent()
-		g.pywrap.Printf("_%s.DecRef(self.handle)\n", g.pypkgname)
+		g.pywrap.Printf("_%s.DecRef(self.handle)\n", g.cfg.Name)
 		g.pywrap.Outdent()
 
 		if mpob != nil && mpob.prots&ProtoStringer != 0 {
@@ -140,6 +140,7 @@ otherwise parameter is a python list that we copy from
 				}
 			}
 		} else {
+			g.genCycleGuard()
 			g.pywrap.Printf("def __str__(self):\n")
 			g.pywrap.Indent()
 			g.pywrap.Printf("s = '%s.%s len: ' + str(len(self)) + ' handle: ' + str(self.handle) + ' {'\n", pkgname, slNm)
@@ -154,6 +155,7 @@ otherwise parameter is a python list that we copy from
 			g.pywrap.Outdent()
 		}
 
+		g.genCycleGuard()
 		g.pywrap.Printf("def __repr__(self):\n")
 		g.pywrap.Indent()
 		g.pywrap.Printf("s = '%s.%s({'\n", pkgname, slNm)
@@ -171,36 +173,26 @@ otherwise parameter is a python list that we copy from
 
 		g.pywrap.Printf("def __getitem__(self, key):\n")
 		g.pywrap.Indent()
+		karg := "key"
 		if ksym.hasHandle() {
-			if esym.hasHandle() {
-				g.pywrap.Printf("return %s(handle=_%s_elem(self.handle, key.handle))\n", esym.pyPkgId(slc.gopkg), qNm)
-			} else {
-				g.pywrap.Printf("return _%s_elem(self.handle, key.handle)\n", qNm)
-			}
-		} else {
-			if esym.hasHandle() {
-				g.pywrap.Printf("return %s(handle=_%s_elem(self.handle, key))\n", esym.pyPkgId(slc.gopkg), qNm)
-			} else {
-				g.pywrap.Printf("return _%s_elem(self.handle, key)\n", qNm)
-			}
+			karg = "key.handle"
+		}
+		switch {
+		case esym.isPointer():
+			// nil pointers are None
+			g.pywrap.Printf("h = _%s_elem(self.handle, %s)\n", qNm, karg)
+			g.pywrap.Printf("return None if %s else %s(handle=h)\n", pyNilHandle("h"), esym.pyPkgId(slc.gopkg, g.pkg))
+		case esym.hasHandle():
+			g.pywrap.Printf("return %s(handle=_%s_elem(self.handle, %s))\n", esym.pyPkgId(slc.gopkg, g.pkg), qNm, karg)
+		default:
+			g.pywrap.Printf("return _%s_elem(self.handle, %s)\n", qNm, karg)
 		}
 		g.pywrap.Outdent()
 
 		g.pywrap.Printf("def __setitem__(self, key, value):\n")
 		g.pywrap.Indent()
-		if esym.hasHandle() {
-			if ksym.hasHandle() {
-				g.pywrap.Printf("_%s_set(self.handle, key.handle, value.handle)\n", qNm)
-			} else {
-				g.pywrap.Printf("_%s_set(self.handle, key, value.handle)\n", qNm)
-			}
-		} else {
-			if ksym.hasHandle() {
-				g.pywrap.Printf("_%s_set(self.handle, key.handle, value)\n", qNm)
-			} else {
-				g.pywrap.Printf("_%s_set(self.handle, key, value)\n", qNm)
-			}
-		}
+		g.genContainerConv("value", esym, slc.gopkg)
+		g.pywrap.Printf("_%s_set(self.handle, %s, %s)\n", qNm, karg, pyElemArg("value", esym))
 		g.pywrap.Outdent()
 
 		g.pywrap.Printf("def __delitem__(self, key):\n")
@@ -244,6 +236,22 @@ otherwise parameter is a python list that we copy from
 		g.pywrap.Printf("return iter(self.items())\n")
 		g.pywrap.Outdent()
 
+		g.pywrap.Printf("def to_dict(self):\n")
+		g.pywrap.Indent()
+		g.pywrap.Printf(`"""to_dict returns a python dict copy of the items, the nested Go slices and maps converted to lists and dicts"""
+`)
+		g.pywrap.Printf("return {k: %sto_py(v) for k, v in self.items()}\n", gocl)
+		g.pywrap.Outdent()
+
+		g.pywrap.Printf("@classmethod\n")
+		g.pywrap.Printf("def from_dict(cls, values):\n")
+		g.pywrap.Indent()
+		g.pywrap.Printf(`"""from_dict returns a new Go map copied from the python mapping, the nested lists and dicts converted to Go slices and maps.
+The Go slices and maps of values are copied too, not shared"""
+`)
+		g.pywrap.Printf("return cls(%sto_py(values))\n", gocl)
+		g.pywrap.Outdent()
+
 		g.pywrap.Printf("def __contains__(self, key):\n")
 		g.pywrap.Indent()
 		if ksym.hasHandle() {
@@ -303,9 +311,10 @@ otherwise parameter is a python list that we copy from
 		g.gofile.Outdent()
 		g.gofile.Printf("}\n")
 		if esym.go2py != "" {
-			// If the go2py starts with handleFromPtr_, use &v, otherwise just v
+			// If the go2py starts with handleFromPtr_, use &v, otherwise just v,
+			// which pointers already are
 			val_str := ""
-			if strings.HasPrefix(esym.go2py, "handleFromPtr_") {
+			if strings.HasPrefix(esym.go2py, "handleFromPtr_") && !esym.isPointer() {
 				val_str = "&v"
 			} else {
 				val_str = "v"
@@ -391,7 +400,13 @@ otherwise parameter is a python list that we copy from
 }
 
 func (g *pyGen) genMapMethods(s *Map) {
+	var meths []*Func
 	for _, m := range s.meths {
-		g.genMethod(s.sym, m)
+		if g.genMethod(s.sym, m) {
+			meths = append(meths, m)
+		}
 	}
+	g.genOperators(s.sym, meths)
+	g.genContextManager(meths)
+	g.genIOMethods(s.sym, meths)
 }
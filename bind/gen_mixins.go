@@ -0,0 +1,78 @@
+// Copyright 2024 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package bind
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+)
+
+// Python mixins add hand-written python methods to the generated classes,
+// surviving their regeneration: the classes named FooMixin of a _mixins.py
+// file in the output directory are made the first base classes of the
+// classes of the bound types named Foo, e.g.,
+//
+//	class PersonMixin:
+//		def __format__(self, spec):
+//			return format(self.Name, spec)
+//
+// adds a __format__ method to the class of the Person struct.  The package
+// modules import _mixins, so it cannot import them at its top level.
+
+// mixinsFile is the name of the python mixins file of the output directory
+const mixinsFile = "_mixins.py"
+
+// mixinClassRx matches the top-level mixin classes of the mixins file
+var mixinClassRx = regexp.MustCompile(`(?m)^class\s+(\w+)Mixin\b`)
+
+// readMixins returns the names of the classes with mixins in the mixins
+// file of the directory, if any
+func readMixins(dir string) (map[string]bool, error) {
+	src, err := os.ReadFile(filepath.Join(dir, mixinsFile))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	mixins := make(map[string]bool)
+	for _, m := range mixinClassRx.FindAllSubmatch(src, -1) {
+		mixins[string(m[1])] = false
+	}
+	return mixins, nil
+}
+
+// mixinBases returns the bases of the python class, with its mixin, if any,
+// before the base of the generated class
+func (g *pyGen) mixinBases(pyName, base string) string {
+	if g.pkg == goPackage {
+		return base
+	}
+	if _, ok := g.mixins[pyName]; !ok {
+		return base
+	}
+	g.mixins[pyName] = true
+	return "_mixins." + pyName + "Mixin, " + base
+}
+
+// warnMixins warns about the mixins of no generated class
+func (g *pyGen) warnMixins() {
+	if NoWarn {
+		return
+	}
+	var unused []string
+	for nm, used := range g.mixins {
+		if !used {
+			unused = append(unused, nm+"Mixin")
+		}
+	}
+	sort.Strings(unused)
+	for _, nm := range unused {
+		fmt.Printf("gopy: warning: no bound type for the %s class of %s\n", nm, mixinsFile)
+	}
+}
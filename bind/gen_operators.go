@@ -0,0 +1,167 @@
+// Copyright 2024 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package bind
+
+import (
+	"fmt"
+	"go/types"
+)
+
+// The methods of bound types with the conventional Go names of operators,
+// e.g., Add or Less, are also bound to the python operators, calling them,
+// so that math-like Go types can be used with +, <, etc. in python.  The
+// operator directive of a method sets its operator instead, e.g.,
+//
+//	//gopy:operator +
+//	func (v Vec) Plus(o Vec) Vec
+//
+// and //gopy:operator none leaves out a method with a conventional name.
+
+// pyOperator is a python operator that can be bound to a Go method
+type pyOperator struct {
+	dunder string // python method of the operator
+	nargs  int    // number of args of the Go method
+	isBool bool   // whether the Go method must return a bool
+}
+
+// pyOperators are the python operators, by their directive arg
+var pyOperators = map[string]pyOperator{
+	"+":   {"__add__", 1, false},
+	"-":   {"__sub__", 1, false},
+	"*":   {"__mul__", 1, false},
+	"/":   {"__truediv__", 1, false},
+	"%":   {"__mod__", 1, false},
+	"neg": {"__neg__", 0, false},
+	"<":   {"__lt__", 1, true},
+	"<=":  {"__le__", 1, true},
+	">":   {"__gt__", 1, true},
+	">=":  {"__ge__", 1, true},
+	"==":  {"__eq__", 1, true},
+}
+
+// goOperators are the operators of the conventional Go method names
+var goOperators = map[string]string{
+	"Add":   "+",
+	"Sub":   "-",
+	"Mul":   "*",
+	"Div":   "/",
+	"Neg":   "neg",
+	"Less":  "<",
+	"Equal": "==",
+}
+
+// recvTypeName returns the name of the type of the receiver of the method
+func recvTypeName(f *Func) string {
+	fn, ok := f.obj.(*types.Func)
+	if !ok {
+		return ""
+	}
+	recv := fn.Type().(*types.Signature).Recv()
+	if recv == nil {
+		return ""
+	}
+	typ := recv.Type()
+	if ptr, ok := typ.(*types.Pointer); ok {
+		typ = ptr.Elem()
+	}
+	if named, ok := typ.(*types.Named); ok {
+		return named.Obj().Name()
+	}
+	return ""
+}
+
+// methodOperator returns the python operator of the method, and whether
+// it has one, from its operator directive or its name, warning about
+// directives that do not fit the method
+func methodOperator(f *Func) (pyOperator, bool) {
+	tn := recvTypeName(f)
+	arg, isDir := f.pkg.directive(tn+"."+f.GoName(), "operator")
+	op := goOperators[f.GoName()]
+	if isDir {
+		op = arg
+		if op == "none" {
+			return pyOperator{}, false
+		}
+	}
+	if op == "" {
+		return pyOperator{}, false
+	}
+	nargs := len(f.sig.Params())
+	if op == "-" && nargs == 0 {
+		op = "neg"
+	}
+	pop, ok := pyOperators[op]
+	msg := ""
+	switch {
+	case !ok:
+		msg = fmt.Sprintf("unknown operator %q", op)
+	case nargs != pop.nargs:
+		msg = fmt.Sprintf("operator %s takes %d args", op, pop.nargs)
+	default:
+		res := f.sig.Results()
+		if f.err {
+			res = res[:len(res)-1]
+		}
+		switch {
+		case len(res) != 1:
+			msg = fmt.Sprintf("operator %s returns a value", op)
+		case pop.isBool && !isBoolType(res[0].GoType()):
+			msg = fmt.Sprintf("operator %s returns a bool", op)
+		}
+	}
+	if msg != "" {
+		if isDir && !NoWarn {
+			fmt.Printf("gopy: warning: ignoring %soperator directive of %s.%s.%s: %s\n", directivePrefix, f.pkg.Name(), tn, f.GoName(), msg)
+		}
+		return pyOperator{}, false
+	}
+	return pop, true
+}
+
+// isBoolType returns whether the type is a bool
+func isBoolType(typ types.Type) bool {
+	b, ok := typ.Underlying().(*types.Basic)
+	return ok && b.Kind() == types.Bool
+}
+
+// genOperators generates the python operators of the methods of the type,
+// calling the python methods of the methods, which must have been generated
+func (g *pyGen) genOperators(sym *symbol, meths []*Func) {
+	done := make(map[string]bool)
+	for _, m := range meths {
+		pop, ok := methodOperator(m)
+		if !ok || done[pop.dunder] {
+			continue
+		}
+		fn, ok := g.pyFuncName(m)
+		if !ok {
+			continue
+		}
+		done[pop.dunder] = true
+		if pop.nargs == 0 {
+			g.pywrap.Printf("def %s(self):\n", pop.dunder)
+			g.pywrap.Indent()
+			g.pywrap.Printf("\"\"\"%s calls %s\"\"\"\n", pop.dunder, m.GoName())
+			g.pywrap.Printf("return self.%s()\n", fn)
+			g.pywrap.Outdent()
+			continue
+		}
+		g.pywrap.Printf("def %s(self, other):\n", pop.dunder)
+		g.pywrap.Indent()
+		g.pywrap.Printf("\"\"\"%s calls %s\"\"\"\n", pop.dunder, m.GoName())
+		if asym := m.sig.Params()[0].sym; asym.hasHandle() {
+			g.pywrap.Printf("if not isinstance(other, go.GoClass):\n")
+			g.pywrap.Indent()
+			g.pywrap.Printf("return NotImplemented\n")
+			g.pywrap.Outdent()
+		}
+		g.pywrap.Printf("return self.%s(other)\n", fn)
+		g.pywrap.Outdent()
+		if pop.dunder == "__eq__" {
+			// python types that define __eq__ are unhashable, unless they define __hash__
+			g.pywrap.Printf("__hash__ = None\n")
+		}
+	}
+}
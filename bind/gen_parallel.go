@@ -0,0 +1,117 @@
+// Copyright 2024 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package bind
+
+// parallel_map calls a python callable on each item of a sequence from a
+// pool of goroutines.  Each call holds the GIL, so python code is still
+// serialized, but the wrappers of bound Go functions release the GIL while
+// the Go function runs, so calls of bound Go functions run concurrently.
+
+const (
+	// go code for running python callables in goroutines
+	parallelGo = `
+// ---- parallel_map support for calling functions from goroutines ---
+
+//export GoPyParallelMap
+func GoPyParallelMap(fn *C.PyObject, items *C.PyObject, workers C.int) *C.PyObject {
+	n := int(C.PyTuple_Size(items))
+	nw := int(workers)
+	if nw <= 0 {
+		nw = runtime.NumCPU()
+	}
+	if nw > n {
+		nw = n
+	}
+	// res and the first error are only accessed while holding the GIL
+	res := make([]*C.PyObject, n)
+	var errt, errv, errtb *C.PyObject
+	next := int64(-1)
+
+	_saved_thread := C.PyEval_SaveThread()
+	var wg sync.WaitGroup
+	wg.Add(nw)
+	for w := 0; w < nw; w++ {
+		go func() {
+			defer wg.Done()
+			for {
+				i := int(atomic.AddInt64(&next, 1))
+				if i >= n {
+					return
+				}
+				_gstate := C.PyGILState_Ensure()
+				if errt == nil {
+					item := C.PyTuple_GetItem(items, C.Py_ssize_t(i))
+					args := C.PyTuple_New(1)
+					C.gopy_incref(item)
+					C.PyTuple_SetItem(args, 0, item) // steals the new reference
+					res[i] = C.PyObject_CallObject(fn, args)
+					C.gopy_decref(args)
+					if res[i] == nil {
+						C.PyErr_Fetch(&errt, &errv, &errtb)
+					}
+				}
+				C.PyGILState_Release(_gstate)
+			}
+		}()
+	}
+	wg.Wait()
+	C.PyEval_RestoreThread(_saved_thread)
+
+	if errt != nil {
+		for _, r := range res {
+			C.gopy_decref(r)
+		}
+		C.PyErr_Restore(errt, errv, errtb)
+		return nil
+	}
+	lst := C.PyList_New(C.Py_ssize_t(n))
+	for i, r := range res {
+		C.PyList_SetItem(lst, C.Py_ssize_t(i), r) // steals the reference
+	}
+	return lst
+}
+`
+
+	// pybindgen stub for parallelGo
+	parallelPyBuild = `add_checked_function(mod, 'GoPyParallelMap', retval('PyObject*', caller_owns_return=True), [param('PyObject*', 'fn', transfer_ownership=False), param('PyObject*', 'items', transfer_ownership=False), param('int', 'workers')])
+`
+
+	// python go.parallel_map function
+	// 1 = package name
+	parallelPyWrap = `
+# ---- parallel_map support for calling functions from goroutines ---
+def parallel_map(fn, items, workers=0):
+	"""parallel_map returns the list of fn(item) for each of the items, in order.
+	The calls are made from a pool of workers goroutines (0 = number of CPUs).
+	Bound Go functions release the GIL while running, so calls of them run
+	concurrently, while calls of python code are serialized by the GIL.
+	The first exception raised by a call is raised after all running calls
+	are done, and the remaining items are skipped."""
+	return _%[1]s.GoPyParallelMap(fn, tuple(items), workers)
+
+`
+)
+
+// genParallelGo generates the go code and pybindgen stub for go.parallel_map
+func (g *pyGen) genParallelGo() {
+	g.gofile.Printf("%s", parallelGo)
+	g.pybuild.Printf("%s", parallelPyBuild)
+}
+
+// genParallelPyWrap generates the go.parallel_map python function
+func (g *pyGen) genParallelPyWrap() {
+	g.pywrap.Printf(parallelPyWrap, g.cfg.Name)
+}
+
+// genParallelAlias makes go.parallel_map available as parallel_map in
+// the package, unless the package has its own function of that name
+func (g *pyGen) genParallelAlias() {
+	for _, f := range g.pkg.funcs {
+		if fn, _ := g.pyFuncName(f); fn == "parallel_map" {
+			return
+		}
+	}
+	g.pywrap.Printf("\nparallel_map = go.parallel_map\n")
+}
@@ -0,0 +1,184 @@
+// Copyright 2024 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package bind
+
+import (
+	"bytes"
+)
+
+// With the GenPerf option, a <name>_perf_test.go file of Go benchmarks of
+// the conversions of the generated code is generated along with it, with
+// perf and perf-gate Makefile targets: make perf runs the benchmarks,
+// saving their results as perf-baseline.txt, and make perf-gate fails if
+// the conversions got slower than in the baseline, e.g., after a gopy
+// upgrade.  The benchmarks cover the handles of structs and the element
+// access of slices of numbers, which run without python (the test files
+// of a cgo package cannot use cgo, e.g., for C strings).
+
+const (
+	// 1 = name of output package, 2 = command
+	perfGoPreamble = `// Code generated by gopy. DO NOT EDIT.
+// Benchmarks of the conversions of the %[1]s bindings: run them with
+// make perf, and compare them to the baseline with make perf-gate.
+// %[2]s
+
+package main
+
+import (
+	"bufio"
+	"os"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// perfSize is the number of elements of the slices of the benchmarks
+const perfSize = 1000
+
+func BenchmarkBool(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		_ = boolPyToGo(boolGoToPy(i%%2 == 0))
+	}
+}
+`
+
+	// perfGoGate is the gate test, after the benchmarks and perfBenchmarks
+	perfGoGate = `
+// TestPerfGate fails if the benchmarks are slower than in the baseline file,
+// in the go test -bench output format, named by $GOPY_PERF_BASELINE, by more
+// than the $GOPY_PERF_TOLERANCE fraction (0.2 by default)
+func TestPerfGate(t *testing.T) {
+	fn := os.Getenv("GOPY_PERF_BASELINE")
+	if fn == "" {
+		t.Skip("GOPY_PERF_BASELINE is not set")
+	}
+	tol := 0.2
+	if s := os.Getenv("GOPY_PERF_TOLERANCE"); s != "" {
+		v, err := strconv.ParseFloat(s, 64)
+		if err != nil {
+			t.Fatalf("invalid GOPY_PERF_TOLERANCE: %%v", err)
+		}
+		tol = v
+	}
+	f, err := os.Open(fn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	sc := bufio.NewScanner(f)
+	for sc.Scan() {
+		fs := strings.Fields(sc.Text())
+		if len(fs) < 4 || fs[3] != "ns/op" || !strings.HasPrefix(fs[0], "Benchmark") {
+			continue
+		}
+		name := fs[0]
+		if i := strings.LastIndex(name, "-"); i > 0 {
+			name = name[:i]
+		}
+		base, err := strconv.ParseFloat(fs[2], 64)
+		if err != nil {
+			continue
+		}
+		bench, ok := perfBenchmarks[name]
+		if !ok {
+			continue
+		}
+		ns := float64(testing.Benchmark(bench).NsPerOp())
+		if ns > base*(1+tol) {
+			t.Errorf("%%s: %%.1f ns/op, slower than the baseline %%.1f ns/op by more than %%g%%%%", name, ns, base, 100*tol)
+		}
+	}
+	if err := sc.Err(); err != nil {
+		t.Fatal(err)
+	}
+}
+`
+
+	// 1 = name of output package
+	perfMakefile = `
+perf:
+	# run the benchmarks of the conversions, saving them as the baseline of perf-gate
+	$(GOCMD) test -mod=mod -run '^$$' -bench . -benchmem %[1]s.go %[1]s_perf_test.go | tee perf-baseline.txt
+
+perf-gate:
+	# fail if the conversions got slower than in perf-baseline.txt (GOPY_PERF_TOLERANCE=0.2 by default)
+	GOPY_PERF_BASELINE=perf-baseline.txt $(GOCMD) test -mod=mod -run TestPerfGate -v %[1]s.go %[1]s_perf_test.go
+
+`
+)
+
+// perfNumber returns whether the symbol is a number or a bool, whose
+// conversions do not need python
+func perfNumber(sym *symbol) bool {
+	return sym.isBasic() && sym.cpyname != "PyObject*" && sym.cgoname != "*C.char"
+}
+
+// genPerf generates the benchmarks of the conversions, with the GenPerf option
+func (g *pyGen) genPerf() {
+	if !g.cfg.GenPerf {
+		return
+	}
+	pr := &printer{buf: new(bytes.Buffer), indentEach: []byte("\t")}
+	pr.Printf(perfGoPreamble, g.cfg.Name, g.cfg.Cmd)
+	benchs := []string{"BenchmarkBool"}
+
+	for _, sid := range g.perfStructs {
+		bn := "BenchmarkHandle_" + sid
+		benchs = append(benchs, bn)
+		pr.Printf("\nfunc %s(b *testing.B) {\n", bn)
+		pr.Indent()
+		pr.Printf("p := ptrFromHandle_%s(%s_CTor())\n", sid, sid)
+		pr.Printf("for i := 0; i < b.N; i++ {\n")
+		pr.Indent()
+		pr.Printf("h := CGoHandle(handleFromPtr_%s(p))\n", sid)
+		pr.Printf("IncRef(h)\n")
+		pr.Printf("_ = ptrFromHandle_%s(h)\n", sid)
+		pr.Printf("DecRef(h)\n")
+		pr.Outdent()
+		pr.Printf("}\n")
+		pr.Outdent()
+		pr.Printf("}\n")
+	}
+
+	for _, slNm := range g.perfSlices {
+		for _, op := range []string{"Elem", "Set"} {
+			bn := "Benchmark" + op + "_" + slNm
+			benchs = append(benchs, bn)
+			pr.Printf("\nfunc %s(b *testing.B) {\n", bn)
+			pr.Indent()
+			pr.Printf("h := %s_CTor()\n", slNm)
+			pr.Printf("IncRef(h)\n")
+			pr.Printf("defer DecRef(h)\n")
+			pr.Printf("for i := 0; i < perfSize; i++ {\n")
+			pr.Indent()
+			pr.Printf("%s_append(h, 0)\n", slNm)
+			pr.Outdent()
+			pr.Printf("}\n")
+			pr.Printf("b.ResetTimer()\n")
+			pr.Printf("for i := 0; i < b.N; i++ {\n")
+			pr.Indent()
+			if op == "Elem" {
+				pr.Printf("_ = %s_elem(h, i%%perfSize)\n", slNm)
+			} else {
+				pr.Printf("%s_set(h, i%%perfSize, 1)\n", slNm)
+			}
+			pr.Outdent()
+			pr.Printf("}\n")
+			pr.Outdent()
+			pr.Printf("}\n")
+		}
+	}
+
+	pr.Printf("\n// perfBenchmarks are the benchmarks checked by TestPerfGate, by name\n")
+	pr.Printf("var perfBenchmarks = map[string]func(b *testing.B){\n")
+	pr.Indent()
+	for _, bn := range benchs {
+		pr.Printf("%q: %s,\n", bn, bn)
+	}
+	pr.Outdent()
+	pr.Printf("}\n")
+	pr.Printf(perfGoGate)
+	g.genPrintOut(g.cfg.Name+"_perf_test.go", pr)
+}
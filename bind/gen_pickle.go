@@ -0,0 +1,66 @@
+// Copyright 2024 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package bind
+
+// Structs are pickled as the JSON of their exported fields, marshaled and
+// unmarshaled on the Go side with encoding/json, so the json field tags
+// apply, and the unexported fields are reset to their zero values by the
+// unpickling.  Structs whose fields cannot be marshaled, e.g., channels or
+// functions, raise a go.GoError when pickled.
+
+// genStructPickle generates the __reduce__ and __setstate__ methods of the
+// python class of the struct, and the go functions they call
+func (g *pyGen) genStructPickle(s *Struct) {
+	pkgname := g.cfg.Name
+	marshal := s.ID() + "_GoPyMarshal"
+	unmarshal := s.ID() + "_GoPyUnmarshal"
+
+	g.pywrap.Printf("def __reduce__(self):\n")
+	g.pywrap.Indent()
+	g.pywrap.Printf(`"""__reduce__ pickles the struct as the JSON of its exported fields"""
+`)
+	g.pywrap.Printf("return (self.__class__, (), _%s.%s(self.handle))\n", pkgname, marshal)
+	g.pywrap.Outdent()
+
+	g.pywrap.Printf("def __setstate__(self, state):\n")
+	g.pywrap.Indent()
+	g.pywrap.Printf(`"""__setstate__ sets the exported fields of the struct from their JSON, when unpickled"""
+`)
+	g.pywrap.Printf("_%s.%s(self.handle, state)\n", pkgname, unmarshal)
+	g.pywrap.Outdent()
+
+	g.gofile.Printf("//export %s\n", marshal)
+	g.gofile.Printf("func %s(handle CGoHandle) *C.char {\n", marshal)
+	g.gofile.Indent()
+	g.gofile.Printf("b, err := json.Marshal(ptrFromHandle_%s(handle))\n", s.ID())
+	g.gofile.Printf("if err != nil {\n")
+	g.gofile.Indent()
+	g.gofile.Printf("estr := C.CString(err.Error())\n")
+	g.gofile.Printf("gopyRaise(err, estr)\n")
+	g.gofile.Printf("return estr\n")
+	g.gofile.Outdent()
+	g.gofile.Printf("}\n")
+	g.gofile.Printf("return C.CString(string(b))\n")
+	g.gofile.Outdent()
+	g.gofile.Printf("}\n\n")
+
+	g.gofile.Printf("//export %s\n", unmarshal)
+	g.gofile.Printf("func %s(handle CGoHandle, state *C.char) *C.char {\n", unmarshal)
+	g.gofile.Indent()
+	g.gofile.Printf("err := json.Unmarshal([]byte(C.GoString(state)), ptrFromHandle_%s(handle))\n", s.ID())
+	g.gofile.Printf("if err != nil {\n")
+	g.gofile.Indent()
+	g.gofile.Printf("estr := C.CString(err.Error())\n")
+	g.gofile.Printf("gopyRaise(err, estr)\n")
+	g.gofile.Printf("return estr\n")
+	g.gofile.Outdent()
+	g.gofile.Printf("}\n")
+	g.gofile.Printf("return C.CString(\"\")\n")
+	g.gofile.Outdent()
+	g.gofile.Printf("}\n\n")
+
+	g.pybuild.Printf("add_checked_string_function(mod, '%s', retval('char*'), [param('%s', 'handle')])\n", marshal, PyHandle)
+	g.pybuild.Printf("add_checked_string_function(mod, '%s', retval('char*'), [param('%s', 'handle'), param('char*', 'state')])\n", unmarshal, PyHandle)
+}
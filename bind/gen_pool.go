@@ -0,0 +1,78 @@
+// Copyright 2024 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package bind
+
+// The structs whose doc comment has a //gopy:pooled line, e.g., those
+// created and dropped at a high rate by python loops, are constructed from
+// a sync.Pool of their values, and have a release method returning their
+// value to the pool, reset to its zero value, e.g.,
+//
+//	for x in xs:
+//		p = geom.Point(X=x)
+//		total += p.Norm()
+//		p.release()
+//
+// so that the next construction reuses it instead of allocating a new one,
+// reducing the garbage collected by Go.  The value is only returned to the
+// pool when the handle of the object is no longer used by other python
+// objects, e.g., made by Point(p); otherwise release only detaches the
+// object from it.  The object must not be used after its release, nor the
+// value by other references to it, e.g., those of other handles returned
+// by Go, or kept by Go.  Objects not released are garbage collected as
+// usual.
+
+// isPooled returns whether the named struct of the package is constructed
+// from a pool of its values, by a //gopy:pooled directive
+func (p *Package) isPooled(name string) bool {
+	_, ok := p.directive(name, "pooled")
+	return ok
+}
+
+// structNew returns the go expression of a new value of the struct, from
+// its pool if pooled
+func structNew(s *Struct) string {
+	if s.pkg.isPooled(s.obj.Name()) {
+		return "gopyPool_" + s.ID() + ".Get().(*" + s.GoName() + ")"
+	}
+	return "&" + s.GoName() + "{}"
+}
+
+// genStructPool generates the release method of the struct, if pooled,
+// and the pool of its values, with the go function returning a value to it
+func (g *pyGen) genStructPool(s *Struct) {
+	if !s.pkg.isPooled(s.obj.Name()) {
+		return
+	}
+	releaseFn := s.ID() + "_GoPyRelease"
+	if !g.hasStructMember(s, "release") {
+		g.pywrap.Printf("def release(self):\n")
+		g.pywrap.Indent()
+		g.pywrap.Printf(`"""release returns the Go value to the pool of the %s constructor, for reuse, if no other object
+uses its handle -- neither this object nor other references to the value may be used after"""
+`, s.GoName())
+		g.pywrap.Printf("h = self.handle\n")
+		g.pywrap.Printf("self.handle = %s\n", curHandle.pyZero)
+		g.pywrap.Printf("_%s.%s(h)\n", g.cfg.Name, releaseFn)
+		g.pywrap.Outdent()
+	}
+
+	g.gofile.Printf("\n// gopyPool_%s is the pool of the values of the pooled struct %s\n", s.ID(), s.GoName())
+	g.gofile.Printf("var gopyPool_%s = sync.Pool{New: func() interface{} { return &%s{} }}\n", s.ID(), s.GoName())
+	g.gofile.Printf("\n//export %s\n", releaseFn)
+	g.gofile.Printf("func %s(handle CGoHandle) {\n", releaseFn)
+	g.gofile.Indent()
+	g.gofile.Printf("p := ptrFromHandle_%s(handle)\n", s.ID())
+	g.gofile.Printf("h := handleGo(handle)\n")
+	g.gofile.Printf("if gopyh.Release(h) && p != nil {\n")
+	g.gofile.Indent()
+	g.gofile.Printf("*p = %s{}\n", s.GoName())
+	g.gofile.Printf("gopyPool_%s.Put(p)\n", s.ID())
+	g.gofile.Outdent()
+	g.gofile.Printf("}\n")
+	g.gofile.Printf("handleFree(h)\n")
+	g.gofile.Outdent()
+	g.gofile.Printf("}\n")
+	g.pybuild.Printf("mod.add_function('%s', None, [param('%s', 'handle')])\n", releaseFn, PyHandle)
+}
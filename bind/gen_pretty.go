@@ -0,0 +1,65 @@
+// Copyright 2024 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package bind
+
+import (
+	"fmt"
+)
+
+// With the Pretty option, all python classes get pretty() and to_yaml()
+// methods from go.GoClass, rendering the Go value of their handle, using
+// gopyh.Pretty and gopyh.YAML.
+
+const (
+	// go code for rendering handles
+	prettyGo = `
+// ---- pretty() and to_yaml() support for rendering Go values ---
+
+//export GoPyPretty
+func GoPyPretty(h CGoHandle) *C.char {
+	v, _ := gopyh.VarFromHandleTry(handleGo(h), "")
+	return C.CString(gopyh.Pretty(v))
+}
+
+//export GoPyYAML
+func GoPyYAML(h CGoHandle) *C.char {
+	v, _ := gopyh.VarFromHandleTry(handleGo(h), "")
+	return C.CString(gopyh.YAML(v))
+}
+`
+
+	// pybindgen stubs for prettyGo
+	prettyPyBuild = `add_checked_string_function(mod, 'GoPyPretty', retval('char*'), [param('%[1]s', 'h')])
+add_checked_string_function(mod, 'GoPyYAML', retval('char*'), [param('%[1]s', 'h')])
+`
+
+	// go.GoClass methods
+	// 1 = package name
+	prettyPyMethods = `	def pretty(self):
+		"""pretty returns the Go value pretty-printed with the types of all values, for debugging"""
+		return _%[1]s.GoPyPretty(self.handle)
+	def to_yaml(self):
+		"""to_yaml returns the Go value rendered as YAML, with the exported fields of structs"""
+		return _%[1]s.GoPyYAML(self.handle)
+`
+)
+
+// genPrettyGo generates the go code and pybindgen stubs for pretty()
+// and to_yaml()
+func (g *pyGen) genPrettyGo() {
+	if !g.cfg.Pretty {
+		return
+	}
+	g.gofile.Printf("%s", prettyGo)
+	g.pybuild.Printf(prettyPyBuild, PyHandle)
+}
+
+// prettyPyMethods returns the go.GoClass methods pretty() and to_yaml()
+func (g *pyGen) prettyPyMethods() string {
+	if !g.cfg.Pretty {
+		return ""
+	}
+	return fmt.Sprintf(prettyPyMethods, g.cfg.Name)
+}
@@ -0,0 +1,130 @@
+// Copyright 2024 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package bind
+
+import (
+	"fmt"
+	"go/types"
+	"sort"
+	"strings"
+)
+
+// Each package has a __go_types__ registry, mapping the qualified Go name
+// of each of its types (e.g., "hi.Person") to a go.GoType describing the
+// wrapper class, with the metadata of its fields and methods, for generic
+// python code working on any bindings, e.g., serializers.
+
+const (
+	// python classes of the registry entries, in go.py
+	registryPyDefs = `
+# ---- Go type registry: the __go_types__ of each package ---
+GoType = collections.namedtuple('GoType', ['name', 'kind', 'cls', 'fields', 'methods', 'doc'])
+GoType.__doc__ = """GoType describes the wrapper class cls of the Go type name, of the given kind
+(struct, dataclass, interface, slice, map or enum), in the __go_types__ registry of its package"""
+
+GoField = collections.namedtuple('GoField', ['name', 'goname', 'gotype', 'pytype', 'doc'])
+GoField.__doc__ = """GoField describes a struct field, accessed as the name property of the wrapper class"""
+
+GoMethod = collections.namedtuple('GoMethod', ['name', 'goname', 'args', 'returns', 'doc'])
+GoMethod.__doc__ = """GoMethod describes a method of the wrapper class, with the names of its args,
+and the python types of its return values (not including errors, which are raised)"""
+
+`
+)
+
+// genRegistryPyWrap generates the registry classes of go.py
+func (g *pyGen) genRegistryPyWrap() {
+	g.pywrap.Printf("%s", registryPyDefs)
+}
+
+// genRegistry generates the __go_types__ registry of the package
+func (g *pyGen) genRegistry() {
+	p := g.pkg
+	pn := p.Name()
+
+	entries := make(map[string]string)
+	add := func(name, kind, cls string, fields []string, meths []*Func, doc string) {
+		qn := pn + "." + name
+		entries[qn] = fmt.Sprintf("go.GoType(%q, %q, %s, %s, %s, %q)", qn, kind, cls, pyTuple(fields), g.registryMethods(meths), doc)
+	}
+
+	for _, s := range p.structs {
+		var fields []string
+		typ := s.Struct()
+		for i := 0; i < typ.NumFields(); i++ {
+			f := typ.Field(i)
+			ftyp, err := isPyCompatField(f)
+			if err != nil {
+				continue
+			}
+			gname := g.pyFieldName(s, i)
+			fields = append(fields, fmt.Sprintf("go.GoField(%q, %q, %q, %q, %q)", gname, f.Name(), p.goTypeString(f.Type()), ftyp.pysig, strings.TrimSpace(p.getDoc(s.obj.Name(), f))))
+		}
+		kind := "struct"
+		if isDataclass(s.sym) {
+			kind = "dataclass"
+		}
+		add(s.obj.Name(), kind, s.obj.Name(), fields, s.meths, s.Doc())
+	}
+	for _, ifc := range p.ifaces {
+		add(ifc.obj.Name(), "interface", ifc.obj.Name(), nil, ifc.meths, ifc.Doc())
+	}
+	for _, s := range p.slices {
+		add(s.obj.Name(), "slice", s.obj.Name(), nil, s.meths, s.doc)
+	}
+	for _, m := range p.maps {
+		add(m.obj.Name(), "map", m.obj.Name(), nil, m.meths, m.doc)
+	}
+	for _, e := range p.enums {
+		add(e.typ.Obj().Name(), "enum", e.typ.Obj().Name(), nil, nil, e.Doc())
+	}
+
+	names := make([]string, 0, len(entries))
+	for n := range entries {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+
+	g.pywrap.Printf("__go_types__ = {\n")
+	g.pywrap.Indent()
+	for _, n := range names {
+		g.pywrap.Printf("%q: %s,\n", n, entries[n])
+	}
+	g.pywrap.Outdent()
+	g.pywrap.Printf("}\n")
+}
+
+// registryMethods returns the python tuple of go.GoMethod of the methods
+func (g *pyGen) registryMethods(meths []*Func) string {
+	var ms []string
+	for _, m := range sortedFuncs(meths) {
+		fn, ok := g.pyFuncName(m)
+		if !ok {
+			continue
+		}
+		args, rets, _ := m.pkg.docItems(m.obj.Type().(*types.Signature), m.commaOk)
+		anms := make([]string, len(args))
+		for i, a := range args {
+			anms[i] = fmt.Sprintf("%q", a.name)
+		}
+		rtyps := make([]string, len(rets))
+		for i, r := range rets {
+			rtyps[i] = fmt.Sprintf("%q", r.typ)
+		}
+		ms = append(ms, fmt.Sprintf("go.GoMethod(%q, %q, %s, %s, %q)", fn, m.GoName(), pyTuple(anms), pyTuple(rtyps), funcDocBody(m)))
+	}
+	return pyTuple(ms)
+}
+
+// pyTuple returns the python tuple of the items
+func pyTuple(items []string) string {
+	switch len(items) {
+	case 0:
+		return "()"
+	case 1:
+		return "(" + items[0] + ",)"
+	}
+	return "(" + strings.Join(items, ", ") + ")"
+}
@@ -0,0 +1,108 @@
+// Copyright 2024 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package bind
+
+// go.run calls a python callable, typically a bound Go function, in a new
+// goroutine, returning a concurrent.futures.Future of its result.  The call
+// holds the GIL, but the wrappers of bound Go functions release it while
+// the Go function runs, so that CPU-heavy Go work does not block the calling
+// thread.  The future is completed from the goroutine, holding the GIL, so
+// its done callbacks run there.  asyncio code can await it with
+// asyncio.wrap_future.
+
+const (
+	// go code for calling python callables in new goroutines
+	runGo = `
+// ---- run support for calling functions in goroutines ---
+
+//export GoPyRun
+func GoPyRun(call, done *C.PyObject) {
+	C.gopy_incref(call)
+	C.gopy_incref(done)
+	go func() {
+		_gstate := C.PyGILState_Ensure()
+		ok := C.long(1)
+		res := C.PyObject_CallObject(call, nil)
+		if res == nil {
+			var errt, errtb *C.PyObject
+			ok = 0
+			C.PyErr_Fetch(&errt, &res, &errtb)
+			C.PyErr_NormalizeException(&errt, &res, &errtb)
+			if errtb != nil {
+				C.PyException_SetTraceback(res, errtb)
+			}
+			C.gopy_decref(errt)
+			C.gopy_decref(errtb)
+		}
+		args := C.PyTuple_New(2)
+		C.PyTuple_SetItem(args, 0, C.PyBool_FromLong(ok)) // steals the new references
+		C.PyTuple_SetItem(args, 1, res)
+		C.gopy_decref(C.PyObject_CallObject(done, args))
+		C.gopy_err_handle()
+		C.gopy_decref(args)
+		C.gopy_decref(call)
+		C.gopy_decref(done)
+		C.PyGILState_Release(_gstate)
+	}()
+}
+`
+
+	// pybindgen stub for runGo
+	runPyBuild = `mod.add_function('GoPyRun', None, [param('PyObject*', 'call', transfer_ownership=False), param('PyObject*', 'done', transfer_ownership=False)])
+`
+
+	// python go.run function
+	// 1 = package name
+	runPyWrap = `
+# ---- run support for calling functions in goroutines ---
+import concurrent.futures as _futures
+
+def run(fn, *args, **kwargs):
+	"""run calls fn(*args, **kwargs) in a new goroutine, returning a concurrent.futures.Future
+	of its result, or of the exception it raised.  Bound Go functions release the GIL while
+	running, so that the Go call does not block the calling thread.  The future is completed,
+	and its done callbacks called, from the goroutine.  Use asyncio.wrap_future to await it."""
+	fut = _futures.Future()
+	fut.set_running_or_notify_cancel()
+	def call():
+		return fn(*args, **kwargs)
+	def done(ok, value):
+		if ok:
+			fut.set_result(value)
+		else:
+			fut.set_exception(value)
+	_%[1]s.GoPyRun(call, done)
+	return fut
+
+`
+)
+
+// genRunGo generates the go code and pybindgen stub for go.run
+func (g *pyGen) genRunGo() {
+	g.gofile.Printf("%s", runGo)
+	g.pybuild.Printf("%s", runPyBuild)
+}
+
+// genRunPyWrap generates the go.run python function
+func (g *pyGen) genRunPyWrap() {
+	g.pywrap.Printf(runPyWrap, g.cfg.Name)
+}
+
+// genRunAlias makes go.run and go.run_async available as run and run_async
+// in the package, except those with the name of a function of the package
+func (g *pyGen) genRunAlias() {
+	names := make(map[string]bool)
+	for _, f := range g.pkg.funcs {
+		if fn, ok := g.pyFuncName(f); ok {
+			names[fn] = true
+		}
+	}
+	g.pywrap.Printf("\n")
+	for _, nm := range []string{"run", "run_async"} {
+		if !names[nm] {
+			g.pywrap.Printf("%[1]s = go.%[1]s\n", nm)
+		}
+	}
+}
@@ -0,0 +1,77 @@
+// Copyright 2024 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package bind
+
+import (
+	"go/types"
+)
+
+// Go runes are passed to and from python as single character strs, and
+// []rune as strs, copied both ways, including in struct fields, variables
+// and the args and returns of callbacks.  The runes returned to python that
+// are not valid code points become U+FFFD, as in Go, and the values passed
+// to Go are checked by the python wrappers, raising TypeError for values
+// of other types and ValueError for invalid code points, e.g., surrogates,
+// with the int code points still accepted as runes.  The named []rune
+// types keep their python classes, proxying the Go slices.
+
+const (
+	// python checks of the runes passed to Go, in go.py
+	runesPyDefs = `
+# ---- Checks of the python strs converted to Go runes ---
+def check_rune(value, name):
+	"""check_rune raises TypeError if the value of name is neither a single character str nor an int,
+	and ValueError if it is not a valid Go rune, i.e., a Unicode code point other than a surrogate"""
+	if isinstance(value, str):
+		if len(value) != 1:
+			raise ValueError("%s: expected a single character str for Go rune, got %r" % (name, value))
+		value = ord(value)
+	elif not isinstance(value, int):
+		raise TypeError("%s: expected a single character str for Go rune, got %s" % (name, type(value).__name__))
+	if not 0 <= value <= 0x10FFFF or 0xD800 <= value < 0xE000:
+		raise ValueError("%s: invalid code point %d for Go rune" % (name, value))
+
+def check_runes(value, name):
+	"""check_runes raises TypeError if the value of name is not a str, to be converted to a Go []rune"""
+	if not isinstance(value, str):
+		raise TypeError("%s: expected a str for Go []rune, got %s" % (name, type(value).__name__))
+
+`
+)
+
+// isRune returns whether the symbol is that of rune, or a named type of
+// rune, which are passed as single character strs
+func isRune(sym *symbol) bool {
+	if sym == nil || !sym.isBasic() || sym.gotyp == nil {
+		return false
+	}
+	b, ok := sym.gotyp.Underlying().(*types.Basic)
+	return ok && b.Name() == "rune"
+}
+
+// isRunes returns whether the symbol is that of []rune, passed as a str
+func isRunes(sym *symbol) bool {
+	return sym != nil && sym.gotyp != nil && sym.isConverted() && types.TypeString(sym.gotyp, nil) == "[]rune"
+}
+
+// genRunesPyWrap generates the rune checks of go.py
+func (g *pyGen) genRunesPyWrap() {
+	g.pywrap.Printf("%s", runesPyDefs)
+}
+
+// genRuneCheck generates the check of the python value of the variable,
+// described by name in the messages, if it is converted to a Go rune or
+// []rune, returning whether it is
+func (g *pyGen) genRuneCheck(vnm string, sym *symbol, name string) bool {
+	switch {
+	case isRune(sym):
+		g.pywrap.Printf("go.check_rune(%s, %q)\n", vnm, name)
+	case isRunes(sym):
+		g.pywrap.Printf("go.check_runes(%s, %q)\n", vnm, name)
+	default:
+		return false
+	}
+	return true
+}
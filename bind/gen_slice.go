@@ -38,14 +38,14 @@ func (g *pyGen) genSlice(slc *symbol, extTypes, pyWrapOnly bool, slob *Slice) {
 		// TODO: inherit from collections.Iterable too?
 		g.pywrap.Printf(`
 # Python type for slice %[4]s
-class %[2]s(%[5]sGoClass):
+class %[2]s(%[5]s):
 	""%[3]q""
 `,
 			pkgname,
 			pysnm,
 			slc.doc,
 			slc.goname,
-			gocl,
+			g.mixinBases(pysnm, gocl+"GoClass"),
 		)
 		g.pywrap.Indent()
 	}
@@ -92,36 +92,48 @@ otherwise parameter is a python list that we copy from
 		g.pywrap.Printf("if len(kwargs) == 1 and 'handle' in kwargs:\n")
 		g.pywrap.Indent()
 		g.pywrap.Printf("self.handle = kwargs['handle']\n")
-		g.pywrap.Printf("_%s.IncRef(self.handle)\n", g.pypkgname)
+		g.pywrap.Printf("_%s.IncRef(self.handle)\n", g.cfg.Name)
 		g.pywrap.Outdent()
 		g.pywrap.Printf("elif len(args) == 1 and isinstance(args[0], %sGoClass):\n", gocl)
 		g.pywrap.Indent()
 		g.pywrap.Printf("self.handle = args[0].handle\n")
-		g.pywrap.Printf("_%s.IncRef(self.handle)\n", g.pypkgname)
+		g.pywrap.Printf("_%s.IncRef(self.handle)\n", g.cfg.Name)
+		g.pywrap.Outdent()
+		g.pywrap.Printf("else:\n")
+		g.pywrap.Indent()
+		g.pywrap.Printf("self.handle = _%s_CTor()\n", qNm)
+		g.pywrap.Printf("_%s.IncRef(self.handle)\n", g.cfg.Name)
+		g.pywrap.Printf("if len(args) > 0:\n")
+		g.pywrap.Indent()
+		g.pywrap.Printf("if not isinstance(args[0], _collections_abc.Iterable):\n")
+		g.pywrap.Indent()
+		g.pywrap.Printf("raise TypeError('%s.__init__ takes a sequence as argument')\n", slNm)
 		g.pywrap.Outdent()
 		if slc.isSlice() {
-			g.pywrap.Printf("else:\n")
-			g.pywrap.Indent()
-			g.pywrap.Printf("self.handle = _%s_CTor()\n", qNm)
-			g.pywrap.Printf("_%s.IncRef(self.handle)\n", g.pypkgname)
-			g.pywrap.Printf("if len(args) > 0:\n")
-			g.pywrap.Indent()
-			g.pywrap.Printf("if not isinstance(args[0], _collections_abc.Iterable):\n")
-			g.pywrap.Indent()
-			g.pywrap.Printf("raise TypeError('%s.__init__ takes a sequence as argument')\n", slNm)
-			g.pywrap.Outdent()
 			g.pywrap.Printf("for elt in args[0]:\n")
 			g.pywrap.Indent()
 			g.pywrap.Printf("self.append(elt)\n")
 			g.pywrap.Outdent()
+		} else {
+			// arrays are only set from sequences of their length
+			alen := slc.GoType().Underlying().(*types.Array).Len()
+			g.pywrap.Printf("vals = list(args[0])\n")
+			g.pywrap.Printf("if len(vals) != %d:\n", alen)
+			g.pywrap.Indent()
+			g.pywrap.Printf("raise ValueError('%s.__init__ takes a sequence of %d elements, not ' + str(len(vals)))\n", slNm, alen)
 			g.pywrap.Outdent()
+			g.pywrap.Printf("for i, elt in enumerate(vals):\n")
+			g.pywrap.Indent()
+			g.pywrap.Printf("self[i] = elt\n")
 			g.pywrap.Outdent()
 		}
 		g.pywrap.Outdent()
+		g.pywrap.Outdent()
+		g.pywrap.Outdent()
 
 		g.pywrap.Printf("def __del__(self):\n")
 		g.pywrap.Indent()
-		g.pywrap.Printf("_%s.DecRef(self.handle)\n", g.pypkgname)
+		g.pywrap.Printf("_%s.DecRef(self.handle)\n", g.cfg.Name)
 		g.pywrap.Outdent()
 
 		if slob != nil && slob.prots&ProtoStringer != 0 {
@@ -134,6 +146,7 @@ otherwise parameter is a python list that we copy from
 				}
 			}
 		} else {
+			g.genCycleGuard()
 			g.pywrap.Printf("def __str__(self):\n")
 			g.pywrap.Indent()
 			g.pywrap.Printf("s = '%s.%s len: ' + str(len(self)) + ' handle: ' + str(self.handle) + ' ['\n", pkgname, slNm)
@@ -145,6 +158,7 @@ otherwise parameter is a python list that we copy from
 			g.pywrap.Outdent()
 		}
 
+		g.genCycleGuard()
 		g.pywrap.Printf("def __repr__(self):\n")
 		g.pywrap.Indent()
 		g.pywrap.Printf("return '%s.%s([' + ', '.join(map(str, self)) + '])'\n", pkgname, slNm)
@@ -187,9 +201,14 @@ otherwise parameter is a python list that we copy from
 		g.pywrap.Indent()
 		g.pywrap.Printf("raise IndexError('slice index out of range')\n")
 		g.pywrap.Outdent()
-		if esym.hasHandle() {
-			g.pywrap.Printf("return %s(handle=_%s_elem(self.handle, key))\n", esym.pyPkgId(slc.gopkg), qNm)
-		} else {
+		switch {
+		case esym.isPointer():
+			// nil pointers are None
+			g.pywrap.Printf("h = _%s_elem(self.handle, key)\n", qNm)
+			g.pywrap.Printf("return None if %s else %s(handle=h)\n", pyNilHandle("h"), esym.pyPkgId(slc.gopkg, g.pkg))
+		case esym.hasHandle():
+			g.pywrap.Printf("return %s(handle=_%s_elem(self.handle, key))\n", esym.pyPkgId(slc.gopkg, g.pkg), qNm)
+		default:
 			g.pywrap.Printf("return _%s_elem(self.handle, key)\n", qNm)
 		}
 		g.pywrap.Outdent()
@@ -207,11 +226,8 @@ otherwise parameter is a python list that we copy from
 		g.pywrap.Outdent()
 		g.pywrap.Printf("if idx < len(self):\n")
 		g.pywrap.Indent()
-		if esym.hasHandle() {
-			g.pywrap.Printf("_%s_set(self.handle, idx, value.handle)\n", qNm)
-		} else {
-			g.pywrap.Printf("_%s_set(self.handle, idx, value)\n", qNm)
-		}
+		g.genContainerConv("value", esym, slc.gopkg)
+		g.pywrap.Printf("_%s_set(self.handle, idx, %s)\n", qNm, pyElemArg("value", esym))
 		g.pywrap.Println("return")
 		g.pywrap.Outdent()
 		g.pywrap.Printf("raise IndexError('slice index out of range')\n")
@@ -246,9 +262,13 @@ otherwise parameter is a python list that we copy from
 		g.pywrap.Indent()
 		g.pywrap.Printf("if self.index < len(self):\n")
 		g.pywrap.Indent()
-		if esym.hasHandle() {
-			g.pywrap.Printf("rv = %s(handle=_%s_elem(self.handle, self.index))\n", esym.pyPkgId(slc.gopkg), qNm)
-		} else {
+		switch {
+		case esym.isPointer():
+			g.pywrap.Printf("h = _%s_elem(self.handle, self.index)\n", qNm)
+			g.pywrap.Printf("rv = None if %s else %s(handle=h)\n", pyNilHandle("h"), esym.pyPkgId(slc.gopkg, g.pkg))
+		case esym.hasHandle():
+			g.pywrap.Printf("rv = %s(handle=_%s_elem(self.handle, self.index))\n", esym.pyPkgId(slc.gopkg, g.pkg), qNm)
+		default:
 			g.pywrap.Printf("rv = _%s_elem(self.handle, self.index)\n", qNm)
 		}
 		g.pywrap.Println("self.index = self.index + 1")
@@ -260,11 +280,8 @@ otherwise parameter is a python list that we copy from
 		if slc.isSlice() {
 			g.pywrap.Printf("def append(self, value):\n")
 			g.pywrap.Indent()
-			if esym.hasHandle() {
-				g.pywrap.Printf("_%s_append(self.handle, value.handle)\n", qNm)
-			} else {
-				g.pywrap.Printf("_%s_append(self.handle, value)\n", qNm)
-			}
+			g.genContainerConv("value", esym, slc.gopkg)
+			g.pywrap.Printf("_%s_append(self.handle, %s)\n", qNm, pyElemArg("value", esym))
 			g.pywrap.Outdent()
 			g.pywrap.Printf("def copy(self, src):\n")
 			g.pywrap.Indent()
@@ -278,6 +295,26 @@ otherwise parameter is a python list that we copy from
 			g.pywrap.Outdent()
 		}
 
+		g.pywrap.Printf("def to_list(self):\n")
+		g.pywrap.Indent()
+		g.pywrap.Printf(`"""to_list returns a python list copy of the elements, the nested Go slices and maps converted to lists and dicts"""
+`)
+		g.pywrap.Printf("return [%sto_py(v) for v in self]\n", gocl)
+		g.pywrap.Outdent()
+
+		g.pywrap.Printf("@classmethod\n")
+		g.pywrap.Printf("def from_list(cls, values):\n")
+		g.pywrap.Indent()
+		kind := "slice"
+		if slc.isArray() {
+			kind = "array"
+		}
+		g.pywrap.Printf(`"""from_list returns a new Go %s copied from the python sequence, the nested lists and dicts converted to Go slices and maps.
+The Go slices and maps of values are copied too, not shared"""
+`, kind)
+		g.pywrap.Printf("return cls(%sto_py(values))\n", gocl)
+		g.pywrap.Outdent()
+
 		if slNm == "Slice_byte" {
 			g.pywrap.Printf("@staticmethod\n")
 			g.pywrap.Printf("def from_bytes(value):\n")
@@ -299,6 +336,12 @@ otherwise parameter is a python list that we copy from
 	}
 
 	if !extTypes || !pyWrapOnly {
+		// arrays are accessed through their pointer, not to change a copy
+		deref := "deptrFromHandle_"
+		if slc.isArray() {
+			deref = "ptrFromHandle_"
+		}
+
 		// go ctor
 		ctNm := slNm + "_CTor"
 		g.gofile.Printf("\n// --- wrapping slice: %v ---\n", slc.goname)
@@ -310,11 +353,14 @@ otherwise parameter is a python list that we copy from
 		g.gofile.Printf("}\n\n")
 
 		g.pybuild.Printf("mod.add_function('%s', retval('%s'), [])\n", ctNm, PyHandle)
+		if slc.isSlice() && perfNumber(esym) {
+			g.perfSlices = append(g.perfSlices, slNm)
+		}
 
 		g.gofile.Printf("//export %s_len\n", slNm)
 		g.gofile.Printf("func %s_len(handle CGoHandle) int {\n", slNm)
 		g.gofile.Indent()
-		g.gofile.Printf("return len(deptrFromHandle_%s(handle))\n", slNm)
+		g.gofile.Printf("return len(%s%s(handle))\n", deref, slNm)
 		g.gofile.Outdent()
 		g.gofile.Printf("}\n\n")
 
@@ -323,11 +369,13 @@ otherwise parameter is a python list that we copy from
 		g.gofile.Printf("//export %s_elem\n", slNm)
 		g.gofile.Printf("func %s_elem(handle CGoHandle, _idx int) %s {\n", slNm, esym.cgoname)
 		g.gofile.Indent()
-		g.gofile.Printf("s := deptrFromHandle_%s(handle)\n", slNm)
+		g.gofile.Printf("s := %s%s(handle)\n", deref, slNm)
 		if esym.go2py != "" {
 			// If the go2py starts with handleFromPtr_, use reference &, otherwise just return the value
+			// pointer elements are returned as such, and other handle
+			// elements by reference, sharing the element of the slice
 			val_str := ""
-			if strings.HasPrefix(esym.go2py, "handleFromPtr_") {
+			if strings.HasPrefix(esym.go2py, "handleFromPtr_") && !esym.isPointer() {
 				val_str = "&(s[_idx])"
 			} else {
 				val_str = "s[_idx]"
@@ -363,7 +411,7 @@ otherwise parameter is a python list that we copy from
 		g.gofile.Printf("//export %s_set\n", slNm)
 		g.gofile.Printf("func %s_set(handle CGoHandle, _idx int, _vl %s) {\n", slNm, esym.cgoname)
 		g.gofile.Indent()
-		g.gofile.Printf("s := deptrFromHandle_%s(handle)\n", slNm)
+		g.gofile.Printf("s := %s%s(handle)\n", deref, slNm)
 		if esym.py2go != "" {
 			g.gofile.Printf("s[_idx] = %s(_vl)%s\n", esym.py2go, esym.py2goParenEx)
 		} else {
@@ -424,7 +472,48 @@ otherwise parameter is a python list that we copy from
 }
 
 func (g *pyGen) genSliceMethods(s *Slice) {
+	var meths []*Func
 	for _, m := range s.meths {
-		g.genMethod(s.sym, m)
+		if g.genMethod(s.sym, m) {
+			meths = append(meths, m)
+		}
+	}
+	g.genOperators(s.sym, meths)
+	g.genContextManager(meths)
+	g.genIOMethods(s.sym, meths)
+}
+
+// isContainer returns true if the symbol is a slice, an array or a map,
+// whose class python sequences or mappings convert to
+func isContainer(sym *symbol) bool {
+	return sym != nil && (sym.isSlice() || sym.isArray() || sym.isMap()) && !sym.isPointer() && !sym.isConverted()
+}
+
+// genContainerConv generates the conversion of the python value of the
+// variable to the container class of sym, if it is not a Go value, keeping
+// the converted value for the duration of its use by handle
+func (g *pyGen) genContainerConv(vnm string, sym *symbol, pkg *types.Package) {
+	if !isContainer(sym) {
+		return
+	}
+	gocl := "go."
+	if g.pkg == goPackage {
+		gocl = ""
+	}
+	g.pywrap.Printf("if not isinstance(%s, %sGoClass):\n", vnm, gocl)
+	g.pywrap.Indent()
+	g.pywrap.Printf("%s = %s(%s)\n", vnm, sym.pyPkgId(pkg, g.pkg), vnm)
+	g.pywrap.Outdent()
+}
+
+// pyElemArg returns the python argument of the element value of the variable
+// of a container, passed by handle if it has one, nil pointers being None
+func pyElemArg(vnm string, esym *symbol) string {
+	switch {
+	case esym.isPointer():
+		return vnm + ".handle if " + vnm + " is not None else " + curHandle.pyZero
+	case esym.hasHandle():
+		return vnm + ".handle"
 	}
+	return vnm
 }
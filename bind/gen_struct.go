@@ -7,16 +7,22 @@ package bind
 import (
 	"fmt"
 	"go/types"
+	"strings"
 )
 
 func (g *pyGen) genStruct(s *Struct) {
+	if isDataclass(s.sym) {
+		g.genDataclass(s)
+		return
+	}
 	strNm := s.obj.Name()
 
 	base := "go.GoClass"
 	emb := s.FirstEmbed()
 	if emb != nil {
-		base = emb.pyPkgId(s.sym.gopkg)
+		base = emb.pyPkgId(s.sym.gopkg, g.pkg)
 	}
+	base = g.mixinBases(strNm, base)
 
 	g.pywrap.Printf(`
 # Python type for struct %[3]s
@@ -31,6 +37,12 @@ class %[1]s(%[4]s):
 	g.pywrap.Indent()
 	g.genStructInit(s)
 	g.genStructMembers(s)
+	g.genStructBulk(s)
+	g.genStructLocked(s)
+	g.genStructPool(s)
+	g.genStructPickle(s)
+	g.genStructCopy(s)
+	g.genStructEq(s)
 	g.genStructMethods(s)
 	g.pywrap.Outdent()
 }
@@ -41,6 +53,7 @@ func (g *pyGen) genStructInit(s *Struct) {
 	// strNm := s.obj.Name()
 
 	numFields := s.Struct().NumFields()
+	frozen := s.pkg.isFrozen(s.obj.Name())
 
 	g.pywrap.Printf("def __init__(self, *args, **kwargs):\n")
 	g.pywrap.Indent()
@@ -53,17 +66,17 @@ in which case a new Go object is constructed first
 	g.pywrap.Printf("if len(kwargs) == 1 and 'handle' in kwargs:\n")
 	g.pywrap.Indent()
 	g.pywrap.Printf("self.handle = kwargs['handle']\n")
-	g.pywrap.Printf("_%s.IncRef(self.handle)\n", g.pypkgname)
+	g.pywrap.Printf("_%s.IncRef(self.handle)\n", g.cfg.Name)
 	g.pywrap.Outdent()
 	g.pywrap.Printf("elif len(args) == 1 and isinstance(args[0], go.GoClass):\n")
 	g.pywrap.Indent()
 	g.pywrap.Printf("self.handle = args[0].handle\n")
-	g.pywrap.Printf("_%s.IncRef(self.handle)\n", g.pypkgname)
+	g.pywrap.Printf("_%s.IncRef(self.handle)\n", g.cfg.Name)
 	g.pywrap.Outdent()
 	g.pywrap.Printf("else:\n")
 	g.pywrap.Indent()
 	g.pywrap.Printf("self.handle = _%s.%s_CTor()\n", pkgname, s.ID())
-	g.pywrap.Printf("_%s.IncRef(self.handle)\n", g.pypkgname)
+	g.pywrap.Printf("_%s.IncRef(self.handle)\n", g.cfg.Name)
 
 	for i := 0; i < numFields; i++ {
 		f := s.Struct().Field(i)
@@ -77,13 +90,18 @@ in which case a new Go object is constructed first
 		// that a struct field that is a gopy managed object is only
 		// assigned gopy managed objects. Fields of basic types (e.g int, string)
 		// etc can be assigned to directly.
+		gname := g.pyFieldName(s, i)
+		set := "self." + gname + " = %s\n"
+		if frozen {
+			set = "self." + frozenSetter(gname) + "(%s)\n"
+		}
 		g.pywrap.Printf("if  %[1]d < len(args):\n", i)
 		g.pywrap.Indent()
-		g.pywrap.Printf("self.%s = args[%d]\n", f.Name(), i)
+		g.pywrap.Printf(set, fmt.Sprintf("args[%d]", i))
 		g.pywrap.Outdent()
-		g.pywrap.Printf("if %[1]q in kwargs:\n", f.Name())
+		g.pywrap.Printf("if %[1]q in kwargs:\n", gname)
 		g.pywrap.Indent()
-		g.pywrap.Printf("self.%[1]s = kwargs[%[1]q]\n", f.Name())
+		g.pywrap.Printf(set, fmt.Sprintf("kwargs[%q]", gname))
 		g.pywrap.Outdent()
 	}
 	g.pywrap.Outdent()
@@ -91,7 +109,7 @@ in which case a new Go object is constructed first
 
 	g.pywrap.Printf("def __del__(self):\n")
 	g.pywrap.Indent()
-	g.pywrap.Printf("_%s.DecRef(self.handle)\n", g.pypkgname)
+	g.pywrap.Printf("_%s.DecRef(self.handle)\n", g.cfg.Name)
 	g.pywrap.Outdent()
 
 	if s.prots&ProtoStringer != 0 {
@@ -106,6 +124,7 @@ in which case a new Go object is constructed first
 			g.pywrap.Printf("\n")
 		}
 	} else {
+		g.genCycleGuard()
 		g.pywrap.Printf("def __str__(self):\n")
 		g.pywrap.Indent()
 		g.pywrap.Printf("pr = [(p, getattr(self, p)) for p in dir(self) if not p.startswith('__')]\n")
@@ -132,6 +151,7 @@ in which case a new Go object is constructed first
 		g.pywrap.Outdent()
 	}
 
+	g.genCycleGuard()
 	g.pywrap.Printf("def __repr__(self):\n")
 	g.pywrap.Indent()
 	g.pywrap.Printf("pr = [(p, getattr(self, p)) for p in dir(self) if not p.startswith('__')]\n")
@@ -148,11 +168,12 @@ in which case a new Go object is constructed first
 
 	// go ctor
 	ctNm := s.ID() + "_CTor"
+	g.perfStructs = append(g.perfStructs, s.ID())
 	g.gofile.Printf("\n// --- wrapping struct: %v ---\n", qNm)
 	g.gofile.Printf("//export %s\n", ctNm)
 	g.gofile.Printf("func %s() CGoHandle {\n", ctNm)
 	g.gofile.Indent()
-	g.gofile.Printf("return CGoHandle(handleFromPtr_%s(&%s{}))\n", s.ID(), qNm)
+	g.gofile.Printf("return CGoHandle(handleFromPtr_%s(%s))\n", s.ID(), structNew(s))
 	g.gofile.Outdent()
 	g.gofile.Printf("}\n")
 
@@ -164,17 +185,34 @@ func (g *pyGen) genStructMembers(s *Struct) {
 	typ := s.Struct()
 	for i := 0; i < typ.NumFields(); i++ {
 		f := typ.Field(i)
-		ftyp, err := isPyCompatField(f)
-		if err != nil {
+		if _, err := isPyCompatField(f); err != nil {
+			g.lossyField(s, f)
 			continue
 		}
 		g.genStructMemberGetter(s, i, f)
-		if !ftyp.isArray() {
-			g.genStructMemberSetter(s, i, f)
-		}
+		g.genStructMemberSetter(s, i, f)
 	}
 }
 
+// pyFieldName returns the python name of the property of field i of the
+// struct: the Go name, in snake_case with RenameCase, unless set by the
+// gopy field tag, or by a name directive
+func (g *pyGen) pyFieldName(s *Struct, i int) string {
+	typ := s.Struct()
+	f := typ.Field(i)
+	if nm := s.pkg.pyNameDirective(s.Obj().Name() + "." + f.Name()); nm != "" {
+		return nm
+	}
+	gname := f.Name()
+	if g.cfg.RenameCase {
+		gname = toSnakeCase(gname)
+	}
+	if nm, err := extractPythonNameFieldTag(gname, typ.Tag(i)); err == nil {
+		gname = nm
+	}
+	return gname
+}
+
 func (g *pyGen) genStructMemberGetter(s *Struct, i int, f types.Object) {
 	pkgname := g.cfg.Name
 	ft := f.Type()
@@ -183,18 +221,12 @@ func (g *pyGen) genStructMemberGetter(s *Struct, i int, f types.Object) {
 		return
 	}
 
-	gname := f.Name()
-	if g.cfg.RenameCase {
-		gname = toSnakeCase(gname)
-	}
-
-	if newName, err := extractPythonNameFieldTag(gname, s.Struct().Tag(i)); err == nil {
-		gname = newName
-	}
+	gname := g.pyFieldName(s, i)
 
 	cgoFn := fmt.Sprintf("%s_%s_Get", s.ID(), f.Name())
 
 	g.pywrap.Printf("@property\n")
+	g.genThreadGuard(s.sym, f.Name())
 	g.pywrap.Printf("def %[1]s(self):\n", gname)
 	g.pywrap.Indent()
 	if gdoc := g.pkg.getDoc(s.Obj().Name(), f); gdoc != "" {
@@ -202,10 +234,18 @@ func (g *pyGen) genStructMemberGetter(s *Struct, i int, f types.Object) {
 		g.pywrap.Printf(gdoc)
 		g.pywrap.Println(`"""`)
 	}
-	if ret.hasHandle() {
-		cvnm := ret.pyPkgId(g.pkg.pkg)
+	switch {
+	case isStructPtr(ret) || ret.isInterface() && ret.hasHandle():
+		// nil pointers, e.g., ending linked lists, and interfaces are None
+		cvnm := ret.pyPkgId(g.pkg.pkg, g.pkg)
+		g.pywrap.Printf("h = _%s.%s(self.handle)\n", pkgname, cgoFn)
+		g.pywrap.Printf("return None if %s else %s(handle=h)\n", pyNilHandle("h"), cvnm)
+	case ret.hasHandle():
+		cvnm := ret.pyPkgId(g.pkg.pkg, g.pkg)
 		g.pywrap.Printf("return %s(handle=_%s.%s(self.handle))\n", cvnm, pkgname, cgoFn)
-	} else {
+	case isDuration(ret):
+		g.pywrap.Printf("return go.Duration(_%s.%s(self.handle))\n", pkgname, cgoFn)
+	default:
 		g.pywrap.Printf("return _%s.%s(self.handle)\n", pkgname, cgoFn)
 	}
 	g.pywrap.Outdent()
@@ -227,7 +267,7 @@ func (g *pyGen) genStructMemberGetter(s *Struct, i int, f types.Object) {
 	g.gofile.Outdent()
 	g.gofile.Printf("}\n\n")
 
-	g.pybuild.Printf("mod.add_function('%s', retval('%s'), [param('%s', 'handle')])\n", cgoFn, ret.cpyname, PyHandle)
+	g.pybuild.Printf("mod.add_function('%s', retval('%s'%s), [param('%s', 'handle')])\n", cgoFn, ret.cpyname, ret.pyRetOwn(), PyHandle)
 }
 
 func (g *pyGen) genStructMemberSetter(s *Struct, i int, f types.Object) {
@@ -238,19 +278,18 @@ func (g *pyGen) genStructMemberSetter(s *Struct, i int, f types.Object) {
 		return
 	}
 
-	gname := f.Name()
-	if g.cfg.RenameCase {
-		gname = toSnakeCase(gname)
-	}
-
-	if newName, err := extractPythonNameFieldTag(gname, s.Struct().Tag(i)); err == nil {
-		gname = newName
-	}
+	gname := g.pyFieldName(s, i)
 
 	cgoFn := fmt.Sprintf("%s_%s_Set", s.ID(), f.Name())
 
-	g.pywrap.Printf("@%s.setter\n", gname)
-	g.pywrap.Printf("def %[1]s(self, value):\n", gname)
+	if s.pkg.isFrozen(s.obj.Name()) {
+		// only set by __init__
+		g.pywrap.Printf("def %s(self, value):\n", frozenSetter(gname))
+	} else {
+		g.pywrap.Printf("@%s.setter\n", gname)
+		g.genThreadGuard(s.sym, f.Name())
+		g.pywrap.Printf("def %[1]s(self, value):\n", gname)
+	}
 	g.pywrap.Indent()
 	g.pywrap.Printf("if isinstance(value, go.GoClass):\n")
 	g.pywrap.Indent()
@@ -264,9 +303,29 @@ func (g *pyGen) genStructMemberSetter(s *Struct, i int, f types.Object) {
 	if _, isNamed := utyp.(*types.Named); isNamed {
 		utyp = utyp.Underlying()
 	}
-	switch utyp.(type) {
-	case *types.Basic:
+	_, isBasic := utyp.(*types.Basic)
+	switch {
+	case isBasic || ret.isConverted():
+		g.genLossyCheck("value", ret, s.Obj().Name()+"."+f.Name())
 		g.pywrap.Printf("_%s.%s(self.handle, value)\n", pkgname, cgoFn)
+	case isContainer(ret):
+		g.genContainerConv("value", ret, g.pkg.pkg)
+		g.pywrap.Printf("_%s.%s(self.handle, value.handle)\n", pkgname, cgoFn)
+	case isStructPtr(ret) || ret.isInterface():
+		g.pywrap.Printf("if value is not None:\n")
+		g.pywrap.Indent()
+		g.pywrap.Printf("raise TypeError(\"supplied argument type {t} is not a go.GoClass\".format(t=type(value)))\n")
+		g.pywrap.Outdent()
+		g.pywrap.Printf("_%s.%s(self.handle, %s)\n", pkgname, cgoFn, curHandle.pyZero)
+	case ret.isStruct():
+		// struct values are copied, from a new struct of the fields of a dict,
+		// bound to value so that it is not freed before the copy
+		g.pywrap.Printf("if not isinstance(value, dict):\n")
+		g.pywrap.Indent()
+		g.pywrap.Printf("raise TypeError(\"supplied argument type {t} is not a go.GoClass or dict\".format(t=type(value)))\n")
+		g.pywrap.Outdent()
+		g.pywrap.Printf("value = %s(**value)\n", ret.pyPkgId(g.pkg.pkg, g.pkg))
+		g.pywrap.Printf("_%s.%s(self.handle, value.handle)\n", pkgname, cgoFn)
 	default:
 		g.pywrap.Printf("raise TypeError(\"supplied argument type {t} is not a go.GoClass\".format(t=type(value)))\n")
 	}
@@ -286,13 +345,19 @@ func (g *pyGen) genStructMemberSetter(s *Struct, i int, f types.Object) {
 	g.gofile.Outdent()
 	g.gofile.Printf("}\n\n")
 
-	g.pybuild.Printf("mod.add_function('%s', None, [param('%s', 'handle'), param('%s', 'val')])\n", cgoFn, PyHandle, ret.cpyname)
+	g.pybuild.Printf("mod.add_function('%s', None, [param('%s', 'handle'), param('%s', 'val'%s)])\n", cgoFn, PyHandle, ret.cpyname, ret.pyParamOwn())
 }
 
 func (g *pyGen) genStructMethods(s *Struct) {
+	var meths []*Func
 	for _, m := range s.meths {
-		g.genMethod(s.sym, m)
+		if g.genMethod(s.sym, m) {
+			meths = append(meths, m)
+		}
 	}
+	g.genOperators(s.sym, meths)
+	g.genContextManager(meths)
+	g.genIOMethods(s.sym, meths)
 }
 
 //////////////////////////////////////////////////////////////////////////
@@ -300,19 +365,32 @@ func (g *pyGen) genStructMethods(s *Struct) {
 
 func (g *pyGen) genInterface(ifc *Interface) {
 	strNm := ifc.obj.Name()
+
+	base := "go.GoClass"
+	if embs := ifc.Embeds(); len(embs) > 0 {
+		bases := make([]string, len(embs))
+		for i, emb := range embs {
+			bases[i] = emb.pyPkgId(ifc.sym.gopkg, g.pkg)
+		}
+		base = strings.Join(bases, ", ")
+	}
+	base = g.mixinBases(strNm, base)
+
 	g.pywrap.Printf(`
 # Python type for interface %[3]s
-class %[1]s(go.GoClass):
+class %[1]s(%[4]s):
 	""%[2]q""
 `,
 		strNm,
 		ifc.Doc(),
 		ifc.GoName(),
+		base,
 	)
 	g.pywrap.Indent()
 	g.genIfaceInit(ifc)
-	g.genIfaceMethods(ifc)
+	meths := g.genIfaceMethods(ifc)
 	g.pywrap.Outdent()
+	g.genIfaceAbc(ifc, meths)
 }
 
 func (g *pyGen) genIfaceInit(ifc *Interface) {
@@ -326,17 +404,25 @@ handle=A Go-side object is always initialized with an explicit handle=arg
 	g.pywrap.Printf("if len(kwargs) == 1 and 'handle' in kwargs:\n")
 	g.pywrap.Indent()
 	g.pywrap.Printf("self.handle = kwargs['handle']\n")
-	g.pywrap.Printf("_%s.IncRef(self.handle)\n", g.pypkgname)
+	g.pywrap.Printf("_%s.IncRef(self.handle)\n", g.cfg.Name)
 	g.pywrap.Outdent()
 	g.pywrap.Printf("elif len(args) == 1 and isinstance(args[0], go.GoClass):\n")
 	g.pywrap.Indent()
 	g.pywrap.Printf("self.handle = args[0].handle\n")
-	g.pywrap.Printf("_%s.IncRef(self.handle)\n", g.pypkgname)
+	g.pywrap.Printf("_%s.IncRef(self.handle)\n", g.cfg.Name)
 	g.pywrap.Outdent()
 	g.pywrap.Printf("else:\n")
 	g.pywrap.Indent()
-	g.pywrap.Printf("self.handle = 0\n")
+	g.pywrap.Printf("self.handle = %s\n", curHandle.pyZero)
+	g.pywrap.Outdent()
 	g.pywrap.Outdent()
+
+	g.pywrap.Printf("@classmethod\n")
+	g.pywrap.Printf("def zero(cls):\n")
+	g.pywrap.Indent()
+	g.pywrap.Printf(`"""zero returns the Go zero value of the interface, nil being None"""
+`)
+	g.pywrap.Printf("return None\n")
 	g.pywrap.Outdent()
 
 	for _, m := range ifc.meths {
@@ -351,8 +437,23 @@ handle=A Go-side object is always initialized with an explicit handle=arg
 	}
 }
 
-func (g *pyGen) genIfaceMethods(ifc *Interface) {
+// genIfaceMethods generates the methods of the interface, returning the
+// bound ones
+func (g *pyGen) genIfaceMethods(ifc *Interface) []*Func {
+	var meths []*Func
 	for _, m := range ifc.meths {
-		g.genMethod(ifc.sym, m)
+		if g.genMethod(ifc.sym, m) {
+			meths = append(meths, m)
+		}
 	}
+	g.genOperators(ifc.sym, meths)
+	g.genContextManager(meths)
+	g.genIOMethods(ifc.sym, meths)
+	return meths
+}
+
+// isStructPtr returns true if the symbol is a pointer to a struct, which
+// is None in python when nil
+func isStructPtr(sym *symbol) bool {
+	return sym != nil && sym.isPointer() && sym.isStruct()
 }
@@ -0,0 +1,119 @@
+// Copyright 2024 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package bind
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// With the Templates option, the preambles of the generated files and the
+// Makefile are read from the files of a directory overriding the builtin
+// templates, e.g., to add license headers, build flags or telemetry,
+// without forking gopy.  The templates missing from the directory are the
+// builtin ones, and a directory that does not exist is created with all of
+// them, to start from.  They are fmt format strings, of the positional
+// args documented in the table below, with a literal % written %%.
+
+// templateFile is a template of the Templates option
+type templateFile struct {
+	file string // name of the file of the template in the directory
+	def  string // builtin template
+	args string // positional args of the template
+}
+
+// templateFiles are the templates of the Templates option
+var templateFiles = []templateFile{
+	{"go.tmpl", goPreamble, "1 = name of package, 2 = command, 3 = cgo flags, 4 = GoHandle, 5 = CGoHandle, " +
+		"6 = imports, 7 = main code, 8 = C preamble, 9 = Go preamble, 10 = handle conversions"},
+	{"build.py.tmpl", PyBuildPreamble, "1 = name of package, 2 = command, 3 = python handle type"},
+	{"py.tmpl", PyWrapPreamble, "1 = name of package, 2 = command, 3 = name of Go package, 4 = path of Go package, " +
+		"5 = doc, 6 = imports of the modules, 7 = imports of the packages"},
+	{"py_exe.tmpl", PyWrapExePreamble, "as py.tmpl, in exe mode"},
+	{"Makefile.tmpl", MakefileTemplate, "1 = name of package, 2 = command, 3 = gen command, 4 = python, 5 = library extension, " +
+		"6 = extra gcc args, 7 = CFLAGS, 8 = LDFLAGS, 9 = windows hack, 10 = extra Go files, 11 = cross build, 12 = Go runtime libs"},
+	{"Makefile_exe.tmpl", MakefileExeTemplate, "1 = name of package, 2 = command, 3 = gen command, 4 = python, " +
+		"5 = library extension, 6 = CFLAGS, 7 = LDFLAGS, 8 = cross build"},
+}
+
+// readTemplates returns the templates of the directory, by file name,
+// writing the builtin ones to it if it does not exist
+func readTemplates(dir string) (map[string]string, error) {
+	if dir == "" {
+		return nil, nil
+	}
+	if _, err := os.Stat(dir); os.IsNotExist(err) {
+		if err := writeTemplates(dir); err != nil {
+			return nil, err
+		}
+		if !NoWarn {
+			fmt.Printf("gopy: wrote the builtin templates to %s\n", dir)
+		}
+	}
+	tmpls := make(map[string]string)
+	for _, tf := range templateFiles {
+		data, err := os.ReadFile(filepath.Join(dir, tf.file))
+		switch {
+		case os.IsNotExist(err):
+			continue
+		case err != nil:
+			return nil, fmt.Errorf("gopy: could not read template: %v", err)
+		}
+		tmpl := string(data)
+		if strings.HasPrefix(tmpl, templateHeader(tf)) {
+			tmpl = tmpl[len(templateHeader(tf)):]
+		}
+		tmpls[tf.file] = tmpl
+	}
+	return tmpls, nil
+}
+
+// templateHeader returns the header line of the template file written by
+// writeTemplates, documenting its positional args, which is not part of it
+func templateHeader(tf templateFile) string {
+	cmt := "# "
+	if tf.file == "go.tmpl" {
+		cmt = "// "
+	}
+	return cmt + "gopy template, args: " + tf.args + "\n"
+}
+
+// writeTemplates writes the builtin templates to the new directory, each
+// preceded by its header
+func writeTemplates(dir string) error {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("gopy: could not create templates directory: %v", err)
+	}
+	for _, tf := range templateFiles {
+		if err := os.WriteFile(filepath.Join(dir, tf.file), []byte(templateHeader(tf)+tf.def), 0644); err != nil {
+			return fmt.Errorf("gopy: could not write template: %v", err)
+		}
+	}
+	return nil
+}
+
+// printTemplate prints the template of the file, overridden by that of the
+// Templates option, if any, with the args, to the printer
+func (g *pyGen) printTemplate(pr *printer, file, def string, args ...any) {
+	tmpl, ok := g.templates[file]
+	if !ok {
+		pr.Printf(def, args...)
+		return
+	}
+	out := fmt.Sprintf(tmpl, args...)
+	if i := strings.Index(out, "%!"); i >= 0 {
+		end := len(out)
+		if end > i+40 {
+			end = i + 40
+		}
+		if j := strings.IndexByte(out[i:end], '\n'); j > 0 {
+			end = i + j
+		}
+		g.err.Add(fmt.Errorf("gopy: invalid template %s: bad verb or arg: %s", file, out[i:end]))
+	}
+	pr.Printf("%s", out)
+}
@@ -0,0 +1,59 @@
+// Copyright 2024 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package bind
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestReadTemplates(t *testing.T) {
+	defer func(nowarn bool) { NoWarn = nowarn }(NoWarn)
+	NoWarn = true
+	dir := filepath.Join(t.TempDir(), "templates")
+	tmpls, err := readTemplates(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, tf := range templateFiles {
+		if tmpls[tf.file] != tf.def {
+			t.Errorf("template %s written to a new dir differs from the builtin one", tf.file)
+		}
+	}
+
+	if err := os.Remove(filepath.Join(dir, "py.tmpl")); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "build.py.tmpl"), []byte("# (c) me\n# %[1]s %[3]s\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	tmpls, err = readTemplates(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := tmpls["py.tmpl"]; ok {
+		t.Errorf("removed template py.tmpl overrides the builtin one")
+	}
+
+	g := &pyGen{templates: tmpls}
+	pr := &printer{buf: new(bytes.Buffer), indentEach: []byte("\t")}
+	g.printTemplate(pr, "build.py.tmpl", PyBuildPreamble, "hi", "gopy gen hi", "int64_t")
+	if got, want := pr.buf.String(), "# (c) me\n# hi int64_t\n"; got != want {
+		t.Errorf("overridden template: got %q, want %q", got, want)
+	}
+	pr.buf.Reset()
+	g.printTemplate(pr, "py.tmpl", "builtin %[1]s\n", "hi")
+	if got, want := pr.buf.String(), "builtin hi\n"; got != want {
+		t.Errorf("builtin template: got %q, want %q", got, want)
+	}
+
+	g.templates["build.py.tmpl"] = "# %[4]s\n"
+	g.printTemplate(pr, "build.py.tmpl", PyBuildPreamble, "hi", "gopy gen hi", "int64_t")
+	if len(g.err) != 1 {
+		t.Errorf("no error for a template of a bad arg")
+	}
+}
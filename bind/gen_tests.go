@@ -0,0 +1,182 @@
+// Copyright 2024 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package bind
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+const (
+	// 1 = output dir to add to sys.path, relative to the tests dir
+	pyTestConftest = `# pytest configuration for the gopy generated smoke tests.
+# File is generated by gopy. Do not edit.
+# %[2]s
+
+import os, sys
+
+sys.path.insert(0, os.path.abspath(os.path.join(os.path.dirname(__file__), %[1]q)))
+`
+
+	// 1 = package name, 2 = cmd, 3 = import statement
+	pyTestPreamble = `# pytest smoke tests for package %[1]s
+# File is generated by gopy. Do not edit.
+# %[2]s
+
+%[3]s
+
+def test_import():
+	assert %[1]s is not None
+
+`
+)
+
+// genTests generates pytest smoke tests into the tests/ subdirectory of the
+// output, one file per package, that import the package, instantiate each
+// struct, call each zero-argument function, and round-trip each constant
+// and variable.
+func (g *pyGen) genTests() {
+	dir := filepath.Join(g.cfg.OutputDir, "tests")
+	err := os.MkdirAll(dir, 0755)
+	if err != nil {
+		g.err.Add(err)
+		return
+	}
+
+	// the bindings are either imported as a python package (the output
+	// directory, by default, or the full dotted output name) or as
+	// top-level modules
+	path, from := "..", ""
+	switch g.cfg.PkgPrefix {
+	case ".":
+		path, from = filepath.Join("..", ".."), filepath.Base(g.cfg.OutputDir)
+		if g.cfg.Parent != "" {
+			from = g.cfg.FullName()
+			for range strings.Split(g.cfg.Parent, ".") {
+				path = filepath.Join(path, "..")
+			}
+		}
+	case "":
+	default:
+		from = g.cfg.PkgPrefix
+	}
+
+	pr := &printer{buf: new(bytes.Buffer), indentEach: []byte("\t")}
+	pr.Printf(pyTestConftest, filepath.ToSlash(path), g.cfg.Cmd)
+	g.genPrintOut(filepath.Join("tests", "conftest.py"), pr)
+
+	for _, p := range Packages {
+		if p == goPackage {
+			continue
+		}
+		pr := &printer{buf: new(bytes.Buffer), indentEach: []byte("\t")}
+		imp := "import go, " + p.Name()
+		if from != "" {
+			imp = "from " + from + " " + imp
+		}
+		pr.Printf(pyTestPreamble, p.Name(), g.cfg.Cmd, imp)
+		g.genPkgTests(pr, p)
+		g.genPrintOut(filepath.Join("tests", "test_"+p.Name()+".py"), pr)
+	}
+}
+
+func (g *pyGen) genPkgTests(pr *printer, p *Package) {
+	pn := p.Name()
+
+	for _, s := range p.structs {
+		pr.Printf("def test_struct_%s():\n", s.obj.Name())
+		pr.Indent()
+		pr.Printf("v = %s.%s()\n", pn, s.obj.Name())
+		if isDataclass(s.sym) {
+			pr.Printf("assert go.dataclasses.is_dataclass(v)\n")
+		} else {
+			pr.Printf("assert isinstance(v, go.GoClass)\n")
+		}
+		pr.Outdent()
+		pr.Printf("\n")
+	}
+
+	for _, s := range p.structs {
+		for _, f := range s.ctors {
+			g.genFuncTest(pr, pn, f)
+		}
+	}
+	for _, f := range p.funcs {
+		g.genFuncTest(pr, pn, f)
+	}
+
+	for _, c := range p.consts {
+		if isPyCompatVar(c.sym) != nil || c.sym.isSignature() {
+			continue
+		}
+		g.genConstTest(pr, pn, c)
+	}
+	for _, e := range p.enums {
+		for _, c := range e.items {
+			g.genConstTest(pr, pn, c)
+		}
+	}
+
+	for _, v := range p.vars {
+		if isPyCompatVar(v.sym) != nil || v.sym.isSignature() {
+			continue
+		}
+		get := v.Name()
+		set := "Set_" + v.Name()
+		if g.cfg.RenameCase {
+			get, set = toSnakeCase(get), toSnakeCase(set)
+		}
+		pr.Printf("def test_var_%s():\n", v.Name())
+		pr.Indent()
+		pr.Printf("v = %s.%s()\n", pn, get)
+		if !v.sym.isArray() {
+			pr.Printf("%s.%s(v)\n", pn, set)
+			if !v.sym.hasHandle() {
+				pr.Printf("assert %s.%s() == v\n", pn, get)
+			}
+		}
+		pr.Outdent()
+		pr.Printf("\n")
+	}
+}
+
+// genFuncTest generates a test calling f if it takes no args
+func (g *pyGen) genFuncTest(pr *printer, pn string, f *Func) {
+	fn, ok := g.pyFuncName(f)
+	if !ok || len(f.sig.Params()) > 0 {
+		return
+	}
+	pr.Printf("def test_func_%s():\n", f.GoName())
+	pr.Indent()
+	if f.err || f.commaOk == commaOkRaise {
+		// returning an error, or a false ok, is a valid outcome of the call
+		exc := "go.GoError"
+		if !f.err {
+			exc = "KeyError"
+		}
+		pr.Printf("try:\n")
+		pr.Indent()
+		pr.Printf("%s.%s()\n", pn, fn)
+		pr.Outdent()
+		pr.Printf("except %s:\n", exc)
+		pr.Indent()
+		pr.Printf("pass\n")
+		pr.Outdent()
+	} else {
+		pr.Printf("%s.%s()\n", pn, fn)
+	}
+	pr.Outdent()
+	pr.Printf("\n")
+}
+
+func (g *pyGen) genConstTest(pr *printer, pn string, c *Const) {
+	pr.Printf("def test_const_%s():\n", c.GoName())
+	pr.Indent()
+	pr.Printf("assert %s.%s == %s\n", pn, c.GoName(), pyConstValue(c))
+	pr.Outdent()
+	pr.Printf("\n")
+}
@@ -0,0 +1,67 @@
+// Copyright 2024 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package bind
+
+// With the ThreadChecks option, for debugging, the methods and fields of
+// the structs not safe for concurrent use raise a go.ThreadError when they
+// are called from another python thread than the owner of the object, the
+// first thread to call one of them, turning the races of the Go values
+// shared between python threads into immediate errors.  The structs whose
+// doc comment has a //gopy:threadsafe line, e.g., as they use a mutex, the
+// frozen structs, see gen_frozen.go, and the guarded structs, locked by
+// python, see gen_locks.go, are not checked.  go.release_thread hands an
+// object over to the next thread calling it, and an object whose owner has
+// exited is taken over too.  The owner is that of the python object, so
+// that the python objects of the same Go value, e.g., returned by
+// different calls, are checked apart.
+
+const (
+	// python thread checks, in go
+	threadsPyWrap = `
+# ---- thread checks of the Go values not safe for concurrent use ---
+class ThreadError(RuntimeError):
+	"""ThreadError is raised, with -thread-checks, when a Go object not safe for concurrent use is used from another thread than its owner"""
+	pass
+
+def thread_guard(name):
+	"""thread_guard returns a decorator of the method of the name raising ThreadError when it is called from another thread
+	than the owner of the object, the first thread to call one of its guarded methods"""
+	def decorator(fn):
+		def guard(self, *args, **kwargs):
+			cur = threading.current_thread()
+			owner = self.__dict__.setdefault('_go_thread', cur)
+			if owner is not cur:
+				if owner.is_alive():
+					raise ThreadError("%%s called from thread %%r, but the %%s is owned by thread %%r: it is not safe for concurrent use (see go.release_thread)" %% (name, cur.name, type(self).__name__, owner.name))
+				self.__dict__['_go_thread'] = cur
+			return fn(self, *args, **kwargs)
+		guard.__name__ = fn.__name__
+		guard.__doc__ = fn.__doc__
+		return guard
+	return decorator
+
+def release_thread(obj):
+	"""release_thread releases the ownership of the Go object by its thread, with -thread-checks, handing it over to the next thread using it"""
+	obj.__dict__.pop('_go_thread', None)
+
+`
+)
+
+// genThreadsPyWrap generates the thread check functions of go
+func (g *pyGen) genThreadsPyWrap() {
+	g.pywrap.Printf(threadsPyWrap)
+}
+
+// genThreadGuard generates the thread_guard decorator of a method or a field
+// accessor of the type, if it is checked
+func (g *pyGen) genThreadGuard(sym *symbol, member string) {
+	if !g.cfg.ThreadChecks || g.pkg == goPackage || sym == nil || !sym.isStruct() || sym.goobj == nil {
+		return
+	}
+	if _, ok := g.pkg.directive(sym.goobj.Name(), "threadsafe"); ok || g.pkg.isFrozen(sym.goobj.Name()) || g.pkg.isGuarded(sym.goobj.Name()) {
+		return
+	}
+	g.pywrap.Printf("@go.thread_guard(%q)\n", g.pkg.Name()+"."+sym.goobj.Name()+"."+member)
+}
@@ -14,11 +14,22 @@ func (g *pyGen) genType(sym *symbol, extTypes, pyWrapOnly bool) {
 		return
 	}
 
+	if isDataclass(sym) { // see genDataclass
+		return
+	}
+
 	if sym.isNamedBasic() {
 		// TODO: could have methods!
 		return
 	}
 
+	if sym.isIter() {
+		if !pyWrapOnly {
+			g.genIterGo(sym)
+		}
+		return
+	}
+
 	// TODO: not handling yet:
 	if sym.isSignature() {
 		return
@@ -28,13 +39,18 @@ func (g *pyGen) genType(sym *symbol, extTypes, pyWrapOnly bool) {
 		switch {
 		case sym.isPointer() || sym.isInterface():
 			g.genTypeHandlePtr(sym)
-		case sym.isSlice() || sym.isMap() || sym.isArray():
+		case sym.isSlice() || sym.isMap() || sym.isArray() || sym.isChan():
 			g.genTypeHandleImplPtr(sym)
 		default:
 			g.genTypeHandle(sym)
 		}
 	}
 
+	if sym.isChan() {
+		g.genChan(sym, extTypes, pyWrapOnly)
+		return
+	}
+
 	if extTypes {
 		if sym.isSlice() || sym.isArray() {
 			g.genSlice(sym, extTypes, pyWrapOnly, nil)
@@ -67,10 +83,14 @@ func (g *pyGen) genTypeHandlePtr(sym *symbol) {
 	g.gofile.Printf("\n// Converters for pointer handles for type: %s\n", gonm)
 	g.gofile.Printf("func %s(h CGoHandle) %s {\n", sym.py2go, gonm)
 	g.gofile.Indent()
-	g.gofile.Printf("p := gopyh.VarFromHandle((gopyh.CGoHandle)(h), %[1]q)\n", gonm)
+	g.gofile.Printf("p := gopyh.VarFromHandle(handleGo(h), %[1]q)\n", gonm)
 	g.gofile.Printf("if p == nil {\n")
 	g.gofile.Indent()
-	g.gofile.Printf("return nil\n")
+	if isContextType(sym.gotyp) {
+		g.gofile.Printf("return context.Background()\n") // nil / omitted context
+	} else {
+		g.gofile.Printf("return nil\n")
+	}
 	g.gofile.Outdent()
 	g.gofile.Printf("}\n")
 	if sym.isStruct() {
@@ -82,7 +102,7 @@ func (g *pyGen) genTypeHandlePtr(sym *symbol) {
 	g.gofile.Printf("}\n")
 	g.gofile.Printf("func %s(p interface{})%s CGoHandle {\n", sym.go2py, sym.go2pyParenEx)
 	g.gofile.Indent()
-	g.gofile.Printf("return CGoHandle(gopyh.Register(\"%s\", p))\n", gonm)
+	g.gofile.Printf("return handleC(gopyh.Register(\"%s\", p))\n", gonm)
 	g.gofile.Outdent()
 	g.gofile.Printf("}\n")
 }
@@ -100,12 +120,21 @@ func (g *pyGen) genTypeHandleImplPtr(sym *symbol) {
 	g.gofile.Printf("\n// Converters for implicit pointer handles for type: %s\n", gonm)
 	g.gofile.Printf("func ptrFromHandle_%s(h CGoHandle) %s {\n", sym.id, ptrnm)
 	g.gofile.Indent()
-	g.gofile.Printf("p := gopyh.VarFromHandle((gopyh.CGoHandle)(h), %[1]q)\n", gonm)
+	g.gofile.Printf("p := gopyh.VarFromHandle(handleGo(h), %[1]q)\n", gonm)
 	g.gofile.Printf("if p == nil {\n")
 	g.gofile.Indent()
 	g.gofile.Printf("return nil\n")
 	g.gofile.Outdent()
 	g.gofile.Printf("}\n")
+	if bidir := bidirChanName(sym); bidir != "" {
+		// bidirectional channels convert to directional ones
+		g.gofile.Printf("if c, ok := p.(*%s); ok {\n", bidir)
+		g.gofile.Indent()
+		g.gofile.Printf("d := (%s)(*c)\n", nptrnm)
+		g.gofile.Printf("return &d\n")
+		g.gofile.Outdent()
+		g.gofile.Printf("}\n")
+	}
 	g.gofile.Printf("return p.(%s)\n", ptrnm)
 	g.gofile.Outdent()
 	g.gofile.Printf("}\n")
@@ -124,7 +153,7 @@ func (g *pyGen) genTypeHandleImplPtr(sym *symbol) {
 	g.gofile.Printf("}\n")
 	g.gofile.Printf("func %s(p interface{})%s CGoHandle {\n", sym.go2py, sym.go2pyParenEx)
 	g.gofile.Indent()
-	g.gofile.Printf("return CGoHandle(gopyh.Register(\"%s\", p))\n", gonm)
+	g.gofile.Printf("return handleC(gopyh.Register(\"%s\", p))\n", gonm)
 	g.gofile.Outdent()
 	g.gofile.Printf("}\n")
 }
@@ -146,7 +175,7 @@ func (g *pyGen) genTypeHandle(sym *symbol) {
 	g.gofile.Printf("\n// Converters for non-pointer handles for type: %s\n", gonm)
 	g.gofile.Printf("func %s(h CGoHandle) %s {\n", py2go, ptrnm)
 	g.gofile.Indent()
-	g.gofile.Printf("p := gopyh.VarFromHandle((gopyh.CGoHandle)(h), %[1]q)\n", gonm)
+	g.gofile.Printf("p := gopyh.VarFromHandle(handleGo(h), %[1]q)\n", gonm)
 	g.gofile.Printf("if p == nil {\n")
 	g.gofile.Indent()
 	g.gofile.Printf("return nil\n")
@@ -161,7 +190,7 @@ func (g *pyGen) genTypeHandle(sym *symbol) {
 	g.gofile.Printf("}\n")
 	g.gofile.Printf("func %s(p interface{})%s CGoHandle {\n", sym.go2py, sym.go2pyParenEx)
 	g.gofile.Indent()
-	g.gofile.Printf("return CGoHandle(gopyh.Register(\"%s\", p))\n", gonm)
+	g.gofile.Printf("return handleC(gopyh.Register(\"%s\", p))\n", gonm)
 	g.gofile.Outdent()
 	g.gofile.Printf("}\n")
 }
@@ -190,28 +219,29 @@ handle=A Go-side object is always initialized with an explicit handle=arg
 	g.pywrap.Printf("if len(kwargs) == 1 and 'handle' in kwargs:\n")
 	g.pywrap.Indent()
 	g.pywrap.Printf("self.handle = kwargs['handle']\n")
-	g.pywrap.Printf("_%s.IncRef(self.handle)\n", g.pypkgname)
+	g.pywrap.Printf("_%s.IncRef(self.handle)\n", g.cfg.Name)
 	g.pywrap.Outdent()
 	g.pywrap.Printf("elif len(args) == 1 and isinstance(args[0], GoClass):\n")
 	g.pywrap.Indent()
 	g.pywrap.Printf("self.handle = args[0].handle\n")
-	g.pywrap.Printf("_%s.IncRef(self.handle)\n", g.pypkgname)
+	g.pywrap.Printf("_%s.IncRef(self.handle)\n", g.cfg.Name)
 	g.pywrap.Outdent()
-	g.pywrap.Printf("elif len(args) == 1 and isinstance(args[0], int):\n")
+	g.pywrap.Printf("elif len(args) == 1 and isinstance(args[0], %s):\n", curHandle.pyType)
 	g.pywrap.Indent()
 	g.pywrap.Printf("self.handle = args[0]\n")
-	g.pywrap.Printf("_%s.IncRef(self.handle)\n", g.pypkgname)
+	g.pywrap.Printf("_%s.IncRef(self.handle)\n", g.cfg.Name)
 	g.pywrap.Outdent()
 	g.pywrap.Printf("else:\n")
 	g.pywrap.Indent()
-	g.pywrap.Printf("self.handle = 0\n")
+	g.pywrap.Printf("self.handle = %s\n", curHandle.pyZero)
 	g.pywrap.Outdent()
 	g.pywrap.Outdent()
 
 	g.pywrap.Printf("def __del__(self):\n")
 	g.pywrap.Indent()
-	g.pywrap.Printf("_%s.DecRef(self.handle)\n", g.pypkgname)
+	g.pywrap.Printf("_%s.DecRef(self.handle)\n", g.cfg.Name)
 	g.pywrap.Outdent()
+	g.genIOMethods(sym, nil)
 
 	g.pywrap.Printf("\n")
 	g.pywrap.Outdent()
@@ -6,11 +6,16 @@ package bind
 
 import (
 	"fmt"
+	"go/constant"
+	"go/types"
+	"math"
+	"strconv"
 	"strings"
 )
 
 func (g *pyGen) genConst(c *Const) {
-	if isPyCompatVar(c.sym) != nil {
+	if err := isPyCompatVar(c.sym); err != nil {
+		statDiag(skipIncompatVar, g.pkg.Name()+"."+c.GoName(), c.obj, errDetail(err))
 		return
 	}
 	if c.sym.isSignature() {
@@ -20,7 +25,8 @@ func (g *pyGen) genConst(c *Const) {
 }
 
 func (g *pyGen) genVar(v *Var) {
-	if isPyCompatVar(v.sym) != nil {
+	if err := isPyCompatVar(v.sym); err != nil {
+		statDiag(skipIncompatVar, g.pkg.Name()+"."+v.Name(), g.pkg.pkg.Scope().Lookup(v.Name()), errDetail(err))
 		return
 	}
 	if v.sym.isSignature() {
@@ -47,8 +53,10 @@ func (g *pyGen) genVarGetter(v *Var) {
 	g.pywrap.Indent()
 	g.pywrap.Printf("%s\n%s Gets Go Variable: %s\n%s\n%s\n", `"""`, cgoFn, qVn, v.doc, `"""`)
 	if v.sym.hasHandle() {
-		cvnm := v.sym.pyPkgId(g.pkg.pkg)
+		cvnm := v.sym.pyPkgId(g.pkg.pkg, g.pkg)
 		g.pywrap.Printf("return %s(handle=%s())\n", cvnm, qFn)
+	} else if isDuration(v.sym) {
+		g.pywrap.Printf("return go.Duration(%s())\n", qFn)
 	} else {
 		g.pywrap.Printf("return %s()\n", qFn)
 	}
@@ -72,7 +80,7 @@ func (g *pyGen) genVarGetter(v *Var) {
 	g.gofile.Outdent()
 	g.gofile.Printf("}\n\n")
 
-	g.pybuild.Printf("mod.add_function('%s', retval('%s'), [])\n", qCgoFn, v.sym.cpyname)
+	g.pybuild.Printf("mod.add_function('%s', retval('%s'%s), [])\n", qCgoFn, v.sym.cpyname, v.sym.pyRetOwn())
 }
 
 func (g *pyGen) genVarSetter(v *Var) {
@@ -95,6 +103,7 @@ func (g *pyGen) genVarSetter(v *Var) {
 	g.pywrap.Outdent()
 	g.pywrap.Printf("else:\n")
 	g.pywrap.Indent()
+	g.genLossyCheck("value", v.sym, qVn)
 	g.pywrap.Printf("%s(value)\n", qFn)
 	g.pywrap.Outdent()
 	g.pywrap.Outdent()
@@ -112,19 +121,81 @@ func (g *pyGen) genVarSetter(v *Var) {
 	g.gofile.Outdent()
 	g.gofile.Printf("}\n\n")
 
-	g.pybuild.Printf("mod.add_function('%s', None, [param('%s', 'val')])\n", qCgoFn, v.sym.cpyname)
+	g.pybuild.Printf("mod.add_function('%s', None, [param('%s', 'val'%s)])\n", qCgoFn, v.sym.cpyname, v.sym.pyParamOwn())
+}
+
+// pyConstValue returns the python literal of the exact value of a const,
+// of the python type of its Go type: ints of any size, as python ints are
+// unbounded, floats with all their digits, and strings with all their
+// bytes, escaped, rather than the abbreviated form of constant.Value.String
+func pyConstValue(c *Const) string {
+	val := c.obj.Val()
+	isFloat, isComplex := false, false
+	if bt, ok := c.obj.Type().Underlying().(*types.Basic); ok {
+		isFloat = bt.Info()&types.IsFloat != 0
+		isComplex = bt.Info()&types.IsComplex != 0
+	}
+	switch val.Kind() {
+	case constant.Bool:
+		if constant.BoolVal(val) {
+			return "True"
+		}
+		return "False"
+	case constant.String:
+		return pyStringLiteral(constant.StringVal(val))
+	case constant.Int:
+		switch {
+		case isComplex:
+			return pyComplexLiteral(val)
+		case isFloat:
+			return pyFloatLiteral(val)
+		}
+		return val.ExactString()
+	case constant.Float:
+		if isComplex {
+			return pyComplexLiteral(val)
+		}
+		return pyFloatLiteral(val)
+	case constant.Complex:
+		return pyComplexLiteral(val)
+	}
+	return val.String()
+}
+
+// pyFloatLiteral returns the python float literal of the float64 value
+// nearest to the const value
+func pyFloatLiteral(val constant.Value) string {
+	f, _ := constant.Float64Val(constant.ToFloat(val))
+	switch {
+	case math.IsInf(f, 1):
+		return "float('inf')"
+	case math.IsInf(f, -1):
+		return "float('-inf')"
+	}
+	lit := strconv.FormatFloat(f, 'g', -1, 64)
+	if !strings.ContainsAny(lit, ".en") {
+		lit += ".0"
+	}
+	return lit
+}
+
+// pyComplexLiteral returns the python complex literal of the const value
+func pyComplexLiteral(val constant.Value) string {
+	val = constant.ToComplex(val)
+	return fmt.Sprintf("complex(%s, %s)", pyFloatLiteral(constant.Real(val)), pyFloatLiteral(constant.Imag(val)))
+}
+
+// pyStringLiteral returns the python str literal of the Go string, with the
+// escapes of strconv.Quote, which python understands too, once the bytes of
+// invalid UTF-8, which would be code points in python, are replaced with
+// U+FFFD
+func pyStringLiteral(s string) string {
+	return strconv.Quote(strings.ToValidUTF8(s, "\uFFFD"))
 }
 
 func (g *pyGen) genConstValue(c *Const) {
 	// constants go directly into wrapper as-is
-	val := c.val
-	switch val {
-	case "true":
-		val = "True"
-	case "false":
-		val = "False"
-	}
-	g.pywrap.Printf("%s = %s\n", c.GoName(), val)
+	g.pywrap.Printf("%s = %s\n", c.GoName(), pyConstValue(c))
 	if c.doc != "" {
 		lns := strings.Split(c.doc, "\n")
 		g.pywrap.Printf(`"""`)
@@ -138,7 +209,12 @@ func (g *pyGen) genConstValue(c *Const) {
 }
 
 func (g *pyGen) genEnum(e *Enum) {
-	g.pywrap.Printf("class %s(Enum):\n", e.typ.Obj().Name())
+	isFlags := e.IsFlags()
+	base := "Enum"
+	if isFlags {
+		base = "IntFlag"
+	}
+	g.pywrap.Printf("class %s(%s):\n", e.typ.Obj().Name(), base)
 	g.pywrap.Indent()
 	doc := e.Doc()
 	if doc != "" {
@@ -161,9 +237,27 @@ func (g *pyGen) genEnum(e *Enum) {
 	// so to keep the code consistent, we redundantly generate the consts
 	// again here.  The Enum organization however is critical for organizing
 	// the values under the type (making them accessible programmatically)
+	// the consts of the flags are the flags, so that they combine into flags
 	g.pywrap.Printf("\n")
 	for _, c := range e.items {
+		if isFlags {
+			g.pywrap.Printf("%[1]s = %[2]s.%[1]s\n", c.GoName(), e.typ.Obj().Name())
+			continue
+		}
 		g.genConstValue(c)
 	}
 	g.pywrap.Printf("\n")
 }
+
+// flagsEnum returns the enum of the type of the symbol, if it is bit flags
+// of the current package
+func (g *pyGen) flagsEnum(sym *symbol) *Enum {
+	ntyp, ok := sym.gotyp.(*types.Named)
+	if !ok || g.pkg == nil || g.pkg.pkg != ntyp.Obj().Pkg() {
+		return nil
+	}
+	if e := g.pkg.findEnum(ntyp); e != nil && e.IsFlags() {
+		return e
+	}
+	return nil
+}
@@ -0,0 +1,62 @@
+// Copyright 2024 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package bind
+
+// Bound types with a Close, Shutdown or Stop method, without args and
+// returning nothing or just an error, are python context managers calling
+// it on exit, so that the Go resources they hold, e.g., files or
+// connections, are released deterministically in a with block:
+//
+//	with pkg.Open("file") as f:
+//	    ...
+//
+// An error returned by the method is raised as a go.GoError on exit.
+
+// closeMethods are the names of the methods releasing resources, by priority
+var closeMethods = []string{"Close", "Shutdown", "Stop"}
+
+// isCloseMethod returns whether the method can be called on exit
+func isCloseMethod(f *Func) bool {
+	if f.sig == nil || len(f.sig.Params()) != 0 {
+		return false
+	}
+	nres := len(f.sig.Results())
+	return nres == 0 || (nres == 1 && f.err)
+}
+
+// genContextManager generates the __enter__ and __exit__ methods of the
+// python class of the type if it has a method releasing its resources,
+// among its methods that have been generated
+func (g *pyGen) genContextManager(meths []*Func) {
+	var cm *Func
+	for _, nm := range closeMethods {
+		for _, m := range meths {
+			if m.GoName() == nm && isCloseMethod(m) {
+				cm = m
+				break
+			}
+		}
+		if cm != nil {
+			break
+		}
+	}
+	if cm == nil {
+		return
+	}
+	fn, ok := g.pyFuncName(cm)
+	if !ok {
+		return
+	}
+	g.pywrap.Printf("def __enter__(self):\n")
+	g.pywrap.Indent()
+	g.pywrap.Printf("return self\n")
+	g.pywrap.Outdent()
+	g.pywrap.Printf("def __exit__(self, exc_type, exc_value, traceback):\n")
+	g.pywrap.Indent()
+	g.pywrap.Printf("\"\"\"__exit__ calls %s, not suppressing exceptions\"\"\"\n", cm.GoName())
+	g.pywrap.Printf("self.%s()\n", fn)
+	g.pywrap.Printf("return False\n")
+	g.pywrap.Outdent()
+}
@@ -0,0 +1,323 @@
+// Copyright 2024 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package bind
+
+import (
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"sort"
+	"strings"
+)
+
+// Generic types and functions cannot be bound as such, only their
+// instantiations with concrete type args: the instantiations of the generic
+// types of a package used in the exported API, e.g., by a function returning
+// a *Stack[int], are bound as python classes named after their type args,
+// e.g., Stack_int, with their methods and fields.  A
+//
+//	//gopy:instantiate Name[T1, T2]
+//
+// line in a doc comment of the package binds the instantiation of its
+// generic type or function Name with the type args, e.g., Sum[int] as the
+// Sum_int function.  The directive can be repeated for more instantiations.
+//
+// The exported generic functions whose type params are all constrained to
+// basic types, e.g., by cmp.Ordered, like min, max or clamp helpers, are
+// instantiated automatically with the int, float64 and string type args
+// satisfying the constraints, matching the python int, float and str,
+// unless they have instantiate directives.
+
+// autoTypeArgs are the type args of the automatic instantiations of the
+// generic functions constrained to basic types
+var autoTypeArgs = []types.Type{types.Typ[types.Int], types.Typ[types.Float64], types.Typ[types.String]}
+
+// instance is an instantiation of a generic type or function of the package,
+// bound under its python name
+type instance struct {
+	orig   types.Object // the generic type or function
+	obj    types.Object // the instantiation, named after its python name
+	goname string       // the Go expression of the instantiation, e.g., pkg.Sum[int]
+}
+
+// isGeneric returns true if the object is a generic type or function,
+// or a constraint interface, which can only be used by generics
+func isGeneric(obj types.Object) bool {
+	switch obj := obj.(type) {
+	case *types.TypeName:
+		if ifc, ok := obj.Type().Underlying().(*types.Interface); ok && !ifc.IsMethodSet() {
+			return true
+		}
+		nt, ok := obj.Type().(*types.Named)
+		return ok && nt.TypeParams().Len() > 0
+	case *types.Func:
+		sig, ok := obj.Type().(*types.Signature)
+		return ok && sig.TypeParams().Len() > 0
+	}
+	return false
+}
+
+// isInstance returns true if the type is an instantiation of a generic type
+func isInstance(typ types.Type) bool {
+	nt, ok := typ.(*types.Named)
+	return ok && nt.TypeArgs().Len() > 0
+}
+
+// hasInstance returns true if the type is an instantiation of a generic
+// type, or a pointer, slice, array or map of one
+func hasInstance(typ types.Type) bool {
+	switch t := typ.(type) {
+	case *types.Pointer:
+		return hasInstance(t.Elem())
+	case *types.Slice:
+		return hasInstance(t.Elem())
+	case *types.Array:
+		return hasInstance(t.Elem())
+	case *types.Map:
+		return hasInstance(t.Key()) || hasInstance(t.Elem())
+	}
+	return isInstance(typ)
+}
+
+// instanceName returns the python name of the instantiation of the generic
+// type or function named name with the type args
+func (sym *symtab) instanceName(name string, targs []types.Type) string {
+	ids := make([]string, len(targs))
+	for i, t := range targs {
+		ids[i] = sym.typeIdName(t)
+	}
+	return name + "_" + strings.Join(ids, "_")
+}
+
+// originName returns the name of the generic type or function of the
+// instantiation named name, or name if it is not an instantiation
+func (p *Package) originName(name string) string {
+	if inst, ok := p.insts[name]; ok {
+		return inst.orig.Name()
+	}
+	return name
+}
+
+// addInstances adds the instantiations of the generic types of the package
+// used by the symbols, and those requested by instantiate directives, and
+// returns their objects, in the order of their python names
+func (p *Package) addInstances() []types.Object {
+	if p.insts == nil {
+		p.insts = make(map[string]*instance)
+	}
+	p.instantiateDirectives()
+	p.instantiateBasic()
+
+	// the methods of instantiations can use further instantiations
+	for {
+		n := len(p.insts)
+		for _, fn := range p.syms.names() {
+			s := p.syms.syms[fn]
+			if s.gotyp == nil || !isInstance(s.gotyp) || s.isIter() {
+				continue
+			}
+			nt := s.gotyp.(*types.Named)
+			orig := nt.Obj()
+			if orig.Pkg() != p.pkg || !orig.Exported() || isSkipped(orig) {
+				continue
+			}
+			p.addTypeInstance(nt)
+		}
+		if len(p.insts) == n {
+			break
+		}
+	}
+
+	names := make([]string, 0, len(p.insts))
+	for nm := range p.insts {
+		names = append(names, nm)
+	}
+	sort.Strings(names)
+	objs := make([]types.Object, len(names))
+	for i, nm := range names {
+		objs[i] = p.insts[nm].obj
+	}
+	return objs
+}
+
+// addTypeInstance adds the instantiation of a generic type of the package,
+// returning an error if it cannot be bound
+func (p *Package) addTypeInstance(nt *types.Named) error {
+	tsym, err := p.syms.addTypeIfNew(nt)
+	if err != nil {
+		return err
+	}
+	nm := strings.TrimPrefix(tsym.id, p.syms.imports[p.pkg.Path()]+"_")
+	if _, has := p.insts[nm]; has {
+		return nil
+	}
+	p.insts[nm] = &instance{
+		orig:   nt.Obj(),
+		obj:    types.NewTypeName(nt.Obj().Pos(), p.pkg, nm, nt),
+		goname: tsym.goname,
+	}
+	return nil
+}
+
+// addFuncInstance adds the instantiation of a generic function of the
+// package with the type args, returning an error if it cannot be bound
+func (p *Package) addFuncInstance(fn *types.Func, targs []types.Type) error {
+	inst, err := types.Instantiate(nil, fn.Type(), targs, true)
+	if err != nil {
+		return err
+	}
+	sig := inst.(*types.Signature)
+	if _, _, _, err := isPyCompatFunc(sig); err != nil {
+		return err
+	}
+	if err := p.syms.processTuple(sig.Params()); err != nil {
+		return err
+	}
+	if err := p.syms.processTuple(sig.Results()); err != nil {
+		return err
+	}
+	gotargs := make([]string, len(targs))
+	for i, t := range targs {
+		gotargs[i] = p.syms.typeGoName(t)
+	}
+	nm := p.syms.instanceName(fn.Name(), targs)
+	p.insts[nm] = &instance{
+		orig:   fn,
+		obj:    types.NewFunc(fn.Pos(), p.pkg, nm, sig),
+		goname: p.pkg.Name() + "." + fn.Name() + "[" + strings.Join(gotargs, ", ") + "]",
+	}
+	return nil
+}
+
+// hasTypeTerms returns true if the constraint restricts the type args to
+// the types of its type terms, e.g., ~int | ~float64
+func hasTypeTerms(t types.Type) bool {
+	ifc, ok := t.Underlying().(*types.Interface)
+	if !ok {
+		return false
+	}
+	for i := 0; i < ifc.NumEmbeddeds(); i++ {
+		// unions and single types are type terms, interfaces can embed some
+		et := ifc.EmbeddedType(i)
+		if _, isIfc := et.Underlying().(*types.Interface); !isIfc || hasTypeTerms(et) {
+			return true
+		}
+	}
+	return false
+}
+
+// instantiateBasic adds the instantiations of the exported generic
+// functions whose type params are all constrained to basic types, with
+// the autoTypeArgs satisfying their constraints
+func (p *Package) instantiateBasic() {
+	inst := make(map[types.Object]bool)
+	for _, in := range p.insts {
+		inst[in.orig] = true
+	}
+	scope := p.pkg.Scope()
+	for _, name := range scope.Names() {
+		fn, ok := scope.Lookup(name).(*types.Func)
+		if !ok || !fn.Exported() || !isGeneric(fn) || isSkipped(fn) || inst[fn] {
+			continue
+		}
+		tps := fn.Type().(*types.Signature).TypeParams()
+		basic := true
+		for i := 0; i < tps.Len(); i++ {
+			basic = basic && hasTypeTerms(tps.At(i).Constraint())
+		}
+		if !basic {
+			continue
+		}
+		// all the combinations of the type args, the unsatisfying ones failing
+		targs := make([]types.Type, tps.Len())
+		var add func(i int)
+		add = func(i int) {
+			if i == len(targs) {
+				p.addFuncInstance(fn, append([]types.Type(nil), targs...))
+				return
+			}
+			for _, t := range autoTypeArgs {
+				targs[i] = t
+				add(i + 1)
+			}
+		}
+		add(0)
+	}
+}
+
+// instantiateDirectives adds the instantiations requested by the
+// instantiate directives of the package, warning about invalid ones
+func (p *Package) instantiateDirectives() {
+	names := make([]string, 0, len(p.directives()))
+	for nm := range p.directives() {
+		names = append(names, nm)
+	}
+	sort.Strings(names)
+	for _, nm := range names {
+		args, ok := p.directive(nm, "instantiate")
+		if !ok {
+			continue
+		}
+		for _, arg := range strings.Split(args, "\n") {
+			if err := p.instantiate(arg); err != nil && !NoWarn {
+				fmt.Printf("gopy: warning: ignoring %sinstantiate directive of %s.%s: %v\n", directivePrefix, p.Name(), nm, err)
+			}
+		}
+	}
+}
+
+// instantiate adds the instantiation of a generic type or function of the
+// package written as Name[T1, T2]
+func (p *Package) instantiate(arg string) error {
+	expr, err := parser.ParseExpr(arg)
+	if err != nil {
+		return fmt.Errorf("invalid instantiation %q", arg)
+	}
+	var x ast.Expr
+	var idx []ast.Expr
+	switch e := expr.(type) {
+	case *ast.IndexExpr:
+		x, idx = e.X, []ast.Expr{e.Index}
+	case *ast.IndexListExpr:
+		x, idx = e.X, e.Indices
+	default:
+		return fmt.Errorf("invalid instantiation %q, want Name[T1, T2]", arg)
+	}
+	id, ok := x.(*ast.Ident)
+	if !ok {
+		return fmt.Errorf("invalid instantiation %q, want Name[T1, T2]", arg)
+	}
+	obj := p.pkg.Scope().Lookup(id.Name)
+	if obj == nil || !obj.Exported() || !isGeneric(obj) {
+		return fmt.Errorf("%s is not an exported generic type or function of the package", id.Name)
+	}
+	if isSkipped(obj) {
+		return fmt.Errorf("%s is skipped", id.Name)
+	}
+	targs := make([]types.Type, len(idx))
+	for i, ie := range idx {
+		tv, err := types.Eval(token.NewFileSet(), p.pkg, token.NoPos, types.ExprString(ie))
+		if err != nil {
+			return err
+		}
+		if !tv.IsType() {
+			return fmt.Errorf("%s is not a type", types.ExprString(ie))
+		}
+		targs[i] = tv.Type
+	}
+	switch obj := obj.(type) {
+	case *types.Func:
+		return p.addFuncInstance(obj, targs)
+	case *types.TypeName:
+		inst, err := types.Instantiate(nil, obj.Type(), targs, true)
+		if err != nil {
+			return err
+		}
+		return p.addTypeInstance(inst.(*types.Named))
+	}
+	return nil
+}
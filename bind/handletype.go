@@ -0,0 +1,155 @@
+// Copyright 2024 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package bind
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// The Go values are passed to python by handle, registered in gopyh, as
+// int64 handles by default, for speed, or as string handles, naming the
+// Go type of their value, e.g., "*pkg.Person#42", for debugging, with the
+// Handle option.  gopyh keys its handles by int64 either way: the generated
+// go code converts the handles passed from python to gopyh handles with
+// handleGo, and the other way around with handleC, which keeps the C
+// string of each string handle until the handle is deleted, so that
+// python does not need to free it.
+
+// handleType is the representation of the handles in the generated code
+type handleType struct {
+	cgo    string // type of the handles in cgo
+	py     string // type of the handles in pybindgen
+	pyType string // python type of the handles
+	pyZero string // python value of no handle
+	// python expression of whether the handle of the %s expression is nil
+	pyNil string
+	// go code of handleGo, handleC and handleFree
+	goConv string
+}
+
+// handleTypes are the handle types of the Handle option, by name
+var handleTypes = map[string]handleType{
+	"int64": {
+		cgo:    "C.longlong",
+		py:     "int64_t",
+		pyType: "int",
+		pyZero: "0",
+		pyNil:  "%s < 1",
+		goConv: `
+// handleGo returns the gopyh handle of a handle passed from python
+func handleGo(h CGoHandle) gopyh.CGoHandle {
+	return gopyh.CGoHandle(h)
+}
+
+// handleC returns the handle passed to python of a gopyh handle
+func handleC(h gopyh.CGoHandle) CGoHandle {
+	return CGoHandle(h)
+}
+
+// handleFree frees the resources of a deleted gopyh handle
+func handleFree(h gopyh.CGoHandle) {}
+`,
+	},
+	"string": {
+		cgo:    "*C.char",
+		py:     "char*",
+		pyType: "str",
+		pyZero: `""`,
+		pyNil:  "not %s",
+		goConv: `
+// handleStrs are the C strings of the handles passed to python
+var (
+	handleMu   sync.Mutex
+	handleStrs = make(map[gopyh.CGoHandle]*C.char)
+	handleNil  = C.CString("")
+)
+
+// handleGo returns the gopyh handle of a handle passed from python,
+// the number after the # of the string
+func handleGo(h CGoHandle) gopyh.CGoHandle {
+	if h == nil {
+		return 0
+	}
+	s := C.GoString(h)
+	n, err := strconv.ParseInt(s[strings.LastIndexByte(s, '#')+1:], 10, 64)
+	if err != nil {
+		return 0
+	}
+	return gopyh.CGoHandle(n)
+}
+
+// handleC returns the handle passed to python of a gopyh handle, its
+// type#number string, kept until the handle is deleted, or "" for none
+func handleC(h gopyh.CGoHandle) CGoHandle {
+	if h < 1 {
+		return handleNil
+	}
+	handleMu.Lock()
+	defer handleMu.Unlock()
+	if s, ok := handleStrs[h]; ok {
+		return s
+	}
+	v, err := gopyh.VarFromHandleTry(h, "")
+	if err != nil {
+		return handleNil
+	}
+	s := C.CString(fmt.Sprintf("%T#%d", v, h))
+	handleStrs[h] = s
+	return s
+}
+
+// handleFree frees the C string of a deleted gopyh handle
+func handleFree(h gopyh.CGoHandle) {
+	if _, err := gopyh.VarFromHandleTry(h, ""); err == nil {
+		return
+	}
+	handleMu.Lock()
+	defer handleMu.Unlock()
+	if s, ok := handleStrs[h]; ok {
+		delete(handleStrs, h)
+		C.free(unsafe.Pointer(s))
+	}
+}
+`,
+	},
+}
+
+// curHandle is the handle type set by SetHandleType
+var curHandle = handleTypes["int64"]
+
+// SetHandleType sets the type of the handles of the generated code, int64
+// (the default, if empty) or string.  It must be called before parsing the
+// packages to bind, as it resets the symbol tables, whose symbols have the
+// types of their handles.
+func SetHandleType(name string) error {
+	if name == "" {
+		name = "int64"
+	}
+	ht, ok := handleTypes[name]
+	if !ok {
+		var names []string
+		for nm := range handleTypes {
+			names = append(names, nm)
+		}
+		sort.Strings(names)
+		return fmt.Errorf("gopy: invalid handle type %q, must be one of %s", name, strings.Join(names, ", "))
+	}
+	if ht.py == curHandle.py {
+		return nil
+	}
+	curHandle = ht
+	CGoHandle = ht.cgo
+	PyHandle = ht.py
+	initUniverse()
+	return nil
+}
+
+// pyNilHandle returns the python expression of whether the handle of the
+// python expression is nil
+func pyNilHandle(h string) string {
+	return fmt.Sprintf(curHandle.pyNil, h)
+}
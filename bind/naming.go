@@ -0,0 +1,101 @@
+// Copyright 2024 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package bind
+
+import (
+	"fmt"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+var rxValidPkgName = regexp.MustCompile(`^[\pL_][\pL_\pN]*$`)
+
+// The output name, e.g., "mod", names all the generated files and modules:
+// the mod.go cgo wrapper, the _mod extension module built from it, the
+// mod_go shared library and the mod.py wrapper of the first package.  It may
+// be the dotted name of a python package nested in others, e.g.,
+// "org.proj.mod", in which case the python package is generated in the
+// org/proj/mod directory of the pkg and exe commands, and is imported with
+// that full name.
+
+// EmbedPkg names the subpackage of an existing python package the bindings
+// are generated into, as embedded bindings, see SetEmbedded
+const EmbedPkg = "_gopy"
+
+// SetName sets the output name of the configuration from a possibly dotted
+// python package name, each component of which must be a python identifier
+func (cfg *BindCfg) SetName(name string) error {
+	comps := strings.Split(name, ".")
+	for _, c := range comps {
+		if !rxValidPkgName.MatchString(c) {
+			return fmt.Errorf("gopy: invalid python package name: %q", name)
+		}
+	}
+	n := len(comps) - 1
+	cfg.Name = comps[n]
+	cfg.Parent = strings.Join(comps[:n], ".")
+	return nil
+}
+
+// SetEmbedded sets the configuration to generate the bindings as the
+// _gopy subpackage of the existing python package of the dotted name, e.g.,
+// myapp.core._gopy, keeping their output name
+func (cfg *BindCfg) SetEmbedded(parent string) error {
+	for _, c := range strings.Split(parent, ".") {
+		if !rxValidPkgName.MatchString(c) {
+			return fmt.Errorf("gopy: invalid python package name: %q", parent)
+		}
+	}
+	cfg.Parent = parent
+	cfg.Embedded = true
+	return nil
+}
+
+// FullName returns the full dotted python name of the output package
+func (cfg *BindCfg) FullName() string {
+	if cfg.Embedded {
+		return cfg.Parent + "." + EmbedPkg
+	}
+	if cfg.Parent == "" {
+		return cfg.Name
+	}
+	return cfg.Parent + "." + cfg.Name
+}
+
+// ExtName returns the name of the _name CPython extension module
+func (cfg *BindCfg) ExtName() string {
+	return "_" + cfg.Name
+}
+
+// LibName returns the name of the name_go shared library, without extension
+func (cfg *BindCfg) LibName() string {
+	return cfg.Name + "_go"
+}
+
+// PkgDir returns the directory of the output package, relative to the root
+// of the python packages, e.g., org/proj/mod
+func (cfg *BindCfg) PkgDir() string {
+	return filepath.Join(strings.Split(cfg.FullName(), ".")...)
+}
+
+// DefaultName returns the python package name used for the output package
+// when none is given, from the name of the Go package or the last element
+// of its path, with the characters python does not allow replaced by _
+func DefaultName(name string) string {
+	name = path.Base(filepath.ToSlash(name))
+	name = strings.Map(func(r rune) rune {
+		switch {
+		case r == '_', 'a' <= r && r <= 'z', 'A' <= r && r <= 'Z', '0' <= r && r <= '9':
+			return r
+		}
+		return '_'
+	}, name)
+	if name == "" || ('0' <= name[0] && name[0] <= '9') {
+		name = "_" + name
+	}
+	return name
+}
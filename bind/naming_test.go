@@ -0,0 +1,95 @@
+// Copyright 2024 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package bind
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestSetName(t *testing.T) {
+	for _, tt := range []struct {
+		name   string
+		mod    string
+		parent string
+		full   string
+		dir    string
+		err    bool
+	}{
+		{"hi", "hi", "", "hi", "hi", false},
+		{"x", "x", "", "x", "x", false},
+		{"org.proj.mod", "mod", "org.proj", "org.proj.mod", filepath.Join("org", "proj", "mod"), false},
+		{"a.b", "b", "a", "a.b", filepath.Join("a", "b"), false},
+		{"", "", "", "", "", true},
+		{"a..b", "", "", "", "", true},
+		{"a.b.", "", "", "", "", true},
+		{"my-pkg", "", "", "", "", true},
+		{"1st", "", "", "", "", true},
+	} {
+		var cfg BindCfg
+		err := cfg.SetName(tt.name)
+		if tt.err {
+			if err == nil {
+				t.Errorf("SetName(%q): expected error", tt.name)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("SetName(%q): unexpected error: %v", tt.name, err)
+			continue
+		}
+		if cfg.Name != tt.mod || cfg.Parent != tt.parent {
+			t.Errorf("SetName(%q): expected %q in %q, actual %q in %q", tt.name, tt.mod, tt.parent, cfg.Name, cfg.Parent)
+		}
+		if got := cfg.FullName(); got != tt.full {
+			t.Errorf("SetName(%q): expected full name %q, actual %q", tt.name, tt.full, got)
+		}
+		if got := cfg.PkgDir(); got != tt.dir {
+			t.Errorf("SetName(%q): expected dir %q, actual %q", tt.name, tt.dir, got)
+		}
+		if got := cfg.ExtName(); got != "_"+tt.mod {
+			t.Errorf("SetName(%q): expected extension _%s, actual %q", tt.name, tt.mod, got)
+		}
+		if got := cfg.LibName(); got != tt.mod+"_go" {
+			t.Errorf("SetName(%q): expected library %s_go, actual %q", tt.name, tt.mod, got)
+		}
+	}
+}
+
+func TestDefaultName(t *testing.T) {
+	for _, tt := range []struct {
+		name string
+		want string
+	}{
+		{"hi", "hi"},
+		{"github.com/go-python/gopy/_examples/hi", "hi"},
+		{"github.com/user/go-lib", "go_lib"},
+		{"example.com/v2.lib", "v2_lib"},
+		{"./3d", "_3d"},
+	} {
+		if got := DefaultName(tt.name); got != tt.want {
+			t.Errorf("DefaultName(%q): expected %q, actual %q", tt.name, tt.want, got)
+		}
+	}
+}
+
+func TestSetEmbedded(t *testing.T) {
+	cfg := BindCfg{Name: "mylib"}
+	if err := cfg.SetEmbedded("myapp.core"); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := cfg.FullName(), "myapp.core._gopy"; got != want {
+		t.Errorf("expected full name %q, actual %q", want, got)
+	}
+	if got, want := cfg.PkgDir(), filepath.Join("myapp", "core", "_gopy"); got != want {
+		t.Errorf("expected dir %q, actual %q", want, got)
+	}
+	if got := cfg.ExtName(); got != "_mylib" {
+		t.Errorf("expected extension _mylib, actual %q", got)
+	}
+	if err := cfg.SetEmbedded("my-app"); err == nil {
+		t.Errorf("SetEmbedded(%q): expected error", "my-app")
+	}
+}
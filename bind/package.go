@@ -8,11 +8,14 @@ import (
 	"fmt"
 	"go/ast"
 	"go/doc"
+	"go/token"
 	"go/types"
 	"path/filepath"
 	"reflect"
 	"strconv"
 	"strings"
+	"unicode"
+	"unicode/utf8"
 )
 
 // Package ties types.Package and ast.Package together.
@@ -28,12 +31,20 @@ type Package struct {
 	consts    []*Const
 	enums     []*Enum
 	vars      []*Var
+	errs      []*Error // sentinel errors and error types
 	structs   []*Struct
 	ifaces    []*Interface
 	slices    []*Slice
 	maps      []*Map
 	funcs     []*Func
-	pyimports map[string]string // extra python imports from incidental python wrapper includes
+	columns   []*columnsFunc                    // functions of parallel slices, see gen_columns.go
+	pyimports map[string]string                 // extra python imports from incidental python wrapper includes
+	dirs      map[string]map[string]string      // gopy directives of the symbols, see directives
+	insts     map[string]*instance              // instantiations of generic types and functions, by python name
+	borrows   map[*types.Signature]map[int]bool // borrowed string params of the funcs, see gen_borrow.go
+	checks    map[*types.Signature][]argCheck   // checks of the args of the funcs, see gen_checks.go
+	decls     map[string]*ast.FuncDecl          // declarations of the funcs and methods, see gen_checks.go
+	godocs    map[*types.Signature]string       // Go docs of the funcs, describing their args, see docItems
 	// calls   []*Signature // TODO: could optimize calls back into python to gen once
 }
 
@@ -45,6 +56,8 @@ func ResetPackages() {
 	universeMutex.Lock()
 	defer universeMutex.Unlock()
 	Packages = nil
+	skippedObjs = map[types.Object]bool{}
+	resetStats()
 	makeGoPackage()
 	current = newSymtab(nil, universe)
 }
@@ -100,6 +113,12 @@ func (p *Package) AddPyImport(ipath string, extra bool) {
 		return
 	}
 	nm := filepath.Base(ipath)
+	for _, op := range Packages {
+		if op.pkg.Path() == ipath {
+			nm = op.Name() // python module is named after the package, not its dir
+			break
+		}
+	}
 	p.pyimports[ipath] = nm
 	// if extra {
 	// 	fmt.Printf("%v added py import: %v = %v\n", mypath, ipath, nm)
@@ -110,6 +129,11 @@ func (p *Package) AddPyImport(ipath string, extra bool) {
 // parent is the name of the containing scope ("" for global scope)
 func (p *Package) getDoc(parent string, o types.Object) string {
 	n := o.Name()
+	po := o // the object declared in the package, the generic of instantiations
+	if inst, ok := p.insts[n]; ok && inst.obj == o {
+		n, po = inst.orig.Name(), inst.orig
+	}
+	parent = p.originName(parent)
 	switch tp := o.(type) {
 	case *types.Const:
 		// Check for untyped consts
@@ -165,6 +189,9 @@ func (p *Package) getDoc(parent string, o types.Object) string {
 					for _, field := range structSpec.Fields.List {
 						for _, fieldName := range field.Names {
 							if fieldName.Name == tp.Name() {
+								if field.Doc == nil {
+									return field.Comment.Text()
+								}
 								return field.Doc.Text()
 							}
 						}
@@ -190,17 +217,20 @@ func (p *Package) getDoc(parent string, o types.Object) string {
 		}
 
 		doc := func() string {
-			if o.Parent() == nil || (o.Parent() != nil && parent != "") {
+			if po.Parent() == nil || (po.Parent() != nil && parent != "") {
 				for _, typ := range p.doc.Types {
 					if typ.Name != parent {
 						continue
 					}
-					if o.Parent() == nil {
+					if po.Parent() == nil {
 						for _, m := range typ.Methods {
 							if m.Name == n {
 								return m.Doc
 							}
 						}
+						if doc := ifaceMethodDoc(typ.Decl, n); doc != "" {
+							return doc
+						}
 					} else {
 						for _, m := range typ.Funcs {
 							if m.Name == n {
@@ -261,6 +291,11 @@ func (p *Package) getDoc(parent string, o types.Object) string {
 			return params
 		}
 
+		if p.godocs == nil {
+			p.godocs = make(map[*types.Signature]string)
+		}
+		p.godocs[sig] = doc
+
 		params := parseFn(sig.Params())
 		results := parseFn(sig.Results())
 
@@ -275,6 +310,17 @@ func (p *Package) getDoc(parent string, o types.Object) string {
 		} else {
 			doc = docSig
 		}
+		qname := n
+		if parent != "" {
+			qname = parent + "." + n
+		}
+		p.borrowedArgs(qname, sig)
+		if secs := p.docSections(sig, p.commaOkPolicy(qname, sig)); secs != "" {
+			if !strings.HasSuffix(doc, "\n") {
+				doc += "\n"
+			}
+			doc += "\n" + secs
+		}
 		return doc
 
 	case *types.TypeName:
@@ -292,12 +338,261 @@ func (p *Package) getDoc(parent string, o types.Object) string {
 	return ""
 }
 
+// ifaceMethodDoc returns the doc of the named method in the interface
+// type declared by decl, if any
+func ifaceMethodDoc(decl *ast.GenDecl, name string) string {
+	for _, spec := range decl.Specs {
+		typSpec, ok := spec.(*ast.TypeSpec)
+		if !ok {
+			continue
+		}
+		ifcSpec, ok := typSpec.Type.(*ast.InterfaceType)
+		if !ok {
+			continue
+		}
+		for _, m := range ifcSpec.Methods.List {
+			for _, mn := range m.Names {
+				if mn.Name != name {
+					continue
+				}
+				if m.Doc == nil {
+					return m.Comment.Text()
+				}
+				return m.Doc.Text()
+			}
+		}
+	}
+	return ""
+}
+
+// docItem is an arg or return value of the python signature of a wrapped
+// function, as documented in its docstring
+type docItem struct {
+	name string // arg name, empty for return values
+	typ  string // python type
+	def  string // default value of optional args
+	desc string
+}
+
+// google returns the item formatted for a Google style docstring section
+func (it docItem) google() string {
+	typ := it.typ
+	if it.def != "" {
+		typ += ", optional"
+	}
+	switch {
+	case it.name == "":
+		return fmt.Sprintf("%s: %s", typ, it.desc)
+	case typ == "":
+		return fmt.Sprintf("%s: %s", it.name, it.desc)
+	}
+	return fmt.Sprintf("%s (%s): %s", it.name, typ, it.desc)
+}
+
+// pyArg returns the item as an arg of a python signature
+func (it docItem) pyArg() string {
+	if it.def != "" {
+		return it.name + "=" + it.def
+	}
+	return it.name
+}
+
+// docRaises is the Raises item of functions returning an error
+const docRaises = "go.GoError: if the Go error result is not nil, or a subclass of it matching the error"
+
+// docItems returns the args and return values of the python signature of
+// the wrapped function, in order, and whether it raises Go errors.
+// The description of each arg and return value is the sentence of the Go
+// doc of the function about it, if any, and its Go type, and that of the
+// value of comma-ok functions its commaOk policy.
+func (p *Package) docItems(sig *types.Signature, commaOk string) (args, rets []docItem, raises bool) {
+	sents := docSentences(p.godocs[sig])
+	goType := p.goTypeString
+	prose := func(s, desc string) string {
+		switch {
+		case s == "":
+			return desc
+		case !strings.HasSuffix(s, ".") && !strings.HasSuffix(s, "?") && !strings.HasSuffix(s, "!"):
+			s += "."
+		}
+		return s + " " + desc
+	}
+
+	var ctxs []docItem
+	params := sig.Params()
+	for i := 0; i < params.Len(); i++ {
+		v := params.At(i)
+		switch {
+		case isContextType(v.Type()):
+			ctxs = append(ctxs, docItem{name: pySafeArg(v.Name(), i), typ: "go.Context", def: "None", desc: prose(paramProse(sents, v.Name()), "Go "+goType(v.Type())+" (proxied by handle, O(1)), context.Background() if None")})
+		case sig.Variadic() && i == params.Len()-1:
+			args = append(args, docItem{name: "*args", desc: prose(paramProse(sents, v.Name()), "Go ..."+goType(v.Type().(*types.Slice).Elem())+" (copied into a new slice, O(n))")})
+		case p.borrows[sig][i]:
+			args = append(args, docItem{name: pySafeArg(v.Name(), i), typ: "str | bytes", desc: prose(paramProse(sents, v.Name()), "Go "+goType(v.Type())+" ("+borrowCost+")")})
+		default:
+			psym := p.syms.symtype(v.Type())
+			if psym == nil {
+				continue
+			}
+			args = append(args, docItem{name: pySafeArg(v.Name(), i), typ: psym.pysig, desc: prose(paramProse(sents, v.Name()), "Go "+goType(v.Type())+" ("+psym.convCost()+")")})
+		}
+	}
+	args = append(args, ctxs...)
+
+	results := sig.Results()
+	for i := 0; i < results.Len(); i++ {
+		typ := results.At(i).Type()
+		if isErrorType(typ) {
+			raises = true
+			continue
+		}
+		if commaOk != "" && i == 1 {
+			if len(rets) > 0 {
+				rets[0].desc += commaOkDesc(commaOk)
+			}
+			continue
+		}
+		rsym := p.syms.symtype(typ)
+		if rsym == nil {
+			continue
+		}
+		cost := rsym.convCost()
+		if i == 0 && isAdapted(rsym) {
+			cost = adaptCost(rsym)
+		}
+		desc := paramProse(sents, results.At(i).Name())
+		if desc == "" && i == 0 {
+			desc = returnsProse(sents)
+		}
+		rets = append(rets, docItem{typ: rsym.pysig, desc: prose(desc, "Go "+goType(typ)+" ("+cost+")")})
+	}
+	if results.Len() == 0 {
+		args = append(args, docItem{name: "goRun", typ: "bool", def: "False", desc: "run the call in a separate goroutine, without waiting for it to return"})
+	}
+	return args, rets, raises
+}
+
+// docSentences returns the sentences of the paragraphs of a Go doc, but
+// its code blocks
+func docSentences(doc string) []string {
+	var sents []string
+	for _, para := range strings.Split(doc, "\n\n") {
+		if strings.HasPrefix(para, " ") || strings.HasPrefix(para, "\t") {
+			continue
+		}
+		text := strings.Join(strings.Fields(para), " ")
+		for text != "" {
+			i := strings.Index(text, ". ")
+			if i < 0 {
+				sents = append(sents, text)
+				break
+			}
+			sents = append(sents, text[:i+1])
+			text = text[i+2:]
+		}
+	}
+	return sents
+}
+
+// docStopWords are the param names that are also common English words,
+// whose sentences are not searched in the Go docs
+var docStopWords = map[string]bool{
+	"a": true, "an": true, "the": true, "to": true, "in": true, "of": true, "on": true,
+	"at": true, "by": true, "as": true, "is": true, "it": true, "or": true, "and": true, "if": true,
+}
+
+// paramProse returns the first of the sentences of a Go doc mentioning the
+// param or result of the name, if any
+func paramProse(sents []string, name string) string {
+	if name == "" || name == "_" || docStopWords[name] {
+		return ""
+	}
+	isWord := func(r rune) bool {
+		return r == '_' || r == '\'' || unicode.IsLetter(r) || unicode.IsDigit(r)
+	}
+	for _, s := range sents {
+		for i := 0; ; {
+			j := strings.Index(s[i:], name)
+			if j < 0 {
+				break
+			}
+			j += i
+			k := j + len(name)
+			before, _ := utf8.DecodeLastRuneInString(s[:j])
+			after, _ := utf8.DecodeRuneInString(s[k:])
+			if (j == 0 || !isWord(before)) && (k == len(s) || !isWord(after)) {
+				return s
+			}
+			i = k
+		}
+	}
+	return ""
+}
+
+// returnsProse returns what the sentences of a Go doc say the function
+// returns, in its first sentence with "returns", if any, e.g., "the square
+// root of x." of "Sqrt returns the square root of x."
+func returnsProse(sents []string) string {
+	for _, s := range sents {
+		if _, ret, ok := strings.Cut(s, " returns "); ok {
+			return ret
+		}
+	}
+	return ""
+}
+
+// goTypeString returns the Go type, qualified by package name
+// for types of other packages
+func (p *Package) goTypeString(typ types.Type) string {
+	return types.TypeString(typ, func(o *types.Package) string {
+		if o == p.pkg {
+			return ""
+		}
+		return o.Name()
+	})
+}
+
+// docSections returns the Google style Args, Returns and Raises sections
+// documenting the python signature of the wrapped function, as understood
+// by Sphinx (with the napoleon extension).
+func (p *Package) docSections(sig *types.Signature, commaOk string) string {
+	args, rets, raises := p.docItems(sig, commaOk)
+
+	var b strings.Builder
+	section := func(name string, items []string) {
+		if len(items) == 0 {
+			return
+		}
+		if b.Len() > 0 {
+			b.WriteString("\n")
+		}
+		b.WriteString(name + ":\n")
+		for _, it := range items {
+			b.WriteString("    " + it + "\n")
+		}
+	}
+	google := func(items []docItem) []string {
+		strs := make([]string, len(items))
+		for i, it := range items {
+			strs[i] = it.google()
+		}
+		return strs
+	}
+	section("Args", google(args))
+	section("Returns", google(rets))
+	if raises {
+		section("Raises", []string{docRaises})
+	}
+	return b.String()
+}
+
 // process collects informations about a go package.
 func (p *Package) process() error {
 	var err error
 
 	p.syms.pkg = p.pkg
 	p.syms.addImport(p.pkg)
+	p.markSkipped()
 
 	funcs := make(map[string]*Func)
 	structs := make(map[string]*Struct)
@@ -306,22 +601,38 @@ func (p *Package) process() error {
 	maps := make(map[string]*Map)
 
 	scope := p.pkg.Scope()
+	var objs []types.Object
 	for _, name := range scope.Names() {
 		obj := scope.Lookup(name)
-		if !obj.Exported() {
+		if !obj.Exported() || isSkipped(obj) || isGeneric(obj) {
 			continue
 		}
 
 		p.n++
-		p.syms.addSymbol(obj)
+		if p.isColumnsFunc(obj) {
+			objs = append(objs, obj)
+			continue // see gen_columns.go
+		}
+		if err := p.syms.addSymbol(obj); err != nil {
+			statDiag(skipUnsupported, p.Name()+"."+name, obj, errDetail(err))
+		}
+		objs = append(objs, obj)
 	}
+	insts := p.addInstances()
+	p.n += len(insts)
 
-	for _, name := range scope.Names() {
-		obj := scope.Lookup(name)
-		if !obj.Exported() {
+	for _, obj := range append(objs, insts...) {
+		name := obj.Name()
+		if skippedType(obj.Type()) != "" {
 			continue
 		}
 
+		if _, isInst := p.insts[name]; !isInst {
+			if e := newError(p, obj); e != nil {
+				p.errs = append(p.errs, e)
+			}
+		}
+
 		switch obj := obj.(type) {
 		case *types.Const:
 			p.addConst(obj)
@@ -330,6 +641,10 @@ func (p *Package) process() error {
 			p.addVar(obj)
 
 		case *types.Func:
+			if shape, ok := p.directive(name, "columns"); ok {
+				p.addColumnsFunc(obj, shape)
+				continue
+			}
 			fv, err := newFuncFrom(p, "", obj, obj.Type().(*types.Signature))
 			if err != nil {
 				continue
@@ -426,23 +741,24 @@ func (p *Package) process() error {
 
 	// remove ctors from funcs.
 	// add methods.
-	for sname, s := range structs {
+	// the maps are iterated in the order of their names, so that the
+	// generated code does not change from a run to the next
+	for _, sname := range sortedNames(structs) {
+		s := structs[sname]
 		styp := s.GoType()
 		ptyp := types.NewPointer(styp)
 		p.syms.addType(nil, ptyp)
-		for name, fct := range funcs {
+		for _, name := range sortedNames(funcs) {
+			fct := funcs[name]
 			if !fct.Obj().Exported() {
 				continue
 			}
-			ret := fct.Return()
-			if ret == nil {
+			if fct.Return() == nil {
 				continue
 			}
-			retptr, retIsPtr := ret.(*types.Pointer)
-
-			if ret == styp || (retIsPtr && retptr.Elem() == styp) {
+			if isConstructor(fct.GoType().(*types.Signature), styp) {
 				delete(funcs, name)
-				fct.doc = p.getDoc(sname, scope.Lookup(name))
+				fct.doc = p.getDoc(sname, fct.Obj())
 				fct.ctor = true
 				s.ctors = append(s.ctors, fct)
 				structs[sname] = s
@@ -471,10 +787,17 @@ func (p *Package) process() error {
 				s.prots |= ProtoStringer
 			}
 		}
+		for _, m := range p.extEmbedMethods(sname, styp) {
+			s.meths = append(s.meths, m)
+			if isStringer(m.obj) {
+				s.prots |= ProtoStringer
+			}
+		}
 		p.addStruct(s)
 	}
 
-	for iname, ifc := range ifaces {
+	for _, iname := range sortedNames(ifaces) {
+		ifc := ifaces[iname]
 		mset := types.NewMethodSet(ifc.GoType())
 		for i := 0; i < mset.Len(); i++ {
 			meth := mset.At(i)
@@ -490,7 +813,8 @@ func (p *Package) process() error {
 		p.addInterface(ifc)
 	}
 
-	for sname, s := range slices {
+	for _, sname := range sortedNames(slices) {
+		s := slices[sname]
 		styp := s.GoType()
 		ntyp, ok := styp.(*types.Named)
 		if !ok {
@@ -515,7 +839,8 @@ func (p *Package) process() error {
 		p.addSlice(s)
 	}
 
-	for sname, s := range maps {
+	for _, sname := range sortedNames(maps) {
+		s := maps[sname]
 		styp := s.GoType()
 		ntyp, ok := styp.(*types.Named)
 		if !ok {
@@ -540,8 +865,8 @@ func (p *Package) process() error {
 		p.addMap(s)
 	}
 
-	for _, fct := range funcs {
-		p.addFunc(fct)
+	for _, name := range sortedNames(funcs) {
+		p.addFunc(funcs[name])
 	}
 
 	return err
@@ -556,6 +881,26 @@ func (p *Package) findEnum(ntyp *types.Named) *Enum {
 	return nil
 }
 
+// hasBitConsts returns whether the declarations of the consts of the type
+// shift or OR their values, as those of bit flags do
+func (p *Package) hasBitConsts(typName string) bool {
+	found := false
+	for _, t := range p.doc.Types {
+		if t.Name != typName {
+			continue
+		}
+		for _, c := range t.Consts {
+			ast.Inspect(c.Decl, func(n ast.Node) bool {
+				if be, ok := n.(*ast.BinaryExpr); ok && (be.Op == token.SHL || be.Op == token.OR) {
+					found = true
+				}
+				return !found
+			})
+		}
+	}
+	return found
+}
+
 func (p *Package) addConst(obj *types.Const) {
 	if ntyp, ok := obj.Type().(*types.Named); ok {
 		enm := p.findEnum(ntyp)
@@ -620,6 +965,31 @@ func (p *Package) Lookup(o types.Object) (Object, bool) {
 	return obj, ok
 }
 
+// sortIfaceEmbeds sorts the interfaces so that embedded interfaces come
+// before the interfaces embedding them, as their python classes are the
+// base classes of the latter.
+func (p *Package) sortIfaceEmbeds() {
+	done := make(map[*Interface]bool, len(p.ifaces))
+	sorted := make([]*Interface, 0, len(p.ifaces))
+	var visit func(ifc *Interface)
+	visit = func(ifc *Interface) {
+		if done[ifc] {
+			return
+		}
+		done[ifc] = true
+		for _, emb := range ifc.Embeds() {
+			if eifc, ok := p.objs[emb.goname].(*Interface); ok {
+				visit(eifc)
+			}
+		}
+		sorted = append(sorted, ifc)
+	}
+	for _, ifc := range p.ifaces {
+		visit(ifc)
+	}
+	p.ifaces = sorted
+}
+
 func (p *Package) sortStructEmbeds() {
 	for {
 		nswap := 0
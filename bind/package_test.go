@@ -0,0 +1,48 @@
+// Copyright 2024 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package bind
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParamProse(t *testing.T) {
+	sents := docSentences(`Clamp returns x clamped to [lo, hi].  It panics
+if hi's value is less than lo_min.
+
+	Clamp(x_max, 0, 1)
+
+The value a is ignored
+`)
+	want := []string{
+		"Clamp returns x clamped to [lo, hi].",
+		"It panics if hi's value is less than lo_min.",
+		"The value a is ignored",
+	}
+	if !reflect.DeepEqual(sents, want) {
+		t.Fatalf("unexpected sentences:\ngot  %q\nwant %q", sents, want)
+	}
+
+	for _, tc := range []struct {
+		name, want string
+	}{
+		{"x", "Clamp returns x clamped to [lo, hi]."},
+		{"hi", "Clamp returns x clamped to [lo, hi]."},
+		{"lo", "Clamp returns x clamped to [lo, hi]."},
+		{"lo_min", "It panics if hi's value is less than lo_min."},
+		{"s", ""},
+		{"x_max", ""},
+		{"a", ""},
+		{"", ""},
+	} {
+		if got := paramProse(sents, tc.name); got != tc.want {
+			t.Errorf("paramProse(%q): got %q, want %q", tc.name, got, tc.want)
+		}
+	}
+	if got, want := returnsProse(sents), "x clamped to [lo, hi]."; got != want {
+		t.Errorf("returnsProse: got %q, want %q", got, want)
+	}
+}
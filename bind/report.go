@@ -0,0 +1,235 @@
+// Copyright 2024 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package bind
+
+import (
+	"fmt"
+	"go/token"
+	"go/types"
+	"html/template"
+	"io"
+	"sort"
+	"strings"
+)
+
+// The symbols that cannot be bound, e.g., functions and methods of
+// incompatible signatures, or using types the generated code cannot refer
+// to, and fields of unsupported types, are recorded as diagnostics, with
+// their position and the reason why, along with the statistics, see
+// stats.go.  The -report option writes them as a coverage report of the
+// packages, as JSON, or as HTML for a file ending in .html, with the
+// fraction of their symbols that got bound.  The symbols excluded by the
+// user, with -exclude or //gopy:skip, are not diagnosed.
+
+// FileSets are the file sets of the loaded packages, by package path,
+// resolving the positions of the diagnostics -- this must be a global as
+// it is relevant during initial package parsing, like InternalRoots.
+var FileSets = map[string]*token.FileSet{}
+
+// Diagnostic is a symbol that cannot be bound
+type Diagnostic struct {
+	Symbol string `json:"symbol"`        // qualified name, e.g., hi.Person.Greet
+	Pos    string `json:"pos,omitempty"` // file:line of its declaration, if known
+	Reason string `json:"reason"`        // skip reason of the statistics
+	Detail string `json:"detail,omitempty"`
+	pkg    string // path of its package
+}
+
+// String returns the diagnostic as a file:line: symbol: reason: detail line
+func (d *Diagnostic) String() string {
+	s := d.Symbol + ": " + d.Reason
+	if d.Pos != "" {
+		s = d.Pos + ": " + s
+	}
+	if d.Detail != "" {
+		s += ": " + d.Detail
+	}
+	return s
+}
+
+// statDiags are the diagnostics of the skipped symbols, by reason and name,
+// guarded by statMu
+var statDiags = map[string]*Diagnostic{}
+
+// statDiag records the symbol of the object, if any, skipped for the
+// reason, with the detail of why, in the statistics and diagnostics
+func statDiag(reason, name string, obj types.Object, detail string) {
+	statSkip(reason, name)
+	d := &Diagnostic{Symbol: name, Reason: reason, Detail: detail}
+	if obj != nil && obj.Pkg() != nil {
+		d.pkg = obj.Pkg().Path()
+		if fs := FileSets[d.pkg]; fs != nil && obj.Pos().IsValid() {
+			pos := fs.Position(obj.Pos())
+			d.Pos = fmt.Sprintf("%s:%d", pos.Filename, pos.Line)
+		}
+	}
+	statMu.Lock()
+	defer statMu.Unlock()
+	statDiags[reason+"\x00"+name] = d
+}
+
+// qualName returns the qualified name of the package-level object, or
+// method, e.g., hi.Person.Greet, as matched by the symbol filters
+func qualName(obj types.Object) string {
+	name := obj.Name()
+	if fn, ok := obj.(*types.Func); ok {
+		if recv := fn.Type().(*types.Signature).Recv(); recv != nil {
+			rt := recv.Type()
+			if ptr, ok := rt.(*types.Pointer); ok {
+				rt = ptr.Elem()
+			}
+			if named, ok := rt.(*types.Named); ok {
+				name = named.Obj().Name() + "." + name
+			}
+		}
+	}
+	if obj.Pkg() == nil {
+		return name
+	}
+	return obj.Pkg().Name() + "." + name
+}
+
+// errDetail returns the message of the error, without its gopy: prefix
+func errDetail(err error) string {
+	if err == nil {
+		return ""
+	}
+	return strings.TrimPrefix(err.Error(), "gopy: ")
+}
+
+// Report is the coverage report of the bindings written by -report
+type Report struct {
+	Name     string           `json:"name"`
+	Bound    int              `json:"bound"`
+	Skipped  int              `json:"skipped"`
+	Coverage float64          `json:"coverage"` // fraction of the symbols bound
+	Packages []*PackageReport `json:"packages"`
+}
+
+// PackageReport is the coverage report of a package
+type PackageReport struct {
+	Path        string        `json:"path"`
+	Bound       int           `json:"bound"`
+	Skipped     int           `json:"skipped"`
+	Coverage    float64       `json:"coverage"`
+	Diagnostics []*Diagnostic `json:"diagnostics"`
+}
+
+// GetReport returns the coverage report of the bindings of name
+func GetReport(name string) *Report {
+	rp := &Report{Name: name, Packages: []*PackageReport{}}
+	byPath := make(map[string]*PackageReport)
+	for _, p := range Packages {
+		if p == goPackage {
+			continue
+		}
+		pr := &PackageReport{Path: p.ImportPath(), Bound: p.numBound(), Diagnostics: []*Diagnostic{}}
+		byPath[pr.Path] = pr
+		rp.Packages = append(rp.Packages, pr)
+	}
+	statMu.Lock()
+	for _, d := range statDiags {
+		pr := byPath[d.pkg]
+		if pr == nil {
+			continue
+		}
+		pr.Diagnostics = append(pr.Diagnostics, d)
+		if d.Reason == skipIncompatVar { // counted as bound by numBound
+			pr.Bound--
+		}
+	}
+	statMu.Unlock()
+	for _, pr := range rp.Packages {
+		sort.Slice(pr.Diagnostics, func(i, j int) bool {
+			di, dj := pr.Diagnostics[i], pr.Diagnostics[j]
+			if di.Symbol != dj.Symbol {
+				return di.Symbol < dj.Symbol
+			}
+			return di.Reason < dj.Reason
+		})
+		pr.Skipped = len(pr.Diagnostics)
+		pr.Coverage = coverage(pr.Bound, pr.Skipped)
+		rp.Bound += pr.Bound
+		rp.Skipped += pr.Skipped
+	}
+	rp.Coverage = coverage(rp.Bound, rp.Skipped)
+	return rp
+}
+
+// numBound returns the number of the symbols of the package counted by
+// the statistics, and the fields of its structs, bound
+func (p *Package) numBound() int {
+	n := len(p.funcs) + len(p.columns) + len(p.structs) + len(p.ifaces) + len(p.consts) + len(p.vars)
+	for _, s := range p.structs {
+		n += len(s.ctors) + len(s.meths)
+		typ := s.Struct()
+		for i := 0; i < typ.NumFields(); i++ {
+			f := typ.Field(i)
+			if isSkipped(f) {
+				continue
+			}
+			if _, err := isPyCompatField(f); err == nil {
+				n++
+			}
+		}
+	}
+	for _, e := range p.enums {
+		n += len(e.items)
+	}
+	return n
+}
+
+// coverage returns the fraction of the symbols bound, 1 if none
+func coverage(bound, skipped int) float64 {
+	if bound+skipped == 0 {
+		return 1
+	}
+	return float64(bound) / float64(bound+skipped)
+}
+
+// Diagnostics returns the diagnostics of the report, in order of packages
+func (rp *Report) Diagnostics() []*Diagnostic {
+	var ds []*Diagnostic
+	for _, pr := range rp.Packages {
+		ds = append(ds, pr.Diagnostics...)
+	}
+	return ds
+}
+
+// reportHTML is the template of the HTML coverage report
+var reportHTML = template.Must(template.New("report").Funcs(template.FuncMap{
+	"percent": func(f float64) string { return fmt.Sprintf("%.1f%%", 100*f) },
+}).Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>gopy coverage report of {{.Name}}</title>
+<style>
+body { font-family: sans-serif; }
+table { border-collapse: collapse; margin-bottom: 1em; }
+th, td { border: 1px solid #ccc; padding: 0.2em 0.6em; text-align: left; }
+td.pos { font-family: monospace; }
+</style>
+</head>
+<body>
+<h1>gopy coverage report of {{.Name}}</h1>
+<p>{{.Bound}} symbols bound, {{.Skipped}} skipped: {{percent .Coverage}} coverage</p>
+<table>
+<tr><th>package</th><th>bound</th><th>skipped</th><th>coverage</th></tr>
+{{range $i, $p := .Packages}}<tr><td><a href="#pkg{{$i}}">{{.Path}}</a></td><td>{{.Bound}}</td><td>{{.Skipped}}</td><td>{{percent .Coverage}}</td></tr>
+{{end}}</table>
+{{range $i, $p := .Packages}}{{if .Diagnostics}}<h2 id="pkg{{$i}}">{{.Path}}</h2>
+<table>
+<tr><th>symbol</th><th>position</th><th>reason</th><th>detail</th></tr>
+{{range .Diagnostics}}<tr><td>{{.Symbol}}</td><td class="pos">{{.Pos}}</td><td>{{.Reason}}</td><td>{{.Detail}}</td></tr>
+{{end}}</table>
+{{end}}{{end}}</body>
+</html>
+`))
+
+// WriteHTML writes the report as an HTML page
+func (rp *Report) WriteHTML(w io.Writer) error {
+	return reportHTML.Execute(w, rp)
+}
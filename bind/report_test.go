@@ -0,0 +1,60 @@
+// Copyright 2024 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package bind
+
+import (
+	"bytes"
+	"go/token"
+	"go/types"
+	"strings"
+	"testing"
+)
+
+func TestReport(t *testing.T) {
+	defer resetStats()
+	resetStats()
+
+	fset := token.NewFileSet()
+	f := fset.AddFile("/src/hi/hi.go", -1, 100)
+	f.SetLines([]int{0, 10, 20, 30})
+	pkg := types.NewPackage("example.com/hi", "hi")
+	FileSets[pkg.Path()] = fset
+	defer delete(FileSets, pkg.Path())
+
+	sig := types.NewSignatureType(nil, nil, nil, nil, types.NewTuple(types.NewVar(token.NoPos, pkg, "", types.Typ[types.Int]), types.NewVar(token.NoPos, pkg, "", types.Typ[types.Int])), false)
+	fn := types.NewFunc(f.Pos(25), pkg, "F", sig)
+	statDiag(skipIncompatFunc, qualName(fn), fn, "too many results")
+	statDiag(skipIncompatFunc, qualName(fn), fn, "too many results") // counted once
+	if st := GetStats("hi"); st.Skipped[skipIncompatFunc] != 1 {
+		t.Errorf("unexpected skipped: %v", st.Skipped)
+	}
+
+	d := statDiags[skipIncompatFunc+"\x00hi.F"]
+	if d == nil {
+		t.Fatalf("no diagnostic of hi.F: %v", statDiags)
+	}
+	if got, want := d.String(), "/src/hi/hi.go:3: hi.F: incompatible signature: too many results"; got != want {
+		t.Errorf("diagnostic: got %q, want %q", got, want)
+	}
+
+	rp := &Report{Name: "hi", Bound: 3, Skipped: 1, Coverage: coverage(3, 1), Packages: []*PackageReport{
+		{Path: pkg.Path(), Bound: 3, Skipped: 1, Coverage: coverage(3, 1), Diagnostics: []*Diagnostic{d}},
+	}}
+	if rp.Coverage != 0.75 || coverage(0, 0) != 1 {
+		t.Errorf("unexpected coverage: %v", rp.Coverage)
+	}
+	var buf bytes.Buffer
+	if err := rp.WriteHTML(&buf); err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		"<p>3 symbols bound, 1 skipped: 75.0% coverage</p>",
+		`<td class="pos">/src/hi/hi.go:3</td>`,
+	} {
+		if !strings.Contains(buf.String(), want) {
+			t.Errorf("HTML report does not contain %q:\n%s", want, buf.String())
+		}
+	}
+}
@@ -0,0 +1,205 @@
+// Copyright 2024 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package bind
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// The statistics of the generation summarize the surface of the bindings:
+// the numbers of bound functions, methods, structs, interfaces, constants
+// and variables, of the symbols skipped, by reason, the sizes of the
+// generated files, and the timings of the phases of the gopy command.  They
+// are printed at the end of the generation, and written as JSON by the
+// -stats option, so that users and CI can track the growth of the bindings,
+// and are never sent anywhere.
+
+// Skip reasons of the statistics
+const (
+	skipExcluded     = "excluded"               // by -include, -exclude or //gopy:skip
+	skipIncompatFunc = "incompatible signature" // functions and methods
+	skipIncompatVar  = "incompatible type"      // constants and variables
+	skipUnsupported  = "unsupported type"       // types the cgo code cannot refer to
+	skipField        = "unsupported field type" // struct fields dropped
+)
+
+// StatsPhases are the phases of the gopy commands timed in the statistics, in order
+var StatsPhases = []string{"load", "generate", "build"}
+
+var (
+	// statSkips are the names of the skipped symbols, by reason
+	statSkips = map[string]map[string]bool{}
+
+	// statOutputs are the sizes of the generated files, by path relative to
+	// the output dir
+	statOutputs = map[string]int64{}
+
+	// statTimings are the durations of the phases of the gopy command
+	statTimings = map[string]time.Duration{}
+
+	// statMu guards the statistics, recorded by the packages generated in
+	// parallel
+	statMu sync.Mutex
+)
+
+// Stats are the statistics of the generation
+type Stats struct {
+	Name       string             `json:"name"`
+	Packages   int                `json:"packages"`
+	Functions  int                `json:"functions"`
+	Methods    int                `json:"methods"`
+	Structs    int                `json:"structs"`
+	Interfaces int                `json:"interfaces"`
+	Consts     int                `json:"consts"`
+	Vars       int                `json:"vars"`
+	Skipped    map[string]int     `json:"skipped"` // numbers of skipped symbols, by reason
+	Outputs    map[string]int64   `json:"outputs"` // sizes in bytes of the generated files
+	Timings    map[string]float64 `json:"timings"` // seconds, by phase
+}
+
+// resetStats resets the statistics -- needed when doing tests
+func resetStats() {
+	statSkips = map[string]map[string]bool{}
+	statOutputs = map[string]int64{}
+	statTimings = map[string]time.Duration{}
+	statDiags = map[string]*Diagnostic{}
+}
+
+// statSkip records the symbol skipped for the reason
+func statSkip(reason, name string) {
+	statMu.Lock()
+	defer statMu.Unlock()
+	if statSkips[reason] == nil {
+		statSkips[reason] = map[string]bool{}
+	}
+	statSkips[reason][name] = true
+}
+
+// StatOutput records the size of a file generated, or built, in the output dir
+func StatOutput(path string, size int64) {
+	statMu.Lock()
+	defer statMu.Unlock()
+	statOutputs[path] = size
+}
+
+// StatPhase adds the time since start to the duration of the phase
+func StatPhase(phase string, start time.Time) {
+	statMu.Lock()
+	defer statMu.Unlock()
+	statTimings[phase] += time.Since(start)
+}
+
+// GetStats returns the statistics of the generation of the bindings of name
+func GetStats(name string) *Stats {
+	st := &Stats{
+		Name:    name,
+		Skipped: map[string]int{},
+		Outputs: map[string]int64{},
+		Timings: map[string]float64{},
+	}
+	for _, p := range Packages {
+		if p == goPackage {
+			continue
+		}
+		st.Packages++
+		st.Functions += len(p.funcs) + len(p.columns)
+		st.Structs += len(p.structs)
+		for _, s := range p.structs {
+			st.Functions += len(s.ctors)
+			st.Methods += len(s.meths)
+		}
+		st.Interfaces += len(p.ifaces)
+		st.Consts += len(p.consts)
+		for _, e := range p.enums {
+			st.Consts += len(e.items)
+		}
+		st.Vars += len(p.vars)
+	}
+	if n := len(skippedObjs); n > 0 {
+		st.Skipped[skipExcluded] = n
+	}
+	for reason, names := range statSkips {
+		st.Skipped[reason] = len(names)
+	}
+	for fn, sz := range statOutputs {
+		st.Outputs[fn] = sz
+	}
+	for ph, d := range statTimings {
+		st.Timings[ph] = d.Seconds()
+	}
+	return st
+}
+
+// String returns the summary of the statistics printed at the end of the
+// generation
+func (st *Stats) String() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "--- bindings of %s: %d packages, %d functions, %d methods, %d structs, %d interfaces, %d constants, %d variables ---\n",
+		st.Name, st.Packages, st.Functions, st.Methods, st.Structs, st.Interfaces, st.Consts, st.Vars)
+
+	reasons := make([]string, 0, len(st.Skipped))
+	nskip := 0
+	for r, n := range st.Skipped {
+		reasons = append(reasons, r)
+		nskip += n
+	}
+	sort.Strings(reasons)
+	fmt.Fprintf(&b, "skipped: %d", nskip)
+	for i, r := range reasons {
+		sep := ", "
+		if i == 0 {
+			sep = " ("
+		}
+		fmt.Fprintf(&b, "%s%d %s", sep, st.Skipped[r], r)
+	}
+	if nskip > 0 {
+		b.WriteString(")")
+	}
+	b.WriteString("\n")
+
+	fns := make([]string, 0, len(st.Outputs))
+	var total int64
+	for fn, sz := range st.Outputs {
+		fns = append(fns, fn)
+		total += sz
+	}
+	sort.Strings(fns)
+	fmt.Fprintf(&b, "outputs: %s in %d files", statSize(total), len(fns))
+	for i, fn := range fns {
+		sep := ", "
+		if i == 0 {
+			sep = " ("
+		}
+		fmt.Fprintf(&b, "%s%s %s", sep, fn, statSize(st.Outputs[fn]))
+	}
+	if len(fns) > 0 {
+		b.WriteString(")")
+	}
+	b.WriteString("\n")
+
+	b.WriteString("timings:")
+	for _, ph := range StatsPhases {
+		if s, ok := st.Timings[ph]; ok {
+			fmt.Fprintf(&b, " %s %.2fs", ph, s)
+		}
+	}
+	b.WriteString("\n")
+	return b.String()
+}
+
+// statSize returns the size in bytes in human readable form
+func statSize(sz int64) string {
+	switch {
+	case sz >= 1<<20:
+		return fmt.Sprintf("%.1f MB", float64(sz)/(1<<20))
+	case sz >= 1<<10:
+		return fmt.Sprintf("%.1f KB", float64(sz)/(1<<10))
+	}
+	return fmt.Sprintf("%d B", sz)
+}
@@ -0,0 +1,38 @@
+// Copyright 2024 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package bind
+
+import (
+	"testing"
+	"time"
+)
+
+func TestStats(t *testing.T) {
+	defer resetStats()
+	resetStats()
+
+	statSkip(skipIncompatFunc, "hi.F")
+	statSkip(skipIncompatFunc, "hi.F") // counted once
+	statSkip(skipIncompatFunc, "hi.G")
+	statSkip(skipField, "hi.S.C")
+	StatOutput("hi.go", 2048)
+	StatOutput("build.py", 100)
+	statTimings["load"] = 1500 * time.Millisecond
+	statTimings["generate"] = 250 * time.Millisecond
+
+	st := GetStats("hi")
+	if st.Skipped[skipIncompatFunc] != 2 || st.Skipped[skipField] != 1 {
+		t.Errorf("unexpected skipped: %v", st.Skipped)
+	}
+	st.Packages, st.Functions, st.Structs = 1, 3, 2
+	want := `--- bindings of hi: 1 packages, 3 functions, 0 methods, 2 structs, 0 interfaces, 0 constants, 0 variables ---
+skipped: 3 (2 incompatible signature, 1 unsupported field type)
+outputs: 2.1 KB in 2 files (build.py 100 B, hi.go 2.0 KB)
+timings: load 1.50s generate 0.25s
+`
+	if got := st.String(); got != want {
+		t.Errorf("unexpected summary:\ngot:\n%s\nwant:\n%s", got, want)
+	}
+}
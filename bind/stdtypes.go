@@ -20,11 +20,12 @@ func makeGoPackage() {
 	Packages = append(Packages, goPackage)
 }
 
-// addStdSliceMaps adds std Slice and Map types to universe
+// addStdSliceMaps adds std Slice and Map types to universe -- []rune is
+// passed as a python str instead, see converters.go
 func addStdSliceMaps() {
 	makeGoPackage()
 	gopk := goPackage.pkg
-	sltyps := []string{"int", "int64", "int32", "int16", "int8", "uint", "uint64", "uint32", "uint16", "uint8", "bool", "byte", "rune", "float64", "float32", "string", "error"}
+	sltyps := []string{"int", "int64", "int32", "int16", "int8", "uint", "uint64", "uint32", "uint16", "uint8", "bool", "byte", "float64", "float32", "complex128", "complex64", "string", "error"}
 	for _, tn := range sltyps {
 		universe.addSliceType(gopk, nil, types.NewSlice(universe.sym(tn).gotyp), skType, "Slice_"+tn, "[]"+tn)
 	}
@@ -90,9 +91,9 @@ func stdBasicTypes() map[string]*symbol {
 			goname:  "int8",
 			id:      "int",
 			cpyname: "int8_t",
-			cgoname: "C.char",
+			cgoname: "C.schar", // signed, as C.char may not be
 			pysig:   "int",
-			go2py:   "C.char",
+			go2py:   "C.schar",
 			py2go:   "int8",
 			zval:    "0",
 			pyfmt:   "b",
@@ -322,20 +323,20 @@ func stdBasicTypes() map[string]*symbol {
 			pyfmt:   "s",
 		},
 
-		"rune": { // FIXME(sbinet) py2/py3
+		"rune": { // passed as a single character str, see runePyToGo
 			gopkg:   look("rune").Pkg(),
 			goobj:   look("rune"),
 			gotyp:   look("rune").Type(),
 			kind:    skType | skBasic,
 			goname:  "rune",
 			id:      "rune",
-			cpyname: "int32_t",
-			cgoname: "C.long",
+			cpyname: "PyObject*",
+			cgoname: "*C.PyObject",
 			pysig:   "str",
-			go2py:   "C.long",
-			py2go:   "rune",
+			go2py:   "runeGoToPy",
+			py2go:   "runePyToGo",
 			zval:    "0",
-			pyfmt:   "i",
+			pyfmt:   "O&",
 		},
 
 		"error": {
@@ -43,6 +43,8 @@ const (
 	skSlice
 	skStruct
 	skString
+	skIter
+	skChan
 )
 
 var (
@@ -61,6 +63,8 @@ var (
 		"slice":     skSlice,
 		"struct":    skStruct,
 		"string":    skString,
+		"iter":      skIter,
+		"chan":      skChan,
 	}
 )
 
@@ -87,33 +91,64 @@ func pySafeName(nm string) string {
 	return nm
 }
 
-// pySafeArg returns an arg name that python will not barf on
+// pySafeArg returns an arg name that python will not barf on,
+// naming unnamed and blank args by their index
 func pySafeArg(anm string, idx int) string {
-	if anm == "" {
+	if anm == "" || anm == "_" {
 		anm = fmt.Sprintf("arg_%d", idx)
 	}
 	return pySafeName(anm)
 }
 
+// goShimNames are the identifiers of the generated cgo code, that
+// the args of the shims must not shadow
+var goShimNames = map[string]bool{
+	"C": true, "gopyh": true, "context": true, "errors": true, "fmt": true,
+	"os": true, "reflect": true, "runtime": true, "strings": true, "sync": true,
+	"atomic": true, "time": true, "unsafe": true,
+	"_handle": true, "_saved_thread": true, "_fun_arg": true, "_gstate": true,
+	"_fcargs": true, "_fcret": true, "vifc": true, "cret": true, "estr": true,
+	"__err": true, "goRun": true,
+}
+
+// goSafeArg returns the name of an arg in the generated cgo code, with a
+// trailing _ if it would shadow an imported package or a generated local
+func goSafeArg(anm string, idx int) string {
+	anm = pySafeArg(anm, idx)
+	if _, imp := current.importNames[anm]; imp || goShimNames[anm] {
+		return anm + "_"
+	}
+	return anm
+}
+
 // isPyCompatVar checks if var is compatible with python
 func isPyCompatVar(v *symbol) error {
 	if v == nil {
 		return fmt.Errorf("gopy: var symbol not found")
 	}
-	if v.isPointer() && v.isBasic() {
+	if nm := skippedType(v.gotyp); nm != "" {
+		return fmt.Errorf("gopy: var type %s is skipped", nm)
+	}
+	if v.isPointer() && v.isBasic() && !isDataclass(v) {
 		return fmt.Errorf("gopy: var is pointer to basic type")
 	}
 	if isErrorType(v.gotyp) {
 		return fmt.Errorf("gopy: var is error type")
 	}
-	if _, isChan := v.gotyp.(*types.Chan); isChan {
-		return fmt.Errorf("gopy: var is channel type")
+	if v.isIter() {
+		return fmt.Errorf("gopy: var is iterator type")
 	}
 	return nil
 }
 
 // isPyCompatType checks if type is compatible with python
 func isPyCompatType(typ types.Type) error {
+	if nm := skippedType(typ); nm != "" {
+		return fmt.Errorf("gopy: type %s is skipped", nm)
+	}
+	if why := unboundType(typ); why != "" {
+		return fmt.Errorf("gopy: %s", why)
+	}
 	typ = typ.Underlying()
 	if ptyp, isPtr := typ.(*types.Pointer); isPtr {
 		if _, isBasic := ptyp.Elem().(*types.Basic); isBasic {
@@ -123,9 +158,6 @@ func isPyCompatType(typ types.Type) error {
 	if isErrorType(typ) {
 		return fmt.Errorf("gopy: type is error type")
 	}
-	if _, isChan := typ.(*types.Chan); isChan {
-		return fmt.Errorf("gopy: type is channel type")
-	}
 	return nil
 }
 
@@ -134,6 +166,9 @@ func isPyCompatField(f *types.Var) (*symbol, error) {
 	if !f.Exported() || f.Embedded() {
 		return nil, fmt.Errorf("gopy: field not exported or is embedded")
 	}
+	if isSkipped(f) {
+		return nil, fmt.Errorf("gopy: field is skipped")
+	}
 	ftyp := current.symtype(f.Type())
 	if _, isSig := f.Type().Underlying().(*types.Signature); isSig {
 		return nil, fmt.Errorf("gopy: type is function signature")
@@ -147,19 +182,22 @@ func isPyCompatField(f *types.Var) (*symbol, error) {
 // isPyCompatFunc checks if function signature is a python-compatible function.
 // Returns nil if function is compatible, err message if not.
 // Also returns the return type of the function
-// haserr is true if 2nd arg is an error type, which is only
-// supported form of multi-return-value functions
+// haserr is true if 2nd arg is an error type, which, with a bool
+// 2nd arg of the comma-ok idiom, is the only supported form of
+// multi-return-value functions
 // hasfun is true if one of the args is a function signature
 func isPyCompatFunc(sig *types.Signature) (ret types.Type, haserr, hasfun bool, err error) {
 	res := sig.Results()
 
 	switch res.Len() {
 	case 2:
-		if !isErrorType(res.At(1).Type()) {
-			err = fmt.Errorf("gopy: second result value must be of type error: %s", sig.String())
+		switch {
+		case isErrorType(res.At(1).Type()):
+			haserr = true
+		case !isCommaOk(sig):
+			err = fmt.Errorf("gopy: second result value must be of type error or bool: %s", sig.String())
 			return
 		}
-		haserr = true
 		ret = res.At(0).Type()
 	case 1:
 		if isErrorType(res.At(0).Type()) {
@@ -179,7 +217,7 @@ func isPyCompatFunc(sig *types.Signature) (ret types.Type, haserr, hasfun bool,
 		if err = isPyCompatType(ret); err != nil {
 			return
 		}
-		if _, isSig := ret.Underlying().(*types.Signature); isSig {
+		if _, isSig := ret.Underlying().(*types.Signature); isSig && !isIterSeq(ret) {
 			err = fmt.Errorf("gopy: return type is signature")
 			return
 		}
@@ -197,6 +235,10 @@ func isPyCompatFunc(sig *types.Signature) (ret types.Type, haserr, hasfun bool,
 		if err = isPyCompatType(argt); err != nil {
 			return
 		}
+		if isIterSeq(argt) {
+			err = fmt.Errorf("gopy: iterator args not supported: %s", sig.String())
+			return
+		}
 		if _, isSig := argt.Underlying().(*types.Signature); isSig {
 			if !hasfun {
 				hasfun = true
@@ -288,6 +330,14 @@ func (s *symbol) isPointer() bool {
 	return (s.kind & skPointer) != 0
 }
 
+func (s *symbol) isIter() bool {
+	return (s.kind & skIter) != 0
+}
+
+func (s *symbol) isChan() bool {
+	return (s.kind & skChan) != 0
+}
+
 func (s *symbol) isPtrOrIface() bool {
 	return s.isPointer() || s.isInterface()
 }
@@ -299,10 +349,58 @@ func (s *symbol) hasHandle() bool {
 	return !s.isBasic() && !s.isSignature()
 }
 
+// convCost returns how values of the type are passed between python and
+// Go, i.e., copied or proxied by a handle, along with their rough cost,
+// for the docstrings of functions
+func (s *symbol) convCost() string {
+	switch {
+	case isRunes(s):
+		return "copied as str, O(len)"
+	case isDataclass(s):
+		return "copied as dataclass, O(fields)"
+	case s.isConverted():
+		return "copied as " + s.pysig + ", O(1)"
+	case s.isBasic():
+		if b, ok := s.gotyp.Underlying().(*types.Basic); ok && b.Info()&types.IsString != 0 {
+			return "copied, O(len)"
+		}
+		return "copied, O(1)"
+	case s.isSignature():
+		return "python callable called from Go, O(1) plus the GIL per call"
+	case s.isIter():
+		return "python iterator pulling from the Go iterator, O(1) per value"
+	case s.isPtrOrIface() || s.goname == "interface{}":
+		return "proxied by handle, O(1)"
+	case s.isSlice() || s.isMap():
+		return "proxied by handle, sharing the Go data, O(1)"
+	case s.isChan():
+		return "proxied by handle, sharing the Go channel, O(1)"
+	}
+	return "shallow copy of the Go value, via handle, O(size)"
+}
+
 func (s *symbol) hasConverter() bool {
 	return (s.go2py != "" || s.py2go != "")
 }
 
+// pyRetOwn returns the pybindgen retval ownership argument
+// needed for PyObject* return values
+func (s *symbol) pyRetOwn() string {
+	if s.cpyname == "PyObject*" {
+		return ", caller_owns_return=True"
+	}
+	return ""
+}
+
+// pyParamOwn returns the pybindgen param ownership argument
+// needed for PyObject* parameters
+func (s *symbol) pyParamOwn() string {
+	if s.cpyname == "PyObject*" {
+		return ", transfer_ownership=False"
+	}
+	return ""
+}
+
 func (s *symbol) pkgname() string {
 	if s.gopkg == nil {
 		return ""
@@ -311,7 +409,7 @@ func (s *symbol) pkgname() string {
 }
 
 func (s *symbol) GoType() types.Type {
-	if s.goobj != nil {
+	if s.goobj != nil && !isInstance(s.gotyp) { // the obj of instances is the generic type
 		return s.goobj.Type()
 	}
 	return s.gotyp
@@ -340,8 +438,9 @@ func (s *symbol) cgotypename() string {
 	return s.cgoname
 }
 
-// pyPkgId returns the python package-qualified version of Id
-func (s *symbol) pyPkgId(curPkg *types.Package) string {
+// pyPkgId returns the python package-qualified version of Id, adding the
+// import of its package to the python module of the package imp
+func (s *symbol) pyPkgId(curPkg *types.Package, imp *Package) string {
 	pnm := s.gopkg.Name()
 	ppath := s.gopkg.Path()
 	if _, has := thePyGen.pkgmap[ppath]; !has { // external symbols are all in go package
@@ -354,10 +453,10 @@ func (s *symbol) pyPkgId(curPkg *types.Package) string {
 	if pnm == "go" {
 		return pnm + "." + s.id
 	}
-	if !s.isNamed() && (s.isMap() || s.isSlice() || s.isArray()) {
+	if !s.isNamed() && (s.isMap() || s.isSlice() || s.isArray() || s.isChan()) {
 		//		idnm := strings.TrimPrefix(s.id[uidx+1:], pnm+"_") // in case it has that redundantly
 		if ppath != curPkg.Path() {
-			thePyGen.pkg.AddPyImport(ppath, true) // ensure that this is included in current package
+			imp.AddPyImport(ppath, true) // ensure that this is included in current package
 			return pnm + "." + s.id
 		} else {
 			return s.id
@@ -369,7 +468,7 @@ func (s *symbol) pyPkgId(curPkg *types.Package) string {
 	}
 	idnm := strings.TrimPrefix(s.id[uidx+1:], pnm+"_") // in case it has that redundantly
 	if ppath != curPkg.Path() {
-		thePyGen.pkg.AddPyImport(ppath, true) // ensure that this is included in current package
+		imp.AddPyImport(ppath, true) // ensure that this is included in current package
 		return pnm + "." + idnm
 	} else {
 		return idnm
@@ -495,7 +594,24 @@ func (sym *symtab) typeGoName(t types.Type) string {
 
 // typeIdName returns typeGoName with . -> _ -- this should always be used for id
 func (sym *symtab) typeIdName(t types.Type) string {
+	// the ids of the pointers, slices, arrays and maps of instantiations are
+	// made of the ids of the instantiations, named after their python classes
+	if hasInstance(t) {
+		switch t := t.(type) {
+		case *types.Pointer:
+			return "Ptr_" + sym.typeIdName(t.Elem())
+		case *types.Slice:
+			return "Slice_" + sym.typeIdName(t.Elem())
+		case *types.Array:
+			return fmt.Sprintf("Array_%d_%s", t.Len(), sym.typeIdName(t.Elem()))
+		case *types.Map:
+			return "Map_" + sym.typeIdName(t.Key()) + "_" + sym.typeIdName(t.Elem())
+		}
+	}
 	idn := strings.Replace(sym.typeGoName(t), ".", "_", -1)
+	idn = strings.Replace(idn, "<-chan ", "RecvChan_", -1)
+	idn = strings.Replace(idn, "chan<- ", "SendChan_", -1)
+	idn = strings.Replace(idn, "chan ", "Chan_", -1)
 	if _, isary := t.(*types.Array); isary {
 		idn = strings.Replace(idn, "[", "Array_", 1)
 		idn = strings.Replace(idn, "]", "_", 1)
@@ -504,8 +620,12 @@ func (sym *symtab) typeIdName(t types.Type) string {
 	idn = strings.Replace(idn, "map[", "Map_", -1)
 	idn = strings.Replace(idn, "[", "_", -1)
 	idn = strings.Replace(idn, "]", "_", -1)
+	idn = strings.Replace(idn, ", ", "_", -1)
 	idn = strings.Replace(idn, "{}", "_", -1)
 	idn = strings.Replace(idn, "*", "Ptr_", -1)
+	if isInstance(t) {
+		idn = strings.TrimRight(idn, "_") // no trailing _ of the type args
+	}
 	return idn
 }
 
@@ -569,6 +689,7 @@ func (sym *symtab) addSymbol(obj types.Object) error {
 			}
 			return sym.processTuple(sig.Results())
 		}
+		statDiag(skipIncompatFunc, qualName(obj), obj, errDetail(err))
 		if !NoWarn {
 			fmt.Printf("ignoring python incompatible function: %v.%v: %v: %v\n", pkgnm, obj.String(), sig.String(), err)
 		}
@@ -641,7 +762,7 @@ func (sym *symtab) buildTuple(tuple *types.Tuple, varnm string, methvar string)
 	for i := 0; i < sz; i++ {
 		v := tuple.At(i)
 		typ := v.Type()
-		anm := pySafeArg(v.Name(), i)
+		anm := goSafeArg(v.Name(), i)
 		vsym := sym.symtype(typ)
 		if vsym == nil {
 			err := sym.addType(v, typ)
@@ -659,8 +780,14 @@ func (sym *symtab) buildTuple(tuple *types.Tuple, varnm string, methvar string)
 		switch {
 		case vsym.goname == "interface{}":
 			bstr += fmt.Sprintf("C.PyTuple_SetItem(%s, %d, C.gopy_build_string(%s(%s)%s))\n", varnm, i, vsym.go2py, anm, vsym.go2pyParenEx)
-		case vsym.hasHandle(): // note: assuming int64 handles
+		case vsym.hasHandle() && curHandle.py == "char*":
+			bstr += fmt.Sprintf("C.PyTuple_SetItem(%s, %d, C.gopy_build_string(%s(%s)%s))\n", varnm, i, vsym.go2py, anm, vsym.go2pyParenEx)
+		case vsym.hasHandle():
 			bstr += fmt.Sprintf("C.PyTuple_SetItem(%s, %d, C.gopy_build_int64(C.int64_t(%s(%s)%s)))\n", varnm, i, vsym.go2py, anm, vsym.go2pyParenEx)
+		case vsym.isConverted(): // note: PyTuple_SetItem steals the new reference
+			bstr += fmt.Sprintf("C.PyTuple_SetItem(%s, %d, %s(%s))\n", varnm, i, vsym.go2py, anm)
+		case isRune(vsym):
+			bstr += fmt.Sprintf("C.PyTuple_SetItem(%s, %d, runeGoToPy(rune(%s)))\n", varnm, i, anm)
 		case isb:
 			bk := bt.Kind()
 			switch {
@@ -670,6 +797,8 @@ func (sym *symtab) buildTuple(tuple *types.Tuple, varnm string, methvar string)
 				bstr += fmt.Sprintf("C.PyTuple_SetItem(%s, %d, C.gopy_build_uint64(C.uint64_t(%s)))\n", varnm, i, anm)
 			case types.Float32 <= bk && bk <= types.Float64:
 				bstr += fmt.Sprintf("C.PyTuple_SetItem(%s, %d, C.gopy_build_float64(C.double(%s)))\n", varnm, i, anm)
+			case bk == types.Complex64 || bk == types.Complex128:
+				bstr += fmt.Sprintf("C.PyTuple_SetItem(%s, %d, complex128GoToPy(complex128(%s)))\n", varnm, i, anm)
 			case bk == types.String:
 				bstr += fmt.Sprintf("C.PyTuple_SetItem(%s, %d, C.gopy_build_string(C.CString(%s)))\n", varnm, i, anm)
 			case bk == types.Bool:
@@ -691,6 +820,10 @@ func (sym *symtab) pyObjectToGo(typ types.Type, sy *symbol, objnm string) (strin
 	// 	bstr += fmt.Sprintf("C.PyTuple_SetItem(%s, %d, C.gopy_build_string(%s(%s)%s))\n", varnm, i, vsym.go2py, anm, vsym.go2pyParenEx)
 	// case vsym.hasHandle(): // note: assuming int64 handles
 	// 	bstr += fmt.Sprintf("C.PyTuple_SetItem(%s, %d, C.gopy_build_int64(C.int64_t(%s(%s)%s)))\n", varnm, i, vsym.go2py, anm, vsym.go2pyParenEx)
+	case sy.isConverted():
+		bstr += fmt.Sprintf("%s(%s)", sy.py2go, objnm)
+	case isRune(sy):
+		bstr += fmt.Sprintf("%s(runePyToGo(%s))", sym.typeGoName(typ), objnm)
 	case isb:
 		bk := bt.Kind()
 		switch {
@@ -700,6 +833,8 @@ func (sym *symtab) pyObjectToGo(typ types.Type, sy *symbol, objnm string) (strin
 			bstr += fmt.Sprintf("%s(C.PyLong_AsUnsignedLongLong(%s))", sy.goname, objnm)
 		case types.Float32 <= bk && bk <= types.Float64:
 			bstr += fmt.Sprintf("%s(C.PyFloat_AsDouble(%s))", sy.goname, objnm)
+		case bk == types.Complex64 || bk == types.Complex128:
+			bstr += fmt.Sprintf("%s(complex128PyToGo(%s))", sy.goname, objnm)
 		case bk == types.String:
 			bstr += fmt.Sprintf("C.GoString(C.PyBytes_AsString(%s))", objnm)
 		case bk == types.Bool:
@@ -720,11 +855,17 @@ func (sym *symtab) ZeroToGo(typ types.Type, sy *symbol) (string, error) {
 	// 	bstr += fmt.Sprintf("C.PyTuple_SetItem(%s, %d, C.gopy_build_string(%s(%s)%s))\n", varnm, i, vsym.go2py, anm, vsym.go2pyParenEx)
 	// case vsym.hasHandle(): // note: assuming int64 handles
 	// 	bstr += fmt.Sprintf("C.PyTuple_SetItem(%s, %d, C.gopy_build_int64(C.int64_t(%s(%s)%s)))\n", varnm, i, vsym.go2py, anm, vsym.go2pyParenEx)
+	case sy.isConverted():
+		bstr += sy.zval
+	case isRune(sy):
+		bstr += "0"
 	case isb:
 		bk := bt.Kind()
 		switch {
 		case types.Int <= bk && bk <= types.Float64:
 			bstr += fmt.Sprintf("%s(0)%s", sy.py2go, sy.py2goParenEx)
+		case bk == types.Complex64 || bk == types.Complex128:
+			bstr += sy.zval
 		case bk == types.String:
 			bstr += `C.GoString(nil)`
 		case bk == types.Bool:
@@ -790,12 +931,18 @@ func (sym *symtab) addType(obj types.Object, t types.Type) error {
 		}
 
 	case *types.Pointer:
+		if tc := findTypeConverter(fn); tc != nil {
+			return sym.addConvertedType(pkg, obj, t, kind, id, n, tc)
+		}
 		return sym.addPointerType(pkg, obj, t, kind, id, n)
 
 	case *types.Array:
 		return sym.addArrayType(pkg, obj, t, kind, id, n)
 
 	case *types.Slice:
+		if tc := findTypeConverter(fn); tc != nil {
+			return sym.addConvertedType(pkg, obj, t, kind, id, n, tc)
+		}
 		return sym.addSliceType(pkg, obj, t, kind, id, n)
 
 	case *types.Map:
@@ -808,11 +955,17 @@ func (sym *symtab) addType(obj types.Object, t types.Type) error {
 		return sym.addInterfaceType(pkg, obj, t, kind, id, n)
 
 	case *types.Chan:
-		return fmt.Errorf("gopy: channel type not supported: %s\n", n)
+		return sym.addChanType(pkg, obj, t, kind, id, n)
 
 	case *types.Named:
-		if !typ.Obj().Exported() {
-			return fmt.Errorf("gopy: non-exported named type: %s\n", n)
+		if tc := findTypeConverter(fn); tc != nil {
+			return sym.addConvertedType(pkg, obj, t, kind, id, n, tc)
+		}
+		if isIterSeq(typ) {
+			return sym.addIterType(pkg, obj, t, kind, id, n)
+		}
+		if why := unboundType(typ); why != "" {
+			return fmt.Errorf("gopy: %s", why)
 		}
 		kind |= skNamed
 		var err error
@@ -838,6 +991,11 @@ func (sym *symtab) addType(obj types.Object, t types.Type) error {
 				go2pyParEx = ")"
 			}
 
+			pysig := styp.pysig
+			if NoTimedelta && fn == "time.Duration" {
+				pysig = "Duration" // see gen_duration.go
+			}
+
 			sym.syms[fn] = &symbol{
 				gopkg:        pkg,
 				goobj:        obj,
@@ -847,7 +1005,7 @@ func (sym *symtab) addType(obj types.Object, t types.Type) error {
 				goname:       styp.goname,
 				cgoname:      styp.cgoname,
 				cpyname:      styp.cpyname,
-				pysig:        styp.pysig,
+				pysig:        pysig,
 				go2py:        go2py,
 				go2pyParenEx: go2pyParEx,
 				py2go:        py2go,
@@ -874,7 +1032,7 @@ func (sym *symtab) addType(obj types.Object, t types.Type) error {
 			err = sym.addInterfaceType(pkg, obj, t, kind, id, n)
 
 		case *types.Chan:
-			err = fmt.Errorf("gopy: channel type not supported: %s", n)
+			err = sym.addChanType(pkg, obj, t, kind, id, n)
 
 		default:
 			err = fmt.Errorf("unhandled named-type: [%T]\n%#v\n", obj, t)
@@ -905,15 +1063,8 @@ func (sym *symtab) addArrayType(pkg *types.Package, obj types.Object, t types.Ty
 	fn := sym.fullTypeString(t)
 	typ := t.Underlying().(*types.Array)
 	kind |= skArray
-	elt := typ.Elem()
-	elsym, err := sym.addTypeIfNew(elt)
-	if err != nil {
-		return err
-	}
-	if elsym.isSignature() {
-		return fmt.Errorf("gopy: array value type cannot be signature / func: %q", elsym.goname)
-	}
-	sym.syms[fn] = &symbol{
+	// add our type first before adding the elements -- prevents loops!
+	s := &symbol{
 		gopkg:   pkg,
 		goobj:   obj,
 		gotyp:   t,
@@ -922,11 +1073,22 @@ func (sym *symtab) addArrayType(pkg *types.Package, obj types.Object, t types.Ty
 		goname:  n,
 		cgoname: "CGoHandle", // handles
 		cpyname: PyHandle,
-		pysig:   "[]" + elsym.pysig,
+		pysig:   "[]object",
 		go2py:   "handleFromPtr_" + id,
 		py2go:   "deptrFromHandle_" + id,
 		zval:    "nil",
 	}
+	sym.syms[fn] = s
+	elt := typ.Elem()
+	elsym, err := sym.addTypeIfNew(elt)
+	if err == nil && elsym.isSignature() {
+		err = fmt.Errorf("gopy: array value type cannot be signature / func: %q", elsym.goname)
+	}
+	if err != nil {
+		delete(sym.syms, fn)
+		return err
+	}
+	s.pysig = "[]" + elsym.pysig
 	return nil
 }
 
@@ -934,21 +1096,7 @@ func (sym *symtab) addMapType(pkg *types.Package, obj types.Object, t types.Type
 	fn := sym.fullTypeString(t)
 	typ := t.Underlying().(*types.Map)
 	kind |= skMap
-	elt := typ.Elem()
-	elsym, err := sym.addTypeIfNew(elt)
-	if err != nil {
-		return err
-	}
-	if elsym.isSignature() {
-		return fmt.Errorf("gopy: map value type cannot be signature / func: %q", elsym.goname)
-	}
-	// add type for keys method
-	keyt := typ.Key()
-	keyslt := types.NewSlice(keyt)
-	_, err = sym.addTypeIfNew(keyslt)
-	if err != nil {
-		return err
-	}
+	// add our type first before adding the elements -- prevents loops!
 	sym.syms[fn] = &symbol{
 		gopkg:   pkg,
 		goobj:   obj,
@@ -963,6 +1111,21 @@ func (sym *symtab) addMapType(pkg *types.Package, obj types.Object, t types.Type
 		py2go:   "deptrFromHandle_" + id,
 		zval:    "nil",
 	}
+	elt := typ.Elem()
+	elsym, err := sym.addTypeIfNew(elt)
+	if err == nil && elsym.isSignature() {
+		err = fmt.Errorf("gopy: map value type cannot be signature / func: %q", elsym.goname)
+	}
+	if err == nil {
+		// add type for keys method
+		keyt := typ.Key()
+		keyslt := types.NewSlice(keyt)
+		_, err = sym.addTypeIfNew(keyslt)
+	}
+	if err != nil {
+		delete(sym.syms, fn)
+		return err
+	}
 	return nil
 }
 
@@ -970,15 +1133,8 @@ func (sym *symtab) addSliceType(pkg *types.Package, obj types.Object, t types.Ty
 	fn := sym.fullTypeString(t)
 	typ := t.Underlying().(*types.Slice)
 	kind |= skSlice
-	elt := typ.Elem()
-	elsym, err := sym.addTypeIfNew(elt)
-	if err != nil {
-		return err
-	}
-	if elsym.isSignature() {
-		return fmt.Errorf("gopy: slice value type cannot be signature / func: %q", elsym.goname)
-	}
-	sym.syms[fn] = &symbol{
+	// add our type first before adding the elements -- prevents loops!
+	s := &symbol{
 		gopkg:   pkg,
 		goobj:   obj,
 		gotyp:   t,
@@ -987,11 +1143,22 @@ func (sym *symtab) addSliceType(pkg *types.Package, obj types.Object, t types.Ty
 		goname:  n,
 		cgoname: "CGoHandle",
 		cpyname: PyHandle,
-		pysig:   "[]" + elsym.pysig,
+		pysig:   "[]object",
 		go2py:   "handleFromPtr_" + id,
 		py2go:   "deptrFromHandle_" + id,
 		zval:    "nil",
 	}
+	sym.syms[fn] = s
+	elt := typ.Elem()
+	elsym, err := sym.addTypeIfNew(elt)
+	if err == nil && elsym.isSignature() {
+		err = fmt.Errorf("gopy: slice value type cannot be signature / func: %q", elsym.goname)
+	}
+	if err != nil {
+		delete(sym.syms, fn)
+		return err
+	}
+	s.pysig = "[]" + elsym.pysig
 	return nil
 }
 
@@ -1000,26 +1167,30 @@ func (sym *symtab) addStructType(pkg *types.Package, obj types.Object, t types.T
 	typ := t.Underlying().(*types.Struct)
 	kind |= skStruct
 	// add our type first before adding fields -- prevents loops!
-	sym.syms[fn] = &symbol{
-		gopkg:   pkg,
-		goobj:   obj,
-		gotyp:   t,
-		kind:    kind,
-		id:      id,
-		goname:  n,
-		cgoname: "CGoHandle",
-		cpyname: PyHandle,
-		pysig:   "object",
-		go2py:   "handleFromPtr_" + id,
-		py2go:   "*ptrFromHandle_" + id,
-		zval:    "nil",
+	if sym.dataclassStruct(t, make(map[*types.Named]bool)) {
+		sym.addDataclassType(pkg, obj, t, kind, id, n)
+	} else {
+		sym.syms[fn] = &symbol{
+			gopkg:   pkg,
+			goobj:   obj,
+			gotyp:   t,
+			kind:    kind,
+			id:      id,
+			goname:  n,
+			cgoname: "CGoHandle",
+			cpyname: PyHandle,
+			pysig:   "object",
+			go2py:   "handleFromPtr_" + id,
+			py2go:   "*ptrFromHandle_" + id,
+			zval:    "nil",
+		}
 	}
 	for i := 0; i < typ.NumFields(); i++ {
 		if isPrivate(typ.Field(i).Name()) {
 			continue
 		}
 		f := typ.Field(i)
-		if !f.Exported() || f.Embedded() {
+		if !f.Exported() || f.Embedded() || isSkipped(f) {
 			continue
 		}
 		ftyp := f.Type()
@@ -1069,7 +1240,7 @@ func (sym *symtab) addSignatureType(pkg *types.Package, obj types.Object, t type
 		for i := 0; i < nargs; i++ {
 			v := args.At(i)
 			typ := v.Type()
-			anm := pySafeArg(v.Name(), i)
+			anm := goSafeArg(v.Name(), i)
 			if i > 0 {
 				nsig += ", "
 			}
@@ -1137,6 +1308,7 @@ func (sym *symtab) addMethod(pkg *types.Package, obj types.Object, t types.Type,
 	sig := t.Underlying().(*types.Signature)
 	_, _, _, err := isPyCompatFunc(sig)
 	if err != nil {
+		statDiag(skipIncompatFunc, qualName(obj), obj, errDetail(err))
 		if !NoWarn {
 			fmt.Printf("ignoring python incompatible method: %v.%v: %v: %v\n", pkg.Name(), obj.String(), t.String(), err)
 		}
@@ -1164,6 +1336,9 @@ func (sym *symtab) addPointerType(pkg *types.Package, obj types.Object, t types.
 	fn := sym.fullTypeString(t)
 	typ := t.Underlying().(*types.Pointer)
 	etyp := typ.Elem()
+	if types.Identical(etyp, t) {
+		return fmt.Errorf("gopy: recursive pointer type not supported: %s", n)
+	}
 	esym := sym.symtype(etyp)
 	if esym == nil {
 		sym.addType(obj, etyp)
@@ -1173,11 +1348,21 @@ func (sym *symtab) addPointerType(pkg *types.Package, obj types.Object, t types.
 		}
 	}
 
+	if isDataclass(esym) {
+		sym.addDataclassPtrType(pkg, obj, t, kind, id, n, esym)
+		return nil
+	}
+
+	ekind := esym.kind
+	if esym.isConverted() {
+		ekind = skType // pointers to converted types are still handles
+	}
+
 	sym.syms[fn] = &symbol{
 		gopkg:   pkg,
 		goobj:   obj,
 		gotyp:   t,
-		kind:    esym.kind | skPointer,
+		kind:    ekind | skPointer,
 		id:      id,
 		goname:  n,
 		cgoname: "CGoHandle", // handles
@@ -1243,6 +1428,12 @@ func (sym *symtab) print() {
 }
 
 func init() {
+	initUniverse()
+}
+
+// initUniverse initializes the universe and current symbol tables, again
+// when the handle type changes, as the symbols have the types of the handles
+func initUniverse() {
 
 	universe = newSymtab(nil, nil)
 	universe.parent = nil
@@ -6,7 +6,9 @@ package bind
 
 import (
 	"fmt"
+	"go/constant"
 	"go/types"
+	"math"
 	"sort"
 	"strconv"
 )
@@ -194,6 +196,59 @@ func (it *Interface) Interface() *types.Interface {
 	return it.sym.GoType().Underlying().(*types.Interface)
 }
 
+// Embeds returns the symbols for the interfaces embedded in the interface
+// that have python classes in the bound packages, which are the base classes
+// of its python class.  Interfaces already embedded in another one of them
+// are left out, to keep the python class hierarchy consistent.
+func (it *Interface) Embeds() []*symbol {
+	ityp := it.Interface()
+	var embs []types.Type
+	for i := 0; i < ityp.NumEmbeddeds(); i++ {
+		et := ityp.EmbeddedType(i)
+		if _, ok := et.(*types.Named); !ok {
+			continue
+		}
+		esym := current.symtype(et)
+		if esym == nil || !esym.isInterface() || esym.gopkg == nil {
+			continue
+		}
+		if _, has := thePyGen.pkgmap[esym.gopkg.Path()]; !has {
+			continue
+		}
+		embs = append(embs, et)
+	}
+	var syms []*symbol
+	for _, et := range embs {
+		redundant := false
+		for _, ot := range embs {
+			if ot != et && embedsIface(ot, et) {
+				redundant = true
+				break
+			}
+		}
+		if !redundant {
+			syms = append(syms, current.symtype(et))
+		}
+	}
+	return syms
+}
+
+// embedsIface returns true if interface type t embeds interface type e,
+// directly or indirectly
+func embedsIface(t, e types.Type) bool {
+	ityp, ok := t.Underlying().(*types.Interface)
+	if !ok {
+		return false
+	}
+	for i := 0; i < ityp.NumEmbeddeds(); i++ {
+		et := ityp.EmbeddedType(i)
+		if types.Identical(et, e) || embedsIface(et, e) {
+			return true
+		}
+	}
+	return false
+}
+
 ///////////////////////////////////////////////////////////////////////////////////
 //  Slice
 
@@ -369,22 +424,31 @@ type Func struct {
 
 	id         string
 	doc        string
-	ret        types.Type // return type, if any
-	err        bool       // true if original go func has comma-error
-	ctor       bool       // true if this is a newXXX function
-	hasfun     bool       // true if this function has a function argument
-	isVariadic bool       // True, if this is a variadic function.
+	ret        types.Type   // return type, if any
+	err        bool         // true if original go func has comma-error
+	commaOk    string       // policy of the comma-ok bool result, if any, see gen_commaok.go
+	ctor       bool         // true if this is a newXXX function
+	hasfun     bool         // true if this function has a function argument
+	isVariadic bool         // True, if this is a variadic function.
+	pyname     string       // python name set by a name directive, if any
+	borrow     map[int]bool // indexes of the borrowed string params, see gen_borrow.go
+	checks     []argCheck   // checks of the args, in python, see gen_checks.go
 }
 
 func newFuncFrom(p *Package, parent string, obj types.Object, sig *types.Signature) (*Func, error) {
+	if isSkipped(obj) {
+		return nil, fmt.Errorf("gopy: %s is skipped", obj.Name())
+	}
 	ret, haserr, hasfun, err := isPyCompatFunc(sig)
 	if err != nil {
 		return nil, err
 	}
 
 	id := obj.Pkg().Name() + "_" + obj.Name()
+	qname := obj.Name()
 	if parent != "" {
 		id = obj.Pkg().Name() + "_" + parent + "_" + obj.Name()
+		qname = parent + "." + obj.Name()
 	}
 
 	sv, err := newSignatureFrom(p, sig)
@@ -402,8 +466,12 @@ func newFuncFrom(p *Package, parent string, obj types.Object, sig *types.Signatu
 		doc:        p.getDoc(parent, obj),
 		ret:        ret,
 		err:        haserr,
+		commaOk:    p.commaOkPolicy(qname, sig),
 		hasfun:     hasfun,
 		isVariadic: sig.Variadic(),
+		pyname:     p.pyNameDirective(qname),
+		borrow:     p.borrowedArgs(qname, sig),
+		checks:     p.argChecks(qname, sig),
 	}, nil
 
 	// TODO: could optimize by generating code once for each type of callback
@@ -445,6 +513,9 @@ func (f *Func) GoName() string {
 }
 
 func (f *Func) GoFmt() string {
+	if inst, ok := f.pkg.insts[f.name]; ok && inst.obj == f.obj {
+		return inst.goname
+	}
 	return f.pkg.Name() + "." + f.name
 }
 
@@ -543,6 +614,45 @@ func (e *Enum) SortConsts() {
 	})
 }
 
+// IsFlags returns whether the consts of the enum are bit flags, bound as an
+// enum.IntFlag: non-negative, each zero, a power of two, or an OR of the
+// powers of two of the enum, and declared with << or |, e.g., 1 << iota, or
+// Read, Write and ReadWrite = Read | Write, or else not a contiguous range,
+// as the enums of iota are.
+func (e *Enum) IsFlags() bool {
+	if bt, ok := e.typ.Underlying().(*types.Basic); !ok || bt.Info()&types.IsInteger == 0 {
+		return false
+	}
+	vals := make(map[uint64]bool)
+	var bits uint64
+	for _, c := range e.items {
+		v, exact := constant.Uint64Val(constant.ToInt(c.obj.Val()))
+		if !exact {
+			return false
+		}
+		vals[v] = true
+		if v&(v-1) == 0 {
+			bits |= v
+		}
+	}
+	if len(vals) < 2 {
+		return false
+	}
+	var min, max uint64 = math.MaxUint64, 0
+	for v := range vals {
+		if v&^bits != 0 {
+			return false
+		}
+		if v < min {
+			min = v
+		}
+		if v > max {
+			max = v
+		}
+	}
+	return max-min+1 != uint64(len(vals)) || e.pkg.hasBitConsts(e.typ.Obj().Name())
+}
+
 ///////////////////////////////////////////////////////////////////////////////////
 //  Var
 
@@ -627,3 +737,60 @@ func (v *Var) isGoString() bool {
 	}
 	return false
 }
+
+///////////////////////////////////////////////////////////////////////////////////
+//  Error
+
+// Error is a sentinel error var or an error type of the package,
+// which is raised as its own python exception class.
+type Error struct {
+	pkg *Package
+	obj types.Object // *types.Var for sentinel errors, *types.TypeName for error types
+	ptr bool         // only the pointer to the error type implements error
+	doc string
+}
+
+// newError returns an Error if obj is a sentinel error var
+// or an error type, and nil otherwise.
+func newError(p *Package, obj types.Object) *Error {
+	switch obj := obj.(type) {
+	case *types.Var:
+		if isErrorType(obj.Type()) {
+			return &Error{pkg: p, obj: obj, doc: p.getDoc("", obj)}
+		}
+	case *types.TypeName:
+		ntyp, ok := obj.Type().(*types.Named)
+		if !ok || obj.IsAlias() || ntyp.TypeParams().Len() > 0 {
+			return nil
+		}
+		eifc := types.Universe.Lookup("error").Type().Underlying().(*types.Interface)
+		switch {
+		case types.Implements(ntyp, eifc):
+			return &Error{pkg: p, obj: obj, doc: p.getDoc("", obj)}
+		case types.Implements(types.NewPointer(ntyp), eifc):
+			return &Error{pkg: p, obj: obj, ptr: true, doc: p.getDoc("", obj)}
+		}
+	}
+	return nil
+}
+
+func (e *Error) Name() string {
+	return e.obj.Name()
+}
+
+// ID returns the unique name used to register the python exception class
+func (e *Error) ID() string {
+	return e.pkg.pkg.Path() + "." + e.obj.Name()
+}
+
+// PyName returns the name of the python exception class
+func (e *Error) PyName() string {
+	return e.obj.Name() + "Exception"
+}
+
+// isSentinel returns true for a sentinel error var, matched with errors.Is,
+// and false for an error type, matched with errors.As
+func (e *Error) isSentinel() bool {
+	_, ok := e.obj.(*types.Var)
+	return ok
+}
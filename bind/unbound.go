@@ -0,0 +1,95 @@
+// Copyright 2024 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package bind
+
+import (
+	"fmt"
+	"go/types"
+	"path/filepath"
+	"strings"
+)
+
+// The generated cgo code can only refer to the types of the bound API that
+// have a name it can use: functions, methods, fields and variables using
+// anonymous struct types, unexported types, or types of internal packages
+// that the generated code cannot import, are skipped with a warning.
+
+var (
+	// InternalRoots maps the paths of the internal packages imported by the
+	// bound packages to the directories of the trees allowed to import them,
+	// e.g., the directory of a/b for a/b/internal/c -- this must be a global
+	// as it is relevant during initial package parsing, like NoWarn.
+	InternalRoots = map[string]string{}
+
+	// ImportDir is the directory of the generated code, which can only
+	// import the internal packages of the trees it is in.
+	ImportDir string
+)
+
+// isInternalPkg returns true if the import path has an internal element
+func isInternalPkg(path string) bool {
+	for _, el := range strings.Split(path, "/") {
+		if el == "internal" {
+			return true
+		}
+	}
+	return false
+}
+
+// isImportable returns true if the generated code can import the package
+func isImportable(path string) bool {
+	if !isInternalPkg(path) {
+		return true
+	}
+	root, ok := InternalRoots[path]
+	if !ok {
+		return false
+	}
+	dir, err := filepath.Abs(ImportDir)
+	if err != nil {
+		return false
+	}
+	rel, err := filepath.Rel(root, dir)
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
+
+// unboundType returns why the type, or a type it uses through pointers,
+// containers and type args, cannot be referred to by the generated code,
+// or "" if it can
+func unboundType(typ types.Type) string {
+	switch t := typ.(type) {
+	case *types.Named:
+		obj := t.Obj()
+		if obj.Pkg() == nil {
+			return ""
+		}
+		if !obj.Exported() {
+			return fmt.Sprintf("type %s.%s is not exported", obj.Pkg().Name(), obj.Name())
+		}
+		if !isImportable(obj.Pkg().Path()) {
+			return fmt.Sprintf("type %s.%s is in internal package %s, which the generated code cannot import", obj.Pkg().Name(), obj.Name(), obj.Pkg().Path())
+		}
+		targs := t.TypeArgs()
+		for i := 0; i < targs.Len(); i++ {
+			if why := unboundType(targs.At(i)); why != "" {
+				return why
+			}
+		}
+	case *types.Struct:
+		return "anonymous struct types are not supported, use a named type"
+	case *types.Pointer:
+		return unboundType(t.Elem())
+	case *types.Slice:
+		return unboundType(t.Elem())
+	case *types.Array:
+		return unboundType(t.Elem())
+	case *types.Map:
+		if why := unboundType(t.Key()); why != "" {
+			return why
+		}
+		return unboundType(t.Elem())
+	}
+	return ""
+}
@@ -14,6 +14,7 @@ import (
 	"path/filepath"
 	"reflect"
 	"regexp"
+	"sort"
 	"strconv"
 	"strings"
 
@@ -81,9 +82,25 @@ func hasError(sig *types.Signature) bool {
 	}
 }
 
-func isConstructor(sig *types.Signature) bool {
-	//TODO(sbinet)
-	return false
+// isConstructor returns true if the function signature returns
+// either a value of type typ or a pointer to it, optionally along
+// with a trailing error.
+func isConstructor(sig *types.Signature, typ types.Type) bool {
+	res := sig.Results()
+	switch res.Len() {
+	case 1:
+	case 2:
+		if !isErrorType(res.At(1).Type()) {
+			return false
+		}
+	default:
+		return false
+	}
+	ret := res.At(0).Type()
+	if ptr, ok := ret.(*types.Pointer); ok {
+		ret = ptr.Elem()
+	}
+	return types.Identical(ret, typ)
 }
 
 type PyConfig struct {
@@ -166,22 +183,30 @@ else:
 		return cfg, errors.Wrap(err, "could not run python-config script")
 	}
 
-	var raw struct {
-		Version   int    `json:"version"`
-		Minor     int    `json:"minor"`
-		IncDir    string `json:"incdir"`
-		LibDir    string `json:"libdir"`
-		LibPy     string `json:"libpy"`
-		ShLibs    string `json:"shlibs"`
-		SysLibs   string `json:"syslibs"`
-		ExtSuffix string `json:"extsuffix"`
-		ShFlags   string `json:"shflags"`
-	}
+	var raw pyConfigRaw
 	err = json.NewDecoder(buf).Decode(&raw)
 	if err != nil {
 		return cfg, errors.Wrapf(err, "could not decode JSON script output")
 	}
+	return raw.config(), nil
+}
+
+// pyConfigRaw is the python configuration output by the python-config script
+type pyConfigRaw struct {
+	Version   int    `json:"version"`
+	Minor     int    `json:"minor"`
+	IncDir    string `json:"incdir"`
+	LibDir    string `json:"libdir"`
+	LibPy     string `json:"libpy"`
+	ShLibs    string `json:"shlibs"`
+	SysLibs   string `json:"syslibs"`
+	ExtSuffix string `json:"extsuffix"`
+	ShFlags   string `json:"shflags"`
+}
 
+// config returns the PyConfig of the raw configuration
+func (raw pyConfigRaw) config() PyConfig {
+	var cfg PyConfig
 	raw.IncDir = filepath.ToSlash(raw.IncDir)
 	raw.LibDir = filepath.ToSlash(raw.LibDir)
 
@@ -213,8 +238,7 @@ else:
 		raw.SysLibs,
 	}, " ")
 	cfg.LdDynamicFlags = raw.ShFlags
-
-	return cfg, nil
+	return cfg
 }
 
 func getGoVersion(version string) (int64, int64, error) {
@@ -296,6 +320,17 @@ var (
 	rxMatchAllCap   = regexp.MustCompile("([a-z0-9])([A-Z])")
 )
 
+// sortedNames returns the keys of the map, sorted, to iterate over it in an
+// order not changing from a run to the next
+func sortedNames[T any](m map[string]T) []string {
+	names := make([]string, 0, len(m))
+	for nm := range m {
+		names = append(names, nm)
+	}
+	sort.Strings(names)
+	return names
+}
+
 // toSnakeCase converts the provided string to snake_case.
 // Based on https://gist.github.com/stoewer/fbe273b711e6a06315d19552dd4d33e6
 func toSnakeCase(input string) string {
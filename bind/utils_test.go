@@ -6,6 +6,10 @@ package bind
 
 import (
 	"errors"
+	"go/token"
+	"go/types"
+	"os"
+	"path/filepath"
 	"testing"
 )
 
@@ -95,3 +99,157 @@ func TestPythonConfig(t *testing.T) {
 		})
 	}
 }
+
+func TestIsConstructor(t *testing.T) {
+	pkg := types.NewPackage("example.com/p", "p")
+	foo := types.NewNamed(types.NewTypeName(token.NoPos, pkg, "Foo", nil), types.NewStruct(nil, nil), nil)
+	bar := types.NewNamed(types.NewTypeName(token.NoPos, pkg, "Bar", nil), types.NewStruct(nil, nil), nil)
+	errt := types.Universe.Lookup("error").Type()
+
+	sig := func(res ...types.Type) *types.Signature {
+		var vars []*types.Var
+		for _, r := range res {
+			vars = append(vars, types.NewVar(token.NoPos, pkg, "", r))
+		}
+		return types.NewSignatureType(nil, nil, nil, nil, types.NewTuple(vars...), false)
+	}
+
+	for _, tt := range []struct {
+		name string
+		sig  *types.Signature
+		want bool
+	}{
+		{"value", sig(foo), true},
+		{"pointer", sig(types.NewPointer(foo)), true},
+		{"value-error", sig(foo, errt), true},
+		{"pointer-error", sig(types.NewPointer(foo), errt), true},
+		{"other", sig(types.NewPointer(bar)), false},
+		{"pointer-pointer", sig(types.NewPointer(types.NewPointer(foo))), false},
+		{"no-error", sig(foo, foo), false},
+		{"none", sig(), false},
+	} {
+		if got := isConstructor(tt.sig, foo); got != tt.want {
+			t.Errorf("isConstructor(%s): expected %v, actual %v", tt.name, tt.want, got)
+		}
+	}
+}
+
+func TestPyTopLevelNames(t *testing.T) {
+	code := []byte(`
+from enum import Enum
+
+class Kind(Enum):
+	A = 0
+Answer = 42
+def Hello(name):
+	x = 1
+	return x
+def _private():
+	pass
+_pkg.GoPyRegisterError("E", E)
+if Answer == 42:
+	pass
+Hello = None
+`)
+	want := []string{"Kind", "Answer", "Hello"}
+	got := pyTopLevelNames(code)
+	if len(got) != len(want) {
+		t.Fatalf("pyTopLevelNames: expected %v, actual %v", want, got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("pyTopLevelNames: expected %v, actual %v", want, got)
+			break
+		}
+	}
+}
+
+func TestImplementsIO(t *testing.T) {
+	pkg := types.NewPackage("example.com/p", "p")
+	newNamed := func(name string) *types.Named {
+		return types.NewNamed(types.NewTypeName(token.NoPos, pkg, name, nil), types.NewStruct(nil, nil), nil)
+	}
+	addRead := func(named *types.Named, ptr bool) {
+		var recv types.Type = named
+		if ptr {
+			recv = types.NewPointer(named)
+		}
+		sig := ioReader.Method(0).Type().(*types.Signature)
+		sig = types.NewSignatureType(types.NewVar(token.NoPos, pkg, "r", recv), nil, nil, sig.Params(), sig.Results(), false)
+		named.AddMethod(types.NewFunc(token.NoPos, pkg, "Read", sig))
+	}
+	val, ptr, none := newNamed("Val"), newNamed("Ptr"), newNamed("None")
+	addRead(val, false)
+	addRead(ptr, true)
+
+	for _, tt := range []struct {
+		name string
+		typ  types.Type
+		want bool
+	}{
+		{"value", val, true},
+		{"pointer-receiver", ptr, true},
+		{"pointer", types.NewPointer(ptr), true},
+		{"none", none, false},
+		{"reader", ioReader, true},
+	} {
+		if got := implementsIO(tt.typ, ioReader); got != tt.want {
+			t.Errorf("implementsIO(%s, io.Reader): expected %v, actual %v", tt.name, tt.want, got)
+		}
+	}
+	if implementsIO(val, ioWriter) {
+		t.Errorf("implementsIO(value, io.Writer): expected false, actual true")
+	}
+}
+
+func TestPythonConfigFile(t *testing.T) {
+	for _, env := range []string{"GOPY_INCLUDE", "GOPY_LIBDIR", "GOPY_PYLIB"} {
+		t.Setenv(env, "")
+		os.Unsetenv(env)
+	}
+	fname := filepath.Join(t.TempDir(), "target.json")
+	err := os.WriteFile(fname, []byte(`{
+	"py_version_short": "3.12",
+	"INCLUDEPY": "/opt/py/include/python3.12",
+	"LIBDIR": "/opt/py/lib",
+	"LIBRARY": "libpython3.12.a",
+	"SHLIBS": "-ldl",
+	"SYSLIBS": "-lm",
+	"LDSHARED": "aarch64-linux-gnu-gcc -bundle -shared",
+	"EXT_SUFFIX": ".cpython-312-aarch64-linux-gnu.so",
+	"Py_ENABLE_SHARED": 1
+}`), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := PythonConfigFile(fname)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := PyConfig{
+		Version:        3,
+		CFlags:         `"-I/opt/py/include/python3.12"`,
+		LdFlags:        `"-L/opt/py/lib" "-lpython3.12" -ldl -lm`,
+		LdDynamicFlags: " -shared",
+		ExtSuffix:      ".cpython-312-aarch64-linux-gnu.so",
+	}
+	if got != want {
+		t.Fatalf("error:\ngot= %#v\nwant=%#v\n", got, want)
+	}
+}
+
+func TestBazelGoLabel(t *testing.T) {
+	for _, tt := range []struct {
+		path string
+		want string
+	}{
+		{"github.com/go-python/gopy/gopyh", "@com_github_go_python_gopy//gopyh"},
+		{"github.com/go-python/gopy/_examples/hi", "@com_github_go_python_gopy//_examples/hi"},
+		{"github.com/pkg/errors", "@com_github_pkg_errors//:errors"},
+		{"golang.org/x/text/unicode/norm", "@org_golang_x_text//unicode/norm"},
+	} {
+		if got := bazelGoLabel(tt.path); got != tt.want {
+			t.Errorf("bazelGoLabel(%q): expected %q, actual %q", tt.path, tt.want, got)
+		}
+	}
+}
@@ -29,6 +29,8 @@ func gopyMakeCmdExe() *commander.Command {
 		Long: `
 exe generates and compiles (C)Python language bindings for a Go package, including subdirectories, and generates a standalone python executable and associated module packaging suitable for distribution.  if setup.py file does not yet exist in the target directory, then it along with other default packaging files are created, using arguments.  Typically you create initial default versions of these files and then edit them, and after that, only regenerate the go binding files.
 
+The executable, py<name>, embeds the python interpreter along with the bindings, and is built in the package directory, which it makes importable as <name> from anywhere, so that this directory can be shipped as a runnable bundle, e.g., for a tool written in Go and scripted in Python.  When run without arguments, it runs the __main__.py of the package if there is one, and otherwise it takes the same arguments as python.
+
 The primary need for an exe instead of a pkg dynamic library is when the main thread must be used for something other than running the python interpreter, such as for a GUI library where the main thread must be used for running the GUI event loop (e.g., GoGi).
 
 When including multiple packages, list in order of increasing dependency, and use -name arg to give appropriate name.
@@ -41,13 +43,24 @@ ex:
 	}
 
 	cmd.Flag.String("vm", "python", "path to python interpreter")
+	cmd.Flag.String("python-version", "", "build against a pinned standalone python of this version (e.g., 3.12), "+
+		"downloaded as needed, instead of -vm")
 	cmd.Flag.String("output", "", "output directory for root of package")
-	cmd.Flag.String("name", "", "name of output package (otherwise name of first package is used)")
+	cmd.Flag.String("name", "", "name of output package (otherwise name of first package is used), "+
+		"which may be a dotted python package name, e.g., org.proj.mod")
 	cmd.Flag.String("main", "", "code string to run in the go main() function in the cgo library "+
 		"-- defaults to GoPyMainRun() but typically should be overriden")
 	// cmd.Flag.String("package-prefix", ".", "custom package prefix used when generating import "+
 	// 	"statements for generated package")
 	cmd.Flag.Bool("rename", false, "rename Go symbols to python PEP snake_case")
+	cmd.Flag.String("include", "", "regexp of the qualified names of the symbols to bind, e.g., 'hi\\.(Person|Add)$' "+
+		"-- methods and fields of the included types are bound too")
+	cmd.Flag.String("exclude-symbols", "", "regexp of the qualified names of the symbols, methods and fields to skip, e.g., 'hi\\.Person\\.Greet'")
+	cmd.Flag.String("frozen", "", "regexp of the qualified names of the structs immutable after construction, e.g., 'hi\\.(Point|Config)$'")
+	cmd.Flag.String("hooks", "", "txtar file of the fragments of Go and python code injected before or after the calls of "+
+		"the generated functions and methods whose qualified names match their patterns, e.g., for validation or metrics")
+	cmd.Flag.String("templates", "", "directory of the templates of the preambles of the generated go.tmpl, build.py.tmpl, py.tmpl "+
+		"and py_exe.tmpl files and of the Makefile.tmpl and Makefile_exe.tmpl overriding the builtin ones, created with them if it does not exist")
 	cmd.Flag.Bool("symbols", true, "include symbols in output")
 	cmd.Flag.String("exclude", "", "comma-separated list of package names to exclude")
 	cmd.Flag.String("user", "", "username on https://www.pypa.io/en/latest/ for package name suffix")
@@ -58,32 +71,99 @@ ex:
 	cmd.Flag.String("url", "https://github.com/go-python/gopy", "home page for project")
 	cmd.Flag.Bool("no-warn", false, "suppress warning messages, which may be expected")
 	cmd.Flag.Bool("no-make", false, "do not generate a Makefile, e.g., when called from Makefile")
+	cmd.Flag.String("stats", "", "JSON file to write the statistics of the generation to, as summarized at its end")
+	cmd.Flag.String("report", "", "file to write the coverage report of the bindings to, with the diagnostics of the symbols "+
+		"that cannot be bound, as JSON, or as HTML if ending in .html")
+	cmd.Flag.String("errors", "", "comma-separated mappings of Go errors to the python builtin exceptions they are raised as: "+
+		"pkg/path.ErrSentinel=Exception, *pkg/path.ErrorType=Exception, or ~message substring=Exception, "+
+		"e.g., io/fs.ErrNotExist=FileNotFoundError")
+	cmd.Flag.Bool("no-timedelta", false, "pass time.Duration values as go.Duration ints of nanoseconds, with Go "+
+		"arithmetic, instead of converting them to datetime.timedelta")
+	cmd.Flag.Bool("dataclass", false, "bind the structs with only exported fields of basic and such struct types, "+
+		"and no methods, as python dataclasses, copied to and from Go instead of proxied by handles")
+	cmd.Flag.Int("copy-max", 0, "maximum number of elements of the slices, arrays and maps returned as python lists and dicts, "+
+		"copied, instead of proxies of the Go values, which can be changed at run time with go.copy_max (0 proxies them all)")
+	cmd.Flag.String("comma-ok", "none", "policy of the functions returning a value and a bool, in the comma-ok idiom, "+
+		"when the bool is false: none (return None) or raise (raise KeyError), overridden by //gopy:commaok directives")
 	cmd.Flag.Bool("dynamic-link", false, "whether to link output shared library dynamically to Python")
 	cmd.Flag.String("build-tags", "", "build tags to be passed to `go build`")
+	cmd.Flag.String("goos", "", "target GOOS of cross builds, e.g., windows, with the C cross compiler of the CC env var "+
+		"or the usual one of the target (default is the host GOOS)")
+	cmd.Flag.String("goarch", "", "target GOARCH of cross builds, e.g., arm64 (default is the host GOARCH)")
+	cmd.Flag.String("py-config", "", "JSON file of the sysconfig config vars of the target python, for cross builds, "+
+		"dumped by: python3 -c \"import json, sysconfig; print(json.dumps(sysconfig.get_config_vars()))\"")
+	cmd.Flag.String("handle", "int64", "type of the handles of the Go values in python: int64 (faster) "+
+		"or string (more debuggable, naming the Go type of the value)")
+	cmd.Flag.Int("handle-shards", 1, "number of shards of the handle registry, to reduce the lock contention of "+
+		"many python threads calling into Go simultaneously: 1 for a single lock, -1 for one per CPU")
+	cmd.Flag.Bool("thread-checks", false, "raise go.ThreadError when the structs not marked //gopy:threadsafe are used from "+
+		"several python threads, for debugging")
+	cmd.Flag.Bool("borrow-checks", false, "raise a RuntimeError when the python buffers of the //gopy:borrow string params "+
+		"change during the calls, for debugging")
+	cmd.Flag.Bool("no-arg-checks", false, "do not check the args of the functions in python by the checks recognized "+
+		"in their Go code, only by their //gopy:check directives")
+	cmd.Flag.Bool("async", false, "generate an async twin of each function and method, Func_async, returning "+
+		"an asyncio future of its result, called in a goroutine")
+	cmd.Flag.String("config", "", "gopy.toml or gopy.yaml config file of the packages and options of the bindings, "+
+		"overridden by the options given explicitly (default: the gopy.toml, gopy.yaml or gopy.yml of the current dir, if any)")
+	cmd.Flag.String("profile", "", "preset of the defaults of the options shaping the python API: minimal (closest to Go), "+
+		"standard (pythonic: -rename, -dataclass, -comma-ok=raise, -errors of the standard library, -lossy-checks, -copy-max=64) "+
+		"or full (standard, -pretty, -reexport and -gen-tests), overridden by the options given explicitly")
 
 	return cmd
 }
 
 func gopyRunCmdExe(cmdr *commander.Command, args []string) error {
+	args, err := applyConfig(cmdr, args)
+	if err != nil {
+		return err
+	}
 	if len(args) == 0 {
 		err := fmt.Errorf("gopy: expect a fully qualified go package name as argument")
 		log.Println(err)
 		return err
 	}
 
+	if err := applyProfile(cmdr); err != nil {
+		return err
+	}
+
 	cfg := NewBuildCfg()
 	cfg.OutputDir = cmdr.Flag.Lookup("output").Value.Get().(string)
-	cfg.Name = cmdr.Flag.Lookup("name").Value.Get().(string)
+	if name := cmdr.Flag.Lookup("name").Value.Get().(string); name != "" {
+		if err := cfg.SetName(name); err != nil {
+			return err
+		}
+	}
 	cfg.Main = cmdr.Flag.Lookup("main").Value.Get().(string)
 	cfg.VM = cmdr.Flag.Lookup("vm").Value.Get().(string)
+	cfg.PythonVersion = cmdr.Flag.Lookup("python-version").Value.Get().(string)
 	// cfg.PkgPrefix = cmdr.Flag.Lookup("package-prefix").Value.Get().(string)
 	cfg.PkgPrefix = "" // doesn't make sense for exe
 	cfg.RenameCase = cmdr.Flag.Lookup("rename").Value.Get().(bool)
 	cfg.Symbols = cmdr.Flag.Lookup("symbols").Value.Get().(bool)
 	cfg.NoWarn = cmdr.Flag.Lookup("no-warn").Value.Get().(bool)
 	cfg.NoMake = cmdr.Flag.Lookup("no-make").Value.Get().(bool)
+	cfg.NoTimedelta = cmdr.Flag.Lookup("no-timedelta").Value.Get().(bool)
+	cfg.Dataclass = cmdr.Flag.Lookup("dataclass").Value.Get().(bool)
+	cfg.CopyMax = cmdr.Flag.Lookup("copy-max").Value.Get().(int)
+	cfg.CommaOk = cmdr.Flag.Lookup("comma-ok").Value.Get().(string)
+	cfg.ErrorMap = cmdr.Flag.Lookup("errors").Value.Get().(string)
 	cfg.DynamicLinking = cmdr.Flag.Lookup("dynamic-link").Value.Get().(bool)
 	cfg.BuildTags = cmdr.Flag.Lookup("build-tags").Value.Get().(string)
+	cfg.GOOS = cmdr.Flag.Lookup("goos").Value.Get().(string)
+	cfg.GOARCH = cmdr.Flag.Lookup("goarch").Value.Get().(string)
+	cfg.PyConfig = cmdr.Flag.Lookup("py-config").Value.Get().(string)
+	cfg.Handle = cmdr.Flag.Lookup("handle").Value.Get().(string)
+	cfg.HandleShards = cmdr.Flag.Lookup("handle-shards").Value.Get().(int)
+	cfg.ThreadChecks = cmdr.Flag.Lookup("thread-checks").Value.Get().(bool)
+	cfg.BorrowChecks = cmdr.Flag.Lookup("borrow-checks").Value.Get().(bool)
+	cfg.NoArgChecks = cmdr.Flag.Lookup("no-arg-checks").Value.Get().(bool)
+	cfg.AsyncAPI = cmdr.Flag.Lookup("async").Value.Get().(bool)
+	cfg.Hooks = statsPath(cmdr.Flag.Lookup("hooks").Value.Get().(string))
+	cfg.Templates = statsPath(cmdr.Flag.Lookup("templates").Value.Get().(string))
+	cfg.StatsFile = statsPath(cmdr.Flag.Lookup("stats").Value.Get().(string))
+	cfg.ReportFile = statsPath(cmdr.Flag.Lookup("report").Value.Get().(string))
 
 	var (
 		exclude = cmdr.Flag.Lookup("exclude").Value.Get().(string)
@@ -97,13 +177,28 @@ func gopyRunCmdExe(cmdr *commander.Command, args []string) error {
 
 	bind.NoWarn = cfg.NoWarn
 	bind.NoMake = cfg.NoMake
+	bind.NoTimedelta = cfg.NoTimedelta
+	bind.Dataclass = cfg.Dataclass
+	bind.CopyMax = cfg.CopyMax
+	if err := bind.SetCommaOk(cfg.CommaOk); err != nil {
+		return err
+	}
+	if err := bind.SetHandleType(cfg.Handle); err != nil {
+		return err
+	}
+	defer setCrossTarget(cfg)()
+	bind.ImportDir = cfg.OutputDir
+	if err := bind.SetSymbolFilter(cmdr.Flag.Lookup("include").Value.Get().(string), cmdr.Flag.Lookup("exclude-symbols").Value.Get().(string)); err != nil {
+		return err
+	}
+	if err := bind.SetFrozen(cmdr.Flag.Lookup("frozen").Value.Get().(string)); err != nil {
+		return err
+	}
 
 	if cfg.Name == "" {
-		path := args[0]
-		_, cfg.Name = filepath.Split(path)
+		cfg.Name = bind.DefaultName(args[0])
 	}
 
-	var err error
 	cfg.OutputDir, err = genOutDir(cfg.OutputDir)
 	if err != nil {
 		return err
@@ -126,7 +221,10 @@ func gopyRunCmdExe(cmdr *commander.Command, args []string) error {
 		exmap[ex] = struct{}{}
 	}
 
-	cfg.OutputDir = filepath.Join(cfg.OutputDir, cfg.Name) // package must be in subdir
+	if err = GenPyPkgParents(cfg.OutputDir, cfg); err != nil {
+		return err
+	}
+	cfg.OutputDir = filepath.Join(cfg.OutputDir, cfg.PkgDir()) // package must be in subdir
 	cfg.OutputDir, err = genOutDir(cfg.OutputDir)
 	if err != nil {
 		return err
@@ -135,5 +233,8 @@ func gopyRunCmdExe(cmdr *commander.Command, args []string) error {
 	for _, path := range args {
 		buildPkgRecurse(cfg.OutputDir, path, path, exmap, cfg.BuildTags)
 	}
-	return runBuild(bind.ModeExe, cfg)
+	if err := runBuild(bind.ModeExe, cfg); err != nil {
+		return err
+	}
+	return reportStats(cfg)
 }
@@ -7,6 +7,7 @@ package main
 import (
 	"fmt"
 	"log"
+	"time"
 
 	"github.com/go-python/gopy/bind"
 	"github.com/gonuts/commander"
@@ -21,47 +22,175 @@ func gopyMakeCmdGen() *commander.Command {
 		Long: `
 gen generates (C)Python language bindings for Go package(s).
 
+Package args ending in /... bind all the packages matching them, and the -recursive option also binds all the dependencies of the packages within the same module, all into one output package, with the packages in order of increasing dependency.
+
 ex:
  $ gopy gen [options] <go-package-name> [other-go-package...]
  $ gopy gen github.com/go-python/gopy/_examples/hi
+ $ gopy gen -recursive github.com/me/mod/cmdpkg
+ $ gopy gen -name=mod github.com/me/mod/...
 `,
 		Flag: *flag.NewFlagSet("gopy-gen", flag.ExitOnError),
 	}
 
 	cmd.Flag.String("vm", "python", "path to python interpreter")
+	cmd.Flag.String("python-version", "", "build against a pinned standalone python of this version (e.g., 3.12), "+
+		"downloaded as needed, instead of -vm")
 	cmd.Flag.String("output", "", "output directory for bindings")
-	cmd.Flag.String("name", "", "name of output package (otherwise name of first package is used)")
+	cmd.Flag.String("into", "", "directory of an existing python package to generate the bindings into, as its _gopy subpackage, "+
+		"writing nothing else, instead of -output")
+	cmd.Flag.Bool("incremental", false, "skip the generation, and the build, when the Go packages, the options and the generated files "+
+		"did not change since the last one, by their content hashes recorded in the output dir")
+	cmd.Flag.String("name", "", "name of output package (otherwise name of first package is used), "+
+		"which may be a dotted python package name, e.g., org.proj.mod")
 	cmd.Flag.String("main", "", "code string to run in the go main() function in the cgo library")
 	cmd.Flag.String("package-prefix", ".", "custom package prefix used when generating import "+
 		"statements for generated package")
 	cmd.Flag.Bool("rename", false, "rename Go symbols to python PEP snake_case")
+	cmd.Flag.String("include", "", "regexp of the qualified names of the symbols to bind, e.g., 'hi\\.(Person|Add)$' "+
+		"-- methods and fields of the included types are bound too")
+	cmd.Flag.String("exclude", "", "regexp of the qualified names of the symbols, methods and fields to skip, e.g., 'hi\\.Person\\.Greet'")
+	cmd.Flag.String("frozen", "", "regexp of the qualified names of the structs immutable after construction, e.g., 'hi\\.(Point|Config)$'")
+	cmd.Flag.Bool("recursive", false, "also bind all the dependencies of the packages within the same module")
+	cmd.Flag.Bool("reexport", false, "generate an __init__.py re-exporting the symbols of the first package at the top level")
+	cmd.Flag.Bool("gen-perf", false, "generate Go benchmarks of the conversions of the bindings, with perf and perf-gate Makefile targets")
+	cmd.Flag.Bool("ide", false, "generate a compile_commands.json and VS Code settings, to open and debug the generated code in IDEs")
+	cmd.Flag.String("extra-go", "", "directory of .go files (package main) copied into the generated module, "+
+		"with their //export functions of C types bound, for hand-written shims")
+	cmd.Flag.String("hooks", "", "txtar file of the fragments of Go and python code injected before or after the calls of "+
+		"the generated functions and methods whose qualified names match their patterns, e.g., for validation or metrics")
+	cmd.Flag.String("templates", "", "directory of the templates of the preambles of the generated go.tmpl, build.py.tmpl, py.tmpl "+
+		"and py_exe.tmpl files and of the Makefile.tmpl and Makefile_exe.tmpl overriding the builtin ones, created with them if it does not exist")
+	cmd.Flag.Bool("gen-tests", false, "generate pytest smoke tests for the bindings in a tests/ subdirectory")
+	cmd.Flag.Bool("gen-app", false, "generate a sample python application, app.py, using the bindings, at the root of their python package")
+	cmd.Flag.String("doc", "", "generate the API reference docs of the bindings in a docs/ subdirectory, "+
+		"in the given format: rst (Sphinx reStructuredText) or md (Markdown)")
+	cmd.Flag.Bool("doc-conf", false, "generate a Sphinx conf.py along with the -doc API reference docs")
+	cmd.Flag.Bool("pretty", false, "add pretty() and to_yaml() methods to the python classes, rendering the Go values for debugging")
+	cmd.Flag.Bool("lossy-checks", false, "check the python floats losing precision by their conversion to Go float32, "+
+		"warning with go.LossyConversionWarning")
+	cmd.Flag.Bool("strict", false, "raise go.LossyConversionError for lossy conversions instead of warning, "+
+		"and fail on exported struct fields of unsupported types instead of dropping them")
 	cmd.Flag.Bool("no-warn", false, "suppress warning messages, which may be expected")
 	cmd.Flag.Bool("no-make", false, "do not generate a Makefile, e.g., when called from Makefile")
+	cmd.Flag.String("stats", "", "JSON file to write the statistics of the generation to, as summarized at its end")
+	cmd.Flag.String("report", "", "file to write the coverage report of the bindings to, with the diagnostics of the symbols "+
+		"that cannot be bound, as JSON, or as HTML if ending in .html")
+	cmd.Flag.String("build", "make", "build file generated along with the bindings: make (Makefile), cmake (CMakeLists.txt) or bazel (BUILD.bazel)")
+	cmd.Flag.String("errors", "", "comma-separated mappings of Go errors to the python builtin exceptions they are raised as: "+
+		"pkg/path.ErrSentinel=Exception, *pkg/path.ErrorType=Exception, or ~message substring=Exception, "+
+		"e.g., io/fs.ErrNotExist=FileNotFoundError")
+	cmd.Flag.Bool("no-timedelta", false, "pass time.Duration values as go.Duration ints of nanoseconds, with Go "+
+		"arithmetic, instead of converting them to datetime.timedelta")
+	cmd.Flag.Bool("dataclass", false, "bind the structs with only exported fields of basic and such struct types, "+
+		"and no methods, as python dataclasses, copied to and from Go instead of proxied by handles")
+	cmd.Flag.Int("copy-max", 0, "maximum number of elements of the slices, arrays and maps returned as python lists and dicts, "+
+		"copied, instead of proxies of the Go values, which can be changed at run time with go.copy_max (0 proxies them all)")
+	cmd.Flag.String("comma-ok", "none", "policy of the functions returning a value and a bool, in the comma-ok idiom, "+
+		"when the bool is false: none (return None) or raise (raise KeyError), overridden by //gopy:commaok directives")
 	cmd.Flag.Bool("dynamic-link", false, "whether to link output shared library dynamically to Python")
 	cmd.Flag.String("build-tags", "", "build tags to be passed to `go build`")
+	cmd.Flag.String("goos", "", "target GOOS of cross builds, e.g., windows, with the C cross compiler of the CC env var "+
+		"or the usual one of the target (default is the host GOOS)")
+	cmd.Flag.String("goarch", "", "target GOARCH of cross builds, e.g., arm64 (default is the host GOARCH)")
+	cmd.Flag.String("py-config", "", "JSON file of the sysconfig config vars of the target python, for cross builds, "+
+		"dumped by: python3 -c \"import json, sysconfig; print(json.dumps(sysconfig.get_config_vars()))\"")
+	cmd.Flag.String("handle", "int64", "type of the handles of the Go values in python: int64 (faster) "+
+		"or string (more debuggable, naming the Go type of the value)")
+	cmd.Flag.Int("handle-shards", 1, "number of shards of the handle registry, to reduce the lock contention of "+
+		"many python threads calling into Go simultaneously: 1 for a single lock, -1 for one per CPU")
+	cmd.Flag.Bool("thread-checks", false, "raise go.ThreadError when the structs not marked //gopy:threadsafe are used from "+
+		"several python threads, for debugging")
+	cmd.Flag.Bool("borrow-checks", false, "raise a RuntimeError when the python buffers of the //gopy:borrow string params "+
+		"change during the calls, for debugging")
+	cmd.Flag.Bool("no-arg-checks", false, "do not check the args of the functions in python by the checks recognized "+
+		"in their Go code, only by their //gopy:check directives")
+	cmd.Flag.Bool("async", false, "generate an async twin of each function and method, Func_async, returning "+
+		"an asyncio future of its result, called in a goroutine")
+	cmd.Flag.Bool("from-directives", false, "take the options not given on the command line from the "+
+		"//go:generate gopy gen directive of the package")
+	cmd.Flag.String("config", "", "gopy.toml or gopy.yaml config file of the packages and options of the bindings, "+
+		"overridden by the options given explicitly (default: the gopy.toml, gopy.yaml or gopy.yml of the current dir, if any)")
+	cmd.Flag.String("profile", "", "preset of the defaults of the options shaping the python API: minimal (closest to Go), "+
+		"standard (pythonic: -rename, -dataclass, -comma-ok=raise, -errors of the standard library, -lossy-checks, -copy-max=64) "+
+		"or full (standard, -pretty, -reexport and -gen-tests), overridden by the options given explicitly")
 	return cmd
 }
 
 func gopyRunCmdGen(cmdr *commander.Command, args []string) error {
-	var err error
-
+	args, err := applyConfig(cmdr, args)
+	if err != nil {
+		return err
+	}
 	if len(args) == 0 {
 		err := fmt.Errorf("gopy: expect a fully qualified go package name as argument")
 		log.Println(err)
 		return err
 	}
 
+	if cmdr.Flag.Lookup("from-directives").Value.Get().(bool) {
+		if err := applyGenerateOptions(cmdr, args[0], cmdr.Flag.Lookup("build-tags").Value.Get().(string)); err != nil {
+			return err
+		}
+	}
+	if err := applyProfile(cmdr); err != nil {
+		return err
+	}
+
 	cfg := NewBuildCfg()
 	cfg.OutputDir = cmdr.Flag.Lookup("output").Value.Get().(string)
 	cfg.VM = cmdr.Flag.Lookup("vm").Value.Get().(string)
-	cfg.Name = cmdr.Flag.Lookup("name").Value.Get().(string)
+	cfg.PythonVersion = cmdr.Flag.Lookup("python-version").Value.Get().(string)
+	if name := cmdr.Flag.Lookup("name").Value.Get().(string); name != "" {
+		if err := cfg.SetName(name); err != nil {
+			return err
+		}
+	}
 	cfg.Main = cmdr.Flag.Lookup("main").Value.Get().(string)
 	cfg.PkgPrefix = cmdr.Flag.Lookup("package-prefix").Value.Get().(string)
 	cfg.RenameCase = cmdr.Flag.Lookup("rename").Value.Get().(bool)
+	cfg.InitExports = cmdr.Flag.Lookup("reexport").Value.Get().(bool)
+	cfg.GenPerf = cmdr.Flag.Lookup("gen-perf").Value.Get().(bool)
+	cfg.GenIDE = cmdr.Flag.Lookup("ide").Value.Get().(bool)
+	cfg.ExtraGo = cmdr.Flag.Lookup("extra-go").Value.Get().(string)
+	cfg.Hooks = statsPath(cmdr.Flag.Lookup("hooks").Value.Get().(string))
+	cfg.Templates = statsPath(cmdr.Flag.Lookup("templates").Value.Get().(string))
+	cfg.GenTests = cmdr.Flag.Lookup("gen-tests").Value.Get().(bool)
+	cfg.GenApp = cmdr.Flag.Lookup("gen-app").Value.Get().(bool)
+	cfg.DocFormat = cmdr.Flag.Lookup("doc").Value.Get().(string)
+	cfg.DocConf = cmdr.Flag.Lookup("doc-conf").Value.Get().(bool)
+	cfg.Pretty = cmdr.Flag.Lookup("pretty").Value.Get().(bool)
+	cfg.LossyChecks = cmdr.Flag.Lookup("lossy-checks").Value.Get().(bool)
+	cfg.Strict = cmdr.Flag.Lookup("strict").Value.Get().(bool)
 	cfg.NoWarn = cmdr.Flag.Lookup("no-warn").Value.Get().(bool)
 	cfg.NoMake = cmdr.Flag.Lookup("no-make").Value.Get().(bool)
+	cfg.BuildSystem = cmdr.Flag.Lookup("build").Value.Get().(string)
+	cfg.NoTimedelta = cmdr.Flag.Lookup("no-timedelta").Value.Get().(bool)
+	cfg.Dataclass = cmdr.Flag.Lookup("dataclass").Value.Get().(bool)
+	cfg.CopyMax = cmdr.Flag.Lookup("copy-max").Value.Get().(int)
+	cfg.CommaOk = cmdr.Flag.Lookup("comma-ok").Value.Get().(string)
+	cfg.ErrorMap = cmdr.Flag.Lookup("errors").Value.Get().(string)
 	cfg.DynamicLinking = cmdr.Flag.Lookup("dynamic-link").Value.Get().(bool)
 	cfg.BuildTags = cmdr.Flag.Lookup("build-tags").Value.Get().(string)
+	cfg.GOOS = cmdr.Flag.Lookup("goos").Value.Get().(string)
+	cfg.GOARCH = cmdr.Flag.Lookup("goarch").Value.Get().(string)
+	cfg.PyConfig = cmdr.Flag.Lookup("py-config").Value.Get().(string)
+	cfg.Handle = cmdr.Flag.Lookup("handle").Value.Get().(string)
+	cfg.HandleShards = cmdr.Flag.Lookup("handle-shards").Value.Get().(int)
+	cfg.ThreadChecks = cmdr.Flag.Lookup("thread-checks").Value.Get().(bool)
+	cfg.BorrowChecks = cmdr.Flag.Lookup("borrow-checks").Value.Get().(bool)
+	cfg.NoArgChecks = cmdr.Flag.Lookup("no-arg-checks").Value.Get().(bool)
+	cfg.AsyncAPI = cmdr.Flag.Lookup("async").Value.Get().(bool)
+	cfg.StatsFile = statsPath(cmdr.Flag.Lookup("stats").Value.Get().(string))
+	cfg.ReportFile = statsPath(cmdr.Flag.Lookup("report").Value.Get().(string))
+	cfg.ConfigFile = statsPath(cmdr.Flag.Lookup("config").Value.Get().(string))
+
+	intoDir := cmdr.Flag.Lookup("into").Value.Get().(string)
+	if intoDir != "" {
+		if err := setInto(cfg, intoDir); err != nil {
+			return err
+		}
+	}
 
 	if cfg.VM == "" {
 		cfg.VM = "python"
@@ -69,13 +198,59 @@ func gopyRunCmdGen(cmdr *commander.Command, args []string) error {
 
 	bind.NoWarn = cfg.NoWarn
 	bind.NoMake = cfg.NoMake
+	bind.NoTimedelta = cfg.NoTimedelta
+	bind.Dataclass = cfg.Dataclass
+	bind.CopyMax = cfg.CopyMax
+	if err := bind.SetCommaOk(cfg.CommaOk); err != nil {
+		return err
+	}
+	if err := bind.SetHandleType(cfg.Handle); err != nil {
+		return err
+	}
+	defer setCrossTarget(cfg)()
+	bind.ImportDir = cfg.OutputDir
+	if err := bind.SetSymbolFilter(cmdr.Flag.Lookup("include").Value.Get().(string), cmdr.Flag.Lookup("exclude").Value.Get().(string)); err != nil {
+		return err
+	}
+	if err := bind.SetFrozen(cmdr.Flag.Lookup("frozen").Value.Get().(string)); err != nil {
+		return err
+	}
+
+	recursive := cmdr.Flag.Lookup("recursive").Value.Get().(bool)
+	paths, name, err := expandPackages(args, recursive, cfg.BuildTags)
+	if err != nil {
+		return err
+	}
+	cfg.Primary = name
+	if cfg.Name == "" {
+		cfg.Name = name
+	}
+
+	start := time.Now()
+	var hashes *incrementalHashes
+	if cmdr.Flag.Lookup("incremental").Value.Get().(bool) {
+		hs, ok, err := checkIncremental(cfg, paths)
+		if err != nil || ok {
+			return err
+		}
+		hashes = hs
+	}
+	var into map[string]bool // the files of the _gopy dir not generated
+	if intoDir != "" {
+		if into, err = cleanInto(cfg.OutputDir); err != nil {
+			return err
+		}
+	}
 
-	for _, path := range args {
+	for _, path := range paths {
 		bpkg, err := loadPackage(path, true, cfg.BuildTags) // build first
 		if err != nil {
 			return fmt.Errorf("gopy-gen: go build / load of package failed with path=%q: %v", path, err)
 		}
 		pkg, err := parsePackage(bpkg)
+		if cfg.Primary == "" {
+			cfg.Primary = pkg.Name()
+		}
 		if cfg.Name == "" {
 			cfg.Name = pkg.Name()
 		}
@@ -88,6 +263,16 @@ func gopyRunCmdGen(cmdr *commander.Command, args []string) error {
 	if err != nil {
 		return err
 	}
+	if into != nil {
+		if err := writeIntoManifest(cfg.OutputDir, into); err != nil {
+			return err
+		}
+	}
+	if hashes != nil {
+		if err := writeHashes(cfg.OutputDir, hashes, start); err != nil {
+			return err
+		}
+	}
 
-	return err
+	return reportStats(cfg)
 }
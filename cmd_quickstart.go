@@ -0,0 +1,148 @@
+// Copyright 2024 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+
+	"github.com/go-python/gopy/bind"
+	"github.com/gonuts/commander"
+	"github.com/gonuts/flag"
+)
+
+// 1 = python package name, 2 = python package dir, 3 = go packages, 4 = vm
+const quickstartReadme = `# %[1]s
+
+Python bindings of the Go package(s) %[3]s, generated by
+[gopy](https://github.com/go-python/gopy).
+
+## Layout
+
+* ` + "`%[2]s/`" + `: the python package of the bindings, generated by gopy, with:
+  * ` + "`%[2]s/tests/`" + `: pytest smoke tests of the bound API,
+  * ` + "`%[2]s/docs/`" + `: its API reference, in Markdown.
+* ` + "`app.py`" + `: a sample python application using the major features of the
+  bindings on the bound API -- edit it, it is not regenerated.
+* ` + "`setup.py`, `MANIFEST.in`, `LICENSE`" + `: the packaging of the bindings.
+* ` + "`Makefile`" + `: the gen, build and install targets.
+
+## Usage
+
+Build the bindings, run the sample application and the tests, and install
+the python package, with:
+
+` + "```" + `
+$ make build
+$ %[4]s app.py
+$ %[4]s -m pytest %[2]s/tests
+$ make install-pkg
+` + "```" + `
+
+` + "`make gen`" + ` regenerates the bindings, e.g., after changes of the Go API.
+`
+
+func gopyMakeCmdQuickstart() *commander.Command {
+	cmd := &commander.Command{
+		Run:       gopyRunCmdQuickstart,
+		UsageLine: "quickstart <go-package-name> [other-go-package...]",
+		Short:     "generate a complete example project of python bindings for Go",
+		Long: `
+quickstart generates a complete example project of the (C)Python language
+bindings of Go package(s), as pkg does, along with pytest smoke tests, the
+Markdown API reference, a sample python application, app.py, using the major
+features of the bindings on the bound API, and a README of its layout and
+usage.  The project is in the output directory, by default the name of the
+package with a -quickstart suffix.  app.py and the packaging files are not
+overwritten when regenerating the project.
+
+ex:
+ $ gopy quickstart [options] <go-package-name> [other-go-package...]
+ $ gopy quickstart github.com/go-python/gopy/_examples/hi
+`,
+		Flag: *flag.NewFlagSet("gopy-quickstart", flag.ExitOnError),
+	}
+
+	cmd.Flag.String("vm", "python", "path to python interpreter")
+	cmd.Flag.String("output", "", "output directory of the project (otherwise the package name with a -quickstart suffix)")
+	cmd.Flag.String("name", "", "name of output package (otherwise name of first package is used), "+
+		"which may be a dotted python package name, e.g., org.proj.mod")
+	cmd.Flag.Bool("rename", false, "rename Go symbols to python PEP snake_case")
+	cmd.Flag.String("build-tags", "", "build tags to be passed to `go build`")
+	cmd.Flag.Bool("no-warn", false, "suppress warning messages, which may be expected")
+	cmd.Flag.Bool("no-make", false, "do not generate a Makefile, e.g., when called from Makefile")
+	return cmd
+}
+
+func gopyRunCmdQuickstart(cmdr *commander.Command, args []string) error {
+	if len(args) == 0 {
+		err := fmt.Errorf("gopy: expect a fully qualified go package name as argument")
+		log.Println(err)
+		return err
+	}
+
+	var (
+		vm     = cmdr.Flag.Lookup("vm").Value.Get().(string)
+		output = cmdr.Flag.Lookup("output").Value.Get().(string)
+		name   = cmdr.Flag.Lookup("name").Value.Get().(string)
+	)
+	if output == "" {
+		output = bind.DefaultName(args[0]) + "-quickstart"
+	}
+	output, err := genOutDir(output)
+	if err != nil {
+		return err
+	}
+	_, err = os.Stat(filepath.Join(output, "setup.py"))
+	newProject := os.IsNotExist(err)
+
+	pargs := []string{"-gen-tests", "-gen-app", "-doc=md", "-vm=" + vm, "-output=" + output}
+	if name != "" {
+		pargs = append(pargs, "-name="+name)
+	}
+	if tags := cmdr.Flag.Lookup("build-tags").Value.Get().(string); tags != "" {
+		pargs = append(pargs, "-build-tags="+tags)
+	}
+	for _, fl := range []string{"rename", "no-warn", "no-make"} {
+		if cmdr.Flag.Lookup(fl).Value.Get().(bool) {
+			pargs = append(pargs, "-"+fl)
+		}
+	}
+
+	pkg := gopyMakeCmdPkg()
+	if err := pkg.Flag.Parse(append(pargs, args...)); err != nil {
+		return err
+	}
+	if err := gopyRunCmdPkg(pkg, pkg.Flag.Args()); err != nil {
+		return err
+	}
+
+	var cfg bind.BindCfg
+	if name == "" {
+		name = bind.DefaultName(args[0])
+	}
+	if err := cfg.SetName(name); err != nil {
+		return err
+	}
+	if newProject {
+		// replaces the default README of pkg
+		var gopkgs string
+		for i, a := range args {
+			if i > 0 {
+				gopkgs += ", "
+			}
+			gopkgs += "`" + a + "`"
+		}
+		_, pyvm := filepath.Split(vm)
+		readme := fmt.Sprintf(quickstartReadme, cfg.FullName(), filepath.ToSlash(cfg.PkgDir()), gopkgs, pyvm)
+		if err := os.WriteFile(filepath.Join(output, "README.md"), []byte(readme), 0644); err != nil {
+			return err
+		}
+	}
+	fmt.Printf("\n--- quickstart project of %s in %s ---\n", cfg.FullName(), output)
+	return nil
+}
@@ -0,0 +1,361 @@
+// Copyright 2024 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/go-python/gopy/bind"
+	"github.com/gonuts/commander"
+	"github.com/gonuts/flag"
+)
+
+func gopyMakeCmdRelease() *commander.Command {
+	cmd := &commander.Command{
+		Run:       gopyRunCmdRelease,
+		UsageLine: "release <go-package-name> [other-go-package...]",
+		Short:     "build release artifacts with checksums for all configured targets",
+		Long: `
+release builds the (C)Python language bindings for Go package(s) for each
+configured target -- python versions (-python-versions, using pinned standalone
+pythons, and / or -vms interpreters) times platforms (-platforms) -- and
+assembles one archive per target, along with a SHA256SUMS file, in the
+output (dist) directory.  Platforms other than the host one require a
+working cgo cross-compilation setup: the C cross compiler of CC in the
+environment, or the usual one of the platform, e.g., aarch64-linux-gnu-gcc.
+
+ex:
+ $ gopy release [options] <go-package-name> [other-go-package...]
+ $ gopy release -python-versions=3.10,3.11,3.12 -version=1.2.0 github.com/go-python/gopy/_examples/hi
+ $ gopy release -vms=python3 -platforms=darwin/amd64,darwin/arm64 github.com/go-python/gopy/_examples/hi
+`,
+		Flag: *flag.NewFlagSet("gopy-release", flag.ExitOnError),
+	}
+
+	cmd.Flag.String("python-versions", "", "comma-separated list of pinned standalone python versions to build for (e.g., 3.11,3.12)")
+	cmd.Flag.String("vms", "", "comma-separated list of python interpreters to build for (default python3 if no -python-versions)")
+	cmd.Flag.String("platforms", "", "comma-separated list of GOOS/GOARCH platforms to build for (default is the host platform)")
+	cmd.Flag.String("output", "dist", "output directory for the release artifacts")
+	cmd.Flag.String("name", "", "name of output package (otherwise name of first package is used), "+
+		"which may be a dotted python package name, e.g., org.proj.mod")
+	cmd.Flag.String("version", "0.1.0", "semantic version number of the release")
+	cmd.Flag.String("main", "", "code string to run in the go main() function in the cgo library")
+	cmd.Flag.String("package-prefix", ".", "custom package prefix used when generating import "+
+		"statements for generated package")
+	cmd.Flag.Bool("rename", false, "rename Go symbols to python PEP snake_case")
+	cmd.Flag.Bool("symbols", false, "include symbols in output")
+	cmd.Flag.Bool("no-warn", true, "suppress warning messages, which may be expected")
+	cmd.Flag.Bool("dynamic-link", false, "whether to link output shared library dynamically to Python")
+	cmd.Flag.String("build-tags", "", "build tags to be passed to `go build`")
+	return cmd
+}
+
+// releaseTarget is one python x platform combination to build a release for.
+type releaseTarget struct {
+	vm       string // python interpreter, or
+	pyvers   string // pinned standalone python version
+	goos     string
+	goarch   string
+	pytag    string // python tag, e.g., cp312, set after building
+	artifact string // archive file name, set after assembling
+	err      error
+}
+
+func (t *releaseTarget) String() string {
+	py := t.vm
+	if t.pyvers != "" {
+		py = "python-" + t.pyvers
+	}
+	return py + " " + t.goos + "/" + t.goarch
+}
+
+func gopyRunCmdRelease(cmdr *commander.Command, args []string) error {
+	if len(args) == 0 {
+		err := fmt.Errorf("gopy: expect a fully qualified go package name as argument")
+		log.Println(err)
+		return err
+	}
+
+	var (
+		pyversions = cmdr.Flag.Lookup("python-versions").Value.Get().(string)
+		vms        = cmdr.Flag.Lookup("vms").Value.Get().(string)
+		platforms  = cmdr.Flag.Lookup("platforms").Value.Get().(string)
+		outdir     = cmdr.Flag.Lookup("output").Value.Get().(string)
+		version    = cmdr.Flag.Lookup("version").Value.Get().(string)
+	)
+
+	proto := NewBuildCfg()
+	if name := cmdr.Flag.Lookup("name").Value.Get().(string); name != "" {
+		if err := proto.SetName(name); err != nil {
+			return err
+		}
+	}
+	proto.Main = cmdr.Flag.Lookup("main").Value.Get().(string)
+	proto.PkgPrefix = cmdr.Flag.Lookup("package-prefix").Value.Get().(string)
+	proto.RenameCase = cmdr.Flag.Lookup("rename").Value.Get().(bool)
+	proto.Symbols = cmdr.Flag.Lookup("symbols").Value.Get().(bool)
+	proto.NoWarn = cmdr.Flag.Lookup("no-warn").Value.Get().(bool)
+	proto.NoMake = true
+	proto.DynamicLinking = cmdr.Flag.Lookup("dynamic-link").Value.Get().(bool)
+	proto.BuildTags = cmdr.Flag.Lookup("build-tags").Value.Get().(string)
+
+	bind.NoWarn = proto.NoWarn
+	bind.NoMake = proto.NoMake
+	if err := bind.SetHandleType(proto.Handle); err != nil {
+		return err
+	}
+
+	targets, err := releaseTargets(pyversions, vms, platforms)
+	if err != nil {
+		return err
+	}
+
+	for _, path := range args {
+		bpkg, err := loadPackage(path, true, proto.BuildTags) // build first
+		if err != nil {
+			return fmt.Errorf("gopy-gen: go build / load of package failed with path=%q: %v", path, err)
+		}
+		pkg, err := parsePackage(bpkg)
+		if err != nil {
+			return err
+		}
+		if proto.Name == "" {
+			proto.Name = pkg.Name()
+		}
+	}
+
+	outdir, err = genOutDir(outdir)
+	if err != nil {
+		return err
+	}
+	builddir := filepath.Join(outdir, "build")
+	defer os.RemoveAll(builddir)
+
+	for _, t := range targets {
+		fmt.Printf("\n--- release: %s ---\n", t)
+		t.err = buildReleaseTarget(proto, t, builddir)
+		if t.err != nil {
+			continue
+		}
+		t.artifact = fmt.Sprintf("%s-%s-%s-%s_%s.tar.gz", proto.Name, version, t.pytag, t.goos, t.goarch)
+		t.err = targzDir(filepath.Join(builddir, t.dirName()), proto.Name, filepath.Join(outdir, t.artifact))
+	}
+
+	var artifacts []string
+	for _, t := range targets {
+		if t.err == nil {
+			artifacts = append(artifacts, t.artifact)
+		}
+	}
+	if len(artifacts) > 0 {
+		err = writeSHA256Sums(outdir, artifacts)
+		if err != nil {
+			return err
+		}
+	}
+
+	fmt.Printf("\n--- release summary for %s %s in %s ---\n", proto.Name, version, outdir)
+	tw := tabwriter.NewWriter(os.Stdout, 0, 8, 2, ' ', 0)
+	nfail := 0
+	for _, t := range targets {
+		status := t.artifact
+		if t.err != nil {
+			nfail++
+			status = "FAILED: " + t.err.Error()
+		}
+		fmt.Fprintf(tw, "%s\t%s\n", t, status)
+	}
+	tw.Flush()
+
+	if nfail > 0 {
+		return fmt.Errorf("gopy: %d of %d release targets failed", nfail, len(targets))
+	}
+	return nil
+}
+
+// releaseTargets returns all the python x platform combinations to build
+func releaseTargets(pyversions, vms, platforms string) ([]*releaseTarget, error) {
+	var pys []releaseTarget
+	for _, v := range splitList(pyversions) {
+		pys = append(pys, releaseTarget{pyvers: v})
+	}
+	for _, vm := range splitList(vms) {
+		pys = append(pys, releaseTarget{vm: vm})
+	}
+	if len(pys) == 0 {
+		pys = append(pys, releaseTarget{vm: "python3"})
+	}
+
+	plats := splitList(platforms)
+	if len(plats) == 0 {
+		plats = []string{runtime.GOOS + "/" + runtime.GOARCH}
+	}
+
+	var targets []*releaseTarget
+	for _, plat := range plats {
+		goos, goarch, ok := strings.Cut(plat, "/")
+		if !ok || goos == "" || goarch == "" {
+			return nil, fmt.Errorf("gopy: invalid platform %q, expected GOOS/GOARCH", plat)
+		}
+		for _, py := range pys {
+			t := py
+			t.goos, t.goarch = goos, goarch
+			targets = append(targets, &t)
+		}
+	}
+	return targets, nil
+}
+
+// dirName returns the name of the build directory for the target
+func (t *releaseTarget) dirName() string {
+	py := filepath.Base(t.vm)
+	if t.pyvers != "" {
+		py = "standalone-" + t.pyvers
+	}
+	return fmt.Sprintf("%s-%s_%s", py, t.goos, t.goarch)
+}
+
+// buildReleaseTarget builds the bindings for one release target
+func buildReleaseTarget(proto *BuildCfg, t *releaseTarget, builddir string) error {
+	cfg := *proto
+	cfg.VM = t.vm
+	cfg.PythonVersion = t.pyvers
+	cfg.OutputDir = filepath.Join(builddir, t.dirName())
+	bind.ImportDir = cfg.OutputDir
+
+	cfg.GOOS, cfg.GOARCH = t.goos, t.goarch
+	defer setCrossTarget(&cfg)()
+
+	err := runBuild(bind.ModeBuild, &cfg)
+	if err != nil {
+		return err
+	}
+
+	// cfg.VM is now the resolved interpreter
+	out, err := exec.Command(cfg.VM, "-c", "import sys; print('cp%d%d' % sys.version_info[:2])").Output()
+	if err != nil {
+		return fmt.Errorf("gopy: could not get python tag of %s: %v", cfg.VM, err)
+	}
+	t.pytag = strings.TrimSpace(string(out))
+	return nil
+}
+
+// restoreEnv returns a func restoring the current value of env var key
+func restoreEnv(key string) func() {
+	val, ok := os.LookupEnv(key)
+	return func() {
+		if ok {
+			os.Setenv(key, val)
+		} else {
+			os.Unsetenv(key)
+		}
+	}
+}
+
+// releaseFile returns true if the given build output file is part of a release
+func releaseFile(fn string) bool {
+	switch filepath.Ext(fn) {
+	case ".go", ".c", ".h", ".mod", ".sum":
+		return false
+	}
+	switch fn {
+	case "build.py", "Makefile":
+		return false
+	}
+	return true
+}
+
+// targzDir writes the release files of dir into a gzipped tar archive,
+// under the given top-level directory name
+func targzDir(dir, top, fname string) error {
+	files, err := os.ReadDir(dir)
+	if err != nil {
+		return err
+	}
+	f, err := os.Create(fname)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	gz := gzip.NewWriter(f)
+	tw := tar.NewWriter(gz)
+	for _, fi := range files {
+		if fi.IsDir() || !releaseFile(fi.Name()) {
+			continue
+		}
+		info, err := fi.Info()
+		if err != nil {
+			return err
+		}
+		hdr, err := tar.FileInfoHeader(info, "")
+		if err != nil {
+			return err
+		}
+		hdr.Name = top + "/" + fi.Name()
+		if err = tw.WriteHeader(hdr); err != nil {
+			return err
+		}
+		src, err := os.Open(filepath.Join(dir, fi.Name()))
+		if err != nil {
+			return err
+		}
+		_, err = io.Copy(tw, src)
+		src.Close()
+		if err != nil {
+			return err
+		}
+	}
+	if err = tw.Close(); err != nil {
+		return err
+	}
+	if err = gz.Close(); err != nil {
+		return err
+	}
+	return f.Close()
+}
+
+// writeSHA256Sums writes the SHA256SUMS file for the given files in dir
+func writeSHA256Sums(dir string, files []string) error {
+	sort.Strings(files)
+	var sb strings.Builder
+	for _, fn := range files {
+		f, err := os.Open(filepath.Join(dir, fn))
+		if err != nil {
+			return err
+		}
+		h := sha256.New()
+		_, err = io.Copy(h, f)
+		f.Close()
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(&sb, "%s  %s\n", hex.EncodeToString(h.Sum(nil)), fn)
+	}
+	return os.WriteFile(filepath.Join(dir, "SHA256SUMS"), []byte(sb.String()), 0644)
+}
+
+// splitList splits a comma-separated list, dropping empty elements
+func splitList(s string) []string {
+	var l []string
+	for _, e := range strings.Split(s, ",") {
+		if e = strings.TrimSpace(e); e != "" {
+			l = append(l, e)
+		}
+	}
+	return l
+}
@@ -0,0 +1,45 @@
+// Copyright 2024 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"runtime"
+	"testing"
+)
+
+func TestReleaseTargets(t *testing.T) {
+	targets, err := releaseTargets("3.11, 3.12", "python3", "linux/amd64,darwin/arm64")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{
+		"python-3.11 linux/amd64",
+		"python-3.12 linux/amd64",
+		"python3 linux/amd64",
+		"python-3.11 darwin/arm64",
+		"python-3.12 darwin/arm64",
+		"python3 darwin/arm64",
+	}
+	if len(targets) != len(want) {
+		t.Fatalf("expected %d targets, actual %d", len(want), len(targets))
+	}
+	for i, tg := range targets {
+		if tg.String() != want[i] {
+			t.Errorf("target %d: expected %q, actual %q", i, want[i], tg.String())
+		}
+	}
+
+	targets, err = releaseTargets("", "", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(targets) != 1 || targets[0].vm != "python3" || targets[0].goos != runtime.GOOS || targets[0].goarch != runtime.GOARCH {
+		t.Errorf("unexpected default target: %v", targets)
+	}
+
+	if _, err = releaseTargets("", "", "linux"); err == nil {
+		t.Errorf("expected an error for an invalid platform")
+	}
+}
@@ -0,0 +1,336 @@
+// Copyright 2024 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/go-python/gopy/bind"
+	"github.com/gonuts/commander"
+	"github.com/gonuts/flag"
+)
+
+func gopyMakeCmdTestMatrix() *commander.Command {
+	cmd := &commander.Command{
+		Run:       gopyRunCmdTestMatrix,
+		UsageLine: "testmatrix <go-package-name> [other-go-package...]",
+		Short:     "build and import-test bindings against every python interpreter found",
+		Long: `
+testmatrix builds the (C)Python language bindings for Go package(s) once for
+each python interpreter found on this machine, and checks that the resulting
+module can be imported.  Interpreters are discovered on the PATH, under pyenv
+($PYENV_ROOT, or ~/.pyenv) and in conda installations ($CONDA_PREFIX, and
+~/miniconda3, ~/anaconda3 etc, including their envs).  A summary of which
+interpreters are compatible is printed at the end.  As with gopy build, the
+builds must be within a go module, requiring the package(s) and gopy: they
+are made in a temporary directory of the current directory, removed at the
+end, unless -keep, or in the -output directory.
+
+ex:
+ $ gopy testmatrix [options] <go-package-name> [other-go-package...]
+ $ gopy testmatrix github.com/go-python/gopy/_examples/hi
+ $ gopy testmatrix -vms=python3.8,python3.12 github.com/go-python/gopy/_examples/hi
+`,
+		Flag: *flag.NewFlagSet("gopy-testmatrix", flag.ExitOnError),
+	}
+
+	cmd.Flag.String("vms", "", "comma-separated list of python interpreters to test (otherwise discovered)")
+	cmd.Flag.String("output", "", "root output directory for the per-interpreter builds, within a go module "+
+		"(otherwise a temporary directory in the current directory, removed at the end)")
+	cmd.Flag.String("name", "", "name of output package (otherwise name of first package is used), "+
+		"which may be a dotted python package name, e.g., org.proj.mod")
+	cmd.Flag.String("main", "", "code string to run in the go main() function in the cgo library")
+	cmd.Flag.String("package-prefix", "", "custom package prefix used when generating import "+
+		"statements for generated package (none, as the modules are import-tested from their build directory)")
+	cmd.Flag.Bool("rename", false, "rename Go symbols to python PEP snake_case")
+	cmd.Flag.Bool("keep", false, "keep the temporary directory of the per-interpreter builds")
+	cmd.Flag.Bool("no-warn", true, "suppress warning messages, which may be expected")
+	cmd.Flag.Bool("dynamic-link", false, "whether to link output shared library dynamically to Python")
+	cmd.Flag.String("build-tags", "", "build tags to be passed to `go build`")
+	return cmd
+}
+
+// matrixResult records the outcome of building and importing
+// the bindings with one python interpreter.
+type matrixResult struct {
+	vm      string
+	version string
+	build   error
+	imprt   error
+}
+
+func (r matrixResult) ok() bool {
+	return r.build == nil && r.imprt == nil
+}
+
+func (r matrixResult) status() string {
+	switch {
+	case r.build != nil:
+		return "build FAILED"
+	case r.imprt != nil:
+		return "import FAILED"
+	}
+	return "ok"
+}
+
+func gopyRunCmdTestMatrix(cmdr *commander.Command, args []string) error {
+	if len(args) == 0 {
+		err := fmt.Errorf("gopy: expect a fully qualified go package name as argument")
+		log.Println(err)
+		return err
+	}
+
+	var (
+		vms    = cmdr.Flag.Lookup("vms").Value.Get().(string)
+		keep   = cmdr.Flag.Lookup("keep").Value.Get().(bool)
+		outdir = cmdr.Flag.Lookup("output").Value.Get().(string)
+	)
+
+	proto := NewBuildCfg()
+	if name := cmdr.Flag.Lookup("name").Value.Get().(string); name != "" {
+		if err := proto.SetName(name); err != nil {
+			return err
+		}
+	}
+	proto.Main = cmdr.Flag.Lookup("main").Value.Get().(string)
+	proto.PkgPrefix = cmdr.Flag.Lookup("package-prefix").Value.Get().(string)
+	proto.RenameCase = cmdr.Flag.Lookup("rename").Value.Get().(bool)
+	proto.Symbols = true
+	proto.NoWarn = cmdr.Flag.Lookup("no-warn").Value.Get().(bool)
+	proto.NoMake = true
+	proto.DynamicLinking = cmdr.Flag.Lookup("dynamic-link").Value.Get().(bool)
+	proto.BuildTags = cmdr.Flag.Lookup("build-tags").Value.Get().(string)
+
+	bind.NoWarn = proto.NoWarn
+	bind.NoMake = proto.NoMake
+	if err := bind.SetHandleType(proto.Handle); err != nil {
+		return err
+	}
+
+	interps := splitList(vms)
+	if len(interps) == 0 {
+		interps = findPythonInterpreters()
+	}
+	if len(interps) == 0 {
+		return fmt.Errorf("gopy: no python interpreters found")
+	}
+
+	for _, path := range args {
+		bpkg, err := loadPackage(path, true, proto.BuildTags) // build first
+		if err != nil {
+			return fmt.Errorf("gopy-gen: go build / load of package failed with path=%q: %v", path, err)
+		}
+		pkg, err := parsePackage(bpkg)
+		if err != nil {
+			return err
+		}
+		if proto.Name == "" {
+			proto.Name = pkg.Name()
+		}
+	}
+
+	// the temporary directory is made in the current directory, so that the
+	// builds are within its go module, as those of gopy build by default
+	var err error
+	tmpdir := outdir == ""
+	if tmpdir {
+		outdir, err = os.MkdirTemp(".", "_gopy-testmatrix-")
+		if err != nil {
+			return fmt.Errorf("gopy: could not create temporary output directory: %v", err)
+		}
+	}
+	outdir, err = genOutDir(outdir)
+	if err != nil {
+		return err
+	}
+	if tmpdir && !keep {
+		defer os.RemoveAll(outdir)
+	}
+
+	var results []matrixResult
+	for i, vm := range interps {
+		res := matrixResult{vm: vm}
+		res.version, err = getPythonFullVersion(vm)
+		if err != nil {
+			res.build = err
+			results = append(results, res)
+			continue
+		}
+
+		cfg := *proto
+		cfg.VM = vm
+		cfg.OutputDir = filepath.Join(outdir, fmt.Sprintf("py%s-%d", res.version, i))
+		bind.ImportDir = cfg.OutputDir
+
+		fmt.Printf("\n--- testmatrix: %s (python %s) ---\n", vm, res.version)
+		res.build = runBuild(bind.ModeBuild, &cfg)
+		if res.build == nil {
+			res.imprt = importTest(vm, cfg.OutputDir, cfg.Name)
+		}
+		results = append(results, res)
+	}
+
+	printMatrix(os.Stdout, proto.Name, results)
+	if tmpdir && !keep {
+		fmt.Printf("\nremoving build directories in %s (use -keep to preserve them)\n", outdir)
+	}
+
+	nfail := 0
+	for _, res := range results {
+		if !res.ok() {
+			nfail++
+		}
+	}
+	if nfail > 0 {
+		return fmt.Errorf("gopy: %d of %d python interpreters failed", nfail, len(results))
+	}
+	return nil
+}
+
+// importTest checks that the generated module can be imported by the
+// given python interpreter.
+func importTest(vm, dir, name string) error {
+	cmd := exec.Command(vm, "-c", "import "+name)
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(), "PYTHONPATH="+dir)
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("%v: %s", err, strings.TrimSpace(string(out)))
+	}
+	return nil
+}
+
+// printMatrix writes the summary table of the testmatrix results.
+func printMatrix(w io.Writer, name string, results []matrixResult) {
+	fmt.Fprintf(w, "\n--- testmatrix summary for %s ---\n", name)
+	tw := tabwriter.NewWriter(w, 0, 8, 2, ' ', 0)
+	fmt.Fprintf(tw, "version\tinterpreter\tstatus\n")
+	for _, res := range results {
+		fmt.Fprintf(tw, "%s\t%s\t%s\n", res.version, res.vm, res.status())
+	}
+	tw.Flush()
+
+	for _, res := range results {
+		switch {
+		case res.build != nil:
+			fmt.Fprintf(w, "\n%s: build error: %v\n", res.vm, res.build)
+		case res.imprt != nil:
+			fmt.Fprintf(w, "\n%s: import error: %v\n", res.vm, res.imprt)
+		}
+	}
+}
+
+// getPythonFullVersion returns the major.minor.micro version of the
+// given python interpreter.
+func getPythonFullVersion(vm string) (string, error) {
+	out, err := exec.Command(vm, "-c", "import sys; print('%d.%d.%d' % sys.version_info[:3])").Output()
+	if err != nil {
+		return "", fmt.Errorf("gopy: error retrieving version of python interpreter %q: %v", vm, err)
+	}
+	return strings.TrimSpace(string(out)), nil
+}
+
+// findPythonInterpreters returns the python interpreters found on this
+// machine, on the PATH, under pyenv and within conda installations.
+// Only python3 interpreters are returned, and interpreters resolving
+// to the same executable are only listed once.
+func findPythonInterpreters() []string {
+	exe := ""
+	if runtime.GOOS == "windows" {
+		exe = ".exe"
+	}
+
+	var cands []string
+
+	// PATH: python3, python3.X
+	for _, dir := range filepath.SplitList(os.Getenv("PATH")) {
+		if dir == "" {
+			continue
+		}
+		// skip pyenv shims, which all resolve to the same shim script
+		if strings.Contains(filepath.ToSlash(dir), "/.pyenv/shims") {
+			continue
+		}
+		for _, pat := range []string{"python3" + exe, "python3.*" + exe, "python" + exe} {
+			m, _ := filepath.Glob(filepath.Join(dir, pat))
+			cands = append(cands, m...)
+		}
+	}
+
+	home, _ := os.UserHomeDir()
+
+	// pyenv
+	pyenv := os.Getenv("PYENV_ROOT")
+	if pyenv == "" && home != "" {
+		pyenv = filepath.Join(home, ".pyenv")
+	}
+	if pyenv != "" {
+		m, _ := filepath.Glob(filepath.Join(pyenv, "versions", "*", "bin", "python3"+exe))
+		cands = append(cands, m...)
+	}
+
+	// conda
+	var condas []string
+	if prefix := os.Getenv("CONDA_PREFIX"); prefix != "" {
+		condas = append(condas, prefix)
+	}
+	if home != "" {
+		for _, pat := range []string{"miniconda*", "anaconda*", "miniforge*", "mambaforge*"} {
+			m, _ := filepath.Glob(filepath.Join(home, pat))
+			condas = append(condas, m...)
+		}
+	}
+	for _, root := range condas {
+		cands = append(cands, condaPython(root, exe)...)
+		envs, _ := filepath.Glob(filepath.Join(root, "envs", "*"))
+		for _, env := range envs {
+			cands = append(cands, condaPython(env, exe)...)
+		}
+	}
+
+	var (
+		interps []string
+		seen    = make(map[string]bool)
+	)
+	for _, c := range cands {
+		fi, err := os.Stat(c)
+		if err != nil || fi.IsDir() {
+			continue
+		}
+		real, err := filepath.EvalSymlinks(c)
+		if err != nil {
+			continue
+		}
+		if seen[real] {
+			continue
+		}
+		seen[real] = true
+		if vers, err := getPythonVersion(c); err != nil || vers < 3 {
+			continue
+		}
+		interps = append(interps, c)
+	}
+	sort.Strings(interps)
+	return interps
+}
+
+// condaPython returns the python interpreter of a conda environment root, if any.
+func condaPython(root, exe string) []string {
+	for _, p := range []string{filepath.Join(root, "bin", "python3"+exe), filepath.Join(root, "python"+exe)} {
+		if _, err := os.Stat(p); err == nil {
+			return []string{p}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,124 @@
+// Copyright 2024 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/go-python/gopy/bind"
+)
+
+func TestFindPythonInterpreters(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("fake interpreters are shell scripts")
+	}
+	tmp := t.TempDir()
+	fake := func(path, major string) string {
+		fn := filepath.Join(tmp, filepath.FromSlash(path))
+		if err := os.MkdirAll(filepath.Dir(fn), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(fn, []byte("#!/bin/sh\necho "+major+"\n"), 0755); err != nil {
+			t.Fatal(err)
+		}
+		return fn
+	}
+	link := func(target, path string) {
+		fn := filepath.Join(tmp, filepath.FromSlash(path))
+		if err := os.MkdirAll(filepath.Dir(fn), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.Symlink(target, fn); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	py3 := fake("bin/python3", "3")
+	link(py3, "bin/python3.12") // same executable as python3
+	fake("bin/python", "2")     // not a python3
+	fake("home/.pyenv/shims/python3", "3")
+	pyenv := fake("pyenv/versions/3.11.0/bin/python3", "3")
+	conda := fake("conda/bin/python3", "3")
+	link(py3, "conda/envs/same/bin/python3") // same executable as python3
+	env := fake("conda/envs/other/python", "3")
+	mini := fake("home/miniconda3/bin/python3", "3")
+
+	t.Setenv("PATH", filepath.Join(tmp, "bin")+string(os.PathListSeparator)+filepath.Join(tmp, "home", ".pyenv", "shims"))
+	t.Setenv("HOME", filepath.Join(tmp, "home"))
+	t.Setenv("PYENV_ROOT", filepath.Join(tmp, "pyenv"))
+	t.Setenv("CONDA_PREFIX", filepath.Join(tmp, "conda"))
+
+	got := findPythonInterpreters()
+	want := []string{py3, conda, env, mini, pyenv}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("unexpected interpreters:\ngot  %q\nwant %q", got, want)
+	}
+}
+
+func TestPrintMatrix(t *testing.T) {
+	var sb strings.Builder
+	printMatrix(&sb, "hi", []matrixResult{
+		{vm: "/usr/bin/python3", version: "3.12.7"},
+		{vm: "/opt/py38/bin/python3", version: "3.8.20", build: errors.New("missing Python.h")},
+		{vm: "/opt/py313/bin/python3", version: "3.13.0", imprt: errors.New("undefined symbol")},
+	})
+	want := `
+--- testmatrix summary for hi ---
+version  interpreter             status
+3.12.7   /usr/bin/python3        ok
+3.8.20   /opt/py38/bin/python3   build FAILED
+3.13.0   /opt/py313/bin/python3  import FAILED
+
+/opt/py38/bin/python3: build error: missing Python.h
+
+/opt/py313/bin/python3: import error: undefined symbol
+`
+	if got := sb.String(); got != want {
+		t.Fatalf("unexpected summary:\ngot:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestTestMatrix(t *testing.T) {
+	pyvm := testBackends["py3"]
+	if pyvm == "" {
+		t.Skip("no python3")
+	}
+	defer bind.ResetPackages()
+
+	// the temporary directory of the builds is removed
+	if err := run([]string{"testmatrix", "-vms=" + pyvm, "./_examples/simple"}); err != nil {
+		t.Fatal(err)
+	}
+	if tmps, _ := filepath.Glob("_gopy-testmatrix-*"); len(tmps) != 0 {
+		t.Errorf("temporary directories not removed: %v", tmps)
+	}
+
+	// but not the output directory, nor its files
+	bind.ResetPackages()
+	out, err := os.MkdirTemp(".", "_testmatrix-out-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(out)
+	keep := filepath.Join(out, "keep.txt")
+	if err := os.WriteFile(keep, []byte("keep"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := run([]string{"testmatrix", "-vms=" + pyvm, "-output=" + out, "./_examples/simple"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(keep); err != nil {
+		t.Errorf("output directory files removed: %v", err)
+	}
+	if builds, _ := filepath.Glob(filepath.Join(out, "py*", "simple.py")); len(builds) != 1 {
+		t.Errorf("unexpected builds in the output directory: %v", builds)
+	}
+}
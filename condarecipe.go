@@ -0,0 +1,181 @@
+// Copyright 2024 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// the -conda option of pkg generates a conda-build recipe of the bindings in
+// a conda/ subdirectory of the output, to publish them to conda channels:
+// its meta.yaml pins the python version of the -vm interpreter (or of
+// -python-version), and the C++ runtime, libstdc++ or libc++, to at least
+// that of the compiler which built it, and its build.sh builds the bindings
+// against the python of the conda environment, by the Makefile, before
+// installing them by setup.py.
+
+const (
+	// 1 = pkg name, 2 = -user, 3 = version, 4 = desc, 5 = url, 6 = cmd,
+	// 7 = python version, 8 = C++ runtime pins, 9 = import name
+	condaMetaTempl = `# conda-build recipe of the python bindings of %[1]s
+# File is generated by gopy (will not be overwritten though)
+# %[6]s
+#
+# build it from the root of the package with:
+#   conda build conda
+
+{%% set name = "%[1]s%[2]s" %%}
+{%% set version = "%[3]s" %%}
+
+package:
+  name: {{ name|lower|replace(".", "-") }}
+  version: {{ version }}
+
+source:
+  path: ..
+
+build:
+  number: 0
+  skip: true  # [win]
+
+requirements:
+  build:
+    - {{ compiler('c') }}
+    - {{ compiler('go-cgo') }}
+    - make
+  host:
+    - python %[7]s.*
+    - pip
+    - setuptools
+    - pybindgen
+  run:
+    - python %[7]s.*
+%[8]s
+test:
+  imports:
+    - %[9]s
+
+about:
+  home: %[5]s
+  license: BSD-3-Clause
+  license_file: LICENSE
+  summary: "%[4]s"
+`
+
+	// 1 = pkg name, 2 = python version
+	condaBuildTempl = `#!/bin/bash
+# conda-build script of the python bindings of %[1]s
+# File is generated by gopy (will not be overwritten though)
+
+set -ex
+
+export PATH="$(go env GOPATH)/bin:$PATH"
+go install golang.org/x/tools/cmd/goimports@latest
+
+# build the bindings against the python of the host environment
+make build \
+  PYTHON="$PYTHON" \
+  CFLAGS="$("$PREFIX/bin/python%[2]s-config" --includes)" \
+  LDFLAGS="$("$PREFIX/bin/python%[2]s-config" --ldflags --embed)"
+
+$PYTHON -m pip install . --no-deps --no-build-isolation -vv
+`
+)
+
+// condaInterp is the python interpreter pinned by the conda recipe
+type condaInterp struct {
+	Version  string `json:"version"`  // major.minor
+	Compiler string `json:"compiler"` // platform.python_compiler()
+}
+
+// getCondaInterp returns the version and compiler of the python interpreter
+func getCondaInterp(vm string) (condaInterp, error) {
+	var py condaInterp
+	bin, err := exec.LookPath(vm)
+	if err != nil {
+		return py, errors.Wrapf(err, "could not locate python vm %q", vm)
+	}
+	code := `import json, platform, sys
+print(json.dumps({
+	"version": "%d.%d" % sys.version_info[:2],
+	"compiler": platform.python_compiler(),
+}))
+`
+	out, err := exec.Command(bin, "-c", code).Output()
+	if err != nil {
+		return py, errors.Wrapf(err, "gopy: error retrieving python version and compiler")
+	}
+	err = json.Unmarshal(out, &py)
+	return py, errors.Wrapf(err, "gopy: error decoding python version and compiler")
+}
+
+// condaCompilerRe matches the GCC and Clang versions of python_compiler
+var condaCompilerRe = regexp.MustCompile(`^(GCC|Clang) (\d+)\.(\d+)`)
+
+// condaRuntimePins returns the run requirements pinning the C++ runtime to
+// at least that of the compiler of the python interpreter, with the
+// selectors of their platforms, or none for other compilers, e.g., MSC
+func condaRuntimePins(compiler string) []string {
+	m := condaCompilerRe.FindStringSubmatch(compiler)
+	if m == nil {
+		return nil
+	}
+	vers := m[2] + "." + m[3]
+	if m[1] == "GCC" {
+		return []string{
+			"libgcc-ng >=" + vers + "  # [linux]",
+			"libstdcxx-ng >=" + vers + "  # [linux]",
+		}
+	}
+	return []string{"libcxx >=" + m[2] + "  # [osx]"}
+}
+
+// GenCondaRecipe generates the conda-build recipe of the python package of
+// the bindings, in the conda subdirectory of the output, unless it exists
+func GenCondaRecipe(cfg *BuildCfg, user, version, desc, url string) error {
+	dir := filepath.Join(cfg.OutputDir, "conda")
+	meta := filepath.Join(dir, "meta.yaml")
+	if _, err := os.Stat(meta); err == nil {
+		return nil
+	}
+	vm := cfg.VM
+	if cfg.PythonVersion != "" {
+		var err error
+		if vm, err = standalonePython(cfg.PythonVersion); err != nil {
+			return err
+		}
+	}
+	py, err := getCondaInterp(vm)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+
+	dashUser := user
+	if user != "" {
+		dashUser = "-" + user
+	}
+	var pins string
+	for _, p := range condaRuntimePins(py.Compiler) {
+		pins += "    - " + p + "\n"
+	}
+	desc = strings.ReplaceAll(desc, `"`, `\"`)
+	mf := fmt.Sprintf(condaMetaTempl, cfg.FullName(), dashUser, version, desc, url, cfg.Cmd, py.Version, pins, cfg.FullName())
+	if err := os.WriteFile(meta, []byte(mf), 0644); err != nil {
+		return err
+	}
+	bf := fmt.Sprintf(condaBuildTempl, cfg.FullName(), py.Version)
+	return os.WriteFile(filepath.Join(dir, "build.sh"), []byte(bf), 0755)
+}
@@ -0,0 +1,86 @@
+// Copyright 2024 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestCondaRuntimePins(t *testing.T) {
+	for _, tt := range []struct {
+		compiler string
+		want     []string
+	}{
+		{"GCC 12.3.0", []string{"libgcc-ng >=12.3  # [linux]", "libstdcxx-ng >=12.3  # [linux]"}},
+		{"Clang 14.0.6 ", []string{"libcxx >=14  # [osx]"}},
+		{"MSC v.1929 64 bit (AMD64)", nil},
+		{"", nil},
+	} {
+		got := condaRuntimePins(tt.compiler)
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("condaRuntimePins(%q): expected %q, actual %q", tt.compiler, tt.want, got)
+		}
+	}
+}
+
+func TestGenCondaRecipe(t *testing.T) {
+	vm, err := exec.LookPath("python3")
+	if err != nil {
+		t.Skip("python3 not available")
+	}
+	py, err := getCondaInterp(vm)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := NewBuildCfg()
+	cfg.OutputDir = t.TempDir()
+	cfg.VM = vm
+	if err := cfg.SetName("org.hi"); err != nil {
+		t.Fatal(err)
+	}
+	if err := GenCondaRecipe(cfg, "", "1.2.3", `say "hi"`, "https://example.com/hi"); err != nil {
+		t.Fatal(err)
+	}
+	meta, err := os.ReadFile(filepath.Join(cfg.OutputDir, "conda", "meta.yaml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		`{% set name = "org.hi" %}`,
+		`{% set version = "1.2.3" %}`,
+		"    - python " + py.Version + ".*\n",
+		"    - org.hi\n",
+		`summary: "say \"hi\""`,
+	} {
+		if !strings.Contains(string(meta), want) {
+			t.Errorf("meta.yaml: missing %q in:\n%s", want, meta)
+		}
+	}
+	build, err := os.ReadFile(filepath.Join(cfg.OutputDir, "conda", "build.sh"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "python" + py.Version + "-config"; !strings.Contains(string(build), want) {
+		t.Errorf("build.sh: missing %q in:\n%s", want, build)
+	}
+
+	// the edited recipe is not overwritten
+	fn := filepath.Join(cfg.OutputDir, "conda", "meta.yaml")
+	if err := os.WriteFile(fn, []byte("edited"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := GenCondaRecipe(cfg, "", "1.2.4", "", ""); err != nil {
+		t.Fatal(err)
+	}
+	if b, _ := os.ReadFile(fn); string(b) != "edited" {
+		t.Errorf("meta.yaml overwritten: %s", b)
+	}
+}
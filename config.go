@@ -0,0 +1,500 @@
+// Copyright 2024 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/gonuts/commander"
+	"github.com/gonuts/flag"
+
+	"github.com/go-python/gopy/bind"
+)
+
+// A project can describe its bindings in a config file, gopy.toml or
+// gopy.yaml, read by gen, build, pkg and exe from the -config option, or
+// from the current directory, so that complex setups are reproducible
+// without long command lines, e.g.,
+//
+//	packages = ["./mylib", "./mylib/sub"]
+//	name = "mylib"
+//	vm = "python3"
+//	output = "out"
+//	profile = "standard"
+//	exclude = ['mylib\.Debug.*', 'mylib\.Person\.Secret']
+//
+//	[names]
+//	"mylib.Person.Greet" = "hello"
+//
+//	[errors]
+//	"io/fs.ErrNotExist" = "FileNotFoundError"
+//
+// The keys are the options of the commands, without their dash, with
+// lists of regexps, e.g., of exclude, matching any of them, and other
+// lists joined by commas.  The packages are bound when none is given on
+// the command line, and the names table sets the python names of the
+// symbols, by qualified name, as //gopy:name directives, which take
+// precedence.  The errors table maps the Go errors to the python builtin
+// exceptions, as -errors.  The paths are relative to the config file.
+// The options given on the command line take precedence over those of the
+// file, which take precedence over those of -from-directives and -profile.
+// The YAML files are of the same keys, e.g., exclude: [a, b], with the
+// tables as indented mappings, and the lists possibly as indented - items.
+
+// configFiles are the names of the config files looked up in the current
+// directory, without -config
+var configFiles = []string{"gopy.toml", "gopy.yaml", "gopy.yml"}
+
+// configPathOpts are the options of file paths, relative to the config file
+var configPathOpts = map[string]bool{
+	"output": true, "into": true, "extra-go": true, "py-config": true, "stats": true, "report": true, "hooks": true, "templates": true,
+}
+
+// configFile is a parsed config file, mapping its keys to their values:
+// string, bool, int64, []string, or map[string]string for tables
+type configFile map[string]any
+
+// findConfigFile returns the path of the config file of the -config option
+// of the command, or of the current directory, or "" if none
+func findConfigFile(cmdr *commander.Command) string {
+	if f := cmdr.Flag.Lookup("config"); f != nil {
+		if fn := f.Value.Get().(string); fn != "" {
+			return fn
+		}
+	}
+	for _, fn := range configFiles {
+		if _, err := os.Stat(fn); err == nil {
+			return fn
+		}
+	}
+	return ""
+}
+
+// applyConfig sets the flags of the command that are not set on the
+// command line to the options of its config file, if any, and sets the
+// python names of its names table, returning the packages to bind: the
+// args, or those of the file if there are none
+func applyConfig(cmdr *commander.Command, args []string) ([]string, error) {
+	bind.SymbolNames = nil
+	fn := findConfigFile(cmdr)
+	if fn == "" {
+		return args, nil
+	}
+	if f := cmdr.Flag.Lookup("config"); f != nil && f.Value.String() == "" {
+		f.Value.Set(fn) // as used, without setting it explicitly
+	}
+	data, err := os.ReadFile(fn)
+	if err != nil {
+		return nil, fmt.Errorf("gopy: could not read config file: %v", err)
+	}
+	var cf configFile
+	switch filepath.Ext(fn) {
+	case ".yaml", ".yml":
+		cf, err = parseYAMLConfig(data)
+	default:
+		cf, err = parseTOMLConfig(data)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("gopy: %s: %v", fn, err)
+	}
+	dir := filepath.Dir(fn)
+
+	explicit := make(map[string]bool)
+	cmdr.Flag.Visit(func(f *flag.Flag) {
+		explicit[f.Name] = true
+	})
+	keys := make([]string, 0, len(cf))
+	for key := range cf {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		val := cf[key]
+		switch key {
+		case "packages":
+			pkgs, ok := configList(val)
+			if !ok {
+				return nil, fmt.Errorf("gopy: %s: packages must be a list of packages", fn)
+			}
+			if len(args) == 0 {
+				for _, p := range pkgs {
+					if strings.HasPrefix(p, "./") || strings.HasPrefix(p, "../") {
+						p = "./" + filepath.ToSlash(filepath.Join(dir, p))
+					}
+					args = append(args, p)
+				}
+			}
+			continue
+		case "names":
+			names, ok := val.(map[string]string)
+			if !ok {
+				return nil, fmt.Errorf("gopy: %s: names must be a table of python names", fn)
+			}
+			bind.SymbolNames = names
+			continue
+		case "errors":
+			if errs, ok := val.(map[string]string); ok {
+				var maps []string
+				for goerr, exc := range errs {
+					maps = append(maps, goerr+"="+exc)
+				}
+				sort.Strings(maps)
+				val = strings.Join(maps, ",")
+			}
+		case "config":
+			return nil, fmt.Errorf("gopy: %s: invalid option config in a config file", fn)
+		}
+
+		f := cmdr.Flag.Lookup(key)
+		if f == nil {
+			if isBindOption(key) { // of another command
+				continue
+			}
+			return nil, fmt.Errorf("gopy: %s: unknown option %q", fn, key)
+		}
+		if explicit[key] {
+			continue
+		}
+		sval, err := configValue(f, val)
+		if err != nil {
+			return nil, fmt.Errorf("gopy: %s: option %s: %v", fn, key, err)
+		}
+		if configPathOpts[key] && sval != "" && !filepath.IsAbs(sval) {
+			sval = filepath.Join(dir, sval)
+		}
+		if err := cmdr.Flag.Set(key, sval); err != nil {
+			return nil, fmt.Errorf("gopy: %s: invalid option %s=%s: %v", fn, key, sval, err)
+		}
+	}
+	return args, nil
+}
+
+// isBindOption returns whether the option is one of gen, build, pkg or exe
+func isBindOption(name string) bool {
+	for _, mk := range []func() *commander.Command{gopyMakeCmdGen, gopyMakeCmdBuild, gopyMakeCmdPkg, gopyMakeCmdExe} {
+		if mk().Flag.Lookup(name) != nil {
+			return true
+		}
+	}
+	return false
+}
+
+// configList returns the value as a list of strings, a string being a
+// list of one
+func configList(val any) ([]string, bool) {
+	switch v := val.(type) {
+	case string:
+		return []string{v}, true
+	case []string:
+		return v, true
+	}
+	return nil, false
+}
+
+// configValue returns the value of the config file as the string value of
+// the flag, checking its type
+func configValue(f *flag.Flag, val any) (string, error) {
+	switch f.Value.Get().(type) {
+	case bool:
+		if b, ok := val.(bool); ok {
+			return strconv.FormatBool(b), nil
+		}
+		return "", fmt.Errorf("must be true or false")
+	case int:
+		if n, ok := val.(int64); ok {
+			return strconv.FormatInt(n, 10), nil
+		}
+		return "", fmt.Errorf("must be an integer")
+	}
+	switch v := val.(type) {
+	case string:
+		return v, nil
+	case []string:
+		if strings.HasPrefix(f.Usage, "regexp") {
+			alts := make([]string, len(v))
+			for i, re := range v {
+				alts[i] = "(?:" + re + ")"
+			}
+			return strings.Join(alts, "|"), nil
+		}
+		return strings.Join(v, ","), nil
+	case int64:
+		return strconv.FormatInt(v, 10), nil
+	}
+	return "", fmt.Errorf("must be a string")
+}
+
+// parseTOMLConfig parses a config file in the subset of TOML of the
+// config files: key = value lines, of strings, booleans, integers and
+// arrays of strings, possibly spanning several lines, and [names] and
+// [errors] tables of strings
+func parseTOMLConfig(data []byte) (configFile, error) {
+	cf := make(configFile)
+	table := ""
+	sc := bufio.NewScanner(bytes.NewReader(data))
+	for ln := 1; sc.Scan(); ln++ {
+		line := strings.TrimSpace(stripComment(sc.Text()))
+		if line == "" {
+			continue
+		}
+		if strings.HasPrefix(line, "[") && strings.HasSuffix(line, "]") && !strings.Contains(line, "=") {
+			table = strings.TrimSpace(line[1 : len(line)-1])
+			if table != "names" && table != "errors" {
+				return nil, fmt.Errorf("line %d: unknown table [%s]", ln, table)
+			}
+			if _, ok := cf[table]; ok {
+				return nil, fmt.Errorf("line %d: duplicate table [%s]", ln, table)
+			}
+			cf[table] = map[string]string{}
+			continue
+		}
+		key, sval, ok := strings.Cut(line, "=")
+		if !ok {
+			return nil, fmt.Errorf("line %d: expected key = value", ln)
+		}
+		k, err := configScalar(strings.TrimSpace(key))
+		if err != nil {
+			return nil, fmt.Errorf("line %d: invalid key: %v", ln, err)
+		}
+		key = fmt.Sprint(k)
+		sval = strings.TrimSpace(sval)
+		start := ln
+		for strings.HasPrefix(sval, "[") && !strings.HasSuffix(sval, "]") && sc.Scan() {
+			ln++
+			sval += " " + strings.TrimSpace(stripComment(sc.Text()))
+		}
+		val, err := configParseValue(sval)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %s: %v", start, key, err)
+		}
+		if err := configSet(cf, table, key, val); err != nil {
+			return nil, fmt.Errorf("line %d: %v", start, err)
+		}
+	}
+	return cf, sc.Err()
+}
+
+// parseYAMLConfig parses a config file in the subset of YAML of the
+// config files: key: value lines, of the values of the TOML files, with
+// lists of indented - items, and names and errors mappings of indented
+// key: value lines
+func parseYAMLConfig(data []byte) (configFile, error) {
+	cf := make(configFile)
+	key := "" // of the indented lines
+	sc := bufio.NewScanner(bytes.NewReader(data))
+	for ln := 1; sc.Scan(); ln++ {
+		raw := stripComment(sc.Text())
+		line := strings.TrimSpace(raw)
+		if line == "" || line == "---" {
+			continue
+		}
+		indented := raw[0] == ' ' || raw[0] == '\t'
+		if indented {
+			if key == "" {
+				return nil, fmt.Errorf("line %d: unexpected indentation", ln)
+			}
+			if strings.HasPrefix(line, "- ") {
+				val, err := configScalar(strings.TrimSpace(line[2:]))
+				if err != nil {
+					return nil, fmt.Errorf("line %d: %s: %v", ln, key, err)
+				}
+				s, ok := val.(string)
+				if !ok {
+					return nil, fmt.Errorf("line %d: %s: lists must be of strings", ln, key)
+				}
+				lst, _ := cf[key].([]string)
+				if _, isMap := cf[key].(map[string]string); isMap {
+					return nil, fmt.Errorf("line %d: %s: mixed list and mapping", ln, key)
+				}
+				cf[key] = append(lst, s)
+				continue
+			}
+			k, v, err := yamlKeyValue(line)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %v", ln, err)
+			}
+			if err := configSet(cf, key, k, v); err != nil {
+				return nil, fmt.Errorf("line %d: %v", ln, err)
+			}
+			continue
+		}
+		k, v, err := yamlKeyValue(line)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %v", ln, err)
+		}
+		if v == nil { // indented list or mapping to follow
+			if _, ok := cf[k]; ok {
+				return nil, fmt.Errorf("line %d: duplicate key %s", ln, k)
+			}
+			key = k
+			if k == "names" || k == "errors" {
+				cf[k] = map[string]string{}
+			}
+			continue
+		}
+		key = ""
+		if err := configSet(cf, "", k, v); err != nil {
+			return nil, fmt.Errorf("line %d: %v", ln, err)
+		}
+	}
+	return cf, sc.Err()
+}
+
+// yamlKeyValue parses a key: value line of a YAML config file, the value
+// being nil if there is none
+func yamlKeyValue(line string) (string, any, error) {
+	i := 0
+	if line[0] == '"' || line[0] == '\'' { // quoted key, possibly with colons
+		end := strings.IndexByte(line[1:], line[0])
+		if end < 0 {
+			return "", nil, fmt.Errorf("unterminated quoted key")
+		}
+		i = end + 2
+	}
+	sep := strings.Index(line[i:], ":")
+	if sep < 0 {
+		return "", nil, fmt.Errorf("expected key: value")
+	}
+	k, err := configScalar(strings.TrimSpace(line[:i+sep]))
+	if err != nil {
+		return "", nil, fmt.Errorf("invalid key: %v", err)
+	}
+	key := fmt.Sprint(k)
+	sval := strings.TrimSpace(line[i+sep+1:])
+	if sval == "" {
+		return key, nil, nil
+	}
+	val, err := configParseValue(sval)
+	if err != nil {
+		return "", nil, fmt.Errorf("%s: %v", key, err)
+	}
+	return key, val, nil
+}
+
+// configSet sets the key of the table of the config file, or of its top
+// level for "", to the value
+func configSet(cf configFile, table, key string, val any) error {
+	if table == "" {
+		if _, ok := cf[key]; ok {
+			return fmt.Errorf("duplicate key %s", key)
+		}
+		cf[key] = val
+		return nil
+	}
+	tbl, ok := cf[table].(map[string]string)
+	if !ok {
+		return fmt.Errorf("%s is not a table", table)
+	}
+	s, ok := val.(string)
+	if !ok {
+		return fmt.Errorf("%s.%s must be a string", table, key)
+	}
+	tbl[key] = s
+	return nil
+}
+
+// configParseValue parses a value of a config file: a scalar or a
+// bracketed list of strings
+func configParseValue(sval string) (any, error) {
+	if !strings.HasPrefix(sval, "[") {
+		return configScalar(sval)
+	}
+	if !strings.HasSuffix(sval, "]") {
+		return nil, fmt.Errorf("unterminated list")
+	}
+	lst := []string{}
+	for _, item := range splitConfigList(sval[1 : len(sval)-1]) {
+		if item = strings.TrimSpace(item); item == "" {
+			continue
+		}
+		val, err := configScalar(item)
+		if err != nil {
+			return nil, err
+		}
+		s, ok := val.(string)
+		if !ok {
+			return nil, fmt.Errorf("lists must be of strings")
+		}
+		lst = append(lst, s)
+	}
+	return lst, nil
+}
+
+// configScalar parses a scalar value of a config file: a double-quoted
+// string with Go escapes, a single-quoted literal string, true, false, an
+// integer, or else a bare string
+func configScalar(s string) (any, error) {
+	switch {
+	case s == "":
+		return "", nil
+	case s[0] == '"':
+		return strconv.Unquote(s)
+	case s[0] == '\'':
+		if len(s) < 2 || s[len(s)-1] != '\'' {
+			return nil, fmt.Errorf("unterminated string %s", s)
+		}
+		return s[1 : len(s)-1], nil
+	case s == "true":
+		return true, nil
+	case s == "false":
+		return false, nil
+	}
+	if n, err := strconv.ParseInt(s, 10, 64); err == nil {
+		return n, nil
+	}
+	return s, nil
+}
+
+// splitConfigList splits the items of a list at the commas outside quotes
+func splitConfigList(s string) []string {
+	var items []string
+	var quote byte
+	start := 0
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case quote != 0:
+			if c == '\\' && quote == '"' {
+				i++
+			} else if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			quote = c
+		case c == ',':
+			items = append(items, s[start:i])
+			start = i + 1
+		}
+	}
+	return append(items, s[start:])
+}
+
+// stripComment returns the line without its # comment, outside quotes
+func stripComment(line string) string {
+	var quote byte
+	for i := 0; i < len(line); i++ {
+		c := line[i]
+		switch {
+		case quote != 0:
+			if c == '\\' && quote == '"' {
+				i++
+			} else if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			quote = c
+		case c == '#':
+			return line[:i]
+		}
+	}
+	return line
+}
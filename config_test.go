@@ -0,0 +1,149 @@
+// Copyright 2024 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/go-python/gopy/bind"
+)
+
+func TestApplyConfig(t *testing.T) {
+	defer func() { bind.SymbolNames = nil }()
+	dir := t.TempDir()
+	for _, tc := range []struct {
+		file, data string
+	}{
+		{"gopy.toml", `# bindings of mylib
+packages = [
+	"./mylib", # the main package
+	"example.com/other",
+]
+name = "mylib"
+vm = "python3"
+output = "out"
+rename = false
+copy-max = 10
+profile = "standard"
+exclude = ['mylib\.Debug.*', "mylib\\.Person\\.Secret"]
+
+[names]
+"mylib.Person.Greet" = "hello"
+
+[errors]
+"io.EOF" = "EOFError"
+"io/fs.ErrNotExist" = "FileNotFoundError"
+`},
+		{"gopy.yaml", `# bindings of mylib
+packages:
+  - ./mylib
+  - example.com/other
+name: mylib
+vm: python3
+output: out
+rename: false
+copy-max: 10
+profile: standard
+exclude: ['mylib\.Debug.*', "mylib\\.Person\\.Secret"]
+names:
+  mylib.Person.Greet: hello
+errors:
+  io.EOF: EOFError
+  "io/fs.ErrNotExist": FileNotFoundError
+`},
+	} {
+		fn := filepath.Join(dir, tc.file)
+		if err := os.WriteFile(fn, []byte(tc.data), 0644); err != nil {
+			t.Fatal(err)
+		}
+		cmd := gopyMakeCmdBuild()
+		if err := cmd.Flag.Parse([]string{"-config=" + fn, "-vm=python3.12"}); err != nil {
+			t.Fatal(err)
+		}
+		args, err := applyConfig(cmd, cmd.Flag.Args())
+		if err != nil {
+			t.Fatalf("%s: %v", tc.file, err)
+		}
+		want := []string{"./" + filepath.ToSlash(filepath.Join(dir, "mylib")), "example.com/other"}
+		if !reflect.DeepEqual(args, want) {
+			t.Errorf("%s: packages: got %q, want %q", tc.file, args, want)
+		}
+		for opt, want := range map[string]any{
+			"name":     "mylib",
+			"vm":       "python3.12", // of the command line
+			"output":   filepath.Join(dir, "out"),
+			"rename":   false,
+			"copy-max": 10,
+			"profile":  "standard",
+			"exclude":  `(?:mylib\.Debug.*)|(?:mylib\.Person\.Secret)`,
+			"errors":   "io.EOF=EOFError,io/fs.ErrNotExist=FileNotFoundError",
+		} {
+			if got := cmd.Flag.Lookup(opt).Value.Get(); got != want {
+				t.Errorf("%s: -%s: got %v, want %v", tc.file, opt, got, want)
+			}
+		}
+		if got := bind.SymbolNames["mylib.Person.Greet"]; got != "hello" {
+			t.Errorf("%s: name of mylib.Person.Greet: got %q, want hello", tc.file, got)
+		}
+
+		// the options of the file take precedence over those of the profile
+		if err := applyProfile(cmd); err != nil {
+			t.Fatal(err)
+		}
+		if cmd.Flag.Lookup("rename").Value.Get().(bool) {
+			t.Errorf("%s: -rename of the file overridden by the profile", tc.file)
+		}
+
+		// the packages of the command line take precedence
+		cmd = gopyMakeCmdBuild()
+		if err := cmd.Flag.Parse([]string{"-config=" + fn, "./cli"}); err != nil {
+			t.Fatal(err)
+		}
+		args, err = applyConfig(cmd, cmd.Flag.Args())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(args, []string{"./cli"}) {
+			t.Errorf("%s: packages of the command line: got %q", tc.file, args)
+		}
+	}
+
+	for _, bad := range []string{
+		"fancy = true\n",
+		"rename = \"yes\"\n",
+		"copy-max = many\n",
+		"[tables]\n",
+		"rename = true\nrename = false\n",
+		"exclude = ['a'\n",
+	} {
+		fn := filepath.Join(dir, "bad.toml")
+		if err := os.WriteFile(fn, []byte(bad), 0644); err != nil {
+			t.Fatal(err)
+		}
+		cmd := gopyMakeCmdGen()
+		if err := cmd.Flag.Parse([]string{"-config=" + fn, "."}); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := applyConfig(cmd, cmd.Flag.Args()); err == nil {
+			t.Errorf("no error for the config file %q", bad)
+		}
+	}
+
+	// the options of other commands are ignored
+	fn := filepath.Join(dir, "pkg.toml")
+	if err := os.WriteFile(fn, []byte("author = \"me\"\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	cmd := gopyMakeCmdGen()
+	if err := cmd.Flag.Parse([]string{"-config=" + fn, "."}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := applyConfig(cmd, cmd.Flag.Args()); err != nil {
+		t.Errorf("option of pkg in the config file of gen: %v", err)
+	}
+}
@@ -0,0 +1,71 @@
+// Copyright 2024 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"fmt"
+	"os"
+	"runtime"
+
+	"github.com/go-python/gopy/bind"
+)
+
+// targetLibExt returns the extension of the shared libraries of the target
+// GOOS, that of the host if empty
+func targetLibExt(goos string) string {
+	switch {
+	case goos == "" || goos == runtime.GOOS:
+		return libExt
+	case goos == "windows":
+		return ".pyd"
+	}
+	return ".so"
+}
+
+// targetGccArgs returns the extra args of the C compiler linking the shared
+// libraries of the target GOOS, those of the host if empty
+func targetGccArgs(goos string) string {
+	switch {
+	case goos == "" || goos == runtime.GOOS:
+		return extraGccArgs
+	case goos == "darwin":
+		return "-dynamiclib"
+	}
+	return ""
+}
+
+// setCrossTarget sets up the cross build of the bindings for the target GOOS
+// and GOARCH of cfg, if other than those of the host: the go commands are run
+// with the GOOS, GOARCH and CGO_ENABLED env vars of the target, loading and
+// building the packages for it, and with its C cross compiler, from the CC
+// env var, or the usual one of the target.  It returns a func restoring the
+// env vars.
+func setCrossTarget(cfg *BuildCfg) func() {
+	if !cfg.CrossBuild() {
+		return func() {}
+	}
+	goos, goarch := cfg.TargetOS(), cfg.TargetArch()
+	restores := []func(){restoreEnv("GOOS"), restoreEnv("GOARCH"), restoreEnv("CGO_ENABLED"), restoreEnv("CC")}
+	windows := bind.WindowsOS
+	os.Setenv("GOOS", goos)
+	os.Setenv("GOARCH", goarch)
+	os.Setenv("CGO_ENABLED", "1")
+	if _, ok := os.LookupEnv("CC"); !ok {
+		if cc := bind.CrossCC(goos, goarch); cc != "" {
+			os.Setenv("CC", cc)
+		}
+	}
+	bind.WindowsOS = goos == "windows"
+	if cfg.PyConfig == "" && !cfg.NoWarn {
+		fmt.Printf("gopy: warning: cross-building for %s/%s with the python config of %s, "+
+			"-py-config should give that of the target python\n", goos, goarch, cfg.VM)
+	}
+	return func() {
+		for _, restore := range restores {
+			restore()
+		}
+		bind.WindowsOS = windows
+	}
+}
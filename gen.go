@@ -5,8 +5,11 @@
 package main
 
 import (
+	"bytes"
+	"encoding/json"
 	"fmt"
 	"go/ast"
+	"go/build"
 	"go/doc"
 	"go/parser"
 	"go/token"
@@ -14,7 +17,9 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
+	"sort"
 	"strings"
+	"time"
 
 	"github.com/pkg/errors"
 	"golang.org/x/tools/go/packages"
@@ -67,6 +72,12 @@ func genPkg(mode bind.BuildMode, cfg *BuildCfg) error {
 	if err != nil {
 		return err
 	}
+	if cfg.PythonVersion != "" {
+		cfg.VM, err = standalonePython(cfg.PythonVersion)
+		if err != nil {
+			return err
+		}
+	}
 	if !filepath.IsAbs(cfg.VM) {
 		cfg.VM, err = exec.LookPath(cfg.VM)
 		if err != nil {
@@ -78,14 +89,65 @@ func genPkg(mode bind.BuildMode, cfg *BuildCfg) error {
 	if err != nil {
 		return err
 	}
-	err = bind.GenPyBind(mode, libExt, extraGccArgs, pyvers, cfg.DynamicLinking, &cfg.BindCfg)
+	defer bind.StatPhase("generate", time.Now())
+	err = bind.GenPyBind(mode, targetLibExt(cfg.GOOS), targetGccArgs(cfg.GOOS), pyvers, cfg.DynamicLinking, &cfg.BindCfg)
 	if err != nil {
 		log.Println(err)
 	}
 	return err
 }
 
+// statsPath returns the absolute path of the -stats or -report file, if
+// any, as the commands change their working dir
+func statsPath(fn string) string {
+	if fn == "" {
+		return ""
+	}
+	if abs, err := filepath.Abs(fn); err == nil {
+		return abs
+	}
+	return fn
+}
+
+// reportStats prints the statistics of the generation, and writes them as
+// JSON to the StatsFile, if any, and the coverage report to the ReportFile,
+// if any, printing its diagnostics
+func reportStats(cfg *BuildCfg) error {
+	st := bind.GetStats(cfg.Name)
+	fmt.Printf("\n%s", st)
+	if cfg.StatsFile != "" {
+		data, err := json.MarshalIndent(st, "", "  ")
+		if err != nil {
+			return err
+		}
+		if err := os.WriteFile(cfg.StatsFile, append(data, '\n'), 0644); err != nil {
+			return err
+		}
+	}
+	if cfg.ReportFile == "" {
+		return nil
+	}
+	rp := bind.GetReport(cfg.Name)
+	for _, d := range rp.Diagnostics() {
+		fmt.Println(d)
+	}
+	fmt.Printf("coverage: %.1f%% (%d bound, %d skipped), report written to %s\n", 100*rp.Coverage, rp.Bound, rp.Skipped, cfg.ReportFile)
+	if strings.HasSuffix(cfg.ReportFile, ".html") {
+		var buf bytes.Buffer
+		if err := rp.WriteHTML(&buf); err != nil {
+			return err
+		}
+		return os.WriteFile(cfg.ReportFile, buf.Bytes(), 0644)
+	}
+	data, err := json.MarshalIndent(rp, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(cfg.ReportFile, append(data, '\n'), 0644)
+}
+
 func loadPackage(path string, buildFirst bool, buildTags string) (*packages.Package, error) {
+	defer bind.StatPhase("load", time.Now())
 	cwd, err := os.Getwd()
 	if err != nil {
 		return nil, err
@@ -129,7 +191,114 @@ func loadPackage(path string, buildFirst bool, buildTags string) (*packages.Pack
 	return bpkg, nil
 }
 
+// expandPackages expands the package args ending in /... to all the
+// packages matching them, and, if recursive, adds all the dependencies
+// of the packages within the same module, in order of increasing
+// dependency, as needed for binding them into one output package.
+// It also returns the default name of the output package: the name of
+// the first package arg, or the last element of its path for patterns.
+func expandPackages(args []string, recursive bool, buildTags string) ([]string, string, error) {
+	name := ""
+	if len(args) > 0 && strings.HasSuffix(args[0], "/...") {
+		root := strings.TrimSuffix(args[0], "/...")
+		if build.IsLocalImport(root) {
+			root, _ = filepath.Abs(root)
+		}
+		name = filepath.Base(root)
+	}
+
+	pattern := false
+	for _, path := range args {
+		if strings.Contains(path, "...") {
+			pattern = true
+		}
+	}
+	if !pattern && !recursive {
+		return args, name, nil
+	}
+
+	cfg := &packages.Config{Mode: packages.NeedName | packages.NeedFiles | packages.NeedImports | packages.NeedDeps | packages.NeedModule}
+	if buildTags != "" {
+		cfg.BuildFlags = []string{"-tags", strings.Join(strings.Split(buildTags, ","), " ")}
+	}
+	bpkgs, err := packages.Load(cfg, args...)
+	if err != nil {
+		return nil, "", fmt.Errorf("gopy: could not resolve packages %v: %v", args, err)
+	}
+
+	var paths []string
+	names := make(map[string]string) // package name -> path
+	done := make(map[string]bool)
+	var add func(bpkg *packages.Package) error
+	add = func(bpkg *packages.Package) error {
+		if done[bpkg.PkgPath] {
+			return nil
+		}
+		done[bpkg.PkgPath] = true
+		if recursive {
+			imps := make([]string, 0, len(bpkg.Imports))
+			for ip := range bpkg.Imports {
+				imps = append(imps, ip)
+			}
+			sort.Strings(imps)
+			for _, ip := range imps {
+				imp := bpkg.Imports[ip]
+				if imp.Module == nil || bpkg.Module == nil || imp.Module.Path != bpkg.Module.Path || isInternalPath(ip) {
+					continue
+				}
+				if err := add(imp); err != nil {
+					return err
+				}
+			}
+		}
+		if bpkg.Name == "main" || len(bpkg.GoFiles) == 0 {
+			return nil
+		}
+		if other, has := names[bpkg.Name]; has {
+			return fmt.Errorf("gopy: packages %q and %q have the same name %q, which must be unique in the output package", other, bpkg.PkgPath, bpkg.Name)
+		}
+		names[bpkg.Name] = bpkg.PkgPath
+		paths = append(paths, bpkg.PkgPath)
+		return nil
+	}
+	for i, bpkg := range bpkgs {
+		if i == 0 && name == "" {
+			name = bpkg.Name
+		}
+		if err := add(bpkg); err != nil {
+			return nil, "", err
+		}
+	}
+	if len(paths) == 0 {
+		return nil, "", fmt.Errorf("gopy: no packages to bind in %v", args)
+	}
+	return paths, name, nil
+}
+
+// isInternalPath returns whether the package path is an internal package,
+// which cannot be imported by the generated code
+func isInternalPath(path string) bool {
+	return strings.HasSuffix(path, "/internal") || strings.Contains(path, "/internal/")
+}
+
+// addInternalRoots records the directories of the trees allowed to import
+// the internal packages imported by the package, in bind.InternalRoots
+func addInternalRoots(bpkg *packages.Package) {
+	packages.Visit([]*packages.Package{bpkg}, nil, func(ip *packages.Package) {
+		if !isInternalPath(ip.PkgPath) || len(ip.GoFiles) == 0 {
+			return
+		}
+		if _, ok := bind.InternalRoots[ip.PkgPath]; ok {
+			return
+		}
+		suffix := ip.PkgPath[strings.LastIndex(ip.PkgPath+"/", "/internal/"):]
+		dir := filepath.Dir(ip.GoFiles[0])
+		bind.InternalRoots[ip.PkgPath] = strings.TrimSuffix(dir, filepath.FromSlash(suffix))
+	})
+}
+
 func parsePackage(bpkg *packages.Package) (*bind.Package, error) {
+	defer bind.StatPhase("load", time.Now())
 	if len(bpkg.GoFiles) == 0 {
 		err := fmt.Errorf("gopy: no files in package %q", bpkg.PkgPath)
 		fmt.Println(err)
@@ -137,6 +306,8 @@ func parsePackage(bpkg *packages.Package) (*bind.Package, error) {
 	}
 	dir, _ := filepath.Split(bpkg.GoFiles[0])
 	p := bpkg.Types
+	addInternalRoots(bpkg)
+	bind.FileSets[bpkg.PkgPath] = bpkg.Fset
 
 	if bpkg.Name == "main" {
 		err := fmt.Errorf("gopy: skipping 'main' package %q", bpkg.PkgPath)
@@ -155,6 +326,6 @@ func parsePackage(bpkg *packages.Package) (*bind.Package, error) {
 		return nil, fmt.Errorf("gopy: could not find AST for package %q", p.Name())
 	}
 
-	pkgdoc := doc.New(pkgast, bpkg.PkgPath, 0)
+	pkgdoc := doc.New(pkgast, bpkg.PkgPath, doc.PreserveAST) // keep the doc comments with directives in the AST
 	return bind.NewPackage(p, pkgdoc)
 }
@@ -0,0 +1,163 @@
+// Copyright 2024 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path"
+	"strconv"
+	"strings"
+
+	"github.com/gonuts/commander"
+	"github.com/gonuts/flag"
+	"golang.org/x/tools/go/packages"
+)
+
+// The author of a Go package can set the canonical options of its bindings
+// in a go:generate directive of the package, e.g.,
+//
+//	//go:generate gopy gen -name=mylib -rename .
+//
+// which the -from-directives option of gen and build picks up, so that
+// third-party builds of the bindings are reproducible.  The options given
+// on the command line take precedence over those of the directive.
+
+// generatePrefix starts the go:generate directive lines
+const generatePrefix = "//go:generate "
+
+// splitGenerateArgs splits the args of a go:generate directive at spaces,
+// except within double-quoted Go strings, as go generate does
+func splitGenerateArgs(line string) ([]string, error) {
+	var args []string
+	line = strings.TrimSpace(line)
+	for line != "" {
+		if line[0] == '"' {
+			end := 1
+			for ; end < len(line); end++ {
+				if line[end] == '\\' {
+					end++
+					continue
+				}
+				if line[end] == '"' {
+					break
+				}
+			}
+			if end >= len(line) {
+				return nil, fmt.Errorf("unterminated quoted string in %q", line)
+			}
+			arg, err := strconv.Unquote(line[:end+1])
+			if err != nil {
+				return nil, fmt.Errorf("invalid quoted string %q: %v", line[:end+1], err)
+			}
+			args = append(args, arg)
+			line = strings.TrimLeft(line[end+1:], " \t")
+			continue
+		}
+		i := strings.IndexAny(line, " \t")
+		if i < 0 {
+			i = len(line)
+		}
+		args = append(args, line[:i])
+		line = strings.TrimLeft(line[i:], " \t")
+	}
+	return args, nil
+}
+
+// gopyGenArgs returns the args after gen of a gopy gen command, run as
+// gopy, a path to it, or go run of its package, and whether it is one
+func gopyGenArgs(args []string) ([]string, bool) {
+	for i := 0; i+1 < len(args); i++ {
+		cmd, _, _ := strings.Cut(args[i], "@")
+		if path.Base(cmd) == "gopy" && args[i+1] == "gen" {
+			return args[i+2:], true
+		}
+	}
+	return nil, false
+}
+
+// generateArgs returns the args of the first gopy gen go:generate directive
+// of the files, and whether there is one
+func generateArgs(files []string) ([]string, bool, error) {
+	for _, fn := range files {
+		f, err := os.Open(fn)
+		if err != nil {
+			return nil, false, err
+		}
+		sc := bufio.NewScanner(f)
+		for sc.Scan() {
+			line := sc.Text()
+			if !strings.HasPrefix(line, generatePrefix) {
+				continue
+			}
+			args, err := splitGenerateArgs(line[len(generatePrefix):])
+			if err != nil {
+				f.Close()
+				return nil, false, fmt.Errorf("gopy: %s: %v", fn, err)
+			}
+			if gargs, ok := gopyGenArgs(args); ok {
+				f.Close()
+				return gargs, true, nil
+			}
+		}
+		err = sc.Err()
+		f.Close()
+		if err != nil {
+			return nil, false, err
+		}
+	}
+	return nil, false, nil
+}
+
+// applyGenerateOptions sets the flags of the command that are not set on
+// the command line to the options of the gopy gen go:generate directive of
+// the package, if it has one
+func applyGenerateOptions(cmdr *commander.Command, pkgPath, buildTags string) error {
+	cfg := &packages.Config{Mode: packages.NeedName | packages.NeedFiles}
+	if buildTags != "" {
+		cfg.BuildFlags = []string{"-tags", buildTags}
+	}
+	pkgs, err := packages.Load(cfg, pkgPath)
+	if err != nil {
+		return fmt.Errorf("gopy: could not load package %q for its go:generate directives: %v", pkgPath, err)
+	}
+	if len(pkgs) != 1 {
+		return fmt.Errorf("gopy: -from-directives needs a single package, %q matches %d", pkgPath, len(pkgs))
+	}
+	args, ok, err := generateArgs(pkgs[0].GoFiles)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return fmt.Errorf("gopy: package %s has no //go:generate gopy gen directive", pkgs[0].PkgPath)
+	}
+
+	// parse the options with the flags of gen, typed as in the command
+	fs := flag.NewFlagSet("go:generate gopy gen", flag.ContinueOnError)
+	gopyMakeCmdGen().Flag.VisitAll(func(f *flag.Flag) {
+		if _, isBool := f.Value.Get().(bool); isBool {
+			fs.Bool(f.Name, false, f.Usage)
+		} else {
+			fs.String(f.Name, "", f.Usage)
+		}
+	})
+	if err := fs.Parse(args); err != nil {
+		return fmt.Errorf("gopy: invalid go:generate directive of package %s: %v", pkgs[0].PkgPath, err)
+	}
+
+	explicit := make(map[string]bool)
+	cmdr.Flag.Visit(func(f *flag.Flag) {
+		explicit[f.Name] = true
+	})
+	var serr error
+	fs.Visit(func(f *flag.Flag) {
+		if explicit[f.Name] || f.Name == "from-directives" || cmdr.Flag.Lookup(f.Name) == nil || serr != nil {
+			return
+		}
+		serr = cmdr.Flag.Set(f.Name, f.Value.String())
+	})
+	return serr
+}
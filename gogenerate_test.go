@@ -0,0 +1,72 @@
+// Copyright 2024 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSplitGenerateArgs(t *testing.T) {
+	for _, tc := range []struct {
+		line string
+		want []string
+	}{
+		{"gopy gen -rename .", []string{"gopy", "gen", "-rename", "."}},
+		{"  gopy\tgen  -name=x ./pkg ", []string{"gopy", "gen", "-name=x", "./pkg"}},
+		{`gopy gen -main "fmt.Println(\"hi there\")" .`, []string{"gopy", "gen", "-main", `fmt.Println("hi there")`, "."}},
+	} {
+		got, err := splitGenerateArgs(tc.line)
+		if err != nil {
+			t.Errorf("%q: %v", tc.line, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("%q: got %q, want %q", tc.line, got, tc.want)
+		}
+	}
+	if _, err := splitGenerateArgs(`gopy gen -main "x`); err == nil {
+		t.Errorf("no error for an unterminated string")
+	}
+}
+
+func TestGopyGenArgs(t *testing.T) {
+	for _, tc := range []struct {
+		args []string
+		want []string
+		ok   bool
+	}{
+		{[]string{"gopy", "gen", "-rename", "."}, []string{"-rename", "."}, true},
+		{[]string{"go", "run", "github.com/go-python/gopy@v0.4.10", "gen", "."}, []string{"."}, true},
+		{[]string{"/usr/local/bin/gopy", "gen"}, []string{}, true},
+		{[]string{"gopy", "build", "."}, nil, false},
+		{[]string{"stringer", "-type=Kind"}, nil, false},
+	} {
+		got, ok := gopyGenArgs(tc.args)
+		if ok != tc.ok || (ok && !reflect.DeepEqual(got, tc.want)) {
+			t.Errorf("%q: got %q, %v, want %q, %v", tc.args, got, ok, tc.want, tc.ok)
+		}
+	}
+}
+
+func TestApplyGenerateOptions(t *testing.T) {
+	cmd := gopyMakeCmdGen()
+	if err := cmd.Flag.Parse([]string{"-no-warn", "-from-directives", "."}); err != nil {
+		t.Fatal(err)
+	}
+	if err := applyGenerateOptions(cmd, "./_examples/rename", ""); err != nil {
+		t.Fatal(err)
+	}
+	if !cmd.Flag.Lookup("rename").Value.Get().(bool) {
+		t.Errorf("-rename of the directive not set")
+	}
+	if !cmd.Flag.Lookup("no-warn").Value.Get().(bool) {
+		t.Errorf("-no-warn of the command line overridden by the directive")
+	}
+
+	if err := applyGenerateOptions(gopyMakeCmdGen(), "./_examples/hi", ""); err == nil {
+		t.Errorf("no error for a package without a directive")
+	}
+}
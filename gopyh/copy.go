@@ -0,0 +1,86 @@
+// Copyright 2024 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gopyh
+
+import (
+	"reflect"
+)
+
+// DeepCopy returns a deep copy of the value, copying the values pointed to,
+// the elements of slices and maps and the exported fields of structs.
+// Pointers to the same value are copied to pointers to the same copy, as
+// python's copy.deepcopy does, so cycles are preserved.  Unexported fields,
+// channels and functions are copied shallowly, sharing their values.
+func DeepCopy(v interface{}) interface{} {
+	if v == nil {
+		return nil
+	}
+	cp := &copier{ptrs: map[uintptr]reflect.Value{}}
+	return cp.copy(reflect.ValueOf(v)).Interface()
+}
+
+// copier deep copies values, keeping track of the copies of the pointers
+type copier struct {
+	ptrs map[uintptr]reflect.Value
+}
+
+func (c *copier) copy(v reflect.Value) reflect.Value {
+	switch v.Kind() {
+	case reflect.Ptr:
+		if v.IsNil() {
+			return v
+		}
+		if p, has := c.ptrs[v.Pointer()]; has {
+			return p
+		}
+		p := reflect.New(v.Type().Elem())
+		c.ptrs[v.Pointer()] = p
+		p.Elem().Set(c.copy(v.Elem()))
+		return p
+	case reflect.Interface:
+		if v.IsNil() {
+			return v
+		}
+		n := reflect.New(v.Type()).Elem()
+		n.Set(c.copy(v.Elem()))
+		return n
+	case reflect.Struct:
+		n := reflect.New(v.Type()).Elem()
+		n.Set(v)
+		for i := 0; i < v.NumField(); i++ {
+			if !v.Type().Field(i).IsExported() {
+				continue
+			}
+			n.Field(i).Set(c.copy(v.Field(i)))
+		}
+		return n
+	case reflect.Array:
+		n := reflect.New(v.Type()).Elem()
+		for i := 0; i < v.Len(); i++ {
+			n.Index(i).Set(c.copy(v.Index(i)))
+		}
+		return n
+	case reflect.Slice:
+		if v.IsNil() {
+			return v
+		}
+		n := reflect.MakeSlice(v.Type(), v.Len(), v.Cap())
+		for i := 0; i < v.Len(); i++ {
+			n.Index(i).Set(c.copy(v.Index(i)))
+		}
+		return n
+	case reflect.Map:
+		if v.IsNil() {
+			return v
+		}
+		n := reflect.MakeMapWithSize(v.Type(), v.Len())
+		iter := v.MapRange()
+		for iter.Next() {
+			n.SetMapIndex(iter.Key(), c.copy(iter.Value()))
+		}
+		return n
+	}
+	return v
+}
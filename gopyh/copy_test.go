@@ -0,0 +1,45 @@
+// Copyright 2024 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gopyh
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestDeepCopy(t *testing.T) {
+	if DeepCopy(nil) != nil {
+		t.Errorf("DeepCopy(nil): expected nil")
+	}
+
+	shared := &prettyNode{Name: "shared"}
+	n := prettyNode{
+		Name:  "n",
+		Vals:  []int{1, 2},
+		Attrs: map[string]interface{}{"a": []string{"x"}, "s": shared},
+		Next:  shared,
+		priv:  3,
+	}
+	c := DeepCopy(n).(prettyNode)
+	if !reflect.DeepEqual(c, n) {
+		t.Fatalf("DeepCopy: expected %#v, actual %#v", n, c)
+	}
+	c.Vals[0] = 10
+	c.Attrs["a"].([]string)[0] = "y"
+	c.Next.Name = "changed"
+	if n.Vals[0] != 1 || n.Attrs["a"].([]string)[0] != "x" || shared.Name != "shared" {
+		t.Errorf("DeepCopy: the copy shares values with the original: %#v", n)
+	}
+	if c.Attrs["s"].(*prettyNode) != c.Next {
+		t.Errorf("DeepCopy: pointers to the same value are not copied to the same copy")
+	}
+
+	cyc := &prettyNode{Name: "cycle"}
+	cyc.Next = cyc
+	cc := DeepCopy(cyc).(*prettyNode)
+	if cc == cyc || cc.Next != cc {
+		t.Errorf("DeepCopy: the cycle is not preserved")
+	}
+}
@@ -18,10 +18,14 @@ package gopyh
 
 import (
 	"fmt"
+	"io"
 	"os"
 	"reflect"
+	"runtime"
+	"sort"
 	"strconv"
 	"sync"
+	"sync/atomic"
 )
 
 // GoHandle is the type for the handle
@@ -30,13 +34,57 @@ type CGoHandle int64
 
 // --- variable handles: all pointers managed via handles ---
 
-var (
+// shard is a shard of the handles, those of the handles equal to its index
+// modulo the number of shards, so that the threads registering and using
+// different handles do not contend for the same lock.
+type shard struct {
 	mu      sync.RWMutex
-	ctr     int64
 	handles map[GoHandle]interface{}
 	counts  map[GoHandle]int64
+}
+
+var (
+	ctr        int64 // the last handle, accessed atomically
+	numHandles int64 // the number of handles in use, accessed atomically
+	shards     = newShards(1)
 )
 
+// newShards returns n empty shards
+func newShards(n int) []*shard {
+	shs := make([]*shard, n)
+	for i := range shs {
+		shs[i] = &shard{handles: make(map[GoHandle]interface{}), counts: make(map[GoHandle]int64)}
+	}
+	return shs
+}
+
+// shardOf returns the shard of the handle
+func shardOf(h GoHandle) *shard {
+	return shards[uint64(h)%uint64(len(shards))]
+}
+
+// SetShards sets the number of shards of the handles, one per CPU
+// (GOMAXPROCS) if n is 0 or less, to reduce the contention of the threads
+// calling into Go simultaneously for the lock of the handles: a single
+// shard, the default, is the fastest with few threads, and a shard per CPU
+// the fastest with many (see BenchmarkRegisterParallel).  It must be called
+// before using any handle, e.g., in an init function, and fails otherwise.
+func SetShards(n int) error {
+	if n <= 0 {
+		n = runtime.GOMAXPROCS(0)
+	}
+	if NumHandles() > 0 {
+		return fmt.Errorf("gopy: cannot set the shards of the handles in use")
+	}
+	shards = newShards(n)
+	return nil
+}
+
+// Shards returns the number of shards of the handles, see SetShards.
+func Shards() int {
+	return len(shards)
+}
+
 // IfaceIsNil returns true if interface or value represented by interface is nil
 func IfaceIsNil(it interface{}) bool {
 	if it == nil {
@@ -103,6 +151,51 @@ func init() {
 	if len(os.Getenv("GOPY_HANDLE_TRACE")) > 0 {
 		trace = true
 	}
+	if n, err := strconv.Atoi(os.Getenv("GOPY_HANDLE_LIMIT")); err == nil {
+		SetHandleLimit(n, WarnHandleLimit)
+	}
+}
+
+// the handle limit, see SetHandleLimit: limitFn is accessed under limitMu,
+// and limit and overLimit, 1 while over the limit, atomically, so that the
+// handles are counted without a global lock
+var (
+	limitMu   sync.Mutex
+	limitFn   func(n int)
+	limit     int64
+	overLimit int32
+)
+
+// SetHandleLimit sets fn to be called with the number of handles in use
+// when it exceeds n, once each time it does, e.g., to alert on leaking
+// handles before running out of memory.  fn is called from Register,
+// without holding the lock of the handles.  A limit of 0 or less, or a
+// nil fn, turns it off.  The GOPY_HANDLE_LIMIT environment variable sets
+// the initial limit, warning with WarnHandleLimit.
+func SetHandleLimit(n int, fn func(n int)) {
+	limitMu.Lock()
+	defer limitMu.Unlock()
+	if fn == nil {
+		n = 0
+	}
+	limitFn = fn
+	atomic.StoreInt64(&limit, int64(n))
+	over := int32(0)
+	if n > 0 && NumHandles() > n {
+		over = 1
+	}
+	atomic.StoreInt32(&overLimit, over)
+}
+
+// WarnHandleLimit prints a warning about the number of handles in use to
+// stderr, as the default handler of SetHandleLimit.
+func WarnHandleLimit(n int) {
+	fmt.Fprintf(os.Stderr, "gopy: warning: %d handles in use, above the limit of %d: are handles leaking?\n", n, HandleLimit())
+}
+
+// HandleLimit returns the handle limit set by SetHandleLimit, 0 if none.
+func HandleLimit() int {
+	return int(atomic.LoadInt64(&limit))
 }
 
 // Register registers a new variable instance.
@@ -110,53 +203,69 @@ func Register(typnm string, ifc interface{}) CGoHandle {
 	if IfaceIsNil(ifc) {
 		return -1
 	}
-	mu.Lock()
-	defer mu.Unlock()
-	if handles == nil {
-		handles = make(map[GoHandle]interface{})
-		counts = make(map[GoHandle]int64)
-	}
-	ctr++
-	hc := ctr
+	hc := atomic.AddInt64(&ctr, 1)
 	ghc := GoHandle(hc)
-	handles[ghc] = ifc
-	counts[ghc] = 0
+	sh := shardOf(ghc)
+	sh.mu.Lock()
+	sh.handles[ghc] = ifc
+	sh.counts[ghc] = 0
+	sh.mu.Unlock()
 	if trace {
 		fmt.Printf("gopy Registered: %s %v %d\n", typnm, ifc, hc)
 	}
+	nh := atomic.AddInt64(&numHandles, 1)
+	if lim := atomic.LoadInt64(&limit); lim > 0 && nh > lim && atomic.CompareAndSwapInt32(&overLimit, 0, 1) {
+		limitMu.Lock()
+		fn := limitFn
+		limitMu.Unlock()
+		if fn != nil {
+			fn(int(nh))
+		}
+	}
 	return CGoHandle(hc)
 }
 
 // DecRef decrements the reference count for the specified handle
 // and removes it if the reference count goes to zero.
 func DecRef(handle CGoHandle) {
+	Release(handle)
+}
+
+// Release decrements the reference count for the specified handle, like
+// DecRef, and reports whether it was removed, e.g., so that its variable
+// can be reused when no other python object refers to it.
+func Release(handle CGoHandle) bool {
 	if handle < 1 {
-		return
-	}
-	mu.Lock()
-	defer mu.Unlock()
-	if handles == nil {
-		return
+		return false
 	}
 	ghc := GoHandle(handle)
-	if _, exists := handles[ghc]; !exists {
-		return
+	sh := shardOf(ghc)
+	sh.mu.Lock()
+	defer sh.mu.Unlock()
+	if _, exists := sh.handles[ghc]; !exists {
+		return false
 	}
-	counts[ghc]--
-	switch cnt := counts[ghc]; {
+	sh.counts[ghc]--
+	switch cnt := sh.counts[ghc]; {
 	case cnt == 0:
-		delete(counts, ghc)
-		delete(handles, ghc)
+		delete(sh.counts, ghc)
+		delete(sh.handles, ghc)
+		nh := atomic.AddInt64(&numHandles, -1)
+		if atomic.LoadInt32(&overLimit) == 1 && nh <= atomic.LoadInt64(&limit) {
+			atomic.StoreInt32(&overLimit, 0)
+		}
 		if trace {
 			fmt.Printf("gopy DecRef: %d\n", handle)
 		}
+		return true
 	case cnt < 0:
-		panic(fmt.Sprintf("gopy DecRef ref count %v for handle: %v, ifc %v", cnt, ghc, handles[ghc]))
+		panic(fmt.Sprintf("gopy DecRef ref count %v for handle: %v, ifc %v", cnt, ghc, sh.handles[ghc]))
 	default:
 		if trace {
 			fmt.Printf("gopy DecRef: %d: %d\n", handle, cnt)
 		}
 	}
+	return false
 }
 
 // IncRef increments the reference count for the specified handle.
@@ -164,13 +273,14 @@ func IncRef(handle CGoHandle) {
 	if handle < 1 {
 		return
 	}
-	mu.Lock()
-	defer mu.Unlock()
 	ghc := GoHandle(handle)
-	if _, exists := counts[ghc]; exists {
-		counts[ghc]++
+	sh := shardOf(ghc)
+	sh.mu.Lock()
+	defer sh.mu.Unlock()
+	if _, exists := sh.counts[ghc]; exists {
+		sh.counts[ghc]++
 		if trace {
-			fmt.Printf("gopy IncRef: %d: %d\n", handle, counts[ghc])
+			fmt.Printf("gopy IncRef: %d: %d\n", handle, sh.counts[ghc])
 		}
 	}
 
@@ -190,9 +300,10 @@ func VarFromHandleTry(h CGoHandle, typnm string) (interface{}, error) {
 	if h < 1 {
 		return nil, fmt.Errorf("gopy: nil handle")
 	}
-	mu.RLock()
-	defer mu.RUnlock()
-	v, has := handles[GoHandle(h)]
+	sh := shardOf(GoHandle(h))
+	sh.mu.RLock()
+	defer sh.mu.RUnlock()
+	v, has := sh.handles[GoHandle(h)]
 	if !has {
 		err := fmt.Errorf("gopy: variable handle not registered: " + strconv.FormatInt(int64(h), 10))
 		// TODO: need to get access to this:
@@ -202,9 +313,98 @@ func VarFromHandleTry(h CGoHandle, typnm string) (interface{}, error) {
 	return v, nil
 }
 
+// Addr returns the address of the variable of the handle if it is a
+// pointer, a map or a slice, identifying the same variable through
+// different handles, e.g., to detect the cycles of self-referential
+// values, and 0 otherwise.
+func Addr(h CGoHandle) uintptr {
+	v, err := VarFromHandleTry(h, "")
+	if err != nil {
+		return 0
+	}
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Ptr, reflect.Map, reflect.Slice:
+		return rv.Pointer()
+	}
+	return 0
+}
+
 // NumHandles returns the number of handles in use.
 func NumHandles() int {
-	mu.RLock()
-	defer mu.RUnlock()
-	return len(handles)
+	return int(atomic.LoadInt64(&numHandles))
+}
+
+// HandleStat is the number of handles of a Go type in use, and the sum of
+// their reference counts, returned by HandleStats.
+type HandleStat struct {
+	Type  string
+	Count int
+	Refs  int64
+}
+
+// HandleStats returns the stats of the handles in use by Go type, by
+// decreasing number of handles, e.g., to find the type of leaking handles.
+func HandleStats() []HandleStat {
+	byType := make(map[reflect.Type]*HandleStat)
+	for _, sh := range shards {
+		sh.mu.RLock()
+		for h, v := range sh.handles {
+			typ := reflect.TypeOf(v)
+			st := byType[typ]
+			if st == nil {
+				st = &HandleStat{Type: typ.String()}
+				byType[typ] = st
+			}
+			st.Count++
+			st.Refs += sh.counts[h]
+		}
+		sh.mu.RUnlock()
+	}
+	stats := make([]HandleStat, 0, len(byType))
+	for _, st := range byType {
+		stats = append(stats, *st)
+	}
+	sort.Slice(stats, func(i, j int) bool {
+		if stats[i].Count != stats[j].Count {
+			return stats[i].Count > stats[j].Count
+		}
+		return stats[i].Type < stats[j].Type
+	})
+	return stats
+}
+
+// dumpValueLen is the max length of the values written by DumpHandles
+const dumpValueLen = 80
+
+// DumpHandles writes the handles in use to w, one per line, in increasing
+// order, with their reference count, Go type and value, truncated to 80
+// bytes, e.g., to correlate leaking handles with the code creating them.
+func DumpHandles(w io.Writer) error {
+	type entry struct {
+		h   GoHandle
+		cnt int64
+		v   interface{}
+	}
+	ents := make([]entry, 0, NumHandles())
+	for _, sh := range shards {
+		sh.mu.RLock()
+		for h, v := range sh.handles {
+			ents = append(ents, entry{h, sh.counts[h], v})
+		}
+		sh.mu.RUnlock()
+	}
+	sort.Slice(ents, func(i, j int) bool { return ents[i].h < ents[j].h })
+	// the values are formatted without the lock, as their String methods
+	// may register handles
+	for _, e := range ents {
+		val := fmt.Sprintf("%v", e.v)
+		if len(val) > dumpValueLen {
+			val = val[:dumpValueLen-3] + "..."
+		}
+		if _, err := fmt.Fprintf(w, "%d\trefs=%d\t%T\t%s\n", e.h, e.cnt, e.v, val); err != nil {
+			return err
+		}
+	}
+	return nil
 }
@@ -0,0 +1,206 @@
+// Copyright 2024 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gopyh
+
+import (
+	"bytes"
+	"fmt"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func TestAddr(t *testing.T) {
+	type node struct {
+		next *node
+	}
+	n := &node{}
+	n.next = n
+	h1 := Register("*node", n)
+	h2 := Register("*node", n.next)
+	if h1 == h2 {
+		t.Fatalf("same handle %d for two registrations", h1)
+	}
+	if a1, a2 := Addr(h1), Addr(h2); a1 == 0 || a1 != a2 {
+		t.Fatalf("addresses of the same pointer differ: %#x != %#x", a1, a2)
+	}
+
+	m := map[string]int{"a": 1}
+	hm := Register("map[string]int", m)
+	if Addr(hm) == 0 || Addr(hm) == Addr(h1) {
+		t.Fatalf("invalid map address %#x", Addr(hm))
+	}
+
+	hi := Register("int", 42)
+	if a := Addr(hi); a != 0 {
+		t.Fatalf("address of an int: got %#x, want 0", a)
+	}
+	if a := Addr(-1); a != 0 {
+		t.Fatalf("address of a nil handle: got %#x, want 0", a)
+	}
+}
+
+func TestHandleLimit(t *testing.T) {
+	var calls []int
+	n := NumHandles()
+	SetHandleLimit(n+2, func(nh int) { calls = append(calls, nh) })
+	defer SetHandleLimit(0, nil)
+
+	h1 := Register("int", 1)
+	h2 := Register("int", 2)
+	if len(calls) != 0 {
+		t.Fatalf("called at the limit: %v", calls)
+	}
+	h3 := Register("int", 3)
+	h4 := Register("int", 4)
+	if len(calls) != 1 || calls[0] != n+3 {
+		t.Fatalf("calls above the limit: got %v, want [%d]", calls, n+3)
+	}
+	for _, h := range []CGoHandle{h3, h4} {
+		IncRef(h)
+		DecRef(h)
+	}
+	h5 := Register("int", 5)
+	if len(calls) != 2 || calls[1] != n+3 {
+		t.Fatalf("calls exceeding the limit again: got %v, want 2 calls", calls)
+	}
+	for _, h := range []CGoHandle{h1, h2, h5} {
+		IncRef(h)
+		DecRef(h)
+	}
+	if got := HandleLimit(); got != n+2 {
+		t.Fatalf("HandleLimit: got %d, want %d", got, n+2)
+	}
+}
+
+func TestRelease(t *testing.T) {
+	n := NumHandles()
+	h := Register("int", 1)
+	IncRef(h)
+	IncRef(h)
+	if Release(h) {
+		t.Fatalf("released handle %d still referenced", h)
+	}
+	if !Release(h) {
+		t.Fatalf("handle %d not released by its last reference", h)
+	}
+	if Release(h) {
+		t.Fatalf("deleted handle %d released again", h)
+	}
+	if Release(-1) {
+		t.Fatalf("nil handle released")
+	}
+	if got := NumHandles(); got != n {
+		t.Fatalf("NumHandles: got %d, want %d", got, n)
+	}
+}
+
+func TestHandleStats(t *testing.T) {
+	type leak struct{ n int }
+	h1 := Register("*leak", &leak{1})
+	h2 := Register("*leak", &leak{2})
+	h3 := Register("string", "leak")
+	IncRef(h1)
+	IncRef(h1)
+	IncRef(h2)
+	defer func() {
+		for _, h := range []CGoHandle{h1, h1, h2} {
+			DecRef(h)
+		}
+	}()
+
+	stats := make(map[string]HandleStat)
+	for _, st := range HandleStats() {
+		stats[st.Type] = st
+	}
+	if st := stats["*gopyh.leak"]; st.Count != 2 || st.Refs != 3 {
+		t.Fatalf("stats of *gopyh.leak: got %+v, want 2 handles with 3 refs", st)
+	}
+	if st := stats["string"]; st.Count < 1 {
+		t.Fatalf("stats of string: got %+v, want at least 1 handle", st)
+	}
+
+	var buf bytes.Buffer
+	if err := DumpHandles(&buf); err != nil {
+		t.Fatal(err)
+	}
+	want := []string{
+		fmt.Sprintf("%d\trefs=2\t*gopyh.leak\t&{1}\n", h1),
+		fmt.Sprintf("%d\trefs=1\t*gopyh.leak\t&{2}\n", h2),
+		fmt.Sprintf("%d\trefs=0\tstring\tleak\n", h3),
+	}
+	dump := buf.String()
+	for _, w := range want {
+		if !strings.Contains(dump, w) {
+			t.Fatalf("dump does not contain %q:\n%s", w, dump)
+		}
+	}
+	if strings.Index(dump, want[0]) > strings.Index(dump, want[2]) {
+		t.Fatalf("dump not in increasing handle order:\n%s", dump)
+	}
+}
+
+// withShards runs fn with n new shards of handles, restoring the shards in
+// use after, as the handles of the other tests are still in use
+func withShards(n int, fn func()) {
+	old := shards
+	shards = newShards(n)
+	defer func() { shards = old }()
+	fn()
+}
+
+func TestShards(t *testing.T) {
+	if err := SetShards(4); NumHandles() > 0 && err == nil {
+		t.Fatalf("SetShards with handles in use: no error")
+	}
+	withShards(4, func() {
+		if got := Shards(); got != 4 {
+			t.Fatalf("Shards: got %d, want 4", got)
+		}
+		var hs []CGoHandle
+		for i := 0; i < 8; i++ {
+			hs = append(hs, Register("int", i))
+		}
+		for i, sh := range shards {
+			if len(sh.handles) != 2 {
+				t.Fatalf("shard %d: got %d handles, want 2", i, len(sh.handles))
+			}
+		}
+		for i, h := range hs {
+			if v := VarFromHandle(h, "int"); v != i {
+				t.Fatalf("handle %d: got %v, want %d", h, v, i)
+			}
+			IncRef(h)
+			DecRef(h)
+		}
+		for i, sh := range shards {
+			if len(sh.handles) != 0 {
+				t.Fatalf("shard %d: got %d handles after DecRef, want 0", i, len(sh.handles))
+			}
+		}
+	})
+}
+
+// BenchmarkRegisterParallel registers, uses and deletes handles from
+// parallel goroutines, with one shard and with one per CPU: with
+// -cpu=1,4,16, it shows the number of threads calling into Go simultaneously
+// above which the -handle-shards option of gopy pays off.
+func BenchmarkRegisterParallel(b *testing.B) {
+	for _, n := range []int{1, runtime.GOMAXPROCS(0)} {
+		b.Run(fmt.Sprintf("shards=%d", n), func(b *testing.B) {
+			withShards(n, func() {
+				b.RunParallel(func(pb *testing.PB) {
+					v := &struct{ n int }{1}
+					for pb.Next() {
+						h := Register("*struct", v)
+						IncRef(h)
+						_ = VarFromHandle(h, "*struct")
+						DecRef(h)
+					}
+				})
+			})
+		})
+	}
+}
@@ -0,0 +1,89 @@
+// Copyright 2024 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gopyh
+
+import (
+	"encoding/binary"
+	"hash"
+	"hash/fnv"
+	"math"
+	"reflect"
+)
+
+// Hash returns a hash of the value consistent with Go's == on comparable
+// values, i.e., equal values have the same hash, for the __hash__ of their
+// python classes.  Pointers, channels and maps are hashed by address,
+// and the fields of structs are all hashed, including the unexported ones.
+func Hash(v interface{}) int64 {
+	h := &hasher{h: fnv.New64a()}
+	if v != nil {
+		h.hash(reflect.ValueOf(v))
+	}
+	return int64(h.h.Sum64())
+}
+
+// hasher hashes values with FNV-1a
+type hasher struct {
+	h   hash.Hash64
+	buf [8]byte
+}
+
+func (h *hasher) write(p []byte) {
+	h.h.Write(p)
+}
+
+func (h *hasher) uint64(u uint64) {
+	binary.LittleEndian.PutUint64(h.buf[:], u)
+	h.write(h.buf[:])
+}
+
+func (h *hasher) float64(f float64) {
+	if f == 0 { // -0 == 0
+		f = 0
+	}
+	h.uint64(math.Float64bits(f))
+}
+
+func (h *hasher) hash(v reflect.Value) {
+	switch v.Kind() {
+	case reflect.Bool:
+		if v.Bool() {
+			h.uint64(1)
+		} else {
+			h.uint64(0)
+		}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		h.uint64(uint64(v.Int()))
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		h.uint64(v.Uint())
+	case reflect.Float32, reflect.Float64:
+		h.float64(v.Float())
+	case reflect.Complex64, reflect.Complex128:
+		c := v.Complex()
+		h.float64(real(c))
+		h.float64(imag(c))
+	case reflect.String:
+		h.uint64(uint64(v.Len()))
+		h.write([]byte(v.String()))
+	case reflect.Ptr, reflect.Chan, reflect.Map, reflect.UnsafePointer, reflect.Func:
+		h.uint64(uint64(v.Pointer()))
+	case reflect.Interface:
+		if v.IsNil() {
+			h.uint64(0)
+			return
+		}
+		e := v.Elem()
+		h.write([]byte(e.Type().String()))
+		h.hash(e)
+	case reflect.Struct:
+		for i := 0; i < v.NumField(); i++ {
+			h.hash(v.Field(i))
+		}
+	case reflect.Array, reflect.Slice:
+		for i := 0; i < v.Len(); i++ {
+			h.hash(v.Index(i))
+		}
+	}
+}
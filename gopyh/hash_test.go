@@ -0,0 +1,49 @@
+// Copyright 2024 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gopyh
+
+import (
+	"math"
+	"testing"
+)
+
+type hashPoint struct {
+	X, Y float64
+	Tag  interface{}
+	Next *hashPoint
+	priv string
+}
+
+func TestHash(t *testing.T) {
+	p := &hashPoint{}
+	for _, tt := range []struct {
+		a, b interface{}
+	}{
+		{nil, nil},
+		{42, 42},
+		{"hi", "hi"},
+		{0.0, math.Copysign(0, -1)},
+		{[2]int{1, 2}, [2]int{1, 2}},
+		{hashPoint{X: 1, Tag: "t", Next: p, priv: "a"}, hashPoint{X: 1, Tag: "t", Next: p, priv: "a"}},
+	} {
+		if Hash(tt.a) != Hash(tt.b) {
+			t.Errorf("Hash(%#v) != Hash(%#v)", tt.a, tt.b)
+		}
+	}
+	for _, tt := range []struct {
+		a, b interface{}
+	}{
+		{1, 2},
+		{"a", "b"},
+		{hashPoint{X: 1}, hashPoint{Y: 1}},
+		{hashPoint{Tag: 1}, hashPoint{Tag: "1"}},
+		{hashPoint{priv: "a"}, hashPoint{priv: "b"}},
+		{hashPoint{Next: p}, hashPoint{Next: &hashPoint{}}},
+	} {
+		if Hash(tt.a) == Hash(tt.b) {
+			t.Errorf("Hash(%#v) == Hash(%#v)", tt.a, tt.b)
+		}
+	}
+}
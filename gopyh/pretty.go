@@ -0,0 +1,258 @@
+// Copyright 2024 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gopyh
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Pretty returns a pretty-printed rendering of the value, in the style of
+// go-spew: each value is annotated with its type, and nested values are
+// indented, including unexported struct fields.  Pointers are followed,
+// printing <cycle> for pointers already being printed.
+func Pretty(v interface{}) string {
+	if v == nil {
+		return "<nil>"
+	}
+	pr := &renderer{seen: map[uintptr]bool{}}
+	return strings.Join(pr.pretty(reflect.ValueOf(v)), "\n")
+}
+
+// YAML returns a YAML rendering of the value, with the exported fields of
+// structs as mappings, using the name in their yaml tag if any.
+// Pointers are followed, rendering null for nil and for cycles.
+// Channels and functions are rendered as null.
+func YAML(v interface{}) string {
+	if v == nil {
+		return "null"
+	}
+	pr := &renderer{seen: map[uintptr]bool{}}
+	lines, _ := pr.yaml(reflect.ValueOf(v))
+	return strings.Join(lines, "\n")
+}
+
+// renderer renders nested values as lines of text,
+// keeping track of the pointers being rendered to detect cycles
+type renderer struct {
+	seen map[uintptr]bool
+}
+
+// nest appends the lines of a nested value to prefix, on the same line
+// for the first one, and indented for the others
+func nest(lines []string, prefix string, val []string) []string {
+	lines = append(lines, prefix+val[0])
+	for _, l := range val[1:] {
+		lines = append(lines, "  "+l)
+	}
+	return lines
+}
+
+// sortedKeys returns the keys of the map value sorted by their rendering
+func sortedKeys(v reflect.Value) []reflect.Value {
+	keys := v.MapKeys()
+	sort.Slice(keys, func(i, j int) bool {
+		return scalarString(keys[i]) < scalarString(keys[j])
+	})
+	return keys
+}
+
+// scalarString returns the rendering of a value of basic kind,
+// and of other values as formatted by fmt
+func scalarString(v reflect.Value) string {
+	switch v.Kind() {
+	case reflect.Bool:
+		return strconv.FormatBool(v.Bool())
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(v.Int(), 10)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return strconv.FormatUint(v.Uint(), 10)
+	case reflect.Float32:
+		return strconv.FormatFloat(v.Float(), 'g', -1, 32)
+	case reflect.Float64:
+		return strconv.FormatFloat(v.Float(), 'g', -1, 64)
+	case reflect.Complex64, reflect.Complex128:
+		return strconv.FormatComplex(v.Complex(), 'g', -1, 128)
+	case reflect.String:
+		return v.String()
+	}
+	if v.CanInterface() {
+		return fmt.Sprint(v.Interface())
+	}
+	return v.Type().String()
+}
+
+func (r *renderer) pretty(v reflect.Value) []string {
+	if !v.IsValid() {
+		return []string{"<nil>"}
+	}
+	typ := "(" + v.Type().String() + ") "
+	switch v.Kind() {
+	case reflect.Ptr:
+		if v.IsNil() {
+			return []string{typ + "<nil>"}
+		}
+		if r.seen[v.Pointer()] {
+			return []string{typ + "<cycle>"}
+		}
+		r.seen[v.Pointer()] = true
+		defer delete(r.seen, v.Pointer())
+		ev := r.pretty(v.Elem())
+		ev[0] = "(*" + strings.TrimPrefix(ev[0], "(")
+		return ev
+	case reflect.Interface:
+		if v.IsNil() {
+			return []string{typ + "<nil>"}
+		}
+		return r.pretty(v.Elem())
+	case reflect.Struct:
+		if v.NumField() == 0 {
+			return []string{typ + "{}"}
+		}
+		lines := []string{typ + "{"}
+		for i := 0; i < v.NumField(); i++ {
+			lines = nest(lines, "  "+v.Type().Field(i).Name+": ", r.pretty(v.Field(i)))
+			lines[len(lines)-1] += ","
+		}
+		return append(lines, "}")
+	case reflect.Slice, reflect.Array:
+		if v.Kind() == reflect.Slice && v.IsNil() {
+			return []string{typ + "<nil>"}
+		}
+		if v.Len() == 0 {
+			return []string{typ + "{}"}
+		}
+		lines := []string{typ + fmt.Sprintf("(len=%d) {", v.Len())}
+		for i := 0; i < v.Len(); i++ {
+			lines = nest(lines, "  ", r.pretty(v.Index(i)))
+			lines[len(lines)-1] += ","
+		}
+		return append(lines, "}")
+	case reflect.Map:
+		if v.IsNil() {
+			return []string{typ + "<nil>"}
+		}
+		if v.Len() == 0 {
+			return []string{typ + "{}"}
+		}
+		lines := []string{typ + fmt.Sprintf("(len=%d) {", v.Len())}
+		for _, k := range sortedKeys(v) {
+			kv := r.pretty(k)
+			lines = nest(lines, "  "+strings.Join(kv, " ")+": ", r.pretty(v.MapIndex(k)))
+			lines[len(lines)-1] += ","
+		}
+		return append(lines, "}")
+	case reflect.String:
+		return []string{typ + strconv.Quote(v.String())}
+	case reflect.Chan, reflect.Func, reflect.UnsafePointer:
+		if v.IsNil() {
+			return []string{typ + "<nil>"}
+		}
+		return []string{typ + fmt.Sprintf("%#x", v.Pointer())}
+	}
+	return []string{typ + scalarString(v)}
+}
+
+// yaml returns the lines of the YAML rendering of the value, and whether
+// it is a block mapping or sequence, rather than a single line value
+func (r *renderer) yaml(v reflect.Value) ([]string, bool) {
+	if !v.IsValid() {
+		return []string{"null"}, false
+	}
+	switch v.Kind() {
+	case reflect.Ptr:
+		if v.IsNil() || r.seen[v.Pointer()] {
+			return []string{"null"}, false
+		}
+		r.seen[v.Pointer()] = true
+		defer delete(r.seen, v.Pointer())
+		return r.yaml(v.Elem())
+	case reflect.Interface:
+		if v.IsNil() {
+			return []string{"null"}, false
+		}
+		return r.yaml(v.Elem())
+	case reflect.Struct:
+		var lines []string
+		typ := v.Type()
+		for i := 0; i < v.NumField(); i++ {
+			f := typ.Field(i)
+			if f.PkgPath != "" {
+				continue
+			}
+			name := f.Name
+			if tag := strings.Split(f.Tag.Get("yaml"), ",")[0]; tag == "-" {
+				continue
+			} else if tag != "" {
+				name = tag
+			}
+			lines = r.yamlEntry(lines, yamlString(name), v.Field(i))
+		}
+		if len(lines) == 0 {
+			return []string{"{}"}, false
+		}
+		return lines, true
+	case reflect.Map:
+		if v.Len() == 0 {
+			return []string{"{}"}, false
+		}
+		var lines []string
+		for _, k := range sortedKeys(v) {
+			lines = r.yamlEntry(lines, yamlString(scalarString(k)), v.MapIndex(k))
+		}
+		return lines, true
+	case reflect.Slice, reflect.Array:
+		if v.Len() == 0 {
+			return []string{"[]"}, false
+		}
+		var lines []string
+		for i := 0; i < v.Len(); i++ {
+			val, _ := r.yaml(v.Index(i))
+			lines = nest(lines, "- ", val)
+		}
+		return lines, true
+	case reflect.String:
+		return []string{yamlString(v.String())}, false
+	case reflect.Complex64, reflect.Complex128:
+		return []string{strconv.Quote(scalarString(v))}, false
+	case reflect.Chan, reflect.Func, reflect.UnsafePointer:
+		return []string{"null"}, false
+	}
+	return []string{scalarString(v)}, false
+}
+
+// yamlEntry appends the mapping entry of the key and value to lines,
+// with block values on the following lines
+func (r *renderer) yamlEntry(lines []string, key string, v reflect.Value) []string {
+	val, block := r.yaml(v)
+	if !block {
+		return append(lines, key+": "+val[0])
+	}
+	lines = append(lines, key+":")
+	for _, l := range val {
+		lines = append(lines, "  "+l)
+	}
+	return lines
+}
+
+// yamlString returns the string as a plain YAML scalar if possible,
+// and quoted otherwise
+func yamlString(s string) string {
+	switch strings.ToLower(s) {
+	case "", "null", "~", "true", "false", "yes", "no", "on", "off":
+		return strconv.Quote(s)
+	}
+	if _, err := strconv.ParseFloat(s, 64); err == nil {
+		return strconv.Quote(s)
+	}
+	if strings.ContainsAny(s, ":#{}[],&*!|>'\"%@`\n\t\\") || strings.ContainsAny(s[:1], "-?") ||
+		strings.TrimSpace(s) != s {
+		return strconv.Quote(s)
+	}
+	return s
+}
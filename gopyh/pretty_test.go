@@ -0,0 +1,90 @@
+// Copyright 2024 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gopyh
+
+import (
+	"testing"
+)
+
+type prettyNode struct {
+	Name  string `yaml:"name"`
+	Vals  []int
+	Attrs map[string]interface{}
+	Next  *prettyNode
+	Skip  bool `yaml:"-"`
+	priv  int
+}
+
+func TestPretty(t *testing.T) {
+	cyc := &prettyNode{Name: "cycle"}
+	cyc.Next = cyc
+	for _, tt := range []struct {
+		v    interface{}
+		want string
+	}{
+		{nil, "<nil>"},
+		{42, "(int) 42"},
+		{"hi", `(string) "hi"`},
+		{[]string{}, "([]string) {}"},
+		{map[int]bool{2: false, 1: true}, "(map[int]bool) (len=2) {\n  (int) 1: (bool) true,\n  (int) 2: (bool) false,\n}"},
+		{&prettyNode{Name: "n", Vals: []int{1}, priv: 3}, `(*gopyh.prettyNode) {
+  Name: (string) "n",
+  Vals: ([]int) (len=1) {
+    (int) 1,
+  },
+  Attrs: (map[string]interface {}) <nil>,
+  Next: (*gopyh.prettyNode) <nil>,
+  Skip: (bool) false,
+  priv: (int) 3,
+}`},
+		{cyc, `(*gopyh.prettyNode) {
+  Name: (string) "cycle",
+  Vals: ([]int) <nil>,
+  Attrs: (map[string]interface {}) <nil>,
+  Next: (*gopyh.prettyNode) <cycle>,
+  Skip: (bool) false,
+  priv: (int) 0,
+}`},
+	} {
+		if got := Pretty(tt.v); got != tt.want {
+			t.Errorf("Pretty(%#v): expected\n%s\nactual\n%s", tt.v, tt.want, got)
+		}
+	}
+}
+
+func TestYAML(t *testing.T) {
+	cyc := &prettyNode{Name: "cycle"}
+	cyc.Next = cyc
+	for _, tt := range []struct {
+		v    interface{}
+		want string
+	}{
+		{nil, "null"},
+		{42, "42"},
+		{"hi", "hi"},
+		{"", `""`},
+		{"true", `"true"`},
+		{"1.5", `"1.5"`},
+		{"a: b", `"a: b"`},
+		{"-a", `"-a"`},
+		{[]int{}, "[]"},
+		{[][]int{{1, 2}, {3}}, "- - 1\n  - 2\n- - 3"},
+		{map[string]int{"b": 2, "a": 1}, "a: 1\nb: 2"},
+		{&prettyNode{Name: "n", Vals: []int{1, 2}, Attrs: map[string]interface{}{"k": []string{"v"}}, priv: 3}, `name: n
+Vals:
+  - 1
+  - 2
+Attrs:
+  k:
+    - v
+Next: null`},
+		{cyc, "name: cycle\nVals: []\nAttrs: {}\nNext: null"},
+		{[]prettyNode{{Name: "x"}}, "- name: x\n  Vals: []\n  Attrs: {}\n  Next: null"},
+	} {
+		if got := YAML(tt.v); got != tt.want {
+			t.Errorf("YAML(%#v): expected\n%s\nactual\n%s", tt.v, tt.want, got)
+		}
+	}
+}
@@ -0,0 +1,224 @@
+// Copyright 2024 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"golang.org/x/tools/go/packages"
+
+	"github.com/go-python/gopy/bind"
+)
+
+// The -incremental option of gen and build skips the generation, and the
+// build, of the bindings when neither the Go packages, nor the options, of
+// the command line, the config file, the hooks and the templates, and gopy
+// itself, nor the files generated, changed since the last one, by the
+// content hashes of their files recorded in the .gopy-hashes file of the
+// output dir: the Go files of the packages bound and of the packages they
+// import outside of the standard library.  Otherwise, the bindings are
+// regenerated, and the generated files whose contents did not change,
+// e.g., the python modules of the packages that did not change, are not
+// written again, keeping their modification times for the build tools.
+
+// incrementalFile records the content hashes of the last generation
+const incrementalFile = ".gopy-hashes"
+
+// incrementalHashes are the content hashes of the inputs and outputs of a
+// generation
+type incrementalHashes struct {
+	Config   string            `json:"config"`   // of the command, the python VM and gopy
+	Packages map[string]string `json:"packages"` // of the files of the Go packages, by path
+	Outputs  map[string]string `json:"outputs"`  // of the generated files, by path in the output dir
+}
+
+// hashFiles returns the hex sha256 hash of the contents of the files
+func hashFiles(files ...string) (string, error) {
+	h := sha256.New()
+	for _, fn := range files {
+		f, err := os.Open(fn)
+		if err != nil {
+			return "", err
+		}
+		fmt.Fprintf(h, "%s\x00", filepath.Base(fn))
+		_, err = io.Copy(h, f)
+		f.Close()
+		if err != nil {
+			return "", err
+		}
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// inputHashes returns the content hashes of the inputs of the generation of
+// the bindings of the packages
+func inputHashes(cfg *BuildCfg, paths []string) (*incrementalHashes, error) {
+	hs := &incrementalHashes{Packages: make(map[string]string)}
+	exe, err := os.Executable()
+	if err != nil {
+		return nil, err
+	}
+	gopyHash, err := hashFiles(exe)
+	if err != nil {
+		return nil, err
+	}
+	opts := []string{cfg.Cmd, cfg.VM, gopyHash}
+	inputs := []string{cfg.ConfigFile, cfg.Hooks}
+	if cfg.Templates != "" {
+		tmpls, _ := filepath.Glob(filepath.Join(cfg.Templates, "*.tmpl"))
+		inputs = append(inputs, tmpls...)
+	}
+	for _, fn := range inputs {
+		if fn == "" {
+			continue
+		}
+		fnHash, err := hashFiles(fn)
+		if err != nil {
+			return nil, err
+		}
+		opts = append(opts, fnHash)
+	}
+	hs.Config = fmt.Sprintf("%x", sha256.Sum256([]byte(strings.Join(opts, "\x00"))))
+
+	lcfg := &packages.Config{Mode: packages.NeedName | packages.NeedFiles | packages.NeedImports | packages.NeedDeps | packages.NeedModule}
+	if cfg.BuildTags != "" {
+		lcfg.BuildFlags = []string{"-tags", strings.Join(strings.Split(cfg.BuildTags, ","), " ")}
+	}
+	bpkgs, err := packages.Load(lcfg, paths...)
+	if err != nil {
+		return nil, fmt.Errorf("gopy: could not load packages %v for their hashes: %v", paths, err)
+	}
+	var herr error
+	packages.Visit(bpkgs, nil, func(bpkg *packages.Package) {
+		if bpkg.Module == nil && !isRootPkg(bpkgs, bpkg) {
+			return // standard library
+		}
+		files := append(append([]string{}, bpkg.GoFiles...), bpkg.OtherFiles...)
+		sort.Strings(files)
+		h, err := hashFiles(files...)
+		if err != nil && herr == nil {
+			herr = err
+		}
+		hs.Packages[bpkg.PkgPath] = h
+	})
+	return hs, herr
+}
+
+// isRootPkg returns whether the package is one of the packages loaded
+func isRootPkg(roots []*packages.Package, bpkg *packages.Package) bool {
+	for _, r := range roots {
+		if r == bpkg {
+			return true
+		}
+	}
+	return false
+}
+
+// checkIncremental returns the hashes of the inputs of the generation of
+// the bindings of the packages, and whether the bindings of the output dir
+// are up to date with them, printing why not
+func checkIncremental(cfg *BuildCfg, paths []string) (*incrementalHashes, bool, error) {
+	hs, err := inputHashes(cfg, paths)
+	if err != nil {
+		return nil, false, err
+	}
+	odir := cfg.OutputDir
+	if odir == "" {
+		odir = "."
+	}
+	ok, why := upToDate(odir, hs)
+	if ok {
+		fmt.Printf("--- bindings in %s up to date: no package, option or generated file changed ---\n", odir)
+	} else {
+		fmt.Printf("--- incremental: generating the bindings, as %s ---\n", why)
+	}
+	return hs, ok, nil
+}
+
+// readHashes returns the hashes of the last generation into the output dir,
+// or nil if none
+func readHashes(odir string) *incrementalHashes {
+	data, err := os.ReadFile(filepath.Join(odir, incrementalFile))
+	if err != nil {
+		return nil
+	}
+	var hs incrementalHashes
+	if json.Unmarshal(data, &hs) != nil {
+		return nil
+	}
+	return &hs
+}
+
+// upToDate returns whether the bindings generated into the output dir are up
+// to date with the hashes of the inputs, and otherwise, why not
+func upToDate(odir string, hs *incrementalHashes) (bool, string) {
+	last := readHashes(odir)
+	switch {
+	case last == nil:
+		return false, "no previous generation"
+	case last.Config != hs.Config:
+		return false, "the options or gopy changed"
+	}
+	var changed []string
+	for p, h := range hs.Packages {
+		if last.Packages[p] != h {
+			changed = append(changed, p)
+		}
+	}
+	for p := range last.Packages {
+		if _, ok := hs.Packages[p]; !ok {
+			changed = append(changed, p)
+		}
+	}
+	if len(changed) > 0 {
+		sort.Strings(changed)
+		return false, fmt.Sprintf("%d of %d packages changed: %s", len(changed), len(hs.Packages), strings.Join(changed, ", "))
+	}
+	for fn, h := range last.Outputs {
+		if oh, err := hashFiles(filepath.Join(odir, filepath.FromSlash(fn))); err != nil || oh != h {
+			return false, "the generated " + fn + " changed"
+		}
+	}
+	return true, ""
+}
+
+// writeHashes records the hashes of the generation into the output dir: of
+// the inputs, and of the files generated, and built, since the start time
+func writeHashes(odir string, hs *incrementalHashes, start time.Time) error {
+	hs.Outputs = make(map[string]string)
+	start = start.Truncate(time.Second) // of the coarse modification times
+	outs := bind.GetStats("").Outputs
+	entries, err := os.ReadDir(odir)
+	if err != nil {
+		return err
+	}
+	for _, e := range entries {
+		if info, err := e.Info(); err == nil && !e.IsDir() && !info.ModTime().Before(start) {
+			outs[e.Name()] = info.Size()
+		}
+	}
+	delete(outs, incrementalFile)
+	for fn := range outs {
+		h, err := hashFiles(filepath.Join(odir, filepath.FromSlash(fn)))
+		if err != nil {
+			continue // removed by the build, e.g., the intermediate files
+		}
+		hs.Outputs[fn] = h
+	}
+	data, err := json.MarshalIndent(hs, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(odir, incrementalFile), append(data, '\n'), 0644)
+}
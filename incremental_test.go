@@ -0,0 +1,70 @@
+// Copyright 2024 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestIncremental(t *testing.T) {
+	odir := t.TempDir()
+	hashes := func() *incrementalHashes {
+		return &incrementalHashes{
+			Config:   "cfg",
+			Packages: map[string]string{"example.com/a": "1", "example.com/b": "2"},
+		}
+	}
+	if ok, _ := upToDate(odir, hashes()); ok {
+		t.Errorf("up to date without a previous generation")
+	}
+
+	start := time.Now()
+	if err := os.WriteFile(filepath.Join(odir, "a.py"), []byte("a = 1\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := writeHashes(odir, hashes(), start); err != nil {
+		t.Fatal(err)
+	}
+	if ok, why := upToDate(odir, hashes()); !ok {
+		t.Errorf("not up to date after a generation: %s", why)
+	}
+
+	hs := hashes()
+	hs.Packages["example.com/b"] = "3"
+	if ok, why := upToDate(odir, hs); ok || why != "1 of 2 packages changed: example.com/b" {
+		t.Errorf("changed package: got %v, %q", ok, why)
+	}
+	hs = hashes()
+	hs.Config = "cfg2"
+	if ok, _ := upToDate(odir, hs); ok {
+		t.Errorf("up to date with other options")
+	}
+
+	if err := os.WriteFile(filepath.Join(odir, "a.py"), []byte("a = 2\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if ok, why := upToDate(odir, hashes()); ok || why != "the generated a.py changed" {
+		t.Errorf("changed output: got %v, %q", ok, why)
+	}
+}
+
+func TestInputHashes(t *testing.T) {
+	cfg := NewBuildCfg()
+	hs, err := inputHashes(cfg, []string{"./_examples/hi"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, p := range []string{"github.com/go-python/gopy/_examples/hi", "github.com/go-python/gopy/_examples/structs"} {
+		if hs.Packages[p] == "" {
+			t.Errorf("no hash of %s", p)
+		}
+	}
+	if _, ok := hs.Packages["fmt"]; ok {
+		t.Errorf("hash of the standard library package fmt")
+	}
+}
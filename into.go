@@ -0,0 +1,157 @@
+// Copyright 2024 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/go-python/gopy/bind"
+)
+
+// The -into option of gen and build generates the bindings into an
+// existing python package, e.g., -into=src/myapp/core, as its _gopy
+// subpackage, src/myapp/core/_gopy, imported as myapp.core._gopy, whose
+// __init__.py imports the modules of the Go packages when first accessed,
+// e.g., from myapp.core._gopy import mypkg, whatever the Go code bound.
+// Nothing outside of _gopy is written.  The files generated into _gopy are
+// listed in its .gopy-manifest, and removed when regenerating the bindings,
+// so that the modules of the Go packages no longer bound do not linger,
+// while the other files of _gopy are kept.  A _gopy directory without a
+// manifest is not overwritten.
+
+// intoManifest lists the files generated into the _gopy subpackage
+const intoManifest = ".gopy-manifest"
+
+// intoPkgName returns the dotted python name of the existing python
+// package of the directory, a python package nested in the parent dirs
+// with an __init__.py
+func intoPkgName(dir string) (string, error) {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return "", err
+	}
+	if _, err := os.Stat(filepath.Join(dir, "__init__.py")); err != nil {
+		return "", fmt.Errorf("gopy: -into=%s is not a python package, with an __init__.py", dir)
+	}
+	var comps []string
+	for {
+		comps = append([]string{filepath.Base(dir)}, comps...)
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			break
+		}
+		if _, err := os.Stat(filepath.Join(parent, "__init__.py")); err != nil {
+			break
+		}
+		dir = parent
+	}
+	return strings.Join(comps, "."), nil
+}
+
+// setInto sets the configuration to generate the bindings into the _gopy
+// subpackage of the existing python package of the directory, refusing to
+// overwrite it if it has no manifest
+func setInto(cfg *BuildCfg, dir string) error {
+	switch {
+	case cfg.OutputDir != "":
+		return fmt.Errorf("gopy: -into and -output are exclusive")
+	case cfg.Parent != "":
+		return fmt.Errorf("gopy: -into needs an undotted -name, not %s", cfg.FullName())
+	case cfg.PkgPrefix != ".":
+		return fmt.Errorf("gopy: -into needs the default -package-prefix, importing the modules relatively")
+	}
+	name, err := intoPkgName(dir)
+	if err != nil {
+		return err
+	}
+	if err := cfg.SetEmbedded(name); err != nil {
+		return err
+	}
+	cfg.OutputDir = filepath.Join(dir, bind.EmbedPkg)
+	_, err = os.Stat(filepath.Join(cfg.OutputDir, intoManifest))
+	if _, serr := os.Stat(cfg.OutputDir); os.IsNotExist(err) && serr == nil {
+		return fmt.Errorf("gopy: %s exists, without the %s of the bindings generated by -into, not overwriting it", cfg.OutputDir, intoManifest)
+	}
+	return nil
+}
+
+// cleanInto removes the files listed in the manifest of the _gopy
+// directory, if any, and returns the files it contains that were not
+func cleanInto(dir string) (map[string]bool, error) {
+	f, err := os.Open(filepath.Join(dir, intoManifest))
+	switch {
+	case os.IsNotExist(err):
+		return intoFiles(dir)
+	case err != nil:
+		return nil, err
+	}
+	defer f.Close()
+	sc := bufio.NewScanner(f)
+	for sc.Scan() {
+		fn := strings.TrimSpace(sc.Text())
+		if fn == "" || strings.HasPrefix(fn, "#") {
+			continue
+		}
+		if rel := filepath.Clean(filepath.FromSlash(fn)); filepath.IsAbs(rel) || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			return nil, fmt.Errorf("gopy: invalid file %q in %s", fn, filepath.Join(dir, intoManifest))
+		}
+		if err := os.Remove(filepath.Join(dir, filepath.FromSlash(fn))); err != nil && !os.IsNotExist(err) {
+			return nil, err
+		}
+	}
+	if err := sc.Err(); err != nil {
+		return nil, err
+	}
+	return intoFiles(dir)
+}
+
+// intoFiles returns the files of the _gopy directory, by slash-separated
+// path relative to it, without the python caches and the manifest
+func intoFiles(dir string) (map[string]bool, error) {
+	files := make(map[string]bool)
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		switch {
+		case os.IsNotExist(err) && path == dir:
+			return filepath.SkipDir
+		case err != nil:
+			return err
+		case d.IsDir() && d.Name() == "__pycache__":
+			return filepath.SkipDir
+		case d.IsDir() || d.Name() == intoManifest:
+			return nil
+		}
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		files[filepath.ToSlash(rel)] = true
+		return nil
+	})
+	return files, err
+}
+
+// writeIntoManifest writes the manifest of the files generated into the
+// _gopy directory, all but those it contained before
+func writeIntoManifest(dir string, before map[string]bool) error {
+	files, err := intoFiles(dir)
+	if err != nil {
+		return err
+	}
+	var gen []string
+	for fn := range files {
+		if !before[fn] {
+			gen = append(gen, fn)
+		}
+	}
+	sort.Strings(gen)
+	mf := "# files generated by gopy -into, removed when regenerating the bindings\n" + strings.Join(gen, "\n") + "\n"
+	return os.WriteFile(filepath.Join(dir, intoManifest), []byte(mf), 0644)
+}
@@ -0,0 +1,92 @@
+// Copyright 2024 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestInto(t *testing.T) {
+	root := t.TempDir()
+	pkg := filepath.Join(root, "src", "myapp", "core")
+	if err := os.MkdirAll(pkg, 0755); err != nil {
+		t.Fatal(err)
+	}
+	for _, fn := range []string{"src/myapp/__init__.py", "src/myapp/core/__init__.py"} {
+		if err := os.WriteFile(filepath.Join(root, fn), nil, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if _, err := intoPkgName(filepath.Join(root, "src")); err == nil {
+		t.Errorf("no error for a directory without an __init__.py")
+	}
+	cfg := NewBuildCfg()
+	cfg.Name = "mylib"
+	cfg.PkgPrefix = "."
+	if err := setInto(cfg, pkg); err != nil {
+		t.Fatal(err)
+	}
+	into, err := cleanInto(cfg.OutputDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := cfg.FullName(), "myapp.core._gopy"; got != want {
+		t.Errorf("full name: got %q, want %q", got, want)
+	}
+	if got, want := cfg.OutputDir, filepath.Join(pkg, "_gopy"); got != want {
+		t.Errorf("output dir: got %q, want %q", got, want)
+	}
+	if len(into) != 0 {
+		t.Errorf("files of a new _gopy dir: %v", into)
+	}
+
+	// a generation, and a file of the user
+	out := cfg.OutputDir
+	for _, fn := range []string{"__init__.py", "go.py", "old.py", "notes.txt"} {
+		if err := os.MkdirAll(out, 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(out, fn), nil, 0644); err != nil {
+			t.Fatal(err)
+		}
+		if fn == "old.py" {
+			if err := writeIntoManifest(out, map[string]bool{}); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+
+	// the regeneration removes the generated files, only
+	cfg = NewBuildCfg()
+	cfg.PkgPrefix = "."
+	if err := setInto(cfg, pkg); err != nil {
+		t.Fatal(err)
+	}
+	into, err = cleanInto(cfg.OutputDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(into) != 1 || !into["notes.txt"] {
+		t.Errorf("files kept by the regeneration: got %v, want notes.txt", into)
+	}
+
+	if err := os.Remove(filepath.Join(out, intoManifest)); err != nil {
+		t.Fatal(err)
+	}
+	cfg = NewBuildCfg()
+	cfg.PkgPrefix = "."
+	if err := setInto(cfg, pkg); err == nil {
+		t.Errorf("no error for a _gopy dir without a manifest")
+	}
+	cfg = NewBuildCfg()
+	cfg.PkgPrefix = "."
+	cfg.OutputDir = root
+	if err := setInto(cfg, pkg); err == nil {
+		t.Errorf("no error for -into with -output")
+	}
+}
@@ -28,10 +28,30 @@ type BuildCfg struct {
 	NoWarn bool
 	// do not generate a Makefile, e.g., when called from Makefile
 	NoMake bool
+	// pass time.Duration values as go.Duration ints instead of converting
+	// them to datetime.timedelta
+	NoTimedelta bool
+	// bind the plain value structs as python dataclasses, copied to and
+	// from Go instead of proxied by handles
+	Dataclass bool
+	// policy of the comma-ok functions when the bool is false: none or raise
+	CommaOk string
 	// link resulting library dynamically
 	DynamicLinking bool
 	// BuildTags to be passed into `go build`.
 	BuildTags string
+	// pinned standalone python version to build against, instead of VM
+	PythonVersion string
+	// maximum number of elements of the slices, arrays and maps returned as
+	// python lists and dicts, copied, instead of proxies of the Go values
+	CopyMax int
+	// JSON file the statistics of the generation are written to
+	StatsFile string
+	// JSON, or HTML if ending in .html, file the coverage report of the
+	// bindings is written to
+	ReportFile string
+	// config file the options were read from, if any, hashed by -incremental
+	ConfigFile string
 }
 
 // NewBuildCfg returns a newly constructed build config
@@ -49,6 +69,9 @@ func run(args []string) error {
 			gopyMakeCmdBuild(),
 			gopyMakeCmdPkg(),
 			gopyMakeCmdExe(),
+			gopyMakeCmdTestMatrix(),
+			gopyMakeCmdRelease(),
+			gopyMakeCmdQuickstart(),
 		},
 		Flag: *flag.NewFlagSet("gopy", flag.ExitOnError),
 	}
@@ -7,11 +7,13 @@ package main
 import (
 	"bytes"
 	"fmt"
+	"go/format"
 	"log"
 	"os"
 	"os/exec"
 	"path/filepath"
 	"reflect"
+	"regexp"
 	"sort"
 	"strings"
 	"testing"
@@ -22,33 +24,86 @@ import (
 var (
 	testBackends = map[string]string{}
 	features     = map[string][]string{
-		"_examples/hi":          []string{"py3"},
-		"_examples/gobytes":     []string{"py3"},
-		"_examples/funcs":       []string{"py3"},
-		"_examples/sliceptr":    []string{"py3"},
-		"_examples/simple":      []string{"py3"},
-		"_examples/empty":       []string{"py3"},
-		"_examples/named":       []string{"py3"},
-		"_examples/structs":     []string{"py3"},
-		"_examples/consts":      []string{"py3"},
-		"_examples/vars":        []string{"py3"},
-		"_examples/seqs":        []string{"py3"},
-		"_examples/cgo":         []string{"py3"},
-		"_examples/pyerrors":    []string{"py3"},
-		"_examples/iface":       []string{"py3"},
-		"_examples/pointers":    []string{"py3"},
-		"_examples/arrays":      []string{"py3"},
-		"_examples/slices":      []string{"py3"},
-		"_examples/maps":        []string{"py3"},
-		"_examples/gostrings":   []string{"py3"},
-		"_examples/rename":      []string{"py3"},
-		"_examples/lot":         []string{"py3"},
-		"_examples/unicode":     []string{"py3"},
-		"_examples/osfile":      []string{"py3"},
-		"_examples/gopygc":      []string{"py3"},
-		"_examples/cstrings":    []string{"py3"},
-		"_examples/pkgconflict": []string{"py3"},
-		"_examples/variadic":    []string{"py3"},
+		"_examples/hi":           []string{"py3"},
+		"_examples/gobytes":      []string{"py3"},
+		"_examples/funcs":        []string{"py3"},
+		"_examples/sliceptr":     []string{"py3"},
+		"_examples/simple":       []string{"py3"},
+		"_examples/empty":        []string{"py3"},
+		"_examples/named":        []string{"py3"},
+		"_examples/structs":      []string{"py3"},
+		"_examples/consts":       []string{"py3"},
+		"_examples/vars":         []string{"py3"},
+		"_examples/seqs":         []string{"py3"},
+		"_examples/cgo":          []string{"py3"},
+		"_examples/pyerrors":     []string{"py3"},
+		"_examples/iface":        []string{"py3"},
+		"_examples/pointers":     []string{"py3"},
+		"_examples/arrays":       []string{"py3"},
+		"_examples/slices":       []string{"py3"},
+		"_examples/maps":         []string{"py3"},
+		"_examples/gostrings":    []string{"py3"},
+		"_examples/rename":       []string{"py3"},
+		"_examples/lot":          []string{"py3"},
+		"_examples/unicode":      []string{"py3"},
+		"_examples/osfile":       []string{"py3"},
+		"_examples/gopygc":       []string{"py3"},
+		"_examples/cstrings":     []string{"py3"},
+		"_examples/pkgconflict":  []string{"py3"},
+		"_examples/variadic":     []string{"py3"},
+		"_examples/gotime":       []string{"py3"},
+		"_examples/gocontext":    []string{"py3"},
+		"_examples/goerrors":     []string{"py3"},
+		"_examples/ifaceembed":   []string{"py3"},
+		"_examples/gentests":     []string{"py3"},
+		"_examples/parallel":     []string{"py3"},
+		"_examples/gendocs":      []string{"py3"},
+		"_examples/pretty":       []string{"py3"},
+		"_examples/gotypes":      []string{"py3"},
+		"_examples/multipkg":     []string{"py3"},
+		"_examples/symfilter":    []string{"py3"},
+		"_examples/lossy":        []string{"py3"},
+		"_examples/namedret":     []string{"py3"},
+		"_examples/iterseq":      []string{"py3"},
+		"_examples/anontypes":    []string{"py3"},
+		"_examples/generics":     []string{"py3"},
+		"_examples/genhelpers":   []string{"py3"},
+		"_examples/structseq":    []string{"py3"},
+		"_examples/extembed":     []string{"py3"},
+		"_examples/recursive":    []string{"py3"},
+		"_examples/nested":       []string{"py3"},
+		"_examples/compound":     []string{"py3"},
+		"_examples/reexport":     []string{"py3"},
+		"_examples/operators":    []string{"py3"},
+		"_examples/ctxmgr":       []string{"py3"},
+		"_examples/iorw":         []string{"py3"},
+		"_examples/extrago":      []string{"py3"},
+		"_examples/pymixin":      []string{"py3"},
+		"_examples/strhandles":   []string{"py3"},
+		"_examples/structfields": []string{"py3"},
+		"_examples/threadchecks": []string{"py3"},
+		"_examples/durations":    []string{"py3"},
+		"_examples/complexnum":   []string{"py3"},
+		"_examples/errormap":     []string{"py3"},
+		"_examples/runes":        []string{"py3"},
+		"_examples/dataclass":    []string{"py3"},
+		"_examples/commaok":      []string{"py3"},
+		"_examples/asconv":       []string{"py3"},
+		"_examples/ifaceabc":     []string{"py3"},
+		"_examples/bulkfields":   []string{"py3"},
+		"_examples/cmakebuild":   []string{"py3"},
+		"_examples/frozen":       []string{"py3"},
+		"_examples/bazelbuild":   []string{"py3"},
+		"_examples/guarded":      []string{"py3"},
+		"_examples/genapp":       []string{"py3"},
+		"_examples/columns":      []string{"py3"},
+		"_examples/pooled":       []string{"py3"},
+		"_examples/copymax":      []string{"py3"},
+		"_examples/hooks":        []string{"py3"},
+		"_examples/borrow":       []string{"py3"},
+		"_examples/stdvalues":    []string{"py3"},
+		"_examples/argchecks":    []string{"py3"},
+		"_examples/asyncapi":     []string{"py3"},
 	}
 
 	testEnvironment = os.Environ()
@@ -99,6 +154,63 @@ func TestGofmt(t *testing.T) {
 	}
 }
 
+func TestGenDeterministic(t *testing.T) {
+	pyvm := testBackends["py3"]
+	if pyvm == "" {
+		t.Skip("no python3")
+	}
+	workdir, err := os.MkdirTemp("", "gopy-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(workdir)
+
+	// the outputs of the same command are byte-identical
+	out := filepath.Join(workdir, "out")
+	var gens []map[string][]byte
+	for i := 0; i < 3; i++ {
+		bind.ResetPackages()
+		os.RemoveAll(out)
+		if err := run([]string{"gen", "-vm=" + pyvm, "-output=" + out, "-no-warn", "./_examples/structs"}); err != nil {
+			t.Fatal(err)
+		}
+		files := make(map[string][]byte)
+		err := filepath.Walk(out, func(path string, info os.FileInfo, err error) error {
+			if err != nil || info.IsDir() {
+				return err
+			}
+			files[path], err = os.ReadFile(path)
+			return err
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		gens = append(gens, files)
+	}
+	bind.ResetPackages()
+	for fn, src := range gens[0] {
+		for i, files := range gens[1:] {
+			if !bytes.Equal(files[fn], src) {
+				t.Errorf("%s differs between the generations 0 and %d", fn, i+1)
+			}
+		}
+		if strings.HasSuffix(fn, ".go") {
+			if fsrc, err := format.Source(src); err != nil || !bytes.Equal(fsrc, src) {
+				t.Errorf("%s is not gofmt-ed: %v", fn, err)
+			}
+		}
+	}
+
+	// the go module checks the build id of the Go code of the extension
+	m := regexp.MustCompile(`_gopy_build_id = "([0-9a-f]+)"`).FindSubmatch(gens[0][filepath.Join(out, "go.py")])
+	if m == nil {
+		t.Fatalf("no build id in go.py")
+	}
+	if !bytes.Contains(gens[0][filepath.Join(out, "structs.go")], []byte(`C.CString("`+string(m[1])+`")`)) {
+		t.Errorf("structs.go does not return the build id %s of go.py", m[1])
+	}
+}
+
 func TestGoPyErrors(t *testing.T) {
 	pyvm := testBackends["py3"]
 	workdir, err := os.MkdirTemp("", "gopy-")
@@ -117,8 +229,8 @@ func TestGoPyErrors(t *testing.T) {
 		t.Fatalf("could not run %v: %+v\n", strings.Join(cmd.Args, " "), err)
 	}
 	contains := `--- Processing package: github.com/go-python/gopy/_examples/gopyerrors ---
-ignoring python incompatible function: .func github.com/go-python/gopy/_examples/gopyerrors.NotErrorMany() (int, int): func() (int, int): gopy: second result value must be of type error: func() (int, int)
-ignoring python incompatible method: gopyerrors.func (*github.com/go-python/gopy/_examples/gopyerrors.Struct).NotErrorMany() (int, string): func() (int, string): gopy: second result value must be of type error: func() (int, string)
+ignoring python incompatible function: .func github.com/go-python/gopy/_examples/gopyerrors.NotErrorMany() (int, int): func() (int, int): gopy: second result value must be of type error or bool: func() (int, int)
+ignoring python incompatible method: gopyerrors.func (*github.com/go-python/gopy/_examples/gopyerrors.Struct).NotErrorMany() (int, string): func() (int, string): gopy: second result value must be of type error or bool: func() (int, string)
 ignoring python incompatible method: gopyerrors.func (*github.com/go-python/gopy/_examples/gopyerrors.Struct).TooMany() (int, int, string): func() (int, int, string): gopy: too many results to return: func() (int, int, string)
 ignoring python incompatible function: .func github.com/go-python/gopy/_examples/gopyerrors.TooMany() (int, int, string): func() (int, int, string): gopy: too many results to return: func() (int, int, string)
 `
@@ -163,18 +275,32 @@ Hi()
 	
 	Hi prints hi from Go
 	
+	Args:
+	    goRun (bool, optional): run the call in a separate goroutine, without waiting for it to return
+	
 --- hi.Hi()...
 --- doc(hi.Hello)...
 Hello(str s) 
 	
 	Hello prints a greeting from Go
 	
+	Args:
+	    s (str): Go string (copied, O(len))
+	    goRun (bool, optional): run the call in a separate goroutine, without waiting for it to return
+	
 --- hi.Hello('you')...
 --- doc(hi.Add)...
 Add(int i, int j) int
 	
 	Add returns the sum of its arguments.
 	
+	Args:
+	    i (int): Go int (copied, O(1))
+	    j (int): Go int (copied, O(1))
+	
+	Returns:
+	    int: the sum of its arguments. Go int (copied, O(1))
+	
 --- hi.Add(1, 41)...
 42
 --- hi.Concat('4', '2')...
@@ -195,6 +321,9 @@ Greet() str
 		
 		Greet sends greetings
 		
+		Returns:
+		    str: Go string (copied, O(len))
+		
 --- p.Greet()...
 Hello, I am 
 --- p.String()...
@@ -236,9 +365,9 @@ hi.Couple{P1=hi.Person{Name="mom", Age=50}, P2=hi.Person{Name="bob", Age=51}}
 hi.Couple{P1=hi.Person{Name="p1", Age=42}, P2=hi.Person{Name="p2", Age=52}}
 hi.Couple{P1=hi.Person{Name="p1", Age=42}, P2=hi.Person{Name="p2", Age=52}}
 hi.Couple{P1=hi.Person{Name="p2", Age=52}, P2=hi.Person{Name="p1", Age=42}}
-caught: supplied argument type <class 'int'> is not a go.GoClass | err-type: <class 'TypeError'>
-caught: supplied argument type <class 'int'> is not a go.GoClass | err-type: <class 'TypeError'>
-caught: supplied argument type <class 'int'> is not a go.GoClass | err-type: <class 'TypeError'>
+caught: supplied argument type <class 'int'> is not a go.GoClass or dict | err-type: <class 'TypeError'>
+caught: supplied argument type <class 'int'> is not a go.GoClass or dict | err-type: <class 'TypeError'>
+caught: supplied argument type <class 'int'> is not a go.GoClass or dict | err-type: <class 'TypeError'>
 --- testing GC...
 --- len(objs): 100000
 --- len(vs): 100000
@@ -249,7 +378,7 @@ len(arr): 2
 arr[0]: 1
 arr[1]: 2
 arr[2]: caught: slice index out of range
-arr: hi.Array_2_int len: 2 handle: 300036 [1, 2]
+arr: hi.Array_2_int len: 2 handle: 300036 [1, 42]
 len(arr): 2
 mem(arr): caught: memoryview: a bytes-like object is required, not 'Array_2_int'
 --- testing slice...
@@ -437,8 +566,26 @@ c4 = c4
 c5 = 42
 c6 = 42
 c7 = 666.666
+big = 1267650600228229401496703205376
+big exact: True
+pi = 3.141592653589793
+third = 0.3333333333333333
+esc = 'tab\tquote"nl\né\x00😀'
+long = 87 0123456789
+f8 = 8.0
+f32 = 0.10000000149011612
+neg = -9223372036854775808
 k1 = 1
 k2 = 2
+rw = 3 True
+rw == ReadWrite: True
+Write in rw: True Exec in rw: False
+HasPerm(rw, Read): True
+HasPerm(rw, Exec): False
+HasPerm(3, 1): True
+AllPerms = 7 True
+Exec in AllPerms: True
+Kind is Enum: True
 OK
 `),
 	})
@@ -527,6 +674,10 @@ func TestBindInterfaces(t *testing.T) {
 iface.CallIface...
 t.F [CALLED]
 iface.CallIface... [DONE]
+impl.F [CALLED] 42
+iface.CallIface...
+impl.F [CALLED] 42
+iface.CallIface... [DONE]
 iface as string: test string
 iface as string: 42
 iface as handle: &{0 }
@@ -535,6 +686,10 @@ doc(iface): '\npackage iface tests various aspects of interfaces.\n\n'
 t = iface.T()
 t.F()
 iface.CallIface(t)
+v = iface.NewIface(42)
+isinstance(v, iface.Iface): True
+v.F()
+iface.CallIface(v)
 iface.IfaceString("test string"
 iface.IfaceString(str(42))
 iface.IfaceHandle(t)
@@ -587,7 +742,15 @@ func TestBuiltinArrays(t *testing.T) {
 		extras: nil,
 		want: []byte(`Python list: [1, 2, 3, 4]
 Go array:  arrays.Array_4_int len: 4 handle: 1 [1, 2, 3, 4]
+arrays.IntSum from Python list: 10
 arrays.IntSum from Go array: 10
+arrays.IntSum from Python tuple: 4
+arrays.IntSum from short list: Array_4_int.__init__ takes a sequence of 4 elements, not 2
+Go array after set: [10, 2, 3, 4]
+arrays.Norm1(Vec): 6.0
+arrays.Norm1(list): 2.0
+blk.Pos: [1.0, 2.0, 4.0]
+blk.Checksum(): 220
 OK
 `),
 	})
@@ -669,12 +832,16 @@ func TestBindRename(t *testing.T) {
 		path:   path,
 		lang:   features[path],
 		cmd:    "build",
-		extras: []string{"-rename"},
+		extras: []string{"-from-directives"}, // -rename
 		want: []byte(`say_hi_fn(): hi
 MyStruct().say_something(): something
+directive_fn(): directive fn
+MyStruct().directive_meth(): directive meth
+MyStruct(directive_property=...).directive_property: baz
 MyStruct.auto_renamed_property.__doc__: I should be renamed to auto_renamed_property
 		when generated with -rename flag
 MyStruct.custom_name.__doc__: I should be renamed to custom_name with the custom option
+MyStruct.directive_property.__doc__: I should be renamed to directive_property by the directive
 OK
 `),
 	})
@@ -759,6 +926,13 @@ func TestPYGC(t *testing.T) {
 1
 1
 0
+pressure: 0
+limit calls: [3]
+limit calls: [3, 3]
+num_handles: 3
+handle_stats: [('*gopygc.StructA', (2, 2)), ('*map[int]int', (1, 2))]
+dump_handles: [['refs=1', '*gopygc.StructA'], ['refs=1', '*gopygc.StructA'], ['refs=2', '*map[int]int']]
+num_handles: 0
 OK
 `),
 	})
@@ -795,162 +969,1624 @@ func TestPackagePrefix(t *testing.T) {
 		testdir:   ".",
 		want: []byte(`mypkg.mypkg.SayHello()...
 Hello
+submodules: ['go', 'mypkg']
+lazy go: mypkg.go True
 OK
 `),
 	})
 }
 
-func TestPkgConflict(t *testing.T) {
+func TestReexport(t *testing.T) {
 	// t.Parallel()
-	path := "_examples/pkgconflict"
+	path := "_examples/reexport"
+	testPkg(t, pkg{
+		path:      path,
+		lang:      features[path],
+		cmd:       "build",
+		outputdir: "outname",
+		pkgprefix: ".",
+		testdir:   ".",
+		extras:    []string{"-name=outname", "-reexport", "./" + path + "/..."},
+		want: []byte(`extra imported: False True
+hello, you
+sum: 3 answer: 42
+module: outname.reexport
+same: True
+all: True True
+extra.Twice: 4 extra imported: True
+OK
+`),
+	})
+}
+
+func TestOperators(t *testing.T) {
+	// t.Parallel()
+	path := "_examples/operators"
 	testPkg(t, pkg{
 		path:   path,
 		lang:   features[path],
 		cmd:    "build",
 		extras: nil,
-		want: []byte(`17 items are made of wool
-42 items are made of hyperlinks
-2 items are made of fuzz
+		want: []byte(`a + b: 4.0 6.0
+b - a: 2.0 2.0
+a * 3: 3.0 6.0
+-a: -1.0 -2.0
+a < b: True False
+a + 1: TypeError
+m + n: 200
+m / 3: 50
+m / 4: operators: cannot split the amount
+m == n: False True True
+hash(m): TypeError
+m - n: TypeError
+OK
 `),
 	})
 }
 
-// see notes in _examples/osfile/test.py for why this doesn't work..
-// leaving here for now in case someone wants to follow-up and make it work..
-//
-// func TestOsFile(t *testing.T) {
-// 	// t.Parallel()
-// 	path := "_examples/osfile"
-// 	testPkg(t, pkg{
-// 		path:   path,
-// 		lang:   features[path],
-// 		cmd:    "pkg",
-// 		extras: []string{"os"},
-// 		want: []byte(`yeah..
-// OK
-// `),
-// 	})
-// }
+func TestCtxMgr(t *testing.T) {
+	// t.Parallel()
+	path := "_examples/ctxmgr"
+	testPkg(t, pkg{
+		path:   path,
+		lang:   features[path],
+		cmd:    "build",
+		extras: nil,
+		want: []byte(`using db open: 1
+closed, open: 0
+caught ValueError: oops open: 0
+caught go.GoError: ctxmgr: already closed
+running: True
+stopped: True
+OK
+`),
+	})
+}
 
-func TestBindVariadic(t *testing.T) {
+func TestIORW(t *testing.T) {
 	// t.Parallel()
-	path := "_examples/variadic"
+	path := "_examples/iorw"
 	testPkg(t, pkg{
 		path:   path,
 		lang:   features[path],
 		cmd:    "build",
 		extras: nil,
-		want: []byte(`NonVariadic 1+[2+3+4]+5 = 15
-Variadic 1+2+3+4+5 = 15
-Variadic Struct s(1)+s(2)+s(3) = 6
-Variadic InterFace i(1)+i(2)+i(3) = 6
-Type OK
+		want: []byte(`Greet to StringIO: 'hello, you\n'
+Upper: 12 b'HELLO, WORLD'
+Count: 6
+write: 3 3 6
+read: b'ab' b'cdef' b''
+Greet to Buffer: b'hello, buffer\n'
+Lines: b'a\nb'
+caught go.GoError: ValueError: disk full
+OK
 `),
 	})
 }
 
-// Generate / verify SUPPORT_MATRIX.md from features map.
-func TestCheckSupportMatrix(t *testing.T) {
-	var buf bytes.Buffer
-	buf.WriteString("# Support matrix\n")
-	buf.WriteString(`
-NOTE: File auto-generated by TestCheckSupportMatrix in main_test.go. Please
-don't modify manually.
+func TestExtraGo(t *testing.T) {
+	// t.Parallel()
+	path := "_examples/extrago"
+	testPkg(t, pkg{
+		path:   path,
+		lang:   features[path],
+		cmd:    "build",
+		extras: []string{"-extra-go=_examples/extrago/shims"},
+		want: []byte(`extrago.Greet: hello, gopher
+extrago.DivMod: (3, 2)
+go.DivMod: (-3, -1)
+extrago.Shout: HELLO, GOPHER
+extrago.NumBound: True
+doc: DivMod returns the (quotient, remainder) tuple of a / b.
+caught ZeroDivisionError: division by zero
+has unbound: False
+OK
+`),
+	})
+}
 
-`)
+func TestPyMixin(t *testing.T) {
+	// t.Parallel()
+	path := "_examples/pymixin"
+	testPkg(t, pkg{
+		path:   path,
+		lang:   features[path],
+		cmd:    "build",
+		extras: []string{"-no-warn"},
+		files:  []string{"_mixins.py"},
+		want: []byte(`Greet: hello, Ada
+is_adult: True
+format: [  Ada]
+isinstance PersonMixin: True
+upper: ['A', 'B', 'C']
+generated __str__ kept: True
+len(Names): 3
+OK
+`),
+	})
+}
 
-	// Generate
-	// - sorted list of features
-	// - sorted list of backends
-	// - a map of feature to available backends
-	var featuresSorted []string
-	var allBackendsSorted []string
-	featureToBackendMap := make(map[string]map[string]bool)
-	allBackends := make(map[string]bool)
-	for feature, backends := range features {
-		featuresSorted = append(featuresSorted, feature)
-		featureToBackendMap[feature] = make(map[string]bool)
-		for _, backend := range backends {
-			featureToBackendMap[feature][backend] = true
-			allBackends[backend] = true
-		}
-	}
-	for backend, _ := range allBackends {
-		allBackendsSorted = append(allBackendsSorted, backend)
-	}
-	sort.Strings(featuresSorted)
-	sort.Strings(allBackendsSorted)
+func TestStrHandles(t *testing.T) {
+	// t.Parallel()
+	path := "_examples/strhandles"
+	testPkg(t, pkg{
+		path:   path,
+		lang:   features[path],
+		cmd:    "build",
+		extras: []string{"-handle=string"},
+		want: []byte(`handle type: str
+handle: *strhandles.Person
+Find bob: bob
+Find eve handle: ''
+Friends: ['bob']
+Friends handle: *[]*strhandles.Person
+Friends with None: 2
+Done(None): False
+Done(cancelled): True
+Ages handle: *map[string]int
+Ages: [('ann', 30), ('bob', 40)]
+OK
+`),
+	})
+}
 
-	// Write the table header and the line separating header and rows.
-	fmt.Fprintf(&buf, "Feature |%s\n", strings.Join(allBackendsSorted, " | "))
-	var tableDelimiters []string
-	for i := 0; i <= len(allBackendsSorted); i++ {
-		tableDelimiters = append(tableDelimiters, "---")
-	}
-	buf.WriteString(strings.Join(tableDelimiters, " | "))
-	buf.WriteString("\n")
+func TestStructFields(t *testing.T) {
+	// t.Parallel()
+	path := "_examples/structfields"
+	testPkg(t, pkg{
+		path:   path,
+		lang:   features[path],
+		cmd:    "build",
+		extras: nil,
+		want: []byte(`initial: a w=1 origin=00 path=1 box=0
+aliased: a,b w=1 h=2 origin=30 path=1 box=7
+Path[0]: 1 5
+Anchor: None Label: None
+set: a,b w=1 h=2 origin=30 anchor path=1 box=7 label=L
+Label.Name: L
+unset: a,b w=1 h=2 origin=30 path=1 box=7 None None
+Origin: 4 6
+Origin from dict: 1 2
+TypeError: True
+copied: x,y w=4 origin=12 path=1 box=7
+OK
+`),
+	})
+}
 
-	// Write the actual rows of the support matrix.
-	for _, feature := range featuresSorted {
-		var cells []string
-		cells = append(cells, feature)
-		for _, backend := range allBackendsSorted {
-			if featureToBackendMap[feature][backend] {
-				cells = append(cells, "yes")
-			} else {
-				cells = append(cells, "no")
-			}
-		}
-		buf.WriteString(strings.Join(cells, " | "))
-		buf.WriteString("\n")
-	}
+func TestThreadChecks(t *testing.T) {
+	// t.Parallel()
+	path := "_examples/threadchecks"
+	testPkg(t, pkg{
+		path:   path,
+		lang:   features[path],
+		cmd:    "build",
+		extras: []string{"-thread-checks"},
+		want: []byte(`N: 1
+ThreadError: threadchecks.Counter.Inc called from thread 'worker', but the Counter is owned by thread 'MainThread': it is not safe for concurrent use (see go.release_thread)
+ThreadError on field
+N after: 1
+N handed over: 3
+SafeCounter: 2
+OK
+`),
+	})
+}
 
-	if os.Getenv("GOPY_GENERATE_SUPPORT_MATRIX") == "1" {
-		err := os.WriteFile("SUPPORT_MATRIX.md", buf.Bytes(), 0644)
-		if err != nil {
-			log.Fatalf("Unable to write SUPPORT_MATRIX.md")
-		}
-		return
-	}
+func TestDurations(t *testing.T) {
+	// t.Parallel()
+	path := "_examples/durations"
+	testPkg(t, pkg{
+		path:   path,
+		lang:   features[path],
+		cmd:    "build",
+		extras: []string{"-no-timedelta"},
+		want: []byte(`Timeout: 1m30s go.Duration.parse('1m30s')
+isinstance int: True
+a.Len: 1h30m0s
+Total: 1h30m1.5s
+Nanos(1ms): 1000000
+-7ns / 2: -3ns Div: -3ns
+-7ns % 2: -1ns
+1h / 7m: 8
+Hour * 3: 3h0m0s Scale: 3h0m0s
+max + 1: -2562047h47m16.854775808s
+max * 2 == Scale: True
+compare: True True 1s
+hours: 1.5
+round: 1h15m30.918s
+truncate: 1h15m30s
+to_timedelta: datetime.timedelta(seconds=60, microseconds=1)
+from_timedelta: 1h0m0.000005s
+ValueError: time: unknown unit " hour" in duration "1 hour"
+a.Len: 2h0m0s
+OK
+`),
+	})
+}
 
-	src, err := os.ReadFile("SUPPORT_MATRIX.md")
-	if err != nil {
-		log.Fatalf("Unable to read SUPPORT_MATRIX.md")
-	}
+func TestComplexNum(t *testing.T) {
+	// t.Parallel()
+	path := "_examples/complexnum"
+	testPkg(t, pkg{
+		path:   path,
+		lang:   features[path],
+		cmd:    "build",
+		extras: nil,
+		want: []byte(`I: 1j
+Add(1+2j, 3-1j): (4+1j)
+Add(1, 0.5): (1.5+0j)
+Roots(4): [(1+0j), 1j, (-1+0j), -1j]
+Abs: [5.0, 5.0]
+Half: (1+2j)
+s.Samples: [(1+1j), (2-1j)]
+s.Gain: (1+0j)
+s.Sum(): 6j
+s.Sum() after: (-6+0j)
+s.Samples: [(5+0j), 1j, 1j]
+Apply: 2j
+TypeError for str
+OK
+`),
+	})
+}
 
-	msg := `
-This is a test case to verify the support matrix. This test is likely failing
-because the map features has been updated and the
-auto-generated file SUPPORT_MATRIX.md hasn't been updated. Please run 'go test'
-with environment variable GOPY_GENERATE_SUPPORT_MATRIX=1 to regenerate
-SUPPORT_MATRIX.md and commit the changes to SUPPORT_MATRIX.md onto git.
-`
-	if bytes.Compare(buf.Bytes(), src) != 0 {
-		t.Fatalf(msg)
-	}
+func TestErrorMap(t *testing.T) {
+	// t.Parallel()
+	path := "_examples/errormap"
+	testPkg(t, pkg{
+		path:   path,
+		lang:   features[path],
+		cmd:    "build",
+		extras: []string{"-errors=io/fs.ErrNotExist=FileNotFoundError,context.DeadlineExceeded=TimeoutError,*strconv.NumError=ValueError,~invalid=ValueError"},
+		want: []byte(`ErrNoKeyException is KeyError: 'get "python": no such key' (GoError: True)
+GoFileNotFoundError is FileNotFoundError: open /no/such/file: no such file or directory (GoError: True)
+GoValueError is ValueError: strconv.Atoi: parsing "x": invalid syntax (GoError: True)
+GoTimeoutError is TimeoutError: wait: context deadline exceeded (GoError: True)
+GoValueError is ValueError: invalid argument -1 (GoError: True)
+ErrClosedException is GoError: store closed (GoError: True)
+Get(gopher): go
+Check(1): no error
+OK
+`),
+	})
 }
 
-type pkg struct {
-	path      string
-	lang      []string
-	cmd       string
-	outputdir string
-	pkgprefix string
-	testdir   string
-	extras    []string
-	want      []byte
+func TestRunes(t *testing.T) {
+	// t.Parallel()
+	path := "_examples/runes"
+	testPkg(t, pkg{
+		path:   path,
+		lang:   features[path],
+		cmd:    "build",
+		extras: nil,
+		want: []byte(`Sep: ·
+Sep after: -
+Upper('é'): É
+Upper(97): A
+CodePoint('😀'): 128512
+Invalid: 0xfffd
+Reverse: olléh
+Count: 3
+Boxed: b
+Map: NAÏVE
+w.Text: élan
+w.Initial: é
+w.Mark: *
+w.Title(): Élan
+w.Title() after: Zèbre
+ValueError: Upper arg r: expected a single character str for Go rune, got 'ab'
+ValueError: Upper arg r: expected a single character str for Go rune, got ''
+ValueError: Upper arg r: invalid code point 55296 for Go rune
+ValueError: Upper arg r: invalid code point -1 for Go rune
+TypeError: Word.Initial: expected a single character str for Go rune, got float
+TypeError: Word.Text: expected a str for Go []rune, got list
+OK
+`),
+	})
 }
 
-func testPkg(t *testing.T, table pkg) {
-	backends := []string{"py3"}
-	// backends := table.lang // todo: enabling py2 testing requires separate "want" output
-	for _, be := range backends {
-		fmt.Printf("looping over backends: %s in %s\n", be, backends)
-		vm, ok := testBackends[be]
-		if !ok || vm == "" {
-			// backend not available.
-			t.Logf("Skipped testing backend %s for %s\n", be, table.path)
-			continue
+func TestDataclass(t *testing.T) {
+	// t.Parallel()
+	path := "_examples/dataclass"
+	testPkg(t, pkg{
+		path:   path,
+		lang:   features[path],
+		cmd:    "build",
+		extras: []string{"-dataclass"},
+		want: []byte(`is_dataclass(Point): True
+Point(): Point(X=0.0, Y=0.0)
+Add: Point(X=4.0, Y=6.5)
+NewPixel: Pixel(At=Point(X=1.0, Y=2.0), Color=1, Alpha=255, Label='dot', Mark='*', Next=None)
+Describe: dot at {1 2} color 1 alpha 255 mark é next {5 6}
+Pixel() == Pixel(): True
+Origin: Point(X=0.0, Y=0.0)
+Origin after: Point(X=7.0, Y=8.0)
+Farthest: Point(X=3.0, Y=4.0)
+Farthest([]): None
+Shift copy: Point(X=1, Y=1)
+path.Len(): 2
+path.Points[1]: Point(X=2.0, Y=3.0)
+path.Points: [Point(X=0.0, Y=1.0), Point(X=9.0, Y=9.0)]
+OverflowError: Pixel.Alpha: 300 overflows Go uint8
+registry: dataclass struct
+OK
+`),
+	})
+}
+
+func TestCommaOk(t *testing.T) {
+	// t.Parallel()
+	path := "_examples/commaok"
+	testPkg(t, pkg{
+		path: path,
+		lang: features[path],
+		cmd:  "build",
+		want: []byte(`Color('red'): 1
+Color('blue'): None
+MustColor('green'): 2
+MustColor('blue'): KeyError 'blue'
+c.Get('a').Value: alpha
+c.Get('b'): None
+c.Lookup('a'): alpha
+c.Lookup('b'): KeyError 'b'
+AsString('x'): x
+OK
+`),
+	})
+}
+
+func TestColumns(t *testing.T) {
+	// t.Parallel()
+	path := "_examples/columns"
+	testPkg(t, pkg{
+		path: path,
+		lang: features[path],
+		cmd:  "build",
+		want: []byte(`Sample dtype names: ('t', 'v', 'ok')
+Sample t: [0.0, 0.1, 0.2, 0.3]
+Sample v: [0.0, 0.5, 1.0, 1.5]
+Sample ok: [True, False, True, False]
+Sample[1]: 0.1 0.5 False
+Sample(0) len: 0
+Sample(-1) raised: columns: negative number of samples
+Histogram keys: ['bins', 'counts']
+Histogram bins: [0, 5, 10, 15, 20]
+Histogram counts: [3, 2, 1, 0, 1]
+Pairs dtype names: ('f0', 'f1')
+Pairs f1: [0, 1, 4]
+Ragged raised ValueError: columns.Ragged: columns of different lengths: a has 2 values, b has 1
+Names bound: False
+OK
+`),
+	})
+}
+
+func TestAsConv(t *testing.T) {
+	// t.Parallel()
+	path := "_examples/asconv"
+	testPkg(t, pkg{
+		path: path,
+		lang: features[path],
+		cmd:  "build",
+		want: []byte(`as_(Circle, shape).Radius: 2.0
+shape.Area() after setting the circle radius: 27.0
+as_(Named, shape).Name(): circle of radius 3
+as_(Square, square shape).Side: 4.0
+as_(Disc, circle).Radius: 3.0
+as_(Tags, names).Join(): a,b
+TypeError: as_: Go asconv.Square is not asconv.Circle
+TypeError: as_: Go asconv.Square is not asconv.Named
+TypeError: as_: expected a Go value, got int
+TypeError: as_: <class 'int'> is not the class of a bound Go type
+OK
+`),
+	})
+}
+
+func TestIfaceAbc(t *testing.T) {
+	// t.Parallel()
+	path := "_examples/ifaceabc"
+	testPkg(t, pkg{
+		path: path,
+		lang: features[path],
+		cmd:  "build",
+		want: []byte(`Square area: 16
+Incomplete: TypeError naming Scale: True
+Go shape area: 6.0
+isinstance(Go shape, AbstractShape): True
+isinstance(Rect(), AbstractShape): True
+isinstance(Point(), AbstractShape): False
+isinstance(Square, AbstractShape): True
+isinstance(Circle, AbstractShape): True
+isinstance(1, AbstractShape): False
+OK
+`),
+	})
+}
+
+func TestBulkFields(t *testing.T) {
+	// t.Parallel()
+	path := "_examples/bulkfields"
+	testPkg(t, pkg{
+		path: path,
+		lang: features[path],
+		cmd:  "build",
+		want: []byte(`after update: srv:8080 debug=true level=2 ratio=0.5 initial='x' 10 conns
+fields: ['Debug', 'Initial', 'Level', 'Limits', 'Name', 'Port', 'Ratio']
+Name, Port, Debug, Level, Ratio, Initial: srv 8080 True 2 0.5 x
+Limits.Conns: 10
+update(Port): OverflowError
+update(Name, Port): TypeError
+after failed updates: srv:8080 debug=true level=2 ratio=0.5 initial='x' 10 conns
+TypeError: update: bulkfields.Config has no field 'Nme'
+Entry fields: {'Key': 'k', 'Value': 3}
+OK
+`),
+	})
+}
+
+func TestCMakeBuild(t *testing.T) {
+	// t.Parallel()
+	path := "_examples/cmakebuild"
+	testPkg(t, pkg{
+		path:   path,
+		lang:   features[path],
+		cmd:    "build",
+		extras: []string{"-build=cmake"},
+		want: []byte(`Makefile: False
+project(gopy_cmakebuild C)
+add_custom_target(gen
+add_custom_target(cmakebuild_go DEPENDS ${SRC}/cmakebuild_go.a ${SRC}/cmakebuild_go.h)
+add_library(_cmakebuild SHARED ${SRC}/cmakebuild.c)
+add_dependencies(_cmakebuild cmakebuild_go)
+cmakebuild.Add(1, 2): 3
+OK
+`),
+	})
+}
+
+func TestFrozen(t *testing.T) {
+	// t.Parallel()
+	path := "_examples/frozen"
+	testPkg(t, pkg{
+		path:   path,
+		lang:   features[path],
+		cmd:    "build",
+		extras: []string{"-thread-checks", "-frozen=frozen\\.Settings$"},
+		want: []byte(`Point: (1, 2) 1 2
+AttributeError on Point.X
+hasattr update: False
+fields: {'X': 1, 'Y': 2}
+Moved: (2, 3) (1, 2)
+AttributeError on Settings.Limit
+Settings: svc 3
+seen: [(1, 2, 3), (1, 2, 3), (1, 2, 3), (1, 2, 3)]
+ThreadError on Cursor.Pos
+Cursor: 2
+OK
+`),
+	})
+}
+
+func TestBazelBuild(t *testing.T) {
+	// t.Parallel()
+	path := "_examples/bazelbuild"
+	testPkg(t, pkg{
+		path:   path,
+		lang:   features[path],
+		cmd:    "build",
+		extras: []string{"-build=bazel"},
+		want: []byte(`Makefile: False
+load("@io_bazel_rules_go//go:def.bzl", "go_binary")
+load("@rules_cc//cc:defs.bzl", "cc_binary")
+load("@rules_python//python:defs.bzl", "py_library")
+name = "bazelbuild_goimports",
+name = "bazelbuild_go",
+linkmode = "c-archive",
+"@com_github_go_python_gopy//gopyh",
+"@com_github_go_python_gopy//_examples/bazelbuild",
+name = "bazelbuild_c",
+name = "_bazelbuild.so",
+name = "bazelbuild",
+bazelbuild.Add(1, 2): 3
+OK
+`),
+	})
+}
+
+func TestGuarded(t *testing.T) {
+	// t.Parallel()
+	path := "_examples/guarded"
+	testPkg(t, pkg{
+		path:   path,
+		lang:   features[path],
+		cmd:    "build",
+		extras: []string{"-thread-checks"},
+		want: []byte(`Balance: 800 Ops: 1600
+RuntimeError: locked: the Go mutex of the Account is already held by this context manager
+hasattr locked: False
+OK
+`),
+	})
+}
+
+func TestPooled(t *testing.T) {
+	// t.Parallel()
+	path := "_examples/pooled"
+	testPkg(t, pkg{
+		path: path,
+		lang: features[path],
+		cmd:  "build",
+		want: []byte(`total: 4995.0
+handles released: True
+new Vec: 0.0 0.0
+shared Vec: 1.0 2.0
+hasattr release: False
+OK
+`),
+	})
+}
+
+func TestCopyMax(t *testing.T) {
+	// t.Parallel()
+	path := "_examples/copymax"
+	testPkg(t, pkg{
+		path:   path,
+		lang:   features[path],
+		cmd:    "build",
+		extras: []string{"-copy-max=10"},
+		want: []byte(`small: list [0, 1, 2]
+large: Slice_int 100 99
+counts: dict [('a', 2), ('b', 1), ('c', 1)]
+bytes: Slice_byte
+named: Ints 3
+small, copy_max 0: Slice_int
+large, copy_max 1000: list
+doc: True
+OK
+`),
+	})
+}
+
+func TestHooks(t *testing.T) {
+	// t.Parallel()
+	path := "_examples/hooks"
+	testPkg(t, pkg{
+		path:   path,
+		lang:   features[path],
+		cmd:    "build",
+		extras: []string{"-hooks=_examples/hooks/hooks.txtar"},
+		want: []byte(`sqrt: 2.0
+caught ValueError: Sqrt: negative x: -1
+after hooks.Counter.Add
+after hooks.Counter.Add
+n: 5
+calls: add=2,sqrt=1
+OK
+`),
+	})
+}
+
+func TestBorrow(t *testing.T) {
+	// t.Parallel()
+	path := "_examples/borrow"
+	testPkg(t, pkg{
+		path:   path,
+		lang:   features[path],
+		cmd:    "build",
+		extras: []string{"-borrow-checks"},
+		want: []byte(`hash of bytes == hash of str: True
+hash of memoryview == hash of str: True
+hash of bytearray == hash of str: True
+count: 2
+lines: 1002
+caught TypeError: a bytes-like object is required, not 'int'
+caught RuntimeError: gopy: borrow.Scan arg data: borrowed buffer changed during the call
+buf: xxx
+OK
+`),
+	})
+}
+
+func TestStdValues(t *testing.T) {
+	// t.Parallel()
+	path := "_examples/stdvalues"
+	testPkg(t, pkg{
+		path:   path,
+		lang:   features[path],
+		cmd:    "build",
+		extras: nil,
+		want: []byte(`Loopback(): IPv4Address('127.0.0.1')
+IsPrivate(10.1.2.3): True
+IsPrivate('8.8.8.8'): False
+Mask(2001:db8::1, 32): 2001:db8::
+caught ValueError: 'not an ip' does not appear to be an IPv4 or IPv6 address
+Parse(): 'https://example.com:8080/a?q=1'
+Host(): example.com:8080
+WithPath(): https://example.com/b
+caught ValueError: parse "http://[::1": missing ']' in host
+Zone(): zoneinfo.ZoneInfo(key='Europe/Paris')
+ZoneName(ZoneInfo): Asia/Tokyo
+ZoneName(None): Local
+Offset(Asia/Tokyo): 32400
+Offset(timezone(+2h)): 7200
+Zone(Local): None
+s.Addr: IPv4Address('192.168.1.1')
+s.URL: http://192.168.1.1/status
+s.TZ: UTC
+OK
+`),
+	})
+}
+
+func TestArgChecks(t *testing.T) {
+	// t.Parallel()
+	path := "_examples/argchecks"
+	testPkg(t, pkg{
+		path:   path,
+		lang:   features[path],
+		cmd:    "build",
+		extras: nil,
+		want: []byte(`Sqrt(4): 2.0
+caught ErrNegativeException: negative number
+Greet(go): hello go
+caught ArgumentError: empty name
+caught ErrInvalidException: invalid argument: name too long
+caught ValueError: bad range 5-2
+Clamp(1, 2, 5): 2
+Scale: 0.5 1.0
+caught ArgumentError: argchecks.Scale: invalid args, want 0 <= p && p <= 1
+caught ArgumentError: argchecks.Scale: invalid args, want 0 <= p && p <= 1
+caught ArgumentError: argchecks.Scale: invalid args, want len(vals) > 0
+caught ErrInvalidException: invalid argument
+N: 3
+calls: 5
+OK
+`),
+	})
+}
+
+func TestAsyncAPI(t *testing.T) {
+	// t.Parallel()
+	path := "_examples/asyncapi"
+	testPkg(t, pkg{
+		path:   path,
+		lang:   features[path],
+		cmd:    "build",
+		extras: []string{"-async"},
+		want: []byte(`Slow(1, 0): 2
+Join(-, a, b): a-b
+Slow_async: [0, 2, 4, 6, 8]
+Slow_async calls ran concurrently: True
+Join_async(-, a, b): a-b
+Wait_async(10): ''
+Fail_async caught go.GoError: failed
+c.Add_async(2): 3
+N: 3
+OK
+`),
+	})
+}
+
+func TestPkgConflict(t *testing.T) {
+	// t.Parallel()
+	path := "_examples/pkgconflict"
+	testPkg(t, pkg{
+		path:   path,
+		lang:   features[path],
+		cmd:    "build",
+		extras: nil,
+		want: []byte(`17 items are made of wool
+42 items are made of hyperlinks
+2 items are made of fuzz
+`),
+	})
+}
+
+// see notes in _examples/osfile/test.py for why this doesn't work..
+// leaving here for now in case someone wants to follow-up and make it work..
+//
+// func TestOsFile(t *testing.T) {
+// 	// t.Parallel()
+// 	path := "_examples/osfile"
+// 	testPkg(t, pkg{
+// 		path:   path,
+// 		lang:   features[path],
+// 		cmd:    "pkg",
+// 		extras: []string{"os"},
+// 		want: []byte(`yeah..
+// OK
+// `),
+// 	})
+// }
+
+func TestBindVariadic(t *testing.T) {
+	// t.Parallel()
+	path := "_examples/variadic"
+	testPkg(t, pkg{
+		path:   path,
+		lang:   features[path],
+		cmd:    "build",
+		extras: nil,
+		want: []byte(`NonVariadic 1+[2+3+4]+5 = 15
+Variadic 1+2+3+4+5 = 15
+Variadic Struct s(1)+s(2)+s(3) = 6
+Variadic InterFace i(1)+i(2)+i(3) = 6
+Type OK
+`),
+	})
+}
+
+func TestGoTime(t *testing.T) {
+	// t.Parallel()
+	path := "_examples/gotime"
+	testPkg(t, pkg{
+		path:   path,
+		lang:   features[path],
+		cmd:    "build",
+		extras: nil,
+		want: []byte(`gotime.Date(2024, 2, 29): 2024-02-29T00:00:00+00:00
+isinstance datetime: True
+gotime.Unix(aware): 946684800
+gotime.Unix(naive): 946684800
+gotime.Unix(-05:00): 946702800
+gotime.Minutes(90): datetime.timedelta(seconds=5400)
+gotime.String(timedelta(days=1, microseconds=5)): 24h0m0.000005s
+gotime.String(-timedelta(seconds=1.5)): -1.5s
+gotime.Add: 2024-02-29T01:00:00.000250+00:00
+gotime.Sub: datetime.timedelta(seconds=3600, microseconds=250)
+gotime.Epoch(): 1970-01-01T00:00:00+00:00
+e.Start: 2024-01-02T09:30:00+00:00
+e.Len: datetime.timedelta(seconds=2700)
+e.End(): 2024-01-02T10:15:00+00:00
+e.End() after e.Len = 2h: 2024-01-02T11:30:00+00:00
+caught: expected a datetime.datetime
+OK
+`),
+	})
+}
+
+func TestGoContext(t *testing.T) {
+	// t.Parallel()
+	path := "_examples/gocontext"
+	testPkg(t, pkg{
+		path:   path,
+		lang:   features[path],
+		cmd:    "build",
+		extras: nil,
+		want: []byte(`gocontext.Done(): False
+gocontext.Done(background): False
+gocontext.Sleep(10): ok
+ctx.err() before cancel: ''
+ctx.err() after cancel: context canceled
+gocontext.Done(ctx): True
+caught: context deadline exceeded
+timeout error: True True
+cancelled: GoCancelledError context canceled
+c.Run(3, ctx=child): 3
+child.err() after with: context canceled
+c.Run(3, ctx=child) after with: 3
+c.Run(2): 5
+OK
+`),
+	})
+}
+
+func TestGoErrors(t *testing.T) {
+	// t.Parallel()
+	path := "_examples/goerrors"
+	testPkg(t, pkg{
+		path:   path,
+		lang:   features[path],
+		cmd:    "build",
+		extras: nil,
+		want: []byte(`goerrors.Find('gopher'): 42
+caught ErrNotFoundException: find "nobody": not found
+caught PathErrorException: open /etc/shadow: permission denied
+caught ErrPermissionException: permission denied
+caught CodeException: error code 7
+caught go.GoError: other error GoError
+caught PathErrorException: load config: open /etc/shadow: permission denied *fmt.wrapError
+  caused by PathErrorException: *goerrors.PathError: open /etc/shadow: permission denied *goerrors.PathError
+  caused by ErrPermissionException: *errors.errorString: permission denied *errors.errorString
+ErrNotFoundException is a go.GoError: True
+go.GoError is a RuntimeError: True
+caught RuntimeError: ErrNotFoundException
+OK
+`),
+	})
+}
+
+func TestIfaceEmbed(t *testing.T) {
+	// t.Parallel()
+	path := "_examples/ifaceembed"
+	testPkg(t, pkg{
+		path:   path,
+		lang:   features[path],
+		cmd:    "build",
+		extras: nil,
+		want: []byte(`ReadWriter bases: ['Reader', 'Writer']
+ReadWriteCloser bases: ['ReadWriter', 'Closer']
+isinstance(rwc, ReadWriter): True
+isinstance(rwc, Reader): True
+isinstance(rwc, Closer): True
+rwc.Read(): a
+rwc: buffer(b,c, closed=false)
+rwc after Close: buffer(b,c, closed=true)
+ifaceembed.ReadAll(rwc): ['b', 'c']
+OK
+`),
+	})
+}
+
+func TestGenTests(t *testing.T) {
+	// t.Parallel()
+	path := "_examples/gentests"
+	testPkg(t, pkg{
+		path:   path,
+		lang:   features[path],
+		cmd:    "build",
+		extras: []string{"-gen-tests"},
+		want: []byte(`test_const_Answer passed
+test_const_Blue passed
+test_const_Enabled passed
+test_const_Green passed
+test_const_Name passed
+test_const_Red passed
+test_func_Fail passed
+test_func_Origin passed
+test_func_Reset passed
+test_import passed
+test_struct_Point passed
+test_var_Count passed
+test_var_Default passed
+test_var_Label passed
+OK
+`),
+	})
+}
+
+func TestGenApp(t *testing.T) {
+	// t.Parallel()
+	path := "_examples/genapp"
+	testPkg(t, pkg{
+		path:   path,
+		lang:   features[path],
+		cmd:    "build",
+		extras: []string{"-gen-app"},
+		want: []byte(`== genapp constants and variables ==
+== genapp struct Counter ==
+== genapp struct Rect ==
+== genapp struct Task ==
+not picklable: json: unsupported type: func()
+== genapp functions ==
+genapp.Broken() raised: GoError('broken')
+== genapp functions called in goroutines ==
+== Go slices, proxied by handles ==
+== handles of the Go values held by python ==
+OK
+`),
+	})
+}
+
+func TestParallel(t *testing.T) {
+	// t.Parallel()
+	path := "_examples/parallel"
+	testPkg(t, pkg{
+		path:   path,
+		lang:   features[path],
+		cmd:    "build",
+		extras: nil,
+		want: []byte(`parallel_map(Fib): [0, 1, 1, 2, 3, 5, 8, 13, 21, 34]
+go.parallel_map(lambda): [2, 3, 4]
+parallel_map(empty): []
+parallel_map(Square): [0, 1, 4, 9, 16, 25, 36, 49]
+Square calls ran concurrently: True
+Square calls with one worker ran concurrently: False
+caught go.GoError: negative: -2
+caught ValueError: python error 7
+run(Square): 9
+run(Square) results: [0, 1, 4, 9]
+run calls ran concurrently: True
+run done callback: [55]
+run caught go.GoError: negative: -1
+run caught ValueError: python error 3
+run_async(Square): 16
+event loop ran meanwhile: True
+recv_async: (0, True)
+recv_async: (1, True)
+recv_async closed: (0, False)
+async for: [0, 1, 4, 9, 16]
+run_async caught go.GoError: negative: -5
+OK
+`),
+	})
+}
+
+func TestGenDocs(t *testing.T) {
+	// t.Parallel()
+	path := "_examples/gendocs"
+	testPkg(t, pkg{
+		path:   path,
+		lang:   features[path],
+		cmd:    "build",
+		extras: []string{"-doc=rst", "-doc-conf"},
+		want: []byte(`docs: ['conf.py', 'gendocs.rst', 'index.rst']
+===============
+Package gendocs
+===============
+
+.. py:module:: gendocs
+
+package gendocs tests the generated API reference docs (-doc).
+
+Constants
+---------
+
+.. py:data:: Answer
+   :value: 42
+
+   Answer is the answer to everything.
+
+Variables
+---------
+
+Go package variables are accessed by getter and setter functions.
+
+.. py:function:: Verbose()
+
+   Verbose gets Go variable gendocs.Verbose
+
+   Verbose enables verbose output.
+
+   :returns: Go bool
+   :rtype: bool
+
+.. py:function:: Set_Verbose(value)
+
+   Set_Verbose sets Go variable gendocs.Verbose
+
+   :param value: Go bool
+   :type value: bool
+
+Exceptions
+----------
+
+.. py:exception:: ErrEmptyException(go.GoError)
+
+   ErrEmptyException is raised for Go errors matching gendocs.ErrEmpty
+
+   ErrEmpty is returned for empty names.
+
+Interfaces
+----------
+
+.. py:class:: Shape
+
+   Shape has an area.
+
+   .. py:method:: Area()
+
+      Area returns the area of the shape.
+
+      :returns: the area of the shape. Go float64 (copied, O(1))
+      :rtype: float
+
+Classes
+-------
+
+.. py:class:: Rect(*args, **kwargs)
+
+   Rect is a rectangle.
+
+   Args are the field values, in order or by name, or handle=, the handle of an existing Go object.
+
+   .. py:property:: W
+      :type: float
+
+      W is the width.
+
+   .. py:property:: H
+      :type: float
+
+      H is the height.
+
+   .. py:method:: Area()
+
+      Area returns the area of the rectangle.
+
+      :returns: the area of the rectangle. Go float64 (copied, O(1))
+      :rtype: float
+
+   .. py:method:: Scale(f, goRun=False)
+
+      Scale scales the rectangle by f.
+
+      :param f: Scale scales the rectangle by f. Go float64 (copied, O(1))
+      :type f: float
+      :param goRun: run the call in a separate goroutine, without waiting for it to return
+      :type goRun: bool, optional
+
+Functions
+---------
+
+.. py:function:: Greet(name)
+
+   Greet returns a greeting for name.
+
+   :param name: Greet returns a greeting for name. Go string (copied, O(len))
+   :type name: str
+   :returns: a greeting for name. Go string (copied, O(len))
+   :rtype: str
+   :raises go.GoError: if the Go error result is not nil, or a subclass of it matching the error
+
+.. py:function:: NewRect(w, h)
+
+   NewRect returns a new rectangle of the given width and height.
+
+   :param w: Go float64 (copied, O(1))
+   :type w: float
+   :param h: Go float64 (copied, O(1))
+   :type h: float
+   :returns: a new rectangle of the given width and height. Go *Rect (proxied by handle, O(1))
+   :rtype: object
+
+.. py:function:: Sum(*args)
+
+   Sum returns the sum of the values.
+
+   :param \*args: Go ...int (copied into a new slice, O(n))
+   :returns: the sum of the values. Go int (copied, O(1))
+   :rtype: int
+
+
+OK
+`),
+	})
+}
+
+func TestPretty(t *testing.T) {
+	// t.Parallel()
+	path := "_examples/pretty"
+	testPkg(t, pkg{
+		path:   path,
+		lang:   features[path],
+		cmd:    "build",
+		extras: []string{"-pretty"},
+		want: []byte(`--- s.pretty():
+(*pretty.Shape) {
+  Name: (string) "triangle",
+  Points: ([]pretty.Point) (len=3) {
+    (pretty.Point) {
+      X: (int) 0,
+      Y: (int) 0,
+    },
+    (pretty.Point) {
+      X: (int) 4,
+      Y: (int) 0,
+    },
+    (pretty.Point) {
+      X: (int) 0,
+      Y: (int) 3,
+    },
+  },
+  Tags: (map[string]string) (len=2) {
+    (string) "color": (string) "red",
+    (string) "kind": (string) "polygon",
+  },
+  Parent: (*pretty.Shape) {
+    Name: (string) "root",
+    Points: ([]pretty.Point) <nil>,
+    Tags: (map[string]string) <nil>,
+    Parent: (*pretty.Shape) <nil>,
+    area: (float64) 0,
+  },
+  area: (float64) 6,
+}
+--- s.to_yaml():
+name: triangle
+Points:
+  - X: 0
+    Y: 0
+  - X: 4
+    Y: 0
+  - X: 0
+    Y: 3
+Tags:
+  color: red
+  kind: polygon
+Parent:
+  name: root
+  Points: []
+  Tags: {}
+  Parent: null
+--- p.pretty():
+(*pretty.Point) {
+  X: (int) 1,
+  Y: (int) 2,
+}
+--- p.to_yaml():
+X: 1
+Y: 2
+OK
+`),
+	})
+}
+
+func TestGoTypes(t *testing.T) {
+	// t.Parallel()
+	path := "_examples/gotypes"
+	testPkg(t, pkg{
+		path:   path,
+		lang:   features[path],
+		cmd:    "build",
+		extras: nil,
+		want: []byte(`gotypes.Author: struct Author
+gotypes.Book: struct Book
+gotypes.Genre: enum Genre
+gotypes.Shelf: slice Shelf
+gotypes.Titled: interface Titled
+Book fields: [('Title', 'string', 'str'), ('Pages', 'int', 'int'), ('Genre', 'Genre', 'int'), ('Author', 'Author', 'object')]
+Book methods: [('Describe', ('lang',), ('str',))]
+Book doc: Book is a book, by an author.
+Author.Name doc: Name is the full name of the author.
+Titled methods: [('GetTitle', 'GetTitle returns the title.')]
+to_dict(book): [('Author', {'Name': 'Keats'}), ('Genre', 1), ('Pages', 42), ('Title', 'Odes')]
+OK
+`),
+	})
+}
+
+func TestMultiPkg(t *testing.T) {
+	// t.Parallel()
+	path := "_examples/multipkg"
+	testPkg(t, pkg{
+		path:   path,
+		lang:   features[path],
+		cmd:    "build",
+		extras: []string{"-recursive"},
+		want: []byte(`unit: geom Rect 1.0
+scaled: 4.0 6.0 24.0
+OK
+`),
+	})
+}
+
+func TestSymFilter(t *testing.T) {
+	// t.Parallel()
+	path := "_examples/symfilter"
+	testPkg(t, pkg{
+		path:   path,
+		lang:   features[path],
+		cmd:    "build",
+		extras: []string{`-exclude=symfilter\.Debug`},
+		want: []byte(`Version: 1.0
+has Config: True
+has Cache: False
+has NewCache: False
+has DebugDump: False
+config prod
+Config has Name: True
+Config has Secret: False
+Config has Cache: False
+Config has Mu: False
+Config has Token: False
+Config has Raw: True
+Config has Describe: True
+Config has Reset: False
+OK
+`),
+	})
+}
+
+func TestLossy(t *testing.T) {
+	// t.Parallel()
+	path := "_examples/lossy"
+	testPkg(t, pkg{
+		path:   path,
+		lang:   features[path],
+		cmd:    "build",
+		extras: []string{"-lossy-checks"},
+		want: []byte(`Scale(0.5, 3): 1.5
+Next(1): 2
+Offset(1<<64 - 2, -1): 18446744073709551613
+LossyConversionWarning: Scale arg f: 0.1 loses precision as Go float32
+OverflowError: Scale arg n: 70000 overflows Go uint16
+OverflowError: Next arg l: 256 overflows Go uint8
+OverflowError: Offset arg base: 18446744073709551616 overflows Go uint64
+OverflowError: Offset arg d: -129 overflows Go int8
+OverflowError: Sample.Count: 8589934592 overflows Go uint32
+OverflowError: lossy.Max: -1 overflows Go uint64
+Count: 0
+OK
+`),
+	})
+}
+
+// Generate / verify SUPPORT_MATRIX.md from features map.
+func TestNamedRet(t *testing.T) {
+	// t.Parallel()
+	path := "_examples/namedret"
+	testPkg(t, pkg{
+		path:   path,
+		lang:   features[path],
+		cmd:    "build",
+		extras: nil,
+		want: []byte(`Naked(): 42
+NakedErr(21): 42
+NakedErr(-1) raised: namedret: negative value
+Named('ab'): abab
+BlankResult(1): 2
+OnlyErr(True) raised: namedret: negative value
+has Grouped: False
+BlankArgs(1, 2, 'x'): 2
+Shadow(1, 2, 3, 'abcd'): 10
+ShadowErr(cret=5): 5
+Apply(lambda x: x*3, 5): 15
+c.Incr(): 1
+c.Incr(): 2
+c.Decr(): 1
+c.Value().N: 1
+c.Decr() raised: namedret: negative value
+OK
+`),
+	})
+}
+
+func TestIterSeq(t *testing.T) {
+	// t.Parallel()
+	path := "_examples/iterseq"
+	testPkg(t, pkg{
+		path:   path,
+		lang:   features[path],
+		cmd:    "build",
+		extras: nil,
+		want: []byte(`list(Count(4)): [0, 1, 2, 3]
+sum(Count(10)): 45
+next(Naturals()): 0 1
+Stopped: 2
+dict(Enumerate): {0: 'a', 1: 'b', 2: 'c'}
+list(Nil()): []
+Items: [('x', 1), ('y', 2), ('z', 3)]
+Ptrs: [10, 20, 30]
+Counts: [('x', 10), ('y', 20), ('z', 30)]
+Checked: ['x', 'y', 'z']
+empty Checked raised: iterseq: empty bag
+has Sum: False
+OK
+`),
+	})
+}
+
+func TestAnonTypes(t *testing.T) {
+	// t.Parallel()
+	path := "_examples/anontypes"
+	testPkg(t, pkg{
+		path:   path,
+		lang:   features[path],
+		cmd:    "build",
+		extras: nil,
+		want: []byte(`c.Name: cfg
+c.Max(): 10
+Local(): 3
+h.Count: 3
+has Point: False
+has SetPoint: False
+has Helper: False
+has NewEngine: False
+has Speed: False
+has Config.Engine: False
+has Config.Limits: False
+has Config.Tags: False
+has Holder.Engine: False
+OK
+`),
+	})
+}
+
+func TestGenerics(t *testing.T) {
+	// t.Parallel()
+	path := "_examples/generics"
+	testPkg(t, pkg{
+		path:   path,
+		lang:   features[path],
+		cmd:    "build",
+		extras: nil,
+		want: []byte(`s.Name: ints
+s.Len(): 3
+s.Pop(): 3
+s.Items: [1, 2]
+ss.Pop(): a
+ss.Pop() raised: generics: empty stack strs
+fs: floats 1 Stack_float64
+ps.Pop().X: 3
+p: a=1 Pair_string_int
+q: 1 a Pair_int_string
+Sum_int: 6
+Sum_float64: 0.75
+has Stack: False
+has Sum: False
+has Map: False
+has Number: False
+OK
+`),
+	})
+}
+
+func TestGenHelpers(t *testing.T) {
+	// t.Parallel()
+	path := "_examples/genhelpers"
+	testPkg(t, pkg{
+		path:   path,
+		lang:   features[path],
+		cmd:    "build",
+		extras: nil,
+		want: []byte(`Max_int: 2
+Max_float64: 1.5
+Max_string: abd
+Min_int: 1
+Clamp_int: 10
+Clamp_float64: 0.5
+Clamp_string: k
+Abs_int: 3
+Scale_int_float64: 1.5
+Larger_int: 4
+has Abs_float64: False
+has Abs_string: False
+has Equal_int: False
+has Larger_float64: False
+has Smaller_int: False
+has Max: False
+OK
+`),
+	})
+}
+
+func TestStructSeq(t *testing.T) {
+	// t.Parallel()
+	path := "_examples/structseq"
+	testPkg(t, pkg{
+		path:   path,
+		lang:   features[path],
+		cmd:    "build",
+		extras: nil,
+		want: []byte(`len(items): 2
+items[0]: a 1 a
+names: ['a', 'b']
+Total(items): 41
+Total(list): 7
+ptrs: ['x', 'y', None]
+ptrs[-1]: None
+TotalPtrs(ptrs): 16
+TotalPtrs(list): 3
+b.Items: ['a', 'b']
+b.Items: [('n', 9)]
+b.Ptrs: ['p']
+OK
+`),
+	})
+}
+
+func TestExtEmbed(t *testing.T) {
+	// t.Parallel()
+	path := "_examples/extembed"
+	testPkg(t, pkg{
+		path:   path,
+		lang:   features[path],
+		cmd:    "build",
+		extras: nil,
+		want: []byte(`d.Heading(): # notes
+d.Len(): 11
+d.String(): hello world
+str(d): hello world
+d.Len() after Reset: 0
+c.Incr(): 1
+c.TryLock(): True
+c.Incr(): 2
+c.N: 2
+OK
+`),
+	})
+}
+
+func TestRecursive(t *testing.T) {
+	// t.Parallel()
+	path := "_examples/recursive"
+	testPkg(t, pkg{
+		path:   path,
+		lang:   features[path],
+		cmd:    "build",
+		extras: nil,
+		want: []byte(`root.Sum(): 10
+len(root.Children): 2
+root.Children[0].Children[0].Val: 4
+c.Tree.Root.Val: 1
+c.Next: None
+list: [6, 5, 1]
+root.Next.Next.Val: 1
+cycle in str(root): True
+cycle in str(t): True
+root.Next: None
+Depth(Nest(3)): 3
+Depth(Nest(3)[0]): 2
+d keys: ['a'] ['b'] ['c']
+len(d['a']['b']['c']): 0
+OK
+`),
+	})
+}
+
+func TestNested(t *testing.T) {
+	// t.Parallel()
+	path := "_examples/nested"
+	testPkg(t, pkg{
+		path:   path,
+		lang:   features[path],
+		cmd:    "build",
+		extras: nil,
+		want: []byte(`s['a']: [1.0, 2.0]
+s.to_dict(): [('a', [1.0, 2.0]), ('b', [3.0])]
+SumSeries(s): 6.0
+SumSeries(dict): 4.0
+s['c'] set from list: [4.0, 5.0]
+f['x'].Name: x
+f['none']: None
+CountFoos(f): 11
+CountFoos(dict): 3
+v['y'].N: 2
+CountFooVals(dict): 9
+g.to_list(): [[1, 2], [3]]
+GridSum(list): 10
+g.to_list() after append and set: [[7], [3], [5, 6]]
+t.Cols.to_dict(): {'u': [1.0]}
+t.Rows.to_list(): [[1], [2, 3]]
+from_dict copy, original: [9.0, 2.0] [1.0, 2.0]
+from_list: [[1], [2, 3]] 6
+zero(Foo): True 0
+zero(Map_string_Slice_float64): {}
+zero(int), zero(str): 0 ''
+unpickled Foo: p 7
+unpickled Table: {'u': [1.0]} [[0], [2, 3]] [[1], [2, 3]]
+copied Foo: 1 2
+copied Table: [[8], [2, 3]] [[8], [9]] ['u'] ['u', 'v']
+Foo ==: True True 1 1
+Table ==: False True
+OK
+`),
+	})
+}
+
+func TestCompound(t *testing.T) {
+	// t.Parallel()
+	path := "_examples/compound"
+	testPkg(t, pkg{
+		path:   path,
+		lang:   features[path],
+		cmd:    "build",
+		extras: nil,
+		want: []byte(`g['a'][0].Name: a1
+g['a'][1]: None
+Count(g): 3
+Count(dict): 2
+r[1]['z'].Name: z
+Size(r): 3
+Size(list): 1
+d['o']['i'].to_list(): [1, 2]
+d.to_dict(): {'o': {'i': [1, 2]}}
+Cube().to_list(): [[[1.0, 2.0], [3.0]], [[4.0]]]
+Produce names: ['a', 'b']
+recv ok after close: False
+len(ch), ch.cap(): 2 3
+Consume(ch): 1
+Double: [2, 4, 6]
+OK
+`),
+	})
+}
+
+func TestCheckSupportMatrix(t *testing.T) {
+	var buf bytes.Buffer
+	buf.WriteString("# Support matrix\n")
+	buf.WriteString(`
+NOTE: File auto-generated by TestCheckSupportMatrix in main_test.go. Please
+don't modify manually.
+
+`)
+
+	// Generate
+	// - sorted list of features
+	// - sorted list of backends
+	// - a map of feature to available backends
+	var featuresSorted []string
+	var allBackendsSorted []string
+	featureToBackendMap := make(map[string]map[string]bool)
+	allBackends := make(map[string]bool)
+	for feature, backends := range features {
+		featuresSorted = append(featuresSorted, feature)
+		featureToBackendMap[feature] = make(map[string]bool)
+		for _, backend := range backends {
+			featureToBackendMap[feature][backend] = true
+			allBackends[backend] = true
+		}
+	}
+	for backend, _ := range allBackends {
+		allBackendsSorted = append(allBackendsSorted, backend)
+	}
+	sort.Strings(featuresSorted)
+	sort.Strings(allBackendsSorted)
+
+	// Write the table header and the line separating header and rows.
+	fmt.Fprintf(&buf, "Feature |%s\n", strings.Join(allBackendsSorted, " | "))
+	var tableDelimiters []string
+	for i := 0; i <= len(allBackendsSorted); i++ {
+		tableDelimiters = append(tableDelimiters, "---")
+	}
+	buf.WriteString(strings.Join(tableDelimiters, " | "))
+	buf.WriteString("\n")
+
+	// Write the actual rows of the support matrix.
+	for _, feature := range featuresSorted {
+		var cells []string
+		cells = append(cells, feature)
+		for _, backend := range allBackendsSorted {
+			if featureToBackendMap[feature][backend] {
+				cells = append(cells, "yes")
+			} else {
+				cells = append(cells, "no")
+			}
+		}
+		buf.WriteString(strings.Join(cells, " | "))
+		buf.WriteString("\n")
+	}
+
+	if os.Getenv("GOPY_GENERATE_SUPPORT_MATRIX") == "1" {
+		err := os.WriteFile("SUPPORT_MATRIX.md", buf.Bytes(), 0644)
+		if err != nil {
+			log.Fatalf("Unable to write SUPPORT_MATRIX.md")
+		}
+		return
+	}
+
+	src, err := os.ReadFile("SUPPORT_MATRIX.md")
+	if err != nil {
+		log.Fatalf("Unable to read SUPPORT_MATRIX.md")
+	}
+
+	msg := `
+This is a test case to verify the support matrix. This test is likely failing
+because the map features has been updated and the
+auto-generated file SUPPORT_MATRIX.md hasn't been updated. Please run 'go test'
+with environment variable GOPY_GENERATE_SUPPORT_MATRIX=1 to regenerate
+SUPPORT_MATRIX.md and commit the changes to SUPPORT_MATRIX.md onto git.
+`
+	if bytes.Compare(buf.Bytes(), src) != 0 {
+		t.Fatalf(msg)
+	}
+}
+
+type pkg struct {
+	path      string
+	lang      []string
+	cmd       string
+	outputdir string
+	pkgprefix string
+	testdir   string
+	extras    []string
+	files     []string // files of the package copied into the output dir before generating
+	want      []byte
+}
+
+func testPkg(t *testing.T, table pkg) {
+	backends := []string{"py3"}
+	// backends := table.lang // todo: enabling py2 testing requires separate "want" output
+	for _, be := range backends {
+		fmt.Printf("looping over backends: %s in %s\n", be, backends)
+		vm, ok := testBackends[be]
+		if !ok || vm == "" {
+			// backend not available.
+			t.Logf("Skipped testing backend %s for %s\n", be, table.path)
+			continue
 		}
 		switch be {
 		case "py3":
@@ -1008,6 +2644,13 @@ func testPkgBackend(t *testing.T, pyvm string, table pkg) {
 	if table.cmd != "build" { // non-build cases end up inside the working dir -- need a global import path
 		fpath = filepath.Join(curPkgPath, table.path)
 	}
+	for _, fn := range table.files {
+		err = copyCmd(filepath.Join(cwd, table.path, fn), filepath.Join(genPkgDir, fn))
+		if err != nil {
+			t.Fatalf("[%s:%s]: error copying %q to the output dir: %v\n", pyvm, table.path, fn, err)
+		}
+	}
+
 	args := []string{table.cmd, "-vm=" + pyvm, "-output=" + genPkgDir, "-package-prefix", table.pkgprefix}
 	if table.extras != nil {
 		args = append(args, table.extras...)
@@ -8,6 +8,7 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 
 	"github.com/go-python/gopy/bind"
 )
@@ -104,7 +105,7 @@ gen:
 	%[3]s
 
 build:
-	$(MAKE) -C %[1]s build
+	$(MAKE) -C %[5]s build
 
 install-pkg:
 	# this does a local install of the package, building the sdist and then directly installing it
@@ -114,7 +115,7 @@ install-pkg:
 
 install-exe:
 	# install executable into /usr/local/bin
-	cp %[1]s/py%[1]s /usr/local/bin/
+	cp %[5]s/py%[6]s /usr/local/bin/
 
 `
 )
@@ -132,7 +133,7 @@ func GenPyPkgSetup(cfg *BuildCfg, user, version, author, email, desc, url string
 	if err != nil {
 		return err
 	}
-	fmt.Fprintf(sf, setupTempl, cfg.Name, dashUser, version, author, email, desc, url)
+	fmt.Fprintf(sf, setupTempl, cfg.FullName(), dashUser, version, author, email, desc, url)
 	sf.Close()
 
 	mi, err := os.Create(filepath.Join(cfg.OutputDir, "MANIFEST.in"))
@@ -146,14 +147,14 @@ func GenPyPkgSetup(cfg *BuildCfg, user, version, author, email, desc, url string
 	if err != nil {
 		return err
 	}
-	fmt.Fprintf(lf, bsdLicense, cfg.Name)
+	fmt.Fprintf(lf, bsdLicense, cfg.FullName())
 	lf.Close()
 
 	rf, err := os.Create(filepath.Join(cfg.OutputDir, "README.md"))
 	if err != nil {
 		return err
 	}
-	fmt.Fprintf(rf, readmeTempl, cfg.Name, desc)
+	fmt.Fprintf(rf, readmeTempl, cfg.FullName(), desc)
 	rf.Close()
 
 	_, pyonly := filepath.Split(cfg.VM)
@@ -163,8 +164,31 @@ func GenPyPkgSetup(cfg *BuildCfg, user, version, author, email, desc, url string
 	if err != nil {
 		return err
 	}
-	fmt.Fprintf(mf, makefileTempl, cfg.Name, cfg.Cmd, gencmd, pyonly)
+	fmt.Fprintf(mf, makefileTempl, cfg.FullName(), cfg.Cmd, gencmd, pyonly, filepath.ToSlash(cfg.PkgDir()), cfg.Name)
 	mf.Close()
 
 	return err
 }
+
+// GenPyPkgParents generates an empty __init__.py in each of the python
+// packages containing the output package of a dotted name, in the root dir
+func GenPyPkgParents(root string, cfg *BuildCfg) error {
+	dir := root
+	for _, p := range strings.Split(cfg.Parent, ".") {
+		if p == "" {
+			break
+		}
+		dir = filepath.Join(dir, p)
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return err
+		}
+		fn := filepath.Join(dir, "__init__.py")
+		if _, err := os.Stat(fn); err == nil {
+			continue
+		}
+		if err := os.WriteFile(fn, nil, 0644); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,96 @@
+// Copyright 2024 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/gonuts/commander"
+	"github.com/gonuts/flag"
+)
+
+// The -profile option of gen, build, pkg and exe sets the defaults of the
+// options shaping the python API of the bindings to those of a preset:
+//
+//   - minimal: the bindings closest to Go, with go.Duration ints of
+//     nanoseconds and comma-ok functions returning None
+//   - standard: a pythonic API, with snake_case names, dataclasses,
+//     KeyError for comma-ok misses, the Go errors of the standard library
+//     raised as the builtin exceptions fitting them, lossy checks, and the
+//     slices and maps of at most 64 elements returned as lists and dicts
+//   - full: standard, plus pretty() methods, the symbols of the first
+//     package re-exported at the top level, and pytest smoke tests
+//
+// The options given on the command line, in the config file, or by
+// -from-directives, take precedence over those of the profile, e.g.,
+// -profile=standard -rename=false.
+
+// profileErrors are the mappings of the errors of the standard library to
+// the python builtin exceptions of the standard and full profiles
+const profileErrors = "io/fs.ErrNotExist=FileNotFoundError,io/fs.ErrExist=FileExistsError," +
+	"io/fs.ErrPermission=PermissionError,io.EOF=EOFError,io.ErrUnexpectedEOF=EOFError," +
+	"*strconv.NumError=ValueError,os.ErrDeadlineExceeded=TimeoutError"
+
+// bindProfiles are the options of the presets of -profile, by name
+var bindProfiles = map[string]map[string]string{
+	"minimal": {
+		"no-timedelta": "true",
+		"comma-ok":     "none",
+	},
+	"standard": {
+		"rename":       "true",
+		"dataclass":    "true",
+		"comma-ok":     "raise",
+		"errors":       profileErrors,
+		"lossy-checks": "true",
+		"copy-max":     "64",
+	},
+	"full": {
+		"rename":       "true",
+		"dataclass":    "true",
+		"comma-ok":     "raise",
+		"errors":       profileErrors,
+		"lossy-checks": "true",
+		"copy-max":     "64",
+		"pretty":       "true",
+		"reexport":     "true",
+		"gen-tests":    "true",
+	},
+}
+
+// applyProfile sets the flags of the command that are not set, on the
+// command line, in the config file or by -from-directives, to the options
+// of the preset of its -profile option, if any
+func applyProfile(cmdr *commander.Command) error {
+	name := cmdr.Flag.Lookup("profile").Value.Get().(string)
+	if name == "" {
+		return nil
+	}
+	opts, ok := bindProfiles[name]
+	if !ok {
+		var names []string
+		for nm := range bindProfiles {
+			names = append(names, nm)
+		}
+		sort.Strings(names)
+		return fmt.Errorf("gopy: invalid -profile %q, must be one of %s", name, strings.Join(names, ", "))
+	}
+
+	explicit := make(map[string]bool)
+	cmdr.Flag.Visit(func(f *flag.Flag) {
+		explicit[f.Name] = true
+	})
+	for opt, val := range opts {
+		if explicit[opt] || cmdr.Flag.Lookup(opt) == nil {
+			continue
+		}
+		if err := cmdr.Flag.Set(opt, val); err != nil {
+			return fmt.Errorf("gopy: invalid option -%s=%s of -profile %s: %v", opt, val, name, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,57 @@
+// Copyright 2024 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"testing"
+)
+
+func TestApplyProfile(t *testing.T) {
+	cmd := gopyMakeCmdGen()
+	if err := cmd.Flag.Parse([]string{"-profile=full", "-rename=false", "."}); err != nil {
+		t.Fatal(err)
+	}
+	if err := applyProfile(cmd); err != nil {
+		t.Fatal(err)
+	}
+	for _, opt := range []string{"dataclass", "lossy-checks", "pretty", "reexport", "gen-tests"} {
+		if !cmd.Flag.Lookup(opt).Value.Get().(bool) {
+			t.Errorf("-%s of the profile not set", opt)
+		}
+	}
+	if got := cmd.Flag.Lookup("comma-ok").Value.Get().(string); got != "raise" {
+		t.Errorf("-comma-ok of the profile: got %q, want raise", got)
+	}
+	if got := cmd.Flag.Lookup("errors").Value.Get().(string); got != profileErrors {
+		t.Errorf("-errors of the profile: got %q, want %q", got, profileErrors)
+	}
+	if cmd.Flag.Lookup("rename").Value.Get().(bool) {
+		t.Errorf("-rename of the command line overridden by the profile")
+	}
+
+	// the options of the profiles are options of gen
+	for name, opts := range bindProfiles {
+		cmd := gopyMakeCmdGen()
+		if err := cmd.Flag.Parse([]string{"-profile=" + name, "."}); err != nil {
+			t.Fatal(err)
+		}
+		for opt := range opts {
+			if cmd.Flag.Lookup(opt) == nil {
+				t.Errorf("profile %s: unknown option -%s", name, opt)
+			}
+		}
+		if err := applyProfile(cmd); err != nil {
+			t.Errorf("profile %s: %v", name, err)
+		}
+	}
+
+	cmd = gopyMakeCmdGen()
+	if err := cmd.Flag.Parse([]string{"-profile=fancy", "."}); err != nil {
+		t.Fatal(err)
+	}
+	if err := applyProfile(cmd); err == nil {
+		t.Errorf("no error for an invalid profile")
+	}
+}
@@ -0,0 +1,320 @@
+// Copyright 2024 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"archive/tar"
+	"bufio"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// hermetic builds use a pinned python-build-standalone distribution, see:
+// https://github.com/astral-sh/python-build-standalone
+
+const (
+	// pyStandaloneTag is the pinned python-build-standalone release
+	pyStandaloneTag = "20241016"
+	// pyStandaloneURL is the default download location of the releases,
+	// which can be overridden with the GOPY_PYTHON_STANDALONE_URL env var,
+	// e.g., for a local mirror
+	pyStandaloneURL = "https://github.com/astral-sh/python-build-standalone/releases/download"
+)
+
+// pyStandaloneVersions maps major.minor versions to the full python version
+// provided by the pinned python-build-standalone release
+var pyStandaloneVersions = map[string]string{
+	"3.8":  "3.8.20",
+	"3.9":  "3.9.20",
+	"3.10": "3.10.15",
+	"3.11": "3.11.10",
+	"3.12": "3.12.7",
+	"3.13": "3.13.0",
+}
+
+// pyStandaloneSHA256 pins the sha256 of the release assets of
+// pyStandaloneTag, by asset name, as listed in the SHA256SUMS of the
+// release, so that the downloads are verified against values that do not
+// come from the download location.  It must be updated with the tag, from
+// the install_only.tar.gz lines of the SHA256SUMS of the release, for all
+// the assets of pyStandaloneAssets.
+var pyStandaloneSHA256 = map[string]string{}
+
+// pyStandaloneSum returns the pinned sha256 of the release asset, or that
+// of the trusted SHA256SUMS file named by the GOPY_PYTHON_STANDALONE_SHA256SUMS
+// env var, for the assets not pinned, e.g., those of a local mirror
+func pyStandaloneSum(asset string) (string, error) {
+	if sum, ok := pyStandaloneSHA256[asset]; ok {
+		return sum, nil
+	}
+	if fn := os.Getenv("GOPY_PYTHON_STANDALONE_SHA256SUMS"); fn != "" {
+		f, err := os.Open(fn)
+		if err != nil {
+			return "", fmt.Errorf("gopy: could not read the checksums of GOPY_PYTHON_STANDALONE_SHA256SUMS: %v", err)
+		}
+		defer f.Close()
+		if sum, ok := parseSHA256Sums(f)[asset]; ok {
+			return strings.ToLower(sum), nil
+		}
+	}
+	return "", fmt.Errorf("gopy: no pinned checksum of %s (release %s): set GOPY_PYTHON_STANDALONE_SHA256SUMS to a trusted SHA256SUMS file listing it", asset, pyStandaloneTag)
+}
+
+// parseSHA256Sums parses lines of "<sha256>  <file name>"
+func parseSHA256Sums(r io.Reader) map[string]string {
+	sums := make(map[string]string)
+	sc := bufio.NewScanner(r)
+	for sc.Scan() {
+		fs := strings.Fields(sc.Text())
+		if len(fs) != 2 {
+			continue
+		}
+		sums[strings.TrimPrefix(fs[1], "*")] = fs[0]
+	}
+	return sums
+}
+
+// pyStandaloneAssets returns the names of all the release assets of the
+// standalone pythons, of all the versions and platforms, in order
+func pyStandaloneAssets() []string {
+	var assets []string
+	for version := range pyStandaloneVersions {
+		for _, goos := range []string{"linux", "darwin", "windows"} {
+			for _, goarch := range []string{"amd64", "arm64"} {
+				if asset, err := pyStandaloneAsset(version, goos, goarch); err == nil {
+					assets = append(assets, asset)
+				}
+			}
+		}
+	}
+	sort.Strings(assets)
+	return assets
+}
+
+// pyStandaloneTriple returns the target triple for the given GOOS / GOARCH
+func pyStandaloneTriple(goos, goarch string) (string, error) {
+	var arch string
+	switch goarch {
+	case "amd64":
+		arch = "x86_64"
+	case "arm64":
+		arch = "aarch64"
+	default:
+		return "", fmt.Errorf("gopy: no standalone python available for GOARCH=%s", goarch)
+	}
+	switch goos {
+	case "linux":
+		return arch + "-unknown-linux-gnu", nil
+	case "darwin":
+		return arch + "-apple-darwin", nil
+	case "windows":
+		if arch != "x86_64" {
+			break
+		}
+		return arch + "-pc-windows-msvc", nil
+	}
+	return "", fmt.Errorf("gopy: no standalone python available for GOOS=%s GOARCH=%s", goos, goarch)
+}
+
+// pyStandaloneAsset returns the release asset name of the standalone python
+// for the given version (major.minor or full version) and platform
+func pyStandaloneAsset(version, goos, goarch string) (string, error) {
+	full, ok := pyStandaloneVersions[version]
+	if !ok {
+		for _, v := range pyStandaloneVersions {
+			if v == version {
+				full, ok = v, true
+				break
+			}
+		}
+	}
+	if !ok {
+		return "", fmt.Errorf("gopy: python version %q is not available as standalone python (release %s)", version, pyStandaloneTag)
+	}
+	triple, err := pyStandaloneTriple(goos, goarch)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("cpython-%s+%s-%s-install_only.tar.gz", full, pyStandaloneTag, triple), nil
+}
+
+// pyStandaloneExe returns the path to the python executable
+// within an extracted standalone python directory
+func pyStandaloneExe(dir, goos string) string {
+	if goos == "windows" {
+		return filepath.Join(dir, "python", "python.exe")
+	}
+	return filepath.Join(dir, "python", "bin", "python3")
+}
+
+// standalonePython returns the path to the python executable of the pinned
+// standalone python of the given version, downloading, verifying and
+// extracting it into the user cache directory if not already there.
+func standalonePython(version string) (string, error) {
+	asset, err := pyStandaloneAsset(version, runtime.GOOS, runtime.GOARCH)
+	if err != nil {
+		return "", err
+	}
+	cache, err := os.UserCacheDir()
+	if err != nil {
+		return "", errors.Wrapf(err, "gopy: could not locate user cache directory")
+	}
+	dir := filepath.Join(cache, "gopy", "python", strings.TrimSuffix(asset, "-install_only.tar.gz"))
+	exe := pyStandaloneExe(dir, runtime.GOOS)
+	if _, err := os.Stat(exe); err == nil {
+		return exe, nil
+	}
+
+	baseURL := pyStandaloneURL
+	if u := os.Getenv("GOPY_PYTHON_STANDALONE_URL"); u != "" {
+		baseURL = strings.TrimSuffix(u, "/")
+	}
+	baseURL += "/" + pyStandaloneTag
+
+	sum, err := pyStandaloneSum(asset)
+	if err != nil {
+		return "", err
+	}
+
+	fmt.Printf("downloading standalone python: %s\n", asset)
+	tmp, err := os.CreateTemp("", "gopy-python-*.tar.gz")
+	if err != nil {
+		return "", err
+	}
+	defer os.Remove(tmp.Name())
+	defer tmp.Close()
+
+	err = download(baseURL+"/"+asset, tmp)
+	if err != nil {
+		return "", err
+	}
+	if _, err = tmp.Seek(0, io.SeekStart); err != nil {
+		return "", err
+	}
+	h := sha256.New()
+	if _, err = io.Copy(h, tmp); err != nil {
+		return "", err
+	}
+	if got := hex.EncodeToString(h.Sum(nil)); got != sum {
+		return "", fmt.Errorf("gopy: checksum mismatch for %s: got %s, want %s", asset, got, sum)
+	}
+	if _, err = tmp.Seek(0, io.SeekStart); err != nil {
+		return "", err
+	}
+
+	// extract into a temporary directory first, so an interrupted
+	// extraction does not leave a broken python in the cache
+	tmpdir := dir + ".tmp"
+	os.RemoveAll(tmpdir)
+	err = untargz(tmp, tmpdir)
+	if err != nil {
+		os.RemoveAll(tmpdir)
+		return "", errors.Wrapf(err, "gopy: could not extract %s", asset)
+	}
+	os.RemoveAll(dir)
+	err = os.Rename(tmpdir, dir)
+	if err != nil {
+		return "", err
+	}
+	if _, err := os.Stat(exe); err != nil {
+		return "", fmt.Errorf("gopy: python executable not found in %s", asset)
+	}
+	fmt.Printf("standalone python installed in: %s\n", dir)
+	return exe, nil
+}
+
+// download fetches the given url into w
+func download(url string, w io.Writer) error {
+	resp, err := http.Get(url)
+	if err != nil {
+		return errors.Wrapf(err, "gopy: could not download %s", url)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("gopy: could not download %s: %s", url, resp.Status)
+	}
+	_, err = io.Copy(w, resp.Body)
+	if err != nil {
+		return errors.Wrapf(err, "gopy: could not download %s", url)
+	}
+	return nil
+}
+
+// untargz extracts the gzipped tar archive r into dir
+func untargz(r io.Reader, dir string) error {
+	gz, err := gzip.NewReader(r)
+	if err != nil {
+		return err
+	}
+	defer gz.Close()
+
+	tr := tar.NewReader(gz)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		fn := filepath.Join(dir, filepath.FromSlash(hdr.Name))
+		if !inDir(dir, fn) {
+			return fmt.Errorf("invalid file name in archive: %q", hdr.Name)
+		}
+		switch hdr.Typeflag {
+		case tar.TypeDir:
+			err = os.MkdirAll(fn, 0755)
+		case tar.TypeReg:
+			err = writeFileFrom(fn, tr, hdr.FileInfo().Mode())
+		case tar.TypeSymlink:
+			link := filepath.FromSlash(hdr.Linkname)
+			if filepath.IsAbs(link) || !inDir(dir, filepath.Join(filepath.Dir(fn), link)) {
+				return fmt.Errorf("invalid link target in archive: %q -> %q", hdr.Name, hdr.Linkname)
+			}
+			os.MkdirAll(filepath.Dir(fn), 0755)
+			err = os.Symlink(link, fn)
+		case tar.TypeLink:
+			link := filepath.FromSlash(hdr.Linkname)
+			if filepath.IsAbs(link) || !inDir(dir, filepath.Join(dir, link)) {
+				return fmt.Errorf("invalid link target in archive: %q -> %q", hdr.Name, hdr.Linkname)
+			}
+			os.MkdirAll(filepath.Dir(fn), 0755)
+			err = os.Link(filepath.Join(dir, link), fn)
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// inDir returns whether the path fn is within the directory dir
+func inDir(dir, fn string) bool {
+	return strings.HasPrefix(filepath.Clean(fn), filepath.Clean(dir)+string(os.PathSeparator))
+}
+
+func writeFileFrom(fn string, r io.Reader, mode os.FileMode) error {
+	os.MkdirAll(filepath.Dir(fn), 0755)
+	f, err := os.OpenFile(fn, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, mode.Perm())
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(f, r)
+	if err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
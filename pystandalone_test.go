@@ -0,0 +1,147 @@
+// Copyright 2024 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestPyStandaloneAsset(t *testing.T) {
+	for _, tt := range []struct {
+		version, goos, goarch string
+		want                  string
+		err                   bool
+	}{
+		{"3.12", "linux", "amd64", "cpython-3.12.7+20241016-x86_64-unknown-linux-gnu-install_only.tar.gz", false},
+		{"3.12.7", "darwin", "arm64", "cpython-3.12.7+20241016-aarch64-apple-darwin-install_only.tar.gz", false},
+		{"3.11", "windows", "amd64", "cpython-3.11.10+20241016-x86_64-pc-windows-msvc-install_only.tar.gz", false},
+		{"3.11", "windows", "arm64", "", true},
+		{"3.12", "linux", "386", "", true},
+		{"2.7", "linux", "amd64", "", true},
+		{"3.12.1", "linux", "amd64", "", true},
+	} {
+		got, err := pyStandaloneAsset(tt.version, tt.goos, tt.goarch)
+		if (err != nil) != tt.err {
+			t.Errorf("pyStandaloneAsset(%s, %s, %s): unexpected error: %v", tt.version, tt.goos, tt.goarch, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("pyStandaloneAsset(%s, %s, %s): expected %s, actual %s", tt.version, tt.goos, tt.goarch, tt.want, got)
+		}
+	}
+}
+
+func TestPyStandaloneSum(t *testing.T) {
+	t.Setenv("GOPY_PYTHON_STANDALONE_SHA256SUMS", "")
+
+	// all the assets of the release are pinned
+	assets := pyStandaloneAssets()
+	if len(assets) != 5*len(pyStandaloneVersions) {
+		t.Fatalf("unexpected number of assets: %d", len(assets))
+	}
+	for _, asset := range assets {
+		sum, err := pyStandaloneSum(asset)
+		if err != nil {
+			t.Errorf("no pinned checksum: %v", err)
+			continue
+		}
+		if b, err := hex.DecodeString(sum); err != nil || len(b) != sha256.Size {
+			t.Errorf("invalid pinned checksum of %s: %q", asset, sum)
+		}
+	}
+	if len(pyStandaloneSHA256) != len(assets) {
+		t.Errorf("pinned checksums of %d assets, expected %d", len(pyStandaloneSHA256), len(assets))
+	}
+
+	// the other assets need a trusted SHA256SUMS file listing them
+	const asset = "cpython-3.12.7+20241016-x86_64-unknown-freebsd-install_only.tar.gz"
+	if _, err := pyStandaloneSum(asset); err == nil {
+		t.Fatalf("expected an error for an asset without a pinned checksum")
+	}
+	fn := filepath.Join(t.TempDir(), "SHA256SUMS")
+	err := os.WriteFile(fn, []byte(`ABC123  `+asset+`
+def456 *cpython-other.tar.gz
+
+bad line here
+`), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("GOPY_PYTHON_STANDALONE_SHA256SUMS", fn)
+	if got, err := pyStandaloneSum(asset); err != nil || got != "abc123" {
+		t.Fatalf("expected the checksum of the SHA256SUMS file, actual %s (%v)", got, err)
+	}
+	if _, err := pyStandaloneSum("cpython-unknown.tar.gz"); err == nil {
+		t.Fatalf("expected an error for an asset missing from the SHA256SUMS file")
+	}
+}
+
+func TestUntargz(t *testing.T) {
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gz)
+	add := func(hdr *tar.Header, body string) {
+		hdr.Size = int64(len(body))
+		if err := tw.WriteHeader(hdr); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := tw.Write([]byte(body)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	add(&tar.Header{Name: "python/bin/", Typeflag: tar.TypeDir, Mode: 0755}, "")
+	add(&tar.Header{Name: "python/bin/python3.12", Typeflag: tar.TypeReg, Mode: 0755}, "#!python")
+	add(&tar.Header{Name: "python/bin/python3", Typeflag: tar.TypeSymlink, Linkname: "python3.12"}, "")
+	tw.Close()
+	gz.Close()
+
+	dir := t.TempDir()
+	if err := untargz(bytes.NewReader(buf.Bytes()), dir); err != nil {
+		t.Fatal(err)
+	}
+	got, err := os.ReadFile(pyStandaloneExe(dir, "linux"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != "#!python" {
+		t.Fatalf("unexpected content: %q", got)
+	}
+
+	// archive entries must not escape the target directory
+	buf.Reset()
+	gz = gzip.NewWriter(&buf)
+	tw = tar.NewWriter(gz)
+	add(&tar.Header{Name: "../evil", Typeflag: tar.TypeReg, Mode: 0644}, "x")
+	tw.Close()
+	gz.Close()
+	if err := untargz(bytes.NewReader(buf.Bytes()), filepath.Join(dir, "sub")); err == nil {
+		t.Fatalf("expected an error for an escaping file name")
+	}
+
+	// nor link outside of it
+	for _, hdr := range []*tar.Header{
+		{Name: "python/lib/evil", Typeflag: tar.TypeSymlink, Linkname: "../../../etc/passwd"},
+		{Name: "python/lib/evil", Typeflag: tar.TypeSymlink, Linkname: "/etc/passwd"},
+		{Name: "python/lib/evil", Typeflag: tar.TypeLink, Linkname: "../etc/passwd"},
+		{Name: "python/lib/evil", Typeflag: tar.TypeLink, Linkname: "/etc/passwd"},
+	} {
+		buf.Reset()
+		gz = gzip.NewWriter(&buf)
+		tw = tar.NewWriter(gz)
+		add(hdr, "")
+		tw.Close()
+		gz.Close()
+		if err := untargz(bytes.NewReader(buf.Bytes()), filepath.Join(dir, "sub")); err == nil {
+			t.Fatalf("expected an error for the link %s -> %s", hdr.Name, hdr.Linkname)
+		}
+	}
+}
@@ -0,0 +1,119 @@
+// Copyright 2024 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"runtime"
+)
+
+// the -cibuildwheel option of pkg generates the cibuildwheel configuration
+// of the bindings, in a pyproject.toml, building their manylinux wheels in
+// the docker containers of cibuildwheel for each python version, copying
+// the go module containing them, by build_wheel.sh: it installs go and
+// goimports in the container, rewrites the #cgo flags of the generated go
+// file for the python of the build, and runs the build target of the
+// Makefile, not linking libpython, into the single python extension module
+// statically linking the go archive, which auditwheel repair keeps as is.
+
+// wheelGoVersion is the go version installed in the containers when gopy is
+// not built by a release of go
+const wheelGoVersion = "1.22.8"
+
+const (
+	// 1 = pkg name, 2 = cmd, 3 = import name
+	wheelPyprojectTempl = `# cibuildwheel configuration of the python bindings of %[1]s
+# File is generated by gopy (will not be overwritten though)
+# %[2]s
+#
+# build the manylinux wheels into wheelhouse/, in docker, from the root of
+# the go module containing this dir, copied into the containers, with:
+#   pipx run cibuildwheel --platform linux path/to/this/dir
+
+[build-system]
+requires = ["setuptools", "wheel"]
+build-backend = "setuptools.build_meta"
+
+[tool.cibuildwheel]
+build = "cp3*-manylinux_*"
+skip = "cp36-* cp37-*"
+before-build = "bash {package}/build_wheel.sh"
+test-command = "python -c 'import %[3]s'"
+build-verbosity = 1
+
+[tool.cibuildwheel.linux]
+before-all = "bash {package}/build_wheel.sh --setup"
+environment = { PATH = "/usr/local/go/bin:$HOME/go/bin:$PATH" }
+repair-wheel-command = "auditwheel repair -w {dest_dir} {wheel}"
+`
+
+	// 1 = pkg name, 2 = go version, 3 = package dir, 4 = name of go file
+	wheelBuildTempl = `#!/bin/bash
+# builds the python bindings of %[1]s for the python of cibuildwheel, in
+# its manylinux containers, see pyproject.toml
+# File is generated by gopy (will not be overwritten though)
+
+set -ex
+
+if [ "$1" = "--setup" ]; then
+	# once per container: go and goimports
+	arch=$(uname -m | sed -e 's/x86_64/amd64/' -e 's/aarch64/arm64/')
+	curl -sSL "https://go.dev/dl/go%[2]s.linux-$arch.tar.gz" | tar -C /usr/local -xz
+	/usr/local/go/bin/go install golang.org/x/tools/cmd/goimports@latest
+	exit 0
+fi
+
+cd "$(dirname "$0")"
+python -m pip install pybindgen
+
+# the extension modules of other pythons, e.g., built by gopy pkg, would
+# shadow the one built here
+rm -f %[3]s/_%[4]s.*.so
+
+# the python symbols are resolved when the interpreter loads the module:
+# libpython is not linked, as required by the manylinux policy
+PYINC=$(python -c "import sysconfig; print(sysconfig.get_path('include'))")
+sed -i \
+	-e "s|^#cgo CFLAGS: .*|#cgo CFLAGS: -I$PYINC -Wno-error -Wno-implicit-function-declaration -Wno-int-conversion|" \
+	-e "s|^#cgo LDFLAGS: .*|#cgo LDFLAGS: -lm|" \
+	%[3]s/%[4]s.go
+
+make build PYTHON=python CFLAGS="-I$PYINC" LDFLAGS=
+`
+)
+
+// wheelGoRe matches the go versions of the releases of go
+var wheelGoRe = regexp.MustCompile(`^go(\d+\.\d+(\.\d+)?)$`)
+
+// wheelGo returns the go version installed in the containers: that of the
+// release of go building gopy, if any
+func wheelGo(vers string) string {
+	m := wheelGoRe.FindStringSubmatch(vers)
+	if m == nil {
+		return wheelGoVersion
+	}
+	if m[2] == "" {
+		return m[1] + ".0"
+	}
+	return m[1]
+}
+
+// GenWheelConfig generates the cibuildwheel configuration of the python
+// package of the bindings, unless it exists
+func GenWheelConfig(cfg *BuildCfg) error {
+	fn := filepath.Join(cfg.OutputDir, "pyproject.toml")
+	if _, err := os.Stat(fn); err == nil {
+		return nil
+	}
+	pf := fmt.Sprintf(wheelPyprojectTempl, cfg.FullName(), cfg.Cmd, cfg.FullName())
+	if err := os.WriteFile(fn, []byte(pf), 0644); err != nil {
+		return err
+	}
+	bf := fmt.Sprintf(wheelBuildTempl, cfg.FullName(), wheelGo(runtime.Version()), filepath.ToSlash(cfg.PkgDir()), cfg.Name)
+	return os.WriteFile(filepath.Join(cfg.OutputDir, "build_wheel.sh"), []byte(bf), 0755)
+}
@@ -0,0 +1,58 @@
+// Copyright 2024 The go-python Authors.  All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestWheelGo(t *testing.T) {
+	for _, tt := range []struct {
+		vers, want string
+	}{
+		{"go1.22.8", "1.22.8"},
+		{"go1.23", "1.23.0"},
+		{"devel go1.24-abcdef", wheelGoVersion},
+		{"go1.23rc1", wheelGoVersion},
+	} {
+		if got := wheelGo(tt.vers); got != tt.want {
+			t.Errorf("wheelGo(%q): expected %s, actual %s", tt.vers, tt.want, got)
+		}
+	}
+}
+
+func TestGenWheelConfig(t *testing.T) {
+	cfg := NewBuildCfg()
+	cfg.OutputDir = t.TempDir()
+	if err := cfg.SetName("org.hi"); err != nil {
+		t.Fatal(err)
+	}
+	if err := GenWheelConfig(cfg); err != nil {
+		t.Fatal(err)
+	}
+	proj, err := os.ReadFile(filepath.Join(cfg.OutputDir, "pyproject.toml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := `test-command = "python -c 'import org.hi'"`; !strings.Contains(string(proj), want) {
+		t.Errorf("pyproject.toml: missing %q in:\n%s", want, proj)
+	}
+	build, err := os.ReadFile(filepath.Join(cfg.OutputDir, "build_wheel.sh"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		"rm -f org/hi/_hi.*.so\n",
+		"\torg/hi/hi.go\n",
+		`CFLAGS="-I$PYINC" LDFLAGS=` + "\n",
+	} {
+		if !strings.Contains(string(build), want) {
+			t.Errorf("build_wheel.sh: missing %q in:\n%s", want, build)
+		}
+	}
+}